@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Track the most recent successful login on the user
+ALTER TABLE users ADD COLUMN last_login_at TIMESTAMP WITH TIME ZONE;
+
+-- Create index for inactive-account reporting
+CREATE INDEX idx_users_last_login_at ON users(last_login_at);
+
+-- Create login history table; user_id is NULL for attempts with an unknown email
+CREATE TABLE login_events (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    success BOOLEAN NOT NULL,
+    method VARCHAR(20) NOT NULL,
+    failure_reason VARCHAR(50),
+    ip_address VARCHAR(45),
+    user_agent TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Create indexes for login history lookups
+CREATE INDEX idx_login_events_user_id ON login_events(user_id);
+CREATE INDEX idx_login_events_created_at ON login_events(created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS login_events;
+DROP INDEX IF EXISTS idx_users_last_login_at;
+ALTER TABLE users DROP COLUMN IF EXISTS last_login_at;
+-- +goose StatementEnd
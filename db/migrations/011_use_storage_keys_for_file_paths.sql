@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- file_path now holds the storage key rather than a filesystem path; uploads were written to
+-- UPLOAD_PATH/<file_name>, which is where the local storage backend looks up the key <file_name>
+UPDATE files SET file_path = file_name;
+
+-- Export archives were written outside of storage and cannot be carried over; expire their links
+UPDATE data_exports SET file_path = NULL, expires_at = NOW() WHERE status = 'completed';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Assumes the default UPLOAD_PATH
+UPDATE files SET file_path = 'uploads/' || file_name;
+-- +goose StatementEnd
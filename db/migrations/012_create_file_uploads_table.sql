@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Resumable (tus) uploads in progress; the files row is created once all bytes have arrived
+CREATE TABLE file_uploads (
+    id UUID PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    upload_length BIGINT NOT NULL,
+    upload_offset BIGINT NOT NULL DEFAULT 0,
+    metadata TEXT,
+    original_name VARCHAR(255) NOT NULL,
+    mime_type VARCHAR(255) NOT NULL,
+    description TEXT,
+    category VARCHAR(100),
+    file_id INTEGER REFERENCES files(id) ON DELETE SET NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Each PATCH request is stored as a separate object until the upload is assembled
+CREATE TABLE file_upload_chunks (
+    id SERIAL PRIMARY KEY,
+    upload_id UUID NOT NULL REFERENCES file_uploads(id) ON DELETE CASCADE,
+    chunk_offset BIGINT NOT NULL,
+    chunk_size BIGINT NOT NULL,
+    storage_key VARCHAR(500) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (upload_id, chunk_offset)
+);
+
+CREATE INDEX idx_file_uploads_user_id ON file_uploads(user_id);
+CREATE INDEX idx_file_uploads_expires_at ON file_uploads(expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS file_upload_chunks;
+DROP TABLE IF EXISTS file_uploads;
+-- +goose StatementEnd
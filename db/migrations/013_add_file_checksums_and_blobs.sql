@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Content is stored once per SHA-256 checksum; files rows referencing the same content share a blob
+CREATE TABLE file_blobs (
+    checksum VARCHAR(64) PRIMARY KEY,
+    storage_key VARCHAR(500) NOT NULL UNIQUE,
+    file_size BIGINT NOT NULL,
+    ref_count INTEGER NOT NULL DEFAULT 0 CHECK (ref_count >= 0),
+    verified_at TIMESTAMP WITH TIME ZONE,
+    corrupted BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- NULL for files uploaded before checksums were introduced; those keep their own object
+ALTER TABLE files ADD COLUMN checksum VARCHAR(64);
+
+-- Running SHA-256 state of a resumable upload, so completing it does not read the content again
+ALTER TABLE file_uploads ADD COLUMN hash_state BYTEA;
+
+CREATE INDEX idx_files_checksum ON files(checksum);
+CREATE INDEX idx_file_blobs_unreferenced ON file_blobs(updated_at) WHERE ref_count = 0;
+CREATE INDEX idx_file_blobs_verified_at ON file_blobs(verified_at NULLS FIRST);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE file_uploads DROP COLUMN IF EXISTS hash_state;
+ALTER TABLE files DROP COLUMN IF EXISTS checksum;
+DROP TABLE IF EXISTS file_blobs;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Malware scan state of uploaded files; only clean files can be downloaded. Files uploaded before
+-- scanning was introduced are considered clean.
+ALTER TABLE files ADD COLUMN scan_status VARCHAR(20) NOT NULL DEFAULT 'clean';
+ALTER TABLE files ADD COLUMN scan_signature VARCHAR(255);
+ALTER TABLE files ADD COLUMN scanned_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE files ADD CONSTRAINT chk_files_scan_status CHECK (scan_status IN ('pending', 'clean', 'infected'));
+
+CREATE INDEX idx_files_scan_pending ON files(created_at) WHERE scan_status = 'pending';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_files_scan_pending;
+ALTER TABLE files DROP CONSTRAINT IF EXISTS chk_files_scan_status;
+ALTER TABLE files DROP COLUMN IF EXISTS scanned_at;
+ALTER TABLE files DROP COLUMN IF EXISTS scan_signature;
+ALTER TABLE files DROP COLUMN IF EXISTS scan_status;
+-- +goose StatementEnd
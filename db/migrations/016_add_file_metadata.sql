@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Metadata extracted from the content of uploaded files, such as image dimensions or the page count
+-- of documents. NULL when nothing was extracted.
+ALTER TABLE files ADD COLUMN metadata JSONB;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE files DROP COLUMN IF EXISTS metadata;
+-- +goose StatementEnd
@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The files row holds the current version of a file; uploading new content moves the previous
+-- content into file_versions, which keeps its reference on the stored content
+ALTER TABLE files ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE files ADD COLUMN content_updated_at TIMESTAMP WITH TIME ZONE;
+UPDATE files SET content_updated_at = created_at;
+ALTER TABLE files ALTER COLUMN content_updated_at SET DEFAULT NOW();
+
+CREATE TABLE file_versions (
+    id SERIAL PRIMARY KEY,
+    file_id INTEGER NOT NULL REFERENCES files(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    original_name VARCHAR(255) NOT NULL,
+    file_path VARCHAR(500) NOT NULL,
+    file_size BIGINT NOT NULL,
+    mime_type VARCHAR(255) NOT NULL,
+    checksum VARCHAR(64),
+    scan_status VARCHAR(20) NOT NULL,
+    metadata JSONB,
+    created_at TIMESTAMP WITH TIME ZONE, -- When the content of the version was uploaded
+    UNIQUE (file_id, version)
+);
+
+CREATE INDEX idx_file_versions_file_path ON file_versions(file_path);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS file_versions;
+ALTER TABLE files DROP COLUMN IF EXISTS content_updated_at;
+ALTER TABLE files DROP COLUMN IF EXISTS version;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Storage used per user, maintained along with the files rows; earlier versions of files count towards
+-- the bytes. Quotas come from the role of the user unless overridden here.
+CREATE TABLE user_storage (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    used_bytes BIGINT NOT NULL DEFAULT 0,
+    file_count INTEGER NOT NULL DEFAULT 0,
+    quota_bytes BIGINT, -- NULL uses the quota of the role, 0 is unlimited
+    quota_files INTEGER,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+INSERT INTO user_storage (user_id, used_bytes, file_count)
+SELECT f.uploaded_by,
+       SUM(f.file_size + COALESCE((SELECT SUM(v.file_size) FROM file_versions v WHERE v.file_id = f.id), 0)),
+       COUNT(*)
+FROM files f
+GROUP BY f.uploaded_by;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS user_storage;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Folders form a tree per user. path is the materialized path of the ancestor IDs, e.g. /3/7/ for a
+-- folder inside folder 7 inside folder 3, so a subtree is every folder whose path starts with
+-- path || id || '/'.
+CREATE TABLE folders (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    parent_id INTEGER REFERENCES folders(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    path TEXT NOT NULL DEFAULT '/',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_folders_name ON folders(user_id, COALESCE(parent_id, 0), LOWER(name));
+CREATE INDEX idx_folders_path ON folders(path text_pattern_ops);
+
+-- Files of a deleted folder are normally deleted first; any left over end up at the top level
+ALTER TABLE files ADD COLUMN folder_id INTEGER REFERENCES folders(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_files_folder_id ON files(folder_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE files DROP COLUMN IF EXISTS folder_id;
+DROP TABLE IF EXISTS folders;
+-- +goose StatementEnd
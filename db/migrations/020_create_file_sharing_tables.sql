@@ -0,0 +1,42 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Links that let anyone holding the token download a file, optionally protected by a password and
+-- limited in time and number of downloads
+CREATE TABLE file_share_links (
+    id SERIAL PRIMARY KEY,
+    file_id INTEGER NOT NULL REFERENCES files(id) ON DELETE CASCADE,
+    created_by INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token VARCHAR(255) NOT NULL,
+    password_hash VARCHAR(255),
+    expires_at TIMESTAMP WITH TIME ZONE,
+    max_downloads INTEGER, -- NULL is unlimited
+    download_count INTEGER NOT NULL DEFAULT 0,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_file_share_links_token ON file_share_links(token);
+CREATE INDEX idx_file_share_links_file_id ON file_share_links(file_id);
+
+-- Access to a file granted by its owner to another user; write includes read
+CREATE TABLE file_grants (
+    id SERIAL PRIMARY KEY,
+    file_id INTEGER NOT NULL REFERENCES files(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    permission VARCHAR(10) NOT NULL,
+    granted_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT chk_file_grants_permission CHECK (permission IN ('read', 'write'))
+);
+
+CREATE UNIQUE INDEX idx_file_grants_file_user ON file_grants(file_id, user_id);
+CREATE INDEX idx_file_grants_user_id ON file_grants(user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS file_grants;
+DROP TABLE IF EXISTS file_share_links;
+-- +goose StatementEnd
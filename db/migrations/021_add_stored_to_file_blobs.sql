@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Whether the content of a blob has been written to storage. A blob is registered before its content
+-- is stored, so uploads of the same content that arrive meanwhile must not rely on it yet. Existing
+-- blobs were stored before they were registered.
+ALTER TABLE file_blobs ADD COLUMN stored BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE file_blobs ALTER COLUMN stored SET DEFAULT FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE file_blobs DROP COLUMN IF EXISTS stored;
+-- +goose StatementEnd
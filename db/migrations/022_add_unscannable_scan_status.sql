@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Files the scanner refuses, such as those over its size limit, can never get a verdict. They are
+-- marked unscannable instead of being left pending, so they stay blocked without being rescanned.
+ALTER TABLE files DROP CONSTRAINT IF EXISTS chk_files_scan_status;
+ALTER TABLE files ADD CONSTRAINT chk_files_scan_status CHECK (scan_status IN ('pending', 'clean', 'infected', 'unscannable'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+UPDATE files SET scan_status = 'pending' WHERE scan_status = 'unscannable';
+UPDATE file_versions SET scan_status = 'pending' WHERE scan_status = 'unscannable';
+ALTER TABLE files DROP CONSTRAINT IF EXISTS chk_files_scan_status;
+ALTER TABLE files ADD CONSTRAINT chk_files_scan_status CHECK (scan_status IN ('pending', 'clean', 'infected'));
+-- +goose StatementEnd
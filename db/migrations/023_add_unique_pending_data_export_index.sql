@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Only one export may be generated at a time per user. Checking for one in progress before creating
+-- another does not stop concurrent requests, so the database enforces it. Duplicates left behind by
+-- such requests are failed, keeping the most recent one.
+UPDATE data_exports
+SET status = 'failed', error_message = 'export was interrupted', updated_at = NOW()
+WHERE status IN ('pending', 'processing')
+  AND id NOT IN (
+      SELECT MAX(id) FROM data_exports
+      WHERE status IN ('pending', 'processing')
+      GROUP BY user_id
+  );
+
+CREATE UNIQUE INDEX idx_data_exports_user_id_in_progress ON data_exports(user_id) WHERE status IN ('pending', 'processing');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_data_exports_user_id_in_progress;
+-- +goose StatementEnd
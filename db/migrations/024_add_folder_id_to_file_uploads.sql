@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Resumable uploads can target a folder; the file is created in it once the upload completes. An upload
+-- whose folder is deleted meanwhile ends up at the top level, like the files of a deleted folder.
+ALTER TABLE file_uploads ADD COLUMN folder_id INTEGER REFERENCES folders(id) ON DELETE SET NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE file_uploads DROP COLUMN IF EXISTS folder_id;
+-- +goose StatementEnd
@@ -0,0 +1,54 @@
+-- name: CreateDataExport :one
+INSERT INTO data_exports (user_id, status)
+VALUES ($1, 'pending')
+RETURNING *;
+
+-- name: GetDataExport :one
+SELECT * FROM data_exports
+WHERE id = $1 LIMIT 1;
+
+-- name: GetDataExportByToken :one
+SELECT * FROM data_exports
+WHERE download_token = $1 LIMIT 1;
+
+-- name: GetPendingDataExportByUser :one
+-- Exports last updated before the cutoff were left behind by a crash and are not in progress anymore
+SELECT * FROM data_exports
+WHERE user_id = $1 AND status IN ('pending', 'processing') AND updated_at > $2
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: GetDataExportsByUser :many
+SELECT * FROM data_exports
+WHERE user_id = $1
+ORDER BY created_at DESC;
+
+-- name: MarkDataExportProcessing :exec
+UPDATE data_exports
+SET status = 'processing', updated_at = NOW()
+WHERE id = $1;
+
+-- name: CompleteDataExport :one
+UPDATE data_exports
+SET status = 'completed', file_path = $2, download_token = $3, expires_at = $4, completed_at = NOW(), updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: FailDataExport :exec
+UPDATE data_exports
+SET status = 'failed', error_message = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: FailStaleDataExports :many
+UPDATE data_exports
+SET status = 'failed', error_message = $2, updated_at = NOW()
+WHERE status IN ('pending', 'processing') AND updated_at <= $1
+RETURNING *;
+
+-- name: GetExpiredDataExports :many
+SELECT * FROM data_exports
+WHERE status = 'completed' AND expires_at IS NOT NULL AND expires_at <= $1;
+
+-- name: DeleteDataExport :exec
+DELETE FROM data_exports
+WHERE id = $1;
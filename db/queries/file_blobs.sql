@@ -0,0 +1,50 @@
+-- name: AcquireFileBlob :one
+-- Adds a reference to the blob with the checksum, creating it when missing; inserted tells whether
+-- the blob is new, stored whether its content has been written yet and corrupted whether the
+-- verification job found it damaged
+INSERT INTO file_blobs (checksum, storage_key, file_size, ref_count, stored)
+VALUES ($1, $2, $3, 1, FALSE)
+ON CONFLICT (checksum) DO UPDATE
+SET ref_count = file_blobs.ref_count + 1, updated_at = NOW()
+RETURNING storage_key, (xmax = 0)::boolean AS inserted, stored, corrupted;
+
+-- name: MarkFileBlobStored :exec
+-- Content written anew replaces damaged content, and is verified again first
+UPDATE file_blobs
+SET stored = TRUE, corrupted = FALSE, verified_at = NULL, updated_at = NOW()
+WHERE checksum = $1;
+
+-- name: GetFileBlob :one
+SELECT * FROM file_blobs
+WHERE checksum = $1;
+
+-- name: ReleaseFileBlob :exec
+UPDATE file_blobs
+SET ref_count = ref_count - 1, updated_at = NOW()
+WHERE checksum = $1 AND ref_count > 0;
+
+-- name: GetUnreferencedFileBlobs :many
+SELECT * FROM file_blobs
+WHERE ref_count = 0 AND updated_at < $1
+ORDER BY updated_at
+LIMIT $2;
+
+-- name: LockUnreferencedFileBlob :one
+SELECT * FROM file_blobs
+WHERE checksum = $1 AND ref_count = 0
+FOR UPDATE;
+
+-- name: DeleteFileBlob :exec
+DELETE FROM file_blobs
+WHERE checksum = $1;
+
+-- name: GetFileBlobsToVerify :many
+SELECT * FROM file_blobs
+WHERE ref_count > 0 AND stored AND (verified_at IS NULL OR verified_at < $1)
+ORDER BY verified_at NULLS FIRST
+LIMIT $2;
+
+-- name: MarkFileBlobVerified :exec
+UPDATE file_blobs
+SET verified_at = NOW(), corrupted = $2
+WHERE checksum = $1;
@@ -0,0 +1,19 @@
+-- name: UpsertFileGrant :one
+INSERT INTO file_grants (file_id, user_id, permission, granted_by)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (file_id, user_id) DO UPDATE
+SET permission = EXCLUDED.permission, granted_by = EXCLUDED.granted_by, updated_at = NOW()
+RETURNING *;
+
+-- name: GetFileGrant :one
+SELECT * FROM file_grants
+WHERE file_id = $1 AND user_id = $2 LIMIT 1;
+
+-- name: GetFileGrantsByFileID :many
+SELECT * FROM file_grants
+WHERE file_id = $1
+ORDER BY created_at, id;
+
+-- name: DeleteFileGrant :exec
+DELETE FROM file_grants
+WHERE file_id = $1 AND user_id = $2;
@@ -0,0 +1,31 @@
+-- name: CreateFileShareLink :one
+INSERT INTO file_share_links (file_id, created_by, token, password_hash, expires_at, max_downloads)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetFileShareLink :one
+SELECT * FROM file_share_links
+WHERE id = $1 LIMIT 1;
+
+-- name: GetFileShareLinkByToken :one
+SELECT * FROM file_share_links
+WHERE token = $1 LIMIT 1;
+
+-- name: GetFileShareLinksByFileID :many
+SELECT * FROM file_share_links
+WHERE file_id = $1
+ORDER BY created_at DESC, id DESC;
+
+-- name: UseFileShareLink :one
+UPDATE file_share_links
+SET download_count = download_count + 1, updated_at = NOW()
+WHERE id = $1
+  AND revoked_at IS NULL
+  AND (expires_at IS NULL OR expires_at > NOW())
+  AND (max_downloads IS NULL OR download_count < max_downloads)
+RETURNING *;
+
+-- name: RevokeFileShareLink :exec
+UPDATE file_share_links
+SET revoked_at = COALESCE(revoked_at, NOW()), updated_at = NOW()
+WHERE id = $1;
@@ -0,0 +1,48 @@
+-- name: CreateFileUpload :one
+INSERT INTO file_uploads (id, user_id, upload_length, metadata, original_name, mime_type, description, category, expires_at, folder_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING *;
+
+-- name: GetFileUpload :one
+SELECT * FROM file_uploads
+WHERE id = $1;
+
+-- name: AdvanceFileUploadOffset :one
+UPDATE file_uploads
+SET upload_offset = sqlc.arg(new_offset), hash_state = sqlc.arg(hash_state), expires_at = sqlc.arg(expires_at), updated_at = NOW()
+WHERE id = sqlc.arg(id) AND upload_offset = sqlc.arg(expected_offset)
+RETURNING *;
+
+-- name: CompleteFileUpload :one
+-- An upload is completed once; completing it again, e.g. from a concurrent request, matches no row
+UPDATE file_uploads
+SET file_id = $2, updated_at = NOW()
+WHERE id = $1 AND file_id IS NULL
+RETURNING *;
+
+-- name: DeleteFileUpload :exec
+DELETE FROM file_uploads
+WHERE id = $1;
+
+-- name: GetExpiredFileUploads :many
+SELECT * FROM file_uploads
+WHERE expires_at <= $1;
+
+-- name: CreateFileUploadChunk :one
+INSERT INTO file_upload_chunks (upload_id, chunk_offset, chunk_size, storage_key)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetFileUploadChunks :many
+SELECT * FROM file_upload_chunks
+WHERE upload_id = $1
+ORDER BY chunk_offset;
+
+-- name: GetFileUploadChunkKeysByUser :many
+SELECT c.storage_key FROM file_upload_chunks c
+JOIN file_uploads u ON u.id = c.upload_id
+WHERE u.user_id = $1;
+
+-- name: DeleteFileUploadChunks :exec
+DELETE FROM file_upload_chunks
+WHERE upload_id = $1;
@@ -0,0 +1,29 @@
+-- name: UpsertFileVariant :one
+INSERT INTO file_variants (file_id, name, storage_key, mime_type, width, height, file_size)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (file_id, name) DO UPDATE
+SET storage_key = EXCLUDED.storage_key,
+    mime_type = EXCLUDED.mime_type,
+    width = EXCLUDED.width,
+    height = EXCLUDED.height,
+    file_size = EXCLUDED.file_size,
+    created_at = NOW()
+RETURNING *;
+
+-- name: GetFileVariant :one
+SELECT * FROM file_variants
+WHERE file_id = $1 AND name = $2;
+
+-- name: GetFileVariantsByFileID :many
+SELECT * FROM file_variants
+WHERE file_id = $1
+ORDER BY name;
+
+-- name: GetFileVariantKeysByUser :many
+SELECT v.storage_key FROM file_variants v
+JOIN files f ON f.id = v.file_id
+WHERE f.uploaded_by = $1;
+
+-- name: DeleteFileVariantsByFileID :exec
+DELETE FROM file_variants
+WHERE file_id = $1;
@@ -0,0 +1,35 @@
+-- name: ArchiveFileVersion :one
+INSERT INTO file_versions (file_id, version, original_name, file_path, file_size, mime_type, checksum, scan_status, metadata, created_at)
+SELECT id, version, original_name, file_path, file_size, mime_type, checksum, scan_status, metadata, content_updated_at
+FROM files
+WHERE id = $1 AND version = $2
+FOR UPDATE
+RETURNING *;
+
+-- name: GetFileVersion :one
+SELECT * FROM file_versions
+WHERE file_id = $1 AND version = $2;
+
+-- name: GetFileVersionsByFileID :many
+SELECT * FROM file_versions
+WHERE file_id = $1
+ORDER BY version DESC;
+
+-- name: GetFileVersionsByUser :many
+SELECT v.* FROM file_versions v
+JOIN files f ON f.id = v.file_id
+WHERE f.uploaded_by = $1;
+
+-- name: SumFileVersionSizes :one
+SELECT COALESCE(SUM(file_size), 0)::bigint AS total_size FROM file_versions
+WHERE file_id = $1;
+
+-- name: DeleteFileVersionsUpTo :many
+DELETE FROM file_versions
+WHERE file_id = $1 AND version <= $2
+RETURNING *;
+
+-- name: UpdateFileVersionScanStatus :exec
+UPDATE file_versions
+SET scan_status = $3
+WHERE file_id = $1 AND file_path = $2 AND scan_status = 'pending';
@@ -1,12 +1,17 @@
 -- name: CreateFile :one
-INSERT INTO files (file_name, original_name, file_path, file_size, mime_type, description, category, uploaded_by)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+INSERT INTO files (file_name, original_name, file_path, file_size, mime_type, description, category, uploaded_by, checksum, scan_status, metadata, folder_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 RETURNING *;
 
 -- name: GetFile :one
 SELECT * FROM files
 WHERE id = $1 LIMIT 1;
 
+-- name: GetFileForUpdate :one
+SELECT * FROM files
+WHERE id = $1 LIMIT 1
+FOR UPDATE;
+
 -- name: GetFilesByUser :many
 SELECT * FROM files
 WHERE uploaded_by = $1
@@ -103,12 +108,124 @@ WHERE uploaded_by = $1
         OR description ILIKE '%' || sqlc.narg(search)::text || '%'
     );
 
+-- name: ListFilesWithCursor :many
+SELECT * FROM files
+WHERE 
+    (sqlc.narg(file_name_filter)::text IS NULL OR file_name ILIKE '%' || sqlc.narg(file_name_filter)::text || '%')
+    AND (sqlc.narg(mime_type_filter)::text IS NULL OR mime_type = sqlc.narg(mime_type_filter)::text)
+    AND (sqlc.narg(category_filter)::text IS NULL OR category = sqlc.narg(category_filter)::text)
+    AND (sqlc.narg(uploaded_by_filter)::integer IS NULL OR uploaded_by = sqlc.narg(uploaded_by_filter)::integer)
+    AND (sqlc.narg(created_after)::timestamp IS NULL OR created_at >= sqlc.narg(created_after)::timestamp)
+    AND (sqlc.narg(created_before)::timestamp IS NULL OR created_at <= sqlc.narg(created_before)::timestamp)
+    AND (
+        sqlc.narg(search)::text IS NULL 
+        OR file_name ILIKE '%' || sqlc.narg(search)::text || '%' 
+        OR original_name ILIKE '%' || sqlc.narg(search)::text || '%'
+        OR description ILIKE '%' || sqlc.narg(search)::text || '%'
+    )
+    AND (
+        sqlc.narg(cursor_id)::integer IS NULL
+        OR (@sort_field::text = 'id' AND @sort_order::text = 'ASC' AND id > sqlc.narg(cursor_id)::integer)
+        OR (@sort_field::text = 'id' AND @sort_order::text = 'DESC' AND id < sqlc.narg(cursor_id)::integer)
+        OR (@sort_field::text = 'file_name' AND @sort_order::text = 'ASC' AND (file_name, id) > (sqlc.narg(cursor_text)::text, sqlc.narg(cursor_id)::integer))
+        OR (@sort_field::text = 'file_name' AND @sort_order::text = 'DESC' AND (file_name, id) < (sqlc.narg(cursor_text)::text, sqlc.narg(cursor_id)::integer))
+        OR (@sort_field::text = 'file_size' AND @sort_order::text = 'ASC' AND (file_size, id) > (sqlc.narg(cursor_size)::bigint, sqlc.narg(cursor_id)::integer))
+        OR (@sort_field::text = 'file_size' AND @sort_order::text = 'DESC' AND (file_size, id) < (sqlc.narg(cursor_size)::bigint, sqlc.narg(cursor_id)::integer))
+        OR (@sort_field::text = 'created_at' AND @sort_order::text = 'ASC' AND (created_at, id) > (sqlc.narg(cursor_time)::timestamptz, sqlc.narg(cursor_id)::integer))
+        OR (@sort_field::text = 'created_at' AND @sort_order::text = 'DESC' AND (created_at, id) < (sqlc.narg(cursor_time)::timestamptz, sqlc.narg(cursor_id)::integer))
+    )
+ORDER BY
+    CASE WHEN @sort_field::text = 'file_name' AND @sort_order::text = 'ASC' THEN file_name END ASC,
+    CASE WHEN @sort_field::text = 'file_name' AND @sort_order::text = 'DESC' THEN file_name END DESC,
+    CASE WHEN @sort_field::text = 'file_size' AND @sort_order::text = 'ASC' THEN file_size END ASC,
+    CASE WHEN @sort_field::text = 'file_size' AND @sort_order::text = 'DESC' THEN file_size END DESC,
+    CASE WHEN @sort_field::text = 'created_at' AND @sort_order::text = 'ASC' THEN created_at END ASC,
+    CASE WHEN @sort_field::text = 'created_at' AND @sort_order::text = 'DESC' THEN created_at END DESC,
+    CASE WHEN @sort_order::text = 'ASC' THEN id END ASC,
+    CASE WHEN @sort_order::text = 'DESC' THEN id END DESC
+LIMIT $1;
+
+-- name: ListFilesByUserWithCursor :many
+SELECT * FROM files
+WHERE uploaded_by = $2
+    AND (sqlc.narg(file_name_filter)::text IS NULL OR file_name ILIKE '%' || sqlc.narg(file_name_filter)::text || '%')
+    AND (sqlc.narg(mime_type_filter)::text IS NULL OR mime_type = sqlc.narg(mime_type_filter)::text)
+    AND (sqlc.narg(category_filter)::text IS NULL OR category = sqlc.narg(category_filter)::text)
+    AND (sqlc.narg(created_after)::timestamp IS NULL OR created_at >= sqlc.narg(created_after)::timestamp)
+    AND (sqlc.narg(created_before)::timestamp IS NULL OR created_at <= sqlc.narg(created_before)::timestamp)
+    AND (
+        sqlc.narg(search)::text IS NULL 
+        OR file_name ILIKE '%' || sqlc.narg(search)::text || '%' 
+        OR original_name ILIKE '%' || sqlc.narg(search)::text || '%'
+        OR description ILIKE '%' || sqlc.narg(search)::text || '%'
+    )
+    AND (
+        sqlc.narg(cursor_id)::integer IS NULL
+        OR (@sort_field::text = 'id' AND @sort_order::text = 'ASC' AND id > sqlc.narg(cursor_id)::integer)
+        OR (@sort_field::text = 'id' AND @sort_order::text = 'DESC' AND id < sqlc.narg(cursor_id)::integer)
+        OR (@sort_field::text = 'file_name' AND @sort_order::text = 'ASC' AND (file_name, id) > (sqlc.narg(cursor_text)::text, sqlc.narg(cursor_id)::integer))
+        OR (@sort_field::text = 'file_name' AND @sort_order::text = 'DESC' AND (file_name, id) < (sqlc.narg(cursor_text)::text, sqlc.narg(cursor_id)::integer))
+        OR (@sort_field::text = 'file_size' AND @sort_order::text = 'ASC' AND (file_size, id) > (sqlc.narg(cursor_size)::bigint, sqlc.narg(cursor_id)::integer))
+        OR (@sort_field::text = 'file_size' AND @sort_order::text = 'DESC' AND (file_size, id) < (sqlc.narg(cursor_size)::bigint, sqlc.narg(cursor_id)::integer))
+        OR (@sort_field::text = 'created_at' AND @sort_order::text = 'ASC' AND (created_at, id) > (sqlc.narg(cursor_time)::timestamptz, sqlc.narg(cursor_id)::integer))
+        OR (@sort_field::text = 'created_at' AND @sort_order::text = 'DESC' AND (created_at, id) < (sqlc.narg(cursor_time)::timestamptz, sqlc.narg(cursor_id)::integer))
+    )
+ORDER BY
+    CASE WHEN @sort_field::text = 'file_name' AND @sort_order::text = 'ASC' THEN file_name END ASC,
+    CASE WHEN @sort_field::text = 'file_name' AND @sort_order::text = 'DESC' THEN file_name END DESC,
+    CASE WHEN @sort_field::text = 'file_size' AND @sort_order::text = 'ASC' THEN file_size END ASC,
+    CASE WHEN @sort_field::text = 'file_size' AND @sort_order::text = 'DESC' THEN file_size END DESC,
+    CASE WHEN @sort_field::text = 'created_at' AND @sort_order::text = 'ASC' THEN created_at END ASC,
+    CASE WHEN @sort_field::text = 'created_at' AND @sort_order::text = 'DESC' THEN created_at END DESC,
+    CASE WHEN @sort_order::text = 'ASC' THEN id END ASC,
+    CASE WHEN @sort_order::text = 'DESC' THEN id END DESC
+LIMIT $1;
+
 -- name: UpdateFile :one
 UPDATE files
 SET description = $2, category = $3, updated_at = NOW()
 WHERE id = $1
 RETURNING *;
 
+-- name: MoveFile :one
+UPDATE files
+SET folder_id = sqlc.narg(folder_id), updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: GetFilesInFolderTree :many
+SELECT fi.* FROM files fi
+JOIN folders d ON d.id = fi.folder_id
+JOIN folders f ON f.id = $1
+WHERE d.id = f.id OR d.path LIKE f.path || f.id || '/%'
+ORDER BY fi.id;
+
+-- name: GetFilesSharedWithUser :many
+SELECT fi.* FROM files fi
+JOIN file_grants g ON g.file_id = fi.id
+WHERE g.user_id = $1
+ORDER BY g.created_at DESC, fi.id DESC;
+
 -- name: DeleteFile :exec
 DELETE FROM files
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1;
+
+-- name: GetFilesPendingScan :many
+SELECT * FROM files
+WHERE scan_status = 'pending' AND created_at < $1
+ORDER BY created_at
+LIMIT $2;
+
+-- name: UpdateFileScanStatus :one
+UPDATE files
+SET scan_status = $2, scan_signature = $3, scanned_at = NOW()
+WHERE id = $1 AND file_path = $4
+RETURNING *;
+
+-- name: ReplaceFileContent :one
+UPDATE files
+SET original_name = $3, file_path = $4, file_size = $5, mime_type = $6, checksum = $7, scan_status = $8,
+    scan_signature = NULL, scanned_at = NULL, metadata = $9, version = version + 1,
+    content_updated_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND version = $2
+RETURNING *;
@@ -0,0 +1,56 @@
+-- name: CreateFolder :one
+INSERT INTO folders (user_id, parent_id, name, path)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetFolder :one
+SELECT sqlc.embed(f),
+    (SELECT COUNT(*) FROM folders d WHERE d.path LIKE f.path || f.id || '/%') AS folder_count,
+    (SELECT COUNT(*) FROM files fi JOIN folders d ON d.id = fi.folder_id
+     WHERE d.id = f.id OR d.path LIKE f.path || f.id || '/%') AS file_count,
+    (SELECT COALESCE(SUM(fi.file_size), 0) FROM files fi JOIN folders d ON d.id = fi.folder_id
+     WHERE d.id = f.id OR d.path LIKE f.path || f.id || '/%')::bigint AS total_size
+FROM folders f
+WHERE f.id = $1 LIMIT 1;
+
+-- name: GetFolderForUpdate :one
+SELECT * FROM folders
+WHERE id = $1 LIMIT 1
+FOR UPDATE;
+
+-- name: GetFoldersByParent :many
+SELECT sqlc.embed(f),
+    (SELECT COUNT(*) FROM folders d WHERE d.path LIKE f.path || f.id || '/%') AS folder_count,
+    (SELECT COUNT(*) FROM files fi JOIN folders d ON d.id = fi.folder_id
+     WHERE d.id = f.id OR d.path LIKE f.path || f.id || '/%') AS file_count,
+    (SELECT COALESCE(SUM(fi.file_size), 0) FROM files fi JOIN folders d ON d.id = fi.folder_id
+     WHERE d.id = f.id OR d.path LIKE f.path || f.id || '/%')::bigint AS total_size
+FROM folders f
+WHERE f.user_id = $1 AND f.parent_id IS NOT DISTINCT FROM sqlc.narg(parent_id)::integer
+ORDER BY LOWER(f.name);
+
+-- name: GetFolderByName :one
+SELECT * FROM folders
+WHERE user_id = $1 AND parent_id IS NOT DISTINCT FROM sqlc.narg(parent_id)::integer AND LOWER(name) = LOWER(sqlc.arg(name))
+LIMIT 1;
+
+-- name: RenameFolder :one
+UPDATE folders
+SET name = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: MoveFolder :one
+UPDATE folders
+SET parent_id = sqlc.narg(parent_id), path = sqlc.arg(path)::text, updated_at = NOW()
+WHERE id = sqlc.arg(id) AND sqlc.arg(path)::text NOT LIKE path || id || '/%'
+RETURNING *;
+
+-- name: MoveFolderDescendants :exec
+UPDATE folders
+SET path = sqlc.arg(new_prefix)::text || substr(path, length(sqlc.arg(old_prefix)::text) + 1)
+WHERE path LIKE sqlc.arg(old_prefix)::text || '%';
+
+-- name: DeleteFolder :exec
+DELETE FROM folders
+WHERE id = $1;
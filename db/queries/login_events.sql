@@ -0,0 +1,14 @@
+-- name: CreateLoginEvent :one
+INSERT INTO login_events (user_id, email, success, method, failure_reason, ip_address, user_agent)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: ListLoginEventsByUser :many
+SELECT * FROM login_events
+WHERE user_id = $1
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3;
+
+-- name: CountLoginEventsByUser :one
+SELECT COUNT(*) FROM login_events
+WHERE user_id = $1;
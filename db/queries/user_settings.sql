@@ -0,0 +1,19 @@
+-- name: GetUserSettings :many
+SELECT * FROM user_settings
+WHERE user_id = $1
+ORDER BY key;
+
+-- name: GetUserSetting :one
+SELECT * FROM user_settings
+WHERE user_id = $1 AND key = $2 LIMIT 1;
+
+-- name: UpsertUserSetting :one
+INSERT INTO user_settings (user_id, key, value)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, key) DO UPDATE
+SET value = EXCLUDED.value, updated_at = NOW()
+RETURNING *;
+
+-- name: DeleteUserSetting :exec
+DELETE FROM user_settings
+WHERE user_id = $1 AND key = $2;
@@ -0,0 +1,37 @@
+-- name: AddUserStorageUsageWithinQuota :one
+-- Overrides recorded for the user take precedence over the quotas of the role; usage that shrinks is
+-- always recorded
+UPDATE user_storage
+SET used_bytes = used_bytes + sqlc.arg(used_bytes),
+    file_count = file_count + sqlc.arg(file_count),
+    updated_at = NOW()
+WHERE user_id = sqlc.arg(user_id)
+  AND (sqlc.arg(used_bytes) <= 0 OR COALESCE(quota_bytes, sqlc.arg(role_quota_bytes)::bigint) = 0
+       OR used_bytes + sqlc.arg(used_bytes) <= COALESCE(quota_bytes, sqlc.arg(role_quota_bytes)::bigint))
+  AND (sqlc.arg(file_count) <= 0 OR COALESCE(quota_files, sqlc.arg(role_quota_files)::integer) = 0
+       OR file_count + sqlc.arg(file_count) <= COALESCE(quota_files, sqlc.arg(role_quota_files)::integer))
+RETURNING *;
+
+-- name: CreateUserStorage :exec
+INSERT INTO user_storage (user_id)
+VALUES ($1)
+ON CONFLICT (user_id) DO NOTHING;
+
+-- name: GetUserStorage :one
+SELECT * FROM user_storage
+WHERE user_id = $1 LIMIT 1;
+
+-- name: AddUserStorageUsage :exec
+INSERT INTO user_storage (user_id, used_bytes, file_count)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id) DO UPDATE
+SET used_bytes = user_storage.used_bytes + EXCLUDED.used_bytes,
+    file_count = user_storage.file_count + EXCLUDED.file_count,
+    updated_at = NOW();
+
+-- name: SetUserStorageQuota :one
+INSERT INTO user_storage (user_id, quota_bytes, quota_files)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id) DO UPDATE
+SET quota_bytes = EXCLUDED.quota_bytes, quota_files = EXCLUDED.quota_files, updated_at = NOW()
+RETURNING *;
@@ -34,6 +34,9 @@ WHERE
     AND (sqlc.narg(email_verified_filter)::boolean IS NULL OR email_verified = sqlc.narg(email_verified_filter)::boolean)
     AND (sqlc.narg(created_after)::timestamp IS NULL OR created_at >= sqlc.narg(created_after)::timestamp)
     AND (sqlc.narg(created_before)::timestamp IS NULL OR created_at <= sqlc.narg(created_before)::timestamp)
+    AND (sqlc.narg(last_login_after)::timestamp IS NULL OR last_login_at >= sqlc.narg(last_login_after)::timestamp)
+    AND (sqlc.narg(last_login_before)::timestamp IS NULL OR last_login_at <= sqlc.narg(last_login_before)::timestamp)
+    AND (sqlc.narg(never_logged_in)::boolean IS NULL OR (last_login_at IS NULL) = sqlc.narg(never_logged_in)::boolean)
     AND (
         sqlc.narg(search)::text IS NULL 
         OR name ILIKE '%' || sqlc.narg(search)::text || '%' 
@@ -50,6 +53,8 @@ ORDER BY
     CASE WHEN @sort_field::text = 'created_at' AND @sort_order::text = 'DESC' THEN created_at END DESC,
     CASE WHEN @sort_field::text = 'role' AND @sort_order::text = 'ASC' THEN role END ASC,
     CASE WHEN @sort_field::text = 'role' AND @sort_order::text = 'DESC' THEN role END DESC,
+    CASE WHEN @sort_field::text = 'last_login_at' AND @sort_order::text = 'ASC' THEN last_login_at END ASC NULLS FIRST,
+    CASE WHEN @sort_field::text = 'last_login_at' AND @sort_order::text = 'DESC' THEN last_login_at END DESC NULLS LAST,
     created_at DESC
 LIMIT $1 OFFSET $2;
 
@@ -65,6 +70,9 @@ WHERE
     AND (sqlc.narg(email_verified_filter)::boolean IS NULL OR email_verified = sqlc.narg(email_verified_filter)::boolean)
     AND (sqlc.narg(created_after)::timestamp IS NULL OR created_at >= sqlc.narg(created_after)::timestamp)
     AND (sqlc.narg(created_before)::timestamp IS NULL OR created_at <= sqlc.narg(created_before)::timestamp)
+    AND (sqlc.narg(last_login_after)::timestamp IS NULL OR last_login_at >= sqlc.narg(last_login_after)::timestamp)
+    AND (sqlc.narg(last_login_before)::timestamp IS NULL OR last_login_at <= sqlc.narg(last_login_before)::timestamp)
+    AND (sqlc.narg(never_logged_in)::boolean IS NULL OR (last_login_at IS NULL) = sqlc.narg(never_logged_in)::boolean)
     AND (
         sqlc.narg(search)::text IS NULL 
         OR name ILIKE '%' || sqlc.narg(search)::text || '%' 
@@ -121,6 +129,95 @@ SET password_reset_token = $2, password_reset_expires_at = $3, updated_at = NOW(
 WHERE id = $1;
 
 -- name: ResetPassword :exec
+-- The token was delivered to the address, so using it proves the user owns it, like a verification token
 UPDATE users
-SET password_hash = $2, password_reset_token = NULL, password_reset_expires_at = NULL, updated_at = NOW()
+SET password_hash = $2, password_reset_token = NULL, password_reset_expires_at = NULL,
+    email_verified = true, email_verification_token = NULL, email_verification_expires_at = NULL, updated_at = NOW()
 WHERE password_reset_token = $1;
+
+-- name: ScheduleUserDeletion :one
+UPDATE users
+SET deletion_requested_at = NOW(), deletion_scheduled_at = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: CancelUserDeletion :one
+UPDATE users
+SET deletion_requested_at = NULL, deletion_scheduled_at = NULL, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: GetUsersDueForDeletion :many
+SELECT * FROM users
+WHERE deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= $1
+ORDER BY deletion_scheduled_at ASC;
+
+-- name: UpdateUserProfile :one
+UPDATE users
+SET name = $2, display_name = $3, bio = $4, locale = $5, timezone = $6, phone = $7, metadata = $8, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: UpdateUserAvatar :one
+UPDATE users
+SET avatar_file_id = $2, avatar_file_name = $3, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: UpdateUserLastLogin :exec
+UPDATE users
+SET last_login_at = $2
+WHERE id = $1;
+
+-- name: ListInactiveUsers :many
+SELECT * FROM users
+WHERE COALESCE(last_login_at, created_at) < sqlc.arg(inactive_since)::timestamp
+ORDER BY COALESCE(last_login_at, created_at) ASC, id ASC
+LIMIT $1 OFFSET $2;
+
+-- name: CountInactiveUsers :one
+SELECT COUNT(*) FROM users
+WHERE COALESCE(last_login_at, created_at) < sqlc.arg(inactive_since)::timestamp;
+
+-- name: ListUsersWithCursor :many
+SELECT * FROM users
+WHERE 
+    (sqlc.narg(name_filter)::text IS NULL OR name ILIKE '%' || sqlc.narg(name_filter)::text || '%')
+    AND (sqlc.narg(email_filter)::text IS NULL OR email ILIKE '%' || sqlc.narg(email_filter)::text || '%') 
+    AND (sqlc.narg(role_filter)::text IS NULL OR role = sqlc.narg(role_filter)::text)
+    AND (sqlc.narg(email_verified_filter)::boolean IS NULL OR email_verified = sqlc.narg(email_verified_filter)::boolean)
+    AND (sqlc.narg(created_after)::timestamp IS NULL OR created_at >= sqlc.narg(created_after)::timestamp)
+    AND (sqlc.narg(created_before)::timestamp IS NULL OR created_at <= sqlc.narg(created_before)::timestamp)
+    AND (sqlc.narg(last_login_after)::timestamp IS NULL OR last_login_at >= sqlc.narg(last_login_after)::timestamp)
+    AND (sqlc.narg(last_login_before)::timestamp IS NULL OR last_login_at <= sqlc.narg(last_login_before)::timestamp)
+    AND (sqlc.narg(never_logged_in)::boolean IS NULL OR (last_login_at IS NULL) = sqlc.narg(never_logged_in)::boolean)
+    AND (
+        sqlc.narg(search)::text IS NULL 
+        OR name ILIKE '%' || sqlc.narg(search)::text || '%' 
+        OR email ILIKE '%' || sqlc.narg(search)::text || '%'
+    )
+    AND (
+        sqlc.narg(cursor_id)::integer IS NULL
+        OR (@sort_field::text = 'id' AND @sort_order::text = 'ASC' AND id > sqlc.narg(cursor_id)::integer)
+        OR (@sort_field::text = 'id' AND @sort_order::text = 'DESC' AND id < sqlc.narg(cursor_id)::integer)
+        OR (@sort_field::text = 'name' AND @sort_order::text = 'ASC' AND (name, id) > (sqlc.narg(cursor_text)::text, sqlc.narg(cursor_id)::integer))
+        OR (@sort_field::text = 'name' AND @sort_order::text = 'DESC' AND (name, id) < (sqlc.narg(cursor_text)::text, sqlc.narg(cursor_id)::integer))
+        OR (@sort_field::text = 'email' AND @sort_order::text = 'ASC' AND (email, id) > (sqlc.narg(cursor_text)::text, sqlc.narg(cursor_id)::integer))
+        OR (@sort_field::text = 'email' AND @sort_order::text = 'DESC' AND (email, id) < (sqlc.narg(cursor_text)::text, sqlc.narg(cursor_id)::integer))
+        OR (@sort_field::text = 'role' AND @sort_order::text = 'ASC' AND (role, id) > (sqlc.narg(cursor_text)::text, sqlc.narg(cursor_id)::integer))
+        OR (@sort_field::text = 'role' AND @sort_order::text = 'DESC' AND (role, id) < (sqlc.narg(cursor_text)::text, sqlc.narg(cursor_id)::integer))
+        OR (@sort_field::text = 'created_at' AND @sort_order::text = 'ASC' AND (created_at, id) > (sqlc.narg(cursor_time)::timestamptz, sqlc.narg(cursor_id)::integer))
+        OR (@sort_field::text = 'created_at' AND @sort_order::text = 'DESC' AND (created_at, id) < (sqlc.narg(cursor_time)::timestamptz, sqlc.narg(cursor_id)::integer))
+    )
+ORDER BY
+    CASE WHEN @sort_field::text = 'name' AND @sort_order::text = 'ASC' THEN name END ASC,
+    CASE WHEN @sort_field::text = 'name' AND @sort_order::text = 'DESC' THEN name END DESC,
+    CASE WHEN @sort_field::text = 'email' AND @sort_order::text = 'ASC' THEN email END ASC,
+    CASE WHEN @sort_field::text = 'email' AND @sort_order::text = 'DESC' THEN email END DESC,
+    CASE WHEN @sort_field::text = 'role' AND @sort_order::text = 'ASC' THEN role END ASC,
+    CASE WHEN @sort_field::text = 'role' AND @sort_order::text = 'DESC' THEN role END DESC,
+    CASE WHEN @sort_field::text = 'created_at' AND @sort_order::text = 'ASC' THEN created_at END ASC,
+    CASE WHEN @sort_field::text = 'created_at' AND @sort_order::text = 'DESC' THEN created_at END DESC,
+    CASE WHEN @sort_order::text = 'ASC' THEN id END ASC,
+    CASE WHEN @sort_order::text = 'DESC' THEN id END DESC
+LIMIT $1;
@@ -24,6 +24,30 @@ func New(db DBTX) *Queries {
 func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	q := Queries{db: db}
 	var err error
+	if q.acquireFileBlobStmt, err = db.PrepareContext(ctx, acquireFileBlob); err != nil {
+		return nil, fmt.Errorf("error preparing query AcquireFileBlob: %w", err)
+	}
+	if q.addUserStorageUsageStmt, err = db.PrepareContext(ctx, addUserStorageUsage); err != nil {
+		return nil, fmt.Errorf("error preparing query AddUserStorageUsage: %w", err)
+	}
+	if q.addUserStorageUsageWithinQuotaStmt, err = db.PrepareContext(ctx, addUserStorageUsageWithinQuota); err != nil {
+		return nil, fmt.Errorf("error preparing query AddUserStorageUsageWithinQuota: %w", err)
+	}
+	if q.advanceFileUploadOffsetStmt, err = db.PrepareContext(ctx, advanceFileUploadOffset); err != nil {
+		return nil, fmt.Errorf("error preparing query AdvanceFileUploadOffset: %w", err)
+	}
+	if q.archiveFileVersionStmt, err = db.PrepareContext(ctx, archiveFileVersion); err != nil {
+		return nil, fmt.Errorf("error preparing query ArchiveFileVersion: %w", err)
+	}
+	if q.cancelUserDeletionStmt, err = db.PrepareContext(ctx, cancelUserDeletion); err != nil {
+		return nil, fmt.Errorf("error preparing query CancelUserDeletion: %w", err)
+	}
+	if q.completeDataExportStmt, err = db.PrepareContext(ctx, completeDataExport); err != nil {
+		return nil, fmt.Errorf("error preparing query CompleteDataExport: %w", err)
+	}
+	if q.completeFileUploadStmt, err = db.PrepareContext(ctx, completeFileUpload); err != nil {
+		return nil, fmt.Errorf("error preparing query CompleteFileUpload: %w", err)
+	}
 	if q.countFilesStmt, err = db.PrepareContext(ctx, countFiles); err != nil {
 		return nil, fmt.Errorf("error preparing query CountFiles: %w", err)
 	}
@@ -33,27 +57,87 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.countFilesWithFiltersStmt, err = db.PrepareContext(ctx, countFilesWithFilters); err != nil {
 		return nil, fmt.Errorf("error preparing query CountFilesWithFilters: %w", err)
 	}
+	if q.countInactiveUsersStmt, err = db.PrepareContext(ctx, countInactiveUsers); err != nil {
+		return nil, fmt.Errorf("error preparing query CountInactiveUsers: %w", err)
+	}
+	if q.countLoginEventsByUserStmt, err = db.PrepareContext(ctx, countLoginEventsByUser); err != nil {
+		return nil, fmt.Errorf("error preparing query CountLoginEventsByUser: %w", err)
+	}
 	if q.countUsersStmt, err = db.PrepareContext(ctx, countUsers); err != nil {
 		return nil, fmt.Errorf("error preparing query CountUsers: %w", err)
 	}
 	if q.countUsersWithFiltersStmt, err = db.PrepareContext(ctx, countUsersWithFilters); err != nil {
 		return nil, fmt.Errorf("error preparing query CountUsersWithFilters: %w", err)
 	}
+	if q.createDataExportStmt, err = db.PrepareContext(ctx, createDataExport); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateDataExport: %w", err)
+	}
 	if q.createFileStmt, err = db.PrepareContext(ctx, createFile); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateFile: %w", err)
 	}
+	if q.createFileShareLinkStmt, err = db.PrepareContext(ctx, createFileShareLink); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateFileShareLink: %w", err)
+	}
+	if q.createFileUploadStmt, err = db.PrepareContext(ctx, createFileUpload); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateFileUpload: %w", err)
+	}
+	if q.createFileUploadChunkStmt, err = db.PrepareContext(ctx, createFileUploadChunk); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateFileUploadChunk: %w", err)
+	}
+	if q.createFolderStmt, err = db.PrepareContext(ctx, createFolder); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateFolder: %w", err)
+	}
+	if q.createLoginEventStmt, err = db.PrepareContext(ctx, createLoginEvent); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateLoginEvent: %w", err)
+	}
 	if q.createUserStmt, err = db.PrepareContext(ctx, createUser); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateUser: %w", err)
 	}
+	if q.createUserStorageStmt, err = db.PrepareContext(ctx, createUserStorage); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateUserStorage: %w", err)
+	}
 	if q.createUserWithPasswordStmt, err = db.PrepareContext(ctx, createUserWithPassword); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateUserWithPassword: %w", err)
 	}
+	if q.deleteDataExportStmt, err = db.PrepareContext(ctx, deleteDataExport); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteDataExport: %w", err)
+	}
 	if q.deleteFileStmt, err = db.PrepareContext(ctx, deleteFile); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteFile: %w", err)
 	}
+	if q.deleteFileBlobStmt, err = db.PrepareContext(ctx, deleteFileBlob); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteFileBlob: %w", err)
+	}
+	if q.deleteFileGrantStmt, err = db.PrepareContext(ctx, deleteFileGrant); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteFileGrant: %w", err)
+	}
+	if q.deleteFileUploadStmt, err = db.PrepareContext(ctx, deleteFileUpload); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteFileUpload: %w", err)
+	}
+	if q.deleteFileUploadChunksStmt, err = db.PrepareContext(ctx, deleteFileUploadChunks); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteFileUploadChunks: %w", err)
+	}
+	if q.deleteFileVariantsByFileIDStmt, err = db.PrepareContext(ctx, deleteFileVariantsByFileID); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteFileVariantsByFileID: %w", err)
+	}
+	if q.deleteFileVersionsUpToStmt, err = db.PrepareContext(ctx, deleteFileVersionsUpTo); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteFileVersionsUpTo: %w", err)
+	}
+	if q.deleteFolderStmt, err = db.PrepareContext(ctx, deleteFolder); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteFolder: %w", err)
+	}
 	if q.deleteUserStmt, err = db.PrepareContext(ctx, deleteUser); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteUser: %w", err)
 	}
+	if q.deleteUserSettingStmt, err = db.PrepareContext(ctx, deleteUserSetting); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteUserSetting: %w", err)
+	}
+	if q.failDataExportStmt, err = db.PrepareContext(ctx, failDataExport); err != nil {
+		return nil, fmt.Errorf("error preparing query FailDataExport: %w", err)
+	}
+	if q.failStaleDataExportsStmt, err = db.PrepareContext(ctx, failStaleDataExports); err != nil {
+		return nil, fmt.Errorf("error preparing query FailStaleDataExports: %w", err)
+	}
 	if q.getAllFilesStmt, err = db.PrepareContext(ctx, getAllFiles); err != nil {
 		return nil, fmt.Errorf("error preparing query GetAllFiles: %w", err)
 	}
@@ -63,15 +147,108 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.getAllUsersStmt, err = db.PrepareContext(ctx, getAllUsers); err != nil {
 		return nil, fmt.Errorf("error preparing query GetAllUsers: %w", err)
 	}
+	if q.getDataExportStmt, err = db.PrepareContext(ctx, getDataExport); err != nil {
+		return nil, fmt.Errorf("error preparing query GetDataExport: %w", err)
+	}
+	if q.getDataExportByTokenStmt, err = db.PrepareContext(ctx, getDataExportByToken); err != nil {
+		return nil, fmt.Errorf("error preparing query GetDataExportByToken: %w", err)
+	}
+	if q.getDataExportsByUserStmt, err = db.PrepareContext(ctx, getDataExportsByUser); err != nil {
+		return nil, fmt.Errorf("error preparing query GetDataExportsByUser: %w", err)
+	}
+	if q.getExpiredDataExportsStmt, err = db.PrepareContext(ctx, getExpiredDataExports); err != nil {
+		return nil, fmt.Errorf("error preparing query GetExpiredDataExports: %w", err)
+	}
+	if q.getExpiredFileUploadsStmt, err = db.PrepareContext(ctx, getExpiredFileUploads); err != nil {
+		return nil, fmt.Errorf("error preparing query GetExpiredFileUploads: %w", err)
+	}
 	if q.getFileStmt, err = db.PrepareContext(ctx, getFile); err != nil {
 		return nil, fmt.Errorf("error preparing query GetFile: %w", err)
 	}
+	if q.getFileBlobStmt, err = db.PrepareContext(ctx, getFileBlob); err != nil {
+		return nil, fmt.Errorf("error preparing query GetFileBlob: %w", err)
+	}
+	if q.getFileBlobsToVerifyStmt, err = db.PrepareContext(ctx, getFileBlobsToVerify); err != nil {
+		return nil, fmt.Errorf("error preparing query GetFileBlobsToVerify: %w", err)
+	}
+	if q.getFileForUpdateStmt, err = db.PrepareContext(ctx, getFileForUpdate); err != nil {
+		return nil, fmt.Errorf("error preparing query GetFileForUpdate: %w", err)
+	}
+	if q.getFileGrantStmt, err = db.PrepareContext(ctx, getFileGrant); err != nil {
+		return nil, fmt.Errorf("error preparing query GetFileGrant: %w", err)
+	}
+	if q.getFileGrantsByFileIDStmt, err = db.PrepareContext(ctx, getFileGrantsByFileID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetFileGrantsByFileID: %w", err)
+	}
+	if q.getFileShareLinkStmt, err = db.PrepareContext(ctx, getFileShareLink); err != nil {
+		return nil, fmt.Errorf("error preparing query GetFileShareLink: %w", err)
+	}
+	if q.getFileShareLinkByTokenStmt, err = db.PrepareContext(ctx, getFileShareLinkByToken); err != nil {
+		return nil, fmt.Errorf("error preparing query GetFileShareLinkByToken: %w", err)
+	}
+	if q.getFileShareLinksByFileIDStmt, err = db.PrepareContext(ctx, getFileShareLinksByFileID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetFileShareLinksByFileID: %w", err)
+	}
+	if q.getFileUploadStmt, err = db.PrepareContext(ctx, getFileUpload); err != nil {
+		return nil, fmt.Errorf("error preparing query GetFileUpload: %w", err)
+	}
+	if q.getFileUploadChunkKeysByUserStmt, err = db.PrepareContext(ctx, getFileUploadChunkKeysByUser); err != nil {
+		return nil, fmt.Errorf("error preparing query GetFileUploadChunkKeysByUser: %w", err)
+	}
+	if q.getFileUploadChunksStmt, err = db.PrepareContext(ctx, getFileUploadChunks); err != nil {
+		return nil, fmt.Errorf("error preparing query GetFileUploadChunks: %w", err)
+	}
+	if q.getFileVariantStmt, err = db.PrepareContext(ctx, getFileVariant); err != nil {
+		return nil, fmt.Errorf("error preparing query GetFileVariant: %w", err)
+	}
+	if q.getFileVariantKeysByUserStmt, err = db.PrepareContext(ctx, getFileVariantKeysByUser); err != nil {
+		return nil, fmt.Errorf("error preparing query GetFileVariantKeysByUser: %w", err)
+	}
+	if q.getFileVariantsByFileIDStmt, err = db.PrepareContext(ctx, getFileVariantsByFileID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetFileVariantsByFileID: %w", err)
+	}
+	if q.getFileVersionStmt, err = db.PrepareContext(ctx, getFileVersion); err != nil {
+		return nil, fmt.Errorf("error preparing query GetFileVersion: %w", err)
+	}
+	if q.getFileVersionsByFileIDStmt, err = db.PrepareContext(ctx, getFileVersionsByFileID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetFileVersionsByFileID: %w", err)
+	}
+	if q.getFileVersionsByUserStmt, err = db.PrepareContext(ctx, getFileVersionsByUser); err != nil {
+		return nil, fmt.Errorf("error preparing query GetFileVersionsByUser: %w", err)
+	}
 	if q.getFilesByUserStmt, err = db.PrepareContext(ctx, getFilesByUser); err != nil {
 		return nil, fmt.Errorf("error preparing query GetFilesByUser: %w", err)
 	}
 	if q.getFilesByUserWithPaginationStmt, err = db.PrepareContext(ctx, getFilesByUserWithPagination); err != nil {
 		return nil, fmt.Errorf("error preparing query GetFilesByUserWithPagination: %w", err)
 	}
+	if q.getFilesInFolderTreeStmt, err = db.PrepareContext(ctx, getFilesInFolderTree); err != nil {
+		return nil, fmt.Errorf("error preparing query GetFilesInFolderTree: %w", err)
+	}
+	if q.getFilesPendingScanStmt, err = db.PrepareContext(ctx, getFilesPendingScan); err != nil {
+		return nil, fmt.Errorf("error preparing query GetFilesPendingScan: %w", err)
+	}
+	if q.getFilesSharedWithUserStmt, err = db.PrepareContext(ctx, getFilesSharedWithUser); err != nil {
+		return nil, fmt.Errorf("error preparing query GetFilesSharedWithUser: %w", err)
+	}
+	if q.getFolderStmt, err = db.PrepareContext(ctx, getFolder); err != nil {
+		return nil, fmt.Errorf("error preparing query GetFolder: %w", err)
+	}
+	if q.getFolderByNameStmt, err = db.PrepareContext(ctx, getFolderByName); err != nil {
+		return nil, fmt.Errorf("error preparing query GetFolderByName: %w", err)
+	}
+	if q.getFolderForUpdateStmt, err = db.PrepareContext(ctx, getFolderForUpdate); err != nil {
+		return nil, fmt.Errorf("error preparing query GetFolderForUpdate: %w", err)
+	}
+	if q.getFoldersByParentStmt, err = db.PrepareContext(ctx, getFoldersByParent); err != nil {
+		return nil, fmt.Errorf("error preparing query GetFoldersByParent: %w", err)
+	}
+	if q.getPendingDataExportByUserStmt, err = db.PrepareContext(ctx, getPendingDataExportByUser); err != nil {
+		return nil, fmt.Errorf("error preparing query GetPendingDataExportByUser: %w", err)
+	}
+	if q.getUnreferencedFileBlobsStmt, err = db.PrepareContext(ctx, getUnreferencedFileBlobs); err != nil {
+		return nil, fmt.Errorf("error preparing query GetUnreferencedFileBlobs: %w", err)
+	}
 	if q.getUserStmt, err = db.PrepareContext(ctx, getUser); err != nil {
 		return nil, fmt.Errorf("error preparing query GetUser: %w", err)
 	}
@@ -87,15 +264,84 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.getUserByVerificationTokenStmt, err = db.PrepareContext(ctx, getUserByVerificationToken); err != nil {
 		return nil, fmt.Errorf("error preparing query GetUserByVerificationToken: %w", err)
 	}
+	if q.getUserSettingStmt, err = db.PrepareContext(ctx, getUserSetting); err != nil {
+		return nil, fmt.Errorf("error preparing query GetUserSetting: %w", err)
+	}
+	if q.getUserSettingsStmt, err = db.PrepareContext(ctx, getUserSettings); err != nil {
+		return nil, fmt.Errorf("error preparing query GetUserSettings: %w", err)
+	}
+	if q.getUserStorageStmt, err = db.PrepareContext(ctx, getUserStorage); err != nil {
+		return nil, fmt.Errorf("error preparing query GetUserStorage: %w", err)
+	}
+	if q.getUsersDueForDeletionStmt, err = db.PrepareContext(ctx, getUsersDueForDeletion); err != nil {
+		return nil, fmt.Errorf("error preparing query GetUsersDueForDeletion: %w", err)
+	}
+	if q.listFilesByUserWithCursorStmt, err = db.PrepareContext(ctx, listFilesByUserWithCursor); err != nil {
+		return nil, fmt.Errorf("error preparing query ListFilesByUserWithCursor: %w", err)
+	}
+	if q.listFilesWithCursorStmt, err = db.PrepareContext(ctx, listFilesWithCursor); err != nil {
+		return nil, fmt.Errorf("error preparing query ListFilesWithCursor: %w", err)
+	}
+	if q.listInactiveUsersStmt, err = db.PrepareContext(ctx, listInactiveUsers); err != nil {
+		return nil, fmt.Errorf("error preparing query ListInactiveUsers: %w", err)
+	}
+	if q.listLoginEventsByUserStmt, err = db.PrepareContext(ctx, listLoginEventsByUser); err != nil {
+		return nil, fmt.Errorf("error preparing query ListLoginEventsByUser: %w", err)
+	}
 	if q.listUsersStmt, err = db.PrepareContext(ctx, listUsers); err != nil {
 		return nil, fmt.Errorf("error preparing query ListUsers: %w", err)
 	}
+	if q.listUsersWithCursorStmt, err = db.PrepareContext(ctx, listUsersWithCursor); err != nil {
+		return nil, fmt.Errorf("error preparing query ListUsersWithCursor: %w", err)
+	}
 	if q.listUsersWithPaginationAndFiltersStmt, err = db.PrepareContext(ctx, listUsersWithPaginationAndFilters); err != nil {
 		return nil, fmt.Errorf("error preparing query ListUsersWithPaginationAndFilters: %w", err)
 	}
+	if q.lockUnreferencedFileBlobStmt, err = db.PrepareContext(ctx, lockUnreferencedFileBlob); err != nil {
+		return nil, fmt.Errorf("error preparing query LockUnreferencedFileBlob: %w", err)
+	}
+	if q.markDataExportProcessingStmt, err = db.PrepareContext(ctx, markDataExportProcessing); err != nil {
+		return nil, fmt.Errorf("error preparing query MarkDataExportProcessing: %w", err)
+	}
+	if q.markFileBlobStoredStmt, err = db.PrepareContext(ctx, markFileBlobStored); err != nil {
+		return nil, fmt.Errorf("error preparing query MarkFileBlobStored: %w", err)
+	}
+	if q.markFileBlobVerifiedStmt, err = db.PrepareContext(ctx, markFileBlobVerified); err != nil {
+		return nil, fmt.Errorf("error preparing query MarkFileBlobVerified: %w", err)
+	}
+	if q.moveFileStmt, err = db.PrepareContext(ctx, moveFile); err != nil {
+		return nil, fmt.Errorf("error preparing query MoveFile: %w", err)
+	}
+	if q.moveFolderStmt, err = db.PrepareContext(ctx, moveFolder); err != nil {
+		return nil, fmt.Errorf("error preparing query MoveFolder: %w", err)
+	}
+	if q.moveFolderDescendantsStmt, err = db.PrepareContext(ctx, moveFolderDescendants); err != nil {
+		return nil, fmt.Errorf("error preparing query MoveFolderDescendants: %w", err)
+	}
+	if q.releaseFileBlobStmt, err = db.PrepareContext(ctx, releaseFileBlob); err != nil {
+		return nil, fmt.Errorf("error preparing query ReleaseFileBlob: %w", err)
+	}
+	if q.renameFolderStmt, err = db.PrepareContext(ctx, renameFolder); err != nil {
+		return nil, fmt.Errorf("error preparing query RenameFolder: %w", err)
+	}
+	if q.replaceFileContentStmt, err = db.PrepareContext(ctx, replaceFileContent); err != nil {
+		return nil, fmt.Errorf("error preparing query ReplaceFileContent: %w", err)
+	}
 	if q.resetPasswordStmt, err = db.PrepareContext(ctx, resetPassword); err != nil {
 		return nil, fmt.Errorf("error preparing query ResetPassword: %w", err)
 	}
+	if q.revokeFileShareLinkStmt, err = db.PrepareContext(ctx, revokeFileShareLink); err != nil {
+		return nil, fmt.Errorf("error preparing query RevokeFileShareLink: %w", err)
+	}
+	if q.scheduleUserDeletionStmt, err = db.PrepareContext(ctx, scheduleUserDeletion); err != nil {
+		return nil, fmt.Errorf("error preparing query ScheduleUserDeletion: %w", err)
+	}
+	if q.setUserStorageQuotaStmt, err = db.PrepareContext(ctx, setUserStorageQuota); err != nil {
+		return nil, fmt.Errorf("error preparing query SetUserStorageQuota: %w", err)
+	}
+	if q.sumFileVersionSizesStmt, err = db.PrepareContext(ctx, sumFileVersionSizes); err != nil {
+		return nil, fmt.Errorf("error preparing query SumFileVersionSizes: %w", err)
+	}
 	if q.updateEmailVerificationStmt, err = db.PrepareContext(ctx, updateEmailVerification); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateEmailVerification: %w", err)
 	}
@@ -105,15 +351,42 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.updateFileStmt, err = db.PrepareContext(ctx, updateFile); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateFile: %w", err)
 	}
+	if q.updateFileScanStatusStmt, err = db.PrepareContext(ctx, updateFileScanStatus); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateFileScanStatus: %w", err)
+	}
+	if q.updateFileVersionScanStatusStmt, err = db.PrepareContext(ctx, updateFileVersionScanStatus); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateFileVersionScanStatus: %w", err)
+	}
 	if q.updatePasswordResetTokenStmt, err = db.PrepareContext(ctx, updatePasswordResetToken); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdatePasswordResetToken: %w", err)
 	}
 	if q.updateUserStmt, err = db.PrepareContext(ctx, updateUser); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateUser: %w", err)
 	}
+	if q.updateUserAvatarStmt, err = db.PrepareContext(ctx, updateUserAvatar); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateUserAvatar: %w", err)
+	}
+	if q.updateUserLastLoginStmt, err = db.PrepareContext(ctx, updateUserLastLogin); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateUserLastLogin: %w", err)
+	}
+	if q.updateUserProfileStmt, err = db.PrepareContext(ctx, updateUserProfile); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateUserProfile: %w", err)
+	}
 	if q.updateVerificationTokenStmt, err = db.PrepareContext(ctx, updateVerificationToken); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateVerificationToken: %w", err)
 	}
+	if q.upsertFileGrantStmt, err = db.PrepareContext(ctx, upsertFileGrant); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertFileGrant: %w", err)
+	}
+	if q.upsertFileVariantStmt, err = db.PrepareContext(ctx, upsertFileVariant); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertFileVariant: %w", err)
+	}
+	if q.upsertUserSettingStmt, err = db.PrepareContext(ctx, upsertUserSetting); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertUserSetting: %w", err)
+	}
+	if q.useFileShareLinkStmt, err = db.PrepareContext(ctx, useFileShareLink); err != nil {
+		return nil, fmt.Errorf("error preparing query UseFileShareLink: %w", err)
+	}
 	if q.verifyEmailByTokenStmt, err = db.PrepareContext(ctx, verifyEmailByToken); err != nil {
 		return nil, fmt.Errorf("error preparing query VerifyEmailByToken: %w", err)
 	}
@@ -122,6 +395,46 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 
 func (q *Queries) Close() error {
 	var err error
+	if q.acquireFileBlobStmt != nil {
+		if cerr := q.acquireFileBlobStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing acquireFileBlobStmt: %w", cerr)
+		}
+	}
+	if q.addUserStorageUsageStmt != nil {
+		if cerr := q.addUserStorageUsageStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing addUserStorageUsageStmt: %w", cerr)
+		}
+	}
+	if q.addUserStorageUsageWithinQuotaStmt != nil {
+		if cerr := q.addUserStorageUsageWithinQuotaStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing addUserStorageUsageWithinQuotaStmt: %w", cerr)
+		}
+	}
+	if q.advanceFileUploadOffsetStmt != nil {
+		if cerr := q.advanceFileUploadOffsetStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing advanceFileUploadOffsetStmt: %w", cerr)
+		}
+	}
+	if q.archiveFileVersionStmt != nil {
+		if cerr := q.archiveFileVersionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing archiveFileVersionStmt: %w", cerr)
+		}
+	}
+	if q.cancelUserDeletionStmt != nil {
+		if cerr := q.cancelUserDeletionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing cancelUserDeletionStmt: %w", cerr)
+		}
+	}
+	if q.completeDataExportStmt != nil {
+		if cerr := q.completeDataExportStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing completeDataExportStmt: %w", cerr)
+		}
+	}
+	if q.completeFileUploadStmt != nil {
+		if cerr := q.completeFileUploadStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing completeFileUploadStmt: %w", cerr)
+		}
+	}
 	if q.countFilesStmt != nil {
 		if cerr := q.countFilesStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing countFilesStmt: %w", cerr)
@@ -137,6 +450,16 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing countFilesWithFiltersStmt: %w", cerr)
 		}
 	}
+	if q.countInactiveUsersStmt != nil {
+		if cerr := q.countInactiveUsersStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countInactiveUsersStmt: %w", cerr)
+		}
+	}
+	if q.countLoginEventsByUserStmt != nil {
+		if cerr := q.countLoginEventsByUserStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countLoginEventsByUserStmt: %w", cerr)
+		}
+	}
 	if q.countUsersStmt != nil {
 		if cerr := q.countUsersStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing countUsersStmt: %w", cerr)
@@ -147,31 +470,121 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing countUsersWithFiltersStmt: %w", cerr)
 		}
 	}
+	if q.createDataExportStmt != nil {
+		if cerr := q.createDataExportStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createDataExportStmt: %w", cerr)
+		}
+	}
 	if q.createFileStmt != nil {
 		if cerr := q.createFileStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createFileStmt: %w", cerr)
 		}
 	}
+	if q.createFileShareLinkStmt != nil {
+		if cerr := q.createFileShareLinkStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createFileShareLinkStmt: %w", cerr)
+		}
+	}
+	if q.createFileUploadStmt != nil {
+		if cerr := q.createFileUploadStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createFileUploadStmt: %w", cerr)
+		}
+	}
+	if q.createFileUploadChunkStmt != nil {
+		if cerr := q.createFileUploadChunkStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createFileUploadChunkStmt: %w", cerr)
+		}
+	}
+	if q.createFolderStmt != nil {
+		if cerr := q.createFolderStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createFolderStmt: %w", cerr)
+		}
+	}
+	if q.createLoginEventStmt != nil {
+		if cerr := q.createLoginEventStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createLoginEventStmt: %w", cerr)
+		}
+	}
 	if q.createUserStmt != nil {
 		if cerr := q.createUserStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createUserStmt: %w", cerr)
 		}
 	}
+	if q.createUserStorageStmt != nil {
+		if cerr := q.createUserStorageStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createUserStorageStmt: %w", cerr)
+		}
+	}
 	if q.createUserWithPasswordStmt != nil {
 		if cerr := q.createUserWithPasswordStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createUserWithPasswordStmt: %w", cerr)
 		}
 	}
+	if q.deleteDataExportStmt != nil {
+		if cerr := q.deleteDataExportStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteDataExportStmt: %w", cerr)
+		}
+	}
 	if q.deleteFileStmt != nil {
 		if cerr := q.deleteFileStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing deleteFileStmt: %w", cerr)
 		}
 	}
+	if q.deleteFileBlobStmt != nil {
+		if cerr := q.deleteFileBlobStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteFileBlobStmt: %w", cerr)
+		}
+	}
+	if q.deleteFileGrantStmt != nil {
+		if cerr := q.deleteFileGrantStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteFileGrantStmt: %w", cerr)
+		}
+	}
+	if q.deleteFileUploadStmt != nil {
+		if cerr := q.deleteFileUploadStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteFileUploadStmt: %w", cerr)
+		}
+	}
+	if q.deleteFileUploadChunksStmt != nil {
+		if cerr := q.deleteFileUploadChunksStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteFileUploadChunksStmt: %w", cerr)
+		}
+	}
+	if q.deleteFileVariantsByFileIDStmt != nil {
+		if cerr := q.deleteFileVariantsByFileIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteFileVariantsByFileIDStmt: %w", cerr)
+		}
+	}
+	if q.deleteFileVersionsUpToStmt != nil {
+		if cerr := q.deleteFileVersionsUpToStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteFileVersionsUpToStmt: %w", cerr)
+		}
+	}
+	if q.deleteFolderStmt != nil {
+		if cerr := q.deleteFolderStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteFolderStmt: %w", cerr)
+		}
+	}
 	if q.deleteUserStmt != nil {
 		if cerr := q.deleteUserStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing deleteUserStmt: %w", cerr)
 		}
 	}
+	if q.deleteUserSettingStmt != nil {
+		if cerr := q.deleteUserSettingStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteUserSettingStmt: %w", cerr)
+		}
+	}
+	if q.failDataExportStmt != nil {
+		if cerr := q.failDataExportStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing failDataExportStmt: %w", cerr)
+		}
+	}
+	if q.failStaleDataExportsStmt != nil {
+		if cerr := q.failStaleDataExportsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing failStaleDataExportsStmt: %w", cerr)
+		}
+	}
 	if q.getAllFilesStmt != nil {
 		if cerr := q.getAllFilesStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getAllFilesStmt: %w", cerr)
@@ -187,11 +600,121 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getAllUsersStmt: %w", cerr)
 		}
 	}
+	if q.getDataExportStmt != nil {
+		if cerr := q.getDataExportStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getDataExportStmt: %w", cerr)
+		}
+	}
+	if q.getDataExportByTokenStmt != nil {
+		if cerr := q.getDataExportByTokenStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getDataExportByTokenStmt: %w", cerr)
+		}
+	}
+	if q.getDataExportsByUserStmt != nil {
+		if cerr := q.getDataExportsByUserStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getDataExportsByUserStmt: %w", cerr)
+		}
+	}
+	if q.getExpiredDataExportsStmt != nil {
+		if cerr := q.getExpiredDataExportsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getExpiredDataExportsStmt: %w", cerr)
+		}
+	}
+	if q.getExpiredFileUploadsStmt != nil {
+		if cerr := q.getExpiredFileUploadsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getExpiredFileUploadsStmt: %w", cerr)
+		}
+	}
 	if q.getFileStmt != nil {
 		if cerr := q.getFileStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getFileStmt: %w", cerr)
 		}
 	}
+	if q.getFileBlobStmt != nil {
+		if cerr := q.getFileBlobStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getFileBlobStmt: %w", cerr)
+		}
+	}
+	if q.getFileBlobsToVerifyStmt != nil {
+		if cerr := q.getFileBlobsToVerifyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getFileBlobsToVerifyStmt: %w", cerr)
+		}
+	}
+	if q.getFileForUpdateStmt != nil {
+		if cerr := q.getFileForUpdateStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getFileForUpdateStmt: %w", cerr)
+		}
+	}
+	if q.getFileGrantStmt != nil {
+		if cerr := q.getFileGrantStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getFileGrantStmt: %w", cerr)
+		}
+	}
+	if q.getFileGrantsByFileIDStmt != nil {
+		if cerr := q.getFileGrantsByFileIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getFileGrantsByFileIDStmt: %w", cerr)
+		}
+	}
+	if q.getFileShareLinkStmt != nil {
+		if cerr := q.getFileShareLinkStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getFileShareLinkStmt: %w", cerr)
+		}
+	}
+	if q.getFileShareLinkByTokenStmt != nil {
+		if cerr := q.getFileShareLinkByTokenStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getFileShareLinkByTokenStmt: %w", cerr)
+		}
+	}
+	if q.getFileShareLinksByFileIDStmt != nil {
+		if cerr := q.getFileShareLinksByFileIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getFileShareLinksByFileIDStmt: %w", cerr)
+		}
+	}
+	if q.getFileUploadStmt != nil {
+		if cerr := q.getFileUploadStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getFileUploadStmt: %w", cerr)
+		}
+	}
+	if q.getFileUploadChunkKeysByUserStmt != nil {
+		if cerr := q.getFileUploadChunkKeysByUserStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getFileUploadChunkKeysByUserStmt: %w", cerr)
+		}
+	}
+	if q.getFileUploadChunksStmt != nil {
+		if cerr := q.getFileUploadChunksStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getFileUploadChunksStmt: %w", cerr)
+		}
+	}
+	if q.getFileVariantStmt != nil {
+		if cerr := q.getFileVariantStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getFileVariantStmt: %w", cerr)
+		}
+	}
+	if q.getFileVariantKeysByUserStmt != nil {
+		if cerr := q.getFileVariantKeysByUserStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getFileVariantKeysByUserStmt: %w", cerr)
+		}
+	}
+	if q.getFileVariantsByFileIDStmt != nil {
+		if cerr := q.getFileVariantsByFileIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getFileVariantsByFileIDStmt: %w", cerr)
+		}
+	}
+	if q.getFileVersionStmt != nil {
+		if cerr := q.getFileVersionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getFileVersionStmt: %w", cerr)
+		}
+	}
+	if q.getFileVersionsByFileIDStmt != nil {
+		if cerr := q.getFileVersionsByFileIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getFileVersionsByFileIDStmt: %w", cerr)
+		}
+	}
+	if q.getFileVersionsByUserStmt != nil {
+		if cerr := q.getFileVersionsByUserStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getFileVersionsByUserStmt: %w", cerr)
+		}
+	}
 	if q.getFilesByUserStmt != nil {
 		if cerr := q.getFilesByUserStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getFilesByUserStmt: %w", cerr)
@@ -202,6 +725,51 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getFilesByUserWithPaginationStmt: %w", cerr)
 		}
 	}
+	if q.getFilesInFolderTreeStmt != nil {
+		if cerr := q.getFilesInFolderTreeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getFilesInFolderTreeStmt: %w", cerr)
+		}
+	}
+	if q.getFilesPendingScanStmt != nil {
+		if cerr := q.getFilesPendingScanStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getFilesPendingScanStmt: %w", cerr)
+		}
+	}
+	if q.getFilesSharedWithUserStmt != nil {
+		if cerr := q.getFilesSharedWithUserStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getFilesSharedWithUserStmt: %w", cerr)
+		}
+	}
+	if q.getFolderStmt != nil {
+		if cerr := q.getFolderStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getFolderStmt: %w", cerr)
+		}
+	}
+	if q.getFolderByNameStmt != nil {
+		if cerr := q.getFolderByNameStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getFolderByNameStmt: %w", cerr)
+		}
+	}
+	if q.getFolderForUpdateStmt != nil {
+		if cerr := q.getFolderForUpdateStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getFolderForUpdateStmt: %w", cerr)
+		}
+	}
+	if q.getFoldersByParentStmt != nil {
+		if cerr := q.getFoldersByParentStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getFoldersByParentStmt: %w", cerr)
+		}
+	}
+	if q.getPendingDataExportByUserStmt != nil {
+		if cerr := q.getPendingDataExportByUserStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getPendingDataExportByUserStmt: %w", cerr)
+		}
+	}
+	if q.getUnreferencedFileBlobsStmt != nil {
+		if cerr := q.getUnreferencedFileBlobsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getUnreferencedFileBlobsStmt: %w", cerr)
+		}
+	}
 	if q.getUserStmt != nil {
 		if cerr := q.getUserStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getUserStmt: %w", cerr)
@@ -227,21 +795,136 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getUserByVerificationTokenStmt: %w", cerr)
 		}
 	}
+	if q.getUserSettingStmt != nil {
+		if cerr := q.getUserSettingStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getUserSettingStmt: %w", cerr)
+		}
+	}
+	if q.getUserSettingsStmt != nil {
+		if cerr := q.getUserSettingsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getUserSettingsStmt: %w", cerr)
+		}
+	}
+	if q.getUserStorageStmt != nil {
+		if cerr := q.getUserStorageStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getUserStorageStmt: %w", cerr)
+		}
+	}
+	if q.getUsersDueForDeletionStmt != nil {
+		if cerr := q.getUsersDueForDeletionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getUsersDueForDeletionStmt: %w", cerr)
+		}
+	}
+	if q.listFilesByUserWithCursorStmt != nil {
+		if cerr := q.listFilesByUserWithCursorStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listFilesByUserWithCursorStmt: %w", cerr)
+		}
+	}
+	if q.listFilesWithCursorStmt != nil {
+		if cerr := q.listFilesWithCursorStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listFilesWithCursorStmt: %w", cerr)
+		}
+	}
+	if q.listInactiveUsersStmt != nil {
+		if cerr := q.listInactiveUsersStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listInactiveUsersStmt: %w", cerr)
+		}
+	}
+	if q.listLoginEventsByUserStmt != nil {
+		if cerr := q.listLoginEventsByUserStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listLoginEventsByUserStmt: %w", cerr)
+		}
+	}
 	if q.listUsersStmt != nil {
 		if cerr := q.listUsersStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listUsersStmt: %w", cerr)
 		}
 	}
+	if q.listUsersWithCursorStmt != nil {
+		if cerr := q.listUsersWithCursorStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listUsersWithCursorStmt: %w", cerr)
+		}
+	}
 	if q.listUsersWithPaginationAndFiltersStmt != nil {
 		if cerr := q.listUsersWithPaginationAndFiltersStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listUsersWithPaginationAndFiltersStmt: %w", cerr)
 		}
 	}
+	if q.lockUnreferencedFileBlobStmt != nil {
+		if cerr := q.lockUnreferencedFileBlobStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing lockUnreferencedFileBlobStmt: %w", cerr)
+		}
+	}
+	if q.markDataExportProcessingStmt != nil {
+		if cerr := q.markDataExportProcessingStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing markDataExportProcessingStmt: %w", cerr)
+		}
+	}
+	if q.markFileBlobStoredStmt != nil {
+		if cerr := q.markFileBlobStoredStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing markFileBlobStoredStmt: %w", cerr)
+		}
+	}
+	if q.markFileBlobVerifiedStmt != nil {
+		if cerr := q.markFileBlobVerifiedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing markFileBlobVerifiedStmt: %w", cerr)
+		}
+	}
+	if q.moveFileStmt != nil {
+		if cerr := q.moveFileStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing moveFileStmt: %w", cerr)
+		}
+	}
+	if q.moveFolderStmt != nil {
+		if cerr := q.moveFolderStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing moveFolderStmt: %w", cerr)
+		}
+	}
+	if q.moveFolderDescendantsStmt != nil {
+		if cerr := q.moveFolderDescendantsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing moveFolderDescendantsStmt: %w", cerr)
+		}
+	}
+	if q.releaseFileBlobStmt != nil {
+		if cerr := q.releaseFileBlobStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing releaseFileBlobStmt: %w", cerr)
+		}
+	}
+	if q.renameFolderStmt != nil {
+		if cerr := q.renameFolderStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing renameFolderStmt: %w", cerr)
+		}
+	}
+	if q.replaceFileContentStmt != nil {
+		if cerr := q.replaceFileContentStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing replaceFileContentStmt: %w", cerr)
+		}
+	}
 	if q.resetPasswordStmt != nil {
 		if cerr := q.resetPasswordStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing resetPasswordStmt: %w", cerr)
 		}
 	}
+	if q.revokeFileShareLinkStmt != nil {
+		if cerr := q.revokeFileShareLinkStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing revokeFileShareLinkStmt: %w", cerr)
+		}
+	}
+	if q.scheduleUserDeletionStmt != nil {
+		if cerr := q.scheduleUserDeletionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing scheduleUserDeletionStmt: %w", cerr)
+		}
+	}
+	if q.setUserStorageQuotaStmt != nil {
+		if cerr := q.setUserStorageQuotaStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing setUserStorageQuotaStmt: %w", cerr)
+		}
+	}
+	if q.sumFileVersionSizesStmt != nil {
+		if cerr := q.sumFileVersionSizesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing sumFileVersionSizesStmt: %w", cerr)
+		}
+	}
 	if q.updateEmailVerificationStmt != nil {
 		if cerr := q.updateEmailVerificationStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing updateEmailVerificationStmt: %w", cerr)
@@ -257,6 +940,16 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing updateFileStmt: %w", cerr)
 		}
 	}
+	if q.updateFileScanStatusStmt != nil {
+		if cerr := q.updateFileScanStatusStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateFileScanStatusStmt: %w", cerr)
+		}
+	}
+	if q.updateFileVersionScanStatusStmt != nil {
+		if cerr := q.updateFileVersionScanStatusStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateFileVersionScanStatusStmt: %w", cerr)
+		}
+	}
 	if q.updatePasswordResetTokenStmt != nil {
 		if cerr := q.updatePasswordResetTokenStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing updatePasswordResetTokenStmt: %w", cerr)
@@ -267,11 +960,46 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing updateUserStmt: %w", cerr)
 		}
 	}
+	if q.updateUserAvatarStmt != nil {
+		if cerr := q.updateUserAvatarStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateUserAvatarStmt: %w", cerr)
+		}
+	}
+	if q.updateUserLastLoginStmt != nil {
+		if cerr := q.updateUserLastLoginStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateUserLastLoginStmt: %w", cerr)
+		}
+	}
+	if q.updateUserProfileStmt != nil {
+		if cerr := q.updateUserProfileStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateUserProfileStmt: %w", cerr)
+		}
+	}
 	if q.updateVerificationTokenStmt != nil {
 		if cerr := q.updateVerificationTokenStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing updateVerificationTokenStmt: %w", cerr)
 		}
 	}
+	if q.upsertFileGrantStmt != nil {
+		if cerr := q.upsertFileGrantStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertFileGrantStmt: %w", cerr)
+		}
+	}
+	if q.upsertFileVariantStmt != nil {
+		if cerr := q.upsertFileVariantStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertFileVariantStmt: %w", cerr)
+		}
+	}
+	if q.upsertUserSettingStmt != nil {
+		if cerr := q.upsertUserSettingStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertUserSettingStmt: %w", cerr)
+		}
+	}
+	if q.useFileShareLinkStmt != nil {
+		if cerr := q.useFileShareLinkStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing useFileShareLinkStmt: %w", cerr)
+		}
+	}
 	if q.verifyEmailByTokenStmt != nil {
 		if cerr := q.verifyEmailByTokenStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing verifyEmailByTokenStmt: %w", cerr)
@@ -316,36 +1044,127 @@ func (q *Queries) queryRow(ctx context.Context, stmt *sql.Stmt, query string, ar
 type Queries struct {
 	db                                      DBTX
 	tx                                      *sql.Tx
+	acquireFileBlobStmt                     *sql.Stmt
+	addUserStorageUsageStmt                 *sql.Stmt
+	addUserStorageUsageWithinQuotaStmt      *sql.Stmt
+	advanceFileUploadOffsetStmt             *sql.Stmt
+	archiveFileVersionStmt                  *sql.Stmt
+	cancelUserDeletionStmt                  *sql.Stmt
+	completeDataExportStmt                  *sql.Stmt
+	completeFileUploadStmt                  *sql.Stmt
 	countFilesStmt                          *sql.Stmt
 	countFilesByUserStmt                    *sql.Stmt
 	countFilesWithFiltersStmt               *sql.Stmt
+	countInactiveUsersStmt                  *sql.Stmt
+	countLoginEventsByUserStmt              *sql.Stmt
 	countUsersStmt                          *sql.Stmt
 	countUsersWithFiltersStmt               *sql.Stmt
+	createDataExportStmt                    *sql.Stmt
 	createFileStmt                          *sql.Stmt
+	createFileShareLinkStmt                 *sql.Stmt
+	createFileUploadStmt                    *sql.Stmt
+	createFileUploadChunkStmt               *sql.Stmt
+	createFolderStmt                        *sql.Stmt
+	createLoginEventStmt                    *sql.Stmt
 	createUserStmt                          *sql.Stmt
+	createUserStorageStmt                   *sql.Stmt
 	createUserWithPasswordStmt              *sql.Stmt
+	deleteDataExportStmt                    *sql.Stmt
 	deleteFileStmt                          *sql.Stmt
+	deleteFileBlobStmt                      *sql.Stmt
+	deleteFileGrantStmt                     *sql.Stmt
+	deleteFileUploadStmt                    *sql.Stmt
+	deleteFileUploadChunksStmt              *sql.Stmt
+	deleteFileVariantsByFileIDStmt          *sql.Stmt
+	deleteFileVersionsUpToStmt              *sql.Stmt
+	deleteFolderStmt                        *sql.Stmt
 	deleteUserStmt                          *sql.Stmt
+	deleteUserSettingStmt                   *sql.Stmt
+	failDataExportStmt                      *sql.Stmt
+	failStaleDataExportsStmt                *sql.Stmt
 	getAllFilesStmt                         *sql.Stmt
 	getAllFilesWithPaginationAndFiltersStmt *sql.Stmt
 	getAllUsersStmt                         *sql.Stmt
+	getDataExportStmt                       *sql.Stmt
+	getDataExportByTokenStmt                *sql.Stmt
+	getDataExportsByUserStmt                *sql.Stmt
+	getExpiredDataExportsStmt               *sql.Stmt
+	getExpiredFileUploadsStmt               *sql.Stmt
 	getFileStmt                             *sql.Stmt
+	getFileBlobStmt                         *sql.Stmt
+	getFileBlobsToVerifyStmt                *sql.Stmt
+	getFileForUpdateStmt                    *sql.Stmt
+	getFileGrantStmt                        *sql.Stmt
+	getFileGrantsByFileIDStmt               *sql.Stmt
+	getFileShareLinkStmt                    *sql.Stmt
+	getFileShareLinkByTokenStmt             *sql.Stmt
+	getFileShareLinksByFileIDStmt           *sql.Stmt
+	getFileUploadStmt                       *sql.Stmt
+	getFileUploadChunkKeysByUserStmt        *sql.Stmt
+	getFileUploadChunksStmt                 *sql.Stmt
+	getFileVariantStmt                      *sql.Stmt
+	getFileVariantKeysByUserStmt            *sql.Stmt
+	getFileVariantsByFileIDStmt             *sql.Stmt
+	getFileVersionStmt                      *sql.Stmt
+	getFileVersionsByFileIDStmt             *sql.Stmt
+	getFileVersionsByUserStmt               *sql.Stmt
 	getFilesByUserStmt                      *sql.Stmt
 	getFilesByUserWithPaginationStmt        *sql.Stmt
+	getFilesInFolderTreeStmt                *sql.Stmt
+	getFilesPendingScanStmt                 *sql.Stmt
+	getFilesSharedWithUserStmt              *sql.Stmt
+	getFolderStmt                           *sql.Stmt
+	getFolderByNameStmt                     *sql.Stmt
+	getFolderForUpdateStmt                  *sql.Stmt
+	getFoldersByParentStmt                  *sql.Stmt
+	getPendingDataExportByUserStmt          *sql.Stmt
+	getUnreferencedFileBlobsStmt            *sql.Stmt
 	getUserStmt                             *sql.Stmt
 	getUserByEmailStmt                      *sql.Stmt
 	getUserByEmailWithPasswordStmt          *sql.Stmt
 	getUserByPasswordResetTokenStmt         *sql.Stmt
 	getUserByVerificationTokenStmt          *sql.Stmt
+	getUserSettingStmt                      *sql.Stmt
+	getUserSettingsStmt                     *sql.Stmt
+	getUserStorageStmt                      *sql.Stmt
+	getUsersDueForDeletionStmt              *sql.Stmt
+	listFilesByUserWithCursorStmt           *sql.Stmt
+	listFilesWithCursorStmt                 *sql.Stmt
+	listInactiveUsersStmt                   *sql.Stmt
+	listLoginEventsByUserStmt               *sql.Stmt
 	listUsersStmt                           *sql.Stmt
+	listUsersWithCursorStmt                 *sql.Stmt
 	listUsersWithPaginationAndFiltersStmt   *sql.Stmt
+	lockUnreferencedFileBlobStmt            *sql.Stmt
+	markDataExportProcessingStmt            *sql.Stmt
+	markFileBlobStoredStmt                  *sql.Stmt
+	markFileBlobVerifiedStmt                *sql.Stmt
+	moveFileStmt                            *sql.Stmt
+	moveFolderStmt                          *sql.Stmt
+	moveFolderDescendantsStmt               *sql.Stmt
+	releaseFileBlobStmt                     *sql.Stmt
+	renameFolderStmt                        *sql.Stmt
+	replaceFileContentStmt                  *sql.Stmt
 	resetPasswordStmt                       *sql.Stmt
+	revokeFileShareLinkStmt                 *sql.Stmt
+	scheduleUserDeletionStmt                *sql.Stmt
+	setUserStorageQuotaStmt                 *sql.Stmt
+	sumFileVersionSizesStmt                 *sql.Stmt
 	updateEmailVerificationStmt             *sql.Stmt
 	updateEmailVerificationTokenStmt        *sql.Stmt
 	updateFileStmt                          *sql.Stmt
+	updateFileScanStatusStmt                *sql.Stmt
+	updateFileVersionScanStatusStmt         *sql.Stmt
 	updatePasswordResetTokenStmt            *sql.Stmt
 	updateUserStmt                          *sql.Stmt
+	updateUserAvatarStmt                    *sql.Stmt
+	updateUserLastLoginStmt                 *sql.Stmt
+	updateUserProfileStmt                   *sql.Stmt
 	updateVerificationTokenStmt             *sql.Stmt
+	upsertFileGrantStmt                     *sql.Stmt
+	upsertFileVariantStmt                   *sql.Stmt
+	upsertUserSettingStmt                   *sql.Stmt
+	useFileShareLinkStmt                    *sql.Stmt
 	verifyEmailByTokenStmt                  *sql.Stmt
 }
 
@@ -353,36 +1172,127 @@ func (q *Queries) WithTx(tx *sql.Tx) *Queries {
 	return &Queries{
 		db:                                      tx,
 		tx:                                      tx,
+		acquireFileBlobStmt:                     q.acquireFileBlobStmt,
+		addUserStorageUsageStmt:                 q.addUserStorageUsageStmt,
+		addUserStorageUsageWithinQuotaStmt:      q.addUserStorageUsageWithinQuotaStmt,
+		advanceFileUploadOffsetStmt:             q.advanceFileUploadOffsetStmt,
+		archiveFileVersionStmt:                  q.archiveFileVersionStmt,
+		cancelUserDeletionStmt:                  q.cancelUserDeletionStmt,
+		completeDataExportStmt:                  q.completeDataExportStmt,
+		completeFileUploadStmt:                  q.completeFileUploadStmt,
 		countFilesStmt:                          q.countFilesStmt,
 		countFilesByUserStmt:                    q.countFilesByUserStmt,
 		countFilesWithFiltersStmt:               q.countFilesWithFiltersStmt,
+		countInactiveUsersStmt:                  q.countInactiveUsersStmt,
+		countLoginEventsByUserStmt:              q.countLoginEventsByUserStmt,
 		countUsersStmt:                          q.countUsersStmt,
 		countUsersWithFiltersStmt:               q.countUsersWithFiltersStmt,
+		createDataExportStmt:                    q.createDataExportStmt,
 		createFileStmt:                          q.createFileStmt,
+		createFileShareLinkStmt:                 q.createFileShareLinkStmt,
+		createFileUploadStmt:                    q.createFileUploadStmt,
+		createFileUploadChunkStmt:               q.createFileUploadChunkStmt,
+		createFolderStmt:                        q.createFolderStmt,
+		createLoginEventStmt:                    q.createLoginEventStmt,
 		createUserStmt:                          q.createUserStmt,
+		createUserStorageStmt:                   q.createUserStorageStmt,
 		createUserWithPasswordStmt:              q.createUserWithPasswordStmt,
+		deleteDataExportStmt:                    q.deleteDataExportStmt,
 		deleteFileStmt:                          q.deleteFileStmt,
+		deleteFileBlobStmt:                      q.deleteFileBlobStmt,
+		deleteFileGrantStmt:                     q.deleteFileGrantStmt,
+		deleteFileUploadStmt:                    q.deleteFileUploadStmt,
+		deleteFileUploadChunksStmt:              q.deleteFileUploadChunksStmt,
+		deleteFileVariantsByFileIDStmt:          q.deleteFileVariantsByFileIDStmt,
+		deleteFileVersionsUpToStmt:              q.deleteFileVersionsUpToStmt,
+		deleteFolderStmt:                        q.deleteFolderStmt,
 		deleteUserStmt:                          q.deleteUserStmt,
+		deleteUserSettingStmt:                   q.deleteUserSettingStmt,
+		failDataExportStmt:                      q.failDataExportStmt,
+		failStaleDataExportsStmt:                q.failStaleDataExportsStmt,
 		getAllFilesStmt:                         q.getAllFilesStmt,
 		getAllFilesWithPaginationAndFiltersStmt: q.getAllFilesWithPaginationAndFiltersStmt,
 		getAllUsersStmt:                         q.getAllUsersStmt,
+		getDataExportStmt:                       q.getDataExportStmt,
+		getDataExportByTokenStmt:                q.getDataExportByTokenStmt,
+		getDataExportsByUserStmt:                q.getDataExportsByUserStmt,
+		getExpiredDataExportsStmt:               q.getExpiredDataExportsStmt,
+		getExpiredFileUploadsStmt:               q.getExpiredFileUploadsStmt,
 		getFileStmt:                             q.getFileStmt,
+		getFileBlobStmt:                         q.getFileBlobStmt,
+		getFileBlobsToVerifyStmt:                q.getFileBlobsToVerifyStmt,
+		getFileForUpdateStmt:                    q.getFileForUpdateStmt,
+		getFileGrantStmt:                        q.getFileGrantStmt,
+		getFileGrantsByFileIDStmt:               q.getFileGrantsByFileIDStmt,
+		getFileShareLinkStmt:                    q.getFileShareLinkStmt,
+		getFileShareLinkByTokenStmt:             q.getFileShareLinkByTokenStmt,
+		getFileShareLinksByFileIDStmt:           q.getFileShareLinksByFileIDStmt,
+		getFileUploadStmt:                       q.getFileUploadStmt,
+		getFileUploadChunkKeysByUserStmt:        q.getFileUploadChunkKeysByUserStmt,
+		getFileUploadChunksStmt:                 q.getFileUploadChunksStmt,
+		getFileVariantStmt:                      q.getFileVariantStmt,
+		getFileVariantKeysByUserStmt:            q.getFileVariantKeysByUserStmt,
+		getFileVariantsByFileIDStmt:             q.getFileVariantsByFileIDStmt,
+		getFileVersionStmt:                      q.getFileVersionStmt,
+		getFileVersionsByFileIDStmt:             q.getFileVersionsByFileIDStmt,
+		getFileVersionsByUserStmt:               q.getFileVersionsByUserStmt,
 		getFilesByUserStmt:                      q.getFilesByUserStmt,
 		getFilesByUserWithPaginationStmt:        q.getFilesByUserWithPaginationStmt,
+		getFilesInFolderTreeStmt:                q.getFilesInFolderTreeStmt,
+		getFilesPendingScanStmt:                 q.getFilesPendingScanStmt,
+		getFilesSharedWithUserStmt:              q.getFilesSharedWithUserStmt,
+		getFolderStmt:                           q.getFolderStmt,
+		getFolderByNameStmt:                     q.getFolderByNameStmt,
+		getFolderForUpdateStmt:                  q.getFolderForUpdateStmt,
+		getFoldersByParentStmt:                  q.getFoldersByParentStmt,
+		getPendingDataExportByUserStmt:          q.getPendingDataExportByUserStmt,
+		getUnreferencedFileBlobsStmt:            q.getUnreferencedFileBlobsStmt,
 		getUserStmt:                             q.getUserStmt,
 		getUserByEmailStmt:                      q.getUserByEmailStmt,
 		getUserByEmailWithPasswordStmt:          q.getUserByEmailWithPasswordStmt,
 		getUserByPasswordResetTokenStmt:         q.getUserByPasswordResetTokenStmt,
 		getUserByVerificationTokenStmt:          q.getUserByVerificationTokenStmt,
+		getUserSettingStmt:                      q.getUserSettingStmt,
+		getUserSettingsStmt:                     q.getUserSettingsStmt,
+		getUserStorageStmt:                      q.getUserStorageStmt,
+		getUsersDueForDeletionStmt:              q.getUsersDueForDeletionStmt,
+		listFilesByUserWithCursorStmt:           q.listFilesByUserWithCursorStmt,
+		listFilesWithCursorStmt:                 q.listFilesWithCursorStmt,
+		listInactiveUsersStmt:                   q.listInactiveUsersStmt,
+		listLoginEventsByUserStmt:               q.listLoginEventsByUserStmt,
 		listUsersStmt:                           q.listUsersStmt,
+		listUsersWithCursorStmt:                 q.listUsersWithCursorStmt,
 		listUsersWithPaginationAndFiltersStmt:   q.listUsersWithPaginationAndFiltersStmt,
+		lockUnreferencedFileBlobStmt:            q.lockUnreferencedFileBlobStmt,
+		markDataExportProcessingStmt:            q.markDataExportProcessingStmt,
+		markFileBlobStoredStmt:                  q.markFileBlobStoredStmt,
+		markFileBlobVerifiedStmt:                q.markFileBlobVerifiedStmt,
+		moveFileStmt:                            q.moveFileStmt,
+		moveFolderStmt:                          q.moveFolderStmt,
+		moveFolderDescendantsStmt:               q.moveFolderDescendantsStmt,
+		releaseFileBlobStmt:                     q.releaseFileBlobStmt,
+		renameFolderStmt:                        q.renameFolderStmt,
+		replaceFileContentStmt:                  q.replaceFileContentStmt,
 		resetPasswordStmt:                       q.resetPasswordStmt,
+		revokeFileShareLinkStmt:                 q.revokeFileShareLinkStmt,
+		scheduleUserDeletionStmt:                q.scheduleUserDeletionStmt,
+		setUserStorageQuotaStmt:                 q.setUserStorageQuotaStmt,
+		sumFileVersionSizesStmt:                 q.sumFileVersionSizesStmt,
 		updateEmailVerificationStmt:             q.updateEmailVerificationStmt,
 		updateEmailVerificationTokenStmt:        q.updateEmailVerificationTokenStmt,
 		updateFileStmt:                          q.updateFileStmt,
+		updateFileScanStatusStmt:                q.updateFileScanStatusStmt,
+		updateFileVersionScanStatusStmt:         q.updateFileVersionScanStatusStmt,
 		updatePasswordResetTokenStmt:            q.updatePasswordResetTokenStmt,
 		updateUserStmt:                          q.updateUserStmt,
+		updateUserAvatarStmt:                    q.updateUserAvatarStmt,
+		updateUserLastLoginStmt:                 q.updateUserLastLoginStmt,
+		updateUserProfileStmt:                   q.updateUserProfileStmt,
 		updateVerificationTokenStmt:             q.updateVerificationTokenStmt,
+		upsertFileGrantStmt:                     q.upsertFileGrantStmt,
+		upsertFileVariantStmt:                   q.upsertFileVariantStmt,
+		upsertUserSettingStmt:                   q.upsertUserSettingStmt,
+		useFileShareLinkStmt:                    q.useFileShareLinkStmt,
 		verifyEmailByTokenStmt:                  q.verifyEmailByTokenStmt,
 	}
 }
@@ -8,6 +8,7 @@ package database
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 )
 
 const countFiles = `-- name: CountFiles :one
@@ -105,20 +106,24 @@ func (q *Queries) CountFilesWithFilters(ctx context.Context, arg CountFilesWithF
 }
 
 const createFile = `-- name: CreateFile :one
-INSERT INTO files (file_name, original_name, file_path, file_size, mime_type, description, category, uploaded_by)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-RETURNING id, file_name, original_name, file_path, file_size, mime_type, description, category, uploaded_by, created_at, updated_at
+INSERT INTO files (file_name, original_name, file_path, file_size, mime_type, description, category, uploaded_by, checksum, scan_status, metadata, folder_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+RETURNING id, file_name, original_name, file_path, file_size, mime_type, description, category, uploaded_by, created_at, updated_at, checksum, scan_status, scan_signature, scanned_at, metadata, version, content_updated_at, folder_id
 `
 
 type CreateFileParams struct {
-	FileName     string         `db:"file_name" json:"file_name"`
-	OriginalName string         `db:"original_name" json:"original_name"`
-	FilePath     string         `db:"file_path" json:"file_path"`
-	FileSize     int64          `db:"file_size" json:"file_size"`
-	MimeType     string         `db:"mime_type" json:"mime_type"`
-	Description  sql.NullString `db:"description" json:"description"`
-	Category     sql.NullString `db:"category" json:"category"`
-	UploadedBy   int32          `db:"uploaded_by" json:"uploaded_by"`
+	FileName     string          `db:"file_name" json:"file_name"`
+	OriginalName string          `db:"original_name" json:"original_name"`
+	FilePath     string          `db:"file_path" json:"file_path"`
+	FileSize     int64           `db:"file_size" json:"file_size"`
+	MimeType     string          `db:"mime_type" json:"mime_type"`
+	Description  sql.NullString  `db:"description" json:"description"`
+	Category     sql.NullString  `db:"category" json:"category"`
+	UploadedBy   int32           `db:"uploaded_by" json:"uploaded_by"`
+	Checksum     sql.NullString  `db:"checksum" json:"checksum"`
+	ScanStatus   string          `db:"scan_status" json:"scan_status"`
+	Metadata     json.RawMessage `db:"metadata" json:"metadata"`
+	FolderID     sql.NullInt32   `db:"folder_id" json:"folder_id"`
 }
 
 func (q *Queries) CreateFile(ctx context.Context, arg CreateFileParams) (Files, error) {
@@ -131,6 +136,10 @@ func (q *Queries) CreateFile(ctx context.Context, arg CreateFileParams) (Files,
 		arg.Description,
 		arg.Category,
 		arg.UploadedBy,
+		arg.Checksum,
+		arg.ScanStatus,
+		arg.Metadata,
+		arg.FolderID,
 	)
 	var i Files
 	err := row.Scan(
@@ -145,6 +154,14 @@ func (q *Queries) CreateFile(ctx context.Context, arg CreateFileParams) (Files,
 		&i.UploadedBy,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Checksum,
+		&i.ScanStatus,
+		&i.ScanSignature,
+		&i.ScannedAt,
+		&i.Metadata,
+		&i.Version,
+		&i.ContentUpdatedAt,
+		&i.FolderID,
 	)
 	return i, err
 }
@@ -160,7 +177,7 @@ func (q *Queries) DeleteFile(ctx context.Context, id int32) error {
 }
 
 const getAllFiles = `-- name: GetAllFiles :many
-SELECT id, file_name, original_name, file_path, file_size, mime_type, description, category, uploaded_by, created_at, updated_at FROM files
+SELECT id, file_name, original_name, file_path, file_size, mime_type, description, category, uploaded_by, created_at, updated_at, checksum, scan_status, scan_signature, scanned_at, metadata, version, content_updated_at, folder_id FROM files
 ORDER BY created_at DESC
 `
 
@@ -185,6 +202,14 @@ func (q *Queries) GetAllFiles(ctx context.Context) ([]Files, error) {
 			&i.UploadedBy,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Checksum,
+			&i.ScanStatus,
+			&i.ScanSignature,
+			&i.ScannedAt,
+			&i.Metadata,
+			&i.Version,
+			&i.ContentUpdatedAt,
+			&i.FolderID,
 		); err != nil {
 			return nil, err
 		}
@@ -200,7 +225,7 @@ func (q *Queries) GetAllFiles(ctx context.Context) ([]Files, error) {
 }
 
 const getAllFilesWithPaginationAndFilters = `-- name: GetAllFilesWithPaginationAndFilters :many
-SELECT id, file_name, original_name, file_path, file_size, mime_type, description, category, uploaded_by, created_at, updated_at FROM files
+SELECT id, file_name, original_name, file_path, file_size, mime_type, description, category, uploaded_by, created_at, updated_at, checksum, scan_status, scan_signature, scanned_at, metadata, version, content_updated_at, folder_id FROM files
 WHERE 
     ($3::text IS NULL OR file_name ILIKE '%' || $3::text || '%')
     AND ($4::text IS NULL OR mime_type = $4::text)
@@ -274,6 +299,14 @@ func (q *Queries) GetAllFilesWithPaginationAndFilters(ctx context.Context, arg G
 			&i.UploadedBy,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Checksum,
+			&i.ScanStatus,
+			&i.ScanSignature,
+			&i.ScannedAt,
+			&i.Metadata,
+			&i.Version,
+			&i.ContentUpdatedAt,
+			&i.FolderID,
 		); err != nil {
 			return nil, err
 		}
@@ -289,7 +322,7 @@ func (q *Queries) GetAllFilesWithPaginationAndFilters(ctx context.Context, arg G
 }
 
 const getFile = `-- name: GetFile :one
-SELECT id, file_name, original_name, file_path, file_size, mime_type, description, category, uploaded_by, created_at, updated_at FROM files
+SELECT id, file_name, original_name, file_path, file_size, mime_type, description, category, uploaded_by, created_at, updated_at, checksum, scan_status, scan_signature, scanned_at, metadata, version, content_updated_at, folder_id FROM files
 WHERE id = $1 LIMIT 1
 `
 
@@ -308,12 +341,53 @@ func (q *Queries) GetFile(ctx context.Context, id int32) (Files, error) {
 		&i.UploadedBy,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Checksum,
+		&i.ScanStatus,
+		&i.ScanSignature,
+		&i.ScannedAt,
+		&i.Metadata,
+		&i.Version,
+		&i.ContentUpdatedAt,
+		&i.FolderID,
+	)
+	return i, err
+}
+
+const getFileForUpdate = `-- name: GetFileForUpdate :one
+SELECT id, file_name, original_name, file_path, file_size, mime_type, description, category, uploaded_by, created_at, updated_at, checksum, scan_status, scan_signature, scanned_at, metadata, version, content_updated_at, folder_id FROM files
+WHERE id = $1 LIMIT 1
+FOR UPDATE
+`
+
+func (q *Queries) GetFileForUpdate(ctx context.Context, id int32) (Files, error) {
+	row := q.queryRow(ctx, q.getFileForUpdateStmt, getFileForUpdate, id)
+	var i Files
+	err := row.Scan(
+		&i.ID,
+		&i.FileName,
+		&i.OriginalName,
+		&i.FilePath,
+		&i.FileSize,
+		&i.MimeType,
+		&i.Description,
+		&i.Category,
+		&i.UploadedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Checksum,
+		&i.ScanStatus,
+		&i.ScanSignature,
+		&i.ScannedAt,
+		&i.Metadata,
+		&i.Version,
+		&i.ContentUpdatedAt,
+		&i.FolderID,
 	)
 	return i, err
 }
 
 const getFilesByUser = `-- name: GetFilesByUser :many
-SELECT id, file_name, original_name, file_path, file_size, mime_type, description, category, uploaded_by, created_at, updated_at FROM files
+SELECT id, file_name, original_name, file_path, file_size, mime_type, description, category, uploaded_by, created_at, updated_at, checksum, scan_status, scan_signature, scanned_at, metadata, version, content_updated_at, folder_id FROM files
 WHERE uploaded_by = $1
 ORDER BY created_at DESC
 `
@@ -339,6 +413,14 @@ func (q *Queries) GetFilesByUser(ctx context.Context, uploadedBy int32) ([]Files
 			&i.UploadedBy,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Checksum,
+			&i.ScanStatus,
+			&i.ScanSignature,
+			&i.ScannedAt,
+			&i.Metadata,
+			&i.Version,
+			&i.ContentUpdatedAt,
+			&i.FolderID,
 		); err != nil {
 			return nil, err
 		}
@@ -354,7 +436,7 @@ func (q *Queries) GetFilesByUser(ctx context.Context, uploadedBy int32) ([]Files
 }
 
 const getFilesByUserWithPagination = `-- name: GetFilesByUserWithPagination :many
-SELECT id, file_name, original_name, file_path, file_size, mime_type, description, category, uploaded_by, created_at, updated_at FROM files
+SELECT id, file_name, original_name, file_path, file_size, mime_type, description, category, uploaded_by, created_at, updated_at, checksum, scan_status, scan_signature, scanned_at, metadata, version, content_updated_at, folder_id FROM files
 WHERE uploaded_by = $3
     AND ($4::text IS NULL OR file_name ILIKE '%' || $4::text || '%')
     AND ($5::text IS NULL OR mime_type = $5::text)
@@ -427,6 +509,395 @@ func (q *Queries) GetFilesByUserWithPagination(ctx context.Context, arg GetFiles
 			&i.UploadedBy,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Checksum,
+			&i.ScanStatus,
+			&i.ScanSignature,
+			&i.ScannedAt,
+			&i.Metadata,
+			&i.Version,
+			&i.ContentUpdatedAt,
+			&i.FolderID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFilesInFolderTree = `-- name: GetFilesInFolderTree :many
+SELECT fi.id, fi.file_name, fi.original_name, fi.file_path, fi.file_size, fi.mime_type, fi.description, fi.category, fi.uploaded_by, fi.created_at, fi.updated_at, fi.checksum, fi.scan_status, fi.scan_signature, fi.scanned_at, fi.metadata, fi.version, fi.content_updated_at, fi.folder_id FROM files fi
+JOIN folders d ON d.id = fi.folder_id
+JOIN folders f ON f.id = $1
+WHERE d.id = f.id OR d.path LIKE f.path || f.id || '/%'
+ORDER BY fi.id
+`
+
+func (q *Queries) GetFilesInFolderTree(ctx context.Context, id int32) ([]Files, error) {
+	rows, err := q.query(ctx, q.getFilesInFolderTreeStmt, getFilesInFolderTree, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Files{}
+	for rows.Next() {
+		var i Files
+		if err := rows.Scan(
+			&i.ID,
+			&i.FileName,
+			&i.OriginalName,
+			&i.FilePath,
+			&i.FileSize,
+			&i.MimeType,
+			&i.Description,
+			&i.Category,
+			&i.UploadedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Checksum,
+			&i.ScanStatus,
+			&i.ScanSignature,
+			&i.ScannedAt,
+			&i.Metadata,
+			&i.Version,
+			&i.ContentUpdatedAt,
+			&i.FolderID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFilesSharedWithUser = `-- name: GetFilesSharedWithUser :many
+SELECT fi.id, fi.file_name, fi.original_name, fi.file_path, fi.file_size, fi.mime_type, fi.description, fi.category, fi.uploaded_by, fi.created_at, fi.updated_at, fi.checksum, fi.scan_status, fi.scan_signature, fi.scanned_at, fi.metadata, fi.version, fi.content_updated_at, fi.folder_id FROM files fi
+JOIN file_grants g ON g.file_id = fi.id
+WHERE g.user_id = $1
+ORDER BY g.created_at DESC, fi.id DESC
+`
+
+func (q *Queries) GetFilesSharedWithUser(ctx context.Context, userID int32) ([]Files, error) {
+	rows, err := q.query(ctx, q.getFilesSharedWithUserStmt, getFilesSharedWithUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Files{}
+	for rows.Next() {
+		var i Files
+		if err := rows.Scan(
+			&i.ID,
+			&i.FileName,
+			&i.OriginalName,
+			&i.FilePath,
+			&i.FileSize,
+			&i.MimeType,
+			&i.Description,
+			&i.Category,
+			&i.UploadedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Checksum,
+			&i.ScanStatus,
+			&i.ScanSignature,
+			&i.ScannedAt,
+			&i.Metadata,
+			&i.Version,
+			&i.ContentUpdatedAt,
+			&i.FolderID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFilesPendingScan = `-- name: GetFilesPendingScan :many
+SELECT id, file_name, original_name, file_path, file_size, mime_type, description, category, uploaded_by, created_at, updated_at, checksum, scan_status, scan_signature, scanned_at, metadata, version, content_updated_at, folder_id FROM files
+WHERE scan_status = 'pending' AND created_at < $1
+ORDER BY created_at
+LIMIT $2
+`
+
+type GetFilesPendingScanParams struct {
+	CreatedAt sql.NullTime `db:"created_at" json:"created_at"`
+	Limit     int32        `db:"limit" json:"limit"`
+}
+
+func (q *Queries) GetFilesPendingScan(ctx context.Context, arg GetFilesPendingScanParams) ([]Files, error) {
+	rows, err := q.query(ctx, q.getFilesPendingScanStmt, getFilesPendingScan, arg.CreatedAt, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Files{}
+	for rows.Next() {
+		var i Files
+		if err := rows.Scan(
+			&i.ID,
+			&i.FileName,
+			&i.OriginalName,
+			&i.FilePath,
+			&i.FileSize,
+			&i.MimeType,
+			&i.Description,
+			&i.Category,
+			&i.UploadedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Checksum,
+			&i.ScanStatus,
+			&i.ScanSignature,
+			&i.ScannedAt,
+			&i.Metadata,
+			&i.Version,
+			&i.ContentUpdatedAt,
+			&i.FolderID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listFilesByUserWithCursor = `-- name: ListFilesByUserWithCursor :many
+SELECT id, file_name, original_name, file_path, file_size, mime_type, description, category, uploaded_by, created_at, updated_at, checksum, scan_status, scan_signature, scanned_at, metadata, version, content_updated_at, folder_id FROM files
+WHERE uploaded_by = $2
+    AND ($3::text IS NULL OR file_name ILIKE '%' || $3::text || '%')
+    AND ($4::text IS NULL OR mime_type = $4::text)
+    AND ($5::text IS NULL OR category = $5::text)
+    AND ($6::timestamp IS NULL OR created_at >= $6::timestamp)
+    AND ($7::timestamp IS NULL OR created_at <= $7::timestamp)
+    AND (
+        $8::text IS NULL 
+        OR file_name ILIKE '%' || $8::text || '%' 
+        OR original_name ILIKE '%' || $8::text || '%'
+        OR description ILIKE '%' || $8::text || '%'
+    )
+    AND (
+        $9::integer IS NULL
+        OR ($10::text = 'id' AND $11::text = 'ASC' AND id > $9::integer)
+        OR ($10::text = 'id' AND $11::text = 'DESC' AND id < $9::integer)
+        OR ($10::text = 'file_name' AND $11::text = 'ASC' AND (file_name, id) > ($12::text, $9::integer))
+        OR ($10::text = 'file_name' AND $11::text = 'DESC' AND (file_name, id) < ($12::text, $9::integer))
+        OR ($10::text = 'file_size' AND $11::text = 'ASC' AND (file_size, id) > ($13::bigint, $9::integer))
+        OR ($10::text = 'file_size' AND $11::text = 'DESC' AND (file_size, id) < ($13::bigint, $9::integer))
+        OR ($10::text = 'created_at' AND $11::text = 'ASC' AND (created_at, id) > ($14::timestamptz, $9::integer))
+        OR ($10::text = 'created_at' AND $11::text = 'DESC' AND (created_at, id) < ($14::timestamptz, $9::integer))
+    )
+ORDER BY
+    CASE WHEN $10::text = 'file_name' AND $11::text = 'ASC' THEN file_name END ASC,
+    CASE WHEN $10::text = 'file_name' AND $11::text = 'DESC' THEN file_name END DESC,
+    CASE WHEN $10::text = 'file_size' AND $11::text = 'ASC' THEN file_size END ASC,
+    CASE WHEN $10::text = 'file_size' AND $11::text = 'DESC' THEN file_size END DESC,
+    CASE WHEN $10::text = 'created_at' AND $11::text = 'ASC' THEN created_at END ASC,
+    CASE WHEN $10::text = 'created_at' AND $11::text = 'DESC' THEN created_at END DESC,
+    CASE WHEN $11::text = 'ASC' THEN id END ASC,
+    CASE WHEN $11::text = 'DESC' THEN id END DESC
+LIMIT $1
+`
+
+type ListFilesByUserWithCursorParams struct {
+	Limit          int32          `db:"limit" json:"limit"`
+	UploadedBy     int32          `db:"uploaded_by" json:"uploaded_by"`
+	FileNameFilter sql.NullString `db:"file_name_filter" json:"file_name_filter"`
+	MimeTypeFilter sql.NullString `db:"mime_type_filter" json:"mime_type_filter"`
+	CategoryFilter sql.NullString `db:"category_filter" json:"category_filter"`
+	CreatedAfter   sql.NullTime   `db:"created_after" json:"created_after"`
+	CreatedBefore  sql.NullTime   `db:"created_before" json:"created_before"`
+	Search         sql.NullString `db:"search" json:"search"`
+	CursorID       sql.NullInt32  `db:"cursor_id" json:"cursor_id"`
+	SortField      string         `db:"sort_field" json:"sort_field"`
+	SortOrder      string         `db:"sort_order" json:"sort_order"`
+	CursorText     sql.NullString `db:"cursor_text" json:"cursor_text"`
+	CursorSize     sql.NullInt64  `db:"cursor_size" json:"cursor_size"`
+	CursorTime     sql.NullTime   `db:"cursor_time" json:"cursor_time"`
+}
+
+func (q *Queries) ListFilesByUserWithCursor(ctx context.Context, arg ListFilesByUserWithCursorParams) ([]Files, error) {
+	rows, err := q.query(ctx, q.listFilesByUserWithCursorStmt, listFilesByUserWithCursor,
+		arg.Limit,
+		arg.UploadedBy,
+		arg.FileNameFilter,
+		arg.MimeTypeFilter,
+		arg.CategoryFilter,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.Search,
+		arg.CursorID,
+		arg.SortField,
+		arg.SortOrder,
+		arg.CursorText,
+		arg.CursorSize,
+		arg.CursorTime,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Files{}
+	for rows.Next() {
+		var i Files
+		if err := rows.Scan(
+			&i.ID,
+			&i.FileName,
+			&i.OriginalName,
+			&i.FilePath,
+			&i.FileSize,
+			&i.MimeType,
+			&i.Description,
+			&i.Category,
+			&i.UploadedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Checksum,
+			&i.ScanStatus,
+			&i.ScanSignature,
+			&i.ScannedAt,
+			&i.Metadata,
+			&i.Version,
+			&i.ContentUpdatedAt,
+			&i.FolderID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listFilesWithCursor = `-- name: ListFilesWithCursor :many
+SELECT id, file_name, original_name, file_path, file_size, mime_type, description, category, uploaded_by, created_at, updated_at, checksum, scan_status, scan_signature, scanned_at, metadata, version, content_updated_at, folder_id FROM files
+WHERE 
+    ($2::text IS NULL OR file_name ILIKE '%' || $2::text || '%')
+    AND ($3::text IS NULL OR mime_type = $3::text)
+    AND ($4::text IS NULL OR category = $4::text)
+    AND ($5::integer IS NULL OR uploaded_by = $5::integer)
+    AND ($6::timestamp IS NULL OR created_at >= $6::timestamp)
+    AND ($7::timestamp IS NULL OR created_at <= $7::timestamp)
+    AND (
+        $8::text IS NULL 
+        OR file_name ILIKE '%' || $8::text || '%' 
+        OR original_name ILIKE '%' || $8::text || '%'
+        OR description ILIKE '%' || $8::text || '%'
+    )
+    AND (
+        $9::integer IS NULL
+        OR ($10::text = 'id' AND $11::text = 'ASC' AND id > $9::integer)
+        OR ($10::text = 'id' AND $11::text = 'DESC' AND id < $9::integer)
+        OR ($10::text = 'file_name' AND $11::text = 'ASC' AND (file_name, id) > ($12::text, $9::integer))
+        OR ($10::text = 'file_name' AND $11::text = 'DESC' AND (file_name, id) < ($12::text, $9::integer))
+        OR ($10::text = 'file_size' AND $11::text = 'ASC' AND (file_size, id) > ($13::bigint, $9::integer))
+        OR ($10::text = 'file_size' AND $11::text = 'DESC' AND (file_size, id) < ($13::bigint, $9::integer))
+        OR ($10::text = 'created_at' AND $11::text = 'ASC' AND (created_at, id) > ($14::timestamptz, $9::integer))
+        OR ($10::text = 'created_at' AND $11::text = 'DESC' AND (created_at, id) < ($14::timestamptz, $9::integer))
+    )
+ORDER BY
+    CASE WHEN $10::text = 'file_name' AND $11::text = 'ASC' THEN file_name END ASC,
+    CASE WHEN $10::text = 'file_name' AND $11::text = 'DESC' THEN file_name END DESC,
+    CASE WHEN $10::text = 'file_size' AND $11::text = 'ASC' THEN file_size END ASC,
+    CASE WHEN $10::text = 'file_size' AND $11::text = 'DESC' THEN file_size END DESC,
+    CASE WHEN $10::text = 'created_at' AND $11::text = 'ASC' THEN created_at END ASC,
+    CASE WHEN $10::text = 'created_at' AND $11::text = 'DESC' THEN created_at END DESC,
+    CASE WHEN $11::text = 'ASC' THEN id END ASC,
+    CASE WHEN $11::text = 'DESC' THEN id END DESC
+LIMIT $1
+`
+
+type ListFilesWithCursorParams struct {
+	Limit            int32          `db:"limit" json:"limit"`
+	FileNameFilter   sql.NullString `db:"file_name_filter" json:"file_name_filter"`
+	MimeTypeFilter   sql.NullString `db:"mime_type_filter" json:"mime_type_filter"`
+	CategoryFilter   sql.NullString `db:"category_filter" json:"category_filter"`
+	UploadedByFilter sql.NullInt32  `db:"uploaded_by_filter" json:"uploaded_by_filter"`
+	CreatedAfter     sql.NullTime   `db:"created_after" json:"created_after"`
+	CreatedBefore    sql.NullTime   `db:"created_before" json:"created_before"`
+	Search           sql.NullString `db:"search" json:"search"`
+	CursorID         sql.NullInt32  `db:"cursor_id" json:"cursor_id"`
+	SortField        string         `db:"sort_field" json:"sort_field"`
+	SortOrder        string         `db:"sort_order" json:"sort_order"`
+	CursorText       sql.NullString `db:"cursor_text" json:"cursor_text"`
+	CursorSize       sql.NullInt64  `db:"cursor_size" json:"cursor_size"`
+	CursorTime       sql.NullTime   `db:"cursor_time" json:"cursor_time"`
+}
+
+func (q *Queries) ListFilesWithCursor(ctx context.Context, arg ListFilesWithCursorParams) ([]Files, error) {
+	rows, err := q.query(ctx, q.listFilesWithCursorStmt, listFilesWithCursor,
+		arg.Limit,
+		arg.FileNameFilter,
+		arg.MimeTypeFilter,
+		arg.CategoryFilter,
+		arg.UploadedByFilter,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.Search,
+		arg.CursorID,
+		arg.SortField,
+		arg.SortOrder,
+		arg.CursorText,
+		arg.CursorSize,
+		arg.CursorTime,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Files{}
+	for rows.Next() {
+		var i Files
+		if err := rows.Scan(
+			&i.ID,
+			&i.FileName,
+			&i.OriginalName,
+			&i.FilePath,
+			&i.FileSize,
+			&i.MimeType,
+			&i.Description,
+			&i.Category,
+			&i.UploadedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Checksum,
+			&i.ScanStatus,
+			&i.ScanSignature,
+			&i.ScannedAt,
+			&i.Metadata,
+			&i.Version,
+			&i.ContentUpdatedAt,
+			&i.FolderID,
 		); err != nil {
 			return nil, err
 		}
@@ -441,11 +912,108 @@ func (q *Queries) GetFilesByUserWithPagination(ctx context.Context, arg GetFiles
 	return items, nil
 }
 
+const moveFile = `-- name: MoveFile :one
+UPDATE files
+SET folder_id = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING id, file_name, original_name, file_path, file_size, mime_type, description, category, uploaded_by, created_at, updated_at, checksum, scan_status, scan_signature, scanned_at, metadata, version, content_updated_at, folder_id
+`
+
+type MoveFileParams struct {
+	ID       int32         `db:"id" json:"id"`
+	FolderID sql.NullInt32 `db:"folder_id" json:"folder_id"`
+}
+
+func (q *Queries) MoveFile(ctx context.Context, arg MoveFileParams) (Files, error) {
+	row := q.queryRow(ctx, q.moveFileStmt, moveFile, arg.ID, arg.FolderID)
+	var i Files
+	err := row.Scan(
+		&i.ID,
+		&i.FileName,
+		&i.OriginalName,
+		&i.FilePath,
+		&i.FileSize,
+		&i.MimeType,
+		&i.Description,
+		&i.Category,
+		&i.UploadedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Checksum,
+		&i.ScanStatus,
+		&i.ScanSignature,
+		&i.ScannedAt,
+		&i.Metadata,
+		&i.Version,
+		&i.ContentUpdatedAt,
+		&i.FolderID,
+	)
+	return i, err
+}
+
+const replaceFileContent = `-- name: ReplaceFileContent :one
+UPDATE files
+SET original_name = $3, file_path = $4, file_size = $5, mime_type = $6, checksum = $7, scan_status = $8,
+    scan_signature = NULL, scanned_at = NULL, metadata = $9, version = version + 1,
+    content_updated_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND version = $2
+RETURNING id, file_name, original_name, file_path, file_size, mime_type, description, category, uploaded_by, created_at, updated_at, checksum, scan_status, scan_signature, scanned_at, metadata, version, content_updated_at, folder_id
+`
+
+type ReplaceFileContentParams struct {
+	ID           int32           `db:"id" json:"id"`
+	Version      int32           `db:"version" json:"version"`
+	OriginalName string          `db:"original_name" json:"original_name"`
+	FilePath     string          `db:"file_path" json:"file_path"`
+	FileSize     int64           `db:"file_size" json:"file_size"`
+	MimeType     string          `db:"mime_type" json:"mime_type"`
+	Checksum     sql.NullString  `db:"checksum" json:"checksum"`
+	ScanStatus   string          `db:"scan_status" json:"scan_status"`
+	Metadata     json.RawMessage `db:"metadata" json:"metadata"`
+}
+
+func (q *Queries) ReplaceFileContent(ctx context.Context, arg ReplaceFileContentParams) (Files, error) {
+	row := q.queryRow(ctx, q.replaceFileContentStmt, replaceFileContent,
+		arg.ID,
+		arg.Version,
+		arg.OriginalName,
+		arg.FilePath,
+		arg.FileSize,
+		arg.MimeType,
+		arg.Checksum,
+		arg.ScanStatus,
+		arg.Metadata,
+	)
+	var i Files
+	err := row.Scan(
+		&i.ID,
+		&i.FileName,
+		&i.OriginalName,
+		&i.FilePath,
+		&i.FileSize,
+		&i.MimeType,
+		&i.Description,
+		&i.Category,
+		&i.UploadedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Checksum,
+		&i.ScanStatus,
+		&i.ScanSignature,
+		&i.ScannedAt,
+		&i.Metadata,
+		&i.Version,
+		&i.ContentUpdatedAt,
+		&i.FolderID,
+	)
+	return i, err
+}
+
 const updateFile = `-- name: UpdateFile :one
 UPDATE files
 SET description = $2, category = $3, updated_at = NOW()
 WHERE id = $1
-RETURNING id, file_name, original_name, file_path, file_size, mime_type, description, category, uploaded_by, created_at, updated_at
+RETURNING id, file_name, original_name, file_path, file_size, mime_type, description, category, uploaded_by, created_at, updated_at, checksum, scan_status, scan_signature, scanned_at, metadata, version, content_updated_at, folder_id
 `
 
 type UpdateFileParams struct {
@@ -469,6 +1037,60 @@ func (q *Queries) UpdateFile(ctx context.Context, arg UpdateFileParams) (Files,
 		&i.UploadedBy,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Checksum,
+		&i.ScanStatus,
+		&i.ScanSignature,
+		&i.ScannedAt,
+		&i.Metadata,
+		&i.Version,
+		&i.ContentUpdatedAt,
+		&i.FolderID,
+	)
+	return i, err
+}
+
+const updateFileScanStatus = `-- name: UpdateFileScanStatus :one
+UPDATE files
+SET scan_status = $2, scan_signature = $3, scanned_at = NOW()
+WHERE id = $1 AND file_path = $4
+RETURNING id, file_name, original_name, file_path, file_size, mime_type, description, category, uploaded_by, created_at, updated_at, checksum, scan_status, scan_signature, scanned_at, metadata, version, content_updated_at, folder_id
+`
+
+type UpdateFileScanStatusParams struct {
+	ID            int32          `db:"id" json:"id"`
+	ScanStatus    string         `db:"scan_status" json:"scan_status"`
+	ScanSignature sql.NullString `db:"scan_signature" json:"scan_signature"`
+	FilePath      string         `db:"file_path" json:"file_path"`
+}
+
+func (q *Queries) UpdateFileScanStatus(ctx context.Context, arg UpdateFileScanStatusParams) (Files, error) {
+	row := q.queryRow(ctx, q.updateFileScanStatusStmt, updateFileScanStatus,
+		arg.ID,
+		arg.ScanStatus,
+		arg.ScanSignature,
+		arg.FilePath,
+	)
+	var i Files
+	err := row.Scan(
+		&i.ID,
+		&i.FileName,
+		&i.OriginalName,
+		&i.FilePath,
+		&i.FileSize,
+		&i.MimeType,
+		&i.Description,
+		&i.Category,
+		&i.UploadedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Checksum,
+		&i.ScanStatus,
+		&i.ScanSignature,
+		&i.ScannedAt,
+		&i.Metadata,
+		&i.Version,
+		&i.ContentUpdatedAt,
+		&i.FolderID,
 	)
 	return i, err
 }
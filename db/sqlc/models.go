@@ -6,33 +6,198 @@ package database
 
 import (
 	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
 )
 
-type Files struct {
-	ID           int32          `db:"id" json:"id"`
-	FileName     string         `db:"file_name" json:"file_name"`
+type DataExports struct {
+	ID            int32          `db:"id" json:"id"`
+	UserID        int32          `db:"user_id" json:"user_id"`
+	Status        string         `db:"status" json:"status"`
+	FilePath      sql.NullString `db:"file_path" json:"file_path"`
+	DownloadToken sql.NullString `db:"download_token" json:"download_token"`
+	ErrorMessage  sql.NullString `db:"error_message" json:"error_message"`
+	ExpiresAt     sql.NullTime   `db:"expires_at" json:"expires_at"`
+	CompletedAt   sql.NullTime   `db:"completed_at" json:"completed_at"`
+	CreatedAt     sql.NullTime   `db:"created_at" json:"created_at"`
+	UpdatedAt     sql.NullTime   `db:"updated_at" json:"updated_at"`
+}
+
+type FileBlobs struct {
+	Checksum   string       `db:"checksum" json:"checksum"`
+	StorageKey string       `db:"storage_key" json:"storage_key"`
+	FileSize   int64        `db:"file_size" json:"file_size"`
+	RefCount   int32        `db:"ref_count" json:"ref_count"`
+	VerifiedAt sql.NullTime `db:"verified_at" json:"verified_at"`
+	Corrupted  bool         `db:"corrupted" json:"corrupted"`
+	CreatedAt  sql.NullTime `db:"created_at" json:"created_at"`
+	UpdatedAt  sql.NullTime `db:"updated_at" json:"updated_at"`
+	Stored     bool         `db:"stored" json:"stored"`
+}
+
+type FileGrants struct {
+	ID         int32         `db:"id" json:"id"`
+	FileID     int32         `db:"file_id" json:"file_id"`
+	UserID     int32         `db:"user_id" json:"user_id"`
+	Permission string        `db:"permission" json:"permission"`
+	GrantedBy  sql.NullInt32 `db:"granted_by" json:"granted_by"`
+	CreatedAt  sql.NullTime  `db:"created_at" json:"created_at"`
+	UpdatedAt  sql.NullTime  `db:"updated_at" json:"updated_at"`
+}
+
+type FileShareLinks struct {
+	ID            int32          `db:"id" json:"id"`
+	FileID        int32          `db:"file_id" json:"file_id"`
+	CreatedBy     int32          `db:"created_by" json:"created_by"`
+	Token         string         `db:"token" json:"token"`
+	PasswordHash  sql.NullString `db:"password_hash" json:"password_hash"`
+	ExpiresAt     sql.NullTime   `db:"expires_at" json:"expires_at"`
+	MaxDownloads  sql.NullInt32  `db:"max_downloads" json:"max_downloads"`
+	DownloadCount int32          `db:"download_count" json:"download_count"`
+	RevokedAt     sql.NullTime   `db:"revoked_at" json:"revoked_at"`
+	CreatedAt     sql.NullTime   `db:"created_at" json:"created_at"`
+	UpdatedAt     sql.NullTime   `db:"updated_at" json:"updated_at"`
+}
+
+type FileUploadChunks struct {
+	ID          int32        `db:"id" json:"id"`
+	UploadID    uuid.UUID    `db:"upload_id" json:"upload_id"`
+	ChunkOffset int64        `db:"chunk_offset" json:"chunk_offset"`
+	ChunkSize   int64        `db:"chunk_size" json:"chunk_size"`
+	StorageKey  string       `db:"storage_key" json:"storage_key"`
+	CreatedAt   sql.NullTime `db:"created_at" json:"created_at"`
+}
+
+type FileUploads struct {
+	ID           uuid.UUID      `db:"id" json:"id"`
+	UserID       int32          `db:"user_id" json:"user_id"`
+	UploadLength int64          `db:"upload_length" json:"upload_length"`
+	UploadOffset int64          `db:"upload_offset" json:"upload_offset"`
+	Metadata     sql.NullString `db:"metadata" json:"metadata"`
 	OriginalName string         `db:"original_name" json:"original_name"`
-	FilePath     string         `db:"file_path" json:"file_path"`
-	FileSize     int64          `db:"file_size" json:"file_size"`
 	MimeType     string         `db:"mime_type" json:"mime_type"`
 	Description  sql.NullString `db:"description" json:"description"`
 	Category     sql.NullString `db:"category" json:"category"`
-	UploadedBy   int32          `db:"uploaded_by" json:"uploaded_by"`
+	FileID       sql.NullInt32  `db:"file_id" json:"file_id"`
+	ExpiresAt    time.Time      `db:"expires_at" json:"expires_at"`
 	CreatedAt    sql.NullTime   `db:"created_at" json:"created_at"`
 	UpdatedAt    sql.NullTime   `db:"updated_at" json:"updated_at"`
+	HashState    []byte         `db:"hash_state" json:"hash_state"`
+	FolderID     sql.NullInt32  `db:"folder_id" json:"folder_id"`
+}
+
+type FileVariants struct {
+	ID         int32        `db:"id" json:"id"`
+	FileID     int32        `db:"file_id" json:"file_id"`
+	Name       string       `db:"name" json:"name"`
+	StorageKey string       `db:"storage_key" json:"storage_key"`
+	MimeType   string       `db:"mime_type" json:"mime_type"`
+	Width      int32        `db:"width" json:"width"`
+	Height     int32        `db:"height" json:"height"`
+	FileSize   int64        `db:"file_size" json:"file_size"`
+	CreatedAt  sql.NullTime `db:"created_at" json:"created_at"`
+}
+
+type FileVersions struct {
+	ID           int32           `db:"id" json:"id"`
+	FileID       int32           `db:"file_id" json:"file_id"`
+	Version      int32           `db:"version" json:"version"`
+	OriginalName string          `db:"original_name" json:"original_name"`
+	FilePath     string          `db:"file_path" json:"file_path"`
+	FileSize     int64           `db:"file_size" json:"file_size"`
+	MimeType     string          `db:"mime_type" json:"mime_type"`
+	Checksum     sql.NullString  `db:"checksum" json:"checksum"`
+	ScanStatus   string          `db:"scan_status" json:"scan_status"`
+	Metadata     json.RawMessage `db:"metadata" json:"metadata"`
+	CreatedAt    sql.NullTime    `db:"created_at" json:"created_at"`
+}
+
+type Files struct {
+	ID               int32           `db:"id" json:"id"`
+	FileName         string          `db:"file_name" json:"file_name"`
+	OriginalName     string          `db:"original_name" json:"original_name"`
+	FilePath         string          `db:"file_path" json:"file_path"`
+	FileSize         int64           `db:"file_size" json:"file_size"`
+	MimeType         string          `db:"mime_type" json:"mime_type"`
+	Description      sql.NullString  `db:"description" json:"description"`
+	Category         sql.NullString  `db:"category" json:"category"`
+	UploadedBy       int32           `db:"uploaded_by" json:"uploaded_by"`
+	CreatedAt        sql.NullTime    `db:"created_at" json:"created_at"`
+	UpdatedAt        sql.NullTime    `db:"updated_at" json:"updated_at"`
+	Checksum         sql.NullString  `db:"checksum" json:"checksum"`
+	ScanStatus       string          `db:"scan_status" json:"scan_status"`
+	ScanSignature    sql.NullString  `db:"scan_signature" json:"scan_signature"`
+	ScannedAt        sql.NullTime    `db:"scanned_at" json:"scanned_at"`
+	Metadata         json.RawMessage `db:"metadata" json:"metadata"`
+	Version          int32           `db:"version" json:"version"`
+	ContentUpdatedAt sql.NullTime    `db:"content_updated_at" json:"content_updated_at"`
+	FolderID         sql.NullInt32   `db:"folder_id" json:"folder_id"`
+}
+
+type Folders struct {
+	ID        int32         `db:"id" json:"id"`
+	UserID    int32         `db:"user_id" json:"user_id"`
+	ParentID  sql.NullInt32 `db:"parent_id" json:"parent_id"`
+	Name      string        `db:"name" json:"name"`
+	Path      string        `db:"path" json:"path"`
+	CreatedAt sql.NullTime  `db:"created_at" json:"created_at"`
+	UpdatedAt sql.NullTime  `db:"updated_at" json:"updated_at"`
+}
+
+type LoginEvents struct {
+	ID            int32          `db:"id" json:"id"`
+	UserID        sql.NullInt32  `db:"user_id" json:"user_id"`
+	Email         string         `db:"email" json:"email"`
+	Success       bool           `db:"success" json:"success"`
+	Method        string         `db:"method" json:"method"`
+	FailureReason sql.NullString `db:"failure_reason" json:"failure_reason"`
+	IpAddress     sql.NullString `db:"ip_address" json:"ip_address"`
+	UserAgent     sql.NullString `db:"user_agent" json:"user_agent"`
+	CreatedAt     sql.NullTime   `db:"created_at" json:"created_at"`
+}
+
+type UserSettings struct {
+	UserID    int32           `db:"user_id" json:"user_id"`
+	Key       string          `db:"key" json:"key"`
+	Value     json.RawMessage `db:"value" json:"value"`
+	CreatedAt sql.NullTime    `db:"created_at" json:"created_at"`
+	UpdatedAt sql.NullTime    `db:"updated_at" json:"updated_at"`
+}
+
+type UserStorage struct {
+	UserID     int32         `db:"user_id" json:"user_id"`
+	UsedBytes  int64         `db:"used_bytes" json:"used_bytes"`
+	FileCount  int32         `db:"file_count" json:"file_count"`
+	QuotaBytes sql.NullInt64 `db:"quota_bytes" json:"quota_bytes"`
+	QuotaFiles sql.NullInt32 `db:"quota_files" json:"quota_files"`
+	CreatedAt  sql.NullTime  `db:"created_at" json:"created_at"`
+	UpdatedAt  sql.NullTime  `db:"updated_at" json:"updated_at"`
 }
 
 type Users struct {
-	ID                         int32          `db:"id" json:"id"`
-	Name                       string         `db:"name" json:"name"`
-	Email                      string         `db:"email" json:"email"`
-	CreatedAt                  sql.NullTime   `db:"created_at" json:"created_at"`
-	UpdatedAt                  sql.NullTime   `db:"updated_at" json:"updated_at"`
-	PasswordHash               string         `db:"password_hash" json:"password_hash"`
-	Role                       string         `db:"role" json:"role"`
-	EmailVerified              bool           `db:"email_verified" json:"email_verified"`
-	EmailVerificationToken     sql.NullString `db:"email_verification_token" json:"email_verification_token"`
-	EmailVerificationExpiresAt sql.NullTime   `db:"email_verification_expires_at" json:"email_verification_expires_at"`
-	PasswordResetToken         sql.NullString `db:"password_reset_token" json:"password_reset_token"`
-	PasswordResetExpiresAt     sql.NullTime   `db:"password_reset_expires_at" json:"password_reset_expires_at"`
+	ID                         int32           `db:"id" json:"id"`
+	Name                       string          `db:"name" json:"name"`
+	Email                      string          `db:"email" json:"email"`
+	CreatedAt                  sql.NullTime    `db:"created_at" json:"created_at"`
+	UpdatedAt                  sql.NullTime    `db:"updated_at" json:"updated_at"`
+	PasswordHash               string          `db:"password_hash" json:"password_hash"`
+	Role                       string          `db:"role" json:"role"`
+	EmailVerified              bool            `db:"email_verified" json:"email_verified"`
+	EmailVerificationToken     sql.NullString  `db:"email_verification_token" json:"email_verification_token"`
+	EmailVerificationExpiresAt sql.NullTime    `db:"email_verification_expires_at" json:"email_verification_expires_at"`
+	PasswordResetToken         sql.NullString  `db:"password_reset_token" json:"password_reset_token"`
+	PasswordResetExpiresAt     sql.NullTime    `db:"password_reset_expires_at" json:"password_reset_expires_at"`
+	DeletionRequestedAt        sql.NullTime    `db:"deletion_requested_at" json:"deletion_requested_at"`
+	DeletionScheduledAt        sql.NullTime    `db:"deletion_scheduled_at" json:"deletion_scheduled_at"`
+	DisplayName                sql.NullString  `db:"display_name" json:"display_name"`
+	Bio                        sql.NullString  `db:"bio" json:"bio"`
+	Locale                     sql.NullString  `db:"locale" json:"locale"`
+	Timezone                   sql.NullString  `db:"timezone" json:"timezone"`
+	Phone                      sql.NullString  `db:"phone" json:"phone"`
+	Metadata                   json.RawMessage `db:"metadata" json:"metadata"`
+	AvatarFileID               sql.NullInt32   `db:"avatar_file_id" json:"avatar_file_id"`
+	AvatarFileName             sql.NullString  `db:"avatar_file_name" json:"avatar_file_name"`
+	LastLoginAt                sql.NullTime    `db:"last_login_at" json:"last_login_at"`
 }
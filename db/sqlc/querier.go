@@ -7,39 +7,142 @@ package database
 import (
 	"context"
 	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
 )
 
 type Querier interface {
+	// Adds a reference to the blob with the checksum, creating it when missing; inserted tells whether
+	// the blob is new, stored whether its content has been written yet and corrupted whether the
+	// verification job found it damaged
+	AcquireFileBlob(ctx context.Context, arg AcquireFileBlobParams) (AcquireFileBlobRow, error)
+	AddUserStorageUsage(ctx context.Context, arg AddUserStorageUsageParams) error
+	// Overrides recorded for the user take precedence over the quotas of the role; usage that shrinks is
+	// always recorded
+	AddUserStorageUsageWithinQuota(ctx context.Context, arg AddUserStorageUsageWithinQuotaParams) (UserStorage, error)
+	AdvanceFileUploadOffset(ctx context.Context, arg AdvanceFileUploadOffsetParams) (FileUploads, error)
+	ArchiveFileVersion(ctx context.Context, arg ArchiveFileVersionParams) (FileVersions, error)
+	CancelUserDeletion(ctx context.Context, id int32) (Users, error)
+	CompleteDataExport(ctx context.Context, arg CompleteDataExportParams) (DataExports, error)
+	// An upload is completed once; completing it again, e.g. from a concurrent request, matches no row
+	CompleteFileUpload(ctx context.Context, arg CompleteFileUploadParams) (FileUploads, error)
 	CountFiles(ctx context.Context) (int64, error)
 	CountFilesByUser(ctx context.Context, arg CountFilesByUserParams) (int64, error)
 	CountFilesWithFilters(ctx context.Context, arg CountFilesWithFiltersParams) (int64, error)
+	CountInactiveUsers(ctx context.Context, inactiveSince time.Time) (int64, error)
+	CountLoginEventsByUser(ctx context.Context, userID sql.NullInt32) (int64, error)
 	CountUsers(ctx context.Context) (int64, error)
 	CountUsersWithFilters(ctx context.Context, arg CountUsersWithFiltersParams) (int64, error)
+	CreateDataExport(ctx context.Context, userID int32) (DataExports, error)
 	CreateFile(ctx context.Context, arg CreateFileParams) (Files, error)
+	CreateFileShareLink(ctx context.Context, arg CreateFileShareLinkParams) (FileShareLinks, error)
+	CreateFileUpload(ctx context.Context, arg CreateFileUploadParams) (FileUploads, error)
+	CreateFileUploadChunk(ctx context.Context, arg CreateFileUploadChunkParams) (FileUploadChunks, error)
+	CreateFolder(ctx context.Context, arg CreateFolderParams) (Folders, error)
+	CreateLoginEvent(ctx context.Context, arg CreateLoginEventParams) (LoginEvents, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (Users, error)
+	CreateUserStorage(ctx context.Context, userID int32) error
 	CreateUserWithPassword(ctx context.Context, arg CreateUserWithPasswordParams) (Users, error)
+	DeleteDataExport(ctx context.Context, id int32) error
 	DeleteFile(ctx context.Context, id int32) error
+	DeleteFileBlob(ctx context.Context, checksum string) error
+	DeleteFileGrant(ctx context.Context, arg DeleteFileGrantParams) error
+	DeleteFileUpload(ctx context.Context, id uuid.UUID) error
+	DeleteFileUploadChunks(ctx context.Context, uploadID uuid.UUID) error
+	DeleteFileVariantsByFileID(ctx context.Context, fileID int32) error
+	DeleteFileVersionsUpTo(ctx context.Context, arg DeleteFileVersionsUpToParams) ([]FileVersions, error)
+	DeleteFolder(ctx context.Context, id int32) error
 	DeleteUser(ctx context.Context, id int32) error
+	DeleteUserSetting(ctx context.Context, arg DeleteUserSettingParams) error
+	FailDataExport(ctx context.Context, arg FailDataExportParams) error
+	FailStaleDataExports(ctx context.Context, arg FailStaleDataExportsParams) ([]DataExports, error)
 	GetAllFiles(ctx context.Context) ([]Files, error)
 	GetAllFilesWithPaginationAndFilters(ctx context.Context, arg GetAllFilesWithPaginationAndFiltersParams) ([]Files, error)
 	GetAllUsers(ctx context.Context) ([]Users, error)
+	GetDataExport(ctx context.Context, id int32) (DataExports, error)
+	GetDataExportByToken(ctx context.Context, downloadToken sql.NullString) (DataExports, error)
+	GetDataExportsByUser(ctx context.Context, userID int32) ([]DataExports, error)
+	GetExpiredDataExports(ctx context.Context, expiresAt sql.NullTime) ([]DataExports, error)
+	GetExpiredFileUploads(ctx context.Context, expiresAt time.Time) ([]FileUploads, error)
 	GetFile(ctx context.Context, id int32) (Files, error)
+	GetFileBlob(ctx context.Context, checksum string) (FileBlobs, error)
+	GetFileBlobsToVerify(ctx context.Context, arg GetFileBlobsToVerifyParams) ([]FileBlobs, error)
+	GetFileForUpdate(ctx context.Context, id int32) (Files, error)
+	GetFileGrant(ctx context.Context, arg GetFileGrantParams) (FileGrants, error)
+	GetFileGrantsByFileID(ctx context.Context, fileID int32) ([]FileGrants, error)
+	GetFileShareLink(ctx context.Context, id int32) (FileShareLinks, error)
+	GetFileShareLinkByToken(ctx context.Context, token string) (FileShareLinks, error)
+	GetFileShareLinksByFileID(ctx context.Context, fileID int32) ([]FileShareLinks, error)
+	GetFileUpload(ctx context.Context, id uuid.UUID) (FileUploads, error)
+	GetFileUploadChunkKeysByUser(ctx context.Context, userID int32) ([]string, error)
+	GetFileUploadChunks(ctx context.Context, uploadID uuid.UUID) ([]FileUploadChunks, error)
+	GetFileVariant(ctx context.Context, arg GetFileVariantParams) (FileVariants, error)
+	GetFileVariantKeysByUser(ctx context.Context, uploadedBy int32) ([]string, error)
+	GetFileVariantsByFileID(ctx context.Context, fileID int32) ([]FileVariants, error)
+	GetFileVersion(ctx context.Context, arg GetFileVersionParams) (FileVersions, error)
+	GetFileVersionsByFileID(ctx context.Context, fileID int32) ([]FileVersions, error)
+	GetFileVersionsByUser(ctx context.Context, uploadedBy int32) ([]FileVersions, error)
 	GetFilesByUser(ctx context.Context, uploadedBy int32) ([]Files, error)
 	GetFilesByUserWithPagination(ctx context.Context, arg GetFilesByUserWithPaginationParams) ([]Files, error)
+	GetFilesInFolderTree(ctx context.Context, id int32) ([]Files, error)
+	GetFilesPendingScan(ctx context.Context, arg GetFilesPendingScanParams) ([]Files, error)
+	GetFilesSharedWithUser(ctx context.Context, userID int32) ([]Files, error)
+	GetFolder(ctx context.Context, id int32) (GetFolderRow, error)
+	GetFolderByName(ctx context.Context, arg GetFolderByNameParams) (Folders, error)
+	GetFolderForUpdate(ctx context.Context, id int32) (Folders, error)
+	GetFoldersByParent(ctx context.Context, arg GetFoldersByParentParams) ([]GetFoldersByParentRow, error)
+	// Exports last updated before the cutoff were left behind by a crash and are not in progress anymore
+	GetPendingDataExportByUser(ctx context.Context, arg GetPendingDataExportByUserParams) (DataExports, error)
+	GetUnreferencedFileBlobs(ctx context.Context, arg GetUnreferencedFileBlobsParams) ([]FileBlobs, error)
 	GetUser(ctx context.Context, id int32) (Users, error)
 	GetUserByEmail(ctx context.Context, email string) (Users, error)
 	GetUserByEmailWithPassword(ctx context.Context, email string) (Users, error)
 	GetUserByPasswordResetToken(ctx context.Context, passwordResetToken sql.NullString) (Users, error)
 	GetUserByVerificationToken(ctx context.Context, emailVerificationToken sql.NullString) (Users, error)
+	GetUserSetting(ctx context.Context, arg GetUserSettingParams) (UserSettings, error)
+	GetUserSettings(ctx context.Context, userID int32) ([]UserSettings, error)
+	GetUserStorage(ctx context.Context, userID int32) (UserStorage, error)
+	GetUsersDueForDeletion(ctx context.Context, deletionScheduledAt sql.NullTime) ([]Users, error)
+	ListFilesByUserWithCursor(ctx context.Context, arg ListFilesByUserWithCursorParams) ([]Files, error)
+	ListFilesWithCursor(ctx context.Context, arg ListFilesWithCursorParams) ([]Files, error)
+	ListInactiveUsers(ctx context.Context, arg ListInactiveUsersParams) ([]Users, error)
+	ListLoginEventsByUser(ctx context.Context, arg ListLoginEventsByUserParams) ([]LoginEvents, error)
 	ListUsers(ctx context.Context, arg ListUsersParams) ([]Users, error)
+	ListUsersWithCursor(ctx context.Context, arg ListUsersWithCursorParams) ([]Users, error)
 	ListUsersWithPaginationAndFilters(ctx context.Context, arg ListUsersWithPaginationAndFiltersParams) ([]Users, error)
+	LockUnreferencedFileBlob(ctx context.Context, checksum string) (FileBlobs, error)
+	MarkDataExportProcessing(ctx context.Context, id int32) error
+	// Content written anew replaces damaged content, and is verified again first
+	MarkFileBlobStored(ctx context.Context, checksum string) error
+	MarkFileBlobVerified(ctx context.Context, arg MarkFileBlobVerifiedParams) error
+	MoveFile(ctx context.Context, arg MoveFileParams) (Files, error)
+	MoveFolder(ctx context.Context, arg MoveFolderParams) (Folders, error)
+	MoveFolderDescendants(ctx context.Context, arg MoveFolderDescendantsParams) error
+	ReleaseFileBlob(ctx context.Context, checksum string) error
+	RenameFolder(ctx context.Context, arg RenameFolderParams) (Folders, error)
+	ReplaceFileContent(ctx context.Context, arg ReplaceFileContentParams) (Files, error)
+	// The token was delivered to the address, so using it proves the user owns it, like a verification token
 	ResetPassword(ctx context.Context, arg ResetPasswordParams) error
+	RevokeFileShareLink(ctx context.Context, id int32) error
+	ScheduleUserDeletion(ctx context.Context, arg ScheduleUserDeletionParams) (Users, error)
+	SetUserStorageQuota(ctx context.Context, arg SetUserStorageQuotaParams) (UserStorage, error)
+	SumFileVersionSizes(ctx context.Context, fileID int32) (int64, error)
 	UpdateEmailVerification(ctx context.Context, arg UpdateEmailVerificationParams) (Users, error)
 	UpdateEmailVerificationToken(ctx context.Context, arg UpdateEmailVerificationTokenParams) (Users, error)
 	UpdateFile(ctx context.Context, arg UpdateFileParams) (Files, error)
+	UpdateFileScanStatus(ctx context.Context, arg UpdateFileScanStatusParams) (Files, error)
+	UpdateFileVersionScanStatus(ctx context.Context, arg UpdateFileVersionScanStatusParams) error
 	UpdatePasswordResetToken(ctx context.Context, arg UpdatePasswordResetTokenParams) error
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (Users, error)
+	UpdateUserAvatar(ctx context.Context, arg UpdateUserAvatarParams) (Users, error)
+	UpdateUserLastLogin(ctx context.Context, arg UpdateUserLastLoginParams) error
+	UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) (Users, error)
 	UpdateVerificationToken(ctx context.Context, arg UpdateVerificationTokenParams) error
+	UpsertFileGrant(ctx context.Context, arg UpsertFileGrantParams) (FileGrants, error)
+	UpsertFileVariant(ctx context.Context, arg UpsertFileVariantParams) (FileVariants, error)
+	UpsertUserSetting(ctx context.Context, arg UpsertUserSettingParams) (UserSettings, error)
+	UseFileShareLink(ctx context.Context, id int32) (FileShareLinks, error)
 	VerifyEmailByToken(ctx context.Context, emailVerificationToken sql.NullString) error
 }
 
@@ -8,8 +8,60 @@ package database
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"time"
 )
 
+const cancelUserDeletion = `-- name: CancelUserDeletion :one
+UPDATE users
+SET deletion_requested_at = NULL, deletion_scheduled_at = NULL, updated_at = NOW()
+WHERE id = $1
+RETURNING id, name, email, created_at, updated_at, password_hash, role, email_verified, email_verification_token, email_verification_expires_at, password_reset_token, password_reset_expires_at, deletion_requested_at, deletion_scheduled_at, display_name, bio, locale, timezone, phone, metadata, avatar_file_id, avatar_file_name, last_login_at
+`
+
+func (q *Queries) CancelUserDeletion(ctx context.Context, id int32) (Users, error) {
+	row := q.queryRow(ctx, q.cancelUserDeletionStmt, cancelUserDeletion, id)
+	var i Users
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.PasswordHash,
+		&i.Role,
+		&i.EmailVerified,
+		&i.EmailVerificationToken,
+		&i.EmailVerificationExpiresAt,
+		&i.PasswordResetToken,
+		&i.PasswordResetExpiresAt,
+		&i.DeletionRequestedAt,
+		&i.DeletionScheduledAt,
+		&i.DisplayName,
+		&i.Bio,
+		&i.Locale,
+		&i.Timezone,
+		&i.Phone,
+		&i.Metadata,
+		&i.AvatarFileID,
+		&i.AvatarFileName,
+		&i.LastLoginAt,
+	)
+	return i, err
+}
+
+const countInactiveUsers = `-- name: CountInactiveUsers :one
+SELECT COUNT(*) FROM users
+WHERE COALESCE(last_login_at, created_at) < $1::timestamp
+`
+
+func (q *Queries) CountInactiveUsers(ctx context.Context, inactiveSince time.Time) (int64, error) {
+	row := q.queryRow(ctx, q.countInactiveUsersStmt, countInactiveUsers, inactiveSince)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const countUsers = `-- name: CountUsers :one
 SELECT COUNT(*) FROM users
 `
@@ -30,10 +82,13 @@ WHERE
     AND ($4::boolean IS NULL OR email_verified = $4::boolean)
     AND ($5::timestamp IS NULL OR created_at >= $5::timestamp)
     AND ($6::timestamp IS NULL OR created_at <= $6::timestamp)
+    AND ($7::timestamp IS NULL OR last_login_at >= $7::timestamp)
+    AND ($8::timestamp IS NULL OR last_login_at <= $8::timestamp)
+    AND ($9::boolean IS NULL OR (last_login_at IS NULL) = $9::boolean)
     AND (
-        $7::text IS NULL 
-        OR name ILIKE '%' || $7::text || '%' 
-        OR email ILIKE '%' || $7::text || '%'
+        $10::text IS NULL 
+        OR name ILIKE '%' || $10::text || '%' 
+        OR email ILIKE '%' || $10::text || '%'
     )
 `
 
@@ -44,6 +99,9 @@ type CountUsersWithFiltersParams struct {
 	EmailVerifiedFilter sql.NullBool   `db:"email_verified_filter" json:"email_verified_filter"`
 	CreatedAfter        sql.NullTime   `db:"created_after" json:"created_after"`
 	CreatedBefore       sql.NullTime   `db:"created_before" json:"created_before"`
+	LastLoginAfter      sql.NullTime   `db:"last_login_after" json:"last_login_after"`
+	LastLoginBefore     sql.NullTime   `db:"last_login_before" json:"last_login_before"`
+	NeverLoggedIn       sql.NullBool   `db:"never_logged_in" json:"never_logged_in"`
 	Search              sql.NullString `db:"search" json:"search"`
 }
 
@@ -55,6 +113,9 @@ func (q *Queries) CountUsersWithFilters(ctx context.Context, arg CountUsersWithF
 		arg.EmailVerifiedFilter,
 		arg.CreatedAfter,
 		arg.CreatedBefore,
+		arg.LastLoginAfter,
+		arg.LastLoginBefore,
+		arg.NeverLoggedIn,
 		arg.Search,
 	)
 	var count int64
@@ -65,7 +126,7 @@ func (q *Queries) CountUsersWithFilters(ctx context.Context, arg CountUsersWithF
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (name, email)
 VALUES ($1, $2)
-RETURNING id, name, email, created_at, updated_at, password_hash, role, email_verified, email_verification_token, email_verification_expires_at, password_reset_token, password_reset_expires_at
+RETURNING id, name, email, created_at, updated_at, password_hash, role, email_verified, email_verification_token, email_verification_expires_at, password_reset_token, password_reset_expires_at, deletion_requested_at, deletion_scheduled_at, display_name, bio, locale, timezone, phone, metadata, avatar_file_id, avatar_file_name, last_login_at
 `
 
 type CreateUserParams struct {
@@ -89,6 +150,17 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (Users,
 		&i.EmailVerificationExpiresAt,
 		&i.PasswordResetToken,
 		&i.PasswordResetExpiresAt,
+		&i.DeletionRequestedAt,
+		&i.DeletionScheduledAt,
+		&i.DisplayName,
+		&i.Bio,
+		&i.Locale,
+		&i.Timezone,
+		&i.Phone,
+		&i.Metadata,
+		&i.AvatarFileID,
+		&i.AvatarFileName,
+		&i.LastLoginAt,
 	)
 	return i, err
 }
@@ -96,7 +168,7 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (Users,
 const createUserWithPassword = `-- name: CreateUserWithPassword :one
 INSERT INTO users (name, email, password_hash, role, email_verification_token, email_verification_expires_at)
 VALUES ($1, $2, $3, $4, $5, $6)
-RETURNING id, name, email, created_at, updated_at, password_hash, role, email_verified, email_verification_token, email_verification_expires_at, password_reset_token, password_reset_expires_at
+RETURNING id, name, email, created_at, updated_at, password_hash, role, email_verified, email_verification_token, email_verification_expires_at, password_reset_token, password_reset_expires_at, deletion_requested_at, deletion_scheduled_at, display_name, bio, locale, timezone, phone, metadata, avatar_file_id, avatar_file_name, last_login_at
 `
 
 type CreateUserWithPasswordParams struct {
@@ -131,6 +203,17 @@ func (q *Queries) CreateUserWithPassword(ctx context.Context, arg CreateUserWith
 		&i.EmailVerificationExpiresAt,
 		&i.PasswordResetToken,
 		&i.PasswordResetExpiresAt,
+		&i.DeletionRequestedAt,
+		&i.DeletionScheduledAt,
+		&i.DisplayName,
+		&i.Bio,
+		&i.Locale,
+		&i.Timezone,
+		&i.Phone,
+		&i.Metadata,
+		&i.AvatarFileID,
+		&i.AvatarFileName,
+		&i.LastLoginAt,
 	)
 	return i, err
 }
@@ -146,7 +229,7 @@ func (q *Queries) DeleteUser(ctx context.Context, id int32) error {
 }
 
 const getAllUsers = `-- name: GetAllUsers :many
-SELECT id, name, email, created_at, updated_at, password_hash, role, email_verified, email_verification_token, email_verification_expires_at, password_reset_token, password_reset_expires_at FROM users
+SELECT id, name, email, created_at, updated_at, password_hash, role, email_verified, email_verification_token, email_verification_expires_at, password_reset_token, password_reset_expires_at, deletion_requested_at, deletion_scheduled_at, display_name, bio, locale, timezone, phone, metadata, avatar_file_id, avatar_file_name, last_login_at FROM users
 ORDER BY created_at DESC
 `
 
@@ -172,6 +255,17 @@ func (q *Queries) GetAllUsers(ctx context.Context) ([]Users, error) {
 			&i.EmailVerificationExpiresAt,
 			&i.PasswordResetToken,
 			&i.PasswordResetExpiresAt,
+			&i.DeletionRequestedAt,
+			&i.DeletionScheduledAt,
+			&i.DisplayName,
+			&i.Bio,
+			&i.Locale,
+			&i.Timezone,
+			&i.Phone,
+			&i.Metadata,
+			&i.AvatarFileID,
+			&i.AvatarFileName,
+			&i.LastLoginAt,
 		); err != nil {
 			return nil, err
 		}
@@ -187,7 +281,7 @@ func (q *Queries) GetAllUsers(ctx context.Context) ([]Users, error) {
 }
 
 const getUser = `-- name: GetUser :one
-SELECT id, name, email, created_at, updated_at, password_hash, role, email_verified, email_verification_token, email_verification_expires_at, password_reset_token, password_reset_expires_at FROM users
+SELECT id, name, email, created_at, updated_at, password_hash, role, email_verified, email_verification_token, email_verification_expires_at, password_reset_token, password_reset_expires_at, deletion_requested_at, deletion_scheduled_at, display_name, bio, locale, timezone, phone, metadata, avatar_file_id, avatar_file_name, last_login_at FROM users
 WHERE id = $1 LIMIT 1
 `
 
@@ -207,12 +301,23 @@ func (q *Queries) GetUser(ctx context.Context, id int32) (Users, error) {
 		&i.EmailVerificationExpiresAt,
 		&i.PasswordResetToken,
 		&i.PasswordResetExpiresAt,
+		&i.DeletionRequestedAt,
+		&i.DeletionScheduledAt,
+		&i.DisplayName,
+		&i.Bio,
+		&i.Locale,
+		&i.Timezone,
+		&i.Phone,
+		&i.Metadata,
+		&i.AvatarFileID,
+		&i.AvatarFileName,
+		&i.LastLoginAt,
 	)
 	return i, err
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, name, email, created_at, updated_at, password_hash, role, email_verified, email_verification_token, email_verification_expires_at, password_reset_token, password_reset_expires_at FROM users
+SELECT id, name, email, created_at, updated_at, password_hash, role, email_verified, email_verification_token, email_verification_expires_at, password_reset_token, password_reset_expires_at, deletion_requested_at, deletion_scheduled_at, display_name, bio, locale, timezone, phone, metadata, avatar_file_id, avatar_file_name, last_login_at FROM users
 WHERE email = $1 LIMIT 1
 `
 
@@ -232,12 +337,23 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (Users, erro
 		&i.EmailVerificationExpiresAt,
 		&i.PasswordResetToken,
 		&i.PasswordResetExpiresAt,
+		&i.DeletionRequestedAt,
+		&i.DeletionScheduledAt,
+		&i.DisplayName,
+		&i.Bio,
+		&i.Locale,
+		&i.Timezone,
+		&i.Phone,
+		&i.Metadata,
+		&i.AvatarFileID,
+		&i.AvatarFileName,
+		&i.LastLoginAt,
 	)
 	return i, err
 }
 
 const getUserByEmailWithPassword = `-- name: GetUserByEmailWithPassword :one
-SELECT id, name, email, created_at, updated_at, password_hash, role, email_verified, email_verification_token, email_verification_expires_at, password_reset_token, password_reset_expires_at FROM users
+SELECT id, name, email, created_at, updated_at, password_hash, role, email_verified, email_verification_token, email_verification_expires_at, password_reset_token, password_reset_expires_at, deletion_requested_at, deletion_scheduled_at, display_name, bio, locale, timezone, phone, metadata, avatar_file_id, avatar_file_name, last_login_at FROM users
 WHERE email = $1 LIMIT 1
 `
 
@@ -257,12 +373,23 @@ func (q *Queries) GetUserByEmailWithPassword(ctx context.Context, email string)
 		&i.EmailVerificationExpiresAt,
 		&i.PasswordResetToken,
 		&i.PasswordResetExpiresAt,
+		&i.DeletionRequestedAt,
+		&i.DeletionScheduledAt,
+		&i.DisplayName,
+		&i.Bio,
+		&i.Locale,
+		&i.Timezone,
+		&i.Phone,
+		&i.Metadata,
+		&i.AvatarFileID,
+		&i.AvatarFileName,
+		&i.LastLoginAt,
 	)
 	return i, err
 }
 
 const getUserByPasswordResetToken = `-- name: GetUserByPasswordResetToken :one
-SELECT id, name, email, created_at, updated_at, password_hash, role, email_verified, email_verification_token, email_verification_expires_at, password_reset_token, password_reset_expires_at FROM users
+SELECT id, name, email, created_at, updated_at, password_hash, role, email_verified, email_verification_token, email_verification_expires_at, password_reset_token, password_reset_expires_at, deletion_requested_at, deletion_scheduled_at, display_name, bio, locale, timezone, phone, metadata, avatar_file_id, avatar_file_name, last_login_at FROM users
 WHERE password_reset_token = $1 LIMIT 1
 `
 
@@ -282,12 +409,23 @@ func (q *Queries) GetUserByPasswordResetToken(ctx context.Context, passwordReset
 		&i.EmailVerificationExpiresAt,
 		&i.PasswordResetToken,
 		&i.PasswordResetExpiresAt,
+		&i.DeletionRequestedAt,
+		&i.DeletionScheduledAt,
+		&i.DisplayName,
+		&i.Bio,
+		&i.Locale,
+		&i.Timezone,
+		&i.Phone,
+		&i.Metadata,
+		&i.AvatarFileID,
+		&i.AvatarFileName,
+		&i.LastLoginAt,
 	)
 	return i, err
 }
 
 const getUserByVerificationToken = `-- name: GetUserByVerificationToken :one
-SELECT id, name, email, created_at, updated_at, password_hash, role, email_verified, email_verification_token, email_verification_expires_at, password_reset_token, password_reset_expires_at FROM users
+SELECT id, name, email, created_at, updated_at, password_hash, role, email_verified, email_verification_token, email_verification_expires_at, password_reset_token, password_reset_expires_at, deletion_requested_at, deletion_scheduled_at, display_name, bio, locale, timezone, phone, metadata, avatar_file_id, avatar_file_name, last_login_at FROM users
 WHERE email_verification_token = $1 LIMIT 1
 `
 
@@ -307,12 +445,136 @@ func (q *Queries) GetUserByVerificationToken(ctx context.Context, emailVerificat
 		&i.EmailVerificationExpiresAt,
 		&i.PasswordResetToken,
 		&i.PasswordResetExpiresAt,
+		&i.DeletionRequestedAt,
+		&i.DeletionScheduledAt,
+		&i.DisplayName,
+		&i.Bio,
+		&i.Locale,
+		&i.Timezone,
+		&i.Phone,
+		&i.Metadata,
+		&i.AvatarFileID,
+		&i.AvatarFileName,
+		&i.LastLoginAt,
 	)
 	return i, err
 }
 
+const getUsersDueForDeletion = `-- name: GetUsersDueForDeletion :many
+SELECT id, name, email, created_at, updated_at, password_hash, role, email_verified, email_verification_token, email_verification_expires_at, password_reset_token, password_reset_expires_at, deletion_requested_at, deletion_scheduled_at, display_name, bio, locale, timezone, phone, metadata, avatar_file_id, avatar_file_name, last_login_at FROM users
+WHERE deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= $1
+ORDER BY deletion_scheduled_at ASC
+`
+
+func (q *Queries) GetUsersDueForDeletion(ctx context.Context, deletionScheduledAt sql.NullTime) ([]Users, error) {
+	rows, err := q.query(ctx, q.getUsersDueForDeletionStmt, getUsersDueForDeletion, deletionScheduledAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Users{}
+	for rows.Next() {
+		var i Users
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Email,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.PasswordHash,
+			&i.Role,
+			&i.EmailVerified,
+			&i.EmailVerificationToken,
+			&i.EmailVerificationExpiresAt,
+			&i.PasswordResetToken,
+			&i.PasswordResetExpiresAt,
+			&i.DeletionRequestedAt,
+			&i.DeletionScheduledAt,
+			&i.DisplayName,
+			&i.Bio,
+			&i.Locale,
+			&i.Timezone,
+			&i.Phone,
+			&i.Metadata,
+			&i.AvatarFileID,
+			&i.AvatarFileName,
+			&i.LastLoginAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listInactiveUsers = `-- name: ListInactiveUsers :many
+SELECT id, name, email, created_at, updated_at, password_hash, role, email_verified, email_verification_token, email_verification_expires_at, password_reset_token, password_reset_expires_at, deletion_requested_at, deletion_scheduled_at, display_name, bio, locale, timezone, phone, metadata, avatar_file_id, avatar_file_name, last_login_at FROM users
+WHERE COALESCE(last_login_at, created_at) < $3::timestamp
+ORDER BY COALESCE(last_login_at, created_at) ASC, id ASC
+LIMIT $1 OFFSET $2
+`
+
+type ListInactiveUsersParams struct {
+	Limit         int32     `db:"limit" json:"limit"`
+	Offset        int32     `db:"offset" json:"offset"`
+	InactiveSince time.Time `db:"inactive_since" json:"inactive_since"`
+}
+
+func (q *Queries) ListInactiveUsers(ctx context.Context, arg ListInactiveUsersParams) ([]Users, error) {
+	rows, err := q.query(ctx, q.listInactiveUsersStmt, listInactiveUsers, arg.Limit, arg.Offset, arg.InactiveSince)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Users{}
+	for rows.Next() {
+		var i Users
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Email,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.PasswordHash,
+			&i.Role,
+			&i.EmailVerified,
+			&i.EmailVerificationToken,
+			&i.EmailVerificationExpiresAt,
+			&i.PasswordResetToken,
+			&i.PasswordResetExpiresAt,
+			&i.DeletionRequestedAt,
+			&i.DeletionScheduledAt,
+			&i.DisplayName,
+			&i.Bio,
+			&i.Locale,
+			&i.Timezone,
+			&i.Phone,
+			&i.Metadata,
+			&i.AvatarFileID,
+			&i.AvatarFileName,
+			&i.LastLoginAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listUsers = `-- name: ListUsers :many
-SELECT id, name, email, created_at, updated_at, password_hash, role, email_verified, email_verification_token, email_verification_expires_at, password_reset_token, password_reset_expires_at FROM users
+SELECT id, name, email, created_at, updated_at, password_hash, role, email_verified, email_verification_token, email_verification_expires_at, password_reset_token, password_reset_expires_at, deletion_requested_at, deletion_scheduled_at, display_name, bio, locale, timezone, phone, metadata, avatar_file_id, avatar_file_name, last_login_at FROM users
 ORDER BY created_at DESC
 LIMIT $1 OFFSET $2
 `
@@ -344,6 +606,144 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]Users,
 			&i.EmailVerificationExpiresAt,
 			&i.PasswordResetToken,
 			&i.PasswordResetExpiresAt,
+			&i.DeletionRequestedAt,
+			&i.DeletionScheduledAt,
+			&i.DisplayName,
+			&i.Bio,
+			&i.Locale,
+			&i.Timezone,
+			&i.Phone,
+			&i.Metadata,
+			&i.AvatarFileID,
+			&i.AvatarFileName,
+			&i.LastLoginAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUsersWithCursor = `-- name: ListUsersWithCursor :many
+SELECT id, name, email, created_at, updated_at, password_hash, role, email_verified, email_verification_token, email_verification_expires_at, password_reset_token, password_reset_expires_at, deletion_requested_at, deletion_scheduled_at, display_name, bio, locale, timezone, phone, metadata, avatar_file_id, avatar_file_name, last_login_at FROM users
+WHERE 
+    ($2::text IS NULL OR name ILIKE '%' || $2::text || '%')
+    AND ($3::text IS NULL OR email ILIKE '%' || $3::text || '%') 
+    AND ($4::text IS NULL OR role = $4::text)
+    AND ($5::boolean IS NULL OR email_verified = $5::boolean)
+    AND ($6::timestamp IS NULL OR created_at >= $6::timestamp)
+    AND ($7::timestamp IS NULL OR created_at <= $7::timestamp)
+    AND ($8::timestamp IS NULL OR last_login_at >= $8::timestamp)
+    AND ($9::timestamp IS NULL OR last_login_at <= $9::timestamp)
+    AND ($10::boolean IS NULL OR (last_login_at IS NULL) = $10::boolean)
+    AND (
+        $11::text IS NULL 
+        OR name ILIKE '%' || $11::text || '%' 
+        OR email ILIKE '%' || $11::text || '%'
+    )
+    AND (
+        $12::integer IS NULL
+        OR ($13::text = 'id' AND $14::text = 'ASC' AND id > $12::integer)
+        OR ($13::text = 'id' AND $14::text = 'DESC' AND id < $12::integer)
+        OR ($13::text = 'name' AND $14::text = 'ASC' AND (name, id) > ($15::text, $12::integer))
+        OR ($13::text = 'name' AND $14::text = 'DESC' AND (name, id) < ($15::text, $12::integer))
+        OR ($13::text = 'email' AND $14::text = 'ASC' AND (email, id) > ($15::text, $12::integer))
+        OR ($13::text = 'email' AND $14::text = 'DESC' AND (email, id) < ($15::text, $12::integer))
+        OR ($13::text = 'role' AND $14::text = 'ASC' AND (role, id) > ($15::text, $12::integer))
+        OR ($13::text = 'role' AND $14::text = 'DESC' AND (role, id) < ($15::text, $12::integer))
+        OR ($13::text = 'created_at' AND $14::text = 'ASC' AND (created_at, id) > ($16::timestamptz, $12::integer))
+        OR ($13::text = 'created_at' AND $14::text = 'DESC' AND (created_at, id) < ($16::timestamptz, $12::integer))
+    )
+ORDER BY
+    CASE WHEN $13::text = 'name' AND $14::text = 'ASC' THEN name END ASC,
+    CASE WHEN $13::text = 'name' AND $14::text = 'DESC' THEN name END DESC,
+    CASE WHEN $13::text = 'email' AND $14::text = 'ASC' THEN email END ASC,
+    CASE WHEN $13::text = 'email' AND $14::text = 'DESC' THEN email END DESC,
+    CASE WHEN $13::text = 'role' AND $14::text = 'ASC' THEN role END ASC,
+    CASE WHEN $13::text = 'role' AND $14::text = 'DESC' THEN role END DESC,
+    CASE WHEN $13::text = 'created_at' AND $14::text = 'ASC' THEN created_at END ASC,
+    CASE WHEN $13::text = 'created_at' AND $14::text = 'DESC' THEN created_at END DESC,
+    CASE WHEN $14::text = 'ASC' THEN id END ASC,
+    CASE WHEN $14::text = 'DESC' THEN id END DESC
+LIMIT $1
+`
+
+type ListUsersWithCursorParams struct {
+	Limit               int32          `db:"limit" json:"limit"`
+	NameFilter          sql.NullString `db:"name_filter" json:"name_filter"`
+	EmailFilter         sql.NullString `db:"email_filter" json:"email_filter"`
+	RoleFilter          sql.NullString `db:"role_filter" json:"role_filter"`
+	EmailVerifiedFilter sql.NullBool   `db:"email_verified_filter" json:"email_verified_filter"`
+	CreatedAfter        sql.NullTime   `db:"created_after" json:"created_after"`
+	CreatedBefore       sql.NullTime   `db:"created_before" json:"created_before"`
+	LastLoginAfter      sql.NullTime   `db:"last_login_after" json:"last_login_after"`
+	LastLoginBefore     sql.NullTime   `db:"last_login_before" json:"last_login_before"`
+	NeverLoggedIn       sql.NullBool   `db:"never_logged_in" json:"never_logged_in"`
+	Search              sql.NullString `db:"search" json:"search"`
+	CursorID            sql.NullInt32  `db:"cursor_id" json:"cursor_id"`
+	SortField           string         `db:"sort_field" json:"sort_field"`
+	SortOrder           string         `db:"sort_order" json:"sort_order"`
+	CursorText          sql.NullString `db:"cursor_text" json:"cursor_text"`
+	CursorTime          sql.NullTime   `db:"cursor_time" json:"cursor_time"`
+}
+
+func (q *Queries) ListUsersWithCursor(ctx context.Context, arg ListUsersWithCursorParams) ([]Users, error) {
+	rows, err := q.query(ctx, q.listUsersWithCursorStmt, listUsersWithCursor,
+		arg.Limit,
+		arg.NameFilter,
+		arg.EmailFilter,
+		arg.RoleFilter,
+		arg.EmailVerifiedFilter,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.LastLoginAfter,
+		arg.LastLoginBefore,
+		arg.NeverLoggedIn,
+		arg.Search,
+		arg.CursorID,
+		arg.SortField,
+		arg.SortOrder,
+		arg.CursorText,
+		arg.CursorTime,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Users{}
+	for rows.Next() {
+		var i Users
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Email,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.PasswordHash,
+			&i.Role,
+			&i.EmailVerified,
+			&i.EmailVerificationToken,
+			&i.EmailVerificationExpiresAt,
+			&i.PasswordResetToken,
+			&i.PasswordResetExpiresAt,
+			&i.DeletionRequestedAt,
+			&i.DeletionScheduledAt,
+			&i.DisplayName,
+			&i.Bio,
+			&i.Locale,
+			&i.Timezone,
+			&i.Phone,
+			&i.Metadata,
+			&i.AvatarFileID,
+			&i.AvatarFileName,
+			&i.LastLoginAt,
 		); err != nil {
 			return nil, err
 		}
@@ -359,7 +759,7 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]Users,
 }
 
 const listUsersWithPaginationAndFilters = `-- name: ListUsersWithPaginationAndFilters :many
-SELECT id, name, email, created_at, updated_at, password_hash, role, email_verified, email_verification_token, email_verification_expires_at, password_reset_token, password_reset_expires_at FROM users
+SELECT id, name, email, created_at, updated_at, password_hash, role, email_verified, email_verification_token, email_verification_expires_at, password_reset_token, password_reset_expires_at, deletion_requested_at, deletion_scheduled_at, display_name, bio, locale, timezone, phone, metadata, avatar_file_id, avatar_file_name, last_login_at FROM users
 WHERE 
     ($3::text IS NULL OR name ILIKE '%' || $3::text || '%')
     AND ($4::text IS NULL OR email ILIKE '%' || $4::text || '%') 
@@ -367,22 +767,27 @@ WHERE
     AND ($6::boolean IS NULL OR email_verified = $6::boolean)
     AND ($7::timestamp IS NULL OR created_at >= $7::timestamp)
     AND ($8::timestamp IS NULL OR created_at <= $8::timestamp)
+    AND ($9::timestamp IS NULL OR last_login_at >= $9::timestamp)
+    AND ($10::timestamp IS NULL OR last_login_at <= $10::timestamp)
+    AND ($11::boolean IS NULL OR (last_login_at IS NULL) = $11::boolean)
     AND (
-        $9::text IS NULL 
-        OR name ILIKE '%' || $9::text || '%' 
-        OR email ILIKE '%' || $9::text || '%'
+        $12::text IS NULL 
+        OR name ILIKE '%' || $12::text || '%' 
+        OR email ILIKE '%' || $12::text || '%'
     )
 ORDER BY
-    CASE WHEN $10::text = 'id' AND $11::text = 'ASC' THEN id END ASC,
-    CASE WHEN $10::text = 'id' AND $11::text = 'DESC' THEN id END DESC,
-    CASE WHEN $10::text = 'name' AND $11::text = 'ASC' THEN name END ASC,
-    CASE WHEN $10::text = 'name' AND $11::text = 'DESC' THEN name END DESC,
-    CASE WHEN $10::text = 'email' AND $11::text = 'ASC' THEN email END ASC,
-    CASE WHEN $10::text = 'email' AND $11::text = 'DESC' THEN email END DESC,
-    CASE WHEN $10::text = 'created_at' AND $11::text = 'ASC' THEN created_at END ASC,
-    CASE WHEN $10::text = 'created_at' AND $11::text = 'DESC' THEN created_at END DESC,
-    CASE WHEN $10::text = 'role' AND $11::text = 'ASC' THEN role END ASC,
-    CASE WHEN $10::text = 'role' AND $11::text = 'DESC' THEN role END DESC,
+    CASE WHEN $13::text = 'id' AND $14::text = 'ASC' THEN id END ASC,
+    CASE WHEN $13::text = 'id' AND $14::text = 'DESC' THEN id END DESC,
+    CASE WHEN $13::text = 'name' AND $14::text = 'ASC' THEN name END ASC,
+    CASE WHEN $13::text = 'name' AND $14::text = 'DESC' THEN name END DESC,
+    CASE WHEN $13::text = 'email' AND $14::text = 'ASC' THEN email END ASC,
+    CASE WHEN $13::text = 'email' AND $14::text = 'DESC' THEN email END DESC,
+    CASE WHEN $13::text = 'created_at' AND $14::text = 'ASC' THEN created_at END ASC,
+    CASE WHEN $13::text = 'created_at' AND $14::text = 'DESC' THEN created_at END DESC,
+    CASE WHEN $13::text = 'role' AND $14::text = 'ASC' THEN role END ASC,
+    CASE WHEN $13::text = 'role' AND $14::text = 'DESC' THEN role END DESC,
+    CASE WHEN $13::text = 'last_login_at' AND $14::text = 'ASC' THEN last_login_at END ASC NULLS FIRST,
+    CASE WHEN $13::text = 'last_login_at' AND $14::text = 'DESC' THEN last_login_at END DESC NULLS LAST,
     created_at DESC
 LIMIT $1 OFFSET $2
 `
@@ -396,6 +801,9 @@ type ListUsersWithPaginationAndFiltersParams struct {
 	EmailVerifiedFilter sql.NullBool   `db:"email_verified_filter" json:"email_verified_filter"`
 	CreatedAfter        sql.NullTime   `db:"created_after" json:"created_after"`
 	CreatedBefore       sql.NullTime   `db:"created_before" json:"created_before"`
+	LastLoginAfter      sql.NullTime   `db:"last_login_after" json:"last_login_after"`
+	LastLoginBefore     sql.NullTime   `db:"last_login_before" json:"last_login_before"`
+	NeverLoggedIn       sql.NullBool   `db:"never_logged_in" json:"never_logged_in"`
 	Search              sql.NullString `db:"search" json:"search"`
 	SortField           string         `db:"sort_field" json:"sort_field"`
 	SortOrder           string         `db:"sort_order" json:"sort_order"`
@@ -411,6 +819,9 @@ func (q *Queries) ListUsersWithPaginationAndFilters(ctx context.Context, arg Lis
 		arg.EmailVerifiedFilter,
 		arg.CreatedAfter,
 		arg.CreatedBefore,
+		arg.LastLoginAfter,
+		arg.LastLoginBefore,
+		arg.NeverLoggedIn,
 		arg.Search,
 		arg.SortField,
 		arg.SortOrder,
@@ -435,6 +846,17 @@ func (q *Queries) ListUsersWithPaginationAndFilters(ctx context.Context, arg Lis
 			&i.EmailVerificationExpiresAt,
 			&i.PasswordResetToken,
 			&i.PasswordResetExpiresAt,
+			&i.DeletionRequestedAt,
+			&i.DeletionScheduledAt,
+			&i.DisplayName,
+			&i.Bio,
+			&i.Locale,
+			&i.Timezone,
+			&i.Phone,
+			&i.Metadata,
+			&i.AvatarFileID,
+			&i.AvatarFileName,
+			&i.LastLoginAt,
 		); err != nil {
 			return nil, err
 		}
@@ -451,7 +873,8 @@ func (q *Queries) ListUsersWithPaginationAndFilters(ctx context.Context, arg Lis
 
 const resetPassword = `-- name: ResetPassword :exec
 UPDATE users
-SET password_hash = $2, password_reset_token = NULL, password_reset_expires_at = NULL, updated_at = NOW()
+SET password_hash = $2, password_reset_token = NULL, password_reset_expires_at = NULL,
+    email_verified = true, email_verification_token = NULL, email_verification_expires_at = NULL, updated_at = NOW()
 WHERE password_reset_token = $1
 `
 
@@ -460,16 +883,60 @@ type ResetPasswordParams struct {
 	PasswordHash       string         `db:"password_hash" json:"password_hash"`
 }
 
+// The token was delivered to the address, so using it proves the user owns it, like a verification token
 func (q *Queries) ResetPassword(ctx context.Context, arg ResetPasswordParams) error {
 	_, err := q.exec(ctx, q.resetPasswordStmt, resetPassword, arg.PasswordResetToken, arg.PasswordHash)
 	return err
 }
 
+const scheduleUserDeletion = `-- name: ScheduleUserDeletion :one
+UPDATE users
+SET deletion_requested_at = NOW(), deletion_scheduled_at = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING id, name, email, created_at, updated_at, password_hash, role, email_verified, email_verification_token, email_verification_expires_at, password_reset_token, password_reset_expires_at, deletion_requested_at, deletion_scheduled_at, display_name, bio, locale, timezone, phone, metadata, avatar_file_id, avatar_file_name, last_login_at
+`
+
+type ScheduleUserDeletionParams struct {
+	ID                  int32        `db:"id" json:"id"`
+	DeletionScheduledAt sql.NullTime `db:"deletion_scheduled_at" json:"deletion_scheduled_at"`
+}
+
+func (q *Queries) ScheduleUserDeletion(ctx context.Context, arg ScheduleUserDeletionParams) (Users, error) {
+	row := q.queryRow(ctx, q.scheduleUserDeletionStmt, scheduleUserDeletion, arg.ID, arg.DeletionScheduledAt)
+	var i Users
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.PasswordHash,
+		&i.Role,
+		&i.EmailVerified,
+		&i.EmailVerificationToken,
+		&i.EmailVerificationExpiresAt,
+		&i.PasswordResetToken,
+		&i.PasswordResetExpiresAt,
+		&i.DeletionRequestedAt,
+		&i.DeletionScheduledAt,
+		&i.DisplayName,
+		&i.Bio,
+		&i.Locale,
+		&i.Timezone,
+		&i.Phone,
+		&i.Metadata,
+		&i.AvatarFileID,
+		&i.AvatarFileName,
+		&i.LastLoginAt,
+	)
+	return i, err
+}
+
 const updateEmailVerification = `-- name: UpdateEmailVerification :one
 UPDATE users
 SET email_verified = $2, email_verification_token = NULL, email_verification_expires_at = NULL, updated_at = NOW()
 WHERE id = $1
-RETURNING id, name, email, created_at, updated_at, password_hash, role, email_verified, email_verification_token, email_verification_expires_at, password_reset_token, password_reset_expires_at
+RETURNING id, name, email, created_at, updated_at, password_hash, role, email_verified, email_verification_token, email_verification_expires_at, password_reset_token, password_reset_expires_at, deletion_requested_at, deletion_scheduled_at, display_name, bio, locale, timezone, phone, metadata, avatar_file_id, avatar_file_name, last_login_at
 `
 
 type UpdateEmailVerificationParams struct {
@@ -493,6 +960,17 @@ func (q *Queries) UpdateEmailVerification(ctx context.Context, arg UpdateEmailVe
 		&i.EmailVerificationExpiresAt,
 		&i.PasswordResetToken,
 		&i.PasswordResetExpiresAt,
+		&i.DeletionRequestedAt,
+		&i.DeletionScheduledAt,
+		&i.DisplayName,
+		&i.Bio,
+		&i.Locale,
+		&i.Timezone,
+		&i.Phone,
+		&i.Metadata,
+		&i.AvatarFileID,
+		&i.AvatarFileName,
+		&i.LastLoginAt,
 	)
 	return i, err
 }
@@ -501,7 +979,7 @@ const updateEmailVerificationToken = `-- name: UpdateEmailVerificationToken :one
 UPDATE users
 SET email_verification_token = $2, email_verification_expires_at = $3, updated_at = NOW()
 WHERE id = $1
-RETURNING id, name, email, created_at, updated_at, password_hash, role, email_verified, email_verification_token, email_verification_expires_at, password_reset_token, password_reset_expires_at
+RETURNING id, name, email, created_at, updated_at, password_hash, role, email_verified, email_verification_token, email_verification_expires_at, password_reset_token, password_reset_expires_at, deletion_requested_at, deletion_scheduled_at, display_name, bio, locale, timezone, phone, metadata, avatar_file_id, avatar_file_name, last_login_at
 `
 
 type UpdateEmailVerificationTokenParams struct {
@@ -526,6 +1004,17 @@ func (q *Queries) UpdateEmailVerificationToken(ctx context.Context, arg UpdateEm
 		&i.EmailVerificationExpiresAt,
 		&i.PasswordResetToken,
 		&i.PasswordResetExpiresAt,
+		&i.DeletionRequestedAt,
+		&i.DeletionScheduledAt,
+		&i.DisplayName,
+		&i.Bio,
+		&i.Locale,
+		&i.Timezone,
+		&i.Phone,
+		&i.Metadata,
+		&i.AvatarFileID,
+		&i.AvatarFileName,
+		&i.LastLoginAt,
 	)
 	return i, err
 }
@@ -551,7 +1040,7 @@ const updateUser = `-- name: UpdateUser :one
 UPDATE users
 SET name = $2, updated_at = NOW()
 WHERE id = $1
-RETURNING id, name, email, created_at, updated_at, password_hash, role, email_verified, email_verification_token, email_verification_expires_at, password_reset_token, password_reset_expires_at
+RETURNING id, name, email, created_at, updated_at, password_hash, role, email_verified, email_verification_token, email_verification_expires_at, password_reset_token, password_reset_expires_at, deletion_requested_at, deletion_scheduled_at, display_name, bio, locale, timezone, phone, metadata, avatar_file_id, avatar_file_name, last_login_at
 `
 
 type UpdateUserParams struct {
@@ -575,6 +1064,135 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (Users,
 		&i.EmailVerificationExpiresAt,
 		&i.PasswordResetToken,
 		&i.PasswordResetExpiresAt,
+		&i.DeletionRequestedAt,
+		&i.DeletionScheduledAt,
+		&i.DisplayName,
+		&i.Bio,
+		&i.Locale,
+		&i.Timezone,
+		&i.Phone,
+		&i.Metadata,
+		&i.AvatarFileID,
+		&i.AvatarFileName,
+		&i.LastLoginAt,
+	)
+	return i, err
+}
+
+const updateUserAvatar = `-- name: UpdateUserAvatar :one
+UPDATE users
+SET avatar_file_id = $2, avatar_file_name = $3, updated_at = NOW()
+WHERE id = $1
+RETURNING id, name, email, created_at, updated_at, password_hash, role, email_verified, email_verification_token, email_verification_expires_at, password_reset_token, password_reset_expires_at, deletion_requested_at, deletion_scheduled_at, display_name, bio, locale, timezone, phone, metadata, avatar_file_id, avatar_file_name, last_login_at
+`
+
+type UpdateUserAvatarParams struct {
+	ID             int32          `db:"id" json:"id"`
+	AvatarFileID   sql.NullInt32  `db:"avatar_file_id" json:"avatar_file_id"`
+	AvatarFileName sql.NullString `db:"avatar_file_name" json:"avatar_file_name"`
+}
+
+func (q *Queries) UpdateUserAvatar(ctx context.Context, arg UpdateUserAvatarParams) (Users, error) {
+	row := q.queryRow(ctx, q.updateUserAvatarStmt, updateUserAvatar, arg.ID, arg.AvatarFileID, arg.AvatarFileName)
+	var i Users
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.PasswordHash,
+		&i.Role,
+		&i.EmailVerified,
+		&i.EmailVerificationToken,
+		&i.EmailVerificationExpiresAt,
+		&i.PasswordResetToken,
+		&i.PasswordResetExpiresAt,
+		&i.DeletionRequestedAt,
+		&i.DeletionScheduledAt,
+		&i.DisplayName,
+		&i.Bio,
+		&i.Locale,
+		&i.Timezone,
+		&i.Phone,
+		&i.Metadata,
+		&i.AvatarFileID,
+		&i.AvatarFileName,
+		&i.LastLoginAt,
+	)
+	return i, err
+}
+
+const updateUserLastLogin = `-- name: UpdateUserLastLogin :exec
+UPDATE users
+SET last_login_at = $2
+WHERE id = $1
+`
+
+type UpdateUserLastLoginParams struct {
+	ID          int32        `db:"id" json:"id"`
+	LastLoginAt sql.NullTime `db:"last_login_at" json:"last_login_at"`
+}
+
+func (q *Queries) UpdateUserLastLogin(ctx context.Context, arg UpdateUserLastLoginParams) error {
+	_, err := q.exec(ctx, q.updateUserLastLoginStmt, updateUserLastLogin, arg.ID, arg.LastLoginAt)
+	return err
+}
+
+const updateUserProfile = `-- name: UpdateUserProfile :one
+UPDATE users
+SET name = $2, display_name = $3, bio = $4, locale = $5, timezone = $6, phone = $7, metadata = $8, updated_at = NOW()
+WHERE id = $1
+RETURNING id, name, email, created_at, updated_at, password_hash, role, email_verified, email_verification_token, email_verification_expires_at, password_reset_token, password_reset_expires_at, deletion_requested_at, deletion_scheduled_at, display_name, bio, locale, timezone, phone, metadata, avatar_file_id, avatar_file_name, last_login_at
+`
+
+type UpdateUserProfileParams struct {
+	ID          int32           `db:"id" json:"id"`
+	Name        string          `db:"name" json:"name"`
+	DisplayName sql.NullString  `db:"display_name" json:"display_name"`
+	Bio         sql.NullString  `db:"bio" json:"bio"`
+	Locale      sql.NullString  `db:"locale" json:"locale"`
+	Timezone    sql.NullString  `db:"timezone" json:"timezone"`
+	Phone       sql.NullString  `db:"phone" json:"phone"`
+	Metadata    json.RawMessage `db:"metadata" json:"metadata"`
+}
+
+func (q *Queries) UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) (Users, error) {
+	row := q.queryRow(ctx, q.updateUserProfileStmt, updateUserProfile,
+		arg.ID,
+		arg.Name,
+		arg.DisplayName,
+		arg.Bio,
+		arg.Locale,
+		arg.Timezone,
+		arg.Phone,
+		arg.Metadata,
+	)
+	var i Users
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.PasswordHash,
+		&i.Role,
+		&i.EmailVerified,
+		&i.EmailVerificationToken,
+		&i.EmailVerificationExpiresAt,
+		&i.PasswordResetToken,
+		&i.PasswordResetExpiresAt,
+		&i.DeletionRequestedAt,
+		&i.DeletionScheduledAt,
+		&i.DisplayName,
+		&i.Bio,
+		&i.Locale,
+		&i.Timezone,
+		&i.Phone,
+		&i.Metadata,
+		&i.AvatarFileID,
+		&i.AvatarFileName,
+		&i.LastLoginAt,
 	)
 	return i, err
 }
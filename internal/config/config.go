@@ -3,18 +3,28 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	_ "github.com/joho/godotenv/autoload"
 )
 
 type Config struct {
-	App      AppConfig
-	Database DatabaseConfig
-	JWT      JWTConfig
-	Server   ServerConfig
-	Upload   UploadConfig
-	Email    EmailConfig
+	App        AppConfig
+	Database   DatabaseConfig
+	JWT        JWTConfig
+	Server     ServerConfig
+	Upload     UploadConfig
+	Quota      QuotaConfig
+	Storage    StorageConfig
+	FileURL    FileURLConfig
+	Integrity  IntegrityConfig
+	Images     ImageConfig
+	Scan       ScanConfig
+	Email      EmailConfig
+	Account    AccountConfig
+	Pagination PaginationConfig
+	Errors     ErrorConfig
 }
 
 type AppConfig struct {
@@ -45,13 +55,75 @@ type ServerConfig struct {
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
 	IdleTimeout  time.Duration
+	// TrustedProxies are the IP addresses or CIDR ranges of reverse proxies whose X-Forwarded-For
+	// header is trusted; without any, the client IP is the address of the connection
+	TrustedProxies []string
 }
 
 type UploadConfig struct {
-	MaxFileSize   int64
-	AllowedTypes  []string
-	UploadPath    string
-	BaseURL       string
+	MaxFileSize     int64
+	AllowedTypes    []string
+	UploadPath      string
+	BaseURL         string
+	TusMaxSize      int64         // Largest resumable upload accepted
+	TusExpiration   time.Duration // Unfinished resumable uploads are discarded after this long without progress
+	StripMetadata   bool          // Remove EXIF, XMP and other identifying metadata from JPEG and PNG uploads
+	ExtractMetadata bool          // Record dimensions, page counts and durations of uploads
+	MaxVersions     int           // Earlier versions kept per file; older ones are deleted when a new version is uploaded
+}
+
+// QuotaConfig limits the storage of users by role; 0 means unlimited. Admins can override the quotas
+// of individual users.
+type QuotaConfig struct {
+	UserBytes      int64 // Bytes of file content, earlier versions of files included
+	UserFiles      int
+	ModeratorBytes int64
+	ModeratorFiles int
+	AdminBytes     int64
+	AdminFiles     int
+}
+
+// StorageConfig selects where uploaded files and data exports are stored
+type StorageConfig struct {
+	Driver            string // local or s3
+	S3Endpoint        string
+	S3Region          string
+	S3Bucket          string
+	S3AccessKeyID     string
+	S3SecretAccessKey string
+	S3UsePathStyle    bool
+}
+
+// FileURLConfig controls the signed URLs stored files and avatars are served from
+type FileURLConfig struct {
+	Secret           string
+	TTL              time.Duration // Lifetime of signed file URLs
+	AvatarTTL        time.Duration // Lifetime of signed avatar URLs, which clients tend to cache
+	BindToIP         bool          // Only accept signed URLs from the IP they were issued to
+	PublicCategories []string      // Files in these categories get permanent, unsigned URLs
+}
+
+// IntegrityConfig schedules the background verification of stored file content against its checksum
+type IntegrityConfig struct {
+	Interval      time.Duration
+	BatchSize     int           // Stored contents verified per run
+	ReverifyAfter time.Duration // Verified content is checked again after this long
+}
+
+// ImageConfig controls the variants, such as thumbnails, derived from uploaded images
+type ImageConfig struct {
+	Variants       []string // name:WIDTHxHEIGHT[:crop][:format] variants rendered after every image upload
+	OnDemandWidths []int    // Widths served as w<width> variants, rendered on first request
+	Workers        int      // Background workers rendering variants
+}
+
+// ScanConfig controls the malware scanning of uploaded files; uploads are not scanned without a clamd address
+type ScanConfig struct {
+	ClamdAddress  string        // tcp://host:port, host:port or unix:///path/to/clamd.sock
+	Timeout       time.Duration // Longest a single scan may take
+	Workers       int           // Background workers scanning uploads
+	RetryInterval time.Duration // Files still pending after this long are scanned again
+	MaxSize       int64         // Largest upload accepted while scanning, matching clamd's StreamMaxLength; 0 for no limit
 }
 
 type EmailConfig struct {
@@ -64,6 +136,24 @@ type EmailConfig struct {
 	BaseURL      string
 }
 
+type AccountConfig struct {
+	DeletionGracePeriod time.Duration
+	ExportPath          string
+	ExportLinkTTL       time.Duration
+	ExportTimeout       time.Duration // Exports still unfinished after this long were interrupted and are marked failed
+	CleanupInterval     time.Duration
+	InviteTTL           time.Duration
+}
+
+type PaginationConfig struct {
+	CursorSecret string
+}
+
+type ErrorConfig struct {
+	ProblemDetails bool
+	TypeBaseURL    string
+}
+
 func Load() *Config {
 	return &Config{
 		App: AppConfig{
@@ -87,16 +177,63 @@ func Load() *Config {
 			RefreshExpiresIn: getEnvAsDuration("JWT_REFRESH_EXPIRES_IN", "168h"), // 7 days
 		},
 		Server: ServerConfig{
-			Port:         getEnvAsInt("PORT", 8080),
-			ReadTimeout:  getEnvAsDuration("SERVER_READ_TIMEOUT", "10s"),
-			WriteTimeout: getEnvAsDuration("SERVER_WRITE_TIMEOUT", "30s"),
-			IdleTimeout:  getEnvAsDuration("SERVER_IDLE_TIMEOUT", "60s"),
+			Port:           getEnvAsInt("PORT", 8080),
+			ReadTimeout:    getEnvAsDuration("SERVER_READ_TIMEOUT", "10s"),
+			WriteTimeout:   getEnvAsDuration("SERVER_WRITE_TIMEOUT", "30s"),
+			IdleTimeout:    getEnvAsDuration("SERVER_IDLE_TIMEOUT", "60s"),
+			TrustedProxies: getEnvAsSlice("SERVER_TRUSTED_PROXIES", nil),
 		},
 		Upload: UploadConfig{
-			MaxFileSize:  getEnvAsInt64("UPLOAD_MAX_FILE_SIZE", 10*1024*1024), // 10MB
-			AllowedTypes: []string{"image/jpeg", "image/png", "image/gif", "application/pdf", "text/plain"},
-			UploadPath:   getEnv("UPLOAD_PATH", "uploads"),
-			BaseURL:      getEnv("BASE_URL", "http://localhost:8080"),
+			MaxFileSize:     getEnvAsInt64("UPLOAD_MAX_FILE_SIZE", 10*1024*1024), // 10MB
+			AllowedTypes:    []string{"image/jpeg", "image/png", "image/gif", "application/pdf", "text/plain"},
+			UploadPath:      getEnv("UPLOAD_PATH", "uploads"),
+			BaseURL:         getEnv("BASE_URL", "http://localhost:8080"),
+			TusMaxSize:      getEnvAsInt64("UPLOAD_TUS_MAX_SIZE", 1024*1024*1024), // 1GB
+			TusExpiration:   getEnvAsDuration("UPLOAD_TUS_EXPIRATION", "24h"),
+			StripMetadata:   getEnvAsBool("UPLOAD_STRIP_METADATA", true),
+			ExtractMetadata: getEnvAsBool("UPLOAD_EXTRACT_METADATA", true),
+			MaxVersions:     getEnvAsInt("UPLOAD_MAX_VERSIONS", 10),
+		},
+		Quota: QuotaConfig{
+			UserBytes:      getEnvAsInt64("QUOTA_USER_BYTES", 1024*1024*1024), // 1GB
+			UserFiles:      getEnvAsInt("QUOTA_USER_FILES", 1000),
+			ModeratorBytes: getEnvAsInt64("QUOTA_MODERATOR_BYTES", 5*1024*1024*1024), // 5GB
+			ModeratorFiles: getEnvAsInt("QUOTA_MODERATOR_FILES", 5000),
+			AdminBytes:     getEnvAsInt64("QUOTA_ADMIN_BYTES", 0),
+			AdminFiles:     getEnvAsInt("QUOTA_ADMIN_FILES", 0),
+		},
+		Storage: StorageConfig{
+			Driver:            getEnv("STORAGE_DRIVER", "local"),
+			S3Endpoint:        getEnv("STORAGE_S3_ENDPOINT", ""),
+			S3Region:          getEnv("STORAGE_S3_REGION", "us-east-1"),
+			S3Bucket:          getEnv("STORAGE_S3_BUCKET", ""),
+			S3AccessKeyID:     getEnv("STORAGE_S3_ACCESS_KEY_ID", ""),
+			S3SecretAccessKey: getEnv("STORAGE_S3_SECRET_ACCESS_KEY", ""),
+			S3UsePathStyle:    getEnvAsBool("STORAGE_S3_USE_PATH_STYLE", false),
+		},
+		FileURL: FileURLConfig{
+			Secret:           getEnv("FILE_URL_SECRET", "your-super-secret-file-url-key-change-this-in-production"),
+			TTL:              getEnvAsDuration("FILE_URL_TTL", "15m"),
+			AvatarTTL:        getEnvAsDuration("FILE_URL_AVATAR_TTL", "24h"),
+			BindToIP:         getEnvAsBool("FILE_URL_BIND_IP", false),
+			PublicCategories: getEnvAsSlice("FILE_URL_PUBLIC_CATEGORIES", nil),
+		},
+		Integrity: IntegrityConfig{
+			Interval:      getEnvAsDuration("FILE_INTEGRITY_INTERVAL", "1h"),
+			BatchSize:     getEnvAsInt("FILE_INTEGRITY_BATCH_SIZE", 100),
+			ReverifyAfter: getEnvAsDuration("FILE_INTEGRITY_REVERIFY_AFTER", "720h"), // 30 days
+		},
+		Images: ImageConfig{
+			Variants:       getEnvAsSlice("IMAGE_VARIANTS", []string{"thumbnail:200x200:crop", "medium:1024x1024", "webp:1600x1600:webp"}),
+			OnDemandWidths: getEnvAsIntSlice("IMAGE_VARIANT_WIDTHS", []int{160, 320, 640, 1280}),
+			Workers:        getEnvAsInt("IMAGE_VARIANT_WORKERS", 2),
+		},
+		Scan: ScanConfig{
+			ClamdAddress:  getEnv("SCAN_CLAMD_ADDRESS", ""),
+			Timeout:       getEnvAsDuration("SCAN_TIMEOUT", "2m"),
+			Workers:       getEnvAsInt("SCAN_WORKERS", 2),
+			RetryInterval: getEnvAsDuration("SCAN_RETRY_INTERVAL", "5m"),
+			MaxSize:       getEnvAsInt64("SCAN_MAX_SIZE", 25*1024*1024), // 25MB, clamd's default
 		},
 		Email: EmailConfig{
 			SMTPHost:     getEnv("SMTP_HOST", "smtp.gmail.com"),
@@ -107,6 +244,21 @@ func Load() *Config {
 			FromName:     getEnv("SMTP_FROM_NAME", "Go Template"),
 			BaseURL:      getEnv("BASE_URL", "http://localhost:8080"),
 		},
+		Account: AccountConfig{
+			DeletionGracePeriod: getEnvAsDuration("ACCOUNT_DELETION_GRACE_PERIOD", "336h"), // 14 days
+			ExportPath:          getEnv("ACCOUNT_EXPORT_PATH", "exports"), // Storage key prefix of export archives
+			ExportLinkTTL:       getEnvAsDuration("ACCOUNT_EXPORT_LINK_TTL", "48h"),
+			ExportTimeout:       getEnvAsDuration("ACCOUNT_EXPORT_TIMEOUT", "1h"),
+			CleanupInterval:     getEnvAsDuration("ACCOUNT_CLEANUP_INTERVAL", "1h"),
+			InviteTTL:           getEnvAsDuration("ACCOUNT_INVITE_TTL", "72h"), // 3 days
+		},
+		Pagination: PaginationConfig{
+			CursorSecret: getEnv("PAGINATION_CURSOR_SECRET", "your-super-secret-cursor-key-change-this-in-production"),
+		},
+		Errors: ErrorConfig{
+			ProblemDetails: getEnvAsBool("ERRORS_PROBLEM_DETAILS", false),
+			TypeBaseURL:    getEnv("ERRORS_TYPE_BASE_URL", ""),
+		},
 	}
 }
 
@@ -154,4 +306,35 @@ func getEnvAsDuration(key string, defaultValue string) time.Duration {
 		return duration
 	}
 	return time.Hour
-}
\ No newline at end of file
+}
+
+// getEnvAsSlice splits a comma-separated variable, ignoring blank entries
+func getEnvAsSlice(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var values []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			values = append(values, item)
+		}
+	}
+	return values
+}
+
+func getEnvAsIntSlice(key string, defaultValue []int) []int {
+	var values []int
+	for _, item := range getEnvAsSlice(key, nil) {
+		intValue, err := strconv.Atoi(item)
+		if err != nil {
+			return defaultValue
+		}
+		values = append(values, intValue)
+	}
+	if values == nil {
+		return defaultValue
+	}
+	return values
+}
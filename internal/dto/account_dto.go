@@ -0,0 +1,23 @@
+package dto
+
+import "time"
+
+// DeleteAccountRequest represents self-service account deletion request
+type DeleteAccountRequest struct {
+	Password string `json:"password" validate:"required"`
+}
+
+// AccountDeletionResponse represents the state of a scheduled account deletion
+type AccountDeletionResponse struct {
+	Message             string     `json:"message"`
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"`
+}
+
+// DataExportResponse represents a personal data export request
+type DataExportResponse struct {
+	ID          int        `json:"id"`
+	Status      string     `json:"status"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
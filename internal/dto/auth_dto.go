@@ -17,6 +17,12 @@ type LoginRequest struct {
 	Password string `json:"password" validate:"required"`
 }
 
+// ClientInfo describes the client a request originated from, recorded in the login history
+type ClientInfo struct {
+	IPAddress string
+	UserAgent string
+}
+
 // RefreshTokenRequest represents refresh token request
 type RefreshTokenRequest struct {
 	RefreshToken string `json:"refresh_token" validate:"required"`
@@ -0,0 +1,30 @@
+package dto
+
+// BulkFileRequest selects up to 100 files by ID; duplicate IDs are processed once
+type BulkFileRequest struct {
+	IDs []int `json:"ids" validate:"required,min=1,max=100,dive,min=1"`
+}
+
+// BulkUpdateCategoryRequest sets the category of the selected files; an empty category clears it
+type BulkUpdateCategoryRequest struct {
+	IDs      []int  `json:"ids" validate:"required,min=1,max=100,dive,min=1"`
+	Category string `json:"category" validate:"max=50"`
+}
+
+// BulkFileResult is the outcome for one file of a bulk operation
+type BulkFileResult struct {
+	ID      int           `json:"id"`
+	Success bool          `json:"success"`
+	Code    string        `json:"code,omitempty"`    // Error code when the operation failed for the file
+	Message string        `json:"message,omitempty"` // Error message when the operation failed for the file
+	File    *FileResponse `json:"file,omitempty"`    // The updated file, for updates
+}
+
+// BulkFileResponse summarizes a bulk operation; files are processed independently, so some can
+// fail while the others succeed
+type BulkFileResponse struct {
+	Total     int              `json:"total"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Results   []BulkFileResult `json:"results"`
+}
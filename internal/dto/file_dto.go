@@ -5,6 +5,18 @@ import "time"
 type UploadFileRequest struct {
 	Description string `form:"description" validate:"omitempty,max=500"`
 	Category    string `form:"category" validate:"omitempty,max=50"`
+	FolderID    *int   `form:"folder_id" validate:"omitempty,min=1"` // Top level when omitted
+}
+
+// CreateUploadRequest describes a resumable upload from its tus Upload-Length and Upload-Metadata headers
+type CreateUploadRequest struct {
+	Length      int64  `json:"upload_length" validate:"gte=0"`
+	Metadata    string `json:"-"`
+	FileName    string `json:"filename" validate:"required,max=255"`
+	FileType    string `json:"filetype" validate:"required"`
+	Description string `json:"description" validate:"omitempty,max=500"`
+	Category    string `json:"category" validate:"omitempty,max=50"`
+	FolderID    *int   `json:"folder_id" validate:"omitempty,min=1"` // Top level when omitted
 }
 
 type FileResponse struct {
@@ -14,14 +26,47 @@ type FileResponse struct {
 	FilePath    string    `json:"file_path"`
 	FileSize    int64     `json:"file_size"`
 	MimeType    string    `json:"mime_type"`
+	Checksum    string    `json:"checksum,omitempty"` // SHA-256 of the content, hex encoded
+	Version     int       `json:"version"` // Number of the current content, increased by every new version
+	ScanStatus  string    `json:"scan_status"` // pending, clean, infected or unscannable; only clean files can be downloaded
+	Metadata    *FileMetadata `json:"metadata,omitempty"` // Extracted from the content, when available
+	VariantURLs map[string]string `json:"variant_urls,omitempty"` // Thumbnails and other variants of images by name
 	Description string    `json:"description"`
 	Category    string    `json:"category"`
 	UploadedBy  int       `json:"uploaded_by"`
+	FolderID    *int      `json:"folder_id,omitempty"` // Omitted for files at the top level
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
 
+// FileMetadata describes the content of a file; fields that do not apply to its type are omitted
+type FileMetadata struct {
+	Width    int     `json:"width,omitempty"`
+	Height   int     `json:"height,omitempty"`
+	Pages    int     `json:"pages,omitempty"`    // Page count of documents
+	Frames   int     `json:"frames,omitempty"`   // Frame count of animations
+	Duration float64 `json:"duration,omitempty"` // Length of animations in seconds
+}
+
+// FileVersionResponse describes a version of the content of a file
+type FileVersionResponse struct {
+	Version      int           `json:"version"`
+	OriginalName string        `json:"original_name"`
+	FileSize     int64         `json:"file_size"`
+	MimeType     string        `json:"mime_type"`
+	Checksum     string        `json:"checksum,omitempty"`
+	ScanStatus   string        `json:"scan_status"`
+	Metadata     *FileMetadata `json:"metadata,omitempty"`
+	Current      bool          `json:"current"`
+	CreatedAt    time.Time     `json:"created_at"` // When the content of the version was uploaded
+}
+
 type UpdateFileRequest struct {
 	Description string `json:"description,omitempty" validate:"omitempty,max=500"`
 	Category    string `json:"category,omitempty" validate:"omitempty,max=50"`
-}
\ No newline at end of file
+}
+
+// MoveFileRequest puts a file into another folder of its owner; a null folder_id moves it to the top level
+type MoveFileRequest struct {
+	FolderID *int `json:"folder_id" validate:"omitempty,min=1"`
+}
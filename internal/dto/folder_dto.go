@@ -0,0 +1,30 @@
+package dto
+
+import "time"
+
+// CreateFolderRequest adds a folder below parent_id, or at the top level when parent_id is omitted
+type CreateFolderRequest struct {
+	Name     string `json:"name" validate:"required,max=255"`
+	ParentID *int   `json:"parent_id" validate:"omitempty,min=1"`
+}
+
+type RenameFolderRequest struct {
+	Name string `json:"name" validate:"required,max=255"`
+}
+
+// MoveFolderRequest puts a folder with its subfolders and files below parent_id; null moves it to the top level
+type MoveFolderRequest struct {
+	ParentID *int `json:"parent_id" validate:"omitempty,min=1"`
+}
+
+// FolderResponse describes a folder; the counts and total_size cover all its subfolders
+type FolderResponse struct {
+	ID          int       `json:"id"`
+	ParentID    *int      `json:"parent_id,omitempty"`
+	Name        string    `json:"name"`
+	FolderCount int       `json:"folder_count"`
+	FileCount   int       `json:"file_count"`
+	TotalSize   int64     `json:"total_size"` // Bytes of the current content of the files, without earlier versions
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
@@ -0,0 +1,38 @@
+package dto
+
+import "time"
+
+// CreateShareLinkRequest describes a share link; without expires_at and max_downloads the link works
+// until it is revoked
+type CreateShareLinkRequest struct {
+	Password     string     `json:"password,omitempty" validate:"omitempty,min=4,max=72"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	MaxDownloads *int       `json:"max_downloads,omitempty" validate:"omitempty,min=1"`
+}
+
+type ShareLinkResponse struct {
+	ID                int        `json:"id"`
+	FileID            int        `json:"file_id"`
+	URL               string     `json:"url"` // Public download URL containing the token
+	PasswordProtected bool       `json:"password_protected"`
+	ExpiresAt         *time.Time `json:"expires_at,omitempty"`
+	MaxDownloads      *int       `json:"max_downloads,omitempty"`
+	DownloadCount     int        `json:"download_count"`
+	Active            bool       `json:"active"` // Not revoked, expired or out of downloads
+	RevokedAt         *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+}
+
+// GrantFileAccessRequest gives a user read or write access to a file; write includes read
+type GrantFileAccessRequest struct {
+	Permission string `json:"permission" validate:"required,oneof=read write"`
+}
+
+type FileGrantResponse struct {
+	FileID     int       `json:"file_id"`
+	UserID     int       `json:"user_id"`
+	Permission string    `json:"permission"`
+	GrantedBy  *int      `json:"granted_by,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
@@ -0,0 +1,17 @@
+package dto
+
+// StorageUsageResponse reports the storage used by a user against their quotas; a quota of 0 is unlimited
+type StorageUsageResponse struct {
+	UsedBytes   int64 `json:"used_bytes"` // Earlier versions of files included
+	FileCount   int   `json:"file_count"`
+	QuotaBytes  int64 `json:"quota_bytes"`
+	QuotaFiles  int   `json:"quota_files"`
+	CustomQuota bool  `json:"custom_quota"` // The quotas are set for the user instead of coming from their role
+}
+
+// UpdateStorageQuotaRequest overrides the quotas of a user; null restores the quota of the user's role
+// and 0 lifts the limit
+type UpdateStorageQuotaRequest struct {
+	QuotaBytes *int64 `json:"quota_bytes" validate:"omitempty,min=0"`
+	QuotaFiles *int   `json:"quota_files" validate:"omitempty,min=0"`
+}
@@ -2,8 +2,6 @@ package dto
 
 import (
 	"time"
-
-	"github.com/go-playground/validator/v10"
 )
 
 type CreateUserRequest struct {
@@ -12,17 +10,57 @@ type CreateUserRequest struct {
 }
 
 type UpdateUserRequest struct {
-	Name string `json:"name,omitempty" validate:"omitempty,min=2,max=100"`
+	Name        string                 `json:"name,omitempty" validate:"omitempty,min=2,max=100"`
+	DisplayName *string                `json:"display_name,omitempty" validate:"omitempty,max=100"`
+	Bio         *string                `json:"bio,omitempty" validate:"omitempty,max=1000"`
+	Locale      *string                `json:"locale,omitempty" validate:"omitempty,bcp47_language_tag"`
+	Timezone    *string                `json:"timezone,omitempty" validate:"omitempty,timezone"`
+	Phone       *string                `json:"phone,omitempty" validate:"omitempty,e164"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
 }
 
 type UserResponse struct {
+	ID                  int                    `json:"id"`
+	Name                string                 `json:"name"`
+	Email               string                 `json:"email"`
+	Role                string                 `json:"role"`
+	EmailVerified       bool                   `json:"email_verified"`
+	DisplayName         *string                `json:"display_name,omitempty"`
+	Bio                 *string                `json:"bio,omitempty"`
+	Locale              *string                `json:"locale,omitempty"`
+	Timezone            *string                `json:"timezone,omitempty"`
+	Phone               *string                `json:"phone,omitempty"`
+	Metadata            map[string]interface{} `json:"metadata,omitempty"`
+	AvatarURL           string                 `json:"avatar_url,omitempty"`
+	AvatarURLs          map[string]string      `json:"avatar_urls,omitempty"`
+	LastLoginAt         *time.Time             `json:"last_login_at,omitempty"`
+	DeletionScheduledAt *time.Time             `json:"deletion_scheduled_at,omitempty"`
+	CreatedAt           time.Time              `json:"created_at"`
+	UpdatedAt           time.Time              `json:"updated_at"`
+}
+
+// UserSummaryResponse is the public view of a user embedded in other resources, e.g. a file's uploader
+type UserSummaryResponse struct {
+	ID          int     `json:"id"`
+	Name        string  `json:"name"`
+	DisplayName *string `json:"display_name,omitempty"`
+	AvatarURL   string  `json:"avatar_url,omitempty"`
+}
+
+type InactiveUserResponse struct {
+	UserResponse
+	LastActivityAt time.Time `json:"last_activity_at"`
+	InactiveDays   int       `json:"inactive_days"`
+}
+
+type LoginEventResponse struct {
 	ID            int       `json:"id"`
-	Name          string    `json:"name"`
-	Email         string    `json:"email"`
-	Role          string    `json:"role"`
-	EmailVerified bool      `json:"email_verified"`
+	Success       bool      `json:"success"`
+	Method        string    `json:"method"`
+	FailureReason *string   `json:"failure_reason,omitempty"`
+	IPAddress     *string   `json:"ip_address,omitempty"`
+	UserAgent     *string   `json:"user_agent,omitempty"`
 	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
 }
 
 type ValidationError struct {
@@ -31,37 +69,3 @@ type ValidationError struct {
 	Value   string `json:"value"`
 	Message string `json:"message"`
 }
-
-func FormatValidationErrors(errs validator.ValidationErrors) []ValidationError {
-	var validationErrors []ValidationError
-	
-	for _, err := range errs {
-		validationErrors = append(validationErrors, ValidationError{
-			Field:   err.Field(),
-			Tag:     err.Tag(),
-			Value:   err.Param(),
-			Message: getValidationMessage(err),
-		})
-	}
-	
-	return validationErrors
-}
-
-func getValidationMessage(err validator.FieldError) string {
-	switch err.Tag() {
-	case "required":
-		return err.Field() + " is required"
-	case "email":
-		return err.Field() + " must be a valid email"
-	case "min":
-		return err.Field() + " must be at least " + err.Param() + " characters"
-	case "max":
-		return err.Field() + " must be at most " + err.Param() + " characters"
-	case "oneof":
-		return err.Field() + " must be one of: " + err.Param()
-	case "password":
-		return err.Field() + " must be at least 8 characters and contain uppercase, lowercase, number, and special character"
-	default:
-		return err.Field() + " is invalid"
-	}
-}
\ No newline at end of file
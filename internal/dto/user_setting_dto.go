@@ -0,0 +1,17 @@
+package dto
+
+// UpdateUserSettingsRequest maps setting keys to new values; a null value resets the key to its default
+type UpdateUserSettingsRequest map[string]interface{}
+
+// UserSettingResponse describes a setting, its current value and how it may be changed
+type UserSettingResponse struct {
+	Key          string      `json:"key"`
+	Value        interface{} `json:"value"`
+	DefaultValue interface{} `json:"default_value"`
+	Type         string      `json:"type"`
+	Options      []string    `json:"options,omitempty"`
+	Min          *int        `json:"min,omitempty"`
+	Max          *int        `json:"max,omitempty"`
+	Locked       bool        `json:"locked"`
+	Description  string      `json:"description"`
+}
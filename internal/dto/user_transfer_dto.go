@@ -0,0 +1,39 @@
+package dto
+
+// Supported formats for bulk user import and export
+const (
+	UserTransferFormatCSV    = "csv"
+	UserTransferFormatNDJSON = "ndjson"
+)
+
+// ImportUserRow represents a single user record of a bulk import file
+type ImportUserRow struct {
+	Name  string `json:"name" validate:"required,min=2,max=100"`
+	Email string `json:"email" validate:"required,email"`
+	Role  string `json:"role,omitempty" validate:"omitempty,oneof=user moderator admin"`
+}
+
+// UserImportOptions controls how a bulk import is processed
+type UserImportOptions struct {
+	Format      string
+	DryRun      bool
+	SendInvites bool
+}
+
+// UserImportRowError describes why a line of a bulk import file was rejected
+type UserImportRowError struct {
+	Line   int               `json:"line"`
+	Email  string            `json:"email,omitempty"`
+	Errors []ValidationError `json:"errors"`
+}
+
+// UserImportResponse summarizes the result of a bulk import
+type UserImportResponse struct {
+	DryRun      bool                 `json:"dry_run"`
+	TotalRows   int                  `json:"total_rows"`
+	ValidRows   int                  `json:"valid_rows"`
+	Created     int                  `json:"created"`
+	Failed      int                  `json:"failed"`
+	InvitesSent int                  `json:"invites_sent"`
+	Errors      []UserImportRowError `json:"errors"`
+}
@@ -0,0 +1,25 @@
+package entity
+
+import (
+	"time"
+)
+
+const (
+	DataExportStatusPending    = "pending"
+	DataExportStatusProcessing = "processing"
+	DataExportStatusCompleted  = "completed"
+	DataExportStatusFailed     = "failed"
+)
+
+type DataExport struct {
+	ID            int        `json:"id"`
+	UserID        int        `json:"user_id"`
+	Status        string     `json:"status"`
+	FilePath      string     `json:"-"`
+	DownloadToken string     `json:"-"` // Never include in JSON responses
+	ErrorMessage  string     `json:"error_message,omitempty"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+	CompletedAt   *time.Time `json:"completed_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
@@ -4,6 +4,14 @@ import (
 	"time"
 )
 
+const (
+	FileScanStatusPending  = "pending"
+	FileScanStatusClean    = "clean"
+	FileScanStatusInfected = "infected"
+	// FileScanStatusUnscannable marks files the scanner refused, such as those over its size limit
+	FileScanStatusUnscannable = "unscannable"
+)
+
 type File struct {
 	ID           int       `json:"id"`
 	FileName     string    `json:"file_name"`
@@ -14,6 +22,23 @@ type File struct {
 	Description  string    `json:"description"`
 	Category     string    `json:"category"`
 	UploadedBy   int       `json:"uploaded_by"`
+	FolderID     *int      `json:"folder_id,omitempty"` // nil for files at the top level
+	Checksum     string    `json:"checksum"` // SHA-256 of the content, empty for files uploaded before checksums
+	ScanStatus    string     `json:"scan_status"` // Malware scan state; only clean files can be downloaded
+	ScanSignature string     `json:"scan_signature,omitempty"` // Name of the malware found in infected files
+	ScannedAt     *time.Time `json:"scanned_at,omitempty"`
+	Metadata     *FileMetadata `json:"metadata,omitempty"` // Extracted from the content; nil when nothing was extracted
+	Version      int       `json:"version"` // Number of the current content, starting at 1
+	ContentUpdatedAt time.Time `json:"content_updated_at"` // When the current content was uploaded
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// FileMetadata describes the content of a file; fields that do not apply to its type are zero
+type FileMetadata struct {
+	Width    int     `json:"width,omitempty"`
+	Height   int     `json:"height,omitempty"`
+	Pages    int     `json:"pages,omitempty"`    // Page count of documents
+	Frames   int     `json:"frames,omitempty"`   // Frame count of animations
+	Duration float64 `json:"duration,omitempty"` // Length of animations in seconds
 }
\ No newline at end of file
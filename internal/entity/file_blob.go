@@ -0,0 +1,19 @@
+package entity
+
+import (
+	"time"
+)
+
+// FileBlob is stored content shared by all files with the same SHA-256 checksum. It is removed once
+// RefCount has dropped to zero.
+type FileBlob struct {
+	Checksum   string     `json:"checksum"`
+	StorageKey string     `json:"storage_key"`
+	Size       int64      `json:"size"`
+	RefCount   int        `json:"ref_count"`
+	VerifiedAt *time.Time `json:"verified_at,omitempty"`
+	Corrupted  bool       `json:"corrupted"`
+	Stored     bool       `json:"stored"` // Content has been written to storage
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
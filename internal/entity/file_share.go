@@ -0,0 +1,38 @@
+package entity
+
+import (
+	"time"
+)
+
+// Permissions that can be granted on a file; write includes read
+const (
+	FilePermissionRead  = "read"
+	FilePermissionWrite = "write"
+)
+
+// FileShareLink lets anyone holding Token download a file until it is revoked, expires or reaches
+// MaxDownloads. The link is protected by a password when PasswordHash is set.
+type FileShareLink struct {
+	ID            int        `json:"id"`
+	FileID        int        `json:"file_id"`
+	CreatedBy     int        `json:"created_by"`
+	Token         string     `json:"-"`
+	PasswordHash  string     `json:"-"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+	MaxDownloads  *int       `json:"max_downloads,omitempty"`
+	DownloadCount int        `json:"download_count"`
+	RevokedAt     *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// FileGrant gives another user than the owner read or write access to a file
+type FileGrant struct {
+	ID         int       `json:"id"`
+	FileID     int       `json:"file_id"`
+	UserID     int       `json:"user_id"`
+	Permission string    `json:"permission"`
+	GrantedBy  *int      `json:"granted_by,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
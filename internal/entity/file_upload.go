@@ -0,0 +1,35 @@
+package entity
+
+import (
+	"time"
+)
+
+// FileUpload is a resumable (tus) upload. Offset counts the bytes received so far; the file is
+// created once it reaches Length.
+type FileUpload struct {
+	ID           string    `json:"id"`
+	UserID       int       `json:"user_id"`
+	Length       int64     `json:"length"`
+	Offset       int64     `json:"offset"`
+	Metadata     string    `json:"metadata,omitempty"`
+	OriginalName string    `json:"original_name"`
+	MimeType     string    `json:"mime_type"`
+	Description  string    `json:"description"`
+	Category     string    `json:"category"`
+	FolderID     *int      `json:"folder_id,omitempty"` // Folder the file is created in; top level when nil
+	FileID       *int      `json:"file_id,omitempty"`
+	HashState    []byte    `json:"-"` // SHA-256 state over the bytes received so far
+	ExpiresAt    time.Time `json:"expires_at"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// FileUploadChunk is stored content of an upload; the body of a PATCH request is stored as one or more
+// chunks of bounded size
+type FileUploadChunk struct {
+	ID         int    `json:"id"`
+	UploadID   string `json:"upload_id"`
+	Offset     int64  `json:"offset"`
+	Size       int64  `json:"size"`
+	StorageKey string `json:"storage_key"`
+}
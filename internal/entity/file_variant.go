@@ -0,0 +1,18 @@
+package entity
+
+import (
+	"time"
+)
+
+// FileVariant is a derivative of an uploaded image, such as a thumbnail, stored alongside it
+type FileVariant struct {
+	ID         int       `json:"id"`
+	FileID     int       `json:"file_id"`
+	Name       string    `json:"name"`
+	StorageKey string    `json:"storage_key"`
+	MimeType   string    `json:"mime_type"`
+	Width      int       `json:"width"`
+	Height     int       `json:"height"`
+	FileSize   int64     `json:"file_size"`
+	CreatedAt  time.Time `json:"created_at"`
+}
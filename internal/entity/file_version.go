@@ -0,0 +1,20 @@
+package entity
+
+import (
+	"time"
+)
+
+// FileVersion is an earlier content of a file, kept when new content was uploaded in its place
+type FileVersion struct {
+	ID           int           `json:"id"`
+	FileID       int           `json:"file_id"`
+	Version      int           `json:"version"`
+	OriginalName string        `json:"original_name"`
+	FilePath     string        `json:"file_path"`
+	FileSize     int64         `json:"file_size"`
+	MimeType     string        `json:"mime_type"`
+	Checksum     string        `json:"checksum"`
+	ScanStatus   string        `json:"scan_status"`
+	Metadata     *FileMetadata `json:"metadata,omitempty"`
+	CreatedAt    time.Time     `json:"created_at"` // When the content of the version was uploaded
+}
@@ -0,0 +1,20 @@
+package entity
+
+import (
+	"time"
+)
+
+// Folder groups the files of a user in a tree. Path lists the IDs of the ancestors, e.g. /3/7/; the
+// counts and TotalSize cover the whole subtree.
+type Folder struct {
+	ID          int       `json:"id"`
+	UserID      int       `json:"user_id"`
+	ParentID    *int      `json:"parent_id,omitempty"`
+	Name        string    `json:"name"`
+	Path        string    `json:"path"`
+	FolderCount int       `json:"folder_count"`
+	FileCount   int       `json:"file_count"`
+	TotalSize   int64     `json:"total_size"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
@@ -0,0 +1,28 @@
+package entity
+
+import (
+	"time"
+)
+
+// Login methods recorded in the login history
+const (
+	LoginMethodPassword = "password"
+)
+
+// Reasons recorded for failed login attempts
+const (
+	LoginFailureUserNotFound    = "user_not_found"
+	LoginFailureInvalidPassword = "invalid_password"
+)
+
+type LoginEvent struct {
+	ID            int       `json:"id"`
+	UserID        *int      `json:"user_id,omitempty"`
+	Email         string    `json:"email"`
+	Success       bool      `json:"success"`
+	Method        string    `json:"method"`
+	FailureReason *string   `json:"failure_reason,omitempty"`
+	IPAddress     *string   `json:"ip_address,omitempty"`
+	UserAgent     *string   `json:"user_agent,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
@@ -4,17 +4,35 @@ import (
 	"time"
 )
 
+// Roles of users; moderators and admins can access the files of all users
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
 type User struct {
-	ID                         int        `json:"id"`
-	Name                       string     `json:"name"`
-	Email                      string     `json:"email"`
-	PasswordHash               string     `json:"-"` // Never include in JSON responses
-	Role                       string     `json:"role"`
-	EmailVerified              bool       `json:"email_verified"`
-	EmailVerificationToken     *string    `json:"-"` // Never include in JSON responses
-	EmailVerificationExpiresAt *time.Time `json:"-"` // Never include in JSON responses
-	PasswordResetToken         *string    `json:"-"` // Never include in JSON responses
-	PasswordResetExpiresAt     *time.Time `json:"-"` // Never include in JSON responses
-	CreatedAt                  time.Time  `json:"created_at"`
-	UpdatedAt                  time.Time  `json:"updated_at"`
+	ID                         int                    `json:"id"`
+	Name                       string                 `json:"name"`
+	Email                      string                 `json:"email"`
+	PasswordHash               string                 `json:"-"` // Never include in JSON responses
+	Role                       string                 `json:"role"`
+	EmailVerified              bool                   `json:"email_verified"`
+	EmailVerificationToken     *string                `json:"-"` // Never include in JSON responses
+	EmailVerificationExpiresAt *time.Time             `json:"-"` // Never include in JSON responses
+	PasswordResetToken         *string                `json:"-"` // Never include in JSON responses
+	PasswordResetExpiresAt     *time.Time             `json:"-"` // Never include in JSON responses
+	DisplayName                *string                `json:"display_name,omitempty"`
+	Bio                        *string                `json:"bio,omitempty"`
+	Locale                     *string                `json:"locale,omitempty"`
+	Timezone                   *string                `json:"timezone,omitempty"`
+	Phone                      *string                `json:"phone,omitempty"`
+	Metadata                   map[string]interface{} `json:"metadata"`
+	AvatarFileID               *int                   `json:"avatar_file_id,omitempty"`
+	AvatarFileName             *string                `json:"-"`
+	LastLoginAt                *time.Time             `json:"last_login_at,omitempty"`
+	DeletionRequestedAt        *time.Time             `json:"deletion_requested_at,omitempty"`
+	DeletionScheduledAt        *time.Time             `json:"deletion_scheduled_at,omitempty"`
+	CreatedAt                  time.Time              `json:"created_at"`
+	UpdatedAt                  time.Time              `json:"updated_at"`
 }
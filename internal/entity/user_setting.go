@@ -0,0 +1,13 @@
+package entity
+
+import (
+	"time"
+)
+
+type UserSetting struct {
+	UserID    int         `json:"user_id"`
+	Key       string      `json:"key"`
+	Value     interface{} `json:"value"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
@@ -0,0 +1,23 @@
+package entity
+
+import (
+	"time"
+)
+
+// UserStorage is the storage used by a user's files, earlier versions included. QuotaBytes and
+// QuotaFiles override the quotas of the user's role when set; 0 means unlimited.
+type UserStorage struct {
+	UserID     int       `json:"user_id"`
+	UsedBytes  int64     `json:"used_bytes"`
+	FileCount  int       `json:"file_count"`
+	QuotaBytes *int64    `json:"quota_bytes,omitempty"`
+	QuotaFiles *int      `json:"quota_files,omitempty"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// StorageQuota holds the quotas of a user's role, which the usage is checked against when files are
+// stored unless UserStorage overrides them; 0 means unlimited
+type StorageQuota struct {
+	Bytes int64
+	Files int
+}
@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"go-template/internal/dto"
+	"go-template/internal/logger"
+	"go-template/internal/service"
+	"go-template/pkg/response"
+	"go-template/pkg/validator"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+type AccountHandler struct {
+	accountService service.AccountService
+	validator      *validator.Validator
+}
+
+func NewAccountHandler(accountService service.AccountService, validator *validator.Validator) *AccountHandler {
+	return &AccountHandler{
+		accountService: accountService,
+		validator:      validator,
+	}
+}
+
+// RequestDataExport godoc
+// @Summary Request a personal data export
+// @Description Queue a ZIP archive with the current user's profile, file metadata and uploaded files. A download link is emailed when it is ready.
+// @Tags User Management
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 202 {object} response.Response{data=dto.DataExportResponse} "Data export queued"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 409 {object} response.Response "Data export already in progress"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /users/me/export [post]
+func (h *AccountHandler) RequestDataExport(c echo.Context) error {
+	requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+	userID := c.Get("user_id").(int) // Set by auth middleware
+	logger.Info("RequestDataExport request started", zap.String("request_id", requestID), zap.Int("user_id", userID))
+
+	export, err := h.accountService.RequestDataExport(c.Request().Context(), userID)
+	if err != nil {
+		logger.Error("Failed to request data export", zap.Error(err), zap.String("request_id", requestID))
+		return err
+	}
+
+	logger.Info("RequestDataExport request completed", zap.String("request_id", requestID))
+	return response.Accepted(c, "Data export queued. You will receive an email with a download link when it is ready.", export)
+}
+
+// DownloadDataExport godoc
+// @Summary Download a personal data export
+// @Description Download a generated data export using the signed token from the notification email
+// @Tags User Management
+// @Produce application/zip
+// @Param token query string true "Download token"
+// @Success 200 {file} file "Data export archive"
+// @Failure 404 {object} response.Response "Invalid or expired download link"
+// @Router /users/me/export/download [get]
+func (h *AccountHandler) DownloadDataExport(c echo.Context) error {
+	requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+	logger.Info("DownloadDataExport request started", zap.String("request_id", requestID))
+
+	archive, info, err := h.accountService.OpenDataExport(c.Request().Context(), c.QueryParam("token"))
+	if err != nil {
+		logger.Warn("Failed to open data export", zap.Error(err), zap.String("request_id", requestID))
+		return err
+	}
+	defer archive.Close()
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, "attachment; filename=\"data-export.zip\"")
+	if info.Size >= 0 {
+		c.Response().Header().Set(echo.HeaderContentLength, strconv.FormatInt(info.Size, 10))
+	}
+
+	logger.Info("DownloadDataExport request completed", zap.String("request_id", requestID), zap.String("key", info.Key))
+	return c.Stream(http.StatusOK, "application/zip", archive)
+}
+
+// DeleteAccount godoc
+// @Summary Delete own account
+// @Description Schedule the current user's account for permanent deletion after a grace period. Requires password confirmation.
+// @Tags User Management
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.DeleteAccountRequest true "Password confirmation"
+// @Success 202 {object} response.Response{data=dto.AccountDeletionResponse} "Account deletion scheduled"
+// @Failure 400 {object} response.Response{error=[]dto.ValidationError} "Validation error"
+// @Failure 401 {object} response.Response "Invalid password"
+// @Failure 409 {object} response.Response "Deletion already scheduled"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /users/me [delete]
+func (h *AccountHandler) DeleteAccount(c echo.Context) error {
+	requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+	userID := c.Get("user_id").(int) // Set by auth middleware
+	logger.Info("DeleteAccount request started", zap.String("request_id", requestID), zap.Int("user_id", userID))
+
+	var req dto.DeleteAccountRequest
+	if err := c.Bind(&req); err != nil {
+		logger.Error("Failed to bind delete account request", zap.Error(err), zap.String("request_id", requestID))
+		return invalidBody(err)
+	}
+
+	// Validate request
+	if validationErrors := h.validator.ValidateStruct(c.Request().Context(), req); validationErrors != nil {
+		logger.Warn("Delete account validation failed", zap.Any("errors", validationErrors), zap.String("request_id", requestID))
+		return response.ValidationError(c, "Validation failed", validationErrors)
+	}
+
+	deletion, err := h.accountService.RequestAccountDeletion(c.Request().Context(), userID, req)
+	if err != nil {
+		logger.Error("Failed to schedule account deletion", zap.Error(err), zap.String("request_id", requestID))
+		return err
+	}
+
+	logger.Info("DeleteAccount request completed", zap.String("request_id", requestID))
+	return response.Accepted(c, deletion.Message, deletion)
+}
+
+// CancelAccountDeletion godoc
+// @Summary Cancel own account deletion
+// @Description Cancel a scheduled account deletion during the grace period
+// @Tags User Management
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=dto.AccountDeletionResponse} "Account deletion cancelled"
+// @Failure 400 {object} response.Response "Deletion not scheduled"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /users/me/deletion/cancel [post]
+func (h *AccountHandler) CancelAccountDeletion(c echo.Context) error {
+	requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+	userID := c.Get("user_id").(int) // Set by auth middleware
+	logger.Info("CancelAccountDeletion request started", zap.String("request_id", requestID), zap.Int("user_id", userID))
+
+	deletion, err := h.accountService.CancelAccountDeletion(c.Request().Context(), userID)
+	if err != nil {
+		logger.Error("Failed to cancel account deletion", zap.Error(err), zap.String("request_id", requestID))
+		return err
+	}
+
+	logger.Info("CancelAccountDeletion request completed", zap.String("request_id", requestID))
+	return response.Success(c, deletion.Message, deletion)
+}
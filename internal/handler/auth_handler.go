@@ -42,11 +42,11 @@ func (h *AuthHandler) Register(c echo.Context) error {
 	var req dto.RegisterRequest
 	if err := c.Bind(&req); err != nil {
 		logger.Error("Failed to bind registration request", zap.Error(err), zap.String("request_id", requestID))
-		return response.BadRequest(c, "Invalid request body", err.Error())
+		return invalidBody(err)
 	}
 
 	// Validate request
-	if validationErrors := h.validator.ValidateStruct(req); validationErrors != nil {
+	if validationErrors := h.validator.ValidateStruct(c.Request().Context(), req); validationErrors != nil {
 		logger.Warn("Registration validation failed", zap.Any("errors", validationErrors), zap.String("request_id", requestID))
 		return response.ValidationError(c, "Validation failed", validationErrors)
 	}
@@ -54,10 +54,7 @@ func (h *AuthHandler) Register(c echo.Context) error {
 	authResponse, err := h.authService.Register(c.Request().Context(), req)
 	if err != nil {
 		logger.Error("Failed to register user", zap.Error(err), zap.String("request_id", requestID))
-		if err == service.ErrUserAlreadyExists {
-			return response.Conflict(c, "User with this email already exists", nil)
-		}
-		return response.BadRequest(c, "Registration failed", err.Error())
+		return err
 	}
 
 	logger.Info("User registration completed successfully", 
@@ -85,22 +82,22 @@ func (h *AuthHandler) Login(c echo.Context) error {
 	var req dto.LoginRequest
 	if err := c.Bind(&req); err != nil {
 		logger.Error("Failed to bind login request", zap.Error(err), zap.String("request_id", requestID))
-		return response.BadRequest(c, "Invalid request body", err.Error())
+		return invalidBody(err)
 	}
 
 	// Validate request
-	if validationErrors := h.validator.ValidateStruct(req); validationErrors != nil {
+	if validationErrors := h.validator.ValidateStruct(c.Request().Context(), req); validationErrors != nil {
 		logger.Warn("Login validation failed", zap.Any("errors", validationErrors), zap.String("request_id", requestID))
 		return response.ValidationError(c, "Validation failed", validationErrors)
 	}
 
-	authResponse, err := h.authService.Login(c.Request().Context(), req)
+	authResponse, err := h.authService.Login(c.Request().Context(), req, dto.ClientInfo{
+		IPAddress: c.RealIP(),
+		UserAgent: c.Request().UserAgent(),
+	})
 	if err != nil {
 		logger.Error("Failed to login user", zap.Error(err), zap.String("request_id", requestID))
-		if err == service.ErrInvalidCredentials {
-			return response.Unauthorized(c, "Invalid email or password")
-		}
-		return response.InternalServerError(c, "Login failed", err.Error())
+		return err
 	}
 
 	logger.Info("User login completed successfully", 
@@ -116,11 +113,11 @@ func (h *AuthHandler) RefreshToken(c echo.Context) error {
 	var req dto.RefreshTokenRequest
 	if err := c.Bind(&req); err != nil {
 		logger.Error("Failed to bind refresh token request", zap.Error(err), zap.String("request_id", requestID))
-		return response.BadRequest(c, "Invalid request body", err.Error())
+		return invalidBody(err)
 	}
 
 	// Validate request
-	if validationErrors := h.validator.ValidateStruct(req); validationErrors != nil {
+	if validationErrors := h.validator.ValidateStruct(c.Request().Context(), req); validationErrors != nil {
 		logger.Warn("Refresh token validation failed", zap.Any("errors", validationErrors), zap.String("request_id", requestID))
 		return response.ValidationError(c, "Validation failed", validationErrors)
 	}
@@ -128,7 +125,7 @@ func (h *AuthHandler) RefreshToken(c echo.Context) error {
 	tokenResponse, err := h.authService.RefreshToken(c.Request().Context(), req)
 	if err != nil {
 		logger.Error("Failed to refresh token", zap.Error(err), zap.String("request_id", requestID))
-		return response.Unauthorized(c, "Invalid refresh token")
+		return err
 	}
 
 	logger.Info("Token refresh completed successfully", zap.String("request_id", requestID))
@@ -156,7 +153,7 @@ func (h *AuthHandler) GetProfile(c echo.Context) error {
 	profile, err := h.authService.GetUserProfile(c.Request().Context(), userID)
 	if err != nil {
 		logger.Error("Failed to get user profile", zap.Error(err), zap.String("request_id", requestID))
-		return response.InternalServerError(c, "Failed to get profile", err.Error())
+		return err
 	}
 
 	logger.Info("Get user profile completed successfully", zap.String("request_id", requestID))
@@ -177,12 +174,12 @@ func (h *AuthHandler) VerifyEmail(c echo.Context) error {
 		// Otherwise, try to bind from request body (for API calls)
 		if err := c.Bind(&req); err != nil {
 			logger.Error("Failed to bind email verification request", zap.Error(err), zap.String("request_id", requestID))
-			return response.BadRequest(c, "Invalid request body", err.Error())
+			return invalidBody(err)
 		}
 	}
 
 	// Validate request
-	if validationErrors := h.validator.ValidateStruct(req); validationErrors != nil {
+	if validationErrors := h.validator.ValidateStruct(c.Request().Context(), req); validationErrors != nil {
 		logger.Warn("Email verification validation failed", zap.Any("errors", validationErrors), zap.String("request_id", requestID))
 		return response.ValidationError(c, "Validation failed", validationErrors)
 	}
@@ -190,13 +187,7 @@ func (h *AuthHandler) VerifyEmail(c echo.Context) error {
 	verifyResponse, err := h.authService.VerifyEmail(c.Request().Context(), req)
 	if err != nil {
 		logger.Error("Failed to verify email", zap.Error(err), zap.String("request_id", requestID))
-		if err == service.ErrInvalidVerificationToken {
-			return response.BadRequest(c, verifyResponse.Message, nil)
-		}
-		if err == service.ErrEmailAlreadyVerified {
-			return response.BadRequest(c, verifyResponse.Message, nil)
-		}
-		return response.InternalServerError(c, "Email verification failed", err.Error())
+		return err
 	}
 
 	logger.Info("Email verification completed successfully", zap.String("request_id", requestID))
@@ -210,11 +201,11 @@ func (h *AuthHandler) ResendVerificationEmail(c echo.Context) error {
 	var req dto.ResendVerificationRequest
 	if err := c.Bind(&req); err != nil {
 		logger.Error("Failed to bind resend verification request", zap.Error(err), zap.String("request_id", requestID))
-		return response.BadRequest(c, "Invalid request body", err.Error())
+		return invalidBody(err)
 	}
 
 	// Validate request
-	if validationErrors := h.validator.ValidateStruct(req); validationErrors != nil {
+	if validationErrors := h.validator.ValidateStruct(c.Request().Context(), req); validationErrors != nil {
 		logger.Warn("Resend verification validation failed", zap.Any("errors", validationErrors), zap.String("request_id", requestID))
 		return response.ValidationError(c, "Validation failed", validationErrors)
 	}
@@ -222,10 +213,7 @@ func (h *AuthHandler) ResendVerificationEmail(c echo.Context) error {
 	resendResponse, err := h.authService.ResendVerificationEmail(c.Request().Context(), req)
 	if err != nil {
 		logger.Error("Failed to resend verification email", zap.Error(err), zap.String("request_id", requestID))
-		if err == service.ErrEmailAlreadyVerified {
-			return response.BadRequest(c, resendResponse.Message, nil)
-		}
-		return response.InternalServerError(c, "Failed to resend verification email", err.Error())
+		return err
 	}
 
 	logger.Info("Resend verification email completed successfully", zap.String("request_id", requestID))
@@ -239,11 +227,11 @@ func (h *AuthHandler) ForgotPassword(c echo.Context) error {
 	var req dto.ForgotPasswordRequest
 	if err := c.Bind(&req); err != nil {
 		logger.Error("Failed to bind forgot password request", zap.Error(err), zap.String("request_id", requestID))
-		return response.BadRequest(c, "Invalid request body", err.Error())
+		return invalidBody(err)
 	}
 
 	// Validate request
-	if validationErrors := h.validator.ValidateStruct(req); validationErrors != nil {
+	if validationErrors := h.validator.ValidateStruct(c.Request().Context(), req); validationErrors != nil {
 		logger.Warn("Forgot password validation failed", zap.Any("errors", validationErrors), zap.String("request_id", requestID))
 		return response.ValidationError(c, "Validation failed", validationErrors)
 	}
@@ -251,7 +239,7 @@ func (h *AuthHandler) ForgotPassword(c echo.Context) error {
 	resetResponse, err := h.authService.ForgotPassword(c.Request().Context(), req)
 	if err != nil {
 		logger.Error("Failed to process forgot password", zap.Error(err), zap.String("request_id", requestID))
-		return response.InternalServerError(c, "Failed to process password reset request", err.Error())
+		return err
 	}
 
 	logger.Info("Forgot password completed successfully", zap.String("request_id", requestID))
@@ -304,7 +292,7 @@ func (h *AuthHandler) ResetPassword(c echo.Context) error {
 	// Bind from request body (will override token if provided in body)
 	if err := c.Bind(&req); err != nil {
 		logger.Error("Failed to bind reset password request", zap.Error(err), zap.String("request_id", requestID))
-		return response.BadRequest(c, "Invalid request body", err.Error())
+		return invalidBody(err)
 	}
 
 	// If token was from query param and not in body, use the query param
@@ -313,7 +301,7 @@ func (h *AuthHandler) ResetPassword(c echo.Context) error {
 	}
 
 	// Validate request
-	if validationErrors := h.validator.ValidateStruct(req); validationErrors != nil {
+	if validationErrors := h.validator.ValidateStruct(c.Request().Context(), req); validationErrors != nil {
 		logger.Warn("Reset password validation failed", zap.Any("errors", validationErrors), zap.String("request_id", requestID))
 		return response.ValidationError(c, "Validation failed", validationErrors)
 	}
@@ -321,10 +309,7 @@ func (h *AuthHandler) ResetPassword(c echo.Context) error {
 	resetResponse, err := h.authService.ResetPassword(c.Request().Context(), req)
 	if err != nil {
 		logger.Error("Failed to reset password", zap.Error(err), zap.String("request_id", requestID))
-		if err == service.ErrInvalidPasswordResetToken {
-			return response.BadRequest(c, resetResponse.Message, nil)
-		}
-		return response.InternalServerError(c, "Failed to reset password", err.Error())
+		return err
 	}
 
 	logger.Info("Reset password completed successfully", zap.String("request_id", requestID))
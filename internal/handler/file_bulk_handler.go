@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"fmt"
+	"time"
+
+	"go-template/internal/dto"
+	"go-template/internal/logger"
+	"go-template/internal/service"
+	"go-template/pkg/response"
+	"go-template/pkg/validator"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+type FileBulkHandler struct {
+	fileBulkService service.FileBulkService
+	validator       *validator.Validator
+}
+
+func NewFileBulkHandler(fileBulkService service.FileBulkService, validator *validator.Validator) *FileBulkHandler {
+	return &FileBulkHandler{
+		fileBulkService: fileBulkService,
+		validator:       validator,
+	}
+}
+
+// DeleteFiles godoc
+// @Summary Delete files in bulk
+// @Description Delete up to 100 files at once. Every file is deleted on its own, so the response reports the outcome per file with the error code the single delete would answer with. Requires moderator or admin role.
+// @Tags Files
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.BulkFileRequest true "File IDs"
+// @Success 200 {object} response.Response{data=dto.BulkFileResponse} "Bulk delete completed"
+// @Failure 400 {object} response.Response{error=[]dto.ValidationError} "Validation error"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Insufficient permissions"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /files/bulk/delete [post]
+func (h *FileBulkHandler) DeleteFiles(c echo.Context) error {
+	requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+	logger.Info("BulkDeleteFiles request started", zap.String("request_id", requestID))
+
+	var req dto.BulkFileRequest
+	if err := c.Bind(&req); err != nil {
+		logger.Error("Failed to bind request", zap.Error(err), zap.String("request_id", requestID))
+		return invalidBody(err)
+	}
+
+	if validationErrors := h.validator.ValidateStruct(c.Request().Context(), req); validationErrors != nil {
+		logger.Warn("Validation failed", zap.Any("errors", validationErrors), zap.String("request_id", requestID))
+		return response.ValidationError(c, "Validation failed", validationErrors)
+	}
+
+	result, err := h.fileBulkService.DeleteFiles(c.Request().Context(), req)
+	if err != nil {
+		logger.Error("Failed to delete files", zap.Error(err), zap.String("request_id", requestID))
+		return err
+	}
+
+	logger.Info("BulkDeleteFiles request completed",
+		zap.String("request_id", requestID),
+		zap.Int("succeeded", result.Succeeded),
+		zap.Int("failed", result.Failed))
+	return response.Success(c, "Bulk delete completed", result)
+}
+
+// UpdateFilesCategory godoc
+// @Summary Update the category of files in bulk
+// @Description Set the category of up to 100 files at once. Each file needs the same access as a single update: the owner, a write grant, or moderator or admin role. The response reports the outcome per file.
+// @Tags Files
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.BulkUpdateCategoryRequest true "File IDs and category"
+// @Success 200 {object} response.Response{data=dto.BulkFileResponse} "Bulk update completed"
+// @Failure 400 {object} response.Response{error=[]dto.ValidationError} "Validation error"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /files/bulk/category [post]
+func (h *FileBulkHandler) UpdateFilesCategory(c echo.Context) error {
+	requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+	userID := c.Get("user_id").(int) // Set by auth middleware
+	logger.Info("BulkUpdateFilesCategory request started", zap.String("request_id", requestID), zap.Int("user_id", userID))
+
+	var req dto.BulkUpdateCategoryRequest
+	if err := c.Bind(&req); err != nil {
+		logger.Error("Failed to bind request", zap.Error(err), zap.String("request_id", requestID))
+		return invalidBody(err)
+	}
+
+	if validationErrors := h.validator.ValidateStruct(c.Request().Context(), req); validationErrors != nil {
+		logger.Warn("Validation failed", zap.Any("errors", validationErrors), zap.String("request_id", requestID))
+		return response.ValidationError(c, "Validation failed", validationErrors)
+	}
+
+	result, err := h.fileBulkService.UpdateFilesCategory(c.Request().Context(), userID, req)
+	if err != nil {
+		logger.Error("Failed to update file categories", zap.Error(err), zap.String("request_id", requestID))
+		return err
+	}
+
+	logger.Info("BulkUpdateFilesCategory request completed",
+		zap.String("request_id", requestID),
+		zap.Int("succeeded", result.Succeeded),
+		zap.Int("failed", result.Failed))
+	return response.Success(c, "Bulk update completed", result)
+}
+
+// DownloadFileArchive godoc
+// @Summary Download files as a ZIP archive
+// @Description Stream a ZIP archive of up to 100 files, read directly from storage. The user needs read access to every file: the owner, a grant, or moderator or admin role. If any file is missing, not accessible or not scanned clean, the archive is refused and the error details name the file.
+// @Tags Files
+// @Accept json
+// @Produce application/zip
+// @Security BearerAuth
+// @Param request body dto.BulkFileRequest true "File IDs"
+// @Success 200 {file} binary "ZIP archive"
+// @Failure 400 {object} response.Response{error=[]dto.ValidationError} "Validation error"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "File belongs to another user or is quarantined"
+// @Failure 404 {object} response.Response "File not found"
+// @Failure 409 {object} response.Response "File is still being scanned"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /files/archive [post]
+func (h *FileBulkHandler) DownloadFileArchive(c echo.Context) error {
+	requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+	userID := c.Get("user_id").(int) // Set by auth middleware
+	logger.Info("DownloadFileArchive request started", zap.String("request_id", requestID), zap.Int("user_id", userID))
+
+	var req dto.BulkFileRequest
+	if err := c.Bind(&req); err != nil {
+		logger.Error("Failed to bind request", zap.Error(err), zap.String("request_id", requestID))
+		return invalidBody(err)
+	}
+
+	if validationErrors := h.validator.ValidateStruct(c.Request().Context(), req); validationErrors != nil {
+		logger.Warn("Validation failed", zap.Any("errors", validationErrors), zap.String("request_id", requestID))
+		return response.ValidationError(c, "Validation failed", validationErrors)
+	}
+
+	filename := fmt.Sprintf("files-%s.zip", time.Now().UTC().Format("20060102-150405"))
+	c.Response().Header().Set(echo.HeaderContentType, "application/zip")
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+
+	// The status line goes out with the first entry; once it is sent a failure mid-stream can only be logged
+	if err := h.fileBulkService.WriteFileArchive(c.Request().Context(), c.Response(), userID, req); err != nil {
+		logger.Error("Failed to write file archive", zap.Error(err), zap.String("request_id", requestID))
+		if c.Response().Committed {
+			return nil
+		}
+		c.Response().Header().Del(echo.HeaderContentType)
+		c.Response().Header().Del(echo.HeaderContentDisposition)
+		return err
+	}
+
+	logger.Info("DownloadFileArchive request completed", zap.String("request_id", requestID))
+	return nil
+}
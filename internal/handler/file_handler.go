@@ -1,12 +1,19 @@
 package handler
 
 import (
-	"path/filepath"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"mime"
+	"net/http"
 	"strconv"
+	"time"
 
 	"go-template/internal/dto"
 	"go-template/internal/logger"
 	"go-template/internal/service"
+	"go-template/pkg/pagination"
 	"go-template/pkg/response"
 	"go-template/pkg/validator"
 
@@ -16,12 +23,14 @@ import (
 
 type FileHandler struct {
 	fileService service.FileService
+	userService service.UserService
 	validator   *validator.Validator
 }
 
-func NewFileHandler(fileService service.FileService, validator *validator.Validator) *FileHandler {
+func NewFileHandler(fileService service.FileService, userService service.UserService, validator *validator.Validator) *FileHandler {
 	return &FileHandler{
 		fileService: fileService,
+		userService: userService,
 		validator:   validator,
 	}
 }
@@ -37,18 +46,18 @@ func (h *FileHandler) UploadFile(c echo.Context) error {
 	file, err := c.FormFile("file")
 	if err != nil {
 		logger.Error("Failed to get file from form", zap.Error(err), zap.String("request_id", requestID))
-		return response.BadRequest(c, "File is required", err.Error())
+		return missingFile("File is required", err)
 	}
 
 	// Bind additional form data
 	var req dto.UploadFileRequest
 	if err := c.Bind(&req); err != nil {
 		logger.Error("Failed to bind upload request", zap.Error(err), zap.String("request_id", requestID))
-		return response.BadRequest(c, "Invalid request data", err.Error())
+		return invalidBody(err)
 	}
 
 	// Validate request
-	if validationErrors := h.validator.ValidateStruct(req); validationErrors != nil {
+	if validationErrors := h.validator.ValidateStruct(c.Request().Context(), req); validationErrors != nil {
 		logger.Warn("Upload validation failed", zap.Any("errors", validationErrors), zap.String("request_id", requestID))
 		return response.ValidationError(c, "Validation failed", validationErrors)
 	}
@@ -56,7 +65,7 @@ func (h *FileHandler) UploadFile(c echo.Context) error {
 	fileResponse, err := h.fileService.UploadFile(c.Request().Context(), file, req, userID)
 	if err != nil {
 		logger.Error("Failed to upload file", zap.Error(err), zap.String("request_id", requestID))
-		return response.BadRequest(c, "Failed to upload file", err.Error())
+		return err
 	}
 
 	logger.Info("UploadFile request completed", zap.String("request_id", requestID))
@@ -71,17 +80,23 @@ func (h *FileHandler) GetFile(c echo.Context) error {
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
 		logger.Error("Invalid file ID", zap.Error(err), zap.String("request_id", requestID))
-		return response.BadRequest(c, "Invalid file ID", err.Error())
+		return invalidID("Invalid file ID", err)
 	}
 
 	file, err := h.fileService.GetFileByID(c.Request().Context(), id)
 	if err != nil {
 		logger.Error("Failed to get file", zap.Error(err), zap.String("request_id", requestID))
-		return response.NotFound(c, "File not found")
+		return err
+	}
+
+	data, err := response.Shape(c.Request().Context(), file, response.GetShapeParams(c), h.fileExpanders())
+	if err != nil {
+		logger.Error("Failed to shape file response", zap.Error(err), zap.String("request_id", requestID))
+		return err
 	}
 
 	logger.Info("GetFile request completed", zap.String("request_id", requestID))
-	return response.Success(c, "File retrieved successfully", file)
+	return response.Success(c, "File retrieved successfully", data)
 }
 
 func (h *FileHandler) GetMyFiles(c echo.Context) error {
@@ -89,57 +104,105 @@ func (h *FileHandler) GetMyFiles(c echo.Context) error {
 	userID := c.Get("user_id").(int) // Set by auth middleware
 	logger.Info("GetMyFiles request started", zap.String("request_id", requestID), zap.Int("user_id", userID))
 
-	files, err := h.fileService.GetFilesByUserID(c.Request().Context(), userID)
+	// Parse pagination and filter parameters
+	paginationParams := pagination.GetPaginationParams(c)
+	filterParams := pagination.GetFileFilterParams(c)
+
+	files, paginationMeta, err := h.fileService.GetFilesByUserIDWithPagination(c.Request().Context(), userID, paginationParams, filterParams)
 	if err != nil {
 		logger.Error("Failed to get user files", zap.Error(err), zap.String("request_id", requestID))
-		return response.InternalServerError(c, "Failed to get files", err.Error())
+		return err
+	}
+
+	data, err := response.Shape(c.Request().Context(), files, response.GetShapeParams(c), h.fileExpanders())
+	if err != nil {
+		logger.Error("Failed to shape file response", zap.Error(err), zap.String("request_id", requestID))
+		return err
+	}
+
+	logger.Info("GetMyFiles request completed",
+		zap.String("request_id", requestID),
+		zap.Int("total_files", paginationMeta.TotalRecords),
+		zap.Int("page", paginationMeta.CurrentPage))
+	return response.SuccessWithPagination(c, "Files retrieved successfully", data, paginationMeta)
+}
+
+func (h *FileHandler) GetSharedFiles(c echo.Context) error {
+	requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+	userID := c.Get("user_id").(int) // Set by auth middleware
+	logger.Info("GetSharedFiles request started", zap.String("request_id", requestID), zap.Int("user_id", userID))
+
+	files, err := h.fileService.GetSharedFiles(c.Request().Context(), userID)
+	if err != nil {
+		logger.Error("Failed to get shared files", zap.Error(err), zap.String("request_id", requestID))
+		return err
+	}
+
+	data, err := response.Shape(c.Request().Context(), files, response.GetShapeParams(c), h.fileExpanders())
+	if err != nil {
+		logger.Error("Failed to shape file response", zap.Error(err), zap.String("request_id", requestID))
+		return err
 	}
 
-	logger.Info("GetMyFiles request completed", zap.String("request_id", requestID))
-	return response.Success(c, "Files retrieved successfully", files)
+	logger.Info("GetSharedFiles request completed", zap.String("request_id", requestID), zap.Int("total_files", len(files)))
+	return response.Success(c, "Files retrieved successfully", data)
 }
 
 func (h *FileHandler) GetAllFiles(c echo.Context) error {
 	requestID := c.Response().Header().Get(echo.HeaderXRequestID)
 	logger.Info("GetAllFiles request started", zap.String("request_id", requestID))
 
-	files, err := h.fileService.GetAllFiles(c.Request().Context())
+	// Parse pagination and filter parameters
+	paginationParams := pagination.GetPaginationParams(c)
+	filterParams := pagination.GetFileFilterParams(c)
+
+	files, paginationMeta, err := h.fileService.GetAllFilesWithPagination(c.Request().Context(), paginationParams, filterParams)
 	if err != nil {
 		logger.Error("Failed to get all files", zap.Error(err), zap.String("request_id", requestID))
-		return response.InternalServerError(c, "Failed to get files", err.Error())
+		return err
+	}
+
+	data, err := response.Shape(c.Request().Context(), files, response.GetShapeParams(c), h.fileExpanders())
+	if err != nil {
+		logger.Error("Failed to shape file response", zap.Error(err), zap.String("request_id", requestID))
+		return err
 	}
 
-	logger.Info("GetAllFiles request completed", zap.String("request_id", requestID))
-	return response.Success(c, "Files retrieved successfully", files)
+	logger.Info("GetAllFiles request completed",
+		zap.String("request_id", requestID),
+		zap.Int("total_files", paginationMeta.TotalRecords),
+		zap.Int("page", paginationMeta.CurrentPage))
+	return response.SuccessWithPagination(c, "Files retrieved successfully", data, paginationMeta)
 }
 
 func (h *FileHandler) UpdateFile(c echo.Context) error {
 	requestID := c.Response().Header().Get(echo.HeaderXRequestID)
-	logger.Info("UpdateFile request started", zap.String("request_id", requestID))
+	userID := c.Get("user_id").(int) // Set by auth middleware
+	logger.Info("UpdateFile request started", zap.String("request_id", requestID), zap.Int("user_id", userID))
 
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
 		logger.Error("Invalid file ID", zap.Error(err), zap.String("request_id", requestID))
-		return response.BadRequest(c, "Invalid file ID", err.Error())
+		return invalidID("Invalid file ID", err)
 	}
 
 	var req dto.UpdateFileRequest
 	if err := c.Bind(&req); err != nil {
 		logger.Error("Failed to bind update request", zap.Error(err), zap.String("request_id", requestID))
-		return response.BadRequest(c, "Invalid request body", err.Error())
+		return invalidBody(err)
 	}
 
 	// Validate request
-	if validationErrors := h.validator.ValidateStruct(req); validationErrors != nil {
+	if validationErrors := h.validator.ValidateStruct(c.Request().Context(), req); validationErrors != nil {
 		logger.Warn("Update validation failed", zap.Any("errors", validationErrors), zap.String("request_id", requestID))
 		return response.ValidationError(c, "Validation failed", validationErrors)
 	}
 
-	file, err := h.fileService.UpdateFile(c.Request().Context(), id, req)
+	file, err := h.fileService.UpdateFile(c.Request().Context(), id, userID, req)
 	if err != nil {
 		logger.Error("Failed to update file", zap.Error(err), zap.String("request_id", requestID))
-		return response.BadRequest(c, "Failed to update file", err.Error())
+		return err
 	}
 
 	logger.Info("UpdateFile request completed", zap.String("request_id", requestID))
@@ -154,13 +217,13 @@ func (h *FileHandler) DeleteFile(c echo.Context) error {
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
 		logger.Error("Invalid file ID", zap.Error(err), zap.String("request_id", requestID))
-		return response.BadRequest(c, "Invalid file ID", err.Error())
+		return invalidID("Invalid file ID", err)
 	}
 
 	err = h.fileService.DeleteFile(c.Request().Context(), id)
 	if err != nil {
 		logger.Error("Failed to delete file", zap.Error(err), zap.String("request_id", requestID))
-		return response.BadRequest(c, "Failed to delete file", err.Error())
+		return err
 	}
 
 	logger.Info("DeleteFile request completed", zap.String("request_id", requestID))
@@ -175,43 +238,284 @@ func (h *FileHandler) DownloadFile(c echo.Context) error {
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
 		logger.Error("Invalid file ID", zap.Error(err), zap.String("request_id", requestID))
-		return response.BadRequest(c, "Invalid file ID", err.Error())
+		return invalidID("Invalid file ID", err)
 	}
 
-	// Get file entity directly for download
-	file, err := h.fileService.GetFileEntity(c.Request().Context(), id)
+	// Stream the content from whichever backend stores it
+	file, content, err := h.fileService.OpenFile(c.Request().Context(), id)
 	if err != nil {
-		logger.Error("Failed to get file entity", zap.Error(err), zap.String("request_id", requestID))
-		return response.NotFound(c, "File not found")
+		logger.Error("Failed to open file", zap.Error(err), zap.String("request_id", requestID))
+		return err
 	}
+	defer content.Close()
 
 	// Set headers for file download
-	c.Response().Header().Set("Content-Disposition", "attachment; filename=\""+file.OriginalName+"\"")
-	c.Response().Header().Set("Content-Type", file.MimeType)
+	c.Response().Header().Set(echo.HeaderContentDisposition, attachmentDisposition(file.OriginalName))
+	c.Response().Header().Set(echo.HeaderContentType, file.MimeType)
+	setChecksumHeaders(c, file.Checksum)
 
+	// The upload time of the current version is the modification time for conditional requests
 	logger.Info("DownloadFile request completed", zap.String("request_id", requestID))
-	return c.File(file.FilePath)
+	return serveContent(c, file.ContentUpdatedAt, content)
 }
 
+// UploadFileVersion replaces the content of a file with an uploaded file, keeping the previous content
+// as a version
+func (h *FileHandler) UploadFileVersion(c echo.Context) error {
+	requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+	userID := c.Get("user_id").(int) // Set by auth middleware
+	logger.Info("UploadFileVersion request started", zap.String("request_id", requestID), zap.Int("user_id", userID))
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		logger.Error("Invalid file ID", zap.Error(err), zap.String("request_id", requestID))
+		return invalidID("Invalid file ID", err)
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		logger.Error("Failed to get file from form", zap.Error(err), zap.String("request_id", requestID))
+		return missingFile("File is required", err)
+	}
+
+	fileResponse, err := h.fileService.UploadFileVersion(c.Request().Context(), id, file, userID)
+	if err != nil {
+		logger.Error("Failed to upload file version", zap.Error(err), zap.String("request_id", requestID))
+		return err
+	}
+
+	logger.Info("UploadFileVersion request completed", zap.String("request_id", requestID), zap.Int("version", fileResponse.Version))
+	return response.Success(c, "File version uploaded successfully", fileResponse)
+}
+
+// GetFileVersions lists the versions of a file, the current one first
+func (h *FileHandler) GetFileVersions(c echo.Context) error {
+	requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+	logger.Info("GetFileVersions request started", zap.String("request_id", requestID))
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		logger.Error("Invalid file ID", zap.Error(err), zap.String("request_id", requestID))
+		return invalidID("Invalid file ID", err)
+	}
+
+	versions, err := h.fileService.GetFileVersions(c.Request().Context(), id)
+	if err != nil {
+		logger.Error("Failed to get file versions", zap.Error(err), zap.String("request_id", requestID))
+		return err
+	}
+
+	logger.Info("GetFileVersions request completed", zap.String("request_id", requestID), zap.Int("versions", len(versions)))
+	return response.Success(c, "File versions retrieved successfully", versions)
+}
+
+// DownloadFileVersion streams the content of a version of a file
+func (h *FileHandler) DownloadFileVersion(c echo.Context) error {
+	requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+	logger.Info("DownloadFileVersion request started", zap.String("request_id", requestID))
+
+	id, version, err := parseFileVersionParams(c)
+	if err != nil {
+		logger.Error("Invalid file version", zap.Error(err), zap.String("request_id", requestID))
+		return invalidID("Invalid file ID or version", err)
+	}
+
+	fileVersion, content, err := h.fileService.OpenFileVersion(c.Request().Context(), id, version)
+	if err != nil {
+		logger.Error("Failed to open file version", zap.Error(err), zap.String("request_id", requestID))
+		return err
+	}
+	defer content.Close()
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, attachmentDisposition(fileVersion.OriginalName))
+	c.Response().Header().Set(echo.HeaderContentType, fileVersion.MimeType)
+	setChecksumHeaders(c, fileVersion.Checksum)
+
+	logger.Info("DownloadFileVersion request completed", zap.String("request_id", requestID))
+	return serveContent(c, fileVersion.CreatedAt, content)
+}
+
+// RestoreFileVersion makes an earlier version of a file current again, as a new version
+func (h *FileHandler) RestoreFileVersion(c echo.Context) error {
+	requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+	logger.Info("RestoreFileVersion request started", zap.String("request_id", requestID))
+
+	id, version, err := parseFileVersionParams(c)
+	if err != nil {
+		logger.Error("Invalid file version", zap.Error(err), zap.String("request_id", requestID))
+		return invalidID("Invalid file ID or version", err)
+	}
+
+	fileResponse, err := h.fileService.RestoreFileVersion(c.Request().Context(), id, version)
+	if err != nil {
+		logger.Error("Failed to restore file version", zap.Error(err), zap.String("request_id", requestID))
+		return err
+	}
+
+	logger.Info("RestoreFileVersion request completed", zap.String("request_id", requestID), zap.Int("version", fileResponse.Version))
+	return response.Success(c, "File version restored successfully", fileResponse)
+}
+
+// MoveFile puts a file of the current user into another of their folders
+func (h *FileHandler) MoveFile(c echo.Context) error {
+	requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+	userID := c.Get("user_id").(int) // Set by auth middleware
+	logger.Info("MoveFile request started", zap.String("request_id", requestID), zap.Int("user_id", userID))
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		logger.Error("Invalid file ID", zap.Error(err), zap.String("request_id", requestID))
+		return invalidID("Invalid file ID", err)
+	}
+
+	var req dto.MoveFileRequest
+	if err := c.Bind(&req); err != nil {
+		logger.Error("Failed to bind move request", zap.Error(err), zap.String("request_id", requestID))
+		return invalidBody(err)
+	}
+
+	if validationErrors := h.validator.ValidateStruct(c.Request().Context(), req); validationErrors != nil {
+		logger.Warn("Move validation failed", zap.Any("errors", validationErrors), zap.String("request_id", requestID))
+		return response.ValidationError(c, "Validation failed", validationErrors)
+	}
+
+	fileResponse, err := h.fileService.MoveFile(c.Request().Context(), id, userID, req)
+	if err != nil {
+		logger.Error("Failed to move file", zap.Error(err), zap.String("request_id", requestID))
+		return err
+	}
+
+	logger.Info("MoveFile request completed", zap.String("request_id", requestID))
+	return response.Success(c, "File moved successfully", fileResponse)
+}
+
+// GetFileVariant streams a variant of an image file, such as its thumbnail
+func (h *FileHandler) GetFileVariant(c echo.Context) error {
+	requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+	logger.Info("GetFileVariant request started", zap.String("request_id", requestID))
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		logger.Error("Invalid file ID", zap.Error(err), zap.String("request_id", requestID))
+		return invalidID("Invalid file ID", err)
+	}
+
+	_, content, variant, err := h.fileService.OpenFileVariant(c.Request().Context(), id, c.Param("name"))
+	if err != nil {
+		logger.Error("Failed to open file variant", zap.Error(err), zap.String("request_id", requestID))
+		return err
+	}
+	defer content.Close()
+
+	c.Response().Header().Set(echo.HeaderContentType, variant.MimeType)
+	c.Response().Header().Set(echo.HeaderXContentTypeOptions, "nosniff")
+
+	logger.Info("GetFileVariant request completed", zap.String("request_id", requestID))
+	return serveContent(c, variant.CreatedAt, content)
+}
+
+// ServeFile streams a file or avatar variant from a file URL: signed and expiring, or permanent for
+// files in public categories
 func (h *FileHandler) ServeFile(c echo.Context) error {
 	requestID := c.Response().Header().Get(echo.HeaderXRequestID)
 	logger.Info("ServeFile request started", zap.String("request_id", requestID))
 
-	filename := c.Param("filename")
-	if filename == "" {
-		logger.Error("Missing filename", zap.String("request_id", requestID))
-		return response.BadRequest(c, "Filename is required", nil)
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		logger.Error("Invalid file ID", zap.Error(err), zap.String("request_id", requestID))
+		return invalidID("Invalid file ID", err)
+	}
+
+	expires := c.QueryParam("expires")
+	variant := c.QueryParam("variant")
+	file, content, info, err := h.fileService.OpenServedFile(c.Request().Context(), id, variant, expires, c.QueryParam("signature"), c.RealIP())
+	if err != nil {
+		logger.Warn("Failed to serve file", zap.Error(err), zap.String("request_id", requestID))
+		return err
 	}
+	defer content.Close()
 
-	// Construct file path
-	filePath := filepath.Join("uploads", filename)
+	contentType := info.ContentType
+	if contentType == "" {
+		contentType = echo.MIMEOctetStream
+	}
+	c.Response().Header().Set(echo.HeaderContentType, contentType)
+	c.Response().Header().Set(echo.HeaderXContentTypeOptions, "nosniff")
+	// Variants are derived content without a checksum of their own; they are validated by the
+	// backend's ETag, when it has one, and their modification time
+	modTime := file.ContentUpdatedAt
+	if variant == "" {
+		setChecksumHeaders(c, file.Checksum)
+	} else {
+		modTime = info.LastModified
+		if info.ETag != "" {
+			c.Response().Header().Set("ETag", info.ETag)
+		}
+	}
 
-	// Check if file exists
-	if _, err := filepath.Abs(filePath); err != nil {
-		logger.Error("Invalid file path", zap.Error(err), zap.String("request_id", requestID))
-		return response.NotFound(c, "File not found")
+	// Signed URLs may only be cached by the client, and no longer than they are valid
+	if expiresAt, err := strconv.ParseInt(expires, 10, 64); err == nil {
+		maxAge := max(expiresAt-time.Now().Unix(), 0)
+		c.Response().Header().Set(echo.HeaderCacheControl, "private, max-age="+strconv.FormatInt(maxAge, 10))
+	} else {
+		c.Response().Header().Set(echo.HeaderCacheControl, "public, max-age=86400")
 	}
 
 	logger.Info("ServeFile request completed", zap.String("request_id", requestID))
-	return c.File(filePath)
+	return serveContent(c, modTime, content)
+}
+
+// parseFileVersionParams reads the file ID and version number from the path
+func parseFileVersionParams(c echo.Context) (int, int, error) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return 0, 0, err
+	}
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil {
+		return 0, 0, err
+	}
+	return id, version, nil
+}
+
+// serveContent writes content honouring Range and If-Range (206, multipart/byteranges for several
+// ranges), and If-None-Match and If-Modified-Since (304) against the ETag header set by the caller
+// and modTime. Content-Type must be set beforehand; Content-Length is derived from the content.
+func serveContent(c echo.Context, modTime time.Time, content io.ReadSeeker) error {
+	http.ServeContent(c.Response(), c.Request(), "", modTime, content)
+	return nil
+}
+
+// attachmentDisposition is the Content-Disposition of a download saved under the original file name. The
+// name is quoted, or encoded as in RFC 2231 when it is not plain ASCII, so it cannot break the header.
+func attachmentDisposition(name string) string {
+	return mime.FormatMediaType("attachment", map[string]string{"filename": name})
+}
+
+// setChecksumHeaders exposes the SHA-256 of the content as a strong ETag and a Digest header. Files
+// uploaded before checksums were recorded get neither.
+func setChecksumHeaders(c echo.Context, checksum string) {
+	digest, err := hex.DecodeString(checksum)
+	if checksum == "" || err != nil {
+		return
+	}
+	c.Response().Header().Set("ETag", `"`+checksum+`"`)
+	c.Response().Header().Set("Digest", "sha-256="+base64.StdEncoding.EncodeToString(digest))
+}
+
+// fileExpanders resolves the related resources that ?expand= can embed in file responses
+func (h *FileHandler) fileExpanders() map[string]response.Expander {
+	return map[string]response.Expander{
+		"uploaded_by": func(ctx context.Context, ids []int) (map[int]interface{}, error) {
+			uploaders, err := h.userService.GetUserSummaries(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+			resources := make(map[int]interface{}, len(uploaders))
+			for id, uploader := range uploaders {
+				resources[id] = uploader
+			}
+			return resources, nil
+		},
+	}
 }
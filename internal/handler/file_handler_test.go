@@ -0,0 +1,226 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"go-template/internal/entity"
+	"go-template/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+const downloadContent = "0123456789abcdefghij"
+
+// fakeDownloadFileService serves file 1 with downloadContent, uploaded at modTime
+type fakeDownloadFileService struct {
+	service.FileService
+	modTime  time.Time
+	checksum string
+}
+
+func (s *fakeDownloadFileService) OpenFile(ctx context.Context, id int) (*entity.File, io.ReadSeekCloser, error) {
+	if id != 1 {
+		return nil, nil, service.ErrFileNotFound
+	}
+	file := &entity.File{ID: 1, OriginalName: "digits.txt", MimeType: "text/plain", Checksum: s.checksum, ContentUpdatedAt: s.modTime}
+	return file, nopReadSeekCloser{bytes.NewReader([]byte(downloadContent))}, nil
+}
+
+func download(h *FileHandler, method string, headers map[string]string) (*httptest.ResponseRecorder, error) {
+	req := httptest.NewRequest(method, "/files/1/download", nil)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+	return rec, h.DownloadFile(c)
+}
+
+func TestAttachmentDisposition(t *testing.T) {
+	names := []string{
+		"report.pdf",
+		"annual report.pdf",
+		`say "hi".txt`,
+		`back\slash.txt`,
+		`evil"; filename="other.exe`,
+		"line\r\nbreak.txt",
+		"résumé.pdf",
+		"日本語.txt",
+	}
+	for _, name := range names {
+		header := attachmentDisposition(name)
+		disposition, params, err := mime.ParseMediaType(header)
+		if err != nil {
+			t.Errorf("attachmentDisposition(%q) = %q, which does not parse: %v", name, header, err)
+			continue
+		}
+		if disposition != "attachment" || params["filename"] != name {
+			t.Errorf("attachmentDisposition(%q) = %q, parsed as %s with filename %q", name, header, disposition, params["filename"])
+		}
+	}
+}
+
+func TestDownloadFileRangesAndConditions(t *testing.T) {
+	modTime := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	sum := sha256.Sum256([]byte(downloadContent))
+	checksum := hex.EncodeToString(sum[:])
+	etag := `"` + checksum + `"`
+	h := NewFileHandler(&fakeDownloadFileService{modTime: modTime, checksum: checksum}, nil, nil)
+
+	tests := []struct {
+		name             string
+		method           string
+		headers          map[string]string
+		wantStatus       int
+		wantBody         string
+		wantContentRange string
+	}{
+		{"whole file", http.MethodGet, nil, http.StatusOK, downloadContent, ""},
+		{"head", http.MethodHead, nil, http.StatusOK, "", ""},
+		{"single range", http.MethodGet, map[string]string{"Range": "bytes=2-5"}, http.StatusPartialContent, "2345", "bytes 2-5/20"},
+		{"suffix range", http.MethodGet, map[string]string{"Range": "bytes=-3"}, http.StatusPartialContent, "hij", "bytes 17-19/20"},
+		{"range past the end", http.MethodGet, map[string]string{"Range": "bytes=15-99"}, http.StatusPartialContent, "fghij", "bytes 15-19/20"},
+		{"If-Range with the ETag", http.MethodGet, map[string]string{"Range": "bytes=2-5", "If-Range": etag}, http.StatusPartialContent, "2345", "bytes 2-5/20"},
+		{"If-Range with a stale ETag", http.MethodGet, map[string]string{"Range": "bytes=2-5", "If-Range": `"stale"`}, http.StatusOK, downloadContent, ""},
+		{"If-Range with the upload time", http.MethodGet, map[string]string{"Range": "bytes=2-5", "If-Range": modTime.Format(http.TimeFormat)}, http.StatusPartialContent, "2345", "bytes 2-5/20"},
+		{"If-Range with an earlier time", http.MethodGet, map[string]string{"Range": "bytes=2-5", "If-Range": modTime.Add(-time.Hour).Format(http.TimeFormat)}, http.StatusOK, downloadContent, ""},
+		{"If-None-Match with the ETag", http.MethodGet, map[string]string{"If-None-Match": etag}, http.StatusNotModified, "", ""},
+		{"If-None-Match with another ETag", http.MethodGet, map[string]string{"If-None-Match": `"stale"`}, http.StatusOK, downloadContent, ""},
+		{"If-Modified-Since the upload", http.MethodGet, map[string]string{"If-Modified-Since": modTime.Format(http.TimeFormat)}, http.StatusNotModified, "", ""},
+		{"If-Modified-Since before the upload", http.MethodGet, map[string]string{"If-Modified-Since": modTime.Add(-time.Hour).Format(http.TimeFormat)}, http.StatusOK, downloadContent, ""},
+		// If-None-Match takes precedence over If-Modified-Since
+		{"stale If-None-Match with If-Modified-Since", http.MethodGet, map[string]string{"If-None-Match": `"stale"`, "If-Modified-Since": modTime.Format(http.TimeFormat)}, http.StatusOK, downloadContent, ""},
+		{"unsatisfiable range", http.MethodGet, map[string]string{"Range": "bytes=20-30"}, http.StatusRequestedRangeNotSatisfiable, "", "bytes */20"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, err := download(h, tt.method, tt.headers)
+			if err != nil {
+				t.Fatalf("DownloadFile: %v", err)
+			}
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if rec.Code == http.StatusRequestedRangeNotSatisfiable {
+				if contentRange := rec.Header().Get("Content-Range"); contentRange != tt.wantContentRange {
+					t.Errorf("Content-Range = %q, want %q", contentRange, tt.wantContentRange)
+				}
+				return
+			}
+			if body := rec.Body.String(); body != tt.wantBody {
+				t.Errorf("body = %q, want %q", body, tt.wantBody)
+			}
+			if contentRange := rec.Header().Get("Content-Range"); contentRange != tt.wantContentRange {
+				t.Errorf("Content-Range = %q, want %q", contentRange, tt.wantContentRange)
+			}
+			// Validators are sent with every answer, including 304
+			if got := rec.Header().Get("ETag"); got != etag {
+				t.Errorf("ETag = %q, want %q", got, etag)
+			}
+			if tt.wantStatus == http.StatusNotModified {
+				return
+			}
+			if got := rec.Header().Get("Last-Modified"); got != modTime.Format(http.TimeFormat) {
+				t.Errorf("Last-Modified = %q, want the upload time", got)
+			}
+			if got := rec.Header().Get("Accept-Ranges"); got != "bytes" {
+				t.Errorf("Accept-Ranges = %q, want bytes", got)
+			}
+			wantLength := len(tt.wantBody)
+			if tt.method == http.MethodHead {
+				wantLength = len(downloadContent)
+			}
+			if got := rec.Header().Get("Content-Length"); got != strconv.Itoa(wantLength) {
+				t.Errorf("Content-Length = %q, want %d", got, wantLength)
+			}
+		})
+	}
+}
+
+func TestDownloadFileMultipleRanges(t *testing.T) {
+	h := NewFileHandler(&fakeDownloadFileService{modTime: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)}, nil, nil)
+
+	rec, err := download(h, http.MethodGet, map[string]string{"Range": "bytes=0-1,10-12"})
+	if err != nil {
+		t.Fatalf("DownloadFile: %v", err)
+	}
+	if rec.Code != http.StatusPartialContent {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusPartialContent)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(rec.Header().Get("Content-Type"))
+	if err != nil || mediaType != "multipart/byteranges" {
+		t.Fatalf("Content-Type = %q, want multipart/byteranges", rec.Header().Get("Content-Type"))
+	}
+
+	want := []struct{ contentRange, body string }{
+		{"bytes 0-1/20", "01"},
+		{"bytes 10-12/20", "abc"},
+	}
+	parts := multipart.NewReader(rec.Body, params["boundary"])
+	for i, w := range want {
+		part, err := parts.NextPart()
+		if err != nil {
+			t.Fatalf("part %d: %v", i, err)
+		}
+		if got := part.Header.Get("Content-Range"); got != w.contentRange {
+			t.Errorf("part %d Content-Range = %q, want %q", i, got, w.contentRange)
+		}
+		if got := part.Header.Get("Content-Type"); got != "text/plain" {
+			t.Errorf("part %d Content-Type = %q, want the type of the file", i, got)
+		}
+		body, err := io.ReadAll(part)
+		if err != nil {
+			t.Fatalf("read part %d: %v", i, err)
+		}
+		if string(body) != w.body {
+			t.Errorf("part %d = %q, want %q", i, body, w.body)
+		}
+	}
+	if _, err := parts.NextPart(); err != io.EOF {
+		t.Errorf("parts after the requested ranges: %v, want none", err)
+	}
+}
+
+func TestSetChecksumHeaders(t *testing.T) {
+	sum := sha256.Sum256([]byte(downloadContent))
+	checksum := hex.EncodeToString(sum[:])
+
+	tests := []struct {
+		name       string
+		checksum   string
+		wantETag   string
+		wantDigest string
+	}{
+		{"checksum", checksum, `"` + checksum + `"`, "sha-256=" + base64.StdEncoding.EncodeToString(sum[:])},
+		{"file uploaded before checksums", "", "", ""},
+		{"not hexadecimal", "not-a-checksum", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+			setChecksumHeaders(c, tt.checksum)
+			if got := rec.Header().Get("ETag"); got != tt.wantETag {
+				t.Errorf("ETag = %q, want %q", got, tt.wantETag)
+			}
+			if got := rec.Header().Get("Digest"); got != tt.wantDigest {
+				t.Errorf("Digest = %q, want %q", got, tt.wantDigest)
+			}
+		})
+	}
+}
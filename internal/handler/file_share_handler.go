@@ -0,0 +1,369 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-template/internal/dto"
+	"go-template/internal/logger"
+	"go-template/internal/service"
+	"go-template/pkg/response"
+	"go-template/pkg/validator"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// shareLinkPasswordHeader carries the password of a protected share link
+const shareLinkPasswordHeader = "X-Share-Password"
+
+type FileShareHandler struct {
+	fileShareService service.FileShareService
+	validator        *validator.Validator
+}
+
+func NewFileShareHandler(fileShareService service.FileShareService, validator *validator.Validator) *FileShareHandler {
+	return &FileShareHandler{
+		fileShareService: fileShareService,
+		validator:        validator,
+	}
+}
+
+// CreateShareLink godoc
+// @Summary Create a share link
+// @Description Create a link that downloads the file without an account. The link can be protected by a password and limited by an expiry time and a number of downloads. Only the owner of the file can share it.
+// @Tags File Sharing
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "File ID"
+// @Param request body dto.CreateShareLinkRequest true "Share link"
+// @Success 201 {object} response.Response{data=dto.ShareLinkResponse} "Share link created successfully"
+// @Failure 400 {object} response.Response{error=[]dto.ValidationError} "Validation error"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "File belongs to another user"
+// @Failure 404 {object} response.Response "File not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /files/{id}/share-links [post]
+func (h *FileShareHandler) CreateShareLink(c echo.Context) error {
+	requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+	userID := c.Get("user_id").(int) // Set by auth middleware
+	logger.Info("CreateShareLink request started", zap.String("request_id", requestID), zap.Int("user_id", userID))
+
+	fileID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		logger.Error("Invalid file ID", zap.Error(err), zap.String("request_id", requestID))
+		return invalidID("Invalid file ID", err)
+	}
+
+	var req dto.CreateShareLinkRequest
+	if err := c.Bind(&req); err != nil {
+		logger.Error("Failed to bind request", zap.Error(err), zap.String("request_id", requestID))
+		return invalidBody(err)
+	}
+
+	if validationErrors := h.validator.ValidateStruct(c.Request().Context(), req); validationErrors != nil {
+		logger.Warn("Validation failed", zap.Any("errors", validationErrors), zap.String("request_id", requestID))
+		return response.ValidationError(c, "Validation failed", validationErrors)
+	}
+
+	link, err := h.fileShareService.CreateShareLink(c.Request().Context(), fileID, userID, req)
+	if err != nil {
+		logger.Error("Failed to create share link", zap.Error(err), zap.String("request_id", requestID))
+		return err
+	}
+
+	logger.Info("CreateShareLink request completed", zap.String("request_id", requestID), zap.Int("link_id", link.ID))
+	return response.Created(c, "Share link created successfully", link)
+}
+
+// GetShareLinks godoc
+// @Summary List share links
+// @Description List the share links of a file, including revoked and expired ones
+// @Tags File Sharing
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "File ID"
+// @Success 200 {object} response.Response{data=[]dto.ShareLinkResponse} "Share links retrieved successfully"
+// @Failure 400 {object} response.Response "Invalid file ID"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "File belongs to another user"
+// @Failure 404 {object} response.Response "File not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /files/{id}/share-links [get]
+func (h *FileShareHandler) GetShareLinks(c echo.Context) error {
+	requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+	userID := c.Get("user_id").(int) // Set by auth middleware
+	logger.Info("GetShareLinks request started", zap.String("request_id", requestID), zap.Int("user_id", userID))
+
+	fileID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		logger.Error("Invalid file ID", zap.Error(err), zap.String("request_id", requestID))
+		return invalidID("Invalid file ID", err)
+	}
+
+	links, err := h.fileShareService.GetShareLinks(c.Request().Context(), fileID, userID)
+	if err != nil {
+		logger.Error("Failed to get share links", zap.Error(err), zap.String("request_id", requestID))
+		return err
+	}
+
+	logger.Info("GetShareLinks request completed", zap.String("request_id", requestID), zap.Int("total_links", len(links)))
+	return response.Success(c, "Share links retrieved successfully", links)
+}
+
+// RevokeShareLink godoc
+// @Summary Revoke a share link
+// @Description Revoke a share link of a file; the link stops working immediately
+// @Tags File Sharing
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "File ID"
+// @Param link_id path int true "Share link ID"
+// @Success 200 {object} response.Response "Share link revoked successfully"
+// @Failure 400 {object} response.Response "Invalid share link ID"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "File belongs to another user"
+// @Failure 404 {object} response.Response "Share link not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /files/{id}/share-links/{link_id} [delete]
+func (h *FileShareHandler) RevokeShareLink(c echo.Context) error {
+	requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+	userID := c.Get("user_id").(int) // Set by auth middleware
+	logger.Info("RevokeShareLink request started", zap.String("request_id", requestID), zap.Int("user_id", userID))
+
+	fileID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		logger.Error("Invalid file ID", zap.Error(err), zap.String("request_id", requestID))
+		return invalidID("Invalid file ID", err)
+	}
+	linkID, err := strconv.Atoi(c.Param("link_id"))
+	if err != nil {
+		logger.Error("Invalid share link ID", zap.Error(err), zap.String("request_id", requestID))
+		return invalidID("Invalid share link ID", err)
+	}
+
+	if err := h.fileShareService.RevokeShareLink(c.Request().Context(), fileID, linkID, userID); err != nil {
+		logger.Error("Failed to revoke share link", zap.Error(err), zap.String("request_id", requestID))
+		return err
+	}
+
+	logger.Info("RevokeShareLink request completed", zap.String("request_id", requestID))
+	return response.Success(c, "Share link revoked successfully", nil)
+}
+
+// DownloadSharedFile godoc
+// @Summary Download a shared file
+// @Description Download the file behind a share link, without authentication. Protected links need the password in the X-Share-Password header, or in the password form field of a POST. Every request answered with content counts as a download, range requests included; HEAD and conditional requests answered without content do not.
+// @Tags File Sharing
+// @Accept x-www-form-urlencoded
+// @Produce octet-stream
+// @Param token path string true "Share link token"
+// @Param X-Share-Password header string false "Password of a protected link"
+// @Param password formData string false "Password of a protected link"
+// @Success 200 {file} binary "File content"
+// @Success 206 {file} binary "Requested range of the file content"
+// @Failure 401 {object} response.Response "Share link password is missing or incorrect"
+// @Failure 404 {object} response.Response "Share link not found"
+// @Failure 410 {object} response.Response "Share link has expired or reached its download limit"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /share/{token} [get]
+// @Router /share/{token} [post]
+func (h *FileShareHandler) DownloadSharedFile(c echo.Context) error {
+	requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+	logger.Info("DownloadSharedFile request started", zap.String("request_id", requestID))
+
+	password := c.Request().Header.Get(shareLinkPasswordHeader)
+	if password == "" && c.Request().Method == http.MethodPost {
+		password = c.FormValue("password")
+	}
+
+	link, file, content, err := h.fileShareService.OpenSharedFile(c.Request().Context(), c.Param("token"), password)
+	if err != nil {
+		logger.Error("Failed to open shared file", zap.Error(err), zap.String("request_id", requestID))
+		return err
+	}
+	defer content.Close()
+
+	// Every request answered with content uses up the link, range requests included, so a limited link
+	// cannot be downloaded piecewise; revalidations of a cached copy do not
+	if sendsContent(c.Request(), file.Checksum, file.ContentUpdatedAt) {
+		if err := h.fileShareService.CountSharedDownload(c.Request().Context(), link.ID); err != nil {
+			logger.Error("Failed to count shared download", zap.Error(err), zap.String("request_id", requestID))
+			return err
+		}
+	}
+
+	// Set headers for file download; the response must not outlive the link in a shared cache
+	c.Response().Header().Set(echo.HeaderContentDisposition, attachmentDisposition(file.OriginalName))
+	c.Response().Header().Set(echo.HeaderContentType, file.MimeType)
+	c.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+	c.Response().Header().Set(echo.HeaderXContentTypeOptions, "nosniff")
+	setChecksumHeaders(c, file.Checksum)
+
+	logger.Info("DownloadSharedFile request completed", zap.String("request_id", requestID), zap.Int("file_id", file.ID))
+	return serveContent(c, file.ContentUpdatedAt, content)
+}
+
+// GetFileGrants godoc
+// @Summary List file grants
+// @Description List the users the file is shared with and their permission
+// @Tags File Sharing
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "File ID"
+// @Success 200 {object} response.Response{data=[]dto.FileGrantResponse} "File grants retrieved successfully"
+// @Failure 400 {object} response.Response "Invalid file ID"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "File belongs to another user"
+// @Failure 404 {object} response.Response "File not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /files/{id}/grants [get]
+func (h *FileShareHandler) GetFileGrants(c echo.Context) error {
+	requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+	userID := c.Get("user_id").(int) // Set by auth middleware
+	logger.Info("GetFileGrants request started", zap.String("request_id", requestID), zap.Int("user_id", userID))
+
+	fileID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		logger.Error("Invalid file ID", zap.Error(err), zap.String("request_id", requestID))
+		return invalidID("Invalid file ID", err)
+	}
+
+	grants, err := h.fileShareService.GetFileGrants(c.Request().Context(), fileID, userID)
+	if err != nil {
+		logger.Error("Failed to get file grants", zap.Error(err), zap.String("request_id", requestID))
+		return err
+	}
+
+	logger.Info("GetFileGrants request completed", zap.String("request_id", requestID), zap.Int("total_grants", len(grants)))
+	return response.Success(c, "File grants retrieved successfully", grants)
+}
+
+// GrantFileAccess godoc
+// @Summary Share a file with a user
+// @Description Give another user read or write access to the file, replacing their earlier permission. Read allows viewing and downloading the file and its versions; write also allows changing its details and content.
+// @Tags File Sharing
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "File ID"
+// @Param user_id path int true "User ID"
+// @Param request body dto.GrantFileAccessRequest true "Permission"
+// @Success 200 {object} response.Response{data=dto.FileGrantResponse} "File access granted successfully"
+// @Failure 400 {object} response.Response{error=[]dto.ValidationError} "Validation error"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "File belongs to another user"
+// @Failure 404 {object} response.Response "File or user not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /files/{id}/grants/{user_id} [put]
+func (h *FileShareHandler) GrantFileAccess(c echo.Context) error {
+	requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+	userID := c.Get("user_id").(int) // Set by auth middleware
+	logger.Info("GrantFileAccess request started", zap.String("request_id", requestID), zap.Int("user_id", userID))
+
+	fileID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		logger.Error("Invalid file ID", zap.Error(err), zap.String("request_id", requestID))
+		return invalidID("Invalid file ID", err)
+	}
+	granteeID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil {
+		logger.Error("Invalid user ID", zap.Error(err), zap.String("request_id", requestID))
+		return invalidID("Invalid user ID", err)
+	}
+
+	var req dto.GrantFileAccessRequest
+	if err := c.Bind(&req); err != nil {
+		logger.Error("Failed to bind request", zap.Error(err), zap.String("request_id", requestID))
+		return invalidBody(err)
+	}
+
+	if validationErrors := h.validator.ValidateStruct(c.Request().Context(), req); validationErrors != nil {
+		logger.Warn("Validation failed", zap.Any("errors", validationErrors), zap.String("request_id", requestID))
+		return response.ValidationError(c, "Validation failed", validationErrors)
+	}
+
+	grant, err := h.fileShareService.GrantFileAccess(c.Request().Context(), fileID, userID, granteeID, req)
+	if err != nil {
+		logger.Error("Failed to grant file access", zap.Error(err), zap.String("request_id", requestID))
+		return err
+	}
+
+	logger.Info("GrantFileAccess request completed", zap.String("request_id", requestID))
+	return response.Success(c, "File access granted successfully", grant)
+}
+
+// RevokeFileAccess godoc
+// @Summary Stop sharing a file with a user
+// @Description Remove the access of another user to the file
+// @Tags File Sharing
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "File ID"
+// @Param user_id path int true "User ID"
+// @Success 200 {object} response.Response "File access revoked successfully"
+// @Failure 400 {object} response.Response "Invalid user ID"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "File belongs to another user"
+// @Failure 404 {object} response.Response "File is not shared with this user"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /files/{id}/grants/{user_id} [delete]
+func (h *FileShareHandler) RevokeFileAccess(c echo.Context) error {
+	requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+	userID := c.Get("user_id").(int) // Set by auth middleware
+	logger.Info("RevokeFileAccess request started", zap.String("request_id", requestID), zap.Int("user_id", userID))
+
+	fileID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		logger.Error("Invalid file ID", zap.Error(err), zap.String("request_id", requestID))
+		return invalidID("Invalid file ID", err)
+	}
+	granteeID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil {
+		logger.Error("Invalid user ID", zap.Error(err), zap.String("request_id", requestID))
+		return invalidID("Invalid user ID", err)
+	}
+
+	if err := h.fileShareService.RevokeFileAccess(c.Request().Context(), fileID, userID, granteeID); err != nil {
+		logger.Error("Failed to revoke file access", zap.Error(err), zap.String("request_id", requestID))
+		return err
+	}
+
+	logger.Info("RevokeFileAccess request completed", zap.String("request_id", requestID))
+	return response.Success(c, "File access revoked successfully", nil)
+}
+
+// sendsContent reports whether serveContent answers the request with content, whole or a range of it:
+// it is not a HEAD request, and not a conditional request the cached copy of the client satisfies
+func sendsContent(r *http.Request, checksum string, modTime time.Time) bool {
+	if r.Method == http.MethodHead {
+		return false
+	}
+
+	// Matching If-None-Match is answered with 304, or with 412 for POST
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		if checksum == "" {
+			return true
+		}
+		for _, etag := range strings.Split(ifNoneMatch, ",") {
+			etag = strings.TrimPrefix(strings.TrimSpace(etag), "W/")
+			if etag == "*" || etag == `"`+checksum+`"` {
+				return false
+			}
+		}
+		return true
+	}
+
+	// If-Modified-Since is only honoured for GET, and only without If-None-Match
+	if r.Method != http.MethodGet || modTime.IsZero() {
+		return true
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return true
+	}
+	return modTime.Truncate(time.Second).After(since)
+}
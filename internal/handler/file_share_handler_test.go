@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-template/internal/entity"
+	"go-template/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+const sharedContent = "shared file content"
+
+// fakeFileShareService serves one file through one link that allows maxDownloads downloads
+type fakeFileShareService struct {
+	service.FileShareService
+	maxDownloads int
+	downloads    int
+	modTime      time.Time
+}
+
+type nopReadSeekCloser struct {
+	io.ReadSeeker
+}
+
+func (nopReadSeekCloser) Close() error {
+	return nil
+}
+
+func (s *fakeFileShareService) OpenSharedFile(ctx context.Context, token, password string) (*entity.FileShareLink, *entity.File, io.ReadSeekCloser, error) {
+	if s.downloads >= s.maxDownloads {
+		return nil, nil, nil, service.ErrShareLinkExpired
+	}
+	file := &entity.File{ID: 1, OriginalName: "report.pdf", MimeType: "application/pdf", Checksum: "abc123", ContentUpdatedAt: s.modTime}
+	return &entity.FileShareLink{ID: 1, FileID: 1}, file, nopReadSeekCloser{bytes.NewReader([]byte(sharedContent))}, nil
+}
+
+func (s *fakeFileShareService) CountSharedDownload(ctx context.Context, linkID int) error {
+	if s.downloads >= s.maxDownloads {
+		return service.ErrShareLinkExpired
+	}
+	s.downloads++
+	return nil
+}
+
+func downloadShared(h *FileShareHandler, method string, headers map[string]string) (*httptest.ResponseRecorder, error) {
+	req := httptest.NewRequest(method, "/share/token", nil)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+	c.SetParamNames("token")
+	c.SetParamValues("token")
+	return rec, h.DownloadSharedFile(c)
+}
+
+func TestDownloadSharedFileCountsEveryRequestSendingContent(t *testing.T) {
+	modTime := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name       string
+		headers    map[string]string
+		wantStatus int
+	}{
+		{"whole file", nil, http.StatusOK},
+		{"open range", map[string]string{"Range": "bytes=0-"}, http.StatusPartialContent},
+		{"partial range", map[string]string{"Range": "bytes=0-5"}, http.StatusPartialContent},
+		// A failing If-Range makes the range request get the whole file
+		{"failing If-Range", map[string]string{"Range": "bytes=0-5", "If-Range": `"other"`}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shares := &fakeFileShareService{maxDownloads: 1, modTime: modTime}
+			h := NewFileShareHandler(shares, nil)
+
+			rec, err := downloadShared(h, http.MethodGet, tt.headers)
+			if err != nil {
+				t.Fatalf("first download: %v", err)
+			}
+			if rec.Code != tt.wantStatus {
+				t.Errorf("first download: status %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if shares.downloads != 1 {
+				t.Errorf("first download counted %d times, want 1", shares.downloads)
+			}
+
+			if _, err := downloadShared(h, http.MethodGet, tt.headers); !errors.Is(err, service.ErrShareLinkExpired) {
+				t.Errorf("second download of a link allowing one: error %v, want %v", err, service.ErrShareLinkExpired)
+			}
+		})
+	}
+}
+
+func TestDownloadSharedFileDoesNotCountRequestsWithoutContent(t *testing.T) {
+	modTime := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name       string
+		method     string
+		headers    map[string]string
+		wantStatus int
+	}{
+		{"HEAD", http.MethodHead, nil, http.StatusOK},
+		{"matching If-None-Match", http.MethodGet, map[string]string{"If-None-Match": `"abc123"`}, http.StatusNotModified},
+		{"If-Modified-Since", http.MethodGet, map[string]string{"If-Modified-Since": modTime.Format(http.TimeFormat)}, http.StatusNotModified},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shares := &fakeFileShareService{maxDownloads: 1, modTime: modTime}
+			h := NewFileShareHandler(shares, nil)
+
+			rec, err := downloadShared(h, tt.method, tt.headers)
+			if err != nil {
+				t.Fatalf("download: %v", err)
+			}
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if shares.downloads != 0 {
+				t.Errorf("counted %d downloads, want 0", shares.downloads)
+			}
+		})
+	}
+}
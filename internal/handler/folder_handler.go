@@ -0,0 +1,272 @@
+package handler
+
+import (
+	"strconv"
+
+	"go-template/internal/dto"
+	"go-template/internal/logger"
+	"go-template/internal/service"
+	"go-template/pkg/response"
+	"go-template/pkg/validator"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+type FolderHandler struct {
+	folderService service.FolderService
+	validator     *validator.Validator
+}
+
+func NewFolderHandler(folderService service.FolderService, validator *validator.Validator) *FolderHandler {
+	return &FolderHandler{
+		folderService: folderService,
+		validator:     validator,
+	}
+}
+
+// CreateFolder godoc
+// @Summary Create a folder
+// @Description Create a folder of the current user below parent_id, or at the top level when parent_id is omitted. Names are unique among siblings, ignoring case.
+// @Tags Folders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreateFolderRequest true "Folder"
+// @Success 201 {object} response.Response{data=dto.FolderResponse} "Folder created successfully"
+// @Failure 400 {object} response.Response{error=[]dto.ValidationError} "Validation error"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 404 {object} response.Response "Parent folder not found"
+// @Failure 409 {object} response.Response "Folder already exists"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /folders [post]
+func (h *FolderHandler) CreateFolder(c echo.Context) error {
+	requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+	userID := c.Get("user_id").(int) // Set by auth middleware
+	logger.Info("CreateFolder request started", zap.String("request_id", requestID), zap.Int("user_id", userID))
+
+	var req dto.CreateFolderRequest
+	if err := c.Bind(&req); err != nil {
+		logger.Error("Failed to bind request", zap.Error(err), zap.String("request_id", requestID))
+		return invalidBody(err)
+	}
+
+	if validationErrors := h.validator.ValidateStruct(c.Request().Context(), req); validationErrors != nil {
+		logger.Warn("Validation failed", zap.Any("errors", validationErrors), zap.String("request_id", requestID))
+		return response.ValidationError(c, "Validation failed", validationErrors)
+	}
+
+	folder, err := h.folderService.CreateFolder(c.Request().Context(), userID, req)
+	if err != nil {
+		logger.Error("Failed to create folder", zap.Error(err), zap.String("request_id", requestID))
+		return err
+	}
+
+	logger.Info("CreateFolder request completed", zap.String("request_id", requestID), zap.Int("folder_id", folder.ID))
+	return response.Created(c, "Folder created successfully", folder)
+}
+
+// GetFolders godoc
+// @Summary List folders
+// @Description List the subfolders of parent_id, or the top level folders of the current user when parent_id is omitted
+// @Tags Folders
+// @Produce json
+// @Security BearerAuth
+// @Param parent_id query int false "Parent folder ID"
+// @Success 200 {object} response.Response{data=[]dto.FolderResponse} "Folders retrieved successfully"
+// @Failure 400 {object} response.Response "Invalid folder ID"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 404 {object} response.Response "Parent folder not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /folders [get]
+func (h *FolderHandler) GetFolders(c echo.Context) error {
+	requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+	userID := c.Get("user_id").(int) // Set by auth middleware
+	logger.Info("GetFolders request started", zap.String("request_id", requestID), zap.Int("user_id", userID))
+
+	var parentID *int
+	if parentIDStr := c.QueryParam("parent_id"); parentIDStr != "" {
+		id, err := strconv.Atoi(parentIDStr)
+		if err != nil {
+			logger.Error("Invalid folder ID", zap.Error(err), zap.String("request_id", requestID))
+			return invalidID("Invalid folder ID", err)
+		}
+		parentID = &id
+	}
+
+	folders, err := h.folderService.GetFolders(c.Request().Context(), userID, parentID)
+	if err != nil {
+		logger.Error("Failed to get folders", zap.Error(err), zap.String("request_id", requestID))
+		return err
+	}
+
+	logger.Info("GetFolders request completed", zap.String("request_id", requestID), zap.Int("total_folders", len(folders)))
+	return response.Success(c, "Folders retrieved successfully", folders)
+}
+
+// GetFolder godoc
+// @Summary Get a folder
+// @Description Get a folder of the current user with the number of folders, files and bytes below it
+// @Tags Folders
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Folder ID"
+// @Success 200 {object} response.Response{data=dto.FolderResponse} "Folder retrieved successfully"
+// @Failure 400 {object} response.Response "Invalid folder ID"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 404 {object} response.Response "Folder not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /folders/{id} [get]
+func (h *FolderHandler) GetFolder(c echo.Context) error {
+	requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+	userID := c.Get("user_id").(int) // Set by auth middleware
+	logger.Info("GetFolder request started", zap.String("request_id", requestID), zap.Int("user_id", userID))
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		logger.Error("Invalid folder ID", zap.Error(err), zap.String("request_id", requestID))
+		return invalidID("Invalid folder ID", err)
+	}
+
+	folder, err := h.folderService.GetFolder(c.Request().Context(), id, userID)
+	if err != nil {
+		logger.Error("Failed to get folder", zap.Error(err), zap.String("request_id", requestID))
+		return err
+	}
+
+	logger.Info("GetFolder request completed", zap.String("request_id", requestID))
+	return response.Success(c, "Folder retrieved successfully", folder)
+}
+
+// RenameFolder godoc
+// @Summary Rename a folder
+// @Description Rename a folder of the current user
+// @Tags Folders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Folder ID"
+// @Param request body dto.RenameFolderRequest true "New name"
+// @Success 200 {object} response.Response{data=dto.FolderResponse} "Folder renamed successfully"
+// @Failure 400 {object} response.Response{error=[]dto.ValidationError} "Validation error"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 404 {object} response.Response "Folder not found"
+// @Failure 409 {object} response.Response "Folder already exists"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /folders/{id} [put]
+func (h *FolderHandler) RenameFolder(c echo.Context) error {
+	requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+	userID := c.Get("user_id").(int) // Set by auth middleware
+	logger.Info("RenameFolder request started", zap.String("request_id", requestID), zap.Int("user_id", userID))
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		logger.Error("Invalid folder ID", zap.Error(err), zap.String("request_id", requestID))
+		return invalidID("Invalid folder ID", err)
+	}
+
+	var req dto.RenameFolderRequest
+	if err := c.Bind(&req); err != nil {
+		logger.Error("Failed to bind request", zap.Error(err), zap.String("request_id", requestID))
+		return invalidBody(err)
+	}
+
+	if validationErrors := h.validator.ValidateStruct(c.Request().Context(), req); validationErrors != nil {
+		logger.Warn("Validation failed", zap.Any("errors", validationErrors), zap.String("request_id", requestID))
+		return response.ValidationError(c, "Validation failed", validationErrors)
+	}
+
+	folder, err := h.folderService.RenameFolder(c.Request().Context(), id, userID, req)
+	if err != nil {
+		logger.Error("Failed to rename folder", zap.Error(err), zap.String("request_id", requestID))
+		return err
+	}
+
+	logger.Info("RenameFolder request completed", zap.String("request_id", requestID))
+	return response.Success(c, "Folder renamed successfully", folder)
+}
+
+// MoveFolder godoc
+// @Summary Move a folder
+// @Description Move a folder of the current user, with its subfolders and files, below parent_id; null moves it to the top level. A folder cannot be moved into itself or its subfolders.
+// @Tags Folders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Folder ID"
+// @Param request body dto.MoveFolderRequest true "New parent"
+// @Success 200 {object} response.Response{data=dto.FolderResponse} "Folder moved successfully"
+// @Failure 400 {object} response.Response{error=[]dto.ValidationError} "Validation error"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 404 {object} response.Response "Folder not found"
+// @Failure 409 {object} response.Response "Folder already exists"
+// @Failure 422 {object} response.Response "Folder cannot be moved into its subfolders"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /folders/{id}/move [post]
+func (h *FolderHandler) MoveFolder(c echo.Context) error {
+	requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+	userID := c.Get("user_id").(int) // Set by auth middleware
+	logger.Info("MoveFolder request started", zap.String("request_id", requestID), zap.Int("user_id", userID))
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		logger.Error("Invalid folder ID", zap.Error(err), zap.String("request_id", requestID))
+		return invalidID("Invalid folder ID", err)
+	}
+
+	var req dto.MoveFolderRequest
+	if err := c.Bind(&req); err != nil {
+		logger.Error("Failed to bind request", zap.Error(err), zap.String("request_id", requestID))
+		return invalidBody(err)
+	}
+
+	if validationErrors := h.validator.ValidateStruct(c.Request().Context(), req); validationErrors != nil {
+		logger.Warn("Validation failed", zap.Any("errors", validationErrors), zap.String("request_id", requestID))
+		return response.ValidationError(c, "Validation failed", validationErrors)
+	}
+
+	folder, err := h.folderService.MoveFolder(c.Request().Context(), id, userID, req)
+	if err != nil {
+		logger.Error("Failed to move folder", zap.Error(err), zap.String("request_id", requestID))
+		return err
+	}
+
+	logger.Info("MoveFolder request completed", zap.String("request_id", requestID))
+	return response.Success(c, "Folder moved successfully", folder)
+}
+
+// DeleteFolder godoc
+// @Summary Delete a folder
+// @Description Delete an empty folder of the current user. With recursive=true its subfolders and the files in them are deleted as well, releasing their storage.
+// @Tags Folders
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Folder ID"
+// @Param recursive query bool false "Also delete subfolders and files"
+// @Success 200 {object} response.Response "Folder deleted successfully"
+// @Failure 400 {object} response.Response "Invalid folder ID"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 404 {object} response.Response "Folder not found"
+// @Failure 409 {object} response.Response "Folder is not empty"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /folders/{id} [delete]
+func (h *FolderHandler) DeleteFolder(c echo.Context) error {
+	requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+	userID := c.Get("user_id").(int) // Set by auth middleware
+	logger.Info("DeleteFolder request started", zap.String("request_id", requestID), zap.Int("user_id", userID))
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		logger.Error("Invalid folder ID", zap.Error(err), zap.String("request_id", requestID))
+		return invalidID("Invalid folder ID", err)
+	}
+	recursive, _ := strconv.ParseBool(c.QueryParam("recursive"))
+
+	if err := h.folderService.DeleteFolder(c.Request().Context(), id, userID, recursive); err != nil {
+		logger.Error("Failed to delete folder", zap.Error(err), zap.String("request_id", requestID))
+		return err
+	}
+
+	logger.Info("DeleteFolder request completed", zap.String("request_id", requestID))
+	return response.Success(c, "Folder deleted successfully", nil)
+}
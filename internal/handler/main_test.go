@@ -0,0 +1,15 @@
+package handler
+
+import (
+	"os"
+	"testing"
+
+	"go-template/internal/logger"
+
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	logger.Logger = zap.NewNop()
+	os.Exit(m.Run())
+}
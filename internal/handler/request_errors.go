@@ -0,0 +1,26 @@
+package handler
+
+import "go-template/pkg/apperror"
+
+// Errors of requests that cannot be parsed. The parser's own message is only logged: it can reveal
+// internals such as Echo's "code=400, message=Syntax error: offset=…".
+var (
+	ErrInvalidID   = apperror.New(apperror.KindInvalid, apperror.CodeInvalidID, "Invalid ID")
+	ErrInvalidBody = apperror.New(apperror.KindInvalid, apperror.CodeInvalidBody, "Invalid request body")
+	ErrMissingFile = apperror.New(apperror.KindInvalid, apperror.CodeMissingFile, "File is required")
+)
+
+// invalidID reports a path parameter that is not a valid ID, naming the kind of ID in the message
+func invalidID(message string, err error) error {
+	return ErrInvalidID.WithMessage(message).Wrap(err)
+}
+
+// invalidBody reports a request body that cannot be bound
+func invalidBody(err error) error {
+	return ErrInvalidBody.Wrap(err)
+}
+
+// missingFile reports a multipart upload without the expected file field
+func missingFile(message string, err error) error {
+	return ErrMissingFile.WithMessage(message).Wrap(err)
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-template/pkg/apperror"
+	"go-template/pkg/response"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestUnparsableRequestsDoNotLeakParserErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		id       string
+		body     string
+		wantErr  error
+		wantCode string
+	}{
+		{"non-numeric ID", "abc", `{}`, ErrInvalidID, apperror.CodeInvalidID},
+		{"malformed JSON", "1", `{"password": `, ErrInvalidBody, apperror.CodeInvalidBody},
+		{"wrong JSON type", "1", `{"max_downloads": "ten"}`, ErrInvalidBody, apperror.CodeInvalidBody},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/files/"+tt.id+"/share-links", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.id)
+			c.Set("user_id", 1)
+
+			err := NewFileShareHandler(&fakeFileShareService{}, nil).CreateShareLink(c)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CreateShareLink error %v, want %v", err, tt.wantErr)
+			}
+
+			if err := response.Error(c, err); err != nil {
+				t.Fatalf("rendering the error: %v", err)
+			}
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("status %d, want %d", rec.Code, http.StatusBadRequest)
+			}
+			body := rec.Body.String()
+			if !strings.Contains(body, `"code":"`+tt.wantCode+`"`) {
+				t.Errorf("response %s does not carry code %s", body, tt.wantCode)
+			}
+			for _, leak := range []string{"code=400", "Syntax error", "offset", "strconv", "unmarshal"} {
+				if strings.Contains(body, leak) {
+					t.Errorf("response %s leaks the parser error (%q)", body, leak)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"strconv"
+
+	"go-template/internal/dto"
+	"go-template/internal/logger"
+	"go-template/internal/service"
+	"go-template/pkg/response"
+	"go-template/pkg/validator"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+type StorageHandler struct {
+	quotaService service.StorageQuotaService
+	validator    *validator.Validator
+}
+
+func NewStorageHandler(quotaService service.StorageQuotaService, validator *validator.Validator) *StorageHandler {
+	return &StorageHandler{
+		quotaService: quotaService,
+		validator:    validator,
+	}
+}
+
+// GetMyStorage godoc
+// @Summary Get own storage usage
+// @Description Get the bytes and number of files stored by the current user and their quotas. Earlier versions of files count towards the bytes; a quota of 0 is unlimited.
+// @Tags User Management
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=dto.StorageUsageResponse} "Storage usage retrieved successfully"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /users/me/storage [get]
+func (h *StorageHandler) GetMyStorage(c echo.Context) error {
+	requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+	userID := c.Get("user_id").(int) // Set by auth middleware
+	logger.Info("GetMyStorage request started", zap.String("request_id", requestID), zap.Int("user_id", userID))
+
+	usage, err := h.quotaService.GetUsage(c.Request().Context(), userID)
+	if err != nil {
+		logger.Error("Failed to get storage usage", zap.Error(err), zap.String("request_id", requestID))
+		return err
+	}
+
+	logger.Info("GetMyStorage request completed", zap.String("request_id", requestID))
+	return response.Success(c, "Storage usage retrieved successfully", usage)
+}
+
+// GetUserStorage godoc
+// @Summary Get storage usage of a user
+// @Description Get the bytes and number of files stored by a user and their quotas. Users can view their own usage, admins can view any.
+// @Tags User Management
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} response.Response{data=dto.StorageUsageResponse} "Storage usage retrieved successfully"
+// @Failure 400 {object} response.Response "Invalid user ID"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "User not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /users/{id}/storage [get]
+func (h *StorageHandler) GetUserStorage(c echo.Context) error {
+	requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+	logger.Info("GetUserStorage request started", zap.String("request_id", requestID))
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		logger.Error("Invalid user ID", zap.Error(err), zap.String("request_id", requestID))
+		return invalidID("Invalid user ID", err)
+	}
+
+	usage, err := h.quotaService.GetUsage(c.Request().Context(), id)
+	if err != nil {
+		logger.Error("Failed to get storage usage", zap.Error(err), zap.String("request_id", requestID))
+		return err
+	}
+
+	logger.Info("GetUserStorage request completed", zap.String("request_id", requestID))
+	return response.Success(c, "Storage usage retrieved successfully", usage)
+}
+
+// UpdateStorageQuota godoc
+// @Summary Override storage quota of a user (Admin only)
+// @Description Set the quotas of a user instead of the quotas of their role. null restores the quota of the role and 0 lifts the limit. Requires admin role.
+// @Tags User Management
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param request body dto.UpdateStorageQuotaRequest true "Quota overrides"
+// @Success 200 {object} response.Response{data=dto.StorageUsageResponse} "Storage quota updated successfully"
+// @Failure 400 {object} response.Response{error=[]dto.ValidationError} "Validation error"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden - Admin access required"
+// @Failure 404 {object} response.Response "User not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /users/{id}/storage/quota [put]
+func (h *StorageHandler) UpdateStorageQuota(c echo.Context) error {
+	requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+	logger.Info("UpdateStorageQuota request started", zap.String("request_id", requestID))
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		logger.Error("Invalid user ID", zap.Error(err), zap.String("request_id", requestID))
+		return invalidID("Invalid user ID", err)
+	}
+
+	var req dto.UpdateStorageQuotaRequest
+	if err := c.Bind(&req); err != nil {
+		logger.Error("Failed to bind request", zap.Error(err), zap.String("request_id", requestID))
+		return invalidBody(err)
+	}
+
+	if validationErrors := h.validator.ValidateStruct(c.Request().Context(), req); validationErrors != nil {
+		logger.Warn("Validation failed", zap.Any("errors", validationErrors), zap.String("request_id", requestID))
+		return response.ValidationError(c, "Validation failed", validationErrors)
+	}
+
+	usage, err := h.quotaService.UpdateQuota(c.Request().Context(), id, req)
+	if err != nil {
+		logger.Error("Failed to update storage quota", zap.Error(err), zap.String("request_id", requestID))
+		return err
+	}
+
+	logger.Info("UpdateStorageQuota request completed", zap.String("request_id", requestID))
+	return response.Success(c, "Storage quota updated successfully", usage)
+}
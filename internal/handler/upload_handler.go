@@ -0,0 +1,259 @@
+package handler
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go-template/internal/dto"
+	"go-template/internal/entity"
+	"go-template/internal/logger"
+	"go-template/internal/service"
+	"go-template/pkg/apperror"
+	"go-template/pkg/response"
+	"go-template/pkg/validator"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// tusExtensions lists the optional parts of the tus protocol that are implemented
+const tusExtensions = "creation,termination"
+
+var ErrInvalidUploadMetadata = apperror.New(apperror.KindInvalid, apperror.CodeInvalidRequest, "Invalid Upload-Metadata header")
+
+// UploadHandler serves resumable uploads using the tus 1.0 protocol (https://tus.io/protocols/resumable-upload)
+type UploadHandler struct {
+	uploadService service.UploadService
+	validator     *validator.Validator
+}
+
+func NewUploadHandler(uploadService service.UploadService, validator *validator.Validator) *UploadHandler {
+	return &UploadHandler{
+		uploadService: uploadService,
+		validator:     validator,
+	}
+}
+
+// Options godoc
+// @Summary Discover resumable upload support
+// @Description Report the supported tus version, extensions and maximum upload size
+// @Tags Files
+// @Success 204 "Tus-Version, Tus-Extension and Tus-Max-Size headers"
+// @Router /files/uploads [options]
+func (h *UploadHandler) Options(c echo.Context) error {
+	c.Response().Header().Set("Tus-Extension", tusExtensions)
+	c.Response().Header().Set("Tus-Max-Size", strconv.FormatInt(h.uploadService.MaxUploadSize(), 10))
+	return c.NoContent(http.StatusNoContent)
+}
+
+// CreateUpload godoc
+// @Summary Create a resumable upload
+// @Description Declare a tus upload. Upload-Metadata must contain filename and filetype and may contain description, category and folder_id, all base64 encoded. The file is created once all bytes have been sent with PATCH.
+// @Tags Files
+// @Security BearerAuth
+// @Param Tus-Resumable header string true "Protocol version (1.0.0)"
+// @Param Upload-Length header int true "Size of the file in bytes"
+// @Param Upload-Metadata header string true "Comma-separated key and base64 value pairs"
+// @Success 201 "Location header with the upload URL"
+// @Failure 400 {object} response.Response "Invalid headers"
+// @Failure 412 {object} response.Response "Unsupported protocol version"
+// @Failure 413 {object} response.Response "Upload too large"
+// @Failure 415 {object} response.Response "File type not allowed"
+// @Failure 422 {object} response.Response{error=[]dto.ValidationError} "Validation error"
+// @Router /files/uploads [post]
+func (h *UploadHandler) CreateUpload(c echo.Context) error {
+	requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+	userID := c.Get("user_id").(int) // Set by auth middleware
+	logger.Info("CreateUpload request started", zap.String("request_id", requestID), zap.Int("user_id", userID))
+
+	if c.Request().Header.Get("Upload-Defer-Length") != "" {
+		logger.Warn("Deferred upload length requested", zap.String("request_id", requestID))
+		return response.BadRequest(c, "Upload-Defer-Length is not supported", nil)
+	}
+
+	length, err := strconv.ParseInt(c.Request().Header.Get("Upload-Length"), 10, 64)
+	if err != nil || length < 0 {
+		logger.Warn("Invalid Upload-Length header", zap.String("request_id", requestID))
+		return response.BadRequest(c, "Invalid Upload-Length header", nil)
+	}
+
+	rawMetadata := c.Request().Header.Get("Upload-Metadata")
+	metadata, err := parseUploadMetadata(rawMetadata)
+	if err != nil {
+		logger.Warn("Invalid Upload-Metadata header", zap.Error(err), zap.String("request_id", requestID))
+		return ErrInvalidUploadMetadata.Wrap(err)
+	}
+
+	req := dto.CreateUploadRequest{
+		Length:      length,
+		Metadata:    rawMetadata,
+		FileName:    metadata["filename"],
+		FileType:    metadata["filetype"],
+		Description: metadata["description"],
+		Category:    metadata["category"],
+	}
+	if value, ok := metadata["folder_id"]; ok {
+		folderID, err := strconv.Atoi(value)
+		if err != nil {
+			logger.Warn("Invalid folder_id in Upload-Metadata", zap.String("request_id", requestID))
+			return ErrInvalidUploadMetadata.Wrap(errors.New("metadata value of folder_id is not a folder ID"))
+		}
+		req.FolderID = &folderID
+	}
+
+	if validationErrors := h.validator.ValidateStruct(c.Request().Context(), req); validationErrors != nil {
+		logger.Warn("Upload validation failed", zap.Any("errors", validationErrors), zap.String("request_id", requestID))
+		return response.ValidationError(c, "Validation failed", validationErrors)
+	}
+
+	upload, err := h.uploadService.CreateUpload(c.Request().Context(), userID, req)
+	if err != nil {
+		logger.Error("Failed to create upload", zap.Error(err), zap.String("request_id", requestID))
+		return err
+	}
+
+	c.Response().Header().Set(echo.HeaderLocation, strings.TrimSuffix(c.Request().URL.Path, "/")+"/"+upload.ID)
+	setUploadHeaders(c, upload)
+
+	logger.Info("CreateUpload request completed", zap.String("request_id", requestID), zap.String("upload_id", upload.ID))
+	return c.NoContent(http.StatusCreated)
+}
+
+// GetUploadOffset godoc
+// @Summary Get the offset of a resumable upload
+// @Description Report how many bytes of the upload have been received, so an interrupted upload can be resumed
+// @Tags Files
+// @Security BearerAuth
+// @Param Tus-Resumable header string true "Protocol version (1.0.0)"
+// @Param id path string true "Upload ID"
+// @Success 200 "Upload-Offset and Upload-Length headers; X-File-ID once the file has been created"
+// @Failure 404 "Upload not found"
+// @Router /files/uploads/{id} [head]
+func (h *UploadHandler) GetUploadOffset(c echo.Context) error {
+	requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+	userID := c.Get("user_id").(int) // Set by auth middleware
+	logger.Debug("GetUploadOffset request started", zap.String("request_id", requestID), zap.Int("user_id", userID))
+
+	upload, err := h.uploadService.GetUpload(c.Request().Context(), userID, c.Param("id"))
+	if err != nil {
+		logger.Warn("Failed to get upload", zap.Error(err), zap.String("request_id", requestID))
+		return err
+	}
+
+	c.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+	c.Response().Header().Set("Upload-Length", strconv.FormatInt(upload.Length, 10))
+	if upload.Metadata != "" {
+		c.Response().Header().Set("Upload-Metadata", upload.Metadata)
+	}
+	setUploadHeaders(c, upload)
+
+	return c.NoContent(http.StatusOK)
+}
+
+// WriteChunk godoc
+// @Summary Send a chunk of a resumable upload
+// @Description Append the request body at Upload-Offset, which must equal the current offset. The file is created when the last byte arrives.
+// @Tags Files
+// @Security BearerAuth
+// @Accept application/offset+octet-stream
+// @Param Tus-Resumable header string true "Protocol version (1.0.0)"
+// @Param Upload-Offset header int true "Offset the chunk starts at"
+// @Param id path string true "Upload ID"
+// @Success 204 "Upload-Offset header with the new offset; X-File-ID once the file has been created"
+// @Failure 400 {object} response.Response "Invalid headers"
+// @Failure 404 {object} response.Response "Upload not found"
+// @Failure 409 {object} response.Response "Offset mismatch"
+// @Failure 413 {object} response.Response "Chunk exceeds the upload length"
+// @Failure 415 {object} response.Response "Wrong content type"
+// @Router /files/uploads/{id} [patch]
+func (h *UploadHandler) WriteChunk(c echo.Context) error {
+	requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+	userID := c.Get("user_id").(int) // Set by auth middleware
+	logger.Info("WriteChunk request started", zap.String("request_id", requestID), zap.Int("user_id", userID))
+
+	offset, err := strconv.ParseInt(c.Request().Header.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		logger.Warn("Invalid Upload-Offset header", zap.String("request_id", requestID))
+		return response.BadRequest(c, "Invalid Upload-Offset header", nil)
+	}
+
+	// The size is checked against the upload length before anything is stored
+	size := c.Request().ContentLength
+	if size < 0 {
+		logger.Warn("Missing Content-Length header", zap.String("request_id", requestID))
+		return response.BadRequest(c, "Content-Length header is required", nil)
+	}
+
+	upload, err := h.uploadService.WriteChunk(c.Request().Context(), userID, c.Param("id"), offset, c.Request().Body, size)
+	if err != nil {
+		logger.Error("Failed to write upload chunk", zap.Error(err), zap.String("request_id", requestID))
+		return err
+	}
+
+	setUploadHeaders(c, upload)
+
+	logger.Info("WriteChunk request completed", zap.String("request_id", requestID), zap.String("upload_id", upload.ID), zap.Int64("offset", upload.Offset))
+	return c.NoContent(http.StatusNoContent)
+}
+
+// TerminateUpload godoc
+// @Summary Terminate a resumable upload
+// @Description Discard an upload and the chunks received so far. A file already created from it is kept.
+// @Tags Files
+// @Security BearerAuth
+// @Param Tus-Resumable header string true "Protocol version (1.0.0)"
+// @Param id path string true "Upload ID"
+// @Success 204 "Upload terminated"
+// @Failure 404 {object} response.Response "Upload not found"
+// @Router /files/uploads/{id} [delete]
+func (h *UploadHandler) TerminateUpload(c echo.Context) error {
+	requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+	userID := c.Get("user_id").(int) // Set by auth middleware
+	logger.Info("TerminateUpload request started", zap.String("request_id", requestID), zap.Int("user_id", userID))
+
+	if err := h.uploadService.TerminateUpload(c.Request().Context(), userID, c.Param("id")); err != nil {
+		logger.Error("Failed to terminate upload", zap.Error(err), zap.String("request_id", requestID))
+		return err
+	}
+
+	logger.Info("TerminateUpload request completed", zap.String("request_id", requestID))
+	return c.NoContent(http.StatusNoContent)
+}
+
+// setUploadHeaders reports the upload offset and, once complete, the ID of the created file
+func setUploadHeaders(c echo.Context, upload *entity.FileUpload) {
+	c.Response().Header().Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	if upload.FileID != nil {
+		c.Response().Header().Set("X-File-ID", strconv.Itoa(*upload.FileID))
+	}
+}
+
+// parseUploadMetadata decodes a tus Upload-Metadata header: comma-separated pairs of a key and an
+// optional base64 encoded value
+func parseUploadMetadata(header string) (map[string]string, error) {
+	metadata := map[string]string{}
+	if strings.TrimSpace(header) == "" {
+		return metadata, nil
+	}
+
+	for _, pair := range strings.Split(header, ",") {
+		key, encoded, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" {
+			return nil, errors.New("metadata keys must not be empty")
+		}
+		if _, exists := metadata[key]; exists {
+			return nil, errors.New("duplicate metadata key " + key)
+		}
+
+		value, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, errors.New("metadata value of " + key + " is not valid base64")
+		}
+		metadata[key] = string(value)
+	}
+
+	return metadata, nil
+}
@@ -14,6 +14,12 @@ import (
 	"go.uber.org/zap"
 )
 
+// Bounds of the days parameter of the inactive accounts report
+const (
+	defaultInactiveDays = 90
+	maxInactiveDays     = 3650
+)
+
 type UserHandler struct {
 	userService service.UserService
 	validator   *validator.Validator
@@ -48,11 +54,11 @@ func (h *UserHandler) CreateUser(c echo.Context) error {
 	var req dto.CreateUserRequest
 	if err := c.Bind(&req); err != nil {
 		logger.Error("Failed to bind request", zap.Error(err), zap.String("request_id", requestID))
-		return response.BadRequest(c, "Invalid request body", err.Error())
+		return invalidBody(err)
 	}
 	
 	// Validate request
-	if validationErrors := h.validator.ValidateStruct(req); validationErrors != nil {
+	if validationErrors := h.validator.ValidateStruct(c.Request().Context(), req); validationErrors != nil {
 		logger.Warn("Validation failed", zap.Any("errors", validationErrors), zap.String("request_id", requestID))
 		return response.ValidationError(c, "Validation failed", validationErrors)
 	}
@@ -60,7 +66,7 @@ func (h *UserHandler) CreateUser(c echo.Context) error {
 	user, err := h.userService.CreateUser(c.Request().Context(), req)
 	if err != nil {
 		logger.Error("Failed to create user", zap.Error(err), zap.String("request_id", requestID))
-		return response.BadRequest(c, "Failed to create user", err.Error())
+		return err
 	}
 	
 	logger.Info("CreateUser request completed", zap.String("request_id", requestID))
@@ -75,17 +81,23 @@ func (h *UserHandler) GetUser(c echo.Context) error {
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
 		logger.Error("Invalid user ID", zap.Error(err), zap.String("request_id", requestID))
-		return response.BadRequest(c, "Invalid user ID", err.Error())
+		return invalidID("Invalid user ID", err)
 	}
 	
 	user, err := h.userService.GetUserByID(c.Request().Context(), id)
 	if err != nil {
 		logger.Error("Failed to get user", zap.Error(err), zap.String("request_id", requestID))
-		return response.NotFound(c, "User not found")
+		return err
+	}
+	
+	data, err := response.Shape(c.Request().Context(), user, response.GetShapeParams(c), nil)
+	if err != nil {
+		logger.Error("Failed to shape user response", zap.Error(err), zap.String("request_id", requestID))
+		return err
 	}
 	
 	logger.Info("GetUser request completed", zap.String("request_id", requestID))
-	return response.Success(c, "User retrieved successfully", user)
+	return response.Success(c, "User retrieved successfully", data)
 }
 
 func (h *UserHandler) UpdateUser(c echo.Context) error {
@@ -96,17 +108,17 @@ func (h *UserHandler) UpdateUser(c echo.Context) error {
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
 		logger.Error("Invalid user ID", zap.Error(err), zap.String("request_id", requestID))
-		return response.BadRequest(c, "Invalid user ID", err.Error())
+		return invalidID("Invalid user ID", err)
 	}
 	
 	var req dto.UpdateUserRequest
 	if err := c.Bind(&req); err != nil {
 		logger.Error("Failed to bind request", zap.Error(err), zap.String("request_id", requestID))
-		return response.BadRequest(c, "Invalid request body", err.Error())
+		return invalidBody(err)
 	}
 	
 	// Validate request
-	if validationErrors := h.validator.ValidateStruct(req); validationErrors != nil {
+	if validationErrors := h.validator.ValidateStruct(c.Request().Context(), req); validationErrors != nil {
 		logger.Warn("Validation failed", zap.Any("errors", validationErrors), zap.String("request_id", requestID))
 		return response.ValidationError(c, "Validation failed", validationErrors)
 	}
@@ -114,13 +126,54 @@ func (h *UserHandler) UpdateUser(c echo.Context) error {
 	user, err := h.userService.UpdateUser(c.Request().Context(), id, req)
 	if err != nil {
 		logger.Error("Failed to update user", zap.Error(err), zap.String("request_id", requestID))
-		return response.BadRequest(c, "Failed to update user", err.Error())
+		return err
 	}
 	
 	logger.Info("UpdateUser request completed", zap.String("request_id", requestID))
 	return response.Success(c, "User updated successfully", user)
 }
 
+// UpdateAvatar godoc
+// @Summary Upload user avatar
+// @Description Upload a JPEG, PNG or GIF profile picture. The image is stored as a file and resized to 64, 128 and 256 pixel squares. Users can update their own avatar, admins can update any.
+// @Tags User Management
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param avatar formData file true "Avatar image"
+// @Success 200 {object} response.Response{data=dto.UserResponse} "Avatar updated successfully"
+// @Failure 400 {object} response.Response "Invalid image"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Router /users/{id}/avatar [put]
+func (h *UserHandler) UpdateAvatar(c echo.Context) error {
+	requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+	logger.Info("UpdateAvatar request started", zap.String("request_id", requestID))
+	
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		logger.Error("Invalid user ID", zap.Error(err), zap.String("request_id", requestID))
+		return invalidID("Invalid user ID", err)
+	}
+	
+	file, err := c.FormFile("avatar")
+	if err != nil {
+		logger.Error("Failed to get avatar from form", zap.Error(err), zap.String("request_id", requestID))
+		return missingFile("Avatar is required", err)
+	}
+	
+	user, err := h.userService.UpdateAvatar(c.Request().Context(), id, file)
+	if err != nil {
+		logger.Error("Failed to update avatar", zap.Error(err), zap.String("request_id", requestID))
+		return err
+	}
+	
+	logger.Info("UpdateAvatar request completed", zap.String("request_id", requestID))
+	return response.Success(c, "Avatar updated successfully", user)
+}
+
 func (h *UserHandler) DeleteUser(c echo.Context) error {
 	requestID := c.Response().Header().Get(echo.HeaderXRequestID)
 	logger.Info("DeleteUser request started", zap.String("request_id", requestID))
@@ -129,13 +182,13 @@ func (h *UserHandler) DeleteUser(c echo.Context) error {
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
 		logger.Error("Invalid user ID", zap.Error(err), zap.String("request_id", requestID))
-		return response.BadRequest(c, "Invalid user ID", err.Error())
+		return invalidID("Invalid user ID", err)
 	}
 	
 	err = h.userService.DeleteUser(c.Request().Context(), id)
 	if err != nil {
 		logger.Error("Failed to delete user", zap.Error(err), zap.String("request_id", requestID))
-		return response.BadRequest(c, "Failed to delete user", err.Error())
+		return err
 	}
 	
 	logger.Info("DeleteUser request completed", zap.String("request_id", requestID))
@@ -151,7 +204,8 @@ func (h *UserHandler) DeleteUser(c echo.Context) error {
 // @Security BearerAuth
 // @Param page query int false "Page number (default: 1)"
 // @Param limit query int false "Items per page (default: 10, max: 100)"
-// @Param sort query string false "Sort field: id, name, email, created_at, role (default: id)"
+// @Param cursor query string false "Keyset pagination cursor from next_cursor/prev_cursor; pass it empty to start. Replaces page, skips total counts and does not support sorting by last_login_at"
+// @Param sort query string false "Sort field: id, name, email, created_at, role, last_login_at (default: id)"
 // @Param order query string false "Sort order: ASC, DESC (default: DESC)"
 // @Param search query string false "Search in name and email"
 // @Param name query string false "Filter by name (partial match)"
@@ -160,7 +214,13 @@ func (h *UserHandler) DeleteUser(c echo.Context) error {
 // @Param email_verified query bool false "Filter by email verification status"
 // @Param created_after query string false "Filter by creation date (RFC3339 format)"
 // @Param created_before query string false "Filter by creation date (RFC3339 format)"
+// @Param last_login_after query string false "Filter by last login date (RFC3339 format)"
+// @Param last_login_before query string false "Filter by last login date (RFC3339 format)"
+// @Param never_logged_in query bool false "Filter by whether the user has ever logged in"
+// @Param filter query string false "Filter expression, e.g. role eq 'admin' and created_at gt 2025-01-01"
+// @Param fields query string false "Comma-separated fields to return, e.g. id,name"
 // @Success 200 {object} response.Response{data=[]dto.UserResponse,pagination=pagination.PaginationMeta} "Users retrieved successfully"
+// @Failure 400 {object} response.Response "Invalid cursor or filter"
 // @Failure 401 {object} response.Response "Unauthorized"
 // @Failure 403 {object} response.Response "Forbidden - Moderator+ access required"
 // @Failure 500 {object} response.Response "Internal server error"
@@ -176,12 +236,103 @@ func (h *UserHandler) GetAllUsers(c echo.Context) error {
 	users, paginationMeta, err := h.userService.GetAllUsersWithPagination(c.Request().Context(), paginationParams, filterParams)
 	if err != nil {
 		logger.Error("Failed to get all users", zap.Error(err), zap.String("request_id", requestID))
-		return response.InternalServerError(c, "Failed to get users", err.Error())
+		return err
+	}
+	
+	data, err := response.Shape(c.Request().Context(), users, response.GetShapeParams(c), nil)
+	if err != nil {
+		logger.Error("Failed to shape user response", zap.Error(err), zap.String("request_id", requestID))
+		return err
 	}
 	
 	logger.Info("GetAllUsers request completed", 
 		zap.String("request_id", requestID),
 		zap.Int("total_users", paginationMeta.TotalRecords),
 		zap.Int("page", paginationMeta.CurrentPage))
-	return response.SuccessWithPagination(c, "Users retrieved successfully", users, paginationMeta)
+	return response.SuccessWithPagination(c, "Users retrieved successfully", data, paginationMeta)
+}
+
+// GetLoginHistory godoc
+// @Summary Get login history
+// @Description Get a paginated list of successful and failed login attempts for a user, newest first. Users can view their own history, admins can view any.
+// @Tags User Management
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param page query int false "Page number (default: 1)"
+// @Param limit query int false "Items per page (default: 10, max: 100)"
+// @Success 200 {object} response.Response{data=[]dto.LoginEventResponse,pagination=pagination.PaginationMeta} "Login history retrieved successfully"
+// @Failure 400 {object} response.Response "Invalid user ID"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "User not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /users/{id}/login-history [get]
+func (h *UserHandler) GetLoginHistory(c echo.Context) error {
+	requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+	logger.Info("GetLoginHistory request started", zap.String("request_id", requestID))
+	
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		logger.Error("Invalid user ID", zap.Error(err), zap.String("request_id", requestID))
+		return invalidID("Invalid user ID", err)
+	}
+	
+	paginationParams := pagination.GetPaginationParams(c)
+	
+	events, paginationMeta, err := h.userService.GetLoginHistory(c.Request().Context(), id, paginationParams)
+	if err != nil {
+		logger.Error("Failed to get login history", zap.Error(err), zap.String("request_id", requestID))
+		return err
+	}
+	
+	logger.Info("GetLoginHistory request completed",
+		zap.String("request_id", requestID),
+		zap.Int("total_events", paginationMeta.TotalRecords))
+	return response.SuccessWithPagination(c, "Login history retrieved successfully", events, paginationMeta)
+}
+
+// GetInactiveUsers godoc
+// @Summary Inactive accounts report (Admin only)
+// @Description List accounts that have not logged in for more than the given number of days, oldest activity first. Accounts that never logged in are measured from their creation date. Requires admin role.
+// @Tags User Management
+// @Produce json
+// @Security BearerAuth
+// @Param days query int false "Minimum days since last login (default: 90, max: 3650)"
+// @Param page query int false "Page number (default: 1)"
+// @Param limit query int false "Items per page (default: 10, max: 100)"
+// @Success 200 {object} response.Response{data=[]dto.InactiveUserResponse,pagination=pagination.PaginationMeta} "Inactive users retrieved successfully"
+// @Failure 400 {object} response.Response "Invalid days parameter"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden - Admin access required"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /users/reports/inactive [get]
+func (h *UserHandler) GetInactiveUsers(c echo.Context) error {
+	requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+	logger.Info("GetInactiveUsers request started", zap.String("request_id", requestID))
+	
+	days := defaultInactiveDays
+	if daysStr := c.QueryParam("days"); daysStr != "" {
+		parsedDays, err := strconv.Atoi(daysStr)
+		if err != nil || parsedDays < 1 || parsedDays > maxInactiveDays {
+			logger.Warn("Invalid days parameter", zap.String("days", daysStr), zap.String("request_id", requestID))
+			return response.BadRequest(c, "Invalid days parameter", "days must be an integer between 1 and 3650")
+		}
+		days = parsedDays
+	}
+	
+	paginationParams := pagination.GetPaginationParams(c)
+	
+	users, paginationMeta, err := h.userService.GetInactiveUsers(c.Request().Context(), days, paginationParams)
+	if err != nil {
+		logger.Error("Failed to get inactive users", zap.Error(err), zap.String("request_id", requestID))
+		return err
+	}
+	
+	logger.Info("GetInactiveUsers request completed",
+		zap.String("request_id", requestID),
+		zap.Int("days", days),
+		zap.Int("total_users", paginationMeta.TotalRecords))
+	return response.SuccessWithPagination(c, "Inactive users retrieved successfully", users, paginationMeta)
 }
\ No newline at end of file
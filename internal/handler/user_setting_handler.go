@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"go-template/internal/dto"
+	"go-template/internal/logger"
+	"go-template/internal/service"
+	"go-template/pkg/response"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+type UserSettingHandler struct {
+	userSettingService service.UserSettingService
+}
+
+func NewUserSettingHandler(userSettingService service.UserSettingService) *UserSettingHandler {
+	return &UserSettingHandler{
+		userSettingService: userSettingService,
+	}
+}
+
+// GetSettings godoc
+// @Summary Get own settings
+// @Description Get every setting of the current user with its value, default and constraints
+// @Tags User Management
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]dto.UserSettingResponse} "Settings retrieved successfully"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /users/me/settings [get]
+func (h *UserSettingHandler) GetSettings(c echo.Context) error {
+	requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+	userID := c.Get("user_id").(int) // Set by auth middleware
+	logger.Info("GetSettings request started", zap.String("request_id", requestID), zap.Int("user_id", userID))
+
+	settings, err := h.userSettingService.GetSettings(c.Request().Context(), userID)
+	if err != nil {
+		logger.Error("Failed to get settings", zap.Error(err), zap.String("request_id", requestID))
+		return err
+	}
+
+	logger.Info("GetSettings request completed", zap.String("request_id", requestID))
+	return response.Success(c, "Settings retrieved successfully", settings)
+}
+
+// UpdateSettings godoc
+// @Summary Update own settings
+// @Description Update one or more settings of the current user. Keys must exist in the settings schema; a null value resets a key to its default. Locked settings cannot be changed.
+// @Tags User Management
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body object true "Map of setting keys to values"
+// @Success 200 {object} response.Response{data=[]dto.UserSettingResponse} "Settings updated successfully"
+// @Failure 400 {object} response.Response{error=[]dto.ValidationError} "Validation error"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /users/me/settings [patch]
+func (h *UserSettingHandler) UpdateSettings(c echo.Context) error {
+	requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+	userID := c.Get("user_id").(int) // Set by auth middleware
+	logger.Info("UpdateSettings request started", zap.String("request_id", requestID), zap.Int("user_id", userID))
+
+	var req dto.UpdateUserSettingsRequest
+	if err := c.Bind(&req); err != nil {
+		logger.Error("Failed to bind settings request", zap.Error(err), zap.String("request_id", requestID))
+		return invalidBody(err)
+	}
+
+	settings, err := h.userSettingService.UpdateSettings(c.Request().Context(), userID, req)
+	if err != nil {
+		logger.Error("Failed to update settings", zap.Error(err), zap.String("request_id", requestID))
+		return err
+	}
+
+	logger.Info("UpdateSettings request completed", zap.String("request_id", requestID))
+	return response.Success(c, "Settings updated successfully", settings)
+}
@@ -2,14 +2,20 @@ package middleware
 
 import (
 	"go-template/internal/logger"
+	"go-template/pkg/apperror"
 	"go-template/pkg/jwt"
-	"go-template/pkg/response"
 	"strings"
 
 	"github.com/labstack/echo/v4"
 	"go.uber.org/zap"
 )
 
+var (
+	ErrMissingToken = apperror.New(apperror.KindUnauthorized, apperror.CodeMissingToken, "authorization header required")
+	ErrInvalidToken = apperror.New(apperror.KindUnauthorized, apperror.CodeInvalidToken, "invalid token")
+	ErrTokenExpired = apperror.New(apperror.KindUnauthorized, apperror.CodeTokenExpired, "token has expired")
+)
+
 // AuthMiddleware creates JWT authentication middleware
 func AuthMiddleware(jwtManager *jwt.JWTManager) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
@@ -20,20 +26,20 @@ func AuthMiddleware(jwtManager *jwt.JWTManager) echo.MiddlewareFunc {
 			authHeader := c.Request().Header.Get("Authorization")
 			if authHeader == "" {
 				logger.Warn("Missing Authorization header", zap.String("request_id", requestID))
-				return response.Unauthorized(c, "Authorization header required")
+				return ErrMissingToken
 			}
 
 			// Check if it starts with "Bearer "
 			if !strings.HasPrefix(authHeader, "Bearer ") {
 				logger.Warn("Invalid Authorization header format", zap.String("request_id", requestID))
-				return response.Unauthorized(c, "Authorization header must start with 'Bearer '")
+				return ErrInvalidToken.WithMessage("authorization header must start with 'Bearer '")
 			}
 
 			// Extract token
 			token := strings.TrimPrefix(authHeader, "Bearer ")
 			if token == "" {
 				logger.Warn("Empty token in Authorization header", zap.String("request_id", requestID))
-				return response.Unauthorized(c, "Token cannot be empty")
+				return ErrMissingToken.WithMessage("token cannot be empty")
 			}
 
 			// Validate token
@@ -43,14 +49,10 @@ func AuthMiddleware(jwtManager *jwt.JWTManager) echo.MiddlewareFunc {
 					zap.Error(err), 
 					zap.String("request_id", requestID))
 				
-				switch err {
-				case jwt.ErrExpiredToken:
-					return response.Unauthorized(c, "Token has expired")
-				case jwt.ErrInvalidToken, jwt.ErrInvalidClaims:
-					return response.Unauthorized(c, "Invalid token")
-				default:
-					return response.Unauthorized(c, "Token validation failed")
+				if err == jwt.ErrExpiredToken {
+					return ErrTokenExpired
 				}
+				return ErrInvalidToken
 			}
 
 			// Set user information in context
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"go-template/pkg/storage"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ClientIPExtractor determines the client IP Echo reports through RealIP. Without trusted proxies it
+// is the address of the connection, as clients can put anything in X-Forwarded-For and X-Real-IP.
+// Behind proxies, X-Forwarded-For is followed back through the trusted proxies only. Proxies are IP
+// addresses or CIDR ranges.
+func ClientIPExtractor(trustedProxies []string) (echo.IPExtractor, error) {
+	if len(trustedProxies) == 0 {
+		return echo.ExtractIPDirect(), nil
+	}
+
+	options := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+	for _, proxy := range trustedProxies {
+		if !strings.Contains(proxy, "/") {
+			if ip := net.ParseIP(proxy); ip != nil && ip.To4() != nil {
+				proxy += "/32"
+			} else {
+				proxy += "/128"
+			}
+		}
+		_, ipRange, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+		}
+		options = append(options, echo.TrustIPRange(ipRange))
+	}
+	return echo.ExtractIPFromXFFHeader(options...), nil
+}
+
+// ClientIPMiddleware stores the client IP in the request context, so signed file URLs issued while
+// handling the request can be bound to it
+func ClientIPMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.SetRequest(c.Request().WithContext(storage.WithClientIP(c.Request().Context(), c.RealIP())))
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-template/pkg/storage"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestClientIPMiddleware(t *testing.T) {
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		remoteAddr     string
+		forwardedFor   string
+		want           string
+	}{
+		{"direct client", nil, "203.0.113.7:5000", "", "203.0.113.7"},
+		{"spoofed header without trusted proxies", nil, "203.0.113.7:5000", "198.51.100.1", "203.0.113.7"},
+		{"spoofed header from a private address", nil, "10.0.0.5:5000", "198.51.100.1", "10.0.0.5"},
+		{"trusted proxy", []string{"10.0.0.0/8"}, "10.0.0.5:5000", "198.51.100.1", "198.51.100.1"},
+		{"trusted proxy address", []string{"10.0.0.5"}, "10.0.0.5:5000", "198.51.100.1", "198.51.100.1"},
+		{"spoofed entry before the trusted proxy", []string{"10.0.0.0/8"}, "10.0.0.5:5000", "192.0.2.9, 198.51.100.1", "198.51.100.1"},
+		{"untrusted proxy", []string{"10.0.0.0/8"}, "203.0.113.7:5000", "198.51.100.1", "203.0.113.7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			extractor, err := ClientIPExtractor(tt.trustedProxies)
+			if err != nil {
+				t.Fatalf("ClientIPExtractor: %v", err)
+			}
+			e := echo.New()
+			e.IPExtractor = extractor
+
+			var clientIP string
+			e.Use(ClientIPMiddleware())
+			e.GET("/", func(c echo.Context) error {
+				clientIP = storage.ClientIPFromContext(c.Request().Context())
+				return c.NoContent(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set(echo.HeaderXRealIP, "192.0.2.200")
+			if tt.forwardedFor != "" {
+				req.Header.Set(echo.HeaderXForwardedFor, tt.forwardedFor)
+			}
+			e.ServeHTTP(httptest.NewRecorder(), req)
+
+			if clientIP != tt.want {
+				t.Errorf("client IP = %q, want %q", clientIP, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientIPExtractorRejectsInvalidProxies(t *testing.T) {
+	for _, proxy := range []string{"proxy.internal", "10.0.0.0/33"} {
+		if _, err := ClientIPExtractor([]string{proxy}); err == nil {
+			t.Errorf("ClientIPExtractor(%q) accepted an invalid proxy", proxy)
+		}
+	}
+}
@@ -1,12 +1,18 @@
 package middleware
 
 import (
+	"net/http"
+
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 )
 
 func CORSMiddleware() echo.MiddlewareFunc {
 	return middleware.CORSWithConfig(middleware.CORSConfig{
+		// Plain OPTIONS requests are not preflights; let them reach routes such as tus discovery
+		Skipper: func(c echo.Context) bool {
+			return c.Request().Method == http.MethodOptions && c.Request().Header.Get(echo.HeaderAccessControlRequestMethod) == ""
+		},
 		AllowOrigins: []string{
 			"http://localhost:3000",
 			"http://localhost:8080",
@@ -14,6 +20,7 @@ func CORSMiddleware() echo.MiddlewareFunc {
 		},
 		AllowMethods: []string{
 			echo.GET,
+			echo.HEAD,
 			echo.POST,
 			echo.PUT,
 			echo.PATCH,
@@ -27,6 +34,31 @@ func CORSMiddleware() echo.MiddlewareFunc {
 			echo.HeaderAuthorization,
 			echo.HeaderXRequestID,
 			"X-CSRF-Token",
+			"Tus-Resumable",
+			"Upload-Length",
+			"Upload-Offset",
+			"Upload-Metadata",
+			"Range",
+			"If-Range",
+			"If-None-Match",
+			"If-Modified-Since",
+			"X-Share-Password",
+		},
+		ExposeHeaders: []string{
+			echo.HeaderLocation,
+			"Tus-Resumable",
+			"Tus-Version",
+			"Tus-Extension",
+			"Tus-Max-Size",
+			"Upload-Offset",
+			"Upload-Length",
+			"Upload-Metadata",
+			"X-File-ID",
+			"Accept-Ranges",
+			"Content-Range",
+			"Content-Disposition",
+			"ETag",
+			"Digest",
 		},
 		AllowCredentials: true,
 		MaxAge:           300,
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"go-template/internal/entity"
+	"go-template/internal/repository"
+
+	"github.com/labstack/echo/v4"
+)
+
+// currentUser returns the authenticated user, loading them from the database only once per request.
+// The locale, RBAC, email verification and file access middleware of a route share the user kept in
+// the context. Failed lookups are not kept, so each middleware reports them its own way.
+func currentUser(c echo.Context, userRepo repository.UserRepository, userID int) (*entity.User, error) {
+	if user, ok := c.Get("user").(*entity.User); ok && user.ID == userID {
+		return user, nil
+	}
+
+	user, err := userRepo.GetByID(c.Request().Context(), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	c.Set("user", user)
+	return user, nil
+}
@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-template/internal/entity"
+	"go-template/internal/repository"
+
+	"github.com/labstack/echo/v4"
+)
+
+// countingUserRepository looks users up by ID and counts the lookups
+type countingUserRepository struct {
+	repository.UserRepository
+	users   map[int]*entity.User
+	lookups int
+}
+
+func (r *countingUserRepository) GetByID(ctx context.Context, id int) (*entity.User, error) {
+	r.lookups++
+	user, ok := r.users[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return user, nil
+}
+
+// fakeAccessFileRepository holds file 10 of user 1
+type fakeAccessFileRepository struct {
+	repository.FileRepository
+}
+
+func (r *fakeAccessFileRepository) GetByID(ctx context.Context, id int) (*entity.File, error) {
+	if id != 10 {
+		return nil, sql.ErrNoRows
+	}
+	return &entity.File{ID: 10, UploadedBy: 1}, nil
+}
+
+// serveAuthenticated runs handler behind middleware for a request of userID to /files/10
+func serveAuthenticated(userID int, handler echo.HandlerFunc, middleware ...echo.MiddlewareFunc) *httptest.ResponseRecorder {
+	e := echo.New()
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/files/10", nil), rec)
+	c.SetParamNames("id")
+	c.SetParamValues("10")
+	c.Set("user_id", userID)
+
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+	if err := handler(c); err != nil {
+		e.HTTPErrorHandler(err, c)
+	}
+	return rec
+}
+
+func TestMiddlewareLoadUserOnce(t *testing.T) {
+	locale := "es"
+	users := &countingUserRepository{users: map[int]*entity.User{
+		2: {ID: 2, Role: entity.RoleModerator, Locale: &locale},
+	}}
+
+	var role interface{}
+	rec := serveAuthenticated(2, func(c echo.Context) error {
+		role = c.Get("user_role")
+		return c.NoContent(http.StatusNoContent)
+	},
+		UserLocaleMiddleware(users),
+		EmailVerificationMiddleware(users),
+		ModeratorOrAdminMiddleware(users),
+		FileReadMiddleware(users, &fakeAccessFileRepository{}, nil),
+	)
+
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+	if users.lookups != 1 {
+		t.Errorf("user looked up %d times, want once per request", users.lookups)
+	}
+	if role != entity.RoleModerator || rec.Header().Get("Content-Language") != "es" || rec.Header().Get("X-Email-Verification-Status") != "unverified" {
+		t.Errorf("role %v, Content-Language %q, X-Email-Verification-Status %q; want the checks to use the shared user",
+			role, rec.Header().Get("Content-Language"), rec.Header().Get("X-Email-Verification-Status"))
+	}
+}
+
+func TestMiddlewareRetriesFailedUserLookup(t *testing.T) {
+	users := &countingUserRepository{users: map[int]*entity.User{}}
+
+	rec := serveAuthenticated(3, func(c echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	},
+		UserLocaleMiddleware(users),
+		AdminMiddleware(users),
+	)
+
+	// The locale is optional, but RBAC refuses a user that does not exist
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+	if users.lookups != 2 {
+		t.Errorf("user looked up %d times, want a lookup per middleware after failures", users.lookups)
+	}
+}
@@ -1,7 +1,6 @@
 package middleware
 
 import (
-	"context"
 	"database/sql"
 	"go-template/internal/logger"
 	"go-template/internal/repository"
@@ -26,7 +25,7 @@ func EmailVerificationMiddleware(userRepo repository.UserRepository) echo.Middle
 			}
 
 			// Get user details to check email verification status
-			user, err := userRepo.GetByID(context.Background(), userID)
+			user, err := currentUser(c, userRepo, userID)
 			if err != nil {
 				if err != sql.ErrNoRows {
 					logger.Warn("Failed to get user for email verification check", 
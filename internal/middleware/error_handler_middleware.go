@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"net/http"
+
+	"go-template/internal/logger"
+	"go-template/pkg/response"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// ErrorHandler renders every error returned by handlers and middleware, so services can return
+// typed domain errors and the mapping to status codes and error codes lives in one place
+func ErrorHandler() echo.HTTPErrorHandler {
+	return func(err error, c echo.Context) {
+		if c.Response().Committed {
+			return
+		}
+
+		requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+		status := response.ErrorStatus(err)
+		if status >= http.StatusInternalServerError {
+			logger.Error("Request failed",
+				zap.Error(err),
+				zap.String("request_id", requestID),
+				zap.String("method", c.Request().Method),
+				zap.String("path", c.Request().URL.Path))
+		}
+
+		if c.Request().Method == http.MethodHead {
+			err = c.NoContent(status)
+		} else {
+			err = response.Error(c, err)
+		}
+		if err != nil {
+			logger.Error("Failed to write error response", zap.Error(err), zap.String("request_id", requestID))
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"context"
+	"database/sql"
+	"go-template/internal/entity"
+	"go-template/internal/logger"
+	"go-template/internal/repository"
+	"go-template/pkg/response"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// FileAccessMiddleware creates middleware that allows access to the file in the "id" parameter if the user
+// owns it, was granted the permission on it, or is a moderator or admin, see repository.CanAccessFile
+func FileAccessMiddleware(userRepo repository.UserRepository, fileRepo repository.FileRepository, grantRepo repository.FileGrantRepository, permission string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+
+			// Get user ID from JWT token (set by AuthMiddleware)
+			userID, ok := c.Get("user_id").(int)
+			if !ok {
+				logger.Warn("File access check failed: user not authenticated",
+					zap.String("request_id", requestID),
+					zap.String("permission", permission))
+				return response.Unauthorized(c, "User not authenticated")
+			}
+
+			// Invalid IDs and missing files are reported by the handler
+			fileID, err := strconv.Atoi(c.Param("id"))
+			if err != nil {
+				return next(c)
+			}
+			file, err := fileRepo.GetByID(context.Background(), fileID)
+			if err != nil {
+				if err == sql.ErrNoRows {
+					return next(c)
+				}
+
+				logger.Error("File access check failed: database error",
+					zap.Error(err),
+					zap.String("request_id", requestID),
+					zap.Int("file_id", fileID))
+				return response.InternalServerError(c, "Internal server error", nil)
+			}
+
+			user, err := currentUser(c, userRepo, userID)
+			if err != nil {
+				if err == sql.ErrNoRows {
+					logger.Warn("File access check failed: user not found",
+						zap.String("request_id", requestID),
+						zap.Int("user_id", userID))
+					return response.Unauthorized(c, "User not found")
+				}
+
+				logger.Error("File access check failed: database error",
+					zap.Error(err),
+					zap.String("request_id", requestID),
+					zap.Int("user_id", userID))
+				return response.InternalServerError(c, "Internal server error", nil)
+			}
+
+			allowed, err := repository.CanAccessFile(context.Background(), grantRepo, user, file, permission)
+			if err != nil {
+				logger.Error("File access check failed: database error",
+					zap.Error(err),
+					zap.String("request_id", requestID),
+					zap.Int("file_id", fileID))
+				return response.InternalServerError(c, "Internal server error", nil)
+			}
+			if !allowed {
+				logger.Warn("File access check failed: file not shared with user",
+					zap.String("request_id", requestID),
+					zap.Int("user_id", userID),
+					zap.Int("file_id", fileID),
+					zap.String("permission", permission))
+				return response.Forbidden(c, "Insufficient permissions")
+			}
+
+			// Set user role in context for handlers to use
+			c.Set("user_role", user.Role)
+
+			logger.Debug("File access check passed",
+				zap.String("request_id", requestID),
+				zap.Int("user_id", userID),
+				zap.Int("file_id", fileID),
+				zap.String("user_role", user.Role))
+
+			return next(c)
+		}
+	}
+}
+
+// FileReadMiddleware creates middleware that requires read access to the file
+func FileReadMiddleware(userRepo repository.UserRepository, fileRepo repository.FileRepository, grantRepo repository.FileGrantRepository) echo.MiddlewareFunc {
+	return FileAccessMiddleware(userRepo, fileRepo, grantRepo, entity.FilePermissionRead)
+}
+
+// FileWriteMiddleware creates middleware that requires write access to the file
+func FileWriteMiddleware(userRepo repository.UserRepository, fileRepo repository.FileRepository, grantRepo repository.FileGrantRepository) echo.MiddlewareFunc {
+	return FileAccessMiddleware(userRepo, fileRepo, grantRepo, entity.FilePermissionWrite)
+}
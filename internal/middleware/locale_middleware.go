@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"go-template/internal/logger"
+	"go-template/internal/repository"
+	"go-template/pkg/i18n"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// LocaleMiddleware negotiates the response locale from the Accept-Language header
+func LocaleMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			locale := i18n.Negotiate(c.Request().Header.Get("Accept-Language"))
+			setLocale(c, locale)
+			c.Response().Header().Add(echo.HeaderVary, "Accept-Language")
+			return next(c)
+		}
+	}
+}
+
+// UserLocaleMiddleware lets the locale of the authenticated user's profile override the negotiated one.
+// It must run after AuthMiddleware.
+func UserLocaleMiddleware(userRepo repository.UserRepository) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			userID, ok := c.Get("user_id").(int)
+			if !ok {
+				return next(c)
+			}
+
+			user, err := currentUser(c, userRepo, userID)
+			if err != nil {
+				// Keep the negotiated locale; authorization is checked elsewhere
+				logger.Debug("Failed to get user for locale override",
+					zap.Error(err),
+					zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)),
+					zap.Int("user_id", userID))
+				return next(c)
+			}
+
+			if user.Locale != nil {
+				if locale, ok := i18n.Match(*user.Locale); ok {
+					setLocale(c, locale)
+				}
+			}
+
+			return next(c)
+		}
+	}
+}
+
+func setLocale(c echo.Context, locale string) {
+	c.SetRequest(c.Request().WithContext(i18n.WithLocale(c.Request().Context(), locale)))
+	c.Response().Header().Set("Content-Language", locale)
+}
@@ -1,8 +1,8 @@
 package middleware
 
 import (
-	"context"
 	"database/sql"
+	"go-template/internal/entity"
 	"go-template/internal/logger"
 	"go-template/internal/repository"
 	"go-template/pkg/response"
@@ -28,7 +28,7 @@ func RoleMiddleware(userRepo repository.UserRepository, requiredRole string) ech
 			}
 
 			// Get user from database to check role
-			user, err := userRepo.GetByID(context.Background(), userID)
+			user, err := currentUser(c, userRepo, userID)
 			if err != nil {
 				if err == sql.ErrNoRows {
 					logger.Warn("RBAC check failed: user not found", 
@@ -87,7 +87,7 @@ func MultiRoleMiddleware(userRepo repository.UserRepository, allowedRoles ...str
 			}
 
 			// Get user from database to check role
-			user, err := userRepo.GetByID(context.Background(), userID)
+			user, err := currentUser(c, userRepo, userID)
 			if err != nil {
 				if err == sql.ErrNoRows {
 					logger.Warn("Multi-role RBAC check failed: user not found", 
@@ -184,7 +184,7 @@ func OwnerOrRoleMiddleware(userRepo repository.UserRepository, resourceUserIDPar
 			}
 
 			// User doesn't own the resource, check if they have required role
-			user, err := userRepo.GetByID(context.Background(), userID)
+			user, err := currentUser(c, userRepo, userID)
 			if err != nil {
 				if err == sql.ErrNoRows {
 					logger.Warn("Owner/Role RBAC check failed: user not found", 
@@ -239,12 +239,12 @@ func OwnerOrRoleMiddleware(userRepo repository.UserRepository, resourceUserIDPar
 
 // AdminMiddleware creates middleware that requires admin role
 func AdminMiddleware(userRepo repository.UserRepository) echo.MiddlewareFunc {
-	return RoleMiddleware(userRepo, "admin")
+	return RoleMiddleware(userRepo, entity.RoleAdmin)
 }
 
 // ModeratorOrAdminMiddleware creates middleware that requires moderator or admin role
 func ModeratorOrAdminMiddleware(userRepo repository.UserRepository) echo.MiddlewareFunc {
-	return MultiRoleMiddleware(userRepo, "moderator", "admin")
+	return MultiRoleMiddleware(userRepo, entity.RoleModerator, entity.RoleAdmin)
 }
 
 // SelfOrAdminMiddleware creates middleware that allows users to access their own resources or requires admin role
@@ -27,8 +27,10 @@ func RequestLoggerMiddleware() echo.MiddlewareFunc {
 				zap.String("user_agent", c.Request().UserAgent()),
 			)
 			
-			// Process request
-			err := next(c)
+			// Process request; errors are rendered here so the logged status is the one sent
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
 			
 			// Calculate duration
 			duration := time.Since(start)
@@ -43,7 +45,7 @@ func RequestLoggerMiddleware() echo.MiddlewareFunc {
 				zap.Int64("bytes_out", c.Response().Size),
 			)
 			
-			return err
+			return nil
 		}
 	}
 }
\ No newline at end of file
@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// StreamTimeoutMiddleware turns the server's read and write timeouts into idle timeouts on routes that
+// transfer large bodies, such as upload chunks and archives: every read or write that makes progress
+// moves the deadline, so transfers on slow links are not cut off while stalled connections still are.
+func StreamTimeoutMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			server, ok := c.Request().Context().Value(http.ServerContextKey).(*http.Server)
+			if !ok {
+				return next(c)
+			}
+
+			controller := http.NewResponseController(c.Response().Writer)
+			if server.ReadTimeout > 0 && c.Request().Body != nil && c.Request().Body != http.NoBody {
+				c.Request().Body = &deadlineReader{
+					ReadCloser: c.Request().Body,
+					controller: controller,
+					timeout:    server.ReadTimeout,
+				}
+			}
+			if server.WriteTimeout > 0 {
+				// The write deadline runs from the start of the request; moving it now leaves the whole
+				// timeout to the first write
+				controller.SetWriteDeadline(time.Now().Add(server.WriteTimeout))
+				c.Response().Writer = &deadlineWriter{
+					ResponseWriter: c.Response().Writer,
+					controller:     controller,
+					timeout:        server.WriteTimeout,
+				}
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// deadlineReader moves the connection's read deadline whenever a read returns data. Once the body has
+// been read the deadline is cleared: the server keeps reading in the background to notice clients going
+// away, and that read timing out would cancel the request while its response is still being prepared.
+type deadlineReader struct {
+	io.ReadCloser
+	controller *http.ResponseController
+	timeout    time.Duration
+}
+
+func (r *deadlineReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if errors.Is(err, io.EOF) {
+		r.controller.SetReadDeadline(time.Time{})
+	} else if n > 0 {
+		r.controller.SetReadDeadline(time.Now().Add(r.timeout))
+	}
+	return n, err
+}
+
+// deadlineWriter moves the connection's write deadline before every write
+type deadlineWriter struct {
+	http.ResponseWriter
+	controller *http.ResponseController
+	timeout    time.Duration
+}
+
+func (w *deadlineWriter) WriteHeader(code int) {
+	w.controller.SetWriteDeadline(time.Now().Add(w.timeout))
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *deadlineWriter) Write(b []byte) (int, error) {
+	w.controller.SetWriteDeadline(time.Now().Add(w.timeout))
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the server's writer, e.g. to flush
+func (w *deadlineWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net/http"
+
+	"go-template/pkg/apperror"
+
+	"github.com/labstack/echo/v4"
+)
+
+// tusVersion is the only version of the tus resumable upload protocol the API speaks
+const tusVersion = "1.0.0"
+
+var (
+	ErrUnsupportedTusVersion   = apperror.New(apperror.KindPreconditionFailed, apperror.CodeUploadVersionUnsupported, "unsupported tus protocol version")
+	ErrInvalidChunkContentType = apperror.New(apperror.KindUnsupportedMediaType, apperror.CodeUnsupportedMediaType, "chunks must be sent as application/offset+octet-stream")
+)
+
+// TusMiddleware enforces the tus protocol headers on resumable upload routes: every request except
+// OPTIONS must declare the supported version, and chunks must use the tus content type.
+func TusMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			c.Response().Header().Set("Tus-Resumable", tusVersion)
+
+			if req.Method == http.MethodOptions {
+				c.Response().Header().Set("Tus-Version", tusVersion)
+				return next(c)
+			}
+
+			if req.Header.Get("Tus-Resumable") != tusVersion {
+				c.Response().Header().Set("Tus-Version", tusVersion)
+				return ErrUnsupportedTusVersion
+			}
+
+			if req.Method == http.MethodPatch && req.Header.Get(echo.HeaderContentType) != "application/offset+octet-stream" {
+				return ErrInvalidChunkContentType
+			}
+
+			return next(c)
+		}
+	}
+}
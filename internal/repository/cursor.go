@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"database/sql"
+	"strconv"
+	"time"
+
+	"go-template/pkg/pagination"
+)
+
+// keysetArgs holds the typed boundary values passed to the *WithCursor queries.
+// Only the argument matching the sort column (plus the id tie-breaker) is set.
+type keysetArgs struct {
+	sortField  string
+	sortOrder  string // Order of the listing, recorded in the cursors of the page
+	queryOrder string // Order the rows are fetched in, reversed when paging backwards
+	id         sql.NullInt32
+	text       sql.NullString
+	time       sql.NullTime
+	size       sql.NullInt64
+}
+
+// newKeysetArgs resolves the sort of a keyset page and converts its cursor into query arguments.
+// A cursor keeps the sort it was created with, so the sort query parameters only apply to the first page.
+func newKeysetArgs(cursor *pagination.Cursor, paginationParams pagination.PaginationParams, allowedSortFields []string) (keysetArgs, error) {
+	if cursor == nil {
+		sortOrder := pagination.SanitizeOrder(paginationParams.Order)
+		return keysetArgs{
+			sortField:  pagination.ValidateSortField(paginationParams.Sort, allowedSortFields),
+			sortOrder:  sortOrder,
+			queryOrder: sortOrder,
+		}, nil
+	}
+
+	args := keysetArgs{
+		sortField: pagination.ValidateSortField(cursor.Sort, allowedSortFields),
+		sortOrder: pagination.SanitizeOrder(cursor.Order),
+		id:        sql.NullInt32{Int32: int32(cursor.ID), Valid: true},
+	}
+	if args.sortField != cursor.Sort {
+		return keysetArgs{}, pagination.ErrInvalidCursor
+	}
+
+	// Walking backwards is the same query in the opposite order; the caller reverses the rows
+	args.queryOrder = args.sortOrder
+	if cursor.Before {
+		args.queryOrder = pagination.ReverseOrder(args.sortOrder)
+	}
+
+	switch args.sortField {
+	case "id":
+	case "created_at":
+		parsedTime, err := time.Parse(time.RFC3339Nano, cursor.Value)
+		if err != nil {
+			return keysetArgs{}, pagination.ErrInvalidCursor
+		}
+		args.time = sql.NullTime{Time: parsedTime, Valid: true}
+	case "file_size":
+		size, err := strconv.ParseInt(cursor.Value, 10, 64)
+		if err != nil {
+			return keysetArgs{}, pagination.ErrInvalidCursor
+		}
+		args.size = sql.NullInt64{Int64: size, Valid: true}
+	default:
+		args.text = sql.NullString{String: cursor.Value, Valid: true}
+	}
+
+	return args, nil
+}
+
+// formatCursorTime encodes a timestamp sort key without losing the database's microsecond precision
+func formatCursorTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
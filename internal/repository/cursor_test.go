@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"go-template/pkg/pagination"
+)
+
+func TestNewKeysetArgsFirstPage(t *testing.T) {
+	tests := []struct {
+		name      string
+		params    pagination.PaginationParams
+		wantField string
+		wantOrder string
+	}{
+		{"requested sort", pagination.PaginationParams{Sort: "File_Size", Order: "asc"}, "file_size", "ASC"},
+		{"unknown sort", pagination.PaginationParams{Sort: "password", Order: "ASC"}, "id", "ASC"},
+		{"defaults", pagination.PaginationParams{}, "id", "DESC"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, err := newKeysetArgs(nil, tt.params, fileSortFields)
+			if err != nil {
+				t.Fatalf("newKeysetArgs: %v", err)
+			}
+			want := keysetArgs{sortField: tt.wantField, sortOrder: tt.wantOrder, queryOrder: tt.wantOrder}
+			if args != want {
+				t.Errorf("newKeysetArgs = %+v, want %+v", args, want)
+			}
+		})
+	}
+}
+
+func TestNewKeysetArgsFromCursor(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 10, 0, 0, 123456000, time.UTC)
+	id := sql.NullInt32{Int32: 42, Valid: true}
+
+	tests := []struct {
+		name   string
+		cursor pagination.Cursor
+		want   keysetArgs
+	}{
+		{
+			"next page by id",
+			pagination.Cursor{Sort: "id", Order: "ASC", ID: 42},
+			keysetArgs{sortField: "id", sortOrder: "ASC", queryOrder: "ASC", id: id},
+		},
+		{
+			"previous page by id",
+			pagination.Cursor{Sort: "id", Order: "ASC", ID: 42, Before: true},
+			keysetArgs{sortField: "id", sortOrder: "ASC", queryOrder: "DESC", id: id},
+		},
+		{
+			"next page by time",
+			pagination.Cursor{Sort: "created_at", Order: "DESC", Value: formatCursorTime(createdAt), ID: 42},
+			keysetArgs{sortField: "created_at", sortOrder: "DESC", queryOrder: "DESC", id: id, time: sql.NullTime{Time: createdAt, Valid: true}},
+		},
+		{
+			"previous page by size",
+			pagination.Cursor{Sort: "file_size", Order: "DESC", Value: "2048", ID: 42, Before: true},
+			keysetArgs{sortField: "file_size", sortOrder: "DESC", queryOrder: "ASC", id: id, size: sql.NullInt64{Int64: 2048, Valid: true}},
+		},
+		{
+			"next page by name",
+			pagination.Cursor{Sort: "file_name", Order: "ASC", Value: "report.pdf", ID: 42},
+			keysetArgs{sortField: "file_name", sortOrder: "ASC", queryOrder: "ASC", id: id, text: sql.NullString{String: "report.pdf", Valid: true}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The sort query parameters of later pages are ignored in favour of the cursor's
+			args, err := newKeysetArgs(&tt.cursor, pagination.PaginationParams{Sort: "file_name", Order: "DESC"}, fileSortFields)
+			if err != nil {
+				t.Fatalf("newKeysetArgs: %v", err)
+			}
+			if !args.time.Time.Equal(tt.want.time.Time) {
+				t.Errorf("time = %v, want %v", args.time.Time, tt.want.time.Time)
+			}
+			args.time.Time, tt.want.time.Time = time.Time{}, time.Time{}
+			if args != tt.want {
+				t.Errorf("newKeysetArgs = %+v, want %+v", args, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewKeysetArgsRejectsInvalidCursors(t *testing.T) {
+	cursors := map[string]pagination.Cursor{
+		// A cursor of another listing, e.g. of users sorted by email, must not fall back to id
+		"sort field of another listing": {Sort: "email", Order: "ASC", Value: "a@example.com", ID: 42},
+		"empty sort field":              {Order: "ASC", ID: 42},
+		"unparsable time":               {Sort: "created_at", Order: "ASC", Value: "yesterday", ID: 42},
+		"unparsable size":               {Sort: "file_size", Order: "ASC", Value: "big", ID: 42},
+	}
+	for name, cursor := range cursors {
+		t.Run(name, func(t *testing.T) {
+			if _, err := newKeysetArgs(&cursor, pagination.PaginationParams{}, fileSortFields); !errors.Is(err, pagination.ErrInvalidCursor) {
+				t.Errorf("newKeysetArgs(%+v) error %v, want %v", cursor, err, pagination.ErrInvalidCursor)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	db "go-template/db/sqlc"
+	"go-template/internal/entity"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrDataExportInProgress is returned by DataExportRepository.Create when the user already has an
+// export pending or processing
+var ErrDataExportInProgress = errors.New("data export already in progress")
+
+// uniqueViolation is the SQLSTATE of PostgreSQL for a violated unique constraint
+const uniqueViolation = "23505"
+
+type DataExportRepository interface {
+	// Create returns ErrDataExportInProgress when another export of the user is pending or processing,
+	// including one that was interrupted and has not been failed yet
+	Create(ctx context.Context, userID int) (*entity.DataExport, error)
+	GetByID(ctx context.Context, id int) (*entity.DataExport, error)
+	GetByToken(ctx context.Context, token string) (*entity.DataExport, error)
+	// GetPendingByUserID returns the export of the user still in progress; exports not updated after
+	// updatedAfter were interrupted and are ignored
+	GetPendingByUserID(ctx context.Context, userID int, updatedAfter time.Time) (*entity.DataExport, error)
+	GetByUserID(ctx context.Context, userID int) ([]entity.DataExport, error)
+	MarkProcessing(ctx context.Context, id int) error
+	Complete(ctx context.Context, id int, filePath, downloadToken string, expiresAt time.Time) (*entity.DataExport, error)
+	Fail(ctx context.Context, id int, errorMessage string) error
+	// FailStale marks the pending and processing exports not updated since before as failed
+	FailStale(ctx context.Context, before time.Time, errorMessage string) ([]entity.DataExport, error)
+	GetExpired(ctx context.Context, before time.Time) ([]entity.DataExport, error)
+	Delete(ctx context.Context, id int) error
+}
+
+type dataExportRepository struct {
+	db      *sql.DB
+	queries *db.Queries
+}
+
+func NewDataExportRepository(dbConn *sql.DB) DataExportRepository {
+	return &dataExportRepository{
+		db:      dbConn,
+		queries: db.New(dbConn),
+	}
+}
+
+func (r *dataExportRepository) Create(ctx context.Context, userID int) (*entity.DataExport, error) {
+	export, err := r.queries.CreateDataExport(ctx, int32(userID))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation && pgErr.ConstraintName == "idx_data_exports_user_id_in_progress" {
+			return nil, ErrDataExportInProgress
+		}
+		return nil, err
+	}
+
+	return r.mapDBDataExportToEntity(&export), nil
+}
+
+func (r *dataExportRepository) GetByID(ctx context.Context, id int) (*entity.DataExport, error) {
+	export, err := r.queries.GetDataExport(ctx, int32(id))
+	if err != nil {
+		return nil, err
+	}
+
+	return r.mapDBDataExportToEntity(&export), nil
+}
+
+func (r *dataExportRepository) GetByToken(ctx context.Context, token string) (*entity.DataExport, error) {
+	export, err := r.queries.GetDataExportByToken(ctx, sql.NullString{String: token, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.mapDBDataExportToEntity(&export), nil
+}
+
+func (r *dataExportRepository) GetPendingByUserID(ctx context.Context, userID int, updatedAfter time.Time) (*entity.DataExport, error) {
+	export, err := r.queries.GetPendingDataExportByUser(ctx, db.GetPendingDataExportByUserParams{
+		UserID:    int32(userID),
+		UpdatedAt: sql.NullTime{Time: updatedAfter, Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.mapDBDataExportToEntity(&export), nil
+}
+
+func (r *dataExportRepository) GetByUserID(ctx context.Context, userID int) ([]entity.DataExport, error) {
+	dbExports, err := r.queries.GetDataExportsByUser(ctx, int32(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	exports := make([]entity.DataExport, len(dbExports))
+	for i, dbExport := range dbExports {
+		exports[i] = *r.mapDBDataExportToEntity(&dbExport)
+	}
+
+	return exports, nil
+}
+
+func (r *dataExportRepository) MarkProcessing(ctx context.Context, id int) error {
+	return r.queries.MarkDataExportProcessing(ctx, int32(id))
+}
+
+func (r *dataExportRepository) Complete(ctx context.Context, id int, filePath, downloadToken string, expiresAt time.Time) (*entity.DataExport, error) {
+	export, err := r.queries.CompleteDataExport(ctx, db.CompleteDataExportParams{
+		ID:            int32(id),
+		FilePath:      sql.NullString{String: filePath, Valid: true},
+		DownloadToken: sql.NullString{String: downloadToken, Valid: true},
+		ExpiresAt:     sql.NullTime{Time: expiresAt, Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.mapDBDataExportToEntity(&export), nil
+}
+
+func (r *dataExportRepository) Fail(ctx context.Context, id int, errorMessage string) error {
+	return r.queries.FailDataExport(ctx, db.FailDataExportParams{
+		ID:           int32(id),
+		ErrorMessage: sql.NullString{String: errorMessage, Valid: errorMessage != ""},
+	})
+}
+
+func (r *dataExportRepository) FailStale(ctx context.Context, before time.Time, errorMessage string) ([]entity.DataExport, error) {
+	dbExports, err := r.queries.FailStaleDataExports(ctx, db.FailStaleDataExportsParams{
+		UpdatedAt:    sql.NullTime{Time: before, Valid: true},
+		ErrorMessage: sql.NullString{String: errorMessage, Valid: errorMessage != ""},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	exports := make([]entity.DataExport, len(dbExports))
+	for i, dbExport := range dbExports {
+		exports[i] = *r.mapDBDataExportToEntity(&dbExport)
+	}
+
+	return exports, nil
+}
+
+func (r *dataExportRepository) GetExpired(ctx context.Context, before time.Time) ([]entity.DataExport, error) {
+	dbExports, err := r.queries.GetExpiredDataExports(ctx, sql.NullTime{Time: before, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+
+	exports := make([]entity.DataExport, len(dbExports))
+	for i, dbExport := range dbExports {
+		exports[i] = *r.mapDBDataExportToEntity(&dbExport)
+	}
+
+	return exports, nil
+}
+
+func (r *dataExportRepository) Delete(ctx context.Context, id int) error {
+	return r.queries.DeleteDataExport(ctx, int32(id))
+}
+
+func (r *dataExportRepository) mapDBDataExportToEntity(dbExport *db.DataExports) *entity.DataExport {
+	return &entity.DataExport{
+		ID:            int(dbExport.ID),
+		UserID:        int(dbExport.UserID),
+		Status:        dbExport.Status,
+		FilePath:      dbExport.FilePath.String,
+		DownloadToken: dbExport.DownloadToken.String,
+		ErrorMessage:  dbExport.ErrorMessage.String,
+		ExpiresAt:     nullTimeToPtr(dbExport.ExpiresAt),
+		CompletedAt:   nullTimeToPtr(dbExport.CompletedAt),
+		CreatedAt:     dbExport.CreatedAt.Time,
+		UpdatedAt:     dbExport.UpdatedAt.Time,
+	}
+}
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	db "go-template/db/sqlc"
+	"go-template/internal/entity"
+)
+
+type FileBlobRepository interface {
+	Acquire(ctx context.Context, checksum, storageKey string, size int64) (*entity.FileBlob, error)
+	MarkStored(ctx context.Context, checksum string) error
+	Get(ctx context.Context, checksum string) (*entity.FileBlob, error)
+	Release(ctx context.Context, checksum string) error
+	GetUnreferenced(ctx context.Context, before time.Time, limit int) ([]entity.FileBlob, error)
+	DeleteUnreferenced(ctx context.Context, checksum string, deleteContent func(storageKey string) error) error
+	GetToVerify(ctx context.Context, verifiedBefore time.Time, limit int) ([]entity.FileBlob, error)
+	MarkVerified(ctx context.Context, checksum string, corrupted bool) error
+}
+
+type fileBlobRepository struct {
+	db      *sql.DB
+	queries *db.Queries
+}
+
+func NewFileBlobRepository(dbConn *sql.DB) FileBlobRepository {
+	return &fileBlobRepository{
+		db:      dbConn,
+		queries: db.New(dbConn),
+	}
+}
+
+// Acquire adds a reference to the blob with the checksum, registering it under storageKey when it is
+// new. Only its storage key, Stored and Corrupted are set. Until Stored, its content has to be stored by
+// the caller, as a concurrent upload registering the blob may still be writing it or may fail.
+func (r *fileBlobRepository) Acquire(ctx context.Context, checksum, storageKey string, size int64) (*entity.FileBlob, error) {
+	blob, err := r.queries.AcquireFileBlob(ctx, db.AcquireFileBlobParams{
+		Checksum:   checksum,
+		StorageKey: storageKey,
+		FileSize:   size,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &entity.FileBlob{
+		Checksum:   checksum,
+		StorageKey: blob.StorageKey,
+		Size:       size,
+		Stored:     blob.Stored,
+		Corrupted:  blob.Corrupted,
+	}, nil
+}
+
+// MarkStored records that the content of the blob has been written to storage, clearing the result of
+// earlier verifications
+func (r *fileBlobRepository) MarkStored(ctx context.Context, checksum string) error {
+	return r.queries.MarkFileBlobStored(ctx, checksum)
+}
+
+func (r *fileBlobRepository) Get(ctx context.Context, checksum string) (*entity.FileBlob, error) {
+	dbBlob, err := r.queries.GetFileBlob(ctx, checksum)
+	if err != nil {
+		return nil, err
+	}
+	return &r.mapDBFileBlobsToEntities([]db.FileBlobs{dbBlob})[0], nil
+}
+
+// Release drops a reference; the blob is kept until DeleteUnreferenced removes it
+func (r *fileBlobRepository) Release(ctx context.Context, checksum string) error {
+	return r.queries.ReleaseFileBlob(ctx, checksum)
+}
+
+func (r *fileBlobRepository) GetUnreferenced(ctx context.Context, before time.Time, limit int) ([]entity.FileBlob, error) {
+	dbBlobs, err := r.queries.GetUnreferencedFileBlobs(ctx, db.GetUnreferencedFileBlobsParams{
+		UpdatedAt: sql.NullTime{Time: before, Valid: true},
+		Limit:     int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.mapDBFileBlobsToEntities(dbBlobs), nil
+}
+
+// DeleteUnreferenced removes a blob that still has no references, calling deleteContent with its
+// storage key before the row goes. The row stays locked meanwhile, so a concurrent upload of the same
+// content waits and then stores it anew. It returns sql.ErrNoRows when the blob was referenced again.
+func (r *fileBlobRepository) DeleteUnreferenced(ctx context.Context, checksum string, deleteContent func(storageKey string) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	queries := r.queries.WithTx(tx)
+	blob, err := queries.LockUnreferencedFileBlob(ctx, checksum)
+	if err != nil {
+		return err
+	}
+
+	if err := deleteContent(blob.StorageKey); err != nil {
+		return err
+	}
+
+	if err := queries.DeleteFileBlob(ctx, checksum); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetToVerify returns referenced blobs never verified or last verified before verifiedBefore, oldest first
+func (r *fileBlobRepository) GetToVerify(ctx context.Context, verifiedBefore time.Time, limit int) ([]entity.FileBlob, error) {
+	dbBlobs, err := r.queries.GetFileBlobsToVerify(ctx, db.GetFileBlobsToVerifyParams{
+		VerifiedAt: sql.NullTime{Time: verifiedBefore, Valid: true},
+		Limit:      int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.mapDBFileBlobsToEntities(dbBlobs), nil
+}
+
+func (r *fileBlobRepository) MarkVerified(ctx context.Context, checksum string, corrupted bool) error {
+	return r.queries.MarkFileBlobVerified(ctx, db.MarkFileBlobVerifiedParams{
+		Checksum:  checksum,
+		Corrupted: corrupted,
+	})
+}
+
+func (r *fileBlobRepository) mapDBFileBlobsToEntities(dbBlobs []db.FileBlobs) []entity.FileBlob {
+	blobs := make([]entity.FileBlob, len(dbBlobs))
+	for i, dbBlob := range dbBlobs {
+		blobs[i] = entity.FileBlob{
+			Checksum:   dbBlob.Checksum,
+			StorageKey: dbBlob.StorageKey,
+			Size:       dbBlob.FileSize,
+			RefCount:   int(dbBlob.RefCount),
+			VerifiedAt: nullTimeToPtr(dbBlob.VerifiedAt),
+			Corrupted:  dbBlob.Corrupted,
+			Stored:     dbBlob.Stored,
+			CreatedAt:  dbBlob.CreatedAt.Time,
+			UpdatedAt:  dbBlob.UpdatedAt.Time,
+		}
+	}
+	return blobs
+}
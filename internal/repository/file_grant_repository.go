@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	db "go-template/db/sqlc"
+	"go-template/internal/entity"
+)
+
+// FileGrantRepository manages the access to files granted by their owners to other users
+type FileGrantRepository interface {
+	// Upsert grants the permission on a file to a user, replacing an earlier grant
+	Upsert(ctx context.Context, fileID, userID int, permission string, grantedBy int) (*entity.FileGrant, error)
+	// Get returns the grant of a user on a file, or sql.ErrNoRows
+	Get(ctx context.Context, fileID, userID int) (*entity.FileGrant, error)
+	GetByFileID(ctx context.Context, fileID int) ([]entity.FileGrant, error)
+	Delete(ctx context.Context, fileID, userID int) error
+}
+
+// CanAccessFile reports whether the user may use the file with the permission: owners, moderators and
+// admins always may, other users need a grant on the file, where write includes read
+func CanAccessFile(ctx context.Context, grantRepo FileGrantRepository, user *entity.User, file *entity.File, permission string) (bool, error) {
+	if file.UploadedBy == user.ID || user.Role == entity.RoleModerator || user.Role == entity.RoleAdmin {
+		return true, nil
+	}
+
+	grant, err := grantRepo.Get(ctx, file.ID, user.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	return grant.Permission == permission || grant.Permission == entity.FilePermissionWrite, nil
+}
+
+type fileGrantRepository struct {
+	db      *sql.DB
+	queries *db.Queries
+}
+
+func NewFileGrantRepository(dbConn *sql.DB) FileGrantRepository {
+	return &fileGrantRepository{
+		db:      dbConn,
+		queries: db.New(dbConn),
+	}
+}
+
+func (r *fileGrantRepository) Upsert(ctx context.Context, fileID, userID int, permission string, grantedBy int) (*entity.FileGrant, error) {
+	grant, err := r.queries.UpsertFileGrant(ctx, db.UpsertFileGrantParams{
+		FileID:     int32(fileID),
+		UserID:     int32(userID),
+		Permission: permission,
+		GrantedBy:  sql.NullInt32{Int32: int32(grantedBy), Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.mapDBFileGrantToEntity(&grant), nil
+}
+
+func (r *fileGrantRepository) Get(ctx context.Context, fileID, userID int) (*entity.FileGrant, error) {
+	grant, err := r.queries.GetFileGrant(ctx, db.GetFileGrantParams{
+		FileID: int32(fileID),
+		UserID: int32(userID),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.mapDBFileGrantToEntity(&grant), nil
+}
+
+func (r *fileGrantRepository) GetByFileID(ctx context.Context, fileID int) ([]entity.FileGrant, error) {
+	dbGrants, err := r.queries.GetFileGrantsByFileID(ctx, int32(fileID))
+	if err != nil {
+		return nil, err
+	}
+
+	grants := make([]entity.FileGrant, len(dbGrants))
+	for i, grant := range dbGrants {
+		grants[i] = *r.mapDBFileGrantToEntity(&grant)
+	}
+
+	return grants, nil
+}
+
+func (r *fileGrantRepository) Delete(ctx context.Context, fileID, userID int) error {
+	return r.queries.DeleteFileGrant(ctx, db.DeleteFileGrantParams{
+		FileID: int32(fileID),
+		UserID: int32(userID),
+	})
+}
+
+func (r *fileGrantRepository) mapDBFileGrantToEntity(dbGrant *db.FileGrants) *entity.FileGrant {
+	return &entity.FileGrant{
+		ID:         int(dbGrant.ID),
+		FileID:     int(dbGrant.FileID),
+		UserID:     int(dbGrant.UserID),
+		Permission: dbGrant.Permission,
+		GrantedBy:  nullInt32ToPtr(dbGrant.GrantedBy),
+		CreatedAt:  dbGrant.CreatedAt.Time,
+		UpdatedAt:  dbGrant.UpdatedAt.Time,
+	}
+}
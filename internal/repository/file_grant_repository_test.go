@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"go-template/internal/entity"
+)
+
+// fakeFileGrantRepository returns the grants it holds, keyed by user, and err for every lookup when set
+type fakeFileGrantRepository struct {
+	FileGrantRepository
+	grants map[int]string
+	err    error
+	calls  int
+}
+
+func (r *fakeFileGrantRepository) Get(ctx context.Context, fileID, userID int) (*entity.FileGrant, error) {
+	r.calls++
+	if r.err != nil {
+		return nil, r.err
+	}
+	permission, ok := r.grants[userID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &entity.FileGrant{FileID: fileID, UserID: userID, Permission: permission}, nil
+}
+
+func TestCanAccessFile(t *testing.T) {
+	file := &entity.File{ID: 10, UploadedBy: 1}
+	grants := &fakeFileGrantRepository{grants: map[int]string{
+		3: entity.FilePermissionRead,
+		4: entity.FilePermissionWrite,
+	}}
+
+	tests := []struct {
+		name       string
+		user       entity.User
+		permission string
+		want       bool
+	}{
+		{"owner reads", entity.User{ID: 1, Role: entity.RoleUser}, entity.FilePermissionRead, true},
+		{"owner writes", entity.User{ID: 1, Role: entity.RoleUser}, entity.FilePermissionWrite, true},
+		{"moderator writes", entity.User{ID: 2, Role: entity.RoleModerator}, entity.FilePermissionWrite, true},
+		{"admin writes", entity.User{ID: 2, Role: entity.RoleAdmin}, entity.FilePermissionWrite, true},
+		{"read grant reads", entity.User{ID: 3, Role: entity.RoleUser}, entity.FilePermissionRead, true},
+		{"read grant writes", entity.User{ID: 3, Role: entity.RoleUser}, entity.FilePermissionWrite, false},
+		{"write grant reads", entity.User{ID: 4, Role: entity.RoleUser}, entity.FilePermissionRead, true},
+		{"write grant writes", entity.User{ID: 4, Role: entity.RoleUser}, entity.FilePermissionWrite, true},
+		{"no grant reads", entity.User{ID: 5, Role: entity.RoleUser}, entity.FilePermissionRead, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CanAccessFile(context.Background(), grants, &tt.user, file, tt.permission)
+			if err != nil {
+				t.Fatalf("CanAccessFile: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CanAccessFile = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanAccessFileGrantError(t *testing.T) {
+	file := &entity.File{ID: 10, UploadedBy: 1}
+	grants := &fakeFileGrantRepository{err: errors.New("database unavailable")}
+
+	if _, err := CanAccessFile(context.Background(), grants, &entity.User{ID: 5, Role: entity.RoleUser}, file, entity.FilePermissionRead); err == nil {
+		t.Error("CanAccessFile hid the failed grant lookup")
+	}
+
+	// Owners and moderating roles need no lookup
+	for _, user := range []entity.User{{ID: 1, Role: entity.RoleUser}, {ID: 2, Role: entity.RoleModerator}} {
+		if ok, err := CanAccessFile(context.Background(), grants, &user, file, entity.FilePermissionWrite); err != nil || !ok {
+			t.Errorf("CanAccessFile(user %d) = %v, %v, want access without a grant lookup", user.ID, ok, err)
+		}
+	}
+	if grants.calls != 1 {
+		t.Errorf("looked up grants %d times, want 1", grants.calls)
+	}
+}
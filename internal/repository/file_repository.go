@@ -3,18 +3,100 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"slices"
+	"strconv"
+	"time"
 
 	db "go-template/db/sqlc"
 	"go-template/internal/entity"
+	"go-template/pkg/pagination"
+
+	"github.com/google/uuid"
 )
 
 type FileRepository interface {
-	Create(ctx context.Context, fileName, originalName, filePath string, fileSize int64, mimeType, description, category string, uploadedBy int) (*entity.File, error)
+	// Create and Delete keep the storage usage of the uploader up to date, as does ReplaceContent. Create
+	// returns ErrQuotaExceeded when the file does not fit in the quota of the uploader.
+	Create(ctx context.Context, fileName, originalName, filePath string, fileSize int64, mimeType, description, category string, uploadedBy int, checksum, scanStatus string, metadata *entity.FileMetadata, folderID *int, quota entity.StorageQuota) (*entity.File, error)
+	// CreateFromUpload records the file assembled from a resumable upload in folderID and marks the
+	// upload complete in one transaction. It returns sql.ErrNoRows when the upload is gone or already completed, e.g. by
+	// a concurrent request, leaving no file behind and the storage usage unchanged.
+	CreateFromUpload(ctx context.Context, uploadID string, fileName, originalName, filePath string, fileSize int64, mimeType, description, category string, uploadedBy int, checksum, scanStatus string, metadata *entity.FileMetadata, folderID *int, quota entity.StorageQuota) (*entity.File, error)
 	GetByID(ctx context.Context, id int) (*entity.File, error)
 	GetByUserID(ctx context.Context, userID int) ([]entity.File, error)
 	Update(ctx context.Context, id int, description, category string) (*entity.File, error)
-	Delete(ctx context.Context, id int) error
+	// Delete returns the deleted file and its versions, whose content is no longer referenced by it, or
+	// sql.ErrNoRows when the file is gone, e.g. deleted by a concurrent request
+	Delete(ctx context.Context, id int) (*entity.File, []entity.FileVersion, error)
+	// Move puts a file into a folder, or at the top level when folderID is nil
+	Move(ctx context.Context, id int, folderID *int) (*entity.File, error)
+	// GetByFolderTree returns the files in a folder and all its subfolders
+	GetByFolderTree(ctx context.Context, folderID int) ([]entity.File, error)
+	// GetSharedWithUser returns the files other users granted the user access to, most recently shared first
+	GetSharedWithUser(ctx context.Context, userID int) ([]entity.File, error)
 	GetAll(ctx context.Context) ([]entity.File, error)
+	GetAllWithPagination(ctx context.Context, paginationParams pagination.PaginationParams, filterParams pagination.FileFilterParams) ([]entity.File, int, error)
+	GetByUserIDWithPagination(ctx context.Context, userID int, paginationParams pagination.PaginationParams, filterParams pagination.FileFilterParams) ([]entity.File, int, error)
+	GetAllWithCursor(ctx context.Context, paginationParams pagination.PaginationParams, filterParams pagination.FileFilterParams) ([]entity.File, pagination.PaginationMeta, error)
+	GetByUserIDWithCursor(ctx context.Context, userID int, paginationParams pagination.PaginationParams, filterParams pagination.FileFilterParams) ([]entity.File, pagination.PaginationMeta, error)
+	// GetPendingScan returns files still waiting for their malware scan, oldest first
+	GetPendingScan(ctx context.Context, createdBefore time.Time, limit int) ([]entity.File, error)
+	// UpdateScanStatus records the verdict on the content stored at filePath; it returns sql.ErrNoRows
+	// when the file has new content meanwhile
+	UpdateScanStatus(ctx context.Context, id int, filePath, status, signature string) (*entity.File, error)
+	// ReplaceContent makes content the new version of a file, moving the current content into its
+	// versions and deleting all but the keepVersions most recent of them, which are returned so their
+	// content can be released. It returns sql.ErrNoRows when the file is gone or already has a newer
+	// version than content.Version-1, and ErrQuotaExceeded when the content does not fit in the quota
+	// of the owner.
+	ReplaceContent(ctx context.Context, id int, content *entity.FileVersion, keepVersions int, quota entity.StorageQuota) (*entity.File, []entity.FileVersion, error)
+}
+
+// fileSortFields are the columns file listings can be sorted by, in both offset and cursor mode
+var fileSortFields = []string{"id", "file_name", "file_size", "created_at"}
+
+// fileFilterSchema lists the file fields available to filter expressions
+var fileFilterSchema = pagination.FilterSchema{
+	"id":            {Column: "id", Type: pagination.FilterInt},
+	"file_name":     {Column: "file_name", Type: pagination.FilterString},
+	"original_name": {Column: "original_name", Type: pagination.FilterString},
+	"mime_type":     {Column: "mime_type", Type: pagination.FilterString},
+	"file_size":     {Column: "file_size", Type: pagination.FilterInt},
+	"description":   {Column: "description", Type: pagination.FilterString, Nullable: true},
+	"category":      {Column: "category", Type: pagination.FilterString, Nullable: true},
+	"uploaded_by":   {Column: "uploaded_by", Type: pagination.FilterInt},
+	"folder_id":     {Column: "folder_id", Type: pagination.FilterInt, Nullable: true},
+	"scan_status":   {Column: "scan_status", Type: pagination.FilterString},
+	"width":         {Column: "(metadata->>'width')::integer", Type: pagination.FilterInt, Nullable: true},
+	"height":        {Column: "(metadata->>'height')::integer", Type: pagination.FilterInt, Nullable: true},
+	"pages":         {Column: "(metadata->>'pages')::integer", Type: pagination.FilterInt, Nullable: true},
+	"frames":        {Column: "(metadata->>'frames')::integer", Type: pagination.FilterInt, Nullable: true},
+	"duration":      {Column: "(metadata->>'duration')::numeric", Type: pagination.FilterFloat, Nullable: true},
+	"created_at":    {Column: "created_at", Type: pagination.FilterTime},
+	"updated_at":    {Column: "updated_at", Type: pagination.FilterTime},
+}
+
+// fileColumns selects the files table in the field order of db.Files, for queries built at runtime
+const fileColumns = "id, file_name, original_name, file_path, file_size, mime_type, description, category, uploaded_by, created_at, updated_at, checksum, scan_status, scan_signature, scanned_at, metadata, version, content_updated_at, folder_id"
+
+// fileListFilters holds the list query arguments shared by the paginated file queries
+type fileListFilters struct {
+	fileName      sql.NullString
+	mimeType      sql.NullString
+	category      sql.NullString
+	uploadedBy    sql.NullInt32
+	createdAfter  sql.NullTime
+	createdBefore sql.NullTime
+	search        sql.NullString
+	folderID      sql.NullInt32
+	recursive     bool
+}
+
+// dynamic reports whether the filters need a query built at runtime; folders are not supported by the
+// static list queries
+func (f fileListFilters) dynamic(filterParams pagination.FileFilterParams) bool {
+	return filterParams.Filter != "" || f.folderID.Valid
 }
 
 type fileRepository struct {
@@ -29,8 +111,58 @@ func NewFileRepository(dbConn *sql.DB) FileRepository {
 	}
 }
 
-func (r *fileRepository) Create(ctx context.Context, fileName, originalName, filePath string, fileSize int64, mimeType, description, category string, uploadedBy int) (*entity.File, error) {
-	createdFile, err := r.queries.CreateFile(ctx, db.CreateFileParams{
+// Create records a file and adds it to the storage usage of its uploader
+func (r *fileRepository) Create(ctx context.Context, fileName, originalName, filePath string, fileSize int64, mimeType, description, category string, uploadedBy int, checksum, scanStatus string, metadata *entity.FileMetadata, folderID *int, quota entity.StorageQuota) (*entity.File, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	queries := r.queries.WithTx(tx)
+	createdFile, err := queries.CreateFile(ctx, db.CreateFileParams{
+		FileName:     fileName,
+		OriginalName: originalName,
+		FilePath:     filePath,
+		FileSize:     fileSize,
+		MimeType:     mimeType,
+		Description:  sql.NullString{String: description, Valid: description != ""},
+		Category:     sql.NullString{String: category, Valid: category != ""},
+		UploadedBy:   int32(uploadedBy),
+		Checksum:     sql.NullString{String: checksum, Valid: checksum != ""},
+		ScanStatus:   scanStatus,
+		Metadata:     fileMetadataToRaw(metadata),
+		FolderID:     ptrToNullInt32(folderID),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := addUsageWithinQuota(ctx, queries, createdFile.UploadedBy, createdFile.FileSize, 1, quota); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return r.mapDBFileToEntity(&createdFile), nil
+}
+
+func (r *fileRepository) CreateFromUpload(ctx context.Context, uploadID string, fileName, originalName, filePath string, fileSize int64, mimeType, description, category string, uploadedBy int, checksum, scanStatus string, metadata *entity.FileMetadata, folderID *int, quota entity.StorageQuota) (*entity.File, error) {
+	id, err := uuid.Parse(uploadID)
+	if err != nil {
+		return nil, sql.ErrNoRows
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	queries := r.queries.WithTx(tx)
+	createdFile, err := queries.CreateFile(ctx, db.CreateFileParams{
 		FileName:     fileName,
 		OriginalName: originalName,
 		FilePath:     filePath,
@@ -39,11 +171,31 @@ func (r *fileRepository) Create(ctx context.Context, fileName, originalName, fil
 		Description:  sql.NullString{String: description, Valid: description != ""},
 		Category:     sql.NullString{String: category, Valid: category != ""},
 		UploadedBy:   int32(uploadedBy),
+		Checksum:     sql.NullString{String: checksum, Valid: checksum != ""},
+		ScanStatus:   scanStatus,
+		Metadata:     fileMetadataToRaw(metadata),
+		FolderID:     ptrToNullInt32(folderID),
 	})
 	if err != nil {
 		return nil, err
 	}
 
+	// Claiming the upload before charging the quota keeps a concurrent completion from counting twice
+	if _, err := queries.CompleteFileUpload(ctx, db.CompleteFileUploadParams{
+		ID:     id,
+		FileID: sql.NullInt32{Int32: createdFile.ID, Valid: true},
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := addUsageWithinQuota(ctx, queries, createdFile.UploadedBy, createdFile.FileSize, 1, quota); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
 	return r.mapDBFileToEntity(&createdFile), nil
 }
 
@@ -83,8 +235,85 @@ func (r *fileRepository) Update(ctx context.Context, id int, description, catego
 	return r.mapDBFileToEntity(&updatedFile), nil
 }
 
-func (r *fileRepository) Delete(ctx context.Context, id int) error {
-	return r.queries.DeleteFile(ctx, int32(id))
+// Delete removes a file with its versions and subtracts them from the storage usage of its uploader
+func (r *fileRepository) Delete(ctx context.Context, id int) (*entity.File, []entity.FileVersion, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	// The lock waits for a concurrent new version or deletion, so the versions read are those deleted
+	queries := r.queries.WithTx(tx)
+	file, err := queries.GetFileForUpdate(ctx, int32(id))
+	if err != nil {
+		return nil, nil, err
+	}
+	versions, err := queries.GetFileVersionsByFileID(ctx, file.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	var versionsSize int64
+	for _, version := range versions {
+		versionsSize += version.FileSize
+	}
+
+	if err := queries.DeleteFile(ctx, file.ID); err != nil {
+		return nil, nil, err
+	}
+	if err := queries.AddUserStorageUsage(ctx, db.AddUserStorageUsageParams{
+		UserID:    file.UploadedBy,
+		UsedBytes: -(file.FileSize + versionsSize),
+		FileCount: -1,
+	}); err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+
+	return r.mapDBFileToEntity(&file), mapDBFileVersionsToEntities(versions), nil
+}
+
+func (r *fileRepository) Move(ctx context.Context, id int, folderID *int) (*entity.File, error) {
+	movedFile, err := r.queries.MoveFile(ctx, db.MoveFileParams{
+		ID:       int32(id),
+		FolderID: ptrToNullInt32(folderID),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.mapDBFileToEntity(&movedFile), nil
+}
+
+func (r *fileRepository) GetByFolderTree(ctx context.Context, folderID int) ([]entity.File, error) {
+	dbFiles, err := r.queries.GetFilesInFolderTree(ctx, int32(folderID))
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]entity.File, len(dbFiles))
+	for i, dbFile := range dbFiles {
+		files[i] = *r.mapDBFileToEntity(&dbFile)
+	}
+
+	return files, nil
+}
+
+func (r *fileRepository) GetSharedWithUser(ctx context.Context, userID int) ([]entity.File, error) {
+	dbFiles, err := r.queries.GetFilesSharedWithUser(ctx, int32(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]entity.File, len(dbFiles))
+	for i, dbFile := range dbFiles {
+		files[i] = *r.mapDBFileToEntity(&dbFile)
+	}
+
+	return files, nil
 }
 
 func (r *fileRepository) GetAll(ctx context.Context) ([]entity.File, error) {
@@ -101,6 +330,480 @@ func (r *fileRepository) GetAll(ctx context.Context) ([]entity.File, error) {
 	return files, nil
 }
 
+func (r *fileRepository) GetAllWithPagination(ctx context.Context, paginationParams pagination.PaginationParams, filterParams pagination.FileFilterParams) ([]entity.File, int, error) {
+	filters := newFileListFilters(paginationParams, filterParams)
+	sortField := pagination.ValidateSortField(paginationParams.Sort, fileSortFields)
+	sortOrder := pagination.SanitizeOrder(paginationParams.Order)
+
+	if filters.dynamic(filterParams) {
+		return r.getAllByFilterExpression(ctx, filterParams.Filter, &filterQuery{}, filters, sortField, sortOrder, paginationParams)
+	}
+
+	// Get paginated files
+	dbFiles, err := r.queries.GetAllFilesWithPaginationAndFilters(ctx, db.GetAllFilesWithPaginationAndFiltersParams{
+		Limit:            int32(paginationParams.Limit),
+		Offset:           int32(paginationParams.CalculateOffset()),
+		FileNameFilter:   filters.fileName,
+		MimeTypeFilter:   filters.mimeType,
+		CategoryFilter:   filters.category,
+		UploadedByFilter: filters.uploadedBy,
+		CreatedAfter:     filters.createdAfter,
+		CreatedBefore:    filters.createdBefore,
+		Search:           filters.search,
+		SortField:        sortField,
+		SortOrder:        sortOrder,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Get total count with same filters
+	totalCount, err := r.queries.CountFilesWithFilters(ctx, db.CountFilesWithFiltersParams{
+		FileNameFilter:   filters.fileName,
+		MimeTypeFilter:   filters.mimeType,
+		CategoryFilter:   filters.category,
+		UploadedByFilter: filters.uploadedBy,
+		CreatedAfter:     filters.createdAfter,
+		CreatedBefore:    filters.createdBefore,
+		Search:           filters.search,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	files := make([]entity.File, len(dbFiles))
+	for i, dbFile := range dbFiles {
+		files[i] = *r.mapDBFileToEntity(&dbFile)
+	}
+
+	return files, int(totalCount), nil
+}
+
+func (r *fileRepository) GetByUserIDWithPagination(ctx context.Context, userID int, paginationParams pagination.PaginationParams, filterParams pagination.FileFilterParams) ([]entity.File, int, error) {
+	filters := newFileListFilters(paginationParams, filterParams)
+	sortField := pagination.ValidateSortField(paginationParams.Sort, fileSortFields)
+	sortOrder := pagination.SanitizeOrder(paginationParams.Order)
+
+	if filters.dynamic(filterParams) {
+		query := &filterQuery{}
+		query.where("uploaded_by = " + query.bind(int32(userID)))
+		return r.getAllByFilterExpression(ctx, filterParams.Filter, query, filters, sortField, sortOrder, paginationParams)
+	}
+
+	// Get paginated files of the user
+	dbFiles, err := r.queries.GetFilesByUserWithPagination(ctx, db.GetFilesByUserWithPaginationParams{
+		Limit:          int32(paginationParams.Limit),
+		Offset:         int32(paginationParams.CalculateOffset()),
+		UploadedBy:     int32(userID),
+		FileNameFilter: filters.fileName,
+		MimeTypeFilter: filters.mimeType,
+		CategoryFilter: filters.category,
+		CreatedAfter:   filters.createdAfter,
+		CreatedBefore:  filters.createdBefore,
+		Search:         filters.search,
+		SortField:      sortField,
+		SortOrder:      sortOrder,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Get total count with same filters
+	totalCount, err := r.queries.CountFilesByUser(ctx, db.CountFilesByUserParams{
+		UploadedBy:     int32(userID),
+		FileNameFilter: filters.fileName,
+		MimeTypeFilter: filters.mimeType,
+		CategoryFilter: filters.category,
+		CreatedAfter:   filters.createdAfter,
+		CreatedBefore:  filters.createdBefore,
+		Search:         filters.search,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	files := make([]entity.File, len(dbFiles))
+	for i, dbFile := range dbFiles {
+		files[i] = *r.mapDBFileToEntity(&dbFile)
+	}
+
+	return files, int(totalCount), nil
+}
+
+func (r *fileRepository) GetAllWithCursor(ctx context.Context, paginationParams pagination.PaginationParams, filterParams pagination.FileFilterParams) ([]entity.File, pagination.PaginationMeta, error) {
+	cursor, err := pagination.DecodeCursor(paginationParams.Cursor)
+	if err != nil {
+		return nil, pagination.PaginationMeta{}, err
+	}
+	keyset, err := newKeysetArgs(cursor, paginationParams, fileSortFields)
+	if err != nil {
+		return nil, pagination.PaginationMeta{}, err
+	}
+
+	filters := newFileListFilters(paginationParams, filterParams)
+
+	// Fetch one extra row to find out whether another page follows
+	var dbFiles []db.Files
+	if filters.dynamic(filterParams) {
+		query := &filterQuery{}
+		filters.apply(query)
+		if err := query.expression(fileFilterSchema, filterParams.Filter); err != nil {
+			return nil, pagination.PaginationMeta{}, err
+		}
+		query.keyset(keyset)
+		listQuery, args := query.list("files", fileColumns, keyset.sortField, keyset.queryOrder, paginationParams.Limit+1, 0)
+		dbFiles, err = r.queryFiles(ctx, listQuery, args...)
+	} else {
+		dbFiles, err = r.queries.ListFilesWithCursor(ctx, db.ListFilesWithCursorParams{
+			Limit:            int32(paginationParams.Limit + 1),
+			FileNameFilter:   filters.fileName,
+			MimeTypeFilter:   filters.mimeType,
+			CategoryFilter:   filters.category,
+			UploadedByFilter: filters.uploadedBy,
+			CreatedAfter:     filters.createdAfter,
+			CreatedBefore:    filters.createdBefore,
+			Search:           filters.search,
+			CursorID:         keyset.id,
+			SortField:        keyset.sortField,
+			SortOrder:        keyset.queryOrder,
+			CursorText:       keyset.text,
+			CursorSize:       keyset.size,
+			CursorTime:       keyset.time,
+		})
+	}
+	if err != nil {
+		return nil, pagination.PaginationMeta{}, err
+	}
+
+	files, meta := r.newFileCursorPage(dbFiles, cursor, keyset, paginationParams.Limit)
+	return files, meta, nil
+}
+
+func (r *fileRepository) GetByUserIDWithCursor(ctx context.Context, userID int, paginationParams pagination.PaginationParams, filterParams pagination.FileFilterParams) ([]entity.File, pagination.PaginationMeta, error) {
+	cursor, err := pagination.DecodeCursor(paginationParams.Cursor)
+	if err != nil {
+		return nil, pagination.PaginationMeta{}, err
+	}
+	keyset, err := newKeysetArgs(cursor, paginationParams, fileSortFields)
+	if err != nil {
+		return nil, pagination.PaginationMeta{}, err
+	}
+
+	filters := newFileListFilters(paginationParams, filterParams)
+
+	// Fetch one extra row to find out whether another page follows
+	var dbFiles []db.Files
+	if filters.dynamic(filterParams) {
+		query := &filterQuery{}
+		query.where("uploaded_by = " + query.bind(int32(userID)))
+		filters.apply(query)
+		if err := query.expression(fileFilterSchema, filterParams.Filter); err != nil {
+			return nil, pagination.PaginationMeta{}, err
+		}
+		query.keyset(keyset)
+		listQuery, args := query.list("files", fileColumns, keyset.sortField, keyset.queryOrder, paginationParams.Limit+1, 0)
+		dbFiles, err = r.queryFiles(ctx, listQuery, args...)
+	} else {
+		dbFiles, err = r.queries.ListFilesByUserWithCursor(ctx, db.ListFilesByUserWithCursorParams{
+			Limit:          int32(paginationParams.Limit + 1),
+			UploadedBy:     int32(userID),
+			FileNameFilter: filters.fileName,
+			MimeTypeFilter: filters.mimeType,
+			CategoryFilter: filters.category,
+			CreatedAfter:   filters.createdAfter,
+			CreatedBefore:  filters.createdBefore,
+			Search:         filters.search,
+			CursorID:       keyset.id,
+			SortField:      keyset.sortField,
+			SortOrder:      keyset.queryOrder,
+			CursorText:     keyset.text,
+			CursorSize:     keyset.size,
+			CursorTime:     keyset.time,
+		})
+	}
+	if err != nil {
+		return nil, pagination.PaginationMeta{}, err
+	}
+
+	files, meta := r.newFileCursorPage(dbFiles, cursor, keyset, paginationParams.Limit)
+	return files, meta, nil
+}
+
+// getAllByFilterExpression lists files matching a filter expression on top of the conditions already in
+// the query and the regular list filters
+func (r *fileRepository) GetPendingScan(ctx context.Context, createdBefore time.Time, limit int) ([]entity.File, error) {
+	dbFiles, err := r.queries.GetFilesPendingScan(ctx, db.GetFilesPendingScanParams{
+		CreatedAt: sql.NullTime{Time: createdBefore, Valid: true},
+		Limit:     int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]entity.File, len(dbFiles))
+	for i, dbFile := range dbFiles {
+		files[i] = *r.mapDBFileToEntity(&dbFile)
+	}
+
+	return files, nil
+}
+
+func (r *fileRepository) UpdateScanStatus(ctx context.Context, id int, filePath, status, signature string) (*entity.File, error) {
+	updatedFile, err := r.queries.UpdateFileScanStatus(ctx, db.UpdateFileScanStatusParams{
+		ID:            int32(id),
+		ScanStatus:    status,
+		ScanSignature: sql.NullString{String: signature, Valid: signature != ""},
+		FilePath:      filePath,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.mapDBFileToEntity(&updatedFile), nil
+}
+
+func (r *fileRepository) ReplaceContent(ctx context.Context, id int, content *entity.FileVersion, keepVersions int, quota entity.StorageQuota) (*entity.File, []entity.FileVersion, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	queries := r.queries.WithTx(tx)
+	current := int32(content.Version - 1)
+	if _, err := queries.ArchiveFileVersion(ctx, db.ArchiveFileVersionParams{
+		ID:      int32(id),
+		Version: current,
+	}); err != nil {
+		return nil, nil, err
+	}
+
+	updatedFile, err := queries.ReplaceFileContent(ctx, db.ReplaceFileContentParams{
+		ID:           int32(id),
+		Version:      current,
+		OriginalName: content.OriginalName,
+		FilePath:     content.FilePath,
+		FileSize:     content.FileSize,
+		MimeType:     content.MimeType,
+		Checksum:     sql.NullString{String: content.Checksum, Valid: content.Checksum != ""},
+		ScanStatus:   content.ScanStatus,
+		Metadata:     fileMetadataToRaw(content.Metadata),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dbPruned, err := queries.DeleteFileVersionsUpTo(ctx, db.DeleteFileVersionsUpToParams{
+		FileID:  int32(id),
+		Version: current - int32(keepVersions),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The previous content is still stored as a version, so only the new content and the pruned
+	// versions change the usage
+	usedBytes := updatedFile.FileSize
+	for _, pruned := range dbPruned {
+		usedBytes -= pruned.FileSize
+	}
+	if err := addUsageWithinQuota(ctx, queries, updatedFile.UploadedBy, usedBytes, 0, quota); err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+
+	return r.mapDBFileToEntity(&updatedFile), mapDBFileVersionsToEntities(dbPruned), nil
+}
+
+func (r *fileRepository) getAllByFilterExpression(ctx context.Context, expression string, query *filterQuery, filters fileListFilters, sortField, sortOrder string, paginationParams pagination.PaginationParams) ([]entity.File, int, error) {
+	filters.apply(query)
+	if err := query.expression(fileFilterSchema, expression); err != nil {
+		return nil, 0, err
+	}
+
+	listQuery, args := query.list("files", fileColumns, sortField, sortOrder, paginationParams.Limit, paginationParams.CalculateOffset())
+	dbFiles, err := r.queryFiles(ctx, listQuery, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var totalCount int
+	countQuery, countArgs := query.count("files")
+	if err := r.db.QueryRowContext(ctx, countQuery, countArgs...).Scan(&totalCount); err != nil {
+		return nil, 0, err
+	}
+
+	files := make([]entity.File, len(dbFiles))
+	for i, dbFile := range dbFiles {
+		files[i] = *r.mapDBFileToEntity(&dbFile)
+	}
+
+	return files, totalCount, nil
+}
+
+// queryFiles runs a query built at runtime that selects fileColumns
+func (r *fileRepository) queryFiles(ctx context.Context, query string, args ...interface{}) ([]db.Files, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	files := []db.Files{}
+	for rows.Next() {
+		var f db.Files
+		if err := rows.Scan(
+			&f.ID,
+			&f.FileName,
+			&f.OriginalName,
+			&f.FilePath,
+			&f.FileSize,
+			&f.MimeType,
+			&f.Description,
+			&f.Category,
+			&f.UploadedBy,
+			&f.CreatedAt,
+			&f.UpdatedAt,
+			&f.Checksum,
+			&f.ScanStatus,
+			&f.ScanSignature,
+			&f.ScannedAt,
+			&f.Metadata,
+			&f.Version,
+			&f.ContentUpdatedAt,
+			&f.FolderID,
+		); err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// newFileCursorPage trims the look-ahead row of a keyset query, restores the listing order and builds the page cursors
+func (r *fileRepository) newFileCursorPage(dbFiles []db.Files, cursor *pagination.Cursor, keyset keysetArgs, limit int) ([]entity.File, pagination.PaginationMeta) {
+	hasMore := len(dbFiles) > limit
+	if hasMore {
+		dbFiles = dbFiles[:limit]
+	}
+	if cursor != nil && cursor.Before {
+		slices.Reverse(dbFiles)
+	}
+
+	files := make([]entity.File, len(dbFiles))
+	for i, dbFile := range dbFiles {
+		files[i] = *r.mapDBFileToEntity(&dbFile)
+	}
+
+	var first, last *pagination.Cursor
+	if len(files) > 0 {
+		first = fileCursor(&files[0], keyset)
+		last = fileCursor(&files[len(files)-1], keyset)
+	}
+
+	return files, pagination.NewCursorPaginationMeta(limit, cursor, hasMore, first, last)
+}
+
+// fileCursor builds the cursor pointing at a file in a keyset listing
+func fileCursor(file *entity.File, keyset keysetArgs) *pagination.Cursor {
+	cursor := &pagination.Cursor{
+		Sort:  keyset.sortField,
+		Order: keyset.sortOrder,
+		ID:    file.ID,
+	}
+
+	switch keyset.sortField {
+	case "file_name":
+		cursor.Value = file.FileName
+	case "file_size":
+		cursor.Value = strconv.FormatInt(file.FileSize, 10)
+	case "created_at":
+		cursor.Value = formatCursorTime(file.CreatedAt)
+	}
+
+	return cursor
+}
+
+// newFileListFilters converts the request filters into nullable query arguments
+func newFileListFilters(paginationParams pagination.PaginationParams, filterParams pagination.FileFilterParams) fileListFilters {
+	var filters fileListFilters
+
+	if filterParams.FileName != "" {
+		filters.fileName = sql.NullString{String: filterParams.FileName, Valid: true}
+	}
+	if filterParams.MimeType != "" {
+		filters.mimeType = sql.NullString{String: filterParams.MimeType, Valid: true}
+	}
+	if filterParams.Category != "" {
+		filters.category = sql.NullString{String: filterParams.Category, Valid: true}
+	}
+	if filterParams.UploadedBy != nil {
+		filters.uploadedBy = sql.NullInt32{Int32: int32(*filterParams.UploadedBy), Valid: true}
+	}
+	if filterParams.CreatedAfter != "" {
+		if parsedTime, err := time.Parse(time.RFC3339, filterParams.CreatedAfter); err == nil {
+			filters.createdAfter = sql.NullTime{Time: parsedTime, Valid: true}
+		}
+	}
+	if filterParams.CreatedBefore != "" {
+		if parsedTime, err := time.Parse(time.RFC3339, filterParams.CreatedBefore); err == nil {
+			filters.createdBefore = sql.NullTime{Time: parsedTime, Valid: true}
+		}
+	}
+	if paginationParams.Search != "" {
+		filters.search = sql.NullString{String: paginationParams.Search, Valid: true}
+	}
+	if filterParams.FolderID != nil {
+		filters.folderID = sql.NullInt32{Int32: int32(*filterParams.FolderID), Valid: true}
+		filters.recursive = filterParams.Recursive
+	}
+
+	return filters
+}
+
+// apply adds the list filters to a query built at runtime, mirroring the static list queries
+func (f fileListFilters) apply(query *filterQuery) {
+	if f.fileName.Valid {
+		query.where("file_name ILIKE '%' || " + query.bind(f.fileName.String) + "::text || '%'")
+	}
+	if f.mimeType.Valid {
+		query.where("mime_type = " + query.bind(f.mimeType.String))
+	}
+	if f.category.Valid {
+		query.where("category = " + query.bind(f.category.String))
+	}
+	if f.uploadedBy.Valid {
+		query.where("uploaded_by = " + query.bind(f.uploadedBy.Int32))
+	}
+	if f.createdAfter.Valid {
+		query.where("created_at >= " + query.bind(f.createdAfter.Time))
+	}
+	if f.createdBefore.Valid {
+		query.where("created_at <= " + query.bind(f.createdBefore.Time))
+	}
+	if f.search.Valid {
+		search := query.bind(f.search.String)
+		query.where("(file_name ILIKE '%' || " + search + "::text || '%' OR original_name ILIKE '%' || " + search +
+			"::text || '%' OR description ILIKE '%' || " + search + "::text || '%')")
+	}
+	if f.folderID.Valid {
+		folder := query.bind(f.folderID.Int32)
+		if f.recursive {
+			query.where("folder_id IN (SELECT d.id FROM folders d JOIN folders f ON f.id = " + folder +
+				" WHERE d.id = f.id OR d.path LIKE f.path || f.id || '/%')")
+		} else {
+			query.where("folder_id = " + folder)
+		}
+	}
+}
+
 func (r *fileRepository) mapDBFileToEntity(dbFile *db.Files) *entity.File {
 	return &entity.File{
 		ID:           int(dbFile.ID),
@@ -112,7 +815,38 @@ func (r *fileRepository) mapDBFileToEntity(dbFile *db.Files) *entity.File {
 		Description:  dbFile.Description.String,
 		Category:     dbFile.Category.String,
 		UploadedBy:   int(dbFile.UploadedBy),
+		FolderID:     nullInt32ToPtr(dbFile.FolderID),
+		Checksum:     dbFile.Checksum.String,
+		ScanStatus:    dbFile.ScanStatus,
+		ScanSignature: dbFile.ScanSignature.String,
+		ScannedAt:     nullTimeToPtr(dbFile.ScannedAt),
+		Metadata:     rawToFileMetadata(dbFile.Metadata),
+		Version:      int(dbFile.Version),
+		ContentUpdatedAt: dbFile.ContentUpdatedAt.Time,
 		CreatedAt:    dbFile.CreatedAt.Time,
 		UpdatedAt:    dbFile.UpdatedAt.Time,
 	}
-}
\ No newline at end of file
+}
+
+// fileMetadataToRaw encodes file metadata for the JSONB column, NULL when there is none
+func fileMetadataToRaw(metadata *entity.FileMetadata) json.RawMessage {
+	if metadata == nil {
+		return nil
+	}
+	raw, err := json.Marshal(metadata)
+	if err != nil {
+		return nil
+	}
+	return raw
+}
+
+func rawToFileMetadata(raw json.RawMessage) *entity.FileMetadata {
+	if len(raw) == 0 {
+		return nil
+	}
+	var metadata entity.FileMetadata
+	if err := json.Unmarshal(raw, &metadata); err != nil {
+		return nil
+	}
+	return &metadata
+}
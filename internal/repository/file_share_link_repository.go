@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	db "go-template/db/sqlc"
+	"go-template/internal/entity"
+)
+
+// FileShareLinkRepository manages the links that share files with anyone holding their token
+type FileShareLinkRepository interface {
+	Create(ctx context.Context, fileID, createdBy int, token, passwordHash string, expiresAt *time.Time, maxDownloads *int) (*entity.FileShareLink, error)
+	GetByID(ctx context.Context, id int) (*entity.FileShareLink, error)
+	GetByToken(ctx context.Context, token string) (*entity.FileShareLink, error)
+	// GetByFileID lists the links of a file, revoked and expired ones included, newest first
+	GetByFileID(ctx context.Context, fileID int) ([]entity.FileShareLink, error)
+	// Use counts a download through the link; it returns sql.ErrNoRows when the link is revoked,
+	// expired or has no downloads left
+	Use(ctx context.Context, id int) (*entity.FileShareLink, error)
+	Revoke(ctx context.Context, id int) error
+}
+
+type fileShareLinkRepository struct {
+	db      *sql.DB
+	queries *db.Queries
+}
+
+func NewFileShareLinkRepository(dbConn *sql.DB) FileShareLinkRepository {
+	return &fileShareLinkRepository{
+		db:      dbConn,
+		queries: db.New(dbConn),
+	}
+}
+
+func (r *fileShareLinkRepository) Create(ctx context.Context, fileID, createdBy int, token, passwordHash string, expiresAt *time.Time, maxDownloads *int) (*entity.FileShareLink, error) {
+	link, err := r.queries.CreateFileShareLink(ctx, db.CreateFileShareLinkParams{
+		FileID:       int32(fileID),
+		CreatedBy:    int32(createdBy),
+		Token:        token,
+		PasswordHash: sql.NullString{String: passwordHash, Valid: passwordHash != ""},
+		ExpiresAt:    ptrToNullTime(expiresAt),
+		MaxDownloads: ptrToNullInt32(maxDownloads),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.mapDBFileShareLinkToEntity(&link), nil
+}
+
+func (r *fileShareLinkRepository) GetByID(ctx context.Context, id int) (*entity.FileShareLink, error) {
+	link, err := r.queries.GetFileShareLink(ctx, int32(id))
+	if err != nil {
+		return nil, err
+	}
+
+	return r.mapDBFileShareLinkToEntity(&link), nil
+}
+
+func (r *fileShareLinkRepository) GetByToken(ctx context.Context, token string) (*entity.FileShareLink, error) {
+	link, err := r.queries.GetFileShareLinkByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.mapDBFileShareLinkToEntity(&link), nil
+}
+
+func (r *fileShareLinkRepository) GetByFileID(ctx context.Context, fileID int) ([]entity.FileShareLink, error) {
+	dbLinks, err := r.queries.GetFileShareLinksByFileID(ctx, int32(fileID))
+	if err != nil {
+		return nil, err
+	}
+
+	links := make([]entity.FileShareLink, len(dbLinks))
+	for i, link := range dbLinks {
+		links[i] = *r.mapDBFileShareLinkToEntity(&link)
+	}
+
+	return links, nil
+}
+
+func (r *fileShareLinkRepository) Use(ctx context.Context, id int) (*entity.FileShareLink, error) {
+	link, err := r.queries.UseFileShareLink(ctx, int32(id))
+	if err != nil {
+		return nil, err
+	}
+
+	return r.mapDBFileShareLinkToEntity(&link), nil
+}
+
+func (r *fileShareLinkRepository) Revoke(ctx context.Context, id int) error {
+	return r.queries.RevokeFileShareLink(ctx, int32(id))
+}
+
+func (r *fileShareLinkRepository) mapDBFileShareLinkToEntity(dbLink *db.FileShareLinks) *entity.FileShareLink {
+	return &entity.FileShareLink{
+		ID:            int(dbLink.ID),
+		FileID:        int(dbLink.FileID),
+		CreatedBy:     int(dbLink.CreatedBy),
+		Token:         dbLink.Token,
+		PasswordHash:  dbLink.PasswordHash.String,
+		ExpiresAt:     nullTimeToPtr(dbLink.ExpiresAt),
+		MaxDownloads:  nullInt32ToPtr(dbLink.MaxDownloads),
+		DownloadCount: int(dbLink.DownloadCount),
+		RevokedAt:     nullTimeToPtr(dbLink.RevokedAt),
+		CreatedAt:     dbLink.CreatedAt.Time,
+		UpdatedAt:     dbLink.UpdatedAt.Time,
+	}
+}
@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	db "go-template/db/sqlc"
+	"go-template/internal/entity"
+
+	"github.com/google/uuid"
+)
+
+type FileUploadRepository interface {
+	Create(ctx context.Context, upload *entity.FileUpload) (*entity.FileUpload, error)
+	GetByID(ctx context.Context, id string) (*entity.FileUpload, error)
+	AppendChunk(ctx context.Context, chunk *entity.FileUploadChunk, hashState []byte, expiresAt time.Time) (*entity.FileUpload, error)
+	Delete(ctx context.Context, id string) error
+	GetExpired(ctx context.Context, before time.Time) ([]entity.FileUpload, error)
+	GetChunks(ctx context.Context, uploadID string) ([]entity.FileUploadChunk, error)
+	DeleteChunks(ctx context.Context, uploadID string) error
+	GetChunkKeysByUserID(ctx context.Context, userID int) ([]string, error)
+}
+
+type fileUploadRepository struct {
+	db      *sql.DB
+	queries *db.Queries
+}
+
+func NewFileUploadRepository(dbConn *sql.DB) FileUploadRepository {
+	return &fileUploadRepository{
+		db:      dbConn,
+		queries: db.New(dbConn),
+	}
+}
+
+func (r *fileUploadRepository) Create(ctx context.Context, upload *entity.FileUpload) (*entity.FileUpload, error) {
+	id, err := uuid.Parse(upload.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	createdUpload, err := r.queries.CreateFileUpload(ctx, db.CreateFileUploadParams{
+		ID:           id,
+		UserID:       int32(upload.UserID),
+		UploadLength: upload.Length,
+		Metadata:     sql.NullString{String: upload.Metadata, Valid: upload.Metadata != ""},
+		OriginalName: upload.OriginalName,
+		MimeType:     upload.MimeType,
+		Description:  sql.NullString{String: upload.Description, Valid: upload.Description != ""},
+		Category:     sql.NullString{String: upload.Category, Valid: upload.Category != ""},
+		ExpiresAt:    upload.ExpiresAt,
+		FolderID:     ptrToNullInt32(upload.FolderID),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.mapDBFileUploadToEntity(&createdUpload), nil
+}
+
+// GetByID returns sql.ErrNoRows for unknown and malformed IDs alike
+func (r *fileUploadRepository) GetByID(ctx context.Context, id string) (*entity.FileUpload, error) {
+	uploadID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, sql.ErrNoRows
+	}
+
+	upload, err := r.queries.GetFileUpload(ctx, uploadID)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.mapDBFileUploadToEntity(&upload), nil
+}
+
+// AppendChunk records a stored chunk and advances the upload offset and hash state past it in one
+// transaction. It returns sql.ErrNoRows when the offset has moved since the chunk was started, e.g. by
+// a concurrent request.
+func (r *fileUploadRepository) AppendChunk(ctx context.Context, chunk *entity.FileUploadChunk, hashState []byte, expiresAt time.Time) (*entity.FileUpload, error) {
+	uploadID, err := uuid.Parse(chunk.UploadID)
+	if err != nil {
+		return nil, sql.ErrNoRows
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	queries := r.queries.WithTx(tx)
+	upload, err := queries.AdvanceFileUploadOffset(ctx, db.AdvanceFileUploadOffsetParams{
+		NewOffset:      chunk.Offset + chunk.Size,
+		HashState:      hashState,
+		ExpiresAt:      expiresAt,
+		ID:             uploadID,
+		ExpectedOffset: chunk.Offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := queries.CreateFileUploadChunk(ctx, db.CreateFileUploadChunkParams{
+		UploadID:    uploadID,
+		ChunkOffset: chunk.Offset,
+		ChunkSize:   chunk.Size,
+		StorageKey:  chunk.StorageKey,
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return r.mapDBFileUploadToEntity(&upload), nil
+}
+
+func (r *fileUploadRepository) Delete(ctx context.Context, id string) error {
+	uploadID, err := uuid.Parse(id)
+	if err != nil {
+		return nil
+	}
+	return r.queries.DeleteFileUpload(ctx, uploadID)
+}
+
+func (r *fileUploadRepository) GetExpired(ctx context.Context, before time.Time) ([]entity.FileUpload, error) {
+	dbUploads, err := r.queries.GetExpiredFileUploads(ctx, before)
+	if err != nil {
+		return nil, err
+	}
+
+	uploads := make([]entity.FileUpload, len(dbUploads))
+	for i, dbUpload := range dbUploads {
+		uploads[i] = *r.mapDBFileUploadToEntity(&dbUpload)
+	}
+
+	return uploads, nil
+}
+
+func (r *fileUploadRepository) GetChunks(ctx context.Context, uploadID string) ([]entity.FileUploadChunk, error) {
+	id, err := uuid.Parse(uploadID)
+	if err != nil {
+		return []entity.FileUploadChunk{}, nil
+	}
+
+	dbChunks, err := r.queries.GetFileUploadChunks(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	chunks := make([]entity.FileUploadChunk, len(dbChunks))
+	for i, dbChunk := range dbChunks {
+		chunks[i] = entity.FileUploadChunk{
+			ID:         int(dbChunk.ID),
+			UploadID:   dbChunk.UploadID.String(),
+			Offset:     dbChunk.ChunkOffset,
+			Size:       dbChunk.ChunkSize,
+			StorageKey: dbChunk.StorageKey,
+		}
+	}
+
+	return chunks, nil
+}
+
+func (r *fileUploadRepository) DeleteChunks(ctx context.Context, uploadID string) error {
+	id, err := uuid.Parse(uploadID)
+	if err != nil {
+		return nil
+	}
+	return r.queries.DeleteFileUploadChunks(ctx, id)
+}
+
+func (r *fileUploadRepository) GetChunkKeysByUserID(ctx context.Context, userID int) ([]string, error) {
+	return r.queries.GetFileUploadChunkKeysByUser(ctx, int32(userID))
+}
+
+func (r *fileUploadRepository) mapDBFileUploadToEntity(dbUpload *db.FileUploads) *entity.FileUpload {
+	return &entity.FileUpload{
+		ID:           dbUpload.ID.String(),
+		UserID:       int(dbUpload.UserID),
+		Length:       dbUpload.UploadLength,
+		Offset:       dbUpload.UploadOffset,
+		Metadata:     dbUpload.Metadata.String,
+		OriginalName: dbUpload.OriginalName,
+		MimeType:     dbUpload.MimeType,
+		Description:  dbUpload.Description.String,
+		Category:     dbUpload.Category.String,
+		FolderID:     nullInt32ToPtr(dbUpload.FolderID),
+		FileID:       nullInt32ToPtr(dbUpload.FileID),
+		HashState:    dbUpload.HashState,
+		ExpiresAt:    dbUpload.ExpiresAt,
+		CreatedAt:    dbUpload.CreatedAt.Time,
+		UpdatedAt:    dbUpload.UpdatedAt.Time,
+	}
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	db "go-template/db/sqlc"
+	"go-template/internal/entity"
+)
+
+type FileVariantRepository interface {
+	Save(ctx context.Context, variant *entity.FileVariant) (*entity.FileVariant, error)
+	GetByName(ctx context.Context, fileID int, name string) (*entity.FileVariant, error)
+	GetByFileID(ctx context.Context, fileID int) ([]entity.FileVariant, error)
+	GetKeysByUserID(ctx context.Context, userID int) ([]string, error)
+	DeleteByFileID(ctx context.Context, fileID int) error
+}
+
+type fileVariantRepository struct {
+	db      *sql.DB
+	queries *db.Queries
+}
+
+func NewFileVariantRepository(dbConn *sql.DB) FileVariantRepository {
+	return &fileVariantRepository{
+		db:      dbConn,
+		queries: db.New(dbConn),
+	}
+}
+
+// Save records a rendered variant, replacing an earlier rendering of the same name
+func (r *fileVariantRepository) Save(ctx context.Context, variant *entity.FileVariant) (*entity.FileVariant, error) {
+	dbVariant, err := r.queries.UpsertFileVariant(ctx, db.UpsertFileVariantParams{
+		FileID:     int32(variant.FileID),
+		Name:       variant.Name,
+		StorageKey: variant.StorageKey,
+		MimeType:   variant.MimeType,
+		Width:      int32(variant.Width),
+		Height:     int32(variant.Height),
+		FileSize:   variant.FileSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.mapDBFileVariantToEntity(&dbVariant), nil
+}
+
+func (r *fileVariantRepository) GetByName(ctx context.Context, fileID int, name string) (*entity.FileVariant, error) {
+	dbVariant, err := r.queries.GetFileVariant(ctx, db.GetFileVariantParams{
+		FileID: int32(fileID),
+		Name:   name,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.mapDBFileVariantToEntity(&dbVariant), nil
+}
+
+func (r *fileVariantRepository) GetByFileID(ctx context.Context, fileID int) ([]entity.FileVariant, error) {
+	dbVariants, err := r.queries.GetFileVariantsByFileID(ctx, int32(fileID))
+	if err != nil {
+		return nil, err
+	}
+
+	variants := make([]entity.FileVariant, len(dbVariants))
+	for i, dbVariant := range dbVariants {
+		variants[i] = *r.mapDBFileVariantToEntity(&dbVariant)
+	}
+	return variants, nil
+}
+
+// GetKeysByUserID returns the storage keys of the variants of all files uploaded by the user
+func (r *fileVariantRepository) GetKeysByUserID(ctx context.Context, userID int) ([]string, error) {
+	return r.queries.GetFileVariantKeysByUser(ctx, int32(userID))
+}
+
+func (r *fileVariantRepository) DeleteByFileID(ctx context.Context, fileID int) error {
+	return r.queries.DeleteFileVariantsByFileID(ctx, int32(fileID))
+}
+
+func (r *fileVariantRepository) mapDBFileVariantToEntity(dbVariant *db.FileVariants) *entity.FileVariant {
+	return &entity.FileVariant{
+		ID:         int(dbVariant.ID),
+		FileID:     int(dbVariant.FileID),
+		Name:       dbVariant.Name,
+		StorageKey: dbVariant.StorageKey,
+		MimeType:   dbVariant.MimeType,
+		Width:      int(dbVariant.Width),
+		Height:     int(dbVariant.Height),
+		FileSize:   dbVariant.FileSize,
+		CreatedAt:  dbVariant.CreatedAt.Time,
+	}
+}
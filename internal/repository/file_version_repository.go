@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	db "go-template/db/sqlc"
+	"go-template/internal/entity"
+)
+
+// FileVersionRepository reads the earlier versions of files; new versions are recorded by
+// FileRepository.ReplaceContent
+type FileVersionRepository interface {
+	GetByVersion(ctx context.Context, fileID, version int) (*entity.FileVersion, error)
+	// GetByFileID returns the earlier versions of a file, newest first
+	GetByFileID(ctx context.Context, fileID int) ([]entity.FileVersion, error)
+	GetByUserID(ctx context.Context, userID int) ([]entity.FileVersion, error)
+	// UpdateScanStatus records the verdict on the content stored at filePath for the versions of a file
+	// still waiting for it
+	UpdateScanStatus(ctx context.Context, fileID int, filePath, status string) error
+}
+
+type fileVersionRepository struct {
+	db      *sql.DB
+	queries *db.Queries
+}
+
+func NewFileVersionRepository(dbConn *sql.DB) FileVersionRepository {
+	return &fileVersionRepository{
+		db:      dbConn,
+		queries: db.New(dbConn),
+	}
+}
+
+func (r *fileVersionRepository) GetByVersion(ctx context.Context, fileID, version int) (*entity.FileVersion, error) {
+	dbVersion, err := r.queries.GetFileVersion(ctx, db.GetFileVersionParams{
+		FileID:  int32(fileID),
+		Version: int32(version),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mapDBFileVersionToEntity(&dbVersion), nil
+}
+
+func (r *fileVersionRepository) GetByFileID(ctx context.Context, fileID int) ([]entity.FileVersion, error) {
+	dbVersions, err := r.queries.GetFileVersionsByFileID(ctx, int32(fileID))
+	if err != nil {
+		return nil, err
+	}
+	return mapDBFileVersionsToEntities(dbVersions), nil
+}
+
+// GetByUserID returns the earlier versions of all files uploaded by the user
+func (r *fileVersionRepository) GetByUserID(ctx context.Context, userID int) ([]entity.FileVersion, error) {
+	dbVersions, err := r.queries.GetFileVersionsByUser(ctx, int32(userID))
+	if err != nil {
+		return nil, err
+	}
+	return mapDBFileVersionsToEntities(dbVersions), nil
+}
+
+func (r *fileVersionRepository) UpdateScanStatus(ctx context.Context, fileID int, filePath, status string) error {
+	return r.queries.UpdateFileVersionScanStatus(ctx, db.UpdateFileVersionScanStatusParams{
+		FileID:     int32(fileID),
+		FilePath:   filePath,
+		ScanStatus: status,
+	})
+}
+
+func mapDBFileVersionsToEntities(dbVersions []db.FileVersions) []entity.FileVersion {
+	versions := make([]entity.FileVersion, len(dbVersions))
+	for i, dbVersion := range dbVersions {
+		versions[i] = *mapDBFileVersionToEntity(&dbVersion)
+	}
+	return versions
+}
+
+func mapDBFileVersionToEntity(dbVersion *db.FileVersions) *entity.FileVersion {
+	return &entity.FileVersion{
+		ID:           int(dbVersion.ID),
+		FileID:       int(dbVersion.FileID),
+		Version:      int(dbVersion.Version),
+		OriginalName: dbVersion.OriginalName,
+		FilePath:     dbVersion.FilePath,
+		FileSize:     dbVersion.FileSize,
+		MimeType:     dbVersion.MimeType,
+		Checksum:     dbVersion.Checksum.String,
+		ScanStatus:   dbVersion.ScanStatus,
+		Metadata:     rawToFileMetadata(dbVersion.Metadata),
+		CreatedAt:    dbVersion.CreatedAt.Time,
+	}
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go-template/pkg/pagination"
+)
+
+// filterQuery builds the WHERE clause of list queries that carry a filter expression. These cannot be
+// expressed as static sqlc queries, so they are assembled at runtime: column names only ever come from
+// whitelists and every value is bound as a positional argument.
+type filterQuery struct {
+	conditions []string
+	args       []interface{}
+}
+
+// bind records an argument and returns its placeholder
+func (q *filterQuery) bind(value interface{}) string {
+	q.args = append(q.args, value)
+	return "$" + strconv.Itoa(len(q.args))
+}
+
+func (q *filterQuery) where(condition string) {
+	q.conditions = append(q.conditions, condition)
+}
+
+// expression parses a filter expression and adds it as a condition, resolving fields through the schema
+func (q *filterQuery) expression(schema pagination.FilterSchema, input string) error {
+	expr, err := pagination.ParseFilter(input)
+	if err != nil || expr == nil {
+		return err
+	}
+
+	condition, err := schema.Build(expr, q.bind)
+	if err != nil {
+		return err
+	}
+	q.where(condition)
+	return nil
+}
+
+// keyset restricts the query to the rows after the cursor in the query order
+func (q *filterQuery) keyset(keyset keysetArgs) {
+	if !keyset.id.Valid {
+		return
+	}
+
+	operator := ">"
+	if keyset.queryOrder == "DESC" {
+		operator = "<"
+	}
+
+	var value interface{}
+	switch {
+	case keyset.text.Valid:
+		value = keyset.text.String
+	case keyset.time.Valid:
+		value = keyset.time.Time
+	case keyset.size.Valid:
+		value = keyset.size.Int64
+	default:
+		q.where(fmt.Sprintf("id %s %s", operator, q.bind(keyset.id.Int32)))
+		return
+	}
+	q.where(fmt.Sprintf("(%s, id) %s (%s, %s)", keyset.sortField, operator, q.bind(value), q.bind(keyset.id.Int32)))
+}
+
+func (q *filterQuery) whereClause() string {
+	if len(q.conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(q.conditions, " AND ")
+}
+
+// list renders the query for one page. sortField must be whitelisted; null sort keys come first in
+// ascending order, matching the static list queries.
+func (q *filterQuery) list(table, columns, sortField, sortOrder string, limit, offset int) (string, []interface{}) {
+	nulls := "NULLS FIRST"
+	if sortOrder == "DESC" {
+		nulls = "NULLS LAST"
+	}
+
+	args := append(slices.Clone(q.args), limit, offset)
+	query := fmt.Sprintf("SELECT %s FROM %s%s ORDER BY %s %s %s, id %s LIMIT $%d OFFSET $%d",
+		columns, table, q.whereClause(), sortField, sortOrder, nulls, sortOrder, len(args)-1, len(args))
+	return query, args
+}
+
+// count renders the query counting every row matching the conditions
+func (q *filterQuery) count(table string) (string, []interface{}) {
+	return "SELECT COUNT(*) FROM " + table + q.whereClause(), q.args
+}
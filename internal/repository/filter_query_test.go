@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"go-template/pkg/pagination"
+)
+
+func TestFilterQueryList(t *testing.T) {
+	cursorTime := time.Date(2025, 3, 4, 5, 6, 7, 800000000, time.UTC)
+
+	tests := []struct {
+		name   string
+		build  func(q *filterQuery) error
+		order  string
+		sort   string
+		sql    string
+		args   []interface{}
+		counts string
+	}{
+		{
+			name:   "no conditions",
+			build:  func(q *filterQuery) error { return nil },
+			order:  "ASC",
+			sort:   "id",
+			sql:    "SELECT id FROM files ORDER BY id ASC NULLS FIRST, id ASC LIMIT $1 OFFSET $2",
+			args:   []interface{}{20, 40},
+			counts: "SELECT COUNT(*) FROM files",
+		},
+		{
+			name:   "empty expression",
+			build:  func(q *filterQuery) error { return q.expression(fileFilterSchema, "  ") },
+			order:  "DESC",
+			sort:   "created_at",
+			sql:    "SELECT id FROM files ORDER BY created_at DESC NULLS LAST, id DESC LIMIT $1 OFFSET $2",
+			args:   []interface{}{20, 40},
+			counts: "SELECT COUNT(*) FROM files",
+		},
+		{
+			name: "static conditions before the expression",
+			build: func(q *filterQuery) error {
+				q.where("uploaded_by = " + q.bind(int32(5)))
+				return q.expression(fileFilterSchema, "mime_type eq 'image/png' or file_size gt 100 and category eq null")
+			},
+			order:  "ASC",
+			sort:   "file_name",
+			sql:    "SELECT id FROM files WHERE uploaded_by = $1 AND (mime_type = $2 OR (file_size > $3 AND category IS NULL)) ORDER BY file_name ASC NULLS FIRST, id ASC LIMIT $4 OFFSET $5",
+			args:   []interface{}{int32(5), "image/png", int64(100), 20, 40},
+			counts: "SELECT COUNT(*) FROM files WHERE uploaded_by = $1 AND (mime_type = $2 OR (file_size > $3 AND category IS NULL))",
+		},
+		{
+			name: "text keyset after the expression",
+			build: func(q *filterQuery) error {
+				if err := q.expression(fileFilterSchema, "original_name contains '100%'"); err != nil {
+					return err
+				}
+				q.keyset(keysetArgs{sortField: "file_name", queryOrder: "ASC", id: sql.NullInt32{Int32: 9, Valid: true}, text: sql.NullString{String: "b.png", Valid: true}})
+				return nil
+			},
+			order:  "ASC",
+			sort:   "file_name",
+			sql:    "SELECT id FROM files WHERE original_name ILIKE $1 AND (file_name, id) > ($2, $3) ORDER BY file_name ASC NULLS FIRST, id ASC LIMIT $4 OFFSET $5",
+			args:   []interface{}{`%100\%%`, "b.png", int32(9), 20, 40},
+			counts: "SELECT COUNT(*) FROM files WHERE original_name ILIKE $1 AND (file_name, id) > ($2, $3)",
+		},
+		{
+			name: "time keyset walking backwards",
+			build: func(q *filterQuery) error {
+				q.keyset(keysetArgs{sortField: "created_at", sortOrder: "ASC", queryOrder: "DESC", id: sql.NullInt32{Int32: 9, Valid: true}, time: sql.NullTime{Time: cursorTime, Valid: true}})
+				return nil
+			},
+			order:  "DESC",
+			sort:   "created_at",
+			sql:    "SELECT id FROM files WHERE (created_at, id) < ($1, $2) ORDER BY created_at DESC NULLS LAST, id DESC LIMIT $3 OFFSET $4",
+			args:   []interface{}{cursorTime, int32(9), 20, 40},
+			counts: "SELECT COUNT(*) FROM files WHERE (created_at, id) < ($1, $2)",
+		},
+		{
+			name: "size keyset",
+			build: func(q *filterQuery) error {
+				q.keyset(keysetArgs{sortField: "file_size", queryOrder: "DESC", id: sql.NullInt32{Int32: 9, Valid: true}, size: sql.NullInt64{Int64: 2048, Valid: true}})
+				return nil
+			},
+			order:  "DESC",
+			sort:   "file_size",
+			sql:    "SELECT id FROM files WHERE (file_size, id) < ($1, $2) ORDER BY file_size DESC NULLS LAST, id DESC LIMIT $3 OFFSET $4",
+			args:   []interface{}{int64(2048), int32(9), 20, 40},
+			counts: "SELECT COUNT(*) FROM files WHERE (file_size, id) < ($1, $2)",
+		},
+		{
+			name: "id keyset",
+			build: func(q *filterQuery) error {
+				q.keyset(keysetArgs{sortField: "id", queryOrder: "ASC", id: sql.NullInt32{Int32: 9, Valid: true}})
+				return nil
+			},
+			order:  "ASC",
+			sort:   "id",
+			sql:    "SELECT id FROM files WHERE id > $1 ORDER BY id ASC NULLS FIRST, id ASC LIMIT $2 OFFSET $3",
+			args:   []interface{}{int32(9), 20, 40},
+			counts: "SELECT COUNT(*) FROM files WHERE id > $1",
+		},
+		{
+			name: "first page has no keyset",
+			build: func(q *filterQuery) error {
+				q.keyset(keysetArgs{sortField: "file_name", sortOrder: "ASC", queryOrder: "ASC"})
+				return nil
+			},
+			order:  "ASC",
+			sort:   "file_name",
+			sql:    "SELECT id FROM files ORDER BY file_name ASC NULLS FIRST, id ASC LIMIT $1 OFFSET $2",
+			args:   []interface{}{20, 40},
+			counts: "SELECT COUNT(*) FROM files",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &filterQuery{}
+			if err := tt.build(q); err != nil {
+				t.Fatalf("build: %v", err)
+			}
+
+			query, args := q.list("files", "id", tt.sort, tt.order, 20, 40)
+			if query != tt.sql {
+				t.Errorf("list SQL = %q, want %q", query, tt.sql)
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("list args = %#v, want %#v", args, tt.args)
+			}
+
+			// The page arguments are not left behind for the count
+			query, args = q.count("files")
+			if query != tt.counts {
+				t.Errorf("count SQL = %q, want %q", query, tt.counts)
+			}
+			if want := tt.args[:len(tt.args)-2]; (len(args) > 0 || len(want) > 0) && !reflect.DeepEqual(args, want) {
+				t.Errorf("count args = %#v, want %#v", args, want)
+			}
+		})
+	}
+}
+
+func TestFilterQueryExpressionErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"unknown field", "file_path eq 'uploads/a.png'"},
+		{"unknown operator", "file_size between 1"},
+		{"syntax error", "file_size gt"},
+		{"invalid value", "created_at gt yesterday"},
+		{"null on non-nullable column", "mime_type eq null"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &filterQuery{}
+			err := q.expression(fileFilterSchema, tt.input)
+			if !errors.Is(err, pagination.ErrInvalidFilter) {
+				t.Fatalf("expression(%q) error = %v, want ErrInvalidFilter", tt.input, err)
+			}
+			if len(q.conditions) != 0 {
+				t.Errorf("expression(%q) added conditions %v", tt.input, q.conditions)
+			}
+		})
+	}
+}
+
+func TestFilterQueryUserSchema(t *testing.T) {
+	q := &filterQuery{}
+	if err := q.expression(userFilterSchema, "role in ('admin', 'moderator') and not email_verified eq false and last_login_at ne null"); err != nil {
+		t.Fatalf("expression: %v", err)
+	}
+
+	query, args := q.count("users")
+	want := "SELECT COUNT(*) FROM users WHERE (role IN ($1, $2) AND NOT email_verified = $3 AND last_login_at IS NOT NULL)"
+	if query != want {
+		t.Errorf("count SQL = %q, want %q", query, want)
+	}
+	if wantArgs := []interface{}{"admin", "moderator", false}; !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("count args = %#v, want %#v", args, wantArgs)
+	}
+
+	// Fields of other resources are unknown
+	if err := (&filterQuery{}).expression(userFilterSchema, "file_size gt 1"); !errors.Is(err, pagination.ErrInvalidFilter) {
+		t.Errorf("expression with a file field error = %v, want ErrInvalidFilter", err)
+	}
+}
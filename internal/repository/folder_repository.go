@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+
+	db "go-template/db/sqlc"
+	"go-template/internal/entity"
+)
+
+// FolderRepository manages the folder trees of users. The path of a folder lists the IDs of its
+// ancestors, so a subtree is found by prefix without walking the tree.
+type FolderRepository interface {
+	// Create adds a folder below parentID, or at the top level when parentID is nil
+	Create(ctx context.Context, userID int, parentID *int, name string) (*entity.Folder, error)
+	// GetByID returns a folder with the number of folders, files and bytes in its subtree
+	GetByID(ctx context.Context, id int) (*entity.Folder, error)
+	// GetByParent lists the subfolders of parentID, or the top level folders when parentID is nil
+	GetByParent(ctx context.Context, userID int, parentID *int) ([]entity.Folder, error)
+	// GetByName finds a sibling folder by name, ignoring case
+	GetByName(ctx context.Context, userID int, parentID *int, name string) (*entity.Folder, error)
+	Rename(ctx context.Context, id int, name string) (*entity.Folder, error)
+	// Move puts a folder with its subtree below parentID; it returns sql.ErrNoRows when parentID is
+	// inside the subtree
+	Move(ctx context.Context, id int, parentID *int) (*entity.Folder, error)
+	// Delete removes a folder and its subfolders; their files are moved to the top level
+	Delete(ctx context.Context, id int) error
+}
+
+type folderRepository struct {
+	db      *sql.DB
+	queries *db.Queries
+}
+
+func NewFolderRepository(dbConn *sql.DB) FolderRepository {
+	return &folderRepository{
+		db:      dbConn,
+		queries: db.New(dbConn),
+	}
+}
+
+func (r *folderRepository) Create(ctx context.Context, userID int, parentID *int, name string) (*entity.Folder, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	queries := r.queries.WithTx(tx)
+
+	// The parent is locked so it cannot be moved while its path is copied
+	path, err := r.childPath(ctx, queries, parentID)
+	if err != nil {
+		return nil, err
+	}
+
+	dbFolder, err := queries.CreateFolder(ctx, db.CreateFolderParams{
+		UserID:   int32(userID),
+		ParentID: ptrToNullInt32(parentID),
+		Name:     name,
+		Path:     path,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return r.mapDBFolderToEntity(&dbFolder), nil
+}
+
+func (r *folderRepository) GetByID(ctx context.Context, id int) (*entity.Folder, error) {
+	row, err := r.queries.GetFolder(ctx, int32(id))
+	if err != nil {
+		return nil, err
+	}
+
+	folder := r.mapDBFolderToEntity(&row.Folders)
+	folder.FolderCount = int(row.FolderCount)
+	folder.FileCount = int(row.FileCount)
+	folder.TotalSize = row.TotalSize
+	return folder, nil
+}
+
+func (r *folderRepository) GetByParent(ctx context.Context, userID int, parentID *int) ([]entity.Folder, error) {
+	rows, err := r.queries.GetFoldersByParent(ctx, db.GetFoldersByParentParams{
+		UserID:   int32(userID),
+		ParentID: ptrToNullInt32(parentID),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	folders := make([]entity.Folder, len(rows))
+	for i, row := range rows {
+		folders[i] = *r.mapDBFolderToEntity(&row.Folders)
+		folders[i].FolderCount = int(row.FolderCount)
+		folders[i].FileCount = int(row.FileCount)
+		folders[i].TotalSize = row.TotalSize
+	}
+
+	return folders, nil
+}
+
+func (r *folderRepository) GetByName(ctx context.Context, userID int, parentID *int, name string) (*entity.Folder, error) {
+	dbFolder, err := r.queries.GetFolderByName(ctx, db.GetFolderByNameParams{
+		UserID:   int32(userID),
+		ParentID: ptrToNullInt32(parentID),
+		Name:     name,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.mapDBFolderToEntity(&dbFolder), nil
+}
+
+func (r *folderRepository) Rename(ctx context.Context, id int, name string) (*entity.Folder, error) {
+	dbFolder, err := r.queries.RenameFolder(ctx, db.RenameFolderParams{
+		ID:   int32(id),
+		Name: name,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.mapDBFolderToEntity(&dbFolder), nil
+}
+
+func (r *folderRepository) Move(ctx context.Context, id int, parentID *int) (*entity.Folder, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	queries := r.queries.WithTx(tx)
+
+	current, err := queries.GetFolderForUpdate(ctx, int32(id))
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := r.childPath(ctx, queries, parentID)
+	if err != nil {
+		return nil, err
+	}
+
+	dbFolder, err := queries.MoveFolder(ctx, db.MoveFolderParams{
+		ParentID: ptrToNullInt32(parentID),
+		Path:     path,
+		ID:       int32(id),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The paths of the subfolders start with the path of the folder itself
+	suffix := strconv.Itoa(id) + "/"
+	if err := queries.MoveFolderDescendants(ctx, db.MoveFolderDescendantsParams{
+		NewPrefix: path + suffix,
+		OldPrefix: current.Path + suffix,
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return r.mapDBFolderToEntity(&dbFolder), nil
+}
+
+func (r *folderRepository) Delete(ctx context.Context, id int) error {
+	return r.queries.DeleteFolder(ctx, int32(id))
+}
+
+// childPath locks the parent folder and returns the path of its children
+func (r *folderRepository) childPath(ctx context.Context, queries *db.Queries, parentID *int) (string, error) {
+	if parentID == nil {
+		return "/", nil
+	}
+
+	parent, err := queries.GetFolderForUpdate(ctx, int32(*parentID))
+	if err != nil {
+		return "", err
+	}
+	return parent.Path + strconv.Itoa(int(parent.ID)) + "/", nil
+}
+
+func (r *folderRepository) mapDBFolderToEntity(dbFolder *db.Folders) *entity.Folder {
+	return &entity.Folder{
+		ID:        int(dbFolder.ID),
+		UserID:    int(dbFolder.UserID),
+		ParentID:  nullInt32ToPtr(dbFolder.ParentID),
+		Name:      dbFolder.Name,
+		Path:      dbFolder.Path,
+		CreatedAt: dbFolder.CreatedAt.Time,
+		UpdatedAt: dbFolder.UpdatedAt.Time,
+	}
+}
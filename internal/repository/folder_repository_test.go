@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// createTestFolder creates a folder of the user below parentID, or at the top level when it is 0
+func createTestFolder(t *testing.T, folders FolderRepository, userID int32, parentID int, name string) int {
+	t.Helper()
+
+	var parent *int
+	if parentID != 0 {
+		parent = &parentID
+	}
+	folder, err := folders.Create(context.Background(), int(userID), parent, name)
+	if err != nil {
+		t.Fatalf("create folder %s: %v", name, err)
+	}
+	return folder.ID
+}
+
+// createTestFile inserts a file of the user with the size into folderID, or at the top level when it is 0
+func createTestFile(t *testing.T, dbConn *sql.DB, userID int32, folderID int, size int64) int {
+	t.Helper()
+
+	var folder sql.NullInt32
+	if folderID != 0 {
+		folder = sql.NullInt32{Int32: int32(folderID), Valid: true}
+	}
+	name := fmt.Sprintf("file%d.txt", size)
+	var fileID int
+	err := dbConn.QueryRow(`INSERT INTO files (file_name, original_name, file_path, file_size, mime_type, uploaded_by, folder_id)
+		VALUES ($1, $1, $1, $2, 'text/plain', $3, $4) RETURNING id`, name, size, userID, folder).Scan(&fileID)
+	if err != nil {
+		t.Fatalf("create file: %v", err)
+	}
+	return fileID
+}
+
+func folderPath(t *testing.T, folders FolderRepository, id int) string {
+	t.Helper()
+
+	folder, err := folders.GetByID(context.Background(), id)
+	if err != nil {
+		t.Fatalf("get folder %d: %v", id, err)
+	}
+	return folder.Path
+}
+
+func TestFolderMoveRewritesDescendantPaths(t *testing.T) {
+	dbConn := openTestDB(t)
+	ctx := context.Background()
+	folders := NewFolderRepository(dbConn)
+	userID := createTestUser(t, dbConn, 0, 0, nil, nil)
+
+	// a/b/c and d
+	a := createTestFolder(t, folders, userID, 0, "a")
+	b := createTestFolder(t, folders, userID, a, "b")
+	c := createTestFolder(t, folders, userID, b, "c")
+	d := createTestFolder(t, folders, userID, 0, "d")
+	if path := folderPath(t, folders, c); path != fmt.Sprintf("/%d/%d/", a, b) {
+		t.Fatalf("path of c = %q, want below a and b", path)
+	}
+
+	// d/b/c and a
+	moved, err := folders.Move(ctx, b, &d)
+	if err != nil {
+		t.Fatalf("Move: %v", err)
+	}
+	if moved.ParentID == nil || *moved.ParentID != d || moved.Path != fmt.Sprintf("/%d/", d) {
+		t.Errorf("moved folder has parent %v and path %q, want below d", moved.ParentID, moved.Path)
+	}
+	if path := folderPath(t, folders, c); path != fmt.Sprintf("/%d/%d/", d, b) {
+		t.Errorf("path of c = %q, want below d and b", path)
+	}
+	if path := folderPath(t, folders, a); path != "/" {
+		t.Errorf("path of a = %q, want it at the top level", path)
+	}
+
+	// Back to the top level: b/c
+	if _, err := folders.Move(ctx, b, nil); err != nil {
+		t.Fatalf("Move to the top level: %v", err)
+	}
+	if path := folderPath(t, folders, c); path != fmt.Sprintf("/%d/", b) {
+		t.Errorf("path of c = %q, want below b only", path)
+	}
+}
+
+func TestFolderMoveIntoOwnSubtree(t *testing.T) {
+	dbConn := openTestDB(t)
+	ctx := context.Background()
+	folders := NewFolderRepository(dbConn)
+	userID := createTestUser(t, dbConn, 0, 0, nil, nil)
+
+	a := createTestFolder(t, folders, userID, 0, "a")
+	b := createTestFolder(t, folders, userID, a, "b")
+	c := createTestFolder(t, folders, userID, b, "c")
+
+	// The service checks this too, but a concurrent move can get past its check
+	for _, parent := range []int{a, b, c} {
+		if _, err := folders.Move(ctx, a, &parent); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("Move of a into folder %d: error %v, want %v", parent, err, sql.ErrNoRows)
+		}
+	}
+	if path := folderPath(t, folders, c); path != fmt.Sprintf("/%d/%d/", a, b) {
+		t.Errorf("path of c = %q after refused moves, want it unchanged", path)
+	}
+}
+
+func TestFolderStatistics(t *testing.T) {
+	dbConn := openTestDB(t)
+	ctx := context.Background()
+	folders := NewFolderRepository(dbConn)
+	files := NewFileRepository(dbConn)
+	userID := createTestUser(t, dbConn, 0, 0, nil, nil)
+
+	// a holds 100 bytes, a/b 20, a/b/c 3 and 4, d 5000, and 1 byte is at the top level
+	a := createTestFolder(t, folders, userID, 0, "a")
+	b := createTestFolder(t, folders, userID, a, "b")
+	c := createTestFolder(t, folders, userID, b, "c")
+	d := createTestFolder(t, folders, userID, 0, "d")
+	inTree := []int{
+		createTestFile(t, dbConn, userID, a, 100),
+		createTestFile(t, dbConn, userID, b, 20),
+		createTestFile(t, dbConn, userID, c, 3),
+		createTestFile(t, dbConn, userID, c, 4),
+	}
+	createTestFile(t, dbConn, userID, d, 5000)
+	createTestFile(t, dbConn, userID, 0, 1)
+
+	tests := []struct {
+		name        string
+		id          int
+		wantFolders int
+		wantFiles   int
+		wantSize    int64
+	}{
+		{"a", a, 2, 4, 127},
+		{"b", b, 1, 3, 27},
+		{"c", c, 0, 2, 7},
+		{"d", d, 0, 1, 5000},
+	}
+	for _, tt := range tests {
+		folder, err := folders.GetByID(ctx, tt.id)
+		if err != nil {
+			t.Fatalf("GetByID(%s): %v", tt.name, err)
+		}
+		if folder.FolderCount != tt.wantFolders || folder.FileCount != tt.wantFiles || folder.TotalSize != tt.wantSize {
+			t.Errorf("%s has %d folders, %d files and %d bytes, want %d, %d and %d", tt.name,
+				folder.FolderCount, folder.FileCount, folder.TotalSize, tt.wantFolders, tt.wantFiles, tt.wantSize)
+		}
+	}
+
+	// Listings carry the same statistics
+	topLevel, err := folders.GetByParent(ctx, int(userID), nil)
+	if err != nil {
+		t.Fatalf("GetByParent: %v", err)
+	}
+	if len(topLevel) != 2 || topLevel[0].ID != a || topLevel[0].FileCount != 4 || topLevel[0].TotalSize != 127 || topLevel[1].ID != d {
+		t.Errorf("top level folders = %+v, want a with 4 files and 127 bytes, then d", topLevel)
+	}
+
+	treeFiles, err := files.GetByFolderTree(ctx, a)
+	if err != nil {
+		t.Fatalf("GetByFolderTree: %v", err)
+	}
+	if len(treeFiles) != len(inTree) {
+		t.Fatalf("GetByFolderTree returned %d files, want %d", len(treeFiles), len(inTree))
+	}
+	for i, file := range treeFiles {
+		if file.ID != inTree[i] {
+			t.Errorf("file %d of the tree = %d, want %d", i, file.ID, inTree[i])
+		}
+	}
+}
+
+func TestFolderDelete(t *testing.T) {
+	dbConn := openTestDB(t)
+	ctx := context.Background()
+	folders := NewFolderRepository(dbConn)
+	files := NewFileRepository(dbConn)
+	userID := createTestUser(t, dbConn, 0, 0, nil, nil)
+
+	a := createTestFolder(t, folders, userID, 0, "a")
+	b := createTestFolder(t, folders, userID, a, "b")
+	d := createTestFolder(t, folders, userID, 0, "d")
+	left := createTestFile(t, dbConn, userID, b, 10)
+
+	if err := folders.Delete(ctx, a); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	for _, id := range []int{a, b} {
+		if _, err := folders.GetByID(ctx, id); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("GetByID(%d) after deleting a: error %v, want %v", id, err, sql.ErrNoRows)
+		}
+	}
+	if _, err := folders.GetByID(ctx, d); err != nil {
+		t.Errorf("GetByID(d): %v", err)
+	}
+
+	// Files the service did not delete first end up at the top level
+	file, err := files.GetByID(ctx, left)
+	if err != nil {
+		t.Fatalf("GetByID of the file: %v", err)
+	}
+	if file.FolderID != nil {
+		t.Errorf("file is in folder %d, want it at the top level", *file.FolderID)
+	}
+}
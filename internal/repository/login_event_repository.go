@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	db "go-template/db/sqlc"
+	"go-template/internal/entity"
+	"go-template/pkg/pagination"
+)
+
+type LoginEventRepository interface {
+	Create(ctx context.Context, event *entity.LoginEvent) (*entity.LoginEvent, error)
+	GetByUserID(ctx context.Context, userID int, paginationParams pagination.PaginationParams) ([]entity.LoginEvent, int, error)
+}
+
+type loginEventRepository struct {
+	db      *sql.DB
+	queries *db.Queries
+}
+
+func NewLoginEventRepository(dbConn *sql.DB) LoginEventRepository {
+	return &loginEventRepository{
+		db:      dbConn,
+		queries: db.New(dbConn),
+	}
+}
+
+func (r *loginEventRepository) Create(ctx context.Context, event *entity.LoginEvent) (*entity.LoginEvent, error) {
+	createdEvent, err := r.queries.CreateLoginEvent(ctx, db.CreateLoginEventParams{
+		UserID:        ptrToNullInt32(event.UserID),
+		Email:         event.Email,
+		Success:       event.Success,
+		Method:        event.Method,
+		FailureReason: ptrToNullString(event.FailureReason),
+		IpAddress:     ptrToNullString(event.IPAddress),
+		UserAgent:     ptrToNullString(event.UserAgent),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.mapDBLoginEventToEntity(&createdEvent), nil
+}
+
+func (r *loginEventRepository) GetByUserID(ctx context.Context, userID int, paginationParams pagination.PaginationParams) ([]entity.LoginEvent, int, error) {
+	dbUserID := sql.NullInt32{Int32: int32(userID), Valid: true}
+
+	dbEvents, err := r.queries.ListLoginEventsByUser(ctx, db.ListLoginEventsByUserParams{
+		UserID: dbUserID,
+		Limit:  int32(paginationParams.Limit),
+		Offset: int32(paginationParams.CalculateOffset()),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	totalCount, err := r.queries.CountLoginEventsByUser(ctx, dbUserID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	events := make([]entity.LoginEvent, len(dbEvents))
+	for i, dbEvent := range dbEvents {
+		events[i] = *r.mapDBLoginEventToEntity(&dbEvent)
+	}
+
+	return events, int(totalCount), nil
+}
+
+func (r *loginEventRepository) mapDBLoginEventToEntity(dbEvent *db.LoginEvents) *entity.LoginEvent {
+	return &entity.LoginEvent{
+		ID:            int(dbEvent.ID),
+		UserID:        nullInt32ToPtr(dbEvent.UserID),
+		Email:         dbEvent.Email,
+		Success:       dbEvent.Success,
+		Method:        dbEvent.Method,
+		FailureReason: nullStringToPtr(dbEvent.FailureReason),
+		IPAddress:     nullStringToPtr(dbEvent.IpAddress),
+		UserAgent:     nullStringToPtr(dbEvent.UserAgent),
+		CreatedAt:     dbEvent.CreatedAt.Time,
+	}
+}
@@ -3,6 +3,9 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"slices"
+	"strings"
 	"time"
 
 	db "go-template/db/sqlc"
@@ -16,11 +19,14 @@ type UserRepository interface {
 	CreateWithPassword(ctx context.Context, name, email, passwordHash string) (*entity.User, error)
 	CreateWithPasswordAndRole(ctx context.Context, name, email, passwordHash, role, emailVerificationToken string, emailVerificationExpiresAt *time.Time) (*entity.User, error)
 	GetByID(ctx context.Context, id int) (*entity.User, error)
+	GetByIDs(ctx context.Context, ids []int) ([]entity.User, error)
 	GetByEmail(ctx context.Context, email string) (*entity.User, error)
 	GetByEmailWithPassword(ctx context.Context, email string) (*entity.User, error)
 	GetByVerificationToken(ctx context.Context, token string) (*entity.User, error)
 	GetByPasswordResetToken(ctx context.Context, token string) (*entity.User, error)
 	Update(ctx context.Context, id int, name string) (*entity.User, error)
+	UpdateProfile(ctx context.Context, id int, name string, displayName, bio, locale, timezone, phone *string, metadata map[string]interface{}) (*entity.User, error)
+	UpdateAvatar(ctx context.Context, id int, avatarFileID *int, avatarFileName *string) (*entity.User, error)
 	VerifyEmail(ctx context.Context, token string) error
 	UpdateVerificationToken(ctx context.Context, userID int, token string, expiresAt *time.Time) error
 	UpdatePasswordResetToken(ctx context.Context, userID int, token string, expiresAt *time.Time) error
@@ -28,8 +34,35 @@ type UserRepository interface {
 	Delete(ctx context.Context, id int) error
 	GetAll(ctx context.Context) ([]entity.User, error)
 	GetAllWithPagination(ctx context.Context, paginationParams pagination.PaginationParams, filterParams pagination.FilterParams) ([]entity.User, int, error)
+	GetAllWithCursor(ctx context.Context, paginationParams pagination.PaginationParams, filterParams pagination.FilterParams) ([]entity.User, pagination.PaginationMeta, error)
+	ScheduleDeletion(ctx context.Context, id int, scheduledAt time.Time) (*entity.User, error)
+	CancelDeletion(ctx context.Context, id int) (*entity.User, error)
+	GetDueForDeletion(ctx context.Context, before time.Time) ([]entity.User, error)
+	UpdateLastLogin(ctx context.Context, id int, loggedInAt time.Time) error
+	GetInactive(ctx context.Context, inactiveSince time.Time, paginationParams pagination.PaginationParams) ([]entity.User, int, error)
 }
 
+// userFilterSchema lists the user fields available to filter expressions
+var userFilterSchema = pagination.FilterSchema{
+	"id":             {Column: "id", Type: pagination.FilterInt},
+	"name":           {Column: "name", Type: pagination.FilterString},
+	"email":          {Column: "email", Type: pagination.FilterString},
+	"role":           {Column: "role", Type: pagination.FilterString},
+	"email_verified": {Column: "email_verified", Type: pagination.FilterBool},
+	"display_name":   {Column: "display_name", Type: pagination.FilterString, Nullable: true},
+	"locale":         {Column: "locale", Type: pagination.FilterString, Nullable: true},
+	"timezone":       {Column: "timezone", Type: pagination.FilterString, Nullable: true},
+	"created_at":     {Column: "created_at", Type: pagination.FilterTime},
+	"updated_at":     {Column: "updated_at", Type: pagination.FilterTime},
+	"last_login_at":  {Column: "last_login_at", Type: pagination.FilterTime, Nullable: true},
+}
+
+// userColumns selects the users table in the field order of db.Users, for queries built at runtime
+const userColumns = "id, name, email, created_at, updated_at, password_hash, role, email_verified, " +
+	"email_verification_token, email_verification_expires_at, password_reset_token, password_reset_expires_at, " +
+	"deletion_requested_at, deletion_scheduled_at, display_name, bio, locale, timezone, phone, metadata, " +
+	"avatar_file_id, avatar_file_name, last_login_at"
+
 type userRepository struct {
 	db      *sql.DB
 	queries *db.Queries
@@ -51,20 +84,7 @@ func (r *userRepository) Create(ctx context.Context, name, email string) (*entit
 		return nil, err
 	}
 
-	return &entity.User{
-		ID:                         int(createdUser.ID),
-		Name:                       createdUser.Name,
-		Email:                      createdUser.Email,
-		PasswordHash:               createdUser.PasswordHash,
-		Role:                       createdUser.Role,
-		EmailVerified:              createdUser.EmailVerified,
-		EmailVerificationToken:     nullStringToPtr(createdUser.EmailVerificationToken),
-		EmailVerificationExpiresAt: nullTimeToPtr(createdUser.EmailVerificationExpiresAt),
-		PasswordResetToken:         nullStringToPtr(createdUser.PasswordResetToken),
-		PasswordResetExpiresAt:     nullTimeToPtr(createdUser.PasswordResetExpiresAt),
-		CreatedAt:                  createdUser.CreatedAt.Time,
-		UpdatedAt:                  createdUser.UpdatedAt.Time,
-	}, nil
+	return r.mapDBUserToEntity(&createdUser), nil
 }
 
 func (r *userRepository) GetByID(ctx context.Context, id int) (*entity.User, error) {
@@ -73,20 +93,33 @@ func (r *userRepository) GetByID(ctx context.Context, id int) (*entity.User, err
 		return nil, err
 	}
 
-	return &entity.User{
-		ID:                         int(user.ID),
-		Name:                       user.Name,
-		Email:                      user.Email,
-		PasswordHash:               user.PasswordHash,
-		Role:                       user.Role,
-		EmailVerified:              user.EmailVerified,
-		EmailVerificationToken:     nullStringToPtr(user.EmailVerificationToken),
-		EmailVerificationExpiresAt: nullTimeToPtr(user.EmailVerificationExpiresAt),
-		PasswordResetToken:         nullStringToPtr(user.PasswordResetToken),
-		PasswordResetExpiresAt:     nullTimeToPtr(user.PasswordResetExpiresAt),
-		CreatedAt:                  user.CreatedAt.Time,
-		UpdatedAt:                  user.UpdatedAt.Time,
-	}, nil
+	return r.mapDBUserToEntity(&user), nil
+}
+
+// GetByIDs loads several users in one query; ids that do not exist are skipped
+func (r *userRepository) GetByIDs(ctx context.Context, ids []int) ([]entity.User, error) {
+	if len(ids) == 0 {
+		return []entity.User{}, nil
+	}
+
+	query := &filterQuery{}
+	placeholders := make([]string, len(ids))
+	for i, id := range ids {
+		placeholders[i] = query.bind(int32(id))
+	}
+	query.where("id IN (" + strings.Join(placeholders, ", ") + ")")
+
+	dbUsers, err := r.queryUsers(ctx, "SELECT "+userColumns+" FROM users"+query.whereClause(), query.args...)
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]entity.User, len(dbUsers))
+	for i, dbUser := range dbUsers {
+		users[i] = *r.mapDBUserToEntity(&dbUser)
+	}
+
+	return users, nil
 }
 
 func (r *userRepository) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
@@ -95,20 +128,7 @@ func (r *userRepository) GetByEmail(ctx context.Context, email string) (*entity.
 		return nil, err
 	}
 
-	return &entity.User{
-		ID:                         int(user.ID),
-		Name:                       user.Name,
-		Email:                      user.Email,
-		PasswordHash:               user.PasswordHash,
-		Role:                       user.Role,
-		EmailVerified:              user.EmailVerified,
-		EmailVerificationToken:     nullStringToPtr(user.EmailVerificationToken),
-		EmailVerificationExpiresAt: nullTimeToPtr(user.EmailVerificationExpiresAt),
-		PasswordResetToken:         nullStringToPtr(user.PasswordResetToken),
-		PasswordResetExpiresAt:     nullTimeToPtr(user.PasswordResetExpiresAt),
-		CreatedAt:                  user.CreatedAt.Time,
-		UpdatedAt:                  user.UpdatedAt.Time,
-	}, nil
+	return r.mapDBUserToEntity(&user), nil
 }
 
 func (r *userRepository) Update(ctx context.Context, id int, name string) (*entity.User, error) {
@@ -120,20 +140,7 @@ func (r *userRepository) Update(ctx context.Context, id int, name string) (*enti
 		return nil, err
 	}
 
-	return &entity.User{
-		ID:                         int(updatedUser.ID),
-		Name:                       updatedUser.Name,
-		Email:                      updatedUser.Email,
-		PasswordHash:               updatedUser.PasswordHash,
-		Role:                       updatedUser.Role,
-		EmailVerified:              updatedUser.EmailVerified,
-		EmailVerificationToken:     nullStringToPtr(updatedUser.EmailVerificationToken),
-		EmailVerificationExpiresAt: nullTimeToPtr(updatedUser.EmailVerificationExpiresAt),
-		PasswordResetToken:         nullStringToPtr(updatedUser.PasswordResetToken),
-		PasswordResetExpiresAt:     nullTimeToPtr(updatedUser.PasswordResetExpiresAt),
-		CreatedAt:                  updatedUser.CreatedAt.Time,
-		UpdatedAt:                  updatedUser.UpdatedAt.Time,
-	}, nil
+	return r.mapDBUserToEntity(&updatedUser), nil
 }
 
 func (r *userRepository) Delete(ctx context.Context, id int) error {
@@ -153,20 +160,7 @@ func (r *userRepository) CreateWithPassword(ctx context.Context, name, email, pa
 		return nil, err
 	}
 
-	return &entity.User{
-		ID:                         int(createdUser.ID),
-		Name:                       createdUser.Name,
-		Email:                      createdUser.Email,
-		PasswordHash:               createdUser.PasswordHash,
-		Role:                       createdUser.Role,
-		EmailVerified:              createdUser.EmailVerified,
-		EmailVerificationToken:     nullStringToPtr(createdUser.EmailVerificationToken),
-		EmailVerificationExpiresAt: nullTimeToPtr(createdUser.EmailVerificationExpiresAt),
-		PasswordResetToken:         nullStringToPtr(createdUser.PasswordResetToken),
-		PasswordResetExpiresAt:     nullTimeToPtr(createdUser.PasswordResetExpiresAt),
-		CreatedAt:                  createdUser.CreatedAt.Time,
-		UpdatedAt:                  createdUser.UpdatedAt.Time,
-	}, nil
+	return r.mapDBUserToEntity(&createdUser), nil
 }
 
 func (r *userRepository) GetByEmailWithPassword(ctx context.Context, email string) (*entity.User, error) {
@@ -175,20 +169,7 @@ func (r *userRepository) GetByEmailWithPassword(ctx context.Context, email strin
 		return nil, err
 	}
 
-	return &entity.User{
-		ID:                         int(user.ID),
-		Name:                       user.Name,
-		Email:                      user.Email,
-		PasswordHash:               user.PasswordHash,
-		Role:                       user.Role,
-		EmailVerified:              user.EmailVerified,
-		EmailVerificationToken:     nullStringToPtr(user.EmailVerificationToken),
-		EmailVerificationExpiresAt: nullTimeToPtr(user.EmailVerificationExpiresAt),
-		PasswordResetToken:         nullStringToPtr(user.PasswordResetToken),
-		PasswordResetExpiresAt:     nullTimeToPtr(user.PasswordResetExpiresAt),
-		CreatedAt:                  user.CreatedAt.Time,
-		UpdatedAt:                  user.UpdatedAt.Time,
-	}, nil
+	return r.mapDBUserToEntity(&user), nil
 }
 
 func (r *userRepository) GetAll(ctx context.Context) ([]entity.User, error) {
@@ -200,20 +181,7 @@ func (r *userRepository) GetAll(ctx context.Context) ([]entity.User, error) {
 
 	users := make([]entity.User, len(userList))
 	for i, dbUser := range userList {
-		users[i] = entity.User{
-			ID:                         int(dbUser.ID),
-			Name:                       dbUser.Name,
-			Email:                      dbUser.Email,
-			PasswordHash:               dbUser.PasswordHash,
-			Role:                       dbUser.Role,
-			EmailVerified:              dbUser.EmailVerified,
-			EmailVerificationToken:     nullStringToPtr(dbUser.EmailVerificationToken),
-			EmailVerificationExpiresAt: nullTimeToPtr(dbUser.EmailVerificationExpiresAt),
-			PasswordResetToken:         nullStringToPtr(dbUser.PasswordResetToken),
-			PasswordResetExpiresAt:     nullTimeToPtr(dbUser.PasswordResetExpiresAt),
-			CreatedAt:                  dbUser.CreatedAt.Time,
-			UpdatedAt:                  dbUser.UpdatedAt.Time,
-		}
+		users[i] = *r.mapDBUserToEntity(&dbUser)
 	}
 
 	return users, nil
@@ -232,20 +200,7 @@ func (r *userRepository) CreateWithPasswordAndRole(ctx context.Context, name, em
 		return nil, err
 	}
 
-	return &entity.User{
-		ID:                         int(createdUser.ID),
-		Name:                       createdUser.Name,
-		Email:                      createdUser.Email,
-		PasswordHash:               createdUser.PasswordHash,
-		Role:                       createdUser.Role,
-		EmailVerified:              createdUser.EmailVerified,
-		EmailVerificationToken:     nullStringToPtr(createdUser.EmailVerificationToken),
-		EmailVerificationExpiresAt: nullTimeToPtr(createdUser.EmailVerificationExpiresAt),
-		PasswordResetToken:         nullStringToPtr(createdUser.PasswordResetToken),
-		PasswordResetExpiresAt:     nullTimeToPtr(createdUser.PasswordResetExpiresAt),
-		CreatedAt:                  createdUser.CreatedAt.Time,
-		UpdatedAt:                  createdUser.UpdatedAt.Time,
-	}, nil
+	return r.mapDBUserToEntity(&createdUser), nil
 }
 
 func (r *userRepository) GetByVerificationToken(ctx context.Context, token string) (*entity.User, error) {
@@ -254,20 +209,7 @@ func (r *userRepository) GetByVerificationToken(ctx context.Context, token strin
 		return nil, err
 	}
 
-	return &entity.User{
-		ID:                         int(user.ID),
-		Name:                       user.Name,
-		Email:                      user.Email,
-		PasswordHash:               user.PasswordHash,
-		Role:                       user.Role,
-		EmailVerified:              user.EmailVerified,
-		EmailVerificationToken:     nullStringToPtr(user.EmailVerificationToken),
-		EmailVerificationExpiresAt: nullTimeToPtr(user.EmailVerificationExpiresAt),
-		PasswordResetToken:         nullStringToPtr(user.PasswordResetToken),
-		PasswordResetExpiresAt:     nullTimeToPtr(user.PasswordResetExpiresAt),
-		CreatedAt:                  user.CreatedAt.Time,
-		UpdatedAt:                  user.UpdatedAt.Time,
-	}, nil
+	return r.mapDBUserToEntity(&user), nil
 }
 
 func (r *userRepository) VerifyEmail(ctx context.Context, token string) error {
@@ -311,26 +253,37 @@ func ptrToNullTime(t *time.Time) sql.NullTime {
 	return sql.NullTime{Valid: false}
 }
 
+func nullInt32ToPtr(ni sql.NullInt32) *int {
+	if ni.Valid {
+		value := int(ni.Int32)
+		return &value
+	}
+	return nil
+}
+
+func ptrToNullInt32(i *int) sql.NullInt32 {
+	if i != nil {
+		return sql.NullInt32{Int32: int32(*i), Valid: true}
+	}
+	return sql.NullInt32{Valid: false}
+}
+
+// rawMessageToMap decodes a JSONB object, falling back to an empty map for malformed data
+func rawMessageToMap(raw json.RawMessage) map[string]interface{} {
+	m := map[string]interface{}{}
+	if len(raw) > 0 {
+		json.Unmarshal(raw, &m)
+	}
+	return m
+}
+
 func (r *userRepository) GetByPasswordResetToken(ctx context.Context, token string) (*entity.User, error) {
 	user, err := r.queries.GetUserByPasswordResetToken(ctx, sql.NullString{String: token, Valid: true})
 	if err != nil {
 		return nil, err
 	}
 
-	return &entity.User{
-		ID:                         int(user.ID),
-		Name:                       user.Name,
-		Email:                      user.Email,
-		PasswordHash:               user.PasswordHash,
-		Role:                       user.Role,
-		EmailVerified:              user.EmailVerified,
-		EmailVerificationToken:     nullStringToPtr(user.EmailVerificationToken),
-		EmailVerificationExpiresAt: nullTimeToPtr(user.EmailVerificationExpiresAt),
-		PasswordResetToken:         nullStringToPtr(user.PasswordResetToken),
-		PasswordResetExpiresAt:     nullTimeToPtr(user.PasswordResetExpiresAt),
-		CreatedAt:                  user.CreatedAt.Time,
-		UpdatedAt:                  user.UpdatedAt.Time,
-	}, nil
+	return r.mapDBUserToEntity(&user), nil
 }
 
 func (r *userRepository) UpdatePasswordResetToken(ctx context.Context, userID int, token string, expiresAt *time.Time) error {
@@ -349,53 +302,31 @@ func (r *userRepository) ResetPassword(ctx context.Context, token, passwordHash
 }
 
 func (r *userRepository) GetAllWithPagination(ctx context.Context, paginationParams pagination.PaginationParams, filterParams pagination.FilterParams) ([]entity.User, int, error) {
-	// Prepare filter parameters for SQLC
-	var nameFilter, emailFilter, roleFilter, search sql.NullString
-	var emailVerifiedFilter sql.NullBool
-	var createdAfter, createdBefore sql.NullTime
-
-	if filterParams.Name != "" {
-		nameFilter = sql.NullString{String: filterParams.Name, Valid: true}
-	}
-	if filterParams.Email != "" {
-		emailFilter = sql.NullString{String: filterParams.Email, Valid: true}
-	}
-	if filterParams.Role != "" {
-		roleFilter = sql.NullString{String: filterParams.Role, Valid: true}
-	}
-	if filterParams.EmailVerified != nil {
-		emailVerifiedFilter = sql.NullBool{Bool: *filterParams.EmailVerified, Valid: true}
-	}
-	if filterParams.CreatedAfter != "" {
-		if parsedTime, err := time.Parse(time.RFC3339, filterParams.CreatedAfter); err == nil {
-			createdAfter = sql.NullTime{Time: parsedTime, Valid: true}
-		}
-	}
-	if filterParams.CreatedBefore != "" {
-		if parsedTime, err := time.Parse(time.RFC3339, filterParams.CreatedBefore); err == nil {
-			createdBefore = sql.NullTime{Time: parsedTime, Valid: true}
-		}
-	}
-	if paginationParams.Search != "" {
-		search = sql.NullString{String: paginationParams.Search, Valid: true}
-	}
+	filters := newUserListFilters(paginationParams, filterParams)
 
 	// Validate and sanitize sort field
-	allowedSortFields := []string{"id", "name", "email", "created_at", "role"}
+	allowedSortFields := []string{"id", "name", "email", "created_at", "role", "last_login_at"}
 	sortField := pagination.ValidateSortField(paginationParams.Sort, allowedSortFields)
 	sortOrder := pagination.SanitizeOrder(paginationParams.Order)
 
+	if filterParams.Filter != "" {
+		return r.getAllByFilterExpression(ctx, filterParams.Filter, filters, sortField, sortOrder, paginationParams)
+	}
+
 	// Get paginated users
 	users, err := r.queries.ListUsersWithPaginationAndFilters(ctx, db.ListUsersWithPaginationAndFiltersParams{
 		Limit:               int32(paginationParams.Limit),
 		Offset:              int32(paginationParams.CalculateOffset()),
-		NameFilter:          nameFilter,
-		EmailFilter:         emailFilter,
-		RoleFilter:          roleFilter,
-		EmailVerifiedFilter: emailVerifiedFilter,
-		CreatedAfter:        createdAfter,
-		CreatedBefore:       createdBefore,
-		Search:              search,
+		NameFilter:          filters.name,
+		EmailFilter:         filters.email,
+		RoleFilter:          filters.role,
+		EmailVerifiedFilter: filters.emailVerified,
+		CreatedAfter:        filters.createdAfter,
+		CreatedBefore:       filters.createdBefore,
+		LastLoginAfter:      filters.lastLoginAfter,
+		LastLoginBefore:     filters.lastLoginBefore,
+		NeverLoggedIn:       filters.neverLoggedIn,
+		Search:              filters.search,
 		SortField:           sortField,
 		SortOrder:           sortOrder,
 	})
@@ -405,13 +336,16 @@ func (r *userRepository) GetAllWithPagination(ctx context.Context, paginationPar
 
 	// Get total count with same filters
 	totalCount, err := r.queries.CountUsersWithFilters(ctx, db.CountUsersWithFiltersParams{
-		NameFilter:          nameFilter,
-		EmailFilter:         emailFilter,
-		RoleFilter:          roleFilter,
-		EmailVerifiedFilter: emailVerifiedFilter,
-		CreatedAfter:        createdAfter,
-		CreatedBefore:       createdBefore,
-		Search:              search,
+		NameFilter:          filters.name,
+		EmailFilter:         filters.email,
+		RoleFilter:          filters.role,
+		EmailVerifiedFilter: filters.emailVerified,
+		CreatedAfter:        filters.createdAfter,
+		CreatedBefore:       filters.createdBefore,
+		LastLoginAfter:      filters.lastLoginAfter,
+		LastLoginBefore:     filters.lastLoginBefore,
+		NeverLoggedIn:       filters.neverLoggedIn,
+		Search:              filters.search,
 	})
 	if err != nil {
 		return nil, 0, err
@@ -420,21 +354,404 @@ func (r *userRepository) GetAllWithPagination(ctx context.Context, paginationPar
 	// Convert SQLC models to entities
 	entityUsers := make([]entity.User, len(users))
 	for i, dbUser := range users {
-		entityUsers[i] = entity.User{
-			ID:                         int(dbUser.ID),
-			Name:                       dbUser.Name,
-			Email:                      dbUser.Email,
-			PasswordHash:               dbUser.PasswordHash,
-			Role:                       dbUser.Role,
-			EmailVerified:              dbUser.EmailVerified,
-			EmailVerificationToken:     nullStringToPtr(dbUser.EmailVerificationToken),
-			EmailVerificationExpiresAt: nullTimeToPtr(dbUser.EmailVerificationExpiresAt),
-			PasswordResetToken:         nullStringToPtr(dbUser.PasswordResetToken),
-			PasswordResetExpiresAt:     nullTimeToPtr(dbUser.PasswordResetExpiresAt),
-			CreatedAt:                  dbUser.CreatedAt.Time,
-			UpdatedAt:                  dbUser.UpdatedAt.Time,
-		}
+		entityUsers[i] = *r.mapDBUserToEntity(&dbUser)
 	}
 
 	return entityUsers, int(totalCount), nil
 }
+
+func (r *userRepository) GetAllWithCursor(ctx context.Context, paginationParams pagination.PaginationParams, filterParams pagination.FilterParams) ([]entity.User, pagination.PaginationMeta, error) {
+	cursor, err := pagination.DecodeCursor(paginationParams.Cursor)
+	if err != nil {
+		return nil, pagination.PaginationMeta{}, err
+	}
+
+	// Keyset pagination needs a non-null sort key, so last_login_at is only available in offset mode
+	allowedSortFields := []string{"id", "name", "email", "created_at", "role"}
+	keyset, err := newKeysetArgs(cursor, paginationParams, allowedSortFields)
+	if err != nil {
+		return nil, pagination.PaginationMeta{}, err
+	}
+
+	filters := newUserListFilters(paginationParams, filterParams)
+
+	// Fetch one extra row to find out whether another page follows
+	var users []db.Users
+	if filterParams.Filter != "" {
+		query := &filterQuery{}
+		filters.apply(query)
+		if err := query.expression(userFilterSchema, filterParams.Filter); err != nil {
+			return nil, pagination.PaginationMeta{}, err
+		}
+		query.keyset(keyset)
+		listQuery, args := query.list("users", userColumns, keyset.sortField, keyset.queryOrder, paginationParams.Limit+1, 0)
+		users, err = r.queryUsers(ctx, listQuery, args...)
+	} else {
+		users, err = r.queries.ListUsersWithCursor(ctx, db.ListUsersWithCursorParams{
+			Limit:               int32(paginationParams.Limit + 1),
+			NameFilter:          filters.name,
+			EmailFilter:         filters.email,
+			RoleFilter:          filters.role,
+			EmailVerifiedFilter: filters.emailVerified,
+			CreatedAfter:        filters.createdAfter,
+			CreatedBefore:       filters.createdBefore,
+			LastLoginAfter:      filters.lastLoginAfter,
+			LastLoginBefore:     filters.lastLoginBefore,
+			NeverLoggedIn:       filters.neverLoggedIn,
+			Search:              filters.search,
+			CursorID:            keyset.id,
+			SortField:           keyset.sortField,
+			SortOrder:           keyset.queryOrder,
+			CursorText:          keyset.text,
+			CursorTime:          keyset.time,
+		})
+	}
+	if err != nil {
+		return nil, pagination.PaginationMeta{}, err
+	}
+
+	hasMore := len(users) > paginationParams.Limit
+	if hasMore {
+		users = users[:paginationParams.Limit]
+	}
+	if cursor != nil && cursor.Before {
+		slices.Reverse(users)
+	}
+
+	entityUsers := make([]entity.User, len(users))
+	for i, dbUser := range users {
+		entityUsers[i] = *r.mapDBUserToEntity(&dbUser)
+	}
+
+	var first, last *pagination.Cursor
+	if len(entityUsers) > 0 {
+		first = userCursor(&entityUsers[0], keyset)
+		last = userCursor(&entityUsers[len(entityUsers)-1], keyset)
+	}
+
+	return entityUsers, pagination.NewCursorPaginationMeta(paginationParams.Limit, cursor, hasMore, first, last), nil
+}
+
+func (r *userRepository) UpdateProfile(ctx context.Context, id int, name string, displayName, bio, locale, timezone, phone *string, metadata map[string]interface{}) (*entity.User, error) {
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	rawMetadata, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedUser, err := r.queries.UpdateUserProfile(ctx, db.UpdateUserProfileParams{
+		ID:          int32(id),
+		Name:        name,
+		DisplayName: ptrToNullString(displayName),
+		Bio:         ptrToNullString(bio),
+		Locale:      ptrToNullString(locale),
+		Timezone:    ptrToNullString(timezone),
+		Phone:       ptrToNullString(phone),
+		Metadata:    rawMetadata,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.mapDBUserToEntity(&updatedUser), nil
+}
+
+func (r *userRepository) UpdateAvatar(ctx context.Context, id int, avatarFileID *int, avatarFileName *string) (*entity.User, error) {
+	updatedUser, err := r.queries.UpdateUserAvatar(ctx, db.UpdateUserAvatarParams{
+		ID:             int32(id),
+		AvatarFileID:   ptrToNullInt32(avatarFileID),
+		AvatarFileName: ptrToNullString(avatarFileName),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.mapDBUserToEntity(&updatedUser), nil
+}
+
+func (r *userRepository) ScheduleDeletion(ctx context.Context, id int, scheduledAt time.Time) (*entity.User, error) {
+	user, err := r.queries.ScheduleUserDeletion(ctx, db.ScheduleUserDeletionParams{
+		ID:                  int32(id),
+		DeletionScheduledAt: sql.NullTime{Time: scheduledAt, Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.mapDBUserToEntity(&user), nil
+}
+
+func (r *userRepository) CancelDeletion(ctx context.Context, id int) (*entity.User, error) {
+	user, err := r.queries.CancelUserDeletion(ctx, int32(id))
+	if err != nil {
+		return nil, err
+	}
+
+	return r.mapDBUserToEntity(&user), nil
+}
+
+func (r *userRepository) GetDueForDeletion(ctx context.Context, before time.Time) ([]entity.User, error) {
+	dbUsers, err := r.queries.GetUsersDueForDeletion(ctx, sql.NullTime{Time: before, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]entity.User, len(dbUsers))
+	for i, dbUser := range dbUsers {
+		users[i] = *r.mapDBUserToEntity(&dbUser)
+	}
+
+	return users, nil
+}
+
+func (r *userRepository) UpdateLastLogin(ctx context.Context, id int, loggedInAt time.Time) error {
+	return r.queries.UpdateUserLastLogin(ctx, db.UpdateUserLastLoginParams{
+		ID:          int32(id),
+		LastLoginAt: sql.NullTime{Time: loggedInAt, Valid: true},
+	})
+}
+
+func (r *userRepository) GetInactive(ctx context.Context, inactiveSince time.Time, paginationParams pagination.PaginationParams) ([]entity.User, int, error) {
+	dbUsers, err := r.queries.ListInactiveUsers(ctx, db.ListInactiveUsersParams{
+		Limit:         int32(paginationParams.Limit),
+		Offset:        int32(paginationParams.CalculateOffset()),
+		InactiveSince: inactiveSince,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	totalCount, err := r.queries.CountInactiveUsers(ctx, inactiveSince)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	users := make([]entity.User, len(dbUsers))
+	for i, dbUser := range dbUsers {
+		users[i] = *r.mapDBUserToEntity(&dbUser)
+	}
+
+	return users, int(totalCount), nil
+}
+
+// getAllByFilterExpression lists users matching a filter expression on top of the regular list filters
+func (r *userRepository) getAllByFilterExpression(ctx context.Context, expression string, filters userListFilters, sortField, sortOrder string, paginationParams pagination.PaginationParams) ([]entity.User, int, error) {
+	query := &filterQuery{}
+	filters.apply(query)
+	if err := query.expression(userFilterSchema, expression); err != nil {
+		return nil, 0, err
+	}
+
+	listQuery, args := query.list("users", userColumns, sortField, sortOrder, paginationParams.Limit, paginationParams.CalculateOffset())
+	dbUsers, err := r.queryUsers(ctx, listQuery, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var totalCount int
+	countQuery, countArgs := query.count("users")
+	if err := r.db.QueryRowContext(ctx, countQuery, countArgs...).Scan(&totalCount); err != nil {
+		return nil, 0, err
+	}
+
+	users := make([]entity.User, len(dbUsers))
+	for i, dbUser := range dbUsers {
+		users[i] = *r.mapDBUserToEntity(&dbUser)
+	}
+
+	return users, totalCount, nil
+}
+
+// queryUsers runs a query built at runtime that selects userColumns
+func (r *userRepository) queryUsers(ctx context.Context, query string, args ...interface{}) ([]db.Users, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []db.Users{}
+	for rows.Next() {
+		var u db.Users
+		if err := rows.Scan(
+			&u.ID,
+			&u.Name,
+			&u.Email,
+			&u.CreatedAt,
+			&u.UpdatedAt,
+			&u.PasswordHash,
+			&u.Role,
+			&u.EmailVerified,
+			&u.EmailVerificationToken,
+			&u.EmailVerificationExpiresAt,
+			&u.PasswordResetToken,
+			&u.PasswordResetExpiresAt,
+			&u.DeletionRequestedAt,
+			&u.DeletionScheduledAt,
+			&u.DisplayName,
+			&u.Bio,
+			&u.Locale,
+			&u.Timezone,
+			&u.Phone,
+			&u.Metadata,
+			&u.AvatarFileID,
+			&u.AvatarFileName,
+			&u.LastLoginAt,
+		); err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// userListFilters holds the filter arguments shared by the user list queries
+type userListFilters struct {
+	name            sql.NullString
+	email           sql.NullString
+	role            sql.NullString
+	emailVerified   sql.NullBool
+	createdAfter    sql.NullTime
+	createdBefore   sql.NullTime
+	lastLoginAfter  sql.NullTime
+	lastLoginBefore sql.NullTime
+	neverLoggedIn   sql.NullBool
+	search          sql.NullString
+}
+
+// newUserListFilters converts the request filters into nullable query arguments
+func newUserListFilters(paginationParams pagination.PaginationParams, filterParams pagination.FilterParams) userListFilters {
+	var filters userListFilters
+
+	if filterParams.Name != "" {
+		filters.name = sql.NullString{String: filterParams.Name, Valid: true}
+	}
+	if filterParams.Email != "" {
+		filters.email = sql.NullString{String: filterParams.Email, Valid: true}
+	}
+	if filterParams.Role != "" {
+		filters.role = sql.NullString{String: filterParams.Role, Valid: true}
+	}
+	if filterParams.EmailVerified != nil {
+		filters.emailVerified = sql.NullBool{Bool: *filterParams.EmailVerified, Valid: true}
+	}
+	if filterParams.CreatedAfter != "" {
+		if parsedTime, err := time.Parse(time.RFC3339, filterParams.CreatedAfter); err == nil {
+			filters.createdAfter = sql.NullTime{Time: parsedTime, Valid: true}
+		}
+	}
+	if filterParams.CreatedBefore != "" {
+		if parsedTime, err := time.Parse(time.RFC3339, filterParams.CreatedBefore); err == nil {
+			filters.createdBefore = sql.NullTime{Time: parsedTime, Valid: true}
+		}
+	}
+	if filterParams.LastLoginAfter != "" {
+		if parsedTime, err := time.Parse(time.RFC3339, filterParams.LastLoginAfter); err == nil {
+			filters.lastLoginAfter = sql.NullTime{Time: parsedTime, Valid: true}
+		}
+	}
+	if filterParams.LastLoginBefore != "" {
+		if parsedTime, err := time.Parse(time.RFC3339, filterParams.LastLoginBefore); err == nil {
+			filters.lastLoginBefore = sql.NullTime{Time: parsedTime, Valid: true}
+		}
+	}
+	if filterParams.NeverLoggedIn != nil {
+		filters.neverLoggedIn = sql.NullBool{Bool: *filterParams.NeverLoggedIn, Valid: true}
+	}
+	if paginationParams.Search != "" {
+		filters.search = sql.NullString{String: paginationParams.Search, Valid: true}
+	}
+
+	return filters
+}
+
+// apply adds the list filters to a query built at runtime, mirroring the static list queries
+func (f userListFilters) apply(query *filterQuery) {
+	if f.name.Valid {
+		query.where("name ILIKE '%' || " + query.bind(f.name.String) + "::text || '%'")
+	}
+	if f.email.Valid {
+		query.where("email ILIKE '%' || " + query.bind(f.email.String) + "::text || '%'")
+	}
+	if f.role.Valid {
+		query.where("role = " + query.bind(f.role.String))
+	}
+	if f.emailVerified.Valid {
+		query.where("email_verified = " + query.bind(f.emailVerified.Bool))
+	}
+	if f.createdAfter.Valid {
+		query.where("created_at >= " + query.bind(f.createdAfter.Time))
+	}
+	if f.createdBefore.Valid {
+		query.where("created_at <= " + query.bind(f.createdBefore.Time))
+	}
+	if f.lastLoginAfter.Valid {
+		query.where("last_login_at >= " + query.bind(f.lastLoginAfter.Time))
+	}
+	if f.lastLoginBefore.Valid {
+		query.where("last_login_at <= " + query.bind(f.lastLoginBefore.Time))
+	}
+	if f.neverLoggedIn.Valid {
+		query.where("(last_login_at IS NULL) = " + query.bind(f.neverLoggedIn.Bool))
+	}
+	if f.search.Valid {
+		search := query.bind(f.search.String)
+		query.where("(name ILIKE '%' || " + search + "::text || '%' OR email ILIKE '%' || " + search + "::text || '%')")
+	}
+}
+
+// userCursor builds the cursor pointing at a user in a keyset listing
+func userCursor(user *entity.User, keyset keysetArgs) *pagination.Cursor {
+	cursor := &pagination.Cursor{
+		Sort:  keyset.sortField,
+		Order: keyset.sortOrder,
+		ID:    user.ID,
+	}
+
+	switch keyset.sortField {
+	case "name":
+		cursor.Value = user.Name
+	case "email":
+		cursor.Value = user.Email
+	case "role":
+		cursor.Value = user.Role
+	case "created_at":
+		cursor.Value = formatCursorTime(user.CreatedAt)
+	}
+
+	return cursor
+}
+
+func (r *userRepository) mapDBUserToEntity(dbUser *db.Users) *entity.User {
+	return &entity.User{
+		ID:                         int(dbUser.ID),
+		Name:                       dbUser.Name,
+		Email:                      dbUser.Email,
+		PasswordHash:               dbUser.PasswordHash,
+		Role:                       dbUser.Role,
+		EmailVerified:              dbUser.EmailVerified,
+		EmailVerificationToken:     nullStringToPtr(dbUser.EmailVerificationToken),
+		EmailVerificationExpiresAt: nullTimeToPtr(dbUser.EmailVerificationExpiresAt),
+		PasswordResetToken:         nullStringToPtr(dbUser.PasswordResetToken),
+		PasswordResetExpiresAt:     nullTimeToPtr(dbUser.PasswordResetExpiresAt),
+		DisplayName:                nullStringToPtr(dbUser.DisplayName),
+		Bio:                        nullStringToPtr(dbUser.Bio),
+		Locale:                     nullStringToPtr(dbUser.Locale),
+		Timezone:                   nullStringToPtr(dbUser.Timezone),
+		Phone:                      nullStringToPtr(dbUser.Phone),
+		Metadata:                   rawMessageToMap(dbUser.Metadata),
+		AvatarFileID:               nullInt32ToPtr(dbUser.AvatarFileID),
+		AvatarFileName:             nullStringToPtr(dbUser.AvatarFileName),
+		LastLoginAt:                nullTimeToPtr(dbUser.LastLoginAt),
+		DeletionRequestedAt:        nullTimeToPtr(dbUser.DeletionRequestedAt),
+		DeletionScheduledAt:        nullTimeToPtr(dbUser.DeletionScheduledAt),
+		CreatedAt:                  dbUser.CreatedAt.Time,
+		UpdatedAt:                  dbUser.UpdatedAt.Time,
+	}
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	db "go-template/db/sqlc"
+	"go-template/internal/entity"
+)
+
+type UserSettingRepository interface {
+	GetByUserID(ctx context.Context, userID int) ([]entity.UserSetting, error)
+	Get(ctx context.Context, userID int, key string) (*entity.UserSetting, error)
+	Upsert(ctx context.Context, userID int, key string, value interface{}) (*entity.UserSetting, error)
+	Delete(ctx context.Context, userID int, key string) error
+}
+
+type userSettingRepository struct {
+	db      *sql.DB
+	queries *db.Queries
+}
+
+func NewUserSettingRepository(dbConn *sql.DB) UserSettingRepository {
+	return &userSettingRepository{
+		db:      dbConn,
+		queries: db.New(dbConn),
+	}
+}
+
+func (r *userSettingRepository) GetByUserID(ctx context.Context, userID int) ([]entity.UserSetting, error) {
+	dbSettings, err := r.queries.GetUserSettings(ctx, int32(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	settings := make([]entity.UserSetting, len(dbSettings))
+	for i, dbSetting := range dbSettings {
+		settings[i] = *r.mapDBUserSettingToEntity(&dbSetting)
+	}
+
+	return settings, nil
+}
+
+func (r *userSettingRepository) Get(ctx context.Context, userID int, key string) (*entity.UserSetting, error) {
+	setting, err := r.queries.GetUserSetting(ctx, db.GetUserSettingParams{
+		UserID: int32(userID),
+		Key:    key,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.mapDBUserSettingToEntity(&setting), nil
+}
+
+func (r *userSettingRepository) Upsert(ctx context.Context, userID int, key string, value interface{}) (*entity.UserSetting, error) {
+	rawValue, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	setting, err := r.queries.UpsertUserSetting(ctx, db.UpsertUserSettingParams{
+		UserID: int32(userID),
+		Key:    key,
+		Value:  rawValue,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.mapDBUserSettingToEntity(&setting), nil
+}
+
+func (r *userSettingRepository) Delete(ctx context.Context, userID int, key string) error {
+	return r.queries.DeleteUserSetting(ctx, db.DeleteUserSettingParams{
+		UserID: int32(userID),
+		Key:    key,
+	})
+}
+
+func (r *userSettingRepository) mapDBUserSettingToEntity(dbSetting *db.UserSettings) *entity.UserSetting {
+	var value interface{}
+	json.Unmarshal(dbSetting.Value, &value)
+
+	return &entity.UserSetting{
+		UserID:    int(dbSetting.UserID),
+		Key:       dbSetting.Key,
+		Value:     value,
+		CreatedAt: dbSetting.CreatedAt.Time,
+		UpdatedAt: dbSetting.UpdatedAt.Time,
+	}
+}
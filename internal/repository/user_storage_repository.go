@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	db "go-template/db/sqlc"
+	"go-template/internal/entity"
+)
+
+// ErrQuotaExceeded is returned by FileRepository when storing a file would take the usage of its owner
+// over their quota
+var ErrQuotaExceeded = errors.New("storage quota exceeded")
+
+// UserStorageRepository reads the storage usage of users, which FileRepository keeps up to date, and
+// manages their quota overrides
+type UserStorageRepository interface {
+	// GetByUserID returns the usage of a user; users without files get an empty usage
+	GetByUserID(ctx context.Context, userID int) (*entity.UserStorage, error)
+	// SetQuota overrides the quotas of a user; nil restores the quota of the user's role
+	SetQuota(ctx context.Context, userID int, quotaBytes *int64, quotaFiles *int) (*entity.UserStorage, error)
+}
+
+type userStorageRepository struct {
+	db      *sql.DB
+	queries *db.Queries
+}
+
+func NewUserStorageRepository(dbConn *sql.DB) UserStorageRepository {
+	return &userStorageRepository{
+		db:      dbConn,
+		queries: db.New(dbConn),
+	}
+}
+
+func (r *userStorageRepository) GetByUserID(ctx context.Context, userID int) (*entity.UserStorage, error) {
+	usage, err := r.queries.GetUserStorage(ctx, int32(userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return &entity.UserStorage{UserID: userID}, nil
+		}
+		return nil, err
+	}
+
+	return r.mapDBUserStorageToEntity(&usage), nil
+}
+
+func (r *userStorageRepository) SetQuota(ctx context.Context, userID int, quotaBytes *int64, quotaFiles *int) (*entity.UserStorage, error) {
+	params := db.SetUserStorageQuotaParams{UserID: int32(userID)}
+	if quotaBytes != nil {
+		params.QuotaBytes = sql.NullInt64{Int64: *quotaBytes, Valid: true}
+	}
+	if quotaFiles != nil {
+		params.QuotaFiles = sql.NullInt32{Int32: int32(*quotaFiles), Valid: true}
+	}
+
+	usage, err := r.queries.SetUserStorageQuota(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.mapDBUserStorageToEntity(&usage), nil
+}
+
+// addUsageWithinQuota adds to the usage of a user within the transaction of queries, returning
+// ErrQuotaExceeded instead when the usage would exceed the quota. The row of the user is locked by the
+// update, so concurrent uploads are checked against each other's usage.
+func addUsageWithinQuota(ctx context.Context, queries *db.Queries, userID int32, bytes int64, files int32, quota entity.StorageQuota) error {
+	if err := queries.CreateUserStorage(ctx, userID); err != nil {
+		return err
+	}
+
+	_, err := queries.AddUserStorageUsageWithinQuota(ctx, db.AddUserStorageUsageWithinQuotaParams{
+		UsedBytes:      bytes,
+		FileCount:      files,
+		UserID:         userID,
+		RoleQuotaBytes: quota.Bytes,
+		RoleQuotaFiles: int32(quota.Files),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrQuotaExceeded
+	}
+	return err
+}
+
+func (r *userStorageRepository) mapDBUserStorageToEntity(dbUsage *db.UserStorage) *entity.UserStorage {
+	usage := &entity.UserStorage{
+		UserID:    int(dbUsage.UserID),
+		UsedBytes: dbUsage.UsedBytes,
+		FileCount: int(dbUsage.FileCount),
+		UpdatedAt: dbUsage.UpdatedAt.Time,
+	}
+	if dbUsage.QuotaBytes.Valid {
+		usage.QuotaBytes = &dbUsage.QuotaBytes.Int64
+	}
+	if dbUsage.QuotaFiles.Valid {
+		quotaFiles := int(dbUsage.QuotaFiles.Int32)
+		usage.QuotaFiles = &quotaFiles
+	}
+	return usage
+}
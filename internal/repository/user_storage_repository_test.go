@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	db "go-template/db/sqlc"
+	"go-template/internal/entity"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/pressly/goose/v3"
+)
+
+// openTestDB migrates a schema of its own in the PostgreSQL database at TEST_DATABASE_URL and drops it
+// when the test ends. Tests using it are skipped when TEST_DATABASE_URL is not set.
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	admin, err := sql.Open("pgx", dsn)
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	t.Cleanup(func() { admin.Close() })
+
+	schema := fmt.Sprintf("test_%d", time.Now().UnixNano())
+	if _, err := admin.Exec("CREATE SCHEMA " + schema); err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+	t.Cleanup(func() { admin.Exec("DROP SCHEMA " + schema + " CASCADE") })
+
+	schemaURL, err := url.Parse(dsn)
+	if err != nil {
+		t.Fatalf("parse TEST_DATABASE_URL: %v", err)
+	}
+	query := schemaURL.Query()
+	query.Set("search_path", schema)
+	schemaURL.RawQuery = query.Encode()
+
+	dbConn, err := sql.Open("pgx", schemaURL.String())
+	if err != nil {
+		t.Fatalf("open schema: %v", err)
+	}
+	t.Cleanup(func() { dbConn.Close() })
+
+	if err := goose.SetDialect("postgres"); err != nil {
+		t.Fatalf("set goose dialect: %v", err)
+	}
+	if err := goose.Up(dbConn, "../../db/migrations"); err != nil {
+		t.Fatalf("run migrations: %v", err)
+	}
+	return dbConn
+}
+
+// createTestUser inserts a user with the given storage usage and quota overrides
+func createTestUser(t *testing.T, dbConn *sql.DB, usedBytes int64, fileCount int, quotaBytes *int64, quotaFiles *int) int32 {
+	t.Helper()
+
+	var userID int32
+	err := dbConn.QueryRow(`INSERT INTO users (name, email) VALUES ('Test', $1) RETURNING id`,
+		fmt.Sprintf("user%d@example.com", time.Now().UnixNano())).Scan(&userID)
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	if _, err := dbConn.Exec(`INSERT INTO user_storage (user_id, used_bytes, file_count, quota_bytes, quota_files) VALUES ($1, $2, $3, $4, $5)`,
+		userID, usedBytes, fileCount, quotaBytes, quotaFiles); err != nil {
+		t.Fatalf("create user storage: %v", err)
+	}
+	return userID
+}
+
+// storageUsage returns the recorded usage of a user
+func storageUsage(t *testing.T, dbConn *sql.DB, userID int32) (int64, int32) {
+	t.Helper()
+
+	usage, err := db.New(dbConn).GetUserStorage(context.Background(), userID)
+	if err != nil {
+		t.Fatalf("get user storage: %v", err)
+	}
+	return usage.UsedBytes, usage.FileCount
+}
+
+func TestAddUsageWithinQuota(t *testing.T) {
+	dbConn := openTestDB(t)
+	ctx := context.Background()
+	roleQuota := entity.StorageQuota{Bytes: 1000, Files: 10}
+	bytesOverride, filesOverride, unlimited := int64(2000), 1, int64(0)
+
+	tests := []struct {
+		name       string
+		usedBytes  int64
+		fileCount  int
+		quotaBytes *int64
+		quotaFiles *int
+		bytes      int64
+		files      int32
+		quota      entity.StorageQuota
+		wantErr    error
+	}{
+		{"fits the role quota", 400, 3, nil, nil, 600, 1, roleQuota, nil},
+		{"exceeds the role bytes", 400, 3, nil, nil, 601, 1, roleQuota, ErrQuotaExceeded},
+		{"exceeds the role files", 400, 10, nil, nil, 1, 1, roleQuota, ErrQuotaExceeded},
+		{"unlimited role", 1 << 40, 1 << 20, nil, nil, 1 << 30, 1, entity.StorageQuota{}, nil},
+		{"bytes override raises the role quota", 400, 3, &bytesOverride, nil, 1600, 1, roleQuota, nil},
+		{"bytes override keeps the role files", 400, 10, &bytesOverride, nil, 1, 1, roleQuota, ErrQuotaExceeded},
+		{"files override lowers the role quota", 0, 1, nil, &filesOverride, 1, 1, roleQuota, ErrQuotaExceeded},
+		{"unlimited override", 400, 3, &unlimited, nil, 1 << 30, 1, roleQuota, nil},
+		{"shrinking usage over the quota", 5000, 20, nil, nil, -100, -1, roleQuota, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userID := createTestUser(t, dbConn, tt.usedBytes, tt.fileCount, tt.quotaBytes, tt.quotaFiles)
+
+			err := addUsageWithinQuota(ctx, db.New(dbConn), userID, tt.bytes, tt.files, tt.quota)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("addUsageWithinQuota error %v, want %v", err, tt.wantErr)
+			}
+
+			wantBytes, wantFiles := tt.usedBytes, int32(tt.fileCount)
+			if tt.wantErr == nil {
+				wantBytes, wantFiles = wantBytes+tt.bytes, wantFiles+tt.files
+			}
+			if usedBytes, fileCount := storageUsage(t, dbConn, userID); usedBytes != wantBytes || fileCount != wantFiles {
+				t.Errorf("usage = %d bytes in %d files, want %d bytes in %d files", usedBytes, fileCount, wantBytes, wantFiles)
+			}
+		})
+	}
+}
+
+func TestAddUsageWithinQuotaCreatesUsage(t *testing.T) {
+	dbConn := openTestDB(t)
+	ctx := context.Background()
+
+	var userID int32
+	if err := dbConn.QueryRow(`INSERT INTO users (name, email) VALUES ('Test', 'new@example.com') RETURNING id`).Scan(&userID); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	if err := addUsageWithinQuota(ctx, db.New(dbConn), userID, 1001, 1, entity.StorageQuota{Bytes: 1000}); !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("addUsageWithinQuota over the quota: error %v, want %v", err, ErrQuotaExceeded)
+	}
+	if err := addUsageWithinQuota(ctx, db.New(dbConn), userID, 1000, 1, entity.StorageQuota{Bytes: 1000}); err != nil {
+		t.Fatalf("addUsageWithinQuota: %v", err)
+	}
+	if usedBytes, fileCount := storageUsage(t, dbConn, userID); usedBytes != 1000 || fileCount != 1 {
+		t.Errorf("usage = %d bytes in %d files, want 1000 bytes in 1 file", usedBytes, fileCount)
+	}
+}
+
+func TestAddUsageWithinQuotaConcurrently(t *testing.T) {
+	dbConn := openTestDB(t)
+	ctx := context.Background()
+	quota := entity.StorageQuota{Bytes: 1000}
+	userID := createTestUser(t, dbConn, 400, 0, nil, nil)
+
+	// Both uploads fit on their own, but not together
+	first, err := dbConn.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	defer first.Rollback()
+	if err := addUsageWithinQuota(ctx, db.New(dbConn).WithTx(first), userID, 500, 1, quota); err != nil {
+		t.Fatalf("first addUsageWithinQuota: %v", err)
+	}
+
+	// The second waits for the lock the first holds on the row of the user, then sees its usage
+	done := make(chan error, 1)
+	go func() {
+		second, err := dbConn.BeginTx(ctx, nil)
+		if err != nil {
+			done <- err
+			return
+		}
+		defer second.Rollback()
+		if err := addUsageWithinQuota(ctx, db.New(dbConn).WithTx(second), userID, 500, 1, quota); err != nil {
+			done <- err
+			return
+		}
+		done <- second.Commit()
+	}()
+
+	select {
+	case err := <-done:
+		t.Fatalf("second addUsageWithinQuota did not wait for the first transaction: %v", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	if err := first.Commit(); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	if err := <-done; !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("second addUsageWithinQuota error %v, want %v", err, ErrQuotaExceeded)
+	}
+	if usedBytes, fileCount := storageUsage(t, dbConn, userID); usedBytes != 900 || fileCount != 1 {
+		t.Errorf("usage = %d bytes in %d files, want 900 bytes in 1 file", usedBytes, fileCount)
+	}
+}
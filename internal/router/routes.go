@@ -15,7 +15,7 @@ import (
 	echoSwagger "github.com/swaggo/echo-swagger"
 )
 
-func SetupRoutes(e *echo.Echo, db *database.DB, userHandler *handler.UserHandler, fileHandler *handler.FileHandler, authHandler *handler.AuthHandler, jwtManager *jwt.JWTManager) {
+func SetupRoutes(e *echo.Echo, db *database.DB, userHandler *handler.UserHandler, fileHandler *handler.FileHandler, authHandler *handler.AuthHandler, accountHandler *handler.AccountHandler, userTransferHandler *handler.UserTransferHandler, userSettingHandler *handler.UserSettingHandler, uploadHandler *handler.UploadHandler, storageHandler *handler.StorageHandler, folderHandler *handler.FolderHandler, fileShareHandler *handler.FileShareHandler, fileBulkHandler *handler.FileBulkHandler, jwtManager *jwt.JWTManager) {
 	// Swagger documentation route
 	e.GET("/swagger/*", echoSwagger.WrapHandler)
 
@@ -49,20 +49,38 @@ func SetupRoutes(e *echo.Echo, db *database.DB, userHandler *handler.UserHandler
 	auth.GET("/reset-password", authHandler.ResetPassword)
 	auth.POST("/reset-password", authHandler.ResetPassword)
 	
-	// Initialize repository for RBAC and email verification middleware
+	// Initialize repository for RBAC, locale and email verification middleware
 	userRepo := repository.NewUserRepository(db.DB)
+	fileRepo := repository.NewFileRepository(db.DB)
+	fileGrantRepo := repository.NewFileGrantRepository(db.DB)
+
+	// Data export download is authorized by the emailed token (public); exports may take longer than
+	// the server timeouts to stream
+	api.GET("/users/me/export/download", accountHandler.DownloadDataExport, middleware.StreamTimeoutMiddleware())
 
 	// Protected auth routes
-	authProtected := auth.Group("", middleware.AuthMiddleware(jwtManager))
+	authProtected := auth.Group("", middleware.AuthMiddleware(jwtManager), middleware.UserLocaleMiddleware(userRepo))
 	authProtected.GET("/me", authHandler.GetProfile)
 
 	// Protected user routes with RBAC
-	users := api.Group("/users", middleware.AuthMiddleware(jwtManager))
+	users := api.Group("/users", middleware.AuthMiddleware(jwtManager), middleware.UserLocaleMiddleware(userRepo))
+
+	// Self-service account routes (any authenticated user, for their own account)
+	users.POST("/me/export", accountHandler.RequestDataExport)
+	users.DELETE("/me", accountHandler.DeleteAccount)
+	users.POST("/me/deletion/cancel", accountHandler.CancelAccountDeletion)
+	users.GET("/me/settings", userSettingHandler.GetSettings)
+	users.PATCH("/me/settings", userSettingHandler.UpdateSettings)
+	users.GET("/me/storage", storageHandler.GetMyStorage)
 	
 	// Admin-only user management
 	usersAdmin := users.Group("", middleware.AdminMiddleware(userRepo))
 	usersAdmin.POST("", userHandler.CreateUser)                    // Only admin can create users
 	usersAdmin.DELETE("/:id", userHandler.DeleteUser)              // Only admin can delete users
+	usersAdmin.POST("/import", userTransferHandler.ImportUsers)    // Only admin can bulk import users
+	usersAdmin.GET("/export", userTransferHandler.ExportUsers, middleware.StreamTimeoutMiddleware()) // Only admin can export users; streamed without a time limit while data moves
+	usersAdmin.GET("/reports/inactive", userHandler.GetInactiveUsers) // Only admin can view the inactive accounts report
+	usersAdmin.PUT("/:id/storage/quota", storageHandler.UpdateStorageQuota) // Only admin can override storage quotas
 	
 	// Moderator and admin can view all users
 	usersModerator := users.Group("", middleware.ModeratorOrAdminMiddleware(userRepo))
@@ -72,27 +90,84 @@ func SetupRoutes(e *echo.Echo, db *database.DB, userHandler *handler.UserHandler
 	usersSelf := users.Group("", middleware.SelfOrAdminMiddleware(userRepo))
 	usersSelf.GET("/:id", userHandler.GetUser)                     // User can view own profile, admin can view any
 	usersSelf.PUT("/:id", userHandler.UpdateUser)                  // User can update own profile, admin can update any
+	usersSelf.PUT("/:id/avatar", userHandler.UpdateAvatar)         // User can update own avatar, admin can update any
+	usersSelf.GET("/:id/login-history", userHandler.GetLoginHistory) // User can view own login history, admin can view any
+	usersSelf.GET("/:id/storage", storageHandler.GetUserStorage)   // User can view own storage usage, admin can view any
 
 	// Protected file routes with email verification warnings and RBAC
 	files := api.Group("/files", 
 		middleware.AuthMiddleware(jwtManager),
+		middleware.UserLocaleMiddleware(userRepo),
 		middleware.EmailVerificationMiddleware(userRepo))
 	
 	// All authenticated users can upload and view their own files
 	files.POST("/upload", fileHandler.UploadFile)                   // Any authenticated user can upload
 	files.GET("/my", fileHandler.GetMyFiles)                       // Any authenticated user can view their own files
+	files.GET("/shared", fileHandler.GetSharedFiles)               // Files other users shared with the current user
+	files.POST("/archive", fileBulkHandler.DownloadFileArchive, middleware.StreamTimeoutMiddleware()) // ZIP of files the user can read, streamed from storage without a time limit while data moves
+	files.POST("/bulk/category", fileBulkHandler.UpdateFilesCategory) // Per-file write access is checked by the service
 	
 	// Moderator and admin can view all files
 	filesModerator := files.Group("", middleware.ModeratorOrAdminMiddleware(userRepo))
 	filesModerator.GET("", fileHandler.GetAllFiles)                // Moderator+ can list all files
 	filesModerator.DELETE("/:id", fileHandler.DeleteFile)          // Moderator+ can delete any file
+	filesModerator.POST("/bulk/delete", fileBulkHandler.DeleteFiles) // Moderator+ can delete many files at once
 	
 	// Individual file operations - all authenticated users can access
-	files.GET("/:id", fileHandler.GetFile)                         // Any authenticated user can view file metadata
-	files.PUT("/:id", fileHandler.UpdateFile)                      // Any authenticated user can update (handler should check ownership)
-	files.GET("/:id/download", fileHandler.DownloadFile)           // Any authenticated user can download (handler should check permissions)
+	files.POST("/:id/move", fileHandler.MoveFile)                  // Owner can move the file into another of their folders
+
+	// Metadata and downloads need read access: the owner, users the file is shared with, and moderator+.
+	// File responses contain signed download URLs, so metadata is protected like the content.
+	filesRead := files.Group("", middleware.FileReadMiddleware(userRepo, fileRepo, fileGrantRepo))
+	filesRead.GET("/:id", fileHandler.GetFile)
+	filesRead.GET("/:id/download", fileHandler.DownloadFile)
+	filesRead.HEAD("/:id/download", fileHandler.DownloadFile)      // Size, ETag and range support without the content
+	filesRead.GET("/:id/variants/:name", fileHandler.GetFileVariant) // Thumbnails and other image variants, rendered on demand if missing
+	filesRead.HEAD("/:id/variants/:name", fileHandler.GetFileVariant)
+	filesRead.GET("/:id/versions", fileHandler.GetFileVersions)    // Version history, current version first
+	filesRead.GET("/:id/versions/:version/download", fileHandler.DownloadFileVersion)
+	filesRead.HEAD("/:id/versions/:version/download", fileHandler.DownloadFileVersion)
+
+	// Changes need write access: the owner, users the file is shared with for writing, and moderator+
+	filesWrite := files.Group("", middleware.FileWriteMiddleware(userRepo, fileRepo, fileGrantRepo))
+	filesWrite.PUT("/:id", fileHandler.UpdateFile)
+	filesWrite.PUT("/:id/content", fileHandler.UploadFileVersion)  // Upload new content; the previous content is kept as a version
+	filesWrite.POST("/:id/versions/:version/restore", fileHandler.RestoreFileVersion) // Make an earlier version current again, as a new version
+
+	// Sharing is managed by the owner of the file
+	files.POST("/:id/share-links", fileShareHandler.CreateShareLink)
+	files.GET("/:id/share-links", fileShareHandler.GetShareLinks)
+	files.DELETE("/:id/share-links/:link_id", fileShareHandler.RevokeShareLink)
+	files.GET("/:id/grants", fileShareHandler.GetFileGrants)
+	files.PUT("/:id/grants/:user_id", fileShareHandler.GrantFileAccess)
+	files.DELETE("/:id/grants/:user_id", fileShareHandler.RevokeFileAccess)
+
+	// Resumable uploads (tus protocol); uploads are only visible to the user who created them
+	api.OPTIONS("/files/uploads", uploadHandler.Options, middleware.TusMiddleware()) // Protocol discovery (public)
+	uploads := files.Group("/uploads", middleware.TusMiddleware())
+	uploads.POST("", uploadHandler.CreateUpload)
+	uploads.HEAD("/:id", uploadHandler.GetUploadOffset)
+	uploads.PATCH("/:id", uploadHandler.WriteChunk, middleware.StreamTimeoutMiddleware()) // Chunks may take longer than the server timeouts
+	uploads.DELETE("/:id", uploadHandler.TerminateUpload)
+
+	// Folders; users only see and change their own folder tree
+	folders := api.Group("/folders",
+		middleware.AuthMiddleware(jwtManager),
+		middleware.UserLocaleMiddleware(userRepo),
+		middleware.EmailVerificationMiddleware(userRepo))
+	folders.POST("", folderHandler.CreateFolder)
+	folders.GET("", folderHandler.GetFolders)           // Top level folders, or the subfolders of ?parent_id=
+	folders.GET("/:id", folderHandler.GetFolder)        // Including the folder, file and byte counts of the subtree
+	folders.PUT("/:id", folderHandler.RenameFolder)
+	folders.POST("/:id/move", folderHandler.MoveFolder)
+	folders.DELETE("/:id", folderHandler.DeleteFolder)  // Only empty folders unless ?recursive=true
+
+	// Share links are authorized by their token and optional password (public)
+	e.GET("/share/:token", fileShareHandler.DownloadSharedFile)
+	e.HEAD("/share/:token", fileShareHandler.DownloadSharedFile)
+	e.POST("/share/:token", fileShareHandler.DownloadSharedFile)
 
-	// Static file serving (public)
-	e.Static("/uploads", "uploads")
-	e.GET("/files/:filename", fileHandler.ServeFile)
+	// Files and avatar variants behind signed or public-category URLs, streamed from the storage backend
+	e.GET("/files/:id", fileHandler.ServeFile)
+	e.HEAD("/files/:id", fileHandler.ServeFile)
 }
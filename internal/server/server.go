@@ -16,6 +16,9 @@ import (
 	"go-template/internal/service"
 	"go-template/pkg/email"
 	"go-template/pkg/jwt"
+	"go-template/pkg/pagination"
+	"go-template/pkg/response"
+	"go-template/pkg/scanner"
 	"go-template/pkg/storage"
 	"go-template/pkg/validator"
 
@@ -59,10 +62,51 @@ func NewServer() (*http.Server, error) {
 	// Initialize Echo
 	e := echo.New()
 	e.HideBanner = true
+	e.HTTPErrorHandler = middleware.ErrorHandler()
+	// Client IPs bind signed file URLs and key rate limits, so forwarding headers are only believed
+	// when they come from a configured proxy
+	e.IPExtractor, err = middleware.ClientIPExtractor(cfg.Server.TrustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure trusted proxies: %w", err)
+	}
+	response.ConfigureErrors(response.ErrorOptions{
+		ProblemDetails: cfg.Errors.ProblemDetails,
+		TypeBaseURL:    cfg.Errors.TypeBaseURL,
+		// Causes of server errors are only shown to developers, never in production
+		ExposeInternalErrors: cfg.App.Debug && cfg.App.Environment != "production",
+	})
 
 	// Initialize dependencies
-	fileStorage := storage.NewFileStorage()
+	fileStorage, err := storage.New(&storage.Config{
+		Driver:    cfg.Storage.Driver,
+		LocalPath: cfg.Upload.UploadPath,
+		S3: storage.S3Config{
+			Endpoint:        cfg.Storage.S3Endpoint,
+			Region:          cfg.Storage.S3Region,
+			Bucket:          cfg.Storage.S3Bucket,
+			AccessKeyID:     cfg.Storage.S3AccessKeyID,
+			SecretAccessKey: cfg.Storage.S3SecretAccessKey,
+			UsePathStyle:    cfg.Storage.S3UsePathStyle,
+		},
+		URLs: storage.URLConfig{
+			BaseURL:  cfg.Upload.BaseURL,
+			Secret:   cfg.FileURL.Secret,
+			BindToIP: cfg.FileURL.BindToIP,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize file storage: %w", err)
+	}
+	// Uploads are only scanned for malware when a clamd address is configured
+	var fileScanner scanner.Scanner
+	if cfg.Scan.ClamdAddress != "" {
+		fileScanner, err = scanner.NewClamdScanner(cfg.Scan.ClamdAddress, cfg.Scan.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize file scanner: %w", err)
+		}
+	}
 	validatorInstance := validator.New()
+	pagination.SetCursorSecret(cfg.Pagination.CursorSecret)
 	jwtManager := jwt.NewJWTManager(
 		cfg.JWT.AccessSecret,
 		cfg.JWT.RefreshSecret,
@@ -73,6 +117,17 @@ func NewServer() (*http.Server, error) {
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(db.DB)
 	fileRepo := repository.NewFileRepository(db.DB)
+	dataExportRepo := repository.NewDataExportRepository(db.DB)
+	userSettingRepo := repository.NewUserSettingRepository(db.DB)
+	loginEventRepo := repository.NewLoginEventRepository(db.DB)
+	fileUploadRepo := repository.NewFileUploadRepository(db.DB)
+	fileBlobRepo := repository.NewFileBlobRepository(db.DB)
+	fileVariantRepo := repository.NewFileVariantRepository(db.DB)
+	fileVersionRepo := repository.NewFileVersionRepository(db.DB)
+	userStorageRepo := repository.NewUserStorageRepository(db.DB)
+	folderRepo := repository.NewFolderRepository(db.DB)
+	fileShareLinkRepo := repository.NewFileShareLinkRepository(db.DB)
+	fileGrantRepo := repository.NewFileGrantRepository(db.DB)
 
 	// Initialize email service
 	emailService := email.NewSMTPService(&email.Config{
@@ -85,14 +140,46 @@ func NewServer() (*http.Server, error) {
 	})
 
 	// Initialize services
-	userService := service.NewUserService(userRepo)
-	fileService := service.NewFileService(fileRepo, fileStorage, cfg)
-	authService := service.NewAuthService(userRepo, jwtManager, emailService, cfg)
+	imageVariantService := service.NewImageVariantService(fileVariantRepo, fileStorage, cfg)
+	fileScanService := service.NewFileScanService(fileRepo, fileVersionRepo, imageVariantService, fileScanner, fileStorage, cfg)
+	storageQuotaService := service.NewStorageQuotaService(userStorageRepo, userRepo, cfg)
+	fileService := service.NewFileService(fileRepo, fileVersionRepo, folderRepo, userRepo, fileBlobRepo, imageVariantService, fileScanService, storageQuotaService, fileStorage, cfg)
+	authService := service.NewAuthService(userRepo, loginEventRepo, jwtManager, emailService, fileStorage, cfg)
+	userTransferService := service.NewUserTransferService(userRepo, emailService, fileStorage, validatorInstance, cfg)
+	accountService := service.NewAccountService(userRepo, fileRepo, fileVersionRepo, dataExportRepo, fileUploadRepo, fileVariantRepo, fileBlobRepo, fileStorage, emailService, cfg)
+	userService := service.NewUserService(userRepo, loginEventRepo, fileService, accountService, fileStorage, cfg)
+	userSettingService := service.NewUserSettingService(userSettingRepo, userRepo)
+	folderService := service.NewFolderService(folderRepo, fileRepo, fileService)
+	fileShareService := service.NewFileShareService(fileRepo, fileShareLinkRepo, fileGrantRepo, userRepo, fileService, cfg)
+	fileBulkService := service.NewFileBulkService(fileRepo, fileGrantRepo, userRepo, fileBlobRepo, fileService, fileStorage)
+	uploadService := service.NewUploadService(fileUploadRepo, fileRepo, folderRepo, fileBlobRepo, fileScanService, storageQuotaService, fileStorage, cfg)
+
+	// Let the email service honour notification preferences
+	emailService.SetPreferenceChecker(userSettingService)
 
 	// Initialize handlers
 	userHandler := handler.NewUserHandler(userService, validatorInstance)
-	fileHandler := handler.NewFileHandler(fileService, validatorInstance)
+	fileHandler := handler.NewFileHandler(fileService, userService, validatorInstance)
 	authHandler := handler.NewAuthHandler(authService, validatorInstance)
+	accountHandler := handler.NewAccountHandler(accountService, validatorInstance)
+	userTransferHandler := handler.NewUserTransferHandler(userTransferService)
+	userSettingHandler := handler.NewUserSettingHandler(userSettingService)
+	uploadHandler := handler.NewUploadHandler(uploadService, validatorInstance)
+	storageHandler := handler.NewStorageHandler(storageQuotaService, validatorInstance)
+	folderHandler := handler.NewFolderHandler(folderService, validatorInstance)
+	fileShareHandler := handler.NewFileShareHandler(fileShareService, validatorInstance)
+	fileBulkHandler := handler.NewFileBulkHandler(fileBulkService, validatorInstance)
+
+	// Start background purge of scheduled account deletions and expired exports
+	accountService.StartCleanupWorker(cfg.Account.CleanupInterval)
+	// Unfinished resumable uploads are purged on the same schedule
+	uploadService.StartCleanupWorker(cfg.Account.CleanupInterval)
+	// Stored content is verified against its checksum and unreferenced content deleted
+	fileService.StartIntegrityWorker(cfg.Integrity.Interval)
+	// Thumbnails and other image variants are rendered after upload
+	imageVariantService.StartWorkers(cfg.Images.Workers)
+	// Uploads are scanned for malware before they can be downloaded
+	fileScanService.StartWorkers(cfg.Scan.Workers, cfg.Scan.RetryInterval)
 
 	// Initialize middleware
 	rateLimiter := middleware.NewRateLimiter(100, time.Minute) // 100 requests per minute
@@ -100,12 +187,14 @@ func NewServer() (*http.Server, error) {
 	// Setup middleware
 	e.Use(echoMiddleware.RequestID())
 	e.Use(middleware.RequestLoggerMiddleware())
+	e.Use(middleware.LocaleMiddleware())
+	e.Use(middleware.ClientIPMiddleware())
 	e.Use(echoMiddleware.Recover())
 	e.Use(middleware.CORSMiddleware())
 	e.Use(middleware.RateLimitMiddleware(rateLimiter))
 
 	// Setup routes
-	router.SetupRoutes(e, db, userHandler, fileHandler, authHandler, jwtManager)
+	router.SetupRoutes(e, db, userHandler, fileHandler, authHandler, accountHandler, userTransferHandler, userSettingHandler, uploadHandler, storageHandler, folderHandler, fileShareHandler, fileBulkHandler, jwtManager)
 
 	// Create HTTP server
 	httpServer := &http.Server{
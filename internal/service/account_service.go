@@ -0,0 +1,532 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"go-template/internal/config"
+	"go-template/internal/dto"
+	"go-template/internal/entity"
+	"go-template/internal/logger"
+	"go-template/internal/repository"
+	"go-template/pkg/apperror"
+	"go-template/pkg/email"
+	"go-template/pkg/i18n"
+	"go-template/pkg/storage"
+	"go-template/pkg/tokens"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrInvalidPassword          = apperror.New(apperror.KindUnauthorized, apperror.CodeInvalidPassword, "invalid password")
+	ErrDeletionAlreadyScheduled = apperror.New(apperror.KindConflict, apperror.CodeDeletionAlreadyScheduled, "account deletion is already scheduled")
+	ErrDeletionNotScheduled     = apperror.New(apperror.KindInvalid, apperror.CodeDeletionNotScheduled, "account deletion is not scheduled")
+	ErrDataExportInProgress     = apperror.New(apperror.KindConflict, apperror.CodeDataExportInProgress, "a data export is already in progress")
+	ErrInvalidDataExportToken   = apperror.New(apperror.KindNotFound, apperror.CodeInvalidDataExportToken, "invalid or expired data export link")
+)
+
+type AccountService interface {
+	RequestDataExport(ctx context.Context, userID int) (*dto.DataExportResponse, error)
+	OpenDataExport(ctx context.Context, token string) (io.ReadCloser, *storage.ObjectInfo, error)
+	RequestAccountDeletion(ctx context.Context, userID int, req dto.DeleteAccountRequest) (*dto.AccountDeletionResponse, error)
+	CancelAccountDeletion(ctx context.Context, userID int) (*dto.AccountDeletionResponse, error)
+	PurgeScheduledDeletions(ctx context.Context) error
+	// PurgeAccount deletes an account right away, like a scheduled deletion does: the files, versions,
+	// variants, uploads and exports of the user are deleted with it and their content is released
+	PurgeAccount(ctx context.Context, userID int) error
+	PurgeExpiredDataExports(ctx context.Context) error
+	StartCleanupWorker(interval time.Duration)
+}
+
+type accountService struct {
+	userRepo       repository.UserRepository
+	fileRepo       repository.FileRepository
+	versionRepo    repository.FileVersionRepository
+	dataExportRepo repository.DataExportRepository
+	uploadRepo     repository.FileUploadRepository
+	variantRepo    repository.FileVariantRepository
+	blobs          *blobStore
+	fileStorage    storage.FileStorage
+	emailService   email.Service
+	config         *config.Config
+}
+
+func NewAccountService(userRepo repository.UserRepository, fileRepo repository.FileRepository, versionRepo repository.FileVersionRepository, dataExportRepo repository.DataExportRepository, uploadRepo repository.FileUploadRepository, variantRepo repository.FileVariantRepository, blobRepo repository.FileBlobRepository, fileStorage storage.FileStorage, emailService email.Service, config *config.Config) AccountService {
+	return &accountService{
+		userRepo:       userRepo,
+		fileRepo:       fileRepo,
+		versionRepo:    versionRepo,
+		dataExportRepo: dataExportRepo,
+		uploadRepo:     uploadRepo,
+		variantRepo:    variantRepo,
+		blobs:          newBlobStore(blobRepo, fileStorage),
+		fileStorage:    fileStorage,
+		emailService:   emailService,
+		config:         config,
+	}
+}
+
+func (s *accountService) RequestDataExport(ctx context.Context, userID int) (*dto.DataExportResponse, error) {
+	logger.Info("Data export requested", zap.Int("user_id", userID))
+
+	// Only one export may be generated at a time per user; one interrupted by a restart does not count
+	if _, err := s.dataExportRepo.GetPendingByUserID(ctx, userID, time.Now().Add(-s.config.Account.ExportTimeout)); err == nil {
+		logger.Warn("Data export already in progress", zap.Int("user_id", userID))
+		return nil, ErrDataExportInProgress
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		logger.Error("Failed to check pending data exports", zap.Error(err))
+		return nil, err
+	}
+
+	// A concurrent request may have created one meanwhile, which the database refuses to duplicate. So
+	// does an interrupted export until it is failed, which is done here rather than waiting for the
+	// cleanup worker.
+	export, err := s.dataExportRepo.Create(ctx, userID)
+	if errors.Is(err, repository.ErrDataExportInProgress) {
+		if err := s.failStaleDataExports(ctx); err != nil {
+			return nil, err
+		}
+		export, err = s.dataExportRepo.Create(ctx, userID)
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrDataExportInProgress) {
+			logger.Warn("Data export already in progress", zap.Int("user_id", userID))
+			return nil, ErrDataExportInProgress
+		}
+		logger.Error("Failed to create data export", zap.Error(err))
+		return nil, err
+	}
+
+	// Generate the archive in the background; the user is notified by email
+	go s.generateDataExport(i18n.FromContext(ctx), export.ID, userID)
+
+	logger.Info("Data export queued", zap.Int("export_id", export.ID), zap.Int("user_id", userID))
+
+	return s.mapDataExportToResponse(export), nil
+}
+
+// OpenDataExport streams the archive of a completed export from the storage backend
+func (s *accountService) OpenDataExport(ctx context.Context, token string) (io.ReadCloser, *storage.ObjectInfo, error) {
+	if err := tokens.ValidateToken(token); err != nil {
+		return nil, nil, ErrInvalidDataExportToken
+	}
+
+	export, err := s.dataExportRepo.GetByToken(ctx, token)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Warn("Invalid data export token used")
+			return nil, nil, ErrInvalidDataExportToken
+		}
+		logger.Error("Failed to get data export by token", zap.Error(err))
+		return nil, nil, err
+	}
+
+	if export.Status != entity.DataExportStatusCompleted || tokens.IsTokenExpired(export.ExpiresAt) {
+		logger.Warn("Expired data export token used", zap.Int("export_id", export.ID))
+		return nil, nil, ErrInvalidDataExportToken
+	}
+
+	archive, info, err := s.fileStorage.Open(ctx, export.FilePath)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			logger.Warn("Data export archive missing from storage", zap.Int("export_id", export.ID))
+			return nil, nil, ErrInvalidDataExportToken
+		}
+		logger.Error("Failed to open data export archive", zap.Error(err), zap.Int("export_id", export.ID))
+		return nil, nil, err
+	}
+
+	return archive, info, nil
+}
+
+func (s *accountService) RequestAccountDeletion(ctx context.Context, userID int, req dto.DeleteAccountRequest) (*dto.AccountDeletionResponse, error) {
+	logger.Info("Account deletion requested", zap.Int("user_id", userID))
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		logger.Error("Failed to get user for deletion", zap.Error(err))
+		return nil, err
+	}
+
+	if user.DeletionScheduledAt != nil {
+		logger.Warn("Account deletion already scheduled", zap.Int("user_id", userID))
+		return nil, ErrDeletionAlreadyScheduled
+	}
+
+	// Require the current password to confirm such a destructive action
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
+		logger.Warn("Account deletion attempt with invalid password", zap.Int("user_id", userID))
+		return nil, ErrInvalidPassword
+	}
+
+	scheduledAt := time.Now().Add(s.config.Account.DeletionGracePeriod)
+	user, err = s.userRepo.ScheduleDeletion(ctx, userID, scheduledAt)
+	if err != nil {
+		logger.Error("Failed to schedule account deletion", zap.Error(err))
+		return nil, err
+	}
+
+	if err := s.emailService.SendAccountDeletionScheduledEmail(userLocale(ctx, user), user.Email, user.Name, scheduledAt); err != nil {
+		// Don't fail the request if email sending fails - just log it
+		logger.Error("Failed to send account deletion email", zap.Error(err))
+	}
+
+	logger.Info("Account deletion scheduled", zap.Int("user_id", userID), zap.Time("scheduled_at", scheduledAt))
+
+	return &dto.AccountDeletionResponse{
+		Message:             "Account deletion scheduled. You can cancel it until the scheduled date.",
+		DeletionScheduledAt: user.DeletionScheduledAt,
+	}, nil
+}
+
+func (s *accountService) CancelAccountDeletion(ctx context.Context, userID int) (*dto.AccountDeletionResponse, error) {
+	logger.Info("Account deletion cancellation requested", zap.Int("user_id", userID))
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		logger.Error("Failed to get user for deletion cancellation", zap.Error(err))
+		return nil, err
+	}
+
+	if user.DeletionScheduledAt == nil {
+		return nil, ErrDeletionNotScheduled
+	}
+
+	if _, err := s.userRepo.CancelDeletion(ctx, userID); err != nil {
+		logger.Error("Failed to cancel account deletion", zap.Error(err))
+		return nil, err
+	}
+
+	logger.Info("Account deletion cancelled", zap.Int("user_id", userID))
+
+	return &dto.AccountDeletionResponse{
+		Message: "Account deletion cancelled",
+	}, nil
+}
+
+// PurgeScheduledDeletions hard-deletes accounts whose grace period has elapsed
+func (s *accountService) PurgeScheduledDeletions(ctx context.Context) error {
+	users, err := s.userRepo.GetDueForDeletion(ctx, time.Now())
+	if err != nil {
+		logger.Error("Failed to get accounts due for deletion", zap.Error(err))
+		return err
+	}
+
+	for _, user := range users {
+		if err := s.purgeUser(ctx, &user); err != nil {
+			logger.Error("Failed to purge account", zap.Error(err), zap.Int("user_id", user.ID))
+			continue
+		}
+		logger.Info("Account permanently deleted", zap.Int("user_id", user.ID))
+	}
+
+	return nil
+}
+
+func (s *accountService) PurgeAccount(ctx context.Context, userID int) error {
+	logger.Info("Purging account", zap.Int("user_id", userID))
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Warn("User not found for purge", zap.Int("user_id", userID))
+			return ErrUserNotFound
+		}
+		logger.Error("Failed to get user for purge", zap.Error(err))
+		return err
+	}
+
+	if err := s.purgeUser(ctx, user); err != nil {
+		logger.Error("Failed to purge account", zap.Error(err), zap.Int("user_id", userID))
+		return err
+	}
+
+	logger.Info("Account permanently deleted", zap.Int("user_id", userID))
+
+	return nil
+}
+
+// PurgeExpiredDataExports removes export archives whose download link has expired, and marks exports
+// that have been generating for longer than the export timeout as failed. Exports are generated in
+// the background, so those interrupted by a restart would otherwise stay in progress.
+func (s *accountService) PurgeExpiredDataExports(ctx context.Context) error {
+	if err := s.failStaleDataExports(ctx); err != nil {
+		return err
+	}
+
+	exports, err := s.dataExportRepo.GetExpired(ctx, time.Now())
+	if err != nil {
+		logger.Error("Failed to get expired data exports", zap.Error(err))
+		return err
+	}
+
+	for _, export := range exports {
+		// Links expired when exports moved into storage have no archive
+		if export.FilePath != "" {
+			if err := s.fileStorage.Delete(ctx, export.FilePath); err != nil {
+				logger.Error("Failed to remove expired data export archive", zap.Error(err), zap.Int("export_id", export.ID))
+				continue
+			}
+		}
+		if err := s.dataExportRepo.Delete(ctx, export.ID); err != nil {
+			logger.Error("Failed to delete expired data export", zap.Error(err), zap.Int("export_id", export.ID))
+		}
+	}
+
+	return nil
+}
+
+// failStaleDataExports marks the exports that have been generating for longer than the export timeout
+// as failed
+func (s *accountService) failStaleDataExports(ctx context.Context) error {
+	stale, err := s.dataExportRepo.FailStale(ctx, time.Now().Add(-s.config.Account.ExportTimeout), "export was interrupted")
+	if err != nil {
+		logger.Error("Failed to fail stale data exports", zap.Error(err))
+		return err
+	}
+	for _, export := range stale {
+		logger.Warn("Data export was interrupted", zap.Int("export_id", export.ID), zap.Int("user_id", export.UserID))
+	}
+	return nil
+}
+
+// StartCleanupWorker periodically purges scheduled deletions and expired exports
+func (s *accountService) StartCleanupWorker(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			ctx := context.Background()
+			s.PurgeScheduledDeletions(ctx)
+			s.PurgeExpiredDataExports(ctx)
+		}
+	}()
+}
+
+func (s *accountService) purgeUser(ctx context.Context, user *entity.User) error {
+	files, err := s.fileRepo.GetByUserID(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	versions, err := s.versionRepo.GetByUserID(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	exports, err := s.dataExportRepo.GetByUserID(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	chunkKeys, err := s.uploadRepo.GetChunkKeysByUserID(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	variantKeys, err := s.variantRepo.GetKeysByUserID(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	// Rows for files, file versions, image variants, exports and unfinished uploads are removed by ON DELETE CASCADE
+	if err := s.userRepo.Delete(ctx, user.ID); err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if err := s.blobs.deleteFileContent(ctx, &file); err != nil {
+			logger.Warn("Failed to delete file of purged account", zap.Error(err), zap.Int("file_id", file.ID))
+		}
+		if isImage(file.MimeType) {
+			deleteAvatarVariants(ctx, s.fileStorage, file.FileName)
+		}
+	}
+	for _, version := range versions {
+		if err := s.blobs.deleteContent(ctx, version.Checksum, version.FilePath); err != nil {
+			logger.Warn("Failed to delete file version of purged account", zap.Error(err), zap.Int("file_id", version.FileID), zap.Int("version", version.Version))
+		}
+	}
+	for _, export := range exports {
+		if export.FilePath == "" {
+			continue
+		}
+		if err := s.fileStorage.Delete(ctx, export.FilePath); err != nil {
+			logger.Warn("Failed to delete data export of purged account", zap.Error(err), zap.Int("export_id", export.ID))
+		}
+	}
+	for _, key := range chunkKeys {
+		if err := s.fileStorage.Delete(ctx, key); err != nil {
+			logger.Warn("Failed to delete upload chunk of purged account", zap.Error(err), zap.String("key", key))
+		}
+	}
+	for _, key := range variantKeys {
+		if err := s.fileStorage.Delete(ctx, key); err != nil {
+			logger.Warn("Failed to delete image variant of purged account", zap.Error(err), zap.String("key", key))
+		}
+	}
+
+	return nil
+}
+
+func (s *accountService) generateDataExport(locale string, exportID, userID int) {
+	// The request is over by the time the export is ready, so only its locale is carried over
+	ctx := i18n.WithLocale(context.Background(), locale)
+	logger.Info("Generating data export", zap.Int("export_id", exportID), zap.Int("user_id", userID))
+
+	if err := s.dataExportRepo.MarkProcessing(ctx, exportID); err != nil {
+		logger.Error("Failed to mark data export as processing", zap.Error(err))
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		s.failDataExport(ctx, exportID, err)
+		return
+	}
+
+	archiveKey, err := s.buildExportArchive(ctx, exportID, user)
+	if err != nil {
+		s.failDataExport(ctx, exportID, err)
+		return
+	}
+
+	downloadToken, err := tokens.GenerateVerificationToken()
+	if err != nil {
+		s.fileStorage.Delete(ctx, archiveKey)
+		s.failDataExport(ctx, exportID, err)
+		return
+	}
+
+	expiresAt := time.Now().Add(s.config.Account.ExportLinkTTL)
+	if _, err := s.dataExportRepo.Complete(ctx, exportID, archiveKey, downloadToken, expiresAt); err != nil {
+		s.fileStorage.Delete(ctx, archiveKey)
+		s.failDataExport(ctx, exportID, err)
+		return
+	}
+
+	downloadURL := fmt.Sprintf("%s/api/v1/users/me/export/download?token=%s", s.config.Email.BaseURL, downloadToken)
+	if err := s.emailService.SendDataExportEmail(userLocale(ctx, user), user.Email, user.Name, downloadURL, expiresAt); err != nil {
+		logger.Error("Failed to send data export email", zap.Error(err), zap.Int("export_id", exportID))
+	}
+
+	logger.Info("Data export generated successfully", zap.Int("export_id", exportID), zap.Int("user_id", userID))
+}
+
+// buildExportArchive writes the user's profile, file metadata and uploaded files into a ZIP archive
+// and stores it, returning its storage key
+func (s *accountService) buildExportArchive(ctx context.Context, exportID int, user *entity.User) (string, error) {
+	files, err := s.fileRepo.GetByUserID(ctx, user.ID)
+	if err != nil {
+		return "", err
+	}
+
+	// The archive is assembled in a scratch file because storage needs its size up front
+	archive, err := os.CreateTemp("", "export-*.zip")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(archive.Name())
+	defer archive.Close()
+
+	zipWriter := zip.NewWriter(archive)
+
+	if err := writeJSONToZip(zipWriter, "profile.json", user); err != nil {
+		return "", err
+	}
+	if err := writeJSONToZip(zipWriter, "files.json", files); err != nil {
+		return "", err
+	}
+
+	for _, file := range files {
+		// Quarantined and unscanned content stays out; files.json still lists the file and its scan status
+		if file.ScanStatus != entity.FileScanStatusClean {
+			continue
+		}
+		name := fmt.Sprintf("files/%d-%s", file.ID, filepath.Base(file.OriginalName))
+		if err := s.copyFileToZip(ctx, zipWriter, name, file.FilePath); err != nil {
+			// A missing blob should not prevent the rest of the data from being exported
+			logger.Warn("Failed to add file to data export", zap.Error(err), zap.Int("file_id", file.ID))
+		}
+	}
+
+	if err := zipWriter.Close(); err != nil {
+		return "", err
+	}
+
+	size, err := archive.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return "", err
+	}
+	if _, err := archive.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	archiveKey := path.Join(s.config.Account.ExportPath, fmt.Sprintf("export-%d-%d.zip", user.ID, exportID))
+	if err := s.fileStorage.Put(ctx, archiveKey, archive, size, "application/zip"); err != nil {
+		return "", err
+	}
+
+	return archiveKey, nil
+}
+
+func (s *accountService) copyFileToZip(ctx context.Context, zipWriter *zip.Writer, name, key string) error {
+	src, _, err := s.fileStorage.Open(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := zipWriter.Create(name)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(dst, src)
+	return err
+}
+
+func (s *accountService) failDataExport(ctx context.Context, exportID int, cause error) {
+	logger.Error("Failed to generate data export", zap.Error(cause), zap.Int("export_id", exportID))
+	if err := s.dataExportRepo.Fail(ctx, exportID, cause.Error()); err != nil {
+		logger.Error("Failed to mark data export as failed", zap.Error(err))
+	}
+}
+
+func (s *accountService) mapDataExportToResponse(export *entity.DataExport) *dto.DataExportResponse {
+	return &dto.DataExportResponse{
+		ID:          export.ID,
+		Status:      export.Status,
+		ExpiresAt:   export.ExpiresAt,
+		CompletedAt: export.CompletedAt,
+		CreatedAt:   export.CreatedAt,
+	}
+}
+
+func writeJSONToZip(zipWriter *zip.Writer, name string, v interface{}) error {
+	w, err := zipWriter.Create(name)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go-template/internal/config"
+	"go-template/internal/entity"
+	"go-template/internal/repository"
+	"go-template/pkg/storage"
+)
+
+// fakeDataExportRepository holds the exports of user 1 and refuses to create exports as if another
+// one of the user were in progress
+type fakeDataExportRepository struct {
+	repository.DataExportRepository
+	exports    []entity.DataExport
+	creates    int
+	staleFails int
+}
+
+func (r *fakeDataExportRepository) GetByUserID(ctx context.Context, userID int) ([]entity.DataExport, error) {
+	return r.exports, nil
+}
+
+func (r *fakeDataExportRepository) GetPendingByUserID(ctx context.Context, userID int, updatedAfter time.Time) (*entity.DataExport, error) {
+	return nil, sql.ErrNoRows
+}
+
+func (r *fakeDataExportRepository) Create(ctx context.Context, userID int) (*entity.DataExport, error) {
+	r.creates++
+	return nil, repository.ErrDataExportInProgress
+}
+
+func (r *fakeDataExportRepository) FailStale(ctx context.Context, before time.Time, errorMessage string) ([]entity.DataExport, error) {
+	r.staleFails++
+	return nil, nil
+}
+
+func TestRequestDataExportConcurrently(t *testing.T) {
+	exports := &fakeDataExportRepository{}
+	s := &accountService{
+		dataExportRepo: exports,
+		config:         &config.Config{Account: config.AccountConfig{ExportTimeout: time.Hour}},
+	}
+
+	// The export created by a concurrent request is not stale, so the retry is refused too
+	if _, err := s.RequestDataExport(context.Background(), 1); !errors.Is(err, ErrDataExportInProgress) {
+		t.Fatalf("RequestDataExport error %v, want %v", err, ErrDataExportInProgress)
+	}
+	if exports.staleFails != 1 || exports.creates != 2 {
+		t.Errorf("failed stale exports %d times and created %d, want once before retrying the creation", exports.staleFails, exports.creates)
+	}
+}
+
+// fakeAccountUserRepository looks users up by ID and deletes them
+type fakeAccountUserRepository struct {
+	repository.UserRepository
+	users map[int]*entity.User
+}
+
+func (r *fakeAccountUserRepository) GetByID(ctx context.Context, id int) (*entity.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return user, nil
+}
+
+func (r *fakeAccountUserRepository) Delete(ctx context.Context, id int) error {
+	delete(r.users, id)
+	return nil
+}
+
+// fakeAccountVersionRepository holds the earlier versions of the files of user 1
+type fakeAccountVersionRepository struct {
+	repository.FileVersionRepository
+	versions []entity.FileVersion
+}
+
+func (r *fakeAccountVersionRepository) GetByUserID(ctx context.Context, userID int) ([]entity.FileVersion, error) {
+	return r.versions, nil
+}
+
+// fakeAccountUploadRepository holds the chunk keys of the unfinished uploads of user 1
+type fakeAccountUploadRepository struct {
+	repository.FileUploadRepository
+	keys []string
+}
+
+func (r *fakeAccountUploadRepository) GetChunkKeysByUserID(ctx context.Context, userID int) ([]string, error) {
+	return r.keys, nil
+}
+
+// fakeAccountVariantRepository holds the variant keys of the files of user 1
+type fakeAccountVariantRepository struct {
+	repository.FileVariantRepository
+	keys []string
+}
+
+func (r *fakeAccountVariantRepository) GetKeysByUserID(ctx context.Context, userID int) ([]string, error) {
+	return r.keys, nil
+}
+
+func TestPurgeAccount(t *testing.T) {
+	ctx := context.Background()
+	blobs, blobRepo, fileStorage := newTestBlobStore(t)
+	// The content of file 10 is shared with a file of another user
+	blobRepo.blobs["shared"] = &entity.FileBlob{Checksum: "shared", RefCount: 2, Stored: true}
+	blobRepo.blobs["version"] = &entity.FileBlob{Checksum: "version", RefCount: 1, Stored: true}
+
+	keys := []string{"chunks/upload-1", "variants/10-small.png", "exports/export-1.zip", "legacy.pdf"}
+	for _, key := range keys {
+		if err := fileStorage.Put(ctx, key, strings.NewReader("content"), 7, "application/octet-stream"); err != nil {
+			t.Fatalf("Put(%s): %v", key, err)
+		}
+	}
+
+	users := &fakeAccountUserRepository{users: map[int]*entity.User{1: {ID: 1}}}
+	files := newFakeFileRepository(&entity.File{ID: 10, UploadedBy: 1, Checksum: "shared"}, &entity.File{ID: 11, UploadedBy: 1, FilePath: "legacy.pdf"})
+	s := &accountService{
+		userRepo:       users,
+		fileRepo:       files,
+		versionRepo:    &fakeAccountVersionRepository{versions: []entity.FileVersion{{FileID: 10, Version: 1, Checksum: "version"}}},
+		dataExportRepo: &fakeDataExportRepository{exports: []entity.DataExport{{ID: 1, UserID: 1, FilePath: "exports/export-1.zip"}, {ID: 2, UserID: 1}}},
+		uploadRepo:     &fakeAccountUploadRepository{keys: []string{"chunks/upload-1"}},
+		variantRepo:    &fakeAccountVariantRepository{keys: []string{"variants/10-small.png"}},
+		blobs:          blobs,
+		fileStorage:    fileStorage,
+	}
+
+	if err := s.PurgeAccount(ctx, 1); err != nil {
+		t.Fatalf("PurgeAccount: %v", err)
+	}
+
+	if _, ok := users.users[1]; ok {
+		t.Error("user was not deleted")
+	}
+	if refs := blobRepo.blobs["shared"].RefCount; refs != 1 {
+		t.Errorf("shared content has %d references, want the one of the other user", refs)
+	}
+	if refs := blobRepo.blobs["version"].RefCount; refs != 0 {
+		t.Errorf("version content has %d references, want 0", refs)
+	}
+	for _, key := range keys {
+		if _, _, err := fileStorage.Open(ctx, key); !errors.Is(err, storage.ErrObjectNotFound) {
+			t.Errorf("Open(%s) after the purge: error %v, want %v", key, err, storage.ErrObjectNotFound)
+		}
+	}
+
+	if err := s.PurgeAccount(ctx, 1); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("PurgeAccount of a deleted user: error %v, want %v", err, ErrUserNotFound)
+	}
+}
@@ -6,10 +6,13 @@ import (
 	"errors"
 	"go-template/internal/config"
 	"go-template/internal/dto"
+	"go-template/internal/entity"
 	"go-template/internal/logger"
 	"go-template/internal/repository"
+	"go-template/pkg/apperror"
 	"go-template/pkg/email"
 	"go-template/pkg/jwt"
+	"go-template/pkg/storage"
 	"go-template/pkg/tokens"
 	"time"
 
@@ -18,17 +21,18 @@ import (
 )
 
 var (
-	ErrInvalidCredentials         = errors.New("invalid email or password")
-	ErrUserAlreadyExists          = errors.New("user with this email already exists")
-	ErrInvalidVerificationToken   = errors.New("invalid or expired verification token")
-	ErrEmailAlreadyVerified       = errors.New("email is already verified")
-	ErrInvalidPasswordResetToken  = errors.New("invalid or expired password reset token")
-	ErrUserNotFound               = errors.New("user not found")
+	ErrInvalidCredentials        = apperror.New(apperror.KindUnauthorized, apperror.CodeInvalidCredentials, "invalid email or password")
+	ErrInvalidRefreshToken       = apperror.New(apperror.KindUnauthorized, apperror.CodeInvalidRefreshToken, "invalid refresh token")
+	ErrUserAlreadyExists         = apperror.New(apperror.KindConflict, apperror.CodeUserAlreadyExists, "user with this email already exists")
+	ErrInvalidVerificationToken  = apperror.New(apperror.KindInvalid, apperror.CodeInvalidVerificationToken, "invalid or expired verification token")
+	ErrEmailAlreadyVerified      = apperror.New(apperror.KindConflict, apperror.CodeEmailAlreadyVerified, "email is already verified")
+	ErrInvalidPasswordResetToken = apperror.New(apperror.KindInvalid, apperror.CodeInvalidPasswordResetToken, "invalid or expired password reset token")
+	ErrUserNotFound              = apperror.New(apperror.KindNotFound, apperror.CodeUserNotFound, "user not found")
 )
 
 type AuthService interface {
 	Register(ctx context.Context, req dto.RegisterRequest) (*dto.AuthResponse, error)
-	Login(ctx context.Context, req dto.LoginRequest) (*dto.AuthResponse, error)
+	Login(ctx context.Context, req dto.LoginRequest, client dto.ClientInfo) (*dto.AuthResponse, error)
 	RefreshToken(ctx context.Context, req dto.RefreshTokenRequest) (*dto.TokenResponse, error)
 	GetUserProfile(ctx context.Context, userID int) (*dto.UserProfileResponse, error)
 	VerifyEmail(ctx context.Context, req dto.VerifyEmailRequest) (*dto.EmailVerificationResponse, error)
@@ -38,18 +42,22 @@ type AuthService interface {
 }
 
 type authService struct {
-	userRepo     repository.UserRepository
-	jwtManager   *jwt.JWTManager
-	emailService email.Service
-	config       *config.Config
+	userRepo       repository.UserRepository
+	loginEventRepo repository.LoginEventRepository
+	jwtManager     *jwt.JWTManager
+	emailService   email.Service
+	fileStorage    storage.FileStorage
+	config         *config.Config
 }
 
-func NewAuthService(userRepo repository.UserRepository, jwtManager *jwt.JWTManager, emailService email.Service, config *config.Config) AuthService {
+func NewAuthService(userRepo repository.UserRepository, loginEventRepo repository.LoginEventRepository, jwtManager *jwt.JWTManager, emailService email.Service, fileStorage storage.FileStorage, config *config.Config) AuthService {
 	return &authService{
-		userRepo:     userRepo,
-		jwtManager:   jwtManager,
-		emailService: emailService,
-		config:       config,
+		userRepo:       userRepo,
+		loginEventRepo: loginEventRepo,
+		jwtManager:     jwtManager,
+		emailService:   emailService,
+		fileStorage:    fileStorage,
+		config:         config,
 	}
 }
 
@@ -88,7 +96,7 @@ func (s *authService) Register(ctx context.Context, req dto.RegisterRequest) (*d
 	}
 
 	// Send verification email
-	if err := s.emailService.SendVerificationEmail(user.Email, user.Name, verificationToken); err != nil {
+	if err := s.emailService.SendVerificationEmail(userLocale(ctx, user), user.Email, user.Name, verificationToken); err != nil {
 		logger.Error("Failed to send verification email", zap.Error(err))
 		// Don't fail registration if email sending fails - just log it
 		logger.Warn("User registered but verification email not sent", zap.Int("user_id", user.ID))
@@ -106,22 +114,14 @@ func (s *authService) Register(ctx context.Context, req dto.RegisterRequest) (*d
 	logger.Info("User registered successfully", zap.Int("user_id", user.ID))
 
 	return &dto.AuthResponse{
-		User: dto.UserResponse{
-			ID:            user.ID,
-			Name:          user.Name,
-			Email:         user.Email,
-			Role:          user.Role,
-			EmailVerified: user.EmailVerified,
-			CreatedAt:     user.CreatedAt,
-			UpdatedAt:     user.UpdatedAt,
-		},
+		User:         *newUserResponse(ctx, user, s.fileStorage, s.config),
 		AccessToken:  tokenPair.AccessToken,
 		RefreshToken: tokenPair.RefreshToken,
 		ExpiresAt:    time.Now().Add(s.config.JWT.AccessExpiresIn),
 	}, nil
 }
 
-func (s *authService) Login(ctx context.Context, req dto.LoginRequest) (*dto.AuthResponse, error) {
+func (s *authService) Login(ctx context.Context, req dto.LoginRequest, client dto.ClientInfo) (*dto.AuthResponse, error) {
 	logger.Info("User login attempt", zap.String("email", req.Email))
 
 	// Get user by email
@@ -129,6 +129,7 @@ func (s *authService) Login(ctx context.Context, req dto.LoginRequest) (*dto.Aut
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			logger.Warn("Login attempt with non-existent email", zap.String("email", req.Email))
+			s.recordLoginEvent(ctx, nil, req.Email, entity.LoginFailureUserNotFound, client)
 			return nil, ErrInvalidCredentials
 		}
 		logger.Error("Failed to get user", zap.Error(err))
@@ -138,9 +139,19 @@ func (s *authService) Login(ctx context.Context, req dto.LoginRequest) (*dto.Aut
 	// Verify password
 	if err := s.verifyPassword(req.Password, user.PasswordHash); err != nil {
 		logger.Warn("Login attempt with invalid password", zap.String("email", req.Email))
+		s.recordLoginEvent(ctx, &user.ID, req.Email, entity.LoginFailureInvalidPassword, client)
 		return nil, ErrInvalidCredentials
 	}
 
+	// Record the successful login; failures here must not block the user from signing in
+	now := time.Now()
+	if err := s.userRepo.UpdateLastLogin(ctx, user.ID, now); err != nil {
+		logger.Error("Failed to update last login", zap.Error(err), zap.Int("user_id", user.ID))
+	} else {
+		user.LastLoginAt = &now
+	}
+	s.recordLoginEvent(ctx, &user.ID, req.Email, "", client)
+
 	// Generate token pair
 	tokenPair, err := s.jwtManager.GenerateTokenPair(user.ID, user.Email)
 	if err != nil {
@@ -151,15 +162,7 @@ func (s *authService) Login(ctx context.Context, req dto.LoginRequest) (*dto.Aut
 	logger.Info("User logged in successfully", zap.Int("user_id", user.ID))
 
 	return &dto.AuthResponse{
-		User: dto.UserResponse{
-			ID:            user.ID,
-			Name:          user.Name,
-			Email:         user.Email,
-			Role:          user.Role,
-			EmailVerified: user.EmailVerified,
-			CreatedAt:     user.CreatedAt,
-			UpdatedAt:     user.UpdatedAt,
-		},
+		User:         *newUserResponse(ctx, user, s.fileStorage, s.config),
 		AccessToken:  tokenPair.AccessToken,
 		RefreshToken: tokenPair.RefreshToken,
 		ExpiresAt:    time.Now().Add(s.config.JWT.AccessExpiresIn),
@@ -173,7 +176,7 @@ func (s *authService) RefreshToken(ctx context.Context, req dto.RefreshTokenRequ
 	newAccessToken, err := s.jwtManager.RefreshAccessToken(req.RefreshToken)
 	if err != nil {
 		logger.Warn("Failed to refresh token", zap.Error(err))
-		return nil, errors.New("invalid refresh token")
+		return nil, ErrInvalidRefreshToken
 	}
 
 	logger.Info("Token refreshed successfully")
@@ -191,7 +194,7 @@ func (s *authService) GetUserProfile(ctx context.Context, userID int) (*dto.User
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			logger.Warn("Profile request for non-existent user", zap.Int("user_id", userID))
-			return nil, errors.New("user not found")
+			return nil, ErrUserNotFound
 		}
 		logger.Error("Failed to get user profile", zap.Error(err))
 		return nil, errors.New("failed to get user profile")
@@ -208,6 +211,25 @@ func (s *authService) GetUserProfile(ctx context.Context, userID int) (*dto.User
 	}, nil
 }
 
+// recordLoginEvent stores a login attempt in the login history; an empty failureReason marks a successful login
+func (s *authService) recordLoginEvent(ctx context.Context, userID *int, email, failureReason string, client dto.ClientInfo) {
+	event := &entity.LoginEvent{
+		UserID:    userID,
+		Email:     email,
+		Success:   failureReason == "",
+		Method:    entity.LoginMethodPassword,
+		IPAddress: stringToPtr(client.IPAddress),
+		UserAgent: stringToPtr(client.UserAgent),
+	}
+	if failureReason != "" {
+		event.FailureReason = &failureReason
+	}
+
+	if _, err := s.loginEventRepo.Create(ctx, event); err != nil {
+		logger.Error("Failed to record login event", zap.Error(err), zap.String("email", email))
+	}
+}
+
 // hashPassword hashes a plain text password using bcrypt
 func (s *authService) hashPassword(password string) (string, error) {
 	// Use bcrypt with cost 12 for strong security
@@ -290,7 +312,7 @@ func (s *authService) ResendVerificationEmail(ctx context.Context, req dto.Resen
 			return &dto.EmailVerificationResponse{
 				Message: "User not found",
 				Success: false,
-			}, errors.New("user not found")
+			}, ErrUserNotFound
 		}
 		logger.Error("Failed to get user by email", zap.Error(err))
 		return &dto.EmailVerificationResponse{
@@ -331,7 +353,7 @@ func (s *authService) ResendVerificationEmail(ctx context.Context, req dto.Resen
 	}
 
 	// Send verification email
-	if err := s.emailService.SendVerificationEmail(user.Email, user.Name, verificationToken); err != nil {
+	if err := s.emailService.SendVerificationEmail(userLocale(ctx, user), user.Email, user.Name, verificationToken); err != nil {
 		logger.Error("Failed to send verification email", zap.Error(err))
 		return &dto.EmailVerificationResponse{
 			Message: "Failed to send verification email",
@@ -391,7 +413,7 @@ func (s *authService) ForgotPassword(ctx context.Context, req dto.ForgotPassword
 	}
 
 	// Send password reset email
-	if err := s.emailService.SendPasswordResetEmail(user.Email, user.Name, resetToken); err != nil {
+	if err := s.emailService.SendPasswordResetEmail(userLocale(ctx, user), user.Email, user.Name, resetToken); err != nil {
 		logger.Error("Failed to send password reset email", zap.Error(err))
 		return &dto.PasswordResetResponse{
 			Message: "Failed to send password reset email",
@@ -461,4 +483,12 @@ func (s *authService) ResetPassword(ctx context.Context, req dto.ResetPasswordRe
 		Message: "Password reset successfully",
 		Success: true,
 	}, nil
+}
+
+// stringToPtr returns nil for empty strings so optional columns are stored as NULL
+func stringToPtr(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
 }
\ No newline at end of file
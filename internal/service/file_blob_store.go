@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"io"
+	"path"
+	"time"
+
+	"go-template/internal/entity"
+	"go-template/internal/logger"
+	"go-template/internal/repository"
+	"go-template/pkg/storage"
+
+	"go.uber.org/zap"
+)
+
+// blobGracePeriod is how long unreferenced content is kept, so a file deleted and uploaded again
+// right away does not have to be stored twice
+const blobGracePeriod = time.Hour
+
+// blobStore keeps file content content-addressed: every distinct content is stored once under a key
+// derived from its SHA-256 checksum and reference counted by the files using it
+type blobStore struct {
+	blobRepo    repository.FileBlobRepository
+	fileStorage storage.FileStorage
+}
+
+func newBlobStore(blobRepo repository.FileBlobRepository, fileStorage storage.FileStorage) *blobStore {
+	return &blobStore{
+		blobRepo:    blobRepo,
+		fileStorage: fileStorage,
+	}
+}
+
+// blobKey is the storage key of the content with the checksum
+func blobKey(checksum string) string {
+	return path.Join("blobs", checksum[:2], checksum)
+}
+
+// put takes a reference on the content with the checksum and returns its storage key. r is only read
+// when no identical content is stored yet, or the stored content was found damaged.
+func (b *blobStore) put(ctx context.Context, checksum string, r io.Reader, size int64, contentType string) (string, error) {
+	blob, err := b.blobRepo.Acquire(ctx, checksum, blobKey(checksum), size)
+	if err != nil {
+		return "", err
+	}
+	key := blob.StorageKey
+	if blob.Stored && !blob.Corrupted {
+		logger.Debug("Reusing stored content", zap.String("checksum", checksum))
+		return key, nil
+	}
+	if blob.Corrupted {
+		logger.Info("Replacing damaged content", zap.String("checksum", checksum))
+	}
+
+	// The blob may have been registered by a concurrent upload of the same content that is still
+	// writing it, or that failed. Writing it here as well is safe, as the content is identical and
+	// objects are replaced atomically, and the file never refers to content that was not written.
+	if err := b.fileStorage.Put(ctx, key, r, size, contentType); err != nil {
+		b.release(ctx, checksum)
+		return "", err
+	}
+	if err := b.blobRepo.MarkStored(ctx, checksum); err != nil {
+		// The content is in place; the next upload of it merely writes it again
+		logger.Warn("Failed to mark content as stored", zap.Error(err), zap.String("checksum", checksum))
+	}
+	return key, nil
+}
+
+// checkIntact fails with ErrFileCorrupted when the verification job found the content with the
+// checksum damaged. Content uploaded before checksums is never verified.
+func (b *blobStore) checkIntact(ctx context.Context, checksum string) error {
+	if checksum == "" {
+		return nil
+	}
+	blob, err := b.blobRepo.Get(ctx, checksum)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		logger.Error("Failed to get stored content", zap.Error(err), zap.String("checksum", checksum))
+		return err
+	}
+	if blob.Corrupted {
+		logger.Warn("Refused damaged content", zap.String("checksum", checksum))
+		return ErrFileCorrupted
+	}
+	return nil
+}
+
+// release drops a reference taken by put; the content is deleted by collectGarbage once unused
+func (b *blobStore) release(ctx context.Context, checksum string) {
+	if err := b.blobRepo.Release(ctx, checksum); err != nil {
+		logger.Warn("Failed to release stored content", zap.Error(err), zap.String("checksum", checksum))
+	}
+}
+
+// deleteFileContent releases the content of a deleted file
+func (b *blobStore) deleteFileContent(ctx context.Context, file *entity.File) error {
+	return b.deleteContent(ctx, file.Checksum, file.FilePath)
+}
+
+// deleteContent releases content that is no longer used by a file or file version. Content uploaded
+// before checksums has a single owner and its object is deleted right away.
+func (b *blobStore) deleteContent(ctx context.Context, checksum, key string) error {
+	if checksum != "" {
+		b.release(ctx, checksum)
+		return nil
+	}
+	return b.fileStorage.Delete(ctx, key)
+}
+
+// collectGarbage deletes content that has had no references for the grace period
+func (b *blobStore) collectGarbage(ctx context.Context, limit int) error {
+	blobs, err := b.blobRepo.GetUnreferenced(ctx, time.Now().Add(-blobGracePeriod), limit)
+	if err != nil {
+		return err
+	}
+
+	for _, blob := range blobs {
+		err := b.blobRepo.DeleteUnreferenced(ctx, blob.Checksum, func(key string) error {
+			return b.fileStorage.Delete(ctx, key)
+		})
+		if err != nil {
+			logger.Warn("Failed to delete unreferenced content", zap.Error(err), zap.String("checksum", blob.Checksum))
+		}
+	}
+
+	if len(blobs) > 0 {
+		logger.Info("Deleted unreferenced content", zap.Int("count", len(blobs)))
+	}
+	return nil
+}
+
+// verify re-reads stored content and compares it with its checksum, flagging missing or altered content
+func (b *blobStore) verify(ctx context.Context, verifiedBefore time.Time, limit int) error {
+	blobs, err := b.blobRepo.GetToVerify(ctx, verifiedBefore, limit)
+	if err != nil {
+		return err
+	}
+
+	corrupted := 0
+	for _, blob := range blobs {
+		intact, err := b.verifyBlob(ctx, &blob)
+		if err != nil {
+			// Storage being unreachable says nothing about the content; try again next run
+			logger.Warn("Failed to verify stored content", zap.Error(err), zap.String("checksum", blob.Checksum))
+			continue
+		}
+		if !intact {
+			corrupted++
+			logger.Error("Stored content failed integrity check", zap.String("checksum", blob.Checksum), zap.String("key", blob.StorageKey))
+		}
+		if err := b.blobRepo.MarkVerified(ctx, blob.Checksum, !intact); err != nil {
+			logger.Warn("Failed to record verification", zap.Error(err), zap.String("checksum", blob.Checksum))
+		}
+	}
+
+	if len(blobs) > 0 {
+		logger.Info("Verified stored content", zap.Int("count", len(blobs)), zap.Int("corrupted", corrupted))
+	}
+	return nil
+}
+
+func (b *blobStore) verifyBlob(ctx context.Context, blob *entity.FileBlob) (bool, error) {
+	checksum, size, err := b.hashObject(ctx, blob.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return size == blob.Size && checksum == blob.Checksum, nil
+}
+
+// hashObject reads a stored object and returns its SHA-256 checksum and size
+func (b *blobStore) hashObject(ctx context.Context, key string) (string, int64, error) {
+	content, _, err := b.fileStorage.Open(ctx, key)
+	if err != nil {
+		return "", 0, err
+	}
+	defer content.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, content)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), size, nil
+}
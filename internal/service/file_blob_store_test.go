@@ -0,0 +1,163 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"go-template/internal/entity"
+	"go-template/internal/repository"
+	"go-template/pkg/storage"
+)
+
+// fakeFileBlobRepository keeps blobs in memory, keyed by checksum
+type fakeFileBlobRepository struct {
+	repository.FileBlobRepository
+	blobs map[string]*entity.FileBlob
+}
+
+func (r *fakeFileBlobRepository) Acquire(ctx context.Context, checksum, storageKey string, size int64) (*entity.FileBlob, error) {
+	blob, ok := r.blobs[checksum]
+	if !ok {
+		blob = &entity.FileBlob{Checksum: checksum, StorageKey: storageKey, Size: size}
+		r.blobs[checksum] = blob
+	}
+	blob.RefCount++
+	acquired := *blob
+	return &acquired, nil
+}
+
+func (r *fakeFileBlobRepository) MarkStored(ctx context.Context, checksum string) error {
+	blob := r.blobs[checksum]
+	blob.Stored, blob.Corrupted, blob.VerifiedAt = true, false, nil
+	return nil
+}
+
+func (r *fakeFileBlobRepository) Release(ctx context.Context, checksum string) error {
+	r.blobs[checksum].RefCount--
+	return nil
+}
+
+func (r *fakeFileBlobRepository) Get(ctx context.Context, checksum string) (*entity.FileBlob, error) {
+	blob, ok := r.blobs[checksum]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	found := *blob
+	return &found, nil
+}
+
+func newTestBlobStore(t *testing.T) (*blobStore, *fakeFileBlobRepository, storage.FileStorage) {
+	t.Helper()
+
+	blobRepo := &fakeFileBlobRepository{blobs: map[string]*entity.FileBlob{}}
+	fileStorage := storage.NewLocalStorage(t.TempDir(), storage.URLConfig{BaseURL: "http://localhost:8080", Secret: "secret"})
+	return newBlobStore(blobRepo, fileStorage), blobRepo, fileStorage
+}
+
+func sha256Hex(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+func readObject(t *testing.T, fileStorage storage.FileStorage, key string) []byte {
+	t.Helper()
+
+	r, _, err := fileStorage.Open(context.Background(), key)
+	if err != nil {
+		t.Fatalf("Open(%s): %v", key, err)
+	}
+	defer r.Close()
+	content, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("read %s: %v", key, err)
+	}
+	return content
+}
+
+// failingReader fails the test when put reads content it should have reused
+type failingReader struct {
+	t *testing.T
+}
+
+func (r failingReader) Read(p []byte) (int, error) {
+	r.t.Error("put read the content although intact content was stored")
+	return 0, io.EOF
+}
+
+func TestBlobStorePutReusesIntactContent(t *testing.T) {
+	blobs, blobRepo, fileStorage := newTestBlobStore(t)
+	ctx := context.Background()
+	content := []byte("shared content")
+	checksum := sha256Hex(content)
+
+	key, err := blobs.put(ctx, checksum, bytes.NewReader(content), int64(len(content)), "text/plain")
+	if err != nil {
+		t.Fatalf("first put: %v", err)
+	}
+	if !blobRepo.blobs[checksum].Stored {
+		t.Fatal("content not marked as stored")
+	}
+
+	reused, err := blobs.put(ctx, checksum, failingReader{t}, int64(len(content)), "text/plain")
+	if err != nil {
+		t.Fatalf("second put: %v", err)
+	}
+	if reused != key {
+		t.Errorf("second put stored under %s, want %s", reused, key)
+	}
+	if got := readObject(t, fileStorage, key); !bytes.Equal(got, content) {
+		t.Errorf("stored %q, want %q", got, content)
+	}
+}
+
+func TestBlobStorePutReplacesCorruptedContent(t *testing.T) {
+	blobs, blobRepo, fileStorage := newTestBlobStore(t)
+	ctx := context.Background()
+	content := []byte("content damaged in storage")
+	checksum := sha256Hex(content)
+
+	key, err := blobs.put(ctx, checksum, bytes.NewReader(content), int64(len(content)), "text/plain")
+	if err != nil {
+		t.Fatalf("first put: %v", err)
+	}
+
+	// The storage altered the object and the verification job flagged it
+	if err := fileStorage.Put(ctx, key, strings.NewReader("garbage"), 7, "text/plain"); err != nil {
+		t.Fatalf("damage object: %v", err)
+	}
+	blobRepo.blobs[checksum].Corrupted = true
+	if err := blobs.checkIntact(ctx, checksum); !errors.Is(err, ErrFileCorrupted) {
+		t.Fatalf("checkIntact error = %v, want ErrFileCorrupted", err)
+	}
+
+	if _, err := blobs.put(ctx, checksum, bytes.NewReader(content), int64(len(content)), "text/plain"); err != nil {
+		t.Fatalf("second put: %v", err)
+	}
+	if got := readObject(t, fileStorage, key); !bytes.Equal(got, content) {
+		t.Errorf("stored %q after a new upload, want %q", got, content)
+	}
+	if blob := blobRepo.blobs[checksum]; blob.Corrupted || !blob.Stored {
+		t.Errorf("blob after a new upload = %+v, want stored and not corrupted", blob)
+	}
+	if err := blobs.checkIntact(ctx, checksum); err != nil {
+		t.Errorf("checkIntact after a new upload: %v", err)
+	}
+}
+
+func TestBlobStoreCheckIntactWithoutBlob(t *testing.T) {
+	blobs, _, _ := newTestBlobStore(t)
+
+	// Content uploaded before checksums has no blob
+	for _, checksum := range []string{"", sha256Hex([]byte("unknown"))} {
+		if err := blobs.checkIntact(context.Background(), checksum); err != nil {
+			t.Errorf("checkIntact(%q): %v", checksum, err)
+		}
+	}
+}
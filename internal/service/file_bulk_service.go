@@ -0,0 +1,269 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"unicode"
+
+	"go-template/internal/dto"
+	"go-template/internal/entity"
+	"go-template/internal/logger"
+	"go-template/internal/repository"
+	"go-template/pkg/apperror"
+	"go-template/pkg/i18n"
+	"go-template/pkg/storage"
+
+	"go.uber.org/zap"
+)
+
+// FileBulkService applies file operations to a list of files, checking access file by file like the
+// single file routes do
+type FileBulkService interface {
+	// DeleteFiles deletes the files, reporting the outcome per file; callers are moderators or admins
+	DeleteFiles(ctx context.Context, req dto.BulkFileRequest) (*dto.BulkFileResponse, error)
+	// UpdateFilesCategory sets the category of the files the user has write access to, reporting the
+	// outcome per file
+	UpdateFilesCategory(ctx context.Context, userID int, req dto.BulkUpdateCategoryRequest) (*dto.BulkFileResponse, error)
+	// WriteFileArchive streams a ZIP archive of the files to w. Every file is checked before anything
+	// is written, so the archive is refused as a whole when the user cannot read one of them.
+	WriteFileArchive(ctx context.Context, w io.Writer, userID int, req dto.BulkFileRequest) error
+}
+
+type fileBulkService struct {
+	fileRepo    repository.FileRepository
+	grantRepo   repository.FileGrantRepository
+	userRepo    repository.UserRepository
+	fileService FileService
+	fileStorage storage.FileStorage
+	blobs       *blobStore
+}
+
+func NewFileBulkService(fileRepo repository.FileRepository, grantRepo repository.FileGrantRepository, userRepo repository.UserRepository, blobRepo repository.FileBlobRepository, fileService FileService, fileStorage storage.FileStorage) FileBulkService {
+	return &fileBulkService{
+		fileRepo:    fileRepo,
+		grantRepo:   grantRepo,
+		userRepo:    userRepo,
+		fileService: fileService,
+		fileStorage: fileStorage,
+		blobs:       newBlobStore(blobRepo, fileStorage),
+	}
+}
+
+func (s *fileBulkService) DeleteFiles(ctx context.Context, req dto.BulkFileRequest) (*dto.BulkFileResponse, error) {
+	ids := uniqueIDs(req.IDs)
+	logger.Info("Deleting files in bulk", zap.Ints("file_ids", ids))
+
+	results := make([]dto.BulkFileResult, len(ids))
+	for i, id := range ids {
+		results[i] = bulkFileResult(ctx, id, s.fileService.DeleteFile(ctx, id))
+	}
+
+	return summarizeBulkResults(results), nil
+}
+
+func (s *fileBulkService) UpdateFilesCategory(ctx context.Context, userID int, req dto.BulkUpdateCategoryRequest) (*dto.BulkFileResponse, error) {
+	ids := uniqueIDs(req.IDs)
+	logger.Info("Updating file categories in bulk", zap.Ints("file_ids", ids), zap.String("category", req.Category))
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]dto.BulkFileResult, len(ids))
+	for i, id := range ids {
+		file, err := s.getAccessibleFile(ctx, id, user, entity.FilePermissionWrite)
+		if err != nil {
+			results[i] = bulkFileResult(ctx, id, err)
+			continue
+		}
+
+		// Only the category changes; UpdateFile replaces both fields
+		updated, err := s.fileService.UpdateFile(ctx, id, user.ID, dto.UpdateFileRequest{Description: file.Description, Category: req.Category})
+		results[i] = bulkFileResult(ctx, id, err)
+		results[i].File = updated
+	}
+
+	return summarizeBulkResults(results), nil
+}
+
+func (s *fileBulkService) WriteFileArchive(ctx context.Context, w io.Writer, userID int, req dto.BulkFileRequest) error {
+	ids := uniqueIDs(req.IDs)
+	logger.Info("Writing file archive", zap.Ints("file_ids", ids), zap.Int("user_id", userID))
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	files := make([]*entity.File, len(ids))
+	for i, id := range ids {
+		file, err := s.getAccessibleFile(ctx, id, user, entity.FilePermissionRead)
+		if err == nil {
+			err = checkScanStatus(file.ScanStatus)
+		}
+		if err == nil {
+			err = s.blobs.checkIntact(ctx, file.Checksum)
+		}
+		if err != nil {
+			if appErr, ok := apperror.As(err); ok {
+				return appErr.WithDetails(map[string]int{"file_id": id})
+			}
+			return err
+		}
+		files[i] = file
+	}
+
+	// Entries are compressed as they are copied from storage, so neither the files nor the archive
+	// are held in memory or on disk
+	zipWriter := zip.NewWriter(w)
+	names := make(map[string]bool, len(files))
+	for _, file := range files {
+		name := uniqueEntryName(names, archiveEntryName(file))
+		if err := s.copyFileToArchive(ctx, zipWriter, name, file); err != nil {
+			logger.Error("Failed to add file to archive", zap.Error(err), zap.Int("file_id", file.ID))
+			return err
+		}
+	}
+	if err := zipWriter.Close(); err != nil {
+		return err
+	}
+
+	logger.Info("File archive written successfully", zap.Int("files", len(files)), zap.Int("user_id", userID))
+
+	return nil
+}
+
+// archiveEntryName turns the original name of a file into a name unzip tools extract into the target
+// directory: separators and control characters are replaced, and names made of dots fall back to the
+// file ID
+func archiveEntryName(file *entity.File) string {
+	name := strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' || unicode.IsControl(r) {
+			return '_'
+		}
+		return r
+	}, file.OriginalName)
+	name = strings.TrimSpace(name)
+	if strings.Trim(name, ".") == "" {
+		return fmt.Sprintf("file-%d", file.ID)
+	}
+	return name
+}
+
+// uniqueEntryName numbers repeated names, e.g. "report (2).pdf", and records the name it returns.
+// Names differing only in case are repeated too, as they clash on case-insensitive file systems.
+func uniqueEntryName(names map[string]bool, name string) string {
+	ext := path.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	unique := name
+	for n := 2; names[strings.ToLower(unique)]; n++ {
+		unique = fmt.Sprintf("%s (%d)%s", stem, n, ext)
+	}
+	names[strings.ToLower(unique)] = true
+	return unique
+}
+
+func (s *fileBulkService) copyFileToArchive(ctx context.Context, zipWriter *zip.Writer, name string, file *entity.File) error {
+	src, _, err := s.fileStorage.Open(ctx, file.FilePath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := zipWriter.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: file.ContentUpdatedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(dst, src)
+	return err
+}
+
+func (s *fileBulkService) getUser(ctx context.Context, userID int) (*entity.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		logger.Error("Failed to get user", zap.Error(err), zap.Int("user_id", userID))
+		return nil, err
+	}
+	return user, nil
+}
+
+// getAccessibleFile returns the file when the user may use it with the permission, checked like the
+// single file routes do
+func (s *fileBulkService) getAccessibleFile(ctx context.Context, id int, user *entity.User, permission string) (*entity.File, error) {
+	file, err := s.fileRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrFileNotFound
+		}
+		logger.Error("Failed to get file", zap.Error(err), zap.Int("file_id", id))
+		return nil, err
+	}
+
+	allowed, err := repository.CanAccessFile(ctx, s.grantRepo, user, file, permission)
+	if err != nil {
+		logger.Error("Failed to get file grant", zap.Error(err), zap.Int("file_id", id))
+		return nil, err
+	}
+	if !allowed {
+		return nil, ErrFileNotOwned
+	}
+	return file, nil
+}
+
+// bulkFileResult reports the outcome for one file, with the error code and message the single file
+// route would answer with
+func bulkFileResult(ctx context.Context, id int, err error) dto.BulkFileResult {
+	if err == nil {
+		return dto.BulkFileResult{ID: id, Success: true}
+	}
+
+	code, message := apperror.CodeInternal, "Internal server error"
+	if appErr, ok := apperror.As(err); ok && appErr.HTTPStatus() < 500 {
+		code, message = appErr.Code, appErr.Message
+	}
+	return dto.BulkFileResult{
+		ID:      id,
+		Code:    code,
+		Message: i18n.T(i18n.FromContext(ctx), message),
+	}
+}
+
+func summarizeBulkResults(results []dto.BulkFileResult) *dto.BulkFileResponse {
+	summary := &dto.BulkFileResponse{Total: len(results), Results: results}
+	for _, result := range results {
+		if result.Success {
+			summary.Succeeded++
+		} else {
+			summary.Failed++
+		}
+	}
+	return summary
+}
+
+// uniqueIDs drops repeated IDs, keeping the order of their first occurrence
+func uniqueIDs(ids []int) []int {
+	seen := make(map[int]bool, len(ids))
+	unique := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+
+	"go-template/internal/dto"
+	"go-template/internal/entity"
+	"go-template/internal/repository"
+	"go-template/pkg/apperror"
+	"go-template/pkg/storage"
+)
+
+// fakeFileGrantRepository holds the grants of users on files, by file and user
+type fakeFileGrantRepository struct {
+	repository.FileGrantRepository
+	grants map[[2]int]string
+}
+
+func (r *fakeFileGrantRepository) Get(ctx context.Context, fileID, userID int) (*entity.FileGrant, error) {
+	permission, ok := r.grants[[2]int{fileID, userID}]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &entity.FileGrant{FileID: fileID, UserID: userID, Permission: permission}, nil
+}
+
+func TestArchiveEntryName(t *testing.T) {
+	tests := []struct {
+		originalName string
+		want         string
+	}{
+		{"report.pdf", "report.pdf"},
+		{"../../etc/passwd", ".._.._etc_passwd"},
+		{`..\..\windows\win.ini`, ".._.._windows_win.ini"},
+		{"/etc/passwd", "_etc_passwd"},
+		{"C:report.pdf", "C_report.pdf"},
+		{"line\nbreak.txt", "line_break.txt"},
+		{"  padded.txt ", "padded.txt"},
+		{"..", "file-7"},
+		{".", "file-7"},
+		{"...", "file-7"},
+		{"", "file-7"},
+		{"/", "_"},
+		{".hidden", ".hidden"},
+	}
+	for _, tt := range tests {
+		if got := archiveEntryName(&entity.File{ID: 7, OriginalName: tt.originalName}); got != tt.want {
+			t.Errorf("archiveEntryName(%q) = %q, want %q", tt.originalName, got, tt.want)
+		}
+	}
+}
+
+func TestUniqueEntryName(t *testing.T) {
+	names := map[string]bool{}
+	// "report (2).pdf" is a real name that the second "report.pdf" would otherwise be renamed to
+	inputs := []string{"report.pdf", "report (2).pdf", "report.pdf", "REPORT.pdf", "notes", "notes"}
+	want := []string{"report.pdf", "report (2).pdf", "report (3).pdf", "REPORT (4).pdf", "notes", "notes (2)"}
+
+	for i, name := range inputs {
+		if got := uniqueEntryName(names, name); got != want[i] {
+			t.Errorf("entry %d: uniqueEntryName(%q) = %q, want %q", i, name, got, want[i])
+		}
+	}
+}
+
+func TestUpdateFilesCategoryPublicCategory(t *testing.T) {
+	blobRepo := &fakeFileBlobRepository{}
+	fileService, fileRepo := newTestFileService(t, blobRepo,
+		&entity.File{ID: 10, UploadedBy: 1, Category: "docs"},
+		&entity.File{ID: 11, UploadedBy: 2, Category: "docs"},
+	)
+	users := publishingUsers()
+	fileService.userRepo = users
+	fileService.config.FileURL.PublicCategories = []string{"avatar"}
+	grants := &fakeFileGrantRepository{grants: map[[2]int]string{{10, 2}: entity.FilePermissionWrite}}
+	s := NewFileBulkService(fileRepo, grants, users, blobRepo, fileService, fileService.fileStorage)
+
+	// User 2 may write file 10 but not publish it; file 11 is their own
+	result, err := s.UpdateFilesCategory(context.Background(), 2, dto.BulkUpdateCategoryRequest{IDs: []int{10, 11}, Category: "avatar"})
+	if err != nil {
+		t.Fatalf("UpdateFilesCategory: %v", err)
+	}
+	if denied := result.Results[0]; denied.Success || denied.Code != apperror.CodeForbidden {
+		t.Errorf("result of the shared file = %+v, want %s", denied, apperror.CodeForbidden)
+	}
+	if !result.Results[1].Success {
+		t.Errorf("result of the own file = %+v, want success", result.Results[1])
+	}
+	if category := fileRepo.files[10].Category; category != "docs" {
+		t.Errorf("category of the shared file = %q, want it unchanged", category)
+	}
+	if category := fileRepo.files[11].Category; category != "avatar" {
+		t.Errorf("category of the own file = %q, want %q", category, "avatar")
+	}
+}
+
+func TestDeleteFilesRemovesAvatarVariants(t *testing.T) {
+	ctx := context.Background()
+	blobRepo := &fakeFileBlobRepository{blobs: map[string]*entity.FileBlob{
+		"old": {Checksum: "old", RefCount: 1, Stored: true},
+	}}
+	// The former avatar has since been moved to another category
+	fileService, fileRepo := newTestFileService(t, blobRepo,
+		&entity.File{ID: 10, UploadedBy: 1, FileName: "old.png", MimeType: "image/png", Category: "photos", Checksum: "old"},
+	)
+	s := NewFileBulkService(fileRepo, nil, publishingUsers(), blobRepo, fileService, fileService.fileStorage)
+
+	for _, size := range avatarSizes {
+		key := avatarVariantName("old.png", size)
+		if err := fileService.fileStorage.Put(ctx, key, strings.NewReader("png"), 3, "image/png"); err != nil {
+			t.Fatalf("Put(%s): %v", key, err)
+		}
+	}
+
+	result, err := s.DeleteFiles(ctx, dto.BulkFileRequest{IDs: []int{10}})
+	if err != nil {
+		t.Fatalf("DeleteFiles: %v", err)
+	}
+	if result.Succeeded != 1 {
+		t.Fatalf("DeleteFiles = %+v, want the file deleted", result)
+	}
+	for _, size := range avatarSizes {
+		key := avatarVariantName("old.png", size)
+		if _, _, err := fileService.fileStorage.Open(ctx, key); !errors.Is(err, storage.ErrObjectNotFound) {
+			t.Errorf("Open(%s) error %v, want %v", key, err, storage.ErrObjectNotFound)
+		}
+	}
+}
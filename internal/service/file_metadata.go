@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+
+	"go-template/internal/config"
+	"go-template/internal/entity"
+	"go-template/internal/logger"
+	"go-template/pkg/filemeta"
+	"go-template/pkg/storage"
+
+	"go.uber.org/zap"
+)
+
+// strippedContent is uploaded content without its metadata, spooled to a temporary file that is
+// removed on Close
+type strippedContent struct {
+	*os.File
+	size     int64
+	checksum string
+}
+
+func (c *strippedContent) Close() error {
+	err := c.File.Close()
+	os.Remove(c.Name())
+	return err
+}
+
+// stripContentMetadata removes identifying metadata, such as the GPS position of photos, from content
+// of the types filemeta can strip. It returns nil when the content is to be stored as is.
+func stripContentMetadata(config *config.Config, content io.Reader, mimeType string) (*strippedContent, error) {
+	if !config.Upload.StripMetadata || !filemeta.CanStrip(mimeType) {
+		return nil, nil
+	}
+
+	tmp, err := os.CreateTemp("", "strip-*")
+	if err != nil {
+		return nil, ErrFileStorage.Wrap(err)
+	}
+	stripped := &strippedContent{File: tmp}
+
+	hash := sha256.New()
+	if err := filemeta.Strip(io.MultiWriter(tmp, hash), content, mimeType); err != nil {
+		stripped.Close()
+		if errors.Is(err, filemeta.ErrMalformed) {
+			return nil, ErrFileImageUnprocessable.Wrap(err)
+		}
+		return nil, ErrFileStorage.Wrap(err)
+	}
+
+	if stripped.size, err = tmp.Seek(0, io.SeekCurrent); err == nil {
+		_, err = tmp.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		stripped.Close()
+		return nil, ErrFileStorage.Wrap(err)
+	}
+	stripped.checksum = hex.EncodeToString(hash.Sum(nil))
+
+	return stripped, nil
+}
+
+// extractContentMetadata reads the metadata of stored content. Extraction is best effort: content it
+// fails on is stored without metadata.
+func extractContentMetadata(ctx context.Context, fileStorage storage.FileStorage, config *config.Config, key, mimeType string) *entity.FileMetadata {
+	if !config.Upload.ExtractMetadata {
+		return nil
+	}
+
+	content, _, err := fileStorage.Open(ctx, key)
+	if err != nil {
+		logger.Warn("Failed to open file for metadata extraction", zap.Error(err), zap.String("key", key))
+		return nil
+	}
+	defer content.Close()
+
+	metadata, err := filemeta.Extract(content, mimeType)
+	if err != nil {
+		logger.Warn("Failed to extract file metadata", zap.Error(err), zap.String("key", key), zap.String("mime_type", mimeType))
+		return nil
+	}
+	if metadata == nil {
+		return nil
+	}
+
+	return &entity.FileMetadata{
+		Width:    metadata.Width,
+		Height:   metadata.Height,
+		Pages:    metadata.Pages,
+		Frames:   metadata.Frames,
+		Duration: metadata.Duration,
+	}
+}
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go-template/internal/config"
+	"go-template/internal/entity"
+	"go-template/internal/logger"
+	"go-template/internal/repository"
+	"go-template/pkg/apperror"
+	"go-template/pkg/scanner"
+	"go-template/pkg/storage"
+
+	"go.uber.org/zap"
+)
+
+var (
+	ErrFileScanPending = apperror.New(apperror.KindConflict, apperror.CodeFileScanPending, "file is still being scanned for malware")
+	ErrFileInfected    = apperror.New(apperror.KindForbidden, apperror.CodeFileInfected, "file is quarantined because malware was found")
+	ErrFileUnscannable = apperror.New(apperror.KindForbidden, apperror.CodeFileUnscannable, "file is blocked because it could not be scanned for malware")
+)
+
+// scanQueueSize bounds the uploads waiting for their scan; beyond it, files are picked up by the
+// retry loop
+const scanQueueSize = 100
+
+// scanRetryBatchSize is the number of pending files requeued per retry run
+const scanRetryBatchSize = 100
+
+// FileScanService scans uploaded files for malware before they can be downloaded. Uploads start out
+// pending and are scanned in the background; clean files are handed on for their image variants,
+// infected ones stay quarantined and those the scanner refuses stay blocked as unscannable. Without a
+// scanner, uploads are clean right away.
+type FileScanService interface {
+	// InitialStatus is the scan status new files are created with
+	InitialStatus() string
+	// MaxSize is the largest file the scanner accepts, or 0 when there is no limit
+	MaxSize() int64
+	// Enqueue schedules the scan of a new file, or hands a clean file straight on
+	Enqueue(file *entity.File)
+	// StartWorkers starts the scanning workers and periodically requeues files still pending, such as
+	// those whose scan failed
+	StartWorkers(workers int, retryInterval time.Duration)
+}
+
+type fileScanService struct {
+	fileRepo       repository.FileRepository
+	versionRepo    repository.FileVersionRepository
+	variantService ImageVariantService
+	scanner        scanner.Scanner
+	fileStorage    storage.FileStorage
+	config         *config.Config
+	queue          chan *entity.File
+
+	mu     sync.Mutex
+	queued map[int]bool
+}
+
+// NewFileScanService creates the scanning stage of uploads; fileScanner may be nil to disable scanning
+func NewFileScanService(fileRepo repository.FileRepository, versionRepo repository.FileVersionRepository, variantService ImageVariantService, fileScanner scanner.Scanner, fileStorage storage.FileStorage, config *config.Config) FileScanService {
+	return &fileScanService{
+		fileRepo:       fileRepo,
+		versionRepo:    versionRepo,
+		variantService: variantService,
+		scanner:        fileScanner,
+		fileStorage:    fileStorage,
+		config:         config,
+		queue:          make(chan *entity.File, scanQueueSize),
+		queued:         make(map[int]bool),
+	}
+}
+
+func (s *fileScanService) InitialStatus() string {
+	if s.scanner == nil {
+		return entity.FileScanStatusClean
+	}
+	return entity.FileScanStatusPending
+}
+
+func (s *fileScanService) MaxSize() int64 {
+	if s.scanner == nil {
+		return 0
+	}
+	return s.config.Scan.MaxSize
+}
+
+func (s *fileScanService) Enqueue(file *entity.File) {
+	if file.ScanStatus == entity.FileScanStatusClean {
+		s.variantService.Enqueue(file)
+		return
+	}
+	if s.scanner == nil || file.ScanStatus != entity.FileScanStatusPending {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.queued[file.ID] {
+		return
+	}
+
+	select {
+	case s.queue <- file:
+		s.queued[file.ID] = true
+	default:
+		logger.Warn("File scan queue is full, the scan will be retried", zap.Int("file_id", file.ID))
+	}
+}
+
+func (s *fileScanService) StartWorkers(workers int, retryInterval time.Duration) {
+	if s.scanner == nil {
+		return
+	}
+
+	for i := 0; i < max(workers, 1); i++ {
+		go func() {
+			for file := range s.queue {
+				s.scan(context.Background(), file)
+
+				s.mu.Lock()
+				delete(s.queued, file.ID)
+				s.mu.Unlock()
+			}
+		}()
+	}
+
+	go func() {
+		ticker := time.NewTicker(retryInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			s.requeuePending(context.Background(), retryInterval)
+		}
+	}()
+}
+
+// requeuePending enqueues files that have been pending for longer than retryInterval, which covers
+// uploads made while the queue was full, scans that failed and files left pending by a restart; files
+// marked unscannable are no longer pending
+func (s *fileScanService) requeuePending(ctx context.Context, retryInterval time.Duration) {
+	files, err := s.fileRepo.GetPendingScan(ctx, time.Now().Add(-retryInterval), scanRetryBatchSize)
+	if err != nil {
+		logger.Error("Failed to get files pending scan", zap.Error(err))
+		return
+	}
+
+	for i := range files {
+		s.Enqueue(&files[i])
+	}
+	if len(files) > 0 {
+		logger.Info("Requeued files pending scan", zap.Int("count", len(files)))
+	}
+}
+
+// scan scans the content of a pending file and records the verdict. Files are left pending when no
+// verdict is reached, so they stay blocked until a later attempt succeeds, except for files over the
+// scanner's size limit: those will never get one and are marked unscannable.
+func (s *fileScanService) scan(ctx context.Context, file *entity.File) {
+	content, _, err := s.fileStorage.Open(ctx, file.FilePath)
+	if err != nil {
+		logger.Error("Failed to open file for scanning", zap.Error(err), zap.Int("file_id", file.ID))
+		return
+	}
+	defer content.Close()
+
+	result, err := s.scanner.Scan(ctx, content)
+	if err != nil {
+		if errors.Is(err, scanner.ErrSizeLimitExceeded) {
+			logger.Warn("File exceeds the scanner's size limit, marking it unscannable", zap.Int("file_id", file.ID), zap.Int64("size", file.FileSize))
+			s.recordScanStatus(ctx, file, entity.FileScanStatusUnscannable, "")
+			return
+		}
+		logger.Error("Failed to scan file", zap.Error(err), zap.Int("file_id", file.ID))
+		return
+	}
+
+	status := entity.FileScanStatusClean
+	if result.Infected {
+		status = entity.FileScanStatusInfected
+	}
+	scanned, ok := s.recordScanStatus(ctx, file, status, result.Signature)
+	if !ok {
+		return
+	}
+
+	if result.Infected {
+		logger.Warn("Malware found in uploaded file", zap.Int("file_id", file.ID), zap.Int("user_id", file.UploadedBy), zap.String("signature", result.Signature))
+		return
+	}
+
+	logger.Debug("File scanned clean", zap.Int("file_id", file.ID))
+	s.variantService.Enqueue(scanned)
+}
+
+// recordScanStatus records the final scan status of the content of a file, telling whether the file
+// still has that content
+func (s *fileScanService) recordScanStatus(ctx context.Context, file *entity.File, status, signature string) (*entity.File, bool) {
+	// The content may have become an earlier version of the file meanwhile, which keeps the status
+	if err := s.versionRepo.UpdateScanStatus(ctx, file.ID, file.FilePath, status); err != nil {
+		logger.Warn("Failed to record scan result of file versions", zap.Error(err), zap.Int("file_id", file.ID))
+	}
+	scanned, err := s.fileRepo.UpdateScanStatus(ctx, file.ID, file.FilePath, status, signature)
+	if err != nil {
+		// Typically the file was deleted or got new content meanwhile
+		logger.Warn("Failed to record scan result", zap.Error(err), zap.Int("file_id", file.ID))
+		return nil, false
+	}
+	return scanned, true
+}
+
+// checkScanStatus blocks access to content that is not known to be clean
+func checkScanStatus(status string) error {
+	switch status {
+	case entity.FileScanStatusClean:
+		return nil
+	case entity.FileScanStatusInfected:
+		return ErrFileInfected
+	case entity.FileScanStatusUnscannable:
+		return ErrFileUnscannable
+	default:
+		return ErrFileScanPending
+	}
+}
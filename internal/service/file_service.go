@@ -2,78 +2,136 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"errors"
 	"go-template/internal/config"
 	"go-template/internal/dto"
 	"go-template/internal/entity"
 	"go-template/internal/logger"
 	"go-template/internal/repository"
+	"go-template/pkg/apperror"
+	"go-template/pkg/filetype"
+	"go-template/pkg/pagination"
 	"go-template/pkg/storage"
+	"io"
 	"mime/multipart"
 	"slices"
+	"time"
 
 	"go.uber.org/zap"
 )
 
+var (
+	ErrFileNotFound       = apperror.New(apperror.KindNotFound, apperror.CodeFileNotFound, "file not found")
+	ErrFileTooLarge       = apperror.New(apperror.KindTooLarge, apperror.CodeFileTooLarge, "file size exceeds maximum allowed size")
+	ErrFileTypeNotAllowed = apperror.New(apperror.KindUnsupportedMediaType, apperror.CodeFileTypeNotAllowed, "file type not allowed")
+	ErrFileTypeMismatch   = apperror.New(apperror.KindUnsupportedMediaType, apperror.CodeFileTypeMismatch, "file content does not match its declared type or extension")
+	ErrFileStorage        = apperror.New(apperror.KindInternal, apperror.CodeFileStorageFailed, "failed to store file")
+	ErrFileURLInvalid     = apperror.New(apperror.KindForbidden, apperror.CodeFileURLInvalid, "invalid or missing file URL signature")
+	ErrFileURLExpired     = apperror.New(apperror.KindForbidden, apperror.CodeFileURLExpired, "file URL has expired")
+	ErrFileNotOwned       = apperror.New(apperror.KindForbidden, apperror.CodeForbidden, "file belongs to another user")
+	ErrFileCorrupted      = apperror.New(apperror.KindConflict, apperror.CodeFileCorrupted, "stored file content is damaged; upload the file again")
+	ErrFilePublishDenied  = apperror.New(apperror.KindForbidden, apperror.CodeForbidden, "only the owner of a file or a moderator can make it public")
+)
+
 type FileService interface {
 	UploadFile(ctx context.Context, file *multipart.FileHeader, req dto.UploadFileRequest, userID int) (*dto.FileResponse, error)
 	GetFileByID(ctx context.Context, id int) (*dto.FileResponse, error)
 	GetFilesByUserID(ctx context.Context, userID int) ([]dto.FileResponse, error)
+	// GetSharedFiles lists the files of other users that were shared with the user
+	GetSharedFiles(ctx context.Context, userID int) ([]dto.FileResponse, error)
 	GetAllFiles(ctx context.Context) ([]dto.FileResponse, error)
-	UpdateFile(ctx context.Context, id int, req dto.UpdateFileRequest) (*dto.FileResponse, error)
+	GetFilesByUserIDWithPagination(ctx context.Context, userID int, paginationParams pagination.PaginationParams, filterParams pagination.FileFilterParams) ([]dto.FileResponse, pagination.PaginationMeta, error)
+	GetAllFilesWithPagination(ctx context.Context, paginationParams pagination.PaginationParams, filterParams pagination.FileFilterParams) ([]dto.FileResponse, pagination.PaginationMeta, error)
+	// UpdateFile changes the description and category of a file for the user; only the owner and
+	// moderator+ may put it in a public category
+	UpdateFile(ctx context.Context, id, userID int, req dto.UpdateFileRequest) (*dto.FileResponse, error)
 	DeleteFile(ctx context.Context, id int) error
-	GetFileEntity(ctx context.Context, id int) (*entity.File, error)
+	OpenFile(ctx context.Context, id int) (*entity.File, io.ReadSeekCloser, error)
+	OpenServedFile(ctx context.Context, id int, variant, expires, signature, clientIP string) (*entity.File, io.ReadSeekCloser, *storage.ObjectInfo, error)
+	OpenFileVariant(ctx context.Context, id int, name string) (*entity.File, io.ReadSeekCloser, *entity.FileVariant, error)
+	VerifyStoredFiles(ctx context.Context) error
+	StartIntegrityWorker(interval time.Duration)
+	// UploadFileVersion replaces the content of a file, keeping the previous content as a version
+	UploadFileVersion(ctx context.Context, id int, file *multipart.FileHeader, userID int) (*dto.FileResponse, error)
+	// GetFileVersions lists the versions of a file, the current one first
+	GetFileVersions(ctx context.Context, id int) ([]dto.FileVersionResponse, error)
+	OpenFileVersion(ctx context.Context, id, version int) (*entity.FileVersion, io.ReadSeekCloser, error)
+	RestoreFileVersion(ctx context.Context, id, version int) (*dto.FileResponse, error)
+	// MoveFile puts a file of the user into another of their folders; storage usage is unaffected as
+	// the file keeps its owner
+	MoveFile(ctx context.Context, id, userID int, req dto.MoveFileRequest) (*dto.FileResponse, error)
 }
 
 type fileService struct {
-	fileRepo    repository.FileRepository
-	fileStorage storage.FileStorage
-	config      *config.Config
+	fileRepo       repository.FileRepository
+	versionRepo    repository.FileVersionRepository
+	folderRepo     repository.FolderRepository
+	userRepo       repository.UserRepository
+	blobs          *blobStore
+	variantService ImageVariantService
+	scanService    FileScanService
+	quotaService   StorageQuotaService
+	fileStorage    storage.FileStorage
+	config         *config.Config
 }
 
-func NewFileService(fileRepo repository.FileRepository, fileStorage storage.FileStorage, config *config.Config) FileService {
+func NewFileService(fileRepo repository.FileRepository, versionRepo repository.FileVersionRepository, folderRepo repository.FolderRepository, userRepo repository.UserRepository, blobRepo repository.FileBlobRepository, variantService ImageVariantService, scanService FileScanService, quotaService StorageQuotaService, fileStorage storage.FileStorage, config *config.Config) FileService {
 	return &fileService{
-		fileRepo:    fileRepo,
-		fileStorage: fileStorage,
-		config:      config,
+		fileRepo:       fileRepo,
+		versionRepo:    versionRepo,
+		folderRepo:     folderRepo,
+		userRepo:       userRepo,
+		blobs:          newBlobStore(blobRepo, fileStorage),
+		variantService: variantService,
+		scanService:    scanService,
+		quotaService:   quotaService,
+		fileStorage:    fileStorage,
+		config:         config,
 	}
 }
 
 func (s *fileService) UploadFile(ctx context.Context, file *multipart.FileHeader, req dto.UploadFileRequest, userID int) (*dto.FileResponse, error) {
 	logger.Info("Uploading file", zap.String("original_name", file.Filename), zap.Int("user_id", userID))
 	
-	// Validate file size
-	if file.Size > s.config.Upload.MaxFileSize {
-		logger.Warn("File size exceeds limit", zap.Int64("size", file.Size), zap.Int64("max_size", s.config.Upload.MaxFileSize))
-		return nil, errors.New("file size exceeds maximum allowed size")
+	if req.FolderID != nil {
+		if _, err := getOwnedFolder(ctx, s.folderRepo, *req.FolderID, userID); err != nil {
+			return nil, err
+		}
 	}
 	
-	// Validate file type
-	if !slices.Contains(s.config.Upload.AllowedTypes, file.Header.Get("Content-Type")) {
-		logger.Warn("Invalid file type", zap.String("mime_type", file.Header.Get("Content-Type")))
-		return nil, errors.New("file type not allowed")
+	quota, err := s.quotaService.CheckQuota(ctx, userID, file.Size, 1)
+	if err != nil {
+		return nil, err
 	}
 	
-	// Save file to storage
-	fileName, filePath, err := s.fileStorage.SaveFile(file, s.config.Upload.UploadPath)
+	content, err := s.storeUploadedFile(ctx, file)
 	if err != nil {
-		logger.Error("Failed to save file", zap.Error(err))
 		return nil, err
 	}
 	
 	// Save to database
-	fileEntity, err := s.fileRepo.Create(ctx, fileName, file.Filename, filePath, file.Size, file.Header.Get("Content-Type"), req.Description, req.Category, userID)
+	fileName := storage.NewKey(file.Filename)
+	fileEntity, err := s.fileRepo.Create(ctx, fileName, file.Filename, content.FilePath, content.FileSize, content.MimeType, req.Description, req.Category, userID, content.Checksum, s.scanService.InitialStatus(), content.Metadata, req.FolderID, quota)
 	if err != nil {
-		// Delete file if database save fails
-		s.fileStorage.DeleteFile(filePath)
+		// Release the content if database save fails
+		s.blobs.release(ctx, content.Checksum)
+		if errors.Is(err, repository.ErrQuotaExceeded) {
+			logger.Warn("Storage quota exceeded while saving file", zap.Int("user_id", userID))
+			return nil, quotaExceededError(ctx, s.quotaService, userID)
+		}
 		logger.Error("Failed to save file to database", zap.Error(err))
 		return nil, err
 	}
 	
 	logger.Info("File uploaded successfully", zap.Int("file_id", fileEntity.ID))
 	
-	return s.mapFileToResponse(fileEntity), nil
+	// The file is scanned for malware in the background; images then get their thumbnails and other variants
+	s.scanService.Enqueue(fileEntity)
+	
+	return s.mapFileToResponse(ctx, fileEntity), nil
 }
 
 func (s *fileService) GetFileByID(ctx context.Context, id int) (*dto.FileResponse, error) {
@@ -83,13 +141,13 @@ func (s *fileService) GetFileByID(ctx context.Context, id int) (*dto.FileRespons
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			logger.Warn("File not found", zap.Int("file_id", id))
-			return nil, errors.New("file not found")
+			return nil, ErrFileNotFound
 		}
 		logger.Error("Failed to get file", zap.Error(err))
 		return nil, err
 	}
 	
-	return s.mapFileToResponse(file), nil
+	return s.mapFileToResponse(ctx, file), nil
 }
 
 func (s *fileService) GetFilesByUserID(ctx context.Context, userID int) ([]dto.FileResponse, error) {
@@ -103,12 +161,29 @@ func (s *fileService) GetFilesByUserID(ctx context.Context, userID int) ([]dto.F
 	
 	var fileResponses []dto.FileResponse
 	for _, file := range files {
-		fileResponses = append(fileResponses, *s.mapFileToResponse(&file))
+		fileResponses = append(fileResponses, *s.mapFileToResponse(ctx, &file))
 	}
 	
 	return fileResponses, nil
 }
 
+func (s *fileService) GetSharedFiles(ctx context.Context, userID int) ([]dto.FileResponse, error) {
+	logger.Debug("Getting files shared with user", zap.Int("user_id", userID))
+
+	files, err := s.fileRepo.GetSharedWithUser(ctx, userID)
+	if err != nil {
+		logger.Error("Failed to get files shared with user", zap.Error(err))
+		return nil, err
+	}
+
+	fileResponses := make([]dto.FileResponse, len(files))
+	for i := range files {
+		fileResponses[i] = *s.mapFileToResponse(ctx, &files[i])
+	}
+
+	return fileResponses, nil
+}
+
 func (s *fileService) GetAllFiles(ctx context.Context) ([]dto.FileResponse, error) {
 	logger.Debug("Getting all files")
 	
@@ -120,26 +195,97 @@ func (s *fileService) GetAllFiles(ctx context.Context) ([]dto.FileResponse, erro
 	
 	var fileResponses []dto.FileResponse
 	for _, file := range files {
-		fileResponses = append(fileResponses, *s.mapFileToResponse(&file))
+		fileResponses = append(fileResponses, *s.mapFileToResponse(ctx, &file))
 	}
 	
 	return fileResponses, nil
 }
 
-func (s *fileService) UpdateFile(ctx context.Context, id int, req dto.UpdateFileRequest) (*dto.FileResponse, error) {
-	logger.Info("Updating file", zap.Int("file_id", id))
+func (s *fileService) GetFilesByUserIDWithPagination(ctx context.Context, userID int, paginationParams pagination.PaginationParams, filterParams pagination.FileFilterParams) ([]dto.FileResponse, pagination.PaginationMeta, error) {
+	logger.Debug("Getting files by user ID with pagination",
+		zap.Int("user_id", userID),
+		zap.Int("page", paginationParams.Page),
+		zap.Int("limit", paginationParams.Limit),
+		zap.String("sort", paginationParams.Sort),
+		zap.String("order", paginationParams.Order),
+		zap.String("search", paginationParams.Search))
+	
+	var files []entity.File
+	var paginationMeta pagination.PaginationMeta
+	var err error
+	if paginationParams.CursorMode {
+		files, paginationMeta, err = s.fileRepo.GetByUserIDWithCursor(ctx, userID, paginationParams, filterParams)
+	} else {
+		var totalCount int
+		files, totalCount, err = s.fileRepo.GetByUserIDWithPagination(ctx, userID, paginationParams, filterParams)
+		paginationMeta = pagination.NewPaginationMeta(paginationParams.Page, paginationParams.Limit, totalCount)
+	}
+	if err != nil {
+		logger.Error("Failed to get files by user ID with pagination", zap.Error(err))
+		return nil, pagination.PaginationMeta{}, err
+	}
+	
+	fileResponses := make([]dto.FileResponse, 0, len(files))
+	for _, file := range files {
+		fileResponses = append(fileResponses, *s.mapFileToResponse(ctx, &file))
+	}
+	
+	return fileResponses, paginationMeta, nil
+}
+
+func (s *fileService) GetAllFilesWithPagination(ctx context.Context, paginationParams pagination.PaginationParams, filterParams pagination.FileFilterParams) ([]dto.FileResponse, pagination.PaginationMeta, error) {
+	logger.Debug("Getting all files with pagination",
+		zap.Int("page", paginationParams.Page),
+		zap.Int("limit", paginationParams.Limit),
+		zap.String("sort", paginationParams.Sort),
+		zap.String("order", paginationParams.Order),
+		zap.String("search", paginationParams.Search))
+	
+	var files []entity.File
+	var paginationMeta pagination.PaginationMeta
+	var err error
+	if paginationParams.CursorMode {
+		files, paginationMeta, err = s.fileRepo.GetAllWithCursor(ctx, paginationParams, filterParams)
+	} else {
+		var totalCount int
+		files, totalCount, err = s.fileRepo.GetAllWithPagination(ctx, paginationParams, filterParams)
+		paginationMeta = pagination.NewPaginationMeta(paginationParams.Page, paginationParams.Limit, totalCount)
+	}
+	if err != nil {
+		logger.Error("Failed to get files with pagination", zap.Error(err))
+		return nil, pagination.PaginationMeta{}, err
+	}
+	
+	fileResponses := make([]dto.FileResponse, 0, len(files))
+	for _, file := range files {
+		fileResponses = append(fileResponses, *s.mapFileToResponse(ctx, &file))
+	}
+	
+	return fileResponses, paginationMeta, nil
+}
+
+func (s *fileService) UpdateFile(ctx context.Context, id, userID int, req dto.UpdateFileRequest) (*dto.FileResponse, error) {
+	logger.Info("Updating file", zap.Int("file_id", id), zap.Int("user_id", userID))
 	
 	// Check if file exists
-	_, err := s.fileRepo.GetByID(ctx, id)
+	existing, err := s.fileRepo.GetByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			logger.Warn("File not found for update", zap.Int("file_id", id))
-			return nil, errors.New("file not found")
+			return nil, ErrFileNotFound
 		}
 		logger.Error("Failed to get file for update", zap.Error(err))
 		return nil, err
 	}
 	
+	// Files in public categories are served without signed URLs, so users the file is only shared
+	// with must not publish it
+	if req.Category != existing.Category && isPublicCategory(s.config, req.Category) {
+		if err := s.checkCanPublish(ctx, existing, userID); err != nil {
+			return nil, err
+		}
+	}
+	
 	// Update file
 	file, err := s.fileRepo.Update(ctx, id, req.Description, req.Category)
 	if err != nil {
@@ -149,7 +295,28 @@ func (s *fileService) UpdateFile(ctx context.Context, id int, req dto.UpdateFile
 	
 	logger.Info("File updated successfully", zap.Int("file_id", id))
 	
-	return s.mapFileToResponse(file), nil
+	return s.mapFileToResponse(ctx, file), nil
+}
+
+// checkCanPublish allows the owner of the file and moderator+ to put it in a public category
+func (s *fileService) checkCanPublish(ctx context.Context, file *entity.File, userID int) error {
+	if file.UploadedBy == userID {
+		return nil
+	}
+	
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrUserNotFound
+		}
+		logger.Error("Failed to get user for publishing file", zap.Error(err), zap.Int("user_id", userID))
+		return err
+	}
+	if user.Role != entity.RoleModerator && user.Role != entity.RoleAdmin {
+		logger.Warn("File publishing denied", zap.Int("file_id", file.ID), zap.Int("user_id", userID))
+		return ErrFilePublishDenied
+	}
+	return nil
 }
 
 func (s *fileService) DeleteFile(ctx context.Context, id int) error {
@@ -159,46 +326,390 @@ func (s *fileService) DeleteFile(ctx context.Context, id int) error {
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			logger.Warn("File not found for deletion", zap.Int("file_id", id))
-			return errors.New("file not found")
+			return ErrFileNotFound
 		}
 		logger.Error("Failed to get file for deletion", zap.Error(err))
 		return err
 	}
 	
-	// Delete from database
-	if err := s.fileRepo.Delete(ctx, id); err != nil {
+	// Variants are removed first, as their rows are deleted along with the file; if the file
+	// survives, missing variants are rendered again on request
+	s.variantService.DeleteVariants(ctx, id)
+	
+	// Only the request that deleted the row releases its content: a concurrent deletion of the same
+	// file would otherwise take away the references of other files with the same content
+	deleted, versions, err := s.fileRepo.Delete(ctx, file.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Warn("File already deleted", zap.Int("file_id", id))
+			return ErrFileNotFound
+		}
 		logger.Error("Failed to delete file from database", zap.Error(err))
 		return err
 	}
 	
-	// Delete from storage
-	if err := s.fileStorage.DeleteFile(file.FilePath); err != nil {
+	// Delete from storage; earlier versions are deleted along with the file
+	if err := s.blobs.deleteFileContent(ctx, deleted); err != nil {
 		logger.Error("Failed to delete file from storage", zap.Error(err))
 		// Note: File is already deleted from database, but physical file remains
 		// In production, you might want to have a cleanup job for orphaned files
 	}
+	for _, version := range versions {
+		if err := s.blobs.deleteContent(ctx, version.Checksum, version.FilePath); err != nil {
+			logger.Error("Failed to delete file version from storage", zap.Error(err), zap.Int("version", version.Version))
+		}
+	}
+	
+	// Avatar sizes have no rows of their own; images may have left the avatar category since
+	if isImage(deleted.MimeType) {
+		deleteAvatarVariants(ctx, s.fileStorage, deleted.FileName)
+	}
 	
 	logger.Info("File deleted successfully", zap.Int("file_id", id))
 	
 	return nil
 }
 
-func (s *fileService) GetFileEntity(ctx context.Context, id int) (*entity.File, error) {
-	return s.fileRepo.GetByID(ctx, id)
+func (s *fileService) MoveFile(ctx context.Context, id, userID int, req dto.MoveFileRequest) (*dto.FileResponse, error) {
+	logger.Info("Moving file", zap.Int("file_id", id), zap.Int("user_id", userID))
+	
+	file, err := s.fileRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Warn("File not found for move", zap.Int("file_id", id))
+			return nil, ErrFileNotFound
+		}
+		logger.Error("Failed to get file for move", zap.Error(err))
+		return nil, err
+	}
+	
+	// Files only go into folders of their owner, so folder sizes always count towards the owner's quota
+	if file.UploadedBy != userID {
+		logger.Warn("File belongs to another user", zap.Int("file_id", id), zap.Int("user_id", userID))
+		return nil, ErrFileNotOwned
+	}
+	if req.FolderID != nil {
+		if _, err := getOwnedFolder(ctx, s.folderRepo, *req.FolderID, userID); err != nil {
+			return nil, err
+		}
+	}
+	
+	movedFile, err := s.fileRepo.Move(ctx, id, req.FolderID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrFileNotFound
+		}
+		logger.Error("Failed to move file", zap.Error(err))
+		return nil, err
+	}
+	
+	logger.Info("File moved successfully", zap.Int("file_id", id))
+	
+	return s.mapFileToResponse(ctx, movedFile), nil
 }
 
-func (s *fileService) mapFileToResponse(file *entity.File) *dto.FileResponse {
+// OpenFile returns the file's metadata and a stream of its content from the storage backend
+func (s *fileService) OpenFile(ctx context.Context, id int) (*entity.File, io.ReadSeekCloser, error) {
+	file, err := s.fileRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, ErrFileNotFound
+		}
+		return nil, nil, err
+	}
+	if err := checkScanStatus(file.ScanStatus); err != nil {
+		return nil, nil, err
+	}
+
+	content, _, err := s.openContent(ctx, file.Checksum, file.FilePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return file, content, nil
+}
+
+// OpenServedFile streams a file, or a variant of it, requested through a file URL. Files in public
+// categories are served to anyone; all others require a valid, unexpired signature.
+func (s *fileService) OpenServedFile(ctx context.Context, id int, variant, expires, signature, clientIP string) (*entity.File, io.ReadSeekCloser, *storage.ObjectInfo, error) {
+	signed := expires != "" || signature != ""
+	if signed {
+		// Checked before the lookup, so forged URLs cannot probe for files
+		if err := s.fileStorage.VerifyFileURL(id, variant, expires, signature, clientIP); err != nil {
+			logger.Warn("Rejected file URL", zap.Error(err), zap.Int("file_id", id))
+			if errors.Is(err, storage.ErrURLExpired) {
+				return nil, nil, nil, ErrFileURLExpired
+			}
+			return nil, nil, nil, ErrFileURLInvalid
+		}
+	}
+
+	file, err := s.fileRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, nil, ErrFileNotFound
+		}
+		return nil, nil, nil, err
+	}
+
+	if !signed && !isPublicCategory(s.config, file.Category) {
+		logger.Warn("Unsigned URL for a private file", zap.Int("file_id", id))
+		return nil, nil, nil, ErrFileURLInvalid
+	}
+
+	// Avatar sizes are re-encoded from the decoded image, so they are served while the original is
+	// being scanned
+	if variant != "" && file.Category == avatarCategory {
+		size, ok := avatarSizes[variant]
+		if !ok {
+			return nil, nil, nil, ErrFileNotFound
+		}
+		content, info, err := s.openObject(ctx, avatarVariantName(file.FileName, size))
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		return file, content, info, nil
+	}
+	if err := checkScanStatus(file.ScanStatus); err != nil {
+		return nil, nil, nil, err
+	}
+	if variant != "" {
+		content, imageVariant, err := s.variantService.OpenVariant(ctx, file, variant)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		return file, content, &storage.ObjectInfo{
+			Key:          imageVariant.StorageKey,
+			Size:         imageVariant.FileSize,
+			ContentType:  imageVariant.MimeType,
+			LastModified: imageVariant.CreatedAt,
+		}, nil
+	}
+
+	content, info, err := s.openContent(ctx, file.Checksum, file.FilePath)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	// Served as the type detected at upload, whatever the backend guesses from the key
+	info.ContentType = file.MimeType
+	return file, content, info, nil
+}
+
+// OpenFileVariant streams a variant of an image, such as its thumbnail, rendering it if needed
+func (s *fileService) OpenFileVariant(ctx context.Context, id int, name string) (*entity.File, io.ReadSeekCloser, *entity.FileVariant, error) {
+	file, err := s.fileRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, nil, ErrFileNotFound
+		}
+		return nil, nil, nil, err
+	}
+	if err := checkScanStatus(file.ScanStatus); err != nil {
+		return nil, nil, nil, err
+	}
+
+	content, variant, err := s.variantService.OpenVariant(ctx, file, name)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return file, content, variant, nil
+}
+
+// VerifyStoredFiles checks a batch of stored content against its checksums and deletes content no file
+// references anymore
+func (s *fileService) VerifyStoredFiles(ctx context.Context) error {
+	batchSize := s.config.Integrity.BatchSize
+	if err := s.blobs.verify(ctx, time.Now().Add(-s.config.Integrity.ReverifyAfter), batchSize); err != nil {
+		logger.Error("Failed to verify stored files", zap.Error(err))
+		return err
+	}
+	if err := s.blobs.collectGarbage(ctx, batchSize); err != nil {
+		logger.Error("Failed to delete unreferenced file content", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// StartIntegrityWorker periodically runs VerifyStoredFiles
+func (s *fileService) StartIntegrityWorker(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			s.VerifyStoredFiles(context.Background())
+		}
+	}()
+}
+
+// uploadSizeLimit lowers an upload size limit to the largest file the scanner accepts, since larger
+// files could never be scanned and so never be downloaded
+func uploadSizeLimit(limit int64, scanService FileScanService) int64 {
+	if scanLimit := scanService.MaxSize(); scanLimit > 0 && scanLimit < limit {
+		return scanLimit
+	}
+	return limit
+}
+
+// storeUploadedFile validates an uploaded file and stores its content, stripped of identifying
+// metadata. The content is returned as a version of no file yet; its reference has to be released
+// if no file ends up using it.
+func (s *fileService) storeUploadedFile(ctx context.Context, file *multipart.FileHeader) (*entity.FileVersion, error) {
+	// Validate file size
+	if maxSize := uploadSizeLimit(s.config.Upload.MaxFileSize, s.scanService); file.Size > maxSize {
+		logger.Warn("File size exceeds limit", zap.Int64("size", file.Size), zap.Int64("max_size", maxSize))
+		return nil, ErrFileTooLarge
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		logger.Error("Failed to open uploaded file", zap.Error(err))
+		return nil, ErrFileStorage.Wrap(err)
+	}
+	defer src.Close()
+
+	// Validate file type from the content; the client's Content-Type and file name are only checked against it
+	mimeType, content, err := filetype.Detect(src)
+	if err != nil {
+		logger.Error("Failed to read uploaded file", zap.Error(err))
+		return nil, ErrFileStorage.Wrap(err)
+	}
+	if err := validateFileType(s.config, mimeType, file.Header.Get("Content-Type"), file.Filename); err != nil {
+		logger.Warn("Invalid file type", zap.String("mime_type", mimeType), zap.String("declared_type", file.Header.Get("Content-Type")), zap.String("original_name", file.Filename))
+		return nil, err
+	}
+
+	// Identifying metadata, such as the GPS position of photos, is removed before the content is stored
+	stripped, err := stripContentMetadata(s.config, content, mimeType)
+	if err != nil {
+		logger.Warn("Failed to strip file metadata", zap.Error(err), zap.String("mime_type", mimeType))
+		return nil, err
+	}
+
+	var body io.Reader = src
+	size := file.Size
+	var checksum string
+	if stripped != nil {
+		defer stripped.Close()
+		body, size, checksum = stripped, stripped.size, stripped.checksum
+	} else {
+		// Hash the content, then rewind; identical content is only stored once
+		hash := sha256.New()
+		if _, err := io.Copy(hash, content); err != nil {
+			logger.Error("Failed to read uploaded file", zap.Error(err))
+			return nil, ErrFileStorage.Wrap(err)
+		}
+		checksum = hex.EncodeToString(hash.Sum(nil))
+		if _, err := src.Seek(0, io.SeekStart); err != nil {
+			logger.Error("Failed to rewind uploaded file", zap.Error(err))
+			return nil, ErrFileStorage.Wrap(err)
+		}
+	}
+
+	// Save file to storage
+	key, err := s.blobs.put(ctx, checksum, body, size, mimeType)
+	if err != nil {
+		logger.Error("Failed to save file", zap.Error(err))
+		return nil, ErrFileStorage.Wrap(err)
+	}
+
+	return &entity.FileVersion{
+		OriginalName: file.Filename,
+		FilePath:     key,
+		FileSize:     size,
+		MimeType:     mimeType,
+		Checksum:     checksum,
+		Metadata:     extractContentMetadata(ctx, s.fileStorage, s.config, key, mimeType),
+	}, nil
+}
+
+// openContent streams the content of a file or file version, refusing content found damaged, whose
+// checksum no longer matches what would be served
+func (s *fileService) openContent(ctx context.Context, checksum, key string) (io.ReadSeekCloser, *storage.ObjectInfo, error) {
+	if err := s.blobs.checkIntact(ctx, checksum); err != nil {
+		return nil, nil, err
+	}
+	return s.openObject(ctx, key)
+}
+
+// openObject streams a stored object by its key; the stream can seek to serve range requests
+func (s *fileService) openObject(ctx context.Context, key string) (io.ReadSeekCloser, *storage.ObjectInfo, error) {
+	content, info, err := storage.OpenSeekable(ctx, s.fileStorage, key)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) || errors.Is(err, storage.ErrInvalidKey) {
+			logger.Warn("Stored object not found", zap.String("key", key))
+			return nil, nil, ErrFileNotFound
+		}
+		logger.Error("Failed to open stored object", zap.Error(err), zap.String("key", key))
+		return nil, nil, ErrFileStorage.Wrap(err)
+	}
+	return content, info, nil
+}
+
+func (s *fileService) mapFileToResponse(ctx context.Context, file *entity.File) *dto.FileResponse {
+	var variantURLs map[string]string
+	if names := s.variantService.VariantNames(file); len(names) > 0 && file.ScanStatus == entity.FileScanStatusClean {
+		variantURLs = make(map[string]string, len(names))
+		for _, name := range names {
+			variantURLs[name] = fileURL(ctx, s.fileStorage, s.config, file.ID, file.Category, name, s.config.FileURL.TTL)
+		}
+	}
+
 	return &dto.FileResponse{
 		ID:           file.ID,
 		FileName:     file.FileName,
 		OriginalName: file.OriginalName,
-		FilePath:     s.fileStorage.GetFileURL(file.FilePath, s.config.Upload.BaseURL),
+		FilePath:     fileURL(ctx, s.fileStorage, s.config, file.ID, file.Category, "", s.config.FileURL.TTL),
 		FileSize:     file.FileSize,
 		MimeType:     file.MimeType,
+		Checksum:     file.Checksum,
+		Version:      file.Version,
+		ScanStatus:   file.ScanStatus,
+		Metadata:     mapFileMetadataToResponse(file.Metadata),
+		VariantURLs:  variantURLs,
 		Description:  file.Description,
 		Category:     file.Category,
 		UploadedBy:   file.UploadedBy,
+		FolderID:     file.FolderID,
 		CreatedAt:    file.CreatedAt,
 		UpdatedAt:    file.UpdatedAt,
 	}
-}
\ No newline at end of file
+}
+
+func mapFileMetadataToResponse(metadata *entity.FileMetadata) *dto.FileMetadata {
+	if metadata == nil {
+		return nil
+	}
+	return &dto.FileMetadata{
+		Width:    metadata.Width,
+		Height:   metadata.Height,
+		Pages:    metadata.Pages,
+		Frames:   metadata.Frames,
+		Duration: metadata.Duration,
+	}
+}
+
+// fileURL returns the URL a file or one of its variants is served from: permanent for files in public
+// categories, otherwise signed and valid for ttl
+func fileURL(ctx context.Context, fileStorage storage.FileStorage, config *config.Config, fileID int, category, variant string, ttl time.Duration) string {
+	if isPublicCategory(config, category) {
+		ttl = 0
+	}
+	return fileStorage.GetFileURL(ctx, fileID, variant, ttl)
+}
+
+// validateFileType checks the detected type of uploaded content against the allowed types and the
+// type and name the client declared
+func validateFileType(config *config.Config, detected, declared, name string) error {
+	err := filetype.Validate(detected, declared, name, config.Upload.AllowedTypes)
+	switch {
+	case errors.Is(err, filetype.ErrNotAllowed):
+		return ErrFileTypeNotAllowed
+	case errors.Is(err, filetype.ErrMismatch):
+		return ErrFileTypeMismatch
+	}
+	return err
+}
+
+func isPublicCategory(config *config.Config, category string) bool {
+	return category != "" && slices.Contains(config.FileURL.PublicCategories, category)
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"go-template/internal/config"
+	"go-template/internal/dto"
+	"go-template/internal/entity"
+	"go-template/internal/repository"
+)
+
+// fakeFileRepository keeps files and their versions in memory. GetByID can be made to keep returning
+// deleted files, as a read that happened before a concurrent deletion would.
+type fakeFileRepository struct {
+	repository.FileRepository
+	files        map[int]*entity.File
+	versions     map[int][]entity.FileVersion
+	deleted      map[int]*entity.File
+	staleGetByID bool
+}
+
+func newFakeFileRepository(files ...*entity.File) *fakeFileRepository {
+	r := &fakeFileRepository{files: map[int]*entity.File{}, versions: map[int][]entity.FileVersion{}, deleted: map[int]*entity.File{}}
+	for _, file := range files {
+		r.files[file.ID] = file
+	}
+	return r
+}
+
+func (r *fakeFileRepository) GetByID(ctx context.Context, id int) (*entity.File, error) {
+	file, ok := r.files[id]
+	if !ok && r.staleGetByID {
+		file, ok = r.deleted[id]
+	}
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	found := *file
+	return &found, nil
+}
+
+func (r *fakeFileRepository) GetByUserID(ctx context.Context, userID int) ([]entity.File, error) {
+	var files []entity.File
+	for _, file := range r.files {
+		if file.UploadedBy == userID {
+			files = append(files, *file)
+		}
+	}
+	return files, nil
+}
+
+func (r *fakeFileRepository) Update(ctx context.Context, id int, description, category string) (*entity.File, error) {
+	file, ok := r.files[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	file.Description, file.Category = description, category
+	updated := *file
+	return &updated, nil
+}
+
+func (r *fakeFileRepository) Delete(ctx context.Context, id int) (*entity.File, []entity.FileVersion, error) {
+	file, ok := r.files[id]
+	if !ok {
+		return nil, nil, sql.ErrNoRows
+	}
+	versions := r.versions[id]
+	delete(r.files, id)
+	delete(r.versions, id)
+	r.deleted[id] = file
+	return file, versions, nil
+}
+
+// fakeImageVariantService records the files whose variants were deleted
+type fakeImageVariantService struct {
+	ImageVariantService
+	deleted []int
+}
+
+func (s *fakeImageVariantService) DeleteVariants(ctx context.Context, fileID int) {
+	s.deleted = append(s.deleted, fileID)
+}
+
+func (s *fakeImageVariantService) VariantNames(file *entity.File) []string {
+	return nil
+}
+
+// newTestFileService stores the files in a fakeFileRepository with blobs in blobRepo
+func newTestFileService(t *testing.T, blobRepo *fakeFileBlobRepository, files ...*entity.File) (*fileService, *fakeFileRepository) {
+	t.Helper()
+
+	_, _, fileStorage := newTestBlobStore(t)
+	fileRepo := newFakeFileRepository(files...)
+	s := NewFileService(fileRepo, nil, nil, nil, blobRepo, &fakeImageVariantService{}, nil, nil, fileStorage, &config.Config{}).(*fileService)
+	return s, fileRepo
+}
+
+func TestDeleteFileReleasesContentOnce(t *testing.T) {
+	// Files 1 and 2 have identical content; file 1 also has an earlier version
+	blobRepo := &fakeFileBlobRepository{blobs: map[string]*entity.FileBlob{
+		"shared":  {Checksum: "shared", RefCount: 2, Stored: true},
+		"version": {Checksum: "version", RefCount: 1, Stored: true},
+	}}
+	s, fileRepo := newTestFileService(t, blobRepo,
+		&entity.File{ID: 1, UploadedBy: 1, Checksum: "shared"},
+		&entity.File{ID: 2, UploadedBy: 1, Checksum: "shared"},
+	)
+	fileRepo.versions[1] = []entity.FileVersion{{FileID: 1, Version: 1, Checksum: "version"}}
+
+	if err := s.DeleteFile(context.Background(), 1); err != nil {
+		t.Fatalf("DeleteFile: %v", err)
+	}
+
+	// A concurrent deletion read the file before it was deleted
+	fileRepo.staleGetByID = true
+	if err := s.DeleteFile(context.Background(), 1); !errors.Is(err, ErrFileNotFound) {
+		t.Errorf("second DeleteFile error %v, want %v", err, ErrFileNotFound)
+	}
+
+	if refs := blobRepo.blobs["shared"].RefCount; refs != 1 {
+		t.Errorf("shared content has %d references, want the one of file 2", refs)
+	}
+	if refs := blobRepo.blobs["version"].RefCount; refs != 0 {
+		t.Errorf("version content has %d references, want 0", refs)
+	}
+}
+
+// publishingUsers has the owner 1 of the files, user 2 with a write grant, moderator 3 and admin 4
+func publishingUsers() *fakeQuotaUserRepository {
+	return &fakeQuotaUserRepository{users: map[int]*entity.User{
+		1: {ID: 1, Role: entity.RoleUser},
+		2: {ID: 2, Role: entity.RoleUser},
+		3: {ID: 3, Role: entity.RoleModerator},
+		4: {ID: 4, Role: entity.RoleAdmin},
+	}}
+}
+
+func TestUpdateFilePublicCategory(t *testing.T) {
+	tests := []struct {
+		name     string
+		userID   int
+		category string
+		current  string
+		wantErr  error
+	}{
+		{"owner publishes", 1, "avatar", "docs", nil},
+		{"write grant publishes", 2, "avatar", "docs", ErrFilePublishDenied},
+		{"moderator publishes", 3, "avatar", "docs", nil},
+		{"admin publishes", 4, "avatar", "docs", nil},
+		{"write grant sets a private category", 2, "invoices", "docs", nil},
+		{"write grant keeps a public category", 2, "avatar", "avatar", nil},
+		{"write grant unpublishes", 2, "docs", "avatar", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, fileRepo := newTestFileService(t, &fakeFileBlobRepository{}, &entity.File{ID: 10, UploadedBy: 1, Category: tt.current})
+			s.userRepo = publishingUsers()
+			s.config.FileURL.PublicCategories = []string{"avatar"}
+
+			_, err := s.UpdateFile(context.Background(), 10, tt.userID, dto.UpdateFileRequest{Description: "photo", Category: tt.category})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpdateFile error %v, want %v", err, tt.wantErr)
+			}
+
+			want := tt.category
+			if tt.wantErr != nil {
+				want = tt.current
+			}
+			if category := fileRepo.files[10].Category; category != want {
+				t.Errorf("category = %q, want %q", category, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,343 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"go-template/internal/config"
+	"go-template/internal/dto"
+	"go-template/internal/entity"
+	"go-template/internal/logger"
+	"go-template/internal/repository"
+	"go-template/pkg/apperror"
+	"go-template/pkg/tokens"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrShareLinkNotFound        = apperror.New(apperror.KindNotFound, apperror.CodeShareLinkNotFound, "share link not found")
+	ErrShareLinkExpired         = apperror.New(apperror.KindGone, apperror.CodeShareLinkExpired, "share link has expired or reached its download limit")
+	ErrShareLinkInvalidPassword = apperror.New(apperror.KindUnauthorized, apperror.CodeShareInvalidPassword, "share link password is missing or incorrect")
+	ErrShareLinkExpiryInPast    = apperror.New(apperror.KindInvalid, apperror.CodeInvalidRequest, "expiry of a share link must be in the future")
+	ErrFileGrantNotFound        = apperror.New(apperror.KindNotFound, apperror.CodeShareGrantNotFound, "file is not shared with this user")
+	ErrFileGrantToOwner         = apperror.New(apperror.KindInvalid, apperror.CodeInvalidRequest, "files cannot be shared with their owner")
+)
+
+// FileShareService shares files through links that work without an account and through grants of read
+// or write access to other users. Only the owner of a file manages how it is shared.
+type FileShareService interface {
+	CreateShareLink(ctx context.Context, fileID, userID int, req dto.CreateShareLinkRequest) (*dto.ShareLinkResponse, error)
+	// GetShareLinks lists the links of a file, revoked and expired ones included
+	GetShareLinks(ctx context.Context, fileID, userID int) ([]dto.ShareLinkResponse, error)
+	RevokeShareLink(ctx context.Context, fileID, linkID, userID int) error
+	// OpenSharedFile checks the link and its password and returns the link and the shared file
+	OpenSharedFile(ctx context.Context, token, password string) (*entity.FileShareLink, *entity.File, io.ReadSeekCloser, error)
+	// CountSharedDownload records a download through the link, and fails when it has no downloads left
+	CountSharedDownload(ctx context.Context, linkID int) error
+	GetFileGrants(ctx context.Context, fileID, userID int) ([]dto.FileGrantResponse, error)
+	// GrantFileAccess gives another user access to a file, replacing an earlier grant
+	GrantFileAccess(ctx context.Context, fileID, userID, granteeID int, req dto.GrantFileAccessRequest) (*dto.FileGrantResponse, error)
+	RevokeFileAccess(ctx context.Context, fileID, userID, granteeID int) error
+}
+
+type fileShareService struct {
+	fileRepo    repository.FileRepository
+	linkRepo    repository.FileShareLinkRepository
+	grantRepo   repository.FileGrantRepository
+	userRepo    repository.UserRepository
+	fileService FileService
+	config      *config.Config
+}
+
+func NewFileShareService(fileRepo repository.FileRepository, linkRepo repository.FileShareLinkRepository, grantRepo repository.FileGrantRepository, userRepo repository.UserRepository, fileService FileService, config *config.Config) FileShareService {
+	return &fileShareService{
+		fileRepo:    fileRepo,
+		linkRepo:    linkRepo,
+		grantRepo:   grantRepo,
+		userRepo:    userRepo,
+		fileService: fileService,
+		config:      config,
+	}
+}
+
+func (s *fileShareService) CreateShareLink(ctx context.Context, fileID, userID int, req dto.CreateShareLinkRequest) (*dto.ShareLinkResponse, error) {
+	logger.Info("Creating share link", zap.Int("file_id", fileID), zap.Int("user_id", userID))
+
+	if _, err := s.getOwnedFile(ctx, fileID, userID); err != nil {
+		return nil, err
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, ErrShareLinkExpiryInPast
+	}
+
+	var passwordHash string
+	if req.Password != "" {
+		// Same cost as account passwords
+		hashedBytes, err := bcrypt.GenerateFromPassword([]byte(req.Password), 12)
+		if err != nil {
+			logger.Error("Failed to hash share link password", zap.Error(err))
+			return nil, err
+		}
+		passwordHash = string(hashedBytes)
+	}
+
+	token, err := tokens.GenerateVerificationToken()
+	if err != nil {
+		logger.Error("Failed to generate share link token", zap.Error(err))
+		return nil, err
+	}
+
+	link, err := s.linkRepo.Create(ctx, fileID, userID, token, passwordHash, req.ExpiresAt, req.MaxDownloads)
+	if err != nil {
+		logger.Error("Failed to create share link", zap.Error(err), zap.Int("file_id", fileID))
+		return nil, err
+	}
+
+	logger.Info("Share link created successfully", zap.Int("file_id", fileID), zap.Int("link_id", link.ID))
+
+	return s.mapShareLinkToResponse(link), nil
+}
+
+func (s *fileShareService) GetShareLinks(ctx context.Context, fileID, userID int) ([]dto.ShareLinkResponse, error) {
+	logger.Debug("Getting share links", zap.Int("file_id", fileID))
+
+	if _, err := s.getOwnedFile(ctx, fileID, userID); err != nil {
+		return nil, err
+	}
+
+	links, err := s.linkRepo.GetByFileID(ctx, fileID)
+	if err != nil {
+		logger.Error("Failed to get share links", zap.Error(err), zap.Int("file_id", fileID))
+		return nil, err
+	}
+
+	linkResponses := make([]dto.ShareLinkResponse, len(links))
+	for i := range links {
+		linkResponses[i] = *s.mapShareLinkToResponse(&links[i])
+	}
+
+	return linkResponses, nil
+}
+
+func (s *fileShareService) RevokeShareLink(ctx context.Context, fileID, linkID, userID int) error {
+	logger.Info("Revoking share link", zap.Int("file_id", fileID), zap.Int("link_id", linkID))
+
+	if _, err := s.getOwnedFile(ctx, fileID, userID); err != nil {
+		return err
+	}
+
+	link, err := s.linkRepo.GetByID(ctx, linkID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrShareLinkNotFound
+		}
+		logger.Error("Failed to get share link", zap.Error(err), zap.Int("link_id", linkID))
+		return err
+	}
+	if link.FileID != fileID {
+		return ErrShareLinkNotFound
+	}
+
+	if err := s.linkRepo.Revoke(ctx, linkID); err != nil {
+		logger.Error("Failed to revoke share link", zap.Error(err), zap.Int("link_id", linkID))
+		return err
+	}
+
+	logger.Info("Share link revoked successfully", zap.Int("link_id", linkID))
+
+	return nil
+}
+
+func (s *fileShareService) OpenSharedFile(ctx context.Context, token, password string) (*entity.FileShareLink, *entity.File, io.ReadSeekCloser, error) {
+	if err := tokens.ValidateToken(token); err != nil {
+		return nil, nil, nil, ErrShareLinkNotFound
+	}
+
+	link, err := s.linkRepo.GetByToken(ctx, token)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Warn("Unknown share link token used")
+			return nil, nil, nil, ErrShareLinkNotFound
+		}
+		logger.Error("Failed to get share link by token", zap.Error(err))
+		return nil, nil, nil, err
+	}
+
+	if link.RevokedAt != nil {
+		logger.Warn("Revoked share link used", zap.Int("link_id", link.ID))
+		return nil, nil, nil, ErrShareLinkNotFound
+	}
+	if !shareLinkActive(link) {
+		logger.Warn("Expired share link used", zap.Int("link_id", link.ID))
+		return nil, nil, nil, ErrShareLinkExpired
+	}
+	if link.PasswordHash != "" {
+		if err := bcrypt.CompareHashAndPassword([]byte(link.PasswordHash), []byte(password)); err != nil {
+			logger.Warn("Invalid share link password", zap.Int("link_id", link.ID))
+			return nil, nil, nil, ErrShareLinkInvalidPassword
+		}
+	}
+
+	// Downloads refused by the malware scan do not use up the link, as they are counted afterwards
+	file, content, err := s.fileService.OpenFile(ctx, link.FileID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	logger.Info("Shared file opened", zap.Int("link_id", link.ID), zap.Int("file_id", file.ID))
+
+	return link, file, content, nil
+}
+
+func (s *fileShareService) CountSharedDownload(ctx context.Context, linkID int) error {
+	// Counted atomically, so concurrent downloads cannot exceed the limit
+	if _, err := s.linkRepo.Use(ctx, linkID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Warn("Share link used up", zap.Int("link_id", linkID))
+			return ErrShareLinkExpired
+		}
+		logger.Error("Failed to count share link download", zap.Error(err), zap.Int("link_id", linkID))
+		return err
+	}
+	return nil
+}
+
+func (s *fileShareService) GetFileGrants(ctx context.Context, fileID, userID int) ([]dto.FileGrantResponse, error) {
+	logger.Debug("Getting file grants", zap.Int("file_id", fileID))
+
+	if _, err := s.getOwnedFile(ctx, fileID, userID); err != nil {
+		return nil, err
+	}
+
+	grants, err := s.grantRepo.GetByFileID(ctx, fileID)
+	if err != nil {
+		logger.Error("Failed to get file grants", zap.Error(err), zap.Int("file_id", fileID))
+		return nil, err
+	}
+
+	grantResponses := make([]dto.FileGrantResponse, len(grants))
+	for i := range grants {
+		grantResponses[i] = *s.mapGrantToResponse(&grants[i])
+	}
+
+	return grantResponses, nil
+}
+
+func (s *fileShareService) GrantFileAccess(ctx context.Context, fileID, userID, granteeID int, req dto.GrantFileAccessRequest) (*dto.FileGrantResponse, error) {
+	logger.Info("Granting file access",
+		zap.Int("file_id", fileID),
+		zap.Int("grantee_id", granteeID),
+		zap.String("permission", req.Permission))
+
+	file, err := s.getOwnedFile(ctx, fileID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if granteeID == file.UploadedBy {
+		return nil, ErrFileGrantToOwner
+	}
+	if _, err := s.userRepo.GetByID(ctx, granteeID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Warn("User not found for file grant", zap.Int("grantee_id", granteeID))
+			return nil, ErrUserNotFound
+		}
+		logger.Error("Failed to get user for file grant", zap.Error(err))
+		return nil, err
+	}
+
+	grant, err := s.grantRepo.Upsert(ctx, fileID, granteeID, req.Permission, userID)
+	if err != nil {
+		logger.Error("Failed to grant file access", zap.Error(err), zap.Int("file_id", fileID))
+		return nil, err
+	}
+
+	logger.Info("File access granted successfully", zap.Int("file_id", fileID), zap.Int("grantee_id", granteeID))
+
+	return s.mapGrantToResponse(grant), nil
+}
+
+func (s *fileShareService) RevokeFileAccess(ctx context.Context, fileID, userID, granteeID int) error {
+	logger.Info("Revoking file access", zap.Int("file_id", fileID), zap.Int("grantee_id", granteeID))
+
+	if _, err := s.getOwnedFile(ctx, fileID, userID); err != nil {
+		return err
+	}
+
+	if _, err := s.grantRepo.Get(ctx, fileID, granteeID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrFileGrantNotFound
+		}
+		logger.Error("Failed to get file grant", zap.Error(err), zap.Int("file_id", fileID))
+		return err
+	}
+
+	if err := s.grantRepo.Delete(ctx, fileID, granteeID); err != nil {
+		logger.Error("Failed to revoke file access", zap.Error(err), zap.Int("file_id", fileID))
+		return err
+	}
+
+	logger.Info("File access revoked successfully", zap.Int("file_id", fileID), zap.Int("grantee_id", granteeID))
+
+	return nil
+}
+
+// getOwnedFile returns a file uploaded by the user; sharing is managed by the owner only
+func (s *fileShareService) getOwnedFile(ctx context.Context, fileID, userID int) (*entity.File, error) {
+	file, err := s.fileRepo.GetByID(ctx, fileID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Warn("File not found", zap.Int("file_id", fileID))
+			return nil, ErrFileNotFound
+		}
+		logger.Error("Failed to get file", zap.Error(err), zap.Int("file_id", fileID))
+		return nil, err
+	}
+	if file.UploadedBy != userID {
+		logger.Warn("File belongs to another user", zap.Int("file_id", fileID), zap.Int("user_id", userID))
+		return nil, ErrFileNotOwned
+	}
+	return file, nil
+}
+
+// shareLinkActive reports whether a link can still be used, ignoring its password
+func shareLinkActive(link *entity.FileShareLink) bool {
+	if link.RevokedAt != nil {
+		return false
+	}
+	if link.ExpiresAt != nil && !link.ExpiresAt.After(time.Now()) {
+		return false
+	}
+	return link.MaxDownloads == nil || link.DownloadCount < *link.MaxDownloads
+}
+
+func (s *fileShareService) mapShareLinkToResponse(link *entity.FileShareLink) *dto.ShareLinkResponse {
+	return &dto.ShareLinkResponse{
+		ID:                link.ID,
+		FileID:            link.FileID,
+		URL:               strings.TrimSuffix(s.config.Upload.BaseURL, "/") + "/share/" + link.Token,
+		PasswordProtected: link.PasswordHash != "",
+		ExpiresAt:         link.ExpiresAt,
+		MaxDownloads:      link.MaxDownloads,
+		DownloadCount:     link.DownloadCount,
+		Active:            shareLinkActive(link),
+		RevokedAt:         link.RevokedAt,
+		CreatedAt:         link.CreatedAt,
+	}
+}
+
+func (s *fileShareService) mapGrantToResponse(grant *entity.FileGrant) *dto.FileGrantResponse {
+	return &dto.FileGrantResponse{
+		FileID:     grant.FileID,
+		UserID:     grant.UserID,
+		Permission: grant.Permission,
+		GrantedBy:  grant.GrantedBy,
+		CreatedAt:  grant.CreatedAt,
+		UpdatedAt:  grant.UpdatedAt,
+	}
+}
@@ -0,0 +1,293 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"go-template/internal/config"
+	"go-template/internal/dto"
+	"go-template/internal/entity"
+	"go-template/internal/repository"
+)
+
+// fakeFileShareLinkRepository keeps links in memory and applies the same conditions as the queries
+type fakeFileShareLinkRepository struct {
+	repository.FileShareLinkRepository
+	links map[int]*entity.FileShareLink
+}
+
+func (r *fakeFileShareLinkRepository) Create(ctx context.Context, fileID, createdBy int, token, passwordHash string, expiresAt *time.Time, maxDownloads *int) (*entity.FileShareLink, error) {
+	link := &entity.FileShareLink{ID: len(r.links) + 1, FileID: fileID, CreatedBy: createdBy, Token: token, PasswordHash: passwordHash, ExpiresAt: expiresAt, MaxDownloads: maxDownloads}
+	r.links[link.ID] = link
+	created := *link
+	return &created, nil
+}
+
+func (r *fakeFileShareLinkRepository) GetByID(ctx context.Context, id int) (*entity.FileShareLink, error) {
+	link, ok := r.links[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	found := *link
+	return &found, nil
+}
+
+func (r *fakeFileShareLinkRepository) GetByToken(ctx context.Context, token string) (*entity.FileShareLink, error) {
+	for _, link := range r.links {
+		if link.Token == token {
+			found := *link
+			return &found, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *fakeFileShareLinkRepository) GetByFileID(ctx context.Context, fileID int) ([]entity.FileShareLink, error) {
+	var links []entity.FileShareLink
+	for _, link := range r.links {
+		if link.FileID == fileID {
+			links = append(links, *link)
+		}
+	}
+	return links, nil
+}
+
+func (r *fakeFileShareLinkRepository) Use(ctx context.Context, id int) (*entity.FileShareLink, error) {
+	link, ok := r.links[id]
+	if !ok || !shareLinkActive(link) {
+		return nil, sql.ErrNoRows
+	}
+	link.DownloadCount++
+	used := *link
+	return &used, nil
+}
+
+func (r *fakeFileShareLinkRepository) Revoke(ctx context.Context, id int) error {
+	now := time.Now()
+	r.links[id].RevokedAt = &now
+	return nil
+}
+
+// fakeShareFileRepository looks files up by ID; other methods are not implemented
+type fakeShareFileRepository struct {
+	repository.FileRepository
+	files map[int]*entity.File
+}
+
+func (r *fakeShareFileRepository) GetByID(ctx context.Context, id int) (*entity.File, error) {
+	file, ok := r.files[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return file, nil
+}
+
+// fakeShareFileService opens the files of a fakeShareFileRepository with empty content
+type fakeShareFileService struct {
+	FileService
+	files *fakeShareFileRepository
+}
+
+type nopReadSeekCloser struct {
+	io.ReadSeeker
+}
+
+func (nopReadSeekCloser) Close() error {
+	return nil
+}
+
+func (s *fakeShareFileService) OpenFile(ctx context.Context, id int) (*entity.File, io.ReadSeekCloser, error) {
+	file, err := s.files.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, ErrFileNotFound
+	}
+	return file, nopReadSeekCloser{bytes.NewReader(nil)}, nil
+}
+
+// newTestFileShareService shares file 10 of user 1 and file 20 of user 2
+func newTestFileShareService() (FileShareService, *fakeFileShareLinkRepository) {
+	files := &fakeShareFileRepository{files: map[int]*entity.File{
+		10: {ID: 10, UploadedBy: 1, OriginalName: "report.pdf"},
+		20: {ID: 20, UploadedBy: 2, OriginalName: "notes.txt"},
+	}}
+	links := &fakeFileShareLinkRepository{links: map[int]*entity.FileShareLink{}}
+	cfg := &config.Config{Upload: config.UploadConfig{BaseURL: "http://localhost:8080"}}
+	return NewFileShareService(files, links, nil, nil, &fakeShareFileService{files: files}, cfg), links
+}
+
+// shareToken returns the token of a created link, which responses only carry inside the URL
+func shareToken(links *fakeFileShareLinkRepository, linkID int) string {
+	return links.links[linkID].Token
+}
+
+func TestSharedFilePassword(t *testing.T) {
+	s, links := newTestFileShareService()
+	ctx := context.Background()
+
+	link, err := s.CreateShareLink(ctx, 10, 1, dto.CreateShareLinkRequest{Password: "correct horse"})
+	if err != nil {
+		t.Fatalf("CreateShareLink: %v", err)
+	}
+	if !link.PasswordProtected {
+		t.Error("link with a password is not reported as protected")
+	}
+	if links.links[link.ID].PasswordHash == "correct horse" {
+		t.Error("share link password is stored in plain text")
+	}
+	token := shareToken(links, link.ID)
+
+	for _, password := range []string{"", "wrong", "correct horse "} {
+		if _, _, _, err := s.OpenSharedFile(ctx, token, password); !errors.Is(err, ErrShareLinkInvalidPassword) {
+			t.Errorf("OpenSharedFile with password %q: error %v, want %v", password, err, ErrShareLinkInvalidPassword)
+		}
+	}
+
+	_, file, content, err := s.OpenSharedFile(ctx, token, "correct horse")
+	if err != nil {
+		t.Fatalf("OpenSharedFile with the right password: %v", err)
+	}
+	content.Close()
+	if file.ID != 10 {
+		t.Errorf("OpenSharedFile opened file %d, want 10", file.ID)
+	}
+}
+
+func TestSharedFileTokens(t *testing.T) {
+	s, links := newTestFileShareService()
+	ctx := context.Background()
+
+	link, err := s.CreateShareLink(ctx, 10, 1, dto.CreateShareLinkRequest{})
+	if err != nil {
+		t.Fatalf("CreateShareLink: %v", err)
+	}
+	token := shareToken(links, link.ID)
+	if link.URL != "http://localhost:8080/share/"+token {
+		t.Errorf("link URL = %q, want the share route with the token", link.URL)
+	}
+
+	unknown := []byte(token)
+	unknown[0] ^= 1
+	for _, tok := range []string{"", "not-a-token", string(unknown)} {
+		if _, _, _, err := s.OpenSharedFile(ctx, tok, ""); !errors.Is(err, ErrShareLinkNotFound) {
+			t.Errorf("OpenSharedFile(%q): error %v, want %v", tok, err, ErrShareLinkNotFound)
+		}
+	}
+}
+
+func TestSharedFileExpiry(t *testing.T) {
+	s, links := newTestFileShareService()
+	ctx := context.Background()
+
+	past := time.Now().Add(-time.Minute)
+	if _, err := s.CreateShareLink(ctx, 10, 1, dto.CreateShareLinkRequest{ExpiresAt: &past}); !errors.Is(err, ErrShareLinkExpiryInPast) {
+		t.Errorf("CreateShareLink with a past expiry: error %v, want %v", err, ErrShareLinkExpiryInPast)
+	}
+
+	future := time.Now().Add(time.Hour)
+	link, err := s.CreateShareLink(ctx, 10, 1, dto.CreateShareLinkRequest{ExpiresAt: &future})
+	if err != nil {
+		t.Fatalf("CreateShareLink: %v", err)
+	}
+	token := shareToken(links, link.ID)
+	if _, _, _, err := s.OpenSharedFile(ctx, token, ""); err != nil {
+		t.Fatalf("OpenSharedFile before the expiry: %v", err)
+	}
+
+	// The link expires while it is in use
+	links.links[link.ID].ExpiresAt = &past
+	if _, _, _, err := s.OpenSharedFile(ctx, token, ""); !errors.Is(err, ErrShareLinkExpired) {
+		t.Errorf("OpenSharedFile after the expiry: error %v, want %v", err, ErrShareLinkExpired)
+	}
+	if err := s.CountSharedDownload(ctx, link.ID); !errors.Is(err, ErrShareLinkExpired) {
+		t.Errorf("CountSharedDownload after the expiry: error %v, want %v", err, ErrShareLinkExpired)
+	}
+}
+
+func TestSharedFileMaxDownloads(t *testing.T) {
+	s, links := newTestFileShareService()
+	ctx := context.Background()
+
+	maxDownloads := 2
+	link, err := s.CreateShareLink(ctx, 10, 1, dto.CreateShareLinkRequest{MaxDownloads: &maxDownloads})
+	if err != nil {
+		t.Fatalf("CreateShareLink: %v", err)
+	}
+	token := shareToken(links, link.ID)
+
+	for i := 1; i <= maxDownloads; i++ {
+		if _, _, _, err := s.OpenSharedFile(ctx, token, ""); err != nil {
+			t.Fatalf("OpenSharedFile %d: %v", i, err)
+		}
+		if err := s.CountSharedDownload(ctx, link.ID); err != nil {
+			t.Fatalf("CountSharedDownload %d: %v", i, err)
+		}
+	}
+
+	if _, _, _, err := s.OpenSharedFile(ctx, token, ""); !errors.Is(err, ErrShareLinkExpired) {
+		t.Errorf("OpenSharedFile of a used up link: error %v, want %v", err, ErrShareLinkExpired)
+	}
+	// A download opened before the last one was counted is refused too
+	if err := s.CountSharedDownload(ctx, link.ID); !errors.Is(err, ErrShareLinkExpired) {
+		t.Errorf("CountSharedDownload of a used up link: error %v, want %v", err, ErrShareLinkExpired)
+	}
+	if count := links.links[link.ID].DownloadCount; count != maxDownloads {
+		t.Errorf("download count = %d, want %d", count, maxDownloads)
+	}
+
+	listed, err := s.GetShareLinks(ctx, 10, 1)
+	if err != nil {
+		t.Fatalf("GetShareLinks: %v", err)
+	}
+	if len(listed) != 1 || listed[0].Active || listed[0].DownloadCount != maxDownloads {
+		t.Errorf("GetShareLinks = %+v, want one inactive link with %d downloads", listed, maxDownloads)
+	}
+}
+
+func TestRevokeShareLink(t *testing.T) {
+	s, links := newTestFileShareService()
+	ctx := context.Background()
+
+	link, err := s.CreateShareLink(ctx, 10, 1, dto.CreateShareLinkRequest{})
+	if err != nil {
+		t.Fatalf("CreateShareLink: %v", err)
+	}
+	token := shareToken(links, link.ID)
+
+	if err := s.RevokeShareLink(ctx, 10, link.ID, 2); !errors.Is(err, ErrFileNotOwned) {
+		t.Errorf("RevokeShareLink by another user: error %v, want %v", err, ErrFileNotOwned)
+	}
+	// The link does not belong to the owner's other file
+	if err := s.RevokeShareLink(ctx, 20, link.ID, 2); !errors.Is(err, ErrShareLinkNotFound) {
+		t.Errorf("RevokeShareLink through another file: error %v, want %v", err, ErrShareLinkNotFound)
+	}
+	if _, _, _, err := s.OpenSharedFile(ctx, token, ""); err != nil {
+		t.Fatalf("OpenSharedFile after refused revocations: %v", err)
+	}
+
+	if err := s.RevokeShareLink(ctx, 10, link.ID, 1); err != nil {
+		t.Fatalf("RevokeShareLink: %v", err)
+	}
+	if _, _, _, err := s.OpenSharedFile(ctx, token, ""); !errors.Is(err, ErrShareLinkNotFound) {
+		t.Errorf("OpenSharedFile of a revoked link: error %v, want %v", err, ErrShareLinkNotFound)
+	}
+	if err := s.CountSharedDownload(ctx, link.ID); !errors.Is(err, ErrShareLinkExpired) {
+		t.Errorf("CountSharedDownload of a revoked link: error %v, want %v", err, ErrShareLinkExpired)
+	}
+}
+
+func TestCreateShareLinkRequiresOwner(t *testing.T) {
+	s, _ := newTestFileShareService()
+	ctx := context.Background()
+
+	if _, err := s.CreateShareLink(ctx, 10, 2, dto.CreateShareLinkRequest{}); !errors.Is(err, ErrFileNotOwned) {
+		t.Errorf("CreateShareLink by another user: error %v, want %v", err, ErrFileNotOwned)
+	}
+	if _, err := s.CreateShareLink(ctx, 99, 1, dto.CreateShareLinkRequest{}); !errors.Is(err, ErrFileNotFound) {
+		t.Errorf("CreateShareLink of a missing file: error %v, want %v", err, ErrFileNotFound)
+	}
+}
@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"mime/multipart"
+
+	"go-template/internal/dto"
+	"go-template/internal/entity"
+	"go-template/internal/logger"
+	"go-template/internal/repository"
+	"go-template/pkg/apperror"
+
+	"go.uber.org/zap"
+)
+
+var (
+	ErrFileVersionNotFound = apperror.New(apperror.KindNotFound, apperror.CodeFileVersionNotFound, "file version not found")
+	ErrFileVersionConflict = apperror.New(apperror.KindConflict, apperror.CodeFileVersionConflict, "file was changed by another request")
+)
+
+func (s *fileService) UploadFileVersion(ctx context.Context, id int, file *multipart.FileHeader, userID int) (*dto.FileResponse, error) {
+	logger.Info("Uploading file version", zap.Int("file_id", id), zap.String("original_name", file.Filename), zap.Int("user_id", userID))
+
+	current, err := s.getFile(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	// The previous content is kept as a version, so the new content adds to the usage of the owner
+	quota, err := s.quotaService.CheckQuota(ctx, current.UploadedBy, file.Size, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := s.storeUploadedFile(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+	content.Version = current.Version + 1
+	content.ScanStatus = s.scanService.InitialStatus()
+
+	return s.replaceContent(ctx, current, content, quota)
+}
+
+func (s *fileService) GetFileVersions(ctx context.Context, id int) ([]dto.FileVersionResponse, error) {
+	file, err := s.getFile(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := s.versionRepo.GetByFileID(ctx, id)
+	if err != nil {
+		logger.Error("Failed to get file versions", zap.Error(err), zap.Int("file_id", id))
+		return nil, err
+	}
+
+	responses := make([]dto.FileVersionResponse, 0, len(versions)+1)
+	responses = append(responses, *mapFileVersionToResponse(currentVersion(file), true))
+	for _, version := range versions {
+		responses = append(responses, *mapFileVersionToResponse(&version, false))
+	}
+
+	return responses, nil
+}
+
+// OpenFileVersion streams the content of a version of a file, the current one included
+func (s *fileService) OpenFileVersion(ctx context.Context, id, version int) (*entity.FileVersion, io.ReadSeekCloser, error) {
+	fileVersion, err := s.getFileVersion(ctx, id, version)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkScanStatus(fileVersion.ScanStatus); err != nil {
+		return nil, nil, err
+	}
+
+	content, _, err := s.openContent(ctx, fileVersion.Checksum, fileVersion.FilePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return fileVersion, content, nil
+}
+
+// RestoreFileVersion makes the content of an earlier version current again. It is recorded as a new
+// version, so the content it replaces stays in the history; restoring the current version changes
+// nothing.
+func (s *fileService) RestoreFileVersion(ctx context.Context, id, version int) (*dto.FileResponse, error) {
+	logger.Info("Restoring file version", zap.Int("file_id", id), zap.Int("version", version))
+
+	current, err := s.getFile(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if version == current.Version {
+		return s.mapFileToResponse(ctx, current), nil
+	}
+
+	previous, err := s.getFileVersion(ctx, id, version)
+	if err != nil {
+		return nil, err
+	}
+	if previous.ScanStatus == entity.FileScanStatusInfected {
+		logger.Warn("Refused to restore infected file version", zap.Int("file_id", id), zap.Int("version", version))
+		return nil, ErrFileInfected
+	}
+	// Damaged content would be written back over itself and taken for intact
+	if err := s.blobs.checkIntact(ctx, previous.Checksum); err != nil {
+		return nil, err
+	}
+	quota, err := s.quotaService.CheckQuota(ctx, current.UploadedBy, previous.FileSize, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// The restored version takes its own reference on the content. Content uploaded before checksums
+	// belongs to the old version alone, so it is stored again as shared content.
+	checksum := previous.Checksum
+	if checksum == "" {
+		if checksum, _, err = s.blobs.hashObject(ctx, previous.FilePath); err != nil {
+			logger.Error("Failed to read file version", zap.Error(err), zap.Int("file_id", id), zap.Int("version", version))
+			return nil, ErrFileStorage.Wrap(err)
+		}
+	}
+	stored, _, err := s.fileStorage.Open(ctx, previous.FilePath)
+	if err != nil {
+		logger.Error("Failed to open file version", zap.Error(err), zap.Int("file_id", id), zap.Int("version", version))
+		return nil, ErrFileStorage.Wrap(err)
+	}
+	defer stored.Close()
+
+	key, err := s.blobs.put(ctx, checksum, stored, previous.FileSize, previous.MimeType)
+	if err != nil {
+		logger.Error("Failed to store restored file version", zap.Error(err), zap.Int("file_id", id))
+		return nil, ErrFileStorage.Wrap(err)
+	}
+
+	// The verdict of the scan holds for the same content
+	content := *previous
+	content.FilePath = key
+	content.Checksum = checksum
+	content.Version = current.Version + 1
+
+	return s.replaceContent(ctx, current, &content, quota)
+}
+
+// replaceContent makes stored content the new version of a file and prunes the versions beyond the
+// retention limit, within the storage quota of the owner. The reference on the content is released
+// when it does not become current.
+func (s *fileService) replaceContent(ctx context.Context, file *entity.File, content *entity.FileVersion, quota entity.StorageQuota) (*dto.FileResponse, error) {
+	updated, pruned, err := s.fileRepo.ReplaceContent(ctx, file.ID, content, max(s.config.Upload.MaxVersions, 0), quota)
+	if err != nil {
+		s.blobs.release(ctx, content.Checksum)
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Warn("File changed while uploading a new version", zap.Int("file_id", file.ID))
+			return nil, ErrFileVersionConflict
+		}
+		if errors.Is(err, repository.ErrQuotaExceeded) {
+			logger.Warn("Storage quota exceeded while saving file version", zap.Int("file_id", file.ID), zap.Int("user_id", file.UploadedBy))
+			return nil, quotaExceededError(ctx, s.quotaService, file.UploadedBy)
+		}
+		logger.Error("Failed to save file version", zap.Error(err), zap.Int("file_id", file.ID))
+		return nil, err
+	}
+
+	for _, version := range pruned {
+		if err := s.blobs.deleteContent(ctx, version.Checksum, version.FilePath); err != nil {
+			logger.Warn("Failed to delete pruned file version", zap.Error(err), zap.Int("file_id", file.ID), zap.Int("version", version.Version))
+		}
+	}
+
+	logger.Info("File version saved", zap.Int("file_id", file.ID), zap.Int("version", updated.Version), zap.Int("pruned", len(pruned)))
+
+	// Variants of the previous content are rendered again once the new content is scanned
+	s.variantService.DeleteVariants(ctx, file.ID)
+	s.scanService.Enqueue(updated)
+
+	return s.mapFileToResponse(ctx, updated), nil
+}
+
+func (s *fileService) getFile(ctx context.Context, id int) (*entity.File, error) {
+	file, err := s.fileRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Warn("File not found", zap.Int("file_id", id))
+			return nil, ErrFileNotFound
+		}
+		logger.Error("Failed to get file", zap.Error(err), zap.Int("file_id", id))
+		return nil, err
+	}
+	return file, nil
+}
+
+// getFileVersion returns a version of a file, the current one included
+func (s *fileService) getFileVersion(ctx context.Context, id, version int) (*entity.FileVersion, error) {
+	file, err := s.getFile(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if version == file.Version {
+		return currentVersion(file), nil
+	}
+
+	fileVersion, err := s.versionRepo.GetByVersion(ctx, id, version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Warn("File version not found", zap.Int("file_id", id), zap.Int("version", version))
+			return nil, ErrFileVersionNotFound
+		}
+		logger.Error("Failed to get file version", zap.Error(err), zap.Int("file_id", id))
+		return nil, err
+	}
+	return fileVersion, nil
+}
+
+// currentVersion describes the current content of a file as a version
+func currentVersion(file *entity.File) *entity.FileVersion {
+	return &entity.FileVersion{
+		FileID:       file.ID,
+		Version:      file.Version,
+		OriginalName: file.OriginalName,
+		FilePath:     file.FilePath,
+		FileSize:     file.FileSize,
+		MimeType:     file.MimeType,
+		Checksum:     file.Checksum,
+		ScanStatus:   file.ScanStatus,
+		Metadata:     file.Metadata,
+		CreatedAt:    file.ContentUpdatedAt,
+	}
+}
+
+func mapFileVersionToResponse(version *entity.FileVersion, current bool) *dto.FileVersionResponse {
+	return &dto.FileVersionResponse{
+		Version:      version.Version,
+		OriginalName: version.OriginalName,
+		FileSize:     version.FileSize,
+		MimeType:     version.MimeType,
+		Checksum:     version.Checksum,
+		ScanStatus:   version.ScanStatus,
+		Metadata:     mapFileMetadataToResponse(version.Metadata),
+		Current:      current,
+		CreatedAt:    version.CreatedAt,
+	}
+}
@@ -0,0 +1,275 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+
+	"go-template/internal/dto"
+	"go-template/internal/entity"
+	"go-template/internal/logger"
+	"go-template/internal/repository"
+	"go-template/pkg/apperror"
+
+	"go.uber.org/zap"
+)
+
+var (
+	ErrFolderNotFound    = apperror.New(apperror.KindNotFound, apperror.CodeFolderNotFound, "folder not found")
+	ErrFolderExists      = apperror.New(apperror.KindConflict, apperror.CodeFolderAlreadyExists, "a folder with this name already exists")
+	ErrFolderNotEmpty    = apperror.New(apperror.KindConflict, apperror.CodeFolderNotEmpty, "folder is not empty")
+	ErrFolderInvalidMove = apperror.New(apperror.KindUnprocessable, apperror.CodeFolderInvalidMove, "a folder cannot be moved into itself or its subfolders")
+)
+
+// FolderService manages the folder trees of users. Users only see their own folders; folders of other
+// users are reported as not found.
+type FolderService interface {
+	CreateFolder(ctx context.Context, userID int, req dto.CreateFolderRequest) (*dto.FolderResponse, error)
+	GetFolder(ctx context.Context, id, userID int) (*dto.FolderResponse, error)
+	// GetFolders lists the subfolders of parentID, or the top level folders when parentID is nil
+	GetFolders(ctx context.Context, userID int, parentID *int) ([]dto.FolderResponse, error)
+	RenameFolder(ctx context.Context, id, userID int, req dto.RenameFolderRequest) (*dto.FolderResponse, error)
+	MoveFolder(ctx context.Context, id, userID int, req dto.MoveFolderRequest) (*dto.FolderResponse, error)
+	// DeleteFolder removes an empty folder; with recursive the files in it and its subfolders are
+	// deleted as well
+	DeleteFolder(ctx context.Context, id, userID int, recursive bool) error
+}
+
+type folderService struct {
+	folderRepo  repository.FolderRepository
+	fileRepo    repository.FileRepository
+	fileService FileService
+}
+
+func NewFolderService(folderRepo repository.FolderRepository, fileRepo repository.FileRepository, fileService FileService) FolderService {
+	return &folderService{
+		folderRepo:  folderRepo,
+		fileRepo:    fileRepo,
+		fileService: fileService,
+	}
+}
+
+func (s *folderService) CreateFolder(ctx context.Context, userID int, req dto.CreateFolderRequest) (*dto.FolderResponse, error) {
+	logger.Info("Creating folder", zap.Int("user_id", userID))
+
+	name := strings.TrimSpace(req.Name)
+	if req.ParentID != nil {
+		if _, err := getOwnedFolder(ctx, s.folderRepo, *req.ParentID, userID); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.checkNameAvailable(ctx, userID, req.ParentID, name, 0); err != nil {
+		return nil, err
+	}
+
+	folder, err := s.folderRepo.Create(ctx, userID, req.ParentID, name)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// The parent was deleted in the meantime
+			return nil, ErrFolderNotFound
+		}
+		logger.Error("Failed to create folder", zap.Error(err), zap.Int("user_id", userID))
+		return nil, err
+	}
+
+	logger.Info("Folder created successfully", zap.Int("folder_id", folder.ID))
+
+	return s.mapFolderToResponse(folder), nil
+}
+
+func (s *folderService) GetFolder(ctx context.Context, id, userID int) (*dto.FolderResponse, error) {
+	logger.Debug("Getting folder", zap.Int("folder_id", id))
+
+	folder, err := getOwnedFolder(ctx, s.folderRepo, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.mapFolderToResponse(folder), nil
+}
+
+func (s *folderService) GetFolders(ctx context.Context, userID int, parentID *int) ([]dto.FolderResponse, error) {
+	logger.Debug("Getting folders", zap.Int("user_id", userID))
+
+	if parentID != nil {
+		if _, err := getOwnedFolder(ctx, s.folderRepo, *parentID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	folders, err := s.folderRepo.GetByParent(ctx, userID, parentID)
+	if err != nil {
+		logger.Error("Failed to get folders", zap.Error(err), zap.Int("user_id", userID))
+		return nil, err
+	}
+
+	folderResponses := make([]dto.FolderResponse, len(folders))
+	for i := range folders {
+		folderResponses[i] = *s.mapFolderToResponse(&folders[i])
+	}
+
+	return folderResponses, nil
+}
+
+func (s *folderService) RenameFolder(ctx context.Context, id, userID int, req dto.RenameFolderRequest) (*dto.FolderResponse, error) {
+	logger.Info("Renaming folder", zap.Int("folder_id", id))
+
+	folder, err := getOwnedFolder(ctx, s.folderRepo, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if err := s.checkNameAvailable(ctx, userID, folder.ParentID, name, id); err != nil {
+		return nil, err
+	}
+
+	renamed, err := s.folderRepo.Rename(ctx, id, name)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrFolderNotFound
+		}
+		logger.Error("Failed to rename folder", zap.Error(err), zap.Int("folder_id", id))
+		return nil, err
+	}
+
+	logger.Info("Folder renamed successfully", zap.Int("folder_id", id))
+
+	return s.withStats(folder, renamed), nil
+}
+
+func (s *folderService) MoveFolder(ctx context.Context, id, userID int, req dto.MoveFolderRequest) (*dto.FolderResponse, error) {
+	logger.Info("Moving folder", zap.Int("folder_id", id))
+
+	folder, err := getOwnedFolder(ctx, s.folderRepo, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.ParentID != nil {
+		parent, err := getOwnedFolder(ctx, s.folderRepo, *req.ParentID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if parent.ID == id || strings.HasPrefix(parent.Path, folder.Path+strconv.Itoa(id)+"/") {
+			logger.Warn("Folder cannot be moved into its own subtree", zap.Int("folder_id", id), zap.Int("parent_id", parent.ID))
+			return nil, ErrFolderInvalidMove
+		}
+	}
+	if err := s.checkNameAvailable(ctx, userID, req.ParentID, folder.Name, id); err != nil {
+		return nil, err
+	}
+
+	moved, err := s.folderRepo.Move(ctx, id, req.ParentID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// Either the folder was deleted, or the parent was moved into the folder in the meantime
+			logger.Warn("Folder could not be moved", zap.Int("folder_id", id))
+			return nil, ErrFolderInvalidMove
+		}
+		logger.Error("Failed to move folder", zap.Error(err), zap.Int("folder_id", id))
+		return nil, err
+	}
+
+	logger.Info("Folder moved successfully", zap.Int("folder_id", id))
+
+	return s.withStats(folder, moved), nil
+}
+
+func (s *folderService) DeleteFolder(ctx context.Context, id, userID int, recursive bool) error {
+	logger.Info("Deleting folder", zap.Int("folder_id", id), zap.Bool("recursive", recursive))
+
+	folder, err := getOwnedFolder(ctx, s.folderRepo, id, userID)
+	if err != nil {
+		return err
+	}
+
+	if !recursive && (folder.FolderCount > 0 || folder.FileCount > 0) {
+		logger.Warn("Folder is not empty", zap.Int("folder_id", id))
+		return ErrFolderNotEmpty.WithDetails(s.mapFolderToResponse(folder))
+	}
+
+	if recursive {
+		files, err := s.fileRepo.GetByFolderTree(ctx, id)
+		if err != nil {
+			logger.Error("Failed to get files of folder", zap.Error(err), zap.Int("folder_id", id))
+			return err
+		}
+		// Files are deleted one by one so their content, versions and storage usage are released
+		for _, file := range files {
+			if err := s.fileService.DeleteFile(ctx, file.ID); err != nil && !errors.Is(err, ErrFileNotFound) {
+				return err
+			}
+		}
+	}
+
+	// Subfolders are deleted along with the folder; files added in the meantime end up at the top level
+	if err := s.folderRepo.Delete(ctx, id); err != nil {
+		logger.Error("Failed to delete folder", zap.Error(err), zap.Int("folder_id", id))
+		return err
+	}
+
+	logger.Info("Folder deleted successfully", zap.Int("folder_id", id))
+
+	return nil
+}
+
+// checkNameAvailable returns ErrFolderExists when another folder than exceptID below parentID has the name
+func (s *folderService) checkNameAvailable(ctx context.Context, userID int, parentID *int, name string, exceptID int) error {
+	existing, err := s.folderRepo.GetByName(ctx, userID, parentID, name)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		logger.Error("Failed to check folder name", zap.Error(err), zap.Int("user_id", userID))
+		return err
+	}
+	if existing.ID == exceptID {
+		return nil
+	}
+
+	logger.Warn("Folder already exists", zap.Int("user_id", userID), zap.Int("folder_id", existing.ID))
+	return ErrFolderExists
+}
+
+// withStats copies the subtree statistics of a folder to its updated row, which has none
+func (s *folderService) withStats(folder, updated *entity.Folder) *dto.FolderResponse {
+	updated.FolderCount = folder.FolderCount
+	updated.FileCount = folder.FileCount
+	updated.TotalSize = folder.TotalSize
+	return s.mapFolderToResponse(updated)
+}
+
+func (s *folderService) mapFolderToResponse(folder *entity.Folder) *dto.FolderResponse {
+	return &dto.FolderResponse{
+		ID:          folder.ID,
+		ParentID:    folder.ParentID,
+		Name:        folder.Name,
+		FolderCount: folder.FolderCount,
+		FileCount:   folder.FileCount,
+		TotalSize:   folder.TotalSize,
+		CreatedAt:   folder.CreatedAt,
+		UpdatedAt:   folder.UpdatedAt,
+	}
+}
+
+// getOwnedFolder returns a folder of the user; folders of other users are reported as not found so
+// their existence is not revealed
+func getOwnedFolder(ctx context.Context, folderRepo repository.FolderRepository, id, userID int) (*entity.Folder, error) {
+	folder, err := folderRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Warn("Folder not found", zap.Int("folder_id", id))
+			return nil, ErrFolderNotFound
+		}
+		logger.Error("Failed to get folder", zap.Error(err), zap.Int("folder_id", id))
+		return nil, err
+	}
+	if folder.UserID != userID {
+		logger.Warn("Folder belongs to another user", zap.Int("folder_id", id), zap.Int("user_id", userID))
+		return nil, ErrFolderNotFound
+	}
+	return folder, nil
+}
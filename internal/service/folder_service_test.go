@@ -0,0 +1,288 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"go-template/internal/dto"
+	"go-template/internal/entity"
+	"go-template/internal/repository"
+	"go-template/pkg/apperror"
+)
+
+// fakeFolderRepository keeps folders with materialized paths in memory, like the folders table, and
+// derives their statistics from the files of files
+type fakeFolderRepository struct {
+	repository.FolderRepository
+	folders map[int]*entity.Folder
+	files   *fakeFileRepository
+}
+
+// inSubtree reports whether folder is below the folder with the id and path
+func inSubtree(folder *entity.Folder, id int, path string) bool {
+	return strings.HasPrefix(folder.Path, path+strconv.Itoa(id)+"/")
+}
+
+func (r *fakeFolderRepository) GetByID(ctx context.Context, id int) (*entity.Folder, error) {
+	folder, ok := r.folders[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	found := *folder
+	for _, other := range r.folders {
+		if inSubtree(other, id, folder.Path) {
+			found.FolderCount++
+		}
+	}
+	for _, file := range r.files.files {
+		if file.FolderID == nil {
+			continue
+		}
+		if in := r.folders[*file.FolderID]; in.ID == id || inSubtree(in, id, folder.Path) {
+			found.FileCount++
+			found.TotalSize += file.FileSize
+		}
+	}
+	return &found, nil
+}
+
+func (r *fakeFolderRepository) GetByName(ctx context.Context, userID int, parentID *int, name string) (*entity.Folder, error) {
+	for _, folder := range r.folders {
+		sameParent := (folder.ParentID == nil && parentID == nil) || (folder.ParentID != nil && parentID != nil && *folder.ParentID == *parentID)
+		if folder.UserID == userID && sameParent && strings.EqualFold(folder.Name, name) {
+			found := *folder
+			return &found, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *fakeFolderRepository) Move(ctx context.Context, id int, parentID *int) (*entity.Folder, error) {
+	folder := r.folders[id]
+	path := "/"
+	if parentID != nil {
+		parent := r.folders[*parentID]
+		path = parent.Path + strconv.Itoa(parent.ID) + "/"
+	}
+	if strings.HasPrefix(path, folder.Path+strconv.Itoa(id)+"/") {
+		return nil, sql.ErrNoRows
+	}
+
+	oldPrefix, newPrefix := folder.Path+strconv.Itoa(id)+"/", path+strconv.Itoa(id)+"/"
+	for _, other := range r.folders {
+		if strings.HasPrefix(other.Path, oldPrefix) {
+			other.Path = newPrefix + strings.TrimPrefix(other.Path, oldPrefix)
+		}
+	}
+	folder.ParentID, folder.Path = parentID, path
+	moved := *folder
+	return &moved, nil
+}
+
+func (r *fakeFolderRepository) Delete(ctx context.Context, id int) error {
+	folder := r.folders[id]
+	for otherID, other := range r.folders {
+		if inSubtree(other, id, folder.Path) {
+			delete(r.folders, otherID)
+		}
+	}
+	delete(r.folders, id)
+	for _, file := range r.files.files {
+		if file.FolderID != nil && r.folders[*file.FolderID] == nil {
+			file.FolderID = nil
+		}
+	}
+	return nil
+}
+
+// fakeFolderFileRepository finds the files of a folder tree of a fakeFolderRepository
+type fakeFolderFileRepository struct {
+	*fakeFileRepository
+	folders *fakeFolderRepository
+}
+
+func (r *fakeFolderFileRepository) GetByFolderTree(ctx context.Context, folderID int) ([]entity.File, error) {
+	root := r.folders.folders[folderID]
+	var files []entity.File
+	for _, file := range r.files {
+		if file.FolderID == nil {
+			continue
+		}
+		if in := r.folders.folders[*file.FolderID]; in.ID == folderID || inSubtree(in, folderID, root.Path) {
+			files = append(files, *file)
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].ID < files[j].ID })
+	return files, nil
+}
+
+// newTestFolderService has the folders a/b/c, d, j, j/k and K of user 1, with k not below a despite
+// the IDs starting alike, and folder e of user 2. The files are stored with their content in blobRepo.
+func newTestFolderService(t *testing.T, blobRepo *fakeFileBlobRepository, files ...*entity.File) (FolderService, *fakeFolderRepository, *fakeFileRepository) {
+	t.Helper()
+
+	fileService, fileRepo := newTestFileService(t, blobRepo, files...)
+	folders := &fakeFolderRepository{files: fileRepo, folders: map[int]*entity.Folder{
+		1:  {ID: 1, UserID: 1, Name: "a", Path: "/"},
+		2:  {ID: 2, UserID: 1, ParentID: intPtr(1), Name: "b", Path: "/1/"},
+		3:  {ID: 3, UserID: 1, ParentID: intPtr(2), Name: "c", Path: "/1/2/"},
+		4:  {ID: 4, UserID: 1, Name: "d", Path: "/"},
+		5:  {ID: 5, UserID: 2, Name: "e", Path: "/"},
+		11: {ID: 11, UserID: 1, Name: "j", Path: "/"},
+		12: {ID: 12, UserID: 1, ParentID: intPtr(11), Name: "k", Path: "/11/"},
+		13: {ID: 13, UserID: 1, Name: "K", Path: "/"},
+	}}
+	treeFiles := &fakeFolderFileRepository{fakeFileRepository: fileRepo, folders: folders}
+	return NewFolderService(folders, treeFiles, fileService), folders, fileRepo
+}
+
+func TestMoveFolder(t *testing.T) {
+	tests := []struct {
+		name     string
+		id       int
+		parentID *int
+		wantErr  error
+		wantPath string
+	}{
+		{"into itself", 1, intPtr(1), ErrFolderInvalidMove, ""},
+		{"into its child", 1, intPtr(2), ErrFolderInvalidMove, ""},
+		{"into its grandchild", 1, intPtr(3), ErrFolderInvalidMove, ""},
+		{"into a folder with a similar path", 1, intPtr(12), nil, "/11/12/"},
+		{"into a sibling", 1, intPtr(4), nil, "/4/"},
+		{"child to the top level", 2, nil, nil, "/"},
+		{"into a folder of another user", 1, intPtr(5), ErrFolderNotFound, ""},
+		{"folder of another user", 5, intPtr(4), ErrFolderNotFound, ""},
+		{"into a missing folder", 1, intPtr(99), ErrFolderNotFound, ""},
+		{"next to a folder with the name in another case", 12, nil, ErrFolderExists, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, folders, _ := newTestFolderService(t, &fakeFileBlobRepository{})
+
+			_, err := s.MoveFolder(context.Background(), tt.id, 1, dto.MoveFolderRequest{ParentID: tt.parentID})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("MoveFolder error %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if path := folders.folders[1].Path; path != "/" {
+					t.Errorf("path of a = %q after a refused move, want it unchanged", path)
+				}
+				return
+			}
+			if path := folders.folders[tt.id].Path; path != tt.wantPath {
+				t.Errorf("path = %q, want %q", path, tt.wantPath)
+			}
+		})
+	}
+}
+
+func TestMoveFolderKeepsSubtree(t *testing.T) {
+	s, folders, _ := newTestFolderService(t, &fakeFileBlobRepository{},
+		&entity.File{ID: 1, UploadedBy: 1, FolderID: intPtr(3), FileSize: 30},
+	)
+
+	moved, err := s.MoveFolder(context.Background(), 2, 1, dto.MoveFolderRequest{ParentID: intPtr(4)})
+	if err != nil {
+		t.Fatalf("MoveFolder: %v", err)
+	}
+	if moved.ParentID == nil || *moved.ParentID != 4 {
+		t.Errorf("parent = %v, want d", moved.ParentID)
+	}
+	// The response carries the statistics of the subtree, which moved along
+	if moved.FolderCount != 1 || moved.FileCount != 1 || moved.TotalSize != 30 {
+		t.Errorf("moved folder has %d folders, %d files and %d bytes, want 1, 1 and 30", moved.FolderCount, moved.FileCount, moved.TotalSize)
+	}
+	if path := folders.folders[3].Path; path != "/4/2/" {
+		t.Errorf("path of c = %q, want below d and b", path)
+	}
+}
+
+func TestDeleteFolder(t *testing.T) {
+	// The file outside the tree has the same content as one inside
+	newBlobs := func() *fakeFileBlobRepository {
+		return &fakeFileBlobRepository{blobs: map[string]*entity.FileBlob{
+			"a":      {Checksum: "a", RefCount: 1, Stored: true},
+			"c":      {Checksum: "c", RefCount: 1, Stored: true},
+			"shared": {Checksum: "shared", RefCount: 2, Stored: true},
+		}}
+	}
+	newFiles := func() []*entity.File {
+		return []*entity.File{
+			{ID: 1, UploadedBy: 1, FolderID: intPtr(1), FileSize: 10, Checksum: "a"},
+			{ID: 2, UploadedBy: 1, FolderID: intPtr(3), FileSize: 20, Checksum: "c"},
+			{ID: 3, UploadedBy: 1, FolderID: intPtr(2), FileSize: 30, Checksum: "shared"},
+			{ID: 4, UploadedBy: 1, FolderID: intPtr(4), FileSize: 30, Checksum: "shared"},
+		}
+	}
+
+	t.Run("not empty", func(t *testing.T) {
+		s, folders, fileRepo := newTestFolderService(t, newBlobs(), newFiles()...)
+
+		err := s.DeleteFolder(context.Background(), 1, 1, false)
+		if !errors.Is(err, ErrFolderNotEmpty) {
+			t.Fatalf("DeleteFolder error %v, want %v", err, ErrFolderNotEmpty)
+		}
+		appErr, _ := apperror.As(err)
+		if details, ok := appErr.Details.(*dto.FolderResponse); !ok || details.FolderCount != 2 || details.FileCount != 3 || details.TotalSize != 60 {
+			t.Errorf("error details = %+v, want 2 folders, 3 files and 60 bytes", appErr.Details)
+		}
+		if len(folders.folders) != 8 || len(fileRepo.files) != 4 {
+			t.Errorf("%d folders and %d files left, want nothing deleted", len(folders.folders), len(fileRepo.files))
+		}
+	})
+
+	t.Run("recursive", func(t *testing.T) {
+		blobRepo := newBlobs()
+		s, folders, fileRepo := newTestFolderService(t, blobRepo, newFiles()...)
+
+		if err := s.DeleteFolder(context.Background(), 1, 1, true); err != nil {
+			t.Fatalf("DeleteFolder: %v", err)
+		}
+		for _, id := range []int{1, 2, 3} {
+			if _, ok := folders.folders[id]; ok {
+				t.Errorf("folder %d was not deleted", id)
+			}
+		}
+		if _, ok := folders.folders[4]; !ok {
+			t.Error("folder d was deleted")
+		}
+		if len(fileRepo.files) != 1 || fileRepo.files[4] == nil {
+			t.Errorf("files left = %v, want only the file in d", fileRepo.files)
+		}
+
+		// The content of the deleted files is released, but still referenced by the file in d
+		want := map[string]int{"a": 0, "c": 0, "shared": 1}
+		for checksum, refs := range want {
+			if got := blobRepo.blobs[checksum].RefCount; got != refs {
+				t.Errorf("content %s has %d references, want %d", checksum, got, refs)
+			}
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		s, folders, _ := newTestFolderService(t, newBlobs())
+
+		if err := s.DeleteFolder(context.Background(), 4, 1, false); err != nil {
+			t.Fatalf("DeleteFolder: %v", err)
+		}
+		if _, ok := folders.folders[4]; ok {
+			t.Error("folder d was not deleted")
+		}
+	})
+
+	t.Run("folder of another user", func(t *testing.T) {
+		s, folders, _ := newTestFolderService(t, newBlobs())
+
+		if err := s.DeleteFolder(context.Background(), 5, 1, true); !errors.Is(err, ErrFolderNotFound) {
+			t.Fatalf("DeleteFolder error %v, want %v", err, ErrFolderNotFound)
+		}
+		if _, ok := folders.folders[5]; !ok {
+			t.Error("folder of another user was deleted")
+		}
+	})
+}
@@ -0,0 +1,286 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go-template/internal/config"
+	"go-template/internal/entity"
+	"go-template/internal/logger"
+	"go-template/internal/repository"
+	"go-template/pkg/apperror"
+	"go-template/pkg/imageutil"
+	"go-template/pkg/storage"
+
+	"go.uber.org/zap"
+)
+
+var (
+	ErrFileVariantNotFound    = apperror.New(apperror.KindNotFound, apperror.CodeFileVariantNotFound, "file variant not found")
+	ErrFileImageUnprocessable = apperror.New(apperror.KindUnprocessable, apperror.CodeFileImageUnprocessable, "image cannot be processed")
+)
+
+// variantQueueSize bounds the uploads waiting for their variants; beyond it, variants are only
+// rendered on demand
+const variantQueueSize = 100
+
+// imageMimeTypes are the uploaded types variants are derived from
+var imageMimeTypes = []string{"image/jpeg", "image/png", "image/gif"}
+
+// ImageVariantService derives variants such as thumbnails from uploaded images. The configured
+// variants are rendered in the background after upload; any variant missing when requested, including
+// the on-demand widths, is rendered then and stored for later requests.
+type ImageVariantService interface {
+	// Enqueue schedules the configured variants of an uploaded image; other files are ignored
+	Enqueue(file *entity.File)
+	StartWorkers(workers int)
+	OpenVariant(ctx context.Context, file *entity.File, name string) (io.ReadSeekCloser, *entity.FileVariant, error)
+	// VariantNames returns the configured variants available for the file, none unless it is an image
+	VariantNames(file *entity.File) []string
+	// DeleteVariants removes the variants of a file, such as when it is deleted or gets new content
+	DeleteVariants(ctx context.Context, fileID int)
+}
+
+type imageVariantService struct {
+	variantRepo repository.FileVariantRepository
+	fileStorage storage.FileStorage
+	config      *config.Config
+	variants    []imageutil.Variant
+	queue       chan *entity.File
+
+	mu        sync.Mutex
+	rendering map[string]*renderCall
+}
+
+// renderCall lets concurrent requests for a variant wait for a single rendering
+type renderCall struct {
+	done    chan struct{}
+	variant *entity.FileVariant
+	err     error
+}
+
+func NewImageVariantService(variantRepo repository.FileVariantRepository, fileStorage storage.FileStorage, config *config.Config) ImageVariantService {
+	var variants []imageutil.Variant
+	for _, spec := range config.Images.Variants {
+		variant, err := imageutil.ParseVariant(spec)
+		if err != nil {
+			logger.Error("Ignoring invalid image variant", zap.Error(err))
+			continue
+		}
+		variants = append(variants, variant)
+	}
+
+	return &imageVariantService{
+		variantRepo: variantRepo,
+		fileStorage: fileStorage,
+		config:      config,
+		variants:    variants,
+		queue:       make(chan *entity.File, variantQueueSize),
+		rendering:   make(map[string]*renderCall),
+	}
+}
+
+func (s *imageVariantService) Enqueue(file *entity.File) {
+	// Avatars come with their own sizes, see saveAvatarVariants
+	if !isImage(file.MimeType) || file.Category == avatarCategory || len(s.variants) == 0 {
+		return
+	}
+
+	select {
+	case s.queue <- file:
+	default:
+		logger.Warn("Image variant queue is full, variants will be rendered on demand", zap.Int("file_id", file.ID))
+	}
+}
+
+// StartWorkers starts the background workers rendering the variants of enqueued images
+func (s *imageVariantService) StartWorkers(workers int) {
+	for i := 0; i < max(workers, 1); i++ {
+		go func() {
+			for file := range s.queue {
+				s.renderAll(context.Background(), file)
+			}
+		}()
+	}
+}
+
+func (s *imageVariantService) OpenVariant(ctx context.Context, file *entity.File, name string) (io.ReadSeekCloser, *entity.FileVariant, error) {
+	spec, ok := s.resolve(name)
+	if !ok || !isImage(file.MimeType) {
+		return nil, nil, ErrFileVariantNotFound
+	}
+
+	variant, err := s.variantRepo.GetByName(ctx, file.ID, name)
+	switch {
+	case err == nil:
+		content, _, err := storage.OpenSeekable(ctx, s.fileStorage, variant.StorageKey)
+		if err == nil {
+			return content, variant, nil
+		}
+		if !errors.Is(err, storage.ErrObjectNotFound) {
+			logger.Error("Failed to open image variant", zap.Error(err), zap.String("key", variant.StorageKey))
+			return nil, nil, ErrFileStorage.Wrap(err)
+		}
+		// The object went missing; render it again
+		logger.Warn("Stored image variant not found", zap.String("key", variant.StorageKey))
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, nil, err
+	}
+
+	variant, err = s.renderOnce(ctx, file, spec)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, _, err := storage.OpenSeekable(ctx, s.fileStorage, variant.StorageKey)
+	if err != nil {
+		logger.Error("Failed to open image variant", zap.Error(err), zap.String("key", variant.StorageKey))
+		return nil, nil, ErrFileStorage.Wrap(err)
+	}
+	return content, variant, nil
+}
+
+func (s *imageVariantService) VariantNames(file *entity.File) []string {
+	if !isImage(file.MimeType) {
+		return nil
+	}
+
+	names := make([]string, len(s.variants))
+	for i, variant := range s.variants {
+		names[i] = variant.Name
+	}
+	return names
+}
+
+func (s *imageVariantService) DeleteVariants(ctx context.Context, fileID int) {
+	variants, err := s.variantRepo.GetByFileID(ctx, fileID)
+	if err != nil {
+		logger.Warn("Failed to get image variants", zap.Error(err), zap.Int("file_id", fileID))
+		return
+	}
+
+	for _, variant := range variants {
+		if err := s.fileStorage.Delete(ctx, variant.StorageKey); err != nil {
+			logger.Warn("Failed to delete image variant", zap.Error(err), zap.String("key", variant.StorageKey))
+		}
+	}
+	if err := s.variantRepo.DeleteByFileID(ctx, fileID); err != nil {
+		logger.Warn("Failed to delete image variant records", zap.Error(err), zap.Int("file_id", fileID))
+	}
+}
+
+// resolve returns the configured variant with the name, or the on-demand width variant "w<width>"
+func (s *imageVariantService) resolve(name string) (imageutil.Variant, bool) {
+	for _, variant := range s.variants {
+		if variant.Name == name {
+			return variant, true
+		}
+	}
+
+	if digits, found := strings.CutPrefix(name, "w"); found {
+		width, err := strconv.Atoi(digits)
+		// Only the canonical spelling, so "w0320" does not render w320 a second time
+		if err == nil && name == "w"+strconv.Itoa(width) && slices.Contains(s.config.Images.OnDemandWidths, width) {
+			return imageutil.Variant{Name: name, Width: width}, true
+		}
+	}
+	return imageutil.Variant{}, false
+}
+
+// renderAll renders the configured variants of a file that are not stored yet
+func (s *imageVariantService) renderAll(ctx context.Context, file *entity.File) {
+	for _, spec := range s.variants {
+		if _, err := s.variantRepo.GetByName(ctx, file.ID, spec.Name); err == nil {
+			continue
+		}
+		if _, err := s.renderOnce(ctx, file, spec); err != nil {
+			logger.Warn("Failed to render image variant", zap.Error(err), zap.Int("file_id", file.ID), zap.String("variant", spec.Name))
+		}
+	}
+}
+
+// renderOnce renders a variant, or waits for the rendering already in progress
+func (s *imageVariantService) renderOnce(ctx context.Context, file *entity.File, spec imageutil.Variant) (*entity.FileVariant, error) {
+	key := fmt.Sprintf("%d/%s", file.ID, spec.Name)
+
+	s.mu.Lock()
+	if call, ok := s.rendering[key]; ok {
+		s.mu.Unlock()
+		<-call.done
+		return call.variant, call.err
+	}
+	call := &renderCall{done: make(chan struct{})}
+	s.rendering[key] = call
+	s.mu.Unlock()
+
+	call.variant, call.err = s.render(ctx, file, spec)
+
+	s.mu.Lock()
+	delete(s.rendering, key)
+	s.mu.Unlock()
+	close(call.done)
+
+	return call.variant, call.err
+}
+
+func (s *imageVariantService) render(ctx context.Context, file *entity.File, spec imageutil.Variant) (*entity.FileVariant, error) {
+	original, _, err := storage.OpenSeekable(ctx, s.fileStorage, file.FilePath)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return nil, ErrFileNotFound
+		}
+		return nil, ErrFileStorage.Wrap(err)
+	}
+	defer original.Close()
+
+	img, format, err := imageutil.Decode(original)
+	if err != nil {
+		logger.Warn("Failed to decode image", zap.Error(err), zap.Int("file_id", file.ID))
+		return nil, ErrFileImageUnprocessable.Wrap(err)
+	}
+
+	rendered := spec.Render(img)
+	var buf bytes.Buffer
+	mimeType, ext, err := imageutil.Encode(&buf, rendered, spec.OutputFormat(format))
+	if err != nil {
+		return nil, ErrFileImageUnprocessable.Wrap(err)
+	}
+
+	key := path.Join("variants", strconv.Itoa(file.ID), spec.Name+ext)
+	size := int64(buf.Len())
+	if err := s.fileStorage.Put(ctx, key, &buf, size, mimeType); err != nil {
+		return nil, ErrFileStorage.Wrap(err)
+	}
+
+	bounds := rendered.Bounds()
+	variant, err := s.variantRepo.Save(ctx, &entity.FileVariant{
+		FileID:     file.ID,
+		Name:       spec.Name,
+		StorageKey: key,
+		MimeType:   mimeType,
+		Width:      bounds.Dx(),
+		Height:     bounds.Dy(),
+		FileSize:   size,
+	})
+	if err != nil {
+		// Typically the file was deleted meanwhile
+		s.fileStorage.Delete(ctx, key)
+		return nil, err
+	}
+
+	logger.Debug("Image variant rendered", zap.Int("file_id", file.ID), zap.String("variant", spec.Name))
+	return variant, nil
+}
+
+func isImage(mimeType string) bool {
+	return slices.Contains(imageMimeTypes, mimeType)
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"go-template/internal/config"
+	"go-template/internal/dto"
+	"go-template/internal/entity"
+	"go-template/internal/logger"
+	"go-template/internal/repository"
+	"go-template/pkg/apperror"
+
+	"go.uber.org/zap"
+)
+
+var (
+	ErrStorageQuotaExceeded = apperror.New(apperror.KindTooLarge, apperror.CodeStorageQuotaExceeded, "storage quota exceeded")
+)
+
+// StorageQuotaService reports the storage used by users and enforces the quotas of their roles, which
+// admins can override per user
+type StorageQuotaService interface {
+	GetUsage(ctx context.Context, userID int) (*dto.StorageUsageResponse, error)
+	UpdateQuota(ctx context.Context, userID int, req dto.UpdateStorageQuotaRequest) (*dto.StorageUsageResponse, error)
+	// CheckQuota returns ErrStorageQuotaExceeded, detailing the usage, when storing another bytes in
+	// files new files would exceed the quotas of the user. Otherwise it returns the quotas of the
+	// user's role, which FileRepository enforces again as it records the usage, since concurrent
+	// uploads may use up the quota meanwhile.
+	CheckQuota(ctx context.Context, userID int, bytes int64, files int) (entity.StorageQuota, error)
+}
+
+type storageQuotaService struct {
+	storageRepo repository.UserStorageRepository
+	userRepo    repository.UserRepository
+	config      *config.Config
+}
+
+func NewStorageQuotaService(storageRepo repository.UserStorageRepository, userRepo repository.UserRepository, config *config.Config) StorageQuotaService {
+	return &storageQuotaService{
+		storageRepo: storageRepo,
+		userRepo:    userRepo,
+		config:      config,
+	}
+}
+
+func (s *storageQuotaService) GetUsage(ctx context.Context, userID int) (*dto.StorageUsageResponse, error) {
+	logger.Debug("Getting storage usage", zap.Int("user_id", userID))
+
+	return s.getUsage(ctx, userID)
+}
+
+func (s *storageQuotaService) UpdateQuota(ctx context.Context, userID int, req dto.UpdateStorageQuotaRequest) (*dto.StorageUsageResponse, error) {
+	logger.Info("Updating storage quota", zap.Int("user_id", userID))
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	usage, err := s.storageRepo.SetQuota(ctx, userID, req.QuotaBytes, req.QuotaFiles)
+	if err != nil {
+		logger.Error("Failed to update storage quota", zap.Error(err), zap.Int("user_id", userID))
+		return nil, err
+	}
+
+	logger.Info("Storage quota updated successfully", zap.Int("user_id", userID))
+
+	return s.mapUsageToResponse(user, usage), nil
+}
+
+func (s *storageQuotaService) CheckQuota(ctx context.Context, userID int, bytes int64, files int) (entity.StorageQuota, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return entity.StorageQuota{}, err
+	}
+
+	storage, err := s.storageRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		logger.Error("Failed to get storage usage", zap.Error(err), zap.Int("user_id", userID))
+		return entity.StorageQuota{}, err
+	}
+	usage := s.mapUsageToResponse(user, storage)
+
+	if (usage.QuotaBytes > 0 && usage.UsedBytes+bytes > usage.QuotaBytes) ||
+		(usage.QuotaFiles > 0 && usage.FileCount+files > usage.QuotaFiles) {
+		logger.Warn("Storage quota exceeded",
+			zap.Int("user_id", userID),
+			zap.Int64("used_bytes", usage.UsedBytes),
+			zap.Int64("bytes", bytes),
+			zap.Int64("quota_bytes", usage.QuotaBytes),
+			zap.Int("file_count", usage.FileCount),
+			zap.Int("quota_files", usage.QuotaFiles))
+		return entity.StorageQuota{}, ErrStorageQuotaExceeded.WithDetails(usage)
+	}
+
+	quotaBytes, quotaFiles := s.roleQuota(user.Role)
+	return entity.StorageQuota{Bytes: quotaBytes, Files: quotaFiles}, nil
+}
+
+// quotaExceededError is ErrStorageQuotaExceeded detailing the current usage of the user, for when
+// FileRepository refused to record more usage
+func quotaExceededError(ctx context.Context, quotaService StorageQuotaService, userID int) error {
+	usage, err := quotaService.GetUsage(ctx, userID)
+	if err != nil {
+		return ErrStorageQuotaExceeded
+	}
+	return ErrStorageQuotaExceeded.WithDetails(usage)
+}
+
+func (s *storageQuotaService) getUsage(ctx context.Context, userID int) (*dto.StorageUsageResponse, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	usage, err := s.storageRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		logger.Error("Failed to get storage usage", zap.Error(err), zap.Int("user_id", userID))
+		return nil, err
+	}
+
+	return s.mapUsageToResponse(user, usage), nil
+}
+
+func (s *storageQuotaService) getUser(ctx context.Context, userID int) (*entity.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Warn("User not found", zap.Int("user_id", userID))
+			return nil, ErrUserNotFound
+		}
+		logger.Error("Failed to get user", zap.Error(err), zap.Int("user_id", userID))
+		return nil, err
+	}
+	return user, nil
+}
+
+// roleQuota returns the configured quotas of a role; unknown roles get the quotas of regular users
+func (s *storageQuotaService) roleQuota(role string) (int64, int) {
+	quota := s.config.Quota
+	switch role {
+	case "admin":
+		return quota.AdminBytes, quota.AdminFiles
+	case "moderator":
+		return quota.ModeratorBytes, quota.ModeratorFiles
+	default:
+		return quota.UserBytes, quota.UserFiles
+	}
+}
+
+func (s *storageQuotaService) mapUsageToResponse(user *entity.User, usage *entity.UserStorage) *dto.StorageUsageResponse {
+	quotaBytes, quotaFiles := s.roleQuota(user.Role)
+	if usage.QuotaBytes != nil {
+		quotaBytes = *usage.QuotaBytes
+	}
+	if usage.QuotaFiles != nil {
+		quotaFiles = *usage.QuotaFiles
+	}
+
+	return &dto.StorageUsageResponse{
+		UsedBytes:   usage.UsedBytes,
+		FileCount:   usage.FileCount,
+		QuotaBytes:  quotaBytes,
+		QuotaFiles:  quotaFiles,
+		CustomQuota: usage.QuotaBytes != nil || usage.QuotaFiles != nil,
+	}
+}
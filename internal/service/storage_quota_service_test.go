@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"go-template/internal/config"
+	"go-template/internal/dto"
+	"go-template/internal/entity"
+	"go-template/internal/repository"
+	"go-template/pkg/apperror"
+)
+
+// fakeUserStorageRepository keeps usages in memory; users without a usage get an empty one
+type fakeUserStorageRepository struct {
+	repository.UserStorageRepository
+	usages map[int]*entity.UserStorage
+}
+
+func (r *fakeUserStorageRepository) GetByUserID(ctx context.Context, userID int) (*entity.UserStorage, error) {
+	usage, ok := r.usages[userID]
+	if !ok {
+		return &entity.UserStorage{UserID: userID}, nil
+	}
+	found := *usage
+	return &found, nil
+}
+
+func (r *fakeUserStorageRepository) SetQuota(ctx context.Context, userID int, quotaBytes *int64, quotaFiles *int) (*entity.UserStorage, error) {
+	usage, ok := r.usages[userID]
+	if !ok {
+		usage = &entity.UserStorage{UserID: userID}
+		r.usages[userID] = usage
+	}
+	usage.QuotaBytes, usage.QuotaFiles = quotaBytes, quotaFiles
+	updated := *usage
+	return &updated, nil
+}
+
+// fakeQuotaUserRepository looks users up by ID; other methods are not implemented
+type fakeQuotaUserRepository struct {
+	repository.UserRepository
+	users map[int]*entity.User
+}
+
+func (r *fakeQuotaUserRepository) GetByID(ctx context.Context, id int) (*entity.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return user, nil
+}
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
+
+// newTestStorageQuotaService has user 1, moderator 2 and admin 3, whose roles get 1000 bytes and 10
+// files, 5000 bytes and 50 files, and no limit
+func newTestStorageQuotaService() (StorageQuotaService, *fakeUserStorageRepository) {
+	users := &fakeQuotaUserRepository{users: map[int]*entity.User{
+		1: {ID: 1, Role: entity.RoleUser},
+		2: {ID: 2, Role: entity.RoleModerator},
+		3: {ID: 3, Role: entity.RoleAdmin},
+	}}
+	storage := &fakeUserStorageRepository{usages: map[int]*entity.UserStorage{}}
+	cfg := &config.Config{Quota: config.QuotaConfig{
+		UserBytes:      1000,
+		UserFiles:      10,
+		ModeratorBytes: 5000,
+		ModeratorFiles: 50,
+	}}
+	return NewStorageQuotaService(storage, users, cfg), storage
+}
+
+func TestStorageUsageRoleQuotas(t *testing.T) {
+	s, storage := newTestStorageQuotaService()
+	storage.usages[1] = &entity.UserStorage{UserID: 1, UsedBytes: 400, FileCount: 3}
+
+	tests := []struct {
+		name   string
+		userID int
+		want   dto.StorageUsageResponse
+	}{
+		{"user", 1, dto.StorageUsageResponse{UsedBytes: 400, FileCount: 3, QuotaBytes: 1000, QuotaFiles: 10}},
+		{"moderator without files", 2, dto.StorageUsageResponse{QuotaBytes: 5000, QuotaFiles: 50}},
+		{"admin", 3, dto.StorageUsageResponse{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usage, err := s.GetUsage(context.Background(), tt.userID)
+			if err != nil {
+				t.Fatalf("GetUsage: %v", err)
+			}
+			if *usage != tt.want {
+				t.Errorf("GetUsage = %+v, want %+v", *usage, tt.want)
+			}
+		})
+	}
+
+	if _, err := s.GetUsage(context.Background(), 99); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("GetUsage of a missing user: error %v, want %v", err, ErrUserNotFound)
+	}
+}
+
+func TestUpdateStorageQuota(t *testing.T) {
+	s, storage := newTestStorageQuotaService()
+	ctx := context.Background()
+	storage.usages[1] = &entity.UserStorage{UserID: 1, UsedBytes: 400, FileCount: 3}
+
+	// Only the bytes are overridden; the files keep the quota of the role
+	usage, err := s.UpdateQuota(ctx, 1, dto.UpdateStorageQuotaRequest{QuotaBytes: int64Ptr(2000)})
+	if err != nil {
+		t.Fatalf("UpdateQuota: %v", err)
+	}
+	want := dto.StorageUsageResponse{UsedBytes: 400, FileCount: 3, QuotaBytes: 2000, QuotaFiles: 10, CustomQuota: true}
+	if *usage != want {
+		t.Errorf("UpdateQuota = %+v, want %+v", *usage, want)
+	}
+
+	// An override of 0 lifts the limit of the role
+	usage, err = s.UpdateQuota(ctx, 1, dto.UpdateStorageQuotaRequest{QuotaBytes: int64Ptr(0), QuotaFiles: intPtr(0)})
+	if err != nil {
+		t.Fatalf("UpdateQuota: %v", err)
+	}
+	want = dto.StorageUsageResponse{UsedBytes: 400, FileCount: 3, CustomQuota: true}
+	if *usage != want {
+		t.Errorf("UpdateQuota to unlimited = %+v, want %+v", *usage, want)
+	}
+
+	// Removing the overrides restores the quotas of the role
+	usage, err = s.UpdateQuota(ctx, 1, dto.UpdateStorageQuotaRequest{})
+	if err != nil {
+		t.Fatalf("UpdateQuota: %v", err)
+	}
+	want = dto.StorageUsageResponse{UsedBytes: 400, FileCount: 3, QuotaBytes: 1000, QuotaFiles: 10}
+	if *usage != want {
+		t.Errorf("UpdateQuota without overrides = %+v, want %+v", *usage, want)
+	}
+
+	if _, err := s.UpdateQuota(ctx, 99, dto.UpdateStorageQuotaRequest{}); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("UpdateQuota of a missing user: error %v, want %v", err, ErrUserNotFound)
+	}
+	if _, ok := storage.usages[99]; ok {
+		t.Error("UpdateQuota recorded a quota for a missing user")
+	}
+}
+
+func TestCheckQuota(t *testing.T) {
+	tests := []struct {
+		name      string
+		userID    int
+		usage     *entity.UserStorage
+		bytes     int64
+		files     int
+		wantQuota entity.StorageQuota
+		wantErr   error
+	}{
+		{"fits", 1, &entity.UserStorage{UsedBytes: 400, FileCount: 3}, 600, 1, entity.StorageQuota{Bytes: 1000, Files: 10}, nil},
+		{"bytes exceeded", 1, &entity.UserStorage{UsedBytes: 400, FileCount: 3}, 601, 1, entity.StorageQuota{}, ErrStorageQuotaExceeded},
+		{"files exceeded", 1, &entity.UserStorage{UsedBytes: 400, FileCount: 10}, 1, 1, entity.StorageQuota{}, ErrStorageQuotaExceeded},
+		{"new version without a file", 1, &entity.UserStorage{UsedBytes: 400, FileCount: 10}, 600, 0, entity.StorageQuota{Bytes: 1000, Files: 10}, nil},
+		{"moderator quota", 2, &entity.UserStorage{UsedBytes: 4000}, 1000, 1, entity.StorageQuota{Bytes: 5000, Files: 50}, nil},
+		{"admin unlimited", 3, &entity.UserStorage{UsedBytes: 1 << 40, FileCount: 1 << 20}, 1 << 30, 1, entity.StorageQuota{}, nil},
+		{"raised override", 1, &entity.UserStorage{UsedBytes: 400, QuotaBytes: int64Ptr(2000)}, 1600, 1, entity.StorageQuota{Bytes: 1000, Files: 10}, nil},
+		{"lowered override", 2, &entity.UserStorage{UsedBytes: 400, QuotaBytes: int64Ptr(500)}, 101, 1, entity.StorageQuota{}, ErrStorageQuotaExceeded},
+		{"unlimited override", 1, &entity.UserStorage{UsedBytes: 400, FileCount: 10, QuotaBytes: int64Ptr(0), QuotaFiles: intPtr(0)}, 1 << 30, 1, entity.StorageQuota{Bytes: 1000, Files: 10}, nil},
+		{"missing user", 99, nil, 1, 1, entity.StorageQuota{}, ErrUserNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, storage := newTestStorageQuotaService()
+			if tt.usage != nil {
+				storage.usages[tt.userID] = tt.usage
+			}
+
+			// The quotas returned are those of the role: FileRepository applies the overrides it
+			// stores itself when it records the usage
+			quota, err := s.CheckQuota(context.Background(), tt.userID, tt.bytes, tt.files)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CheckQuota error %v, want %v", err, tt.wantErr)
+			}
+			if quota != tt.wantQuota {
+				t.Errorf("CheckQuota = %+v, want %+v", quota, tt.wantQuota)
+			}
+		})
+	}
+}
+
+func TestCheckQuotaDetailsUsage(t *testing.T) {
+	s, storage := newTestStorageQuotaService()
+	storage.usages[1] = &entity.UserStorage{UserID: 1, UsedBytes: 900, FileCount: 3}
+
+	_, err := s.CheckQuota(context.Background(), 1, 200, 1)
+	var appErr *apperror.Error
+	if !errors.As(err, &appErr) {
+		t.Fatalf("CheckQuota error %v, want %v", err, ErrStorageQuotaExceeded)
+	}
+	want := dto.StorageUsageResponse{UsedBytes: 900, FileCount: 3, QuotaBytes: 1000, QuotaFiles: 10}
+	if usage, ok := appErr.Details.(*dto.StorageUsageResponse); !ok || *usage != want {
+		t.Errorf("CheckQuota error details = %+v, want %+v", appErr.Details, want)
+	}
+
+	// Refusals of the repository are detailed the same way
+	err = quotaExceededError(context.Background(), s, 1)
+	if !errors.As(err, &appErr) || !errors.Is(err, ErrStorageQuotaExceeded) {
+		t.Fatalf("quotaExceededError = %v, want %v", err, ErrStorageQuotaExceeded)
+	}
+	if usage, ok := appErr.Details.(*dto.StorageUsageResponse); !ok || *usage != want {
+		t.Errorf("quotaExceededError details = %+v, want %+v", appErr.Details, want)
+	}
+}
@@ -0,0 +1,485 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"slices"
+	"time"
+
+	"go-template/internal/config"
+	"go-template/internal/dto"
+	"go-template/internal/entity"
+	"go-template/internal/logger"
+	"go-template/internal/repository"
+	"go-template/pkg/apperror"
+	"go-template/pkg/filetype"
+	"go-template/pkg/storage"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrUploadNotFound        = apperror.New(apperror.KindNotFound, apperror.CodeUploadNotFound, "upload not found")
+	ErrUploadTooLarge        = apperror.New(apperror.KindTooLarge, apperror.CodeUploadTooLarge, "upload exceeds the maximum allowed size")
+	ErrUploadOffsetMismatch  = apperror.New(apperror.KindConflict, apperror.CodeUploadOffsetMismatch, "upload offset does not match the current offset")
+	ErrUploadLengthExceeded  = apperror.New(apperror.KindTooLarge, apperror.CodeUploadLengthExceeded, "chunk exceeds the declared upload length")
+	ErrUploadChunkIncomplete = apperror.New(apperror.KindInvalid, apperror.CodeUploadChunkIncomplete, "chunk ended before its declared length")
+)
+
+// uploadPartSize bounds how much of a chunk is held in memory before it is stored; a client going away
+// loses at most the part being received
+const uploadPartSize = 4 << 20
+
+// UploadService implements resumable uploads following the tus protocol. Every chunk is stored as
+// separate objects of at most uploadPartSize; once all bytes have arrived they are assembled into a
+// regular file.
+type UploadService interface {
+	CreateUpload(ctx context.Context, userID int, req dto.CreateUploadRequest) (*entity.FileUpload, error)
+	GetUpload(ctx context.Context, userID int, id string) (*entity.FileUpload, error)
+	WriteChunk(ctx context.Context, userID int, id string, offset int64, content io.Reader, size int64) (*entity.FileUpload, error)
+	TerminateUpload(ctx context.Context, userID int, id string) error
+	MaxUploadSize() int64
+	PurgeExpiredUploads(ctx context.Context) error
+	StartCleanupWorker(interval time.Duration)
+}
+
+type uploadService struct {
+	uploadRepo   repository.FileUploadRepository
+	fileRepo     repository.FileRepository
+	folderRepo   repository.FolderRepository
+	blobs        *blobStore
+	scanService  FileScanService
+	quotaService StorageQuotaService
+	fileStorage  storage.FileStorage
+	config       *config.Config
+}
+
+func NewUploadService(uploadRepo repository.FileUploadRepository, fileRepo repository.FileRepository, folderRepo repository.FolderRepository, blobRepo repository.FileBlobRepository, scanService FileScanService, quotaService StorageQuotaService, fileStorage storage.FileStorage, config *config.Config) UploadService {
+	return &uploadService{
+		uploadRepo:   uploadRepo,
+		fileRepo:     fileRepo,
+		folderRepo:   folderRepo,
+		blobs:        newBlobStore(blobRepo, fileStorage),
+		scanService:  scanService,
+		quotaService: quotaService,
+		fileStorage:  fileStorage,
+		config:       config,
+	}
+}
+
+func (s *uploadService) CreateUpload(ctx context.Context, userID int, req dto.CreateUploadRequest) (*entity.FileUpload, error) {
+	logger.Info("Creating resumable upload", zap.String("original_name", req.FileName), zap.Int64("length", req.Length), zap.Int("user_id", userID))
+
+	if maxSize := s.MaxUploadSize(); req.Length > maxSize {
+		logger.Warn("Upload length exceeds limit", zap.Int64("length", req.Length), zap.Int64("max_size", maxSize))
+		return nil, ErrUploadTooLarge
+	}
+
+	if !slices.Contains(s.config.Upload.AllowedTypes, req.FileType) {
+		logger.Warn("Invalid file type", zap.String("mime_type", req.FileType))
+		return nil, ErrFileTypeNotAllowed
+	}
+
+	if req.FolderID != nil {
+		if _, err := getOwnedFolder(ctx, s.folderRepo, *req.FolderID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	// The quota is checked up front so an upload that cannot be kept is not transferred
+	if _, err := s.quotaService.CheckQuota(ctx, userID, req.Length, 1); err != nil {
+		return nil, err
+	}
+
+	upload, err := s.uploadRepo.Create(ctx, &entity.FileUpload{
+		ID:           uuid.New().String(),
+		UserID:       userID,
+		Length:       req.Length,
+		Metadata:     req.Metadata,
+		OriginalName: req.FileName,
+		MimeType:     req.FileType,
+		Description:  req.Description,
+		Category:     req.Category,
+		FolderID:     req.FolderID,
+		ExpiresAt:    time.Now().Add(s.config.Upload.TusExpiration),
+	})
+	if err != nil {
+		logger.Error("Failed to create upload", zap.Error(err))
+		return nil, err
+	}
+
+	// An empty file is complete as soon as it is declared
+	if upload.Length == 0 {
+		return s.completeUpload(ctx, upload)
+	}
+
+	logger.Info("Resumable upload created", zap.String("upload_id", upload.ID))
+
+	return upload, nil
+}
+
+func (s *uploadService) GetUpload(ctx context.Context, userID int, id string) (*entity.FileUpload, error) {
+	upload, err := s.uploadRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Warn("Upload not found", zap.String("upload_id", id))
+			return nil, ErrUploadNotFound
+		}
+		logger.Error("Failed to get upload", zap.Error(err))
+		return nil, err
+	}
+
+	// Uploads of other users are reported as missing so their IDs cannot be probed
+	if upload.UserID != userID {
+		logger.Warn("Upload belongs to another user", zap.String("upload_id", id), zap.Int("user_id", userID))
+		return nil, ErrUploadNotFound
+	}
+
+	return upload, nil
+}
+
+func (s *uploadService) WriteChunk(ctx context.Context, userID int, id string, offset int64, content io.Reader, size int64) (*entity.FileUpload, error) {
+	upload, err := s.GetUpload(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if offset != upload.Offset {
+		logger.Warn("Upload offset mismatch", zap.String("upload_id", id), zap.Int64("offset", offset), zap.Int64("current_offset", upload.Offset))
+		return nil, ErrUploadOffsetMismatch
+	}
+
+	if offset+size > upload.Length {
+		logger.Warn("Chunk exceeds upload length", zap.String("upload_id", id), zap.Int64("offset", offset), zap.Int64("size", size))
+		return nil, ErrUploadLengthExceeded
+	}
+
+	if size > 0 {
+		upload, err = s.storeChunk(ctx, upload, content, size)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Also retried when a previous assembly of a fully received upload failed
+	if upload.Offset == upload.Length && upload.FileID == nil {
+		return s.completeUpload(ctx, upload)
+	}
+
+	return upload, nil
+}
+
+// storeChunk stores the body of a chunk in parts of at most uploadPartSize, advancing the upload past
+// each. When the client goes away, the parts received so far are kept and the upload resumes after
+// them instead of starting the chunk over.
+func (s *uploadService) storeChunk(ctx context.Context, upload *entity.FileUpload, content io.Reader, size int64) (*entity.FileUpload, error) {
+	// The checksum is computed as chunks arrive, so completing the upload needs no extra pass
+	contentHash, err := restoreHash(upload.HashState)
+	if err != nil {
+		logger.Error("Failed to restore upload checksum state", zap.Error(err), zap.String("upload_id", upload.ID))
+		return nil, err
+	}
+
+	// A client going away cancels the request, which must not prevent keeping what it sent
+	ctx = context.WithoutCancel(ctx)
+
+	part := make([]byte, min(size, uploadPartSize))
+	for remaining := size; remaining > 0; {
+		n, readErr := io.ReadFull(content, part[:min(remaining, int64(len(part)))])
+		if n > 0 {
+			contentHash.Write(part[:n])
+			upload, err = s.storeChunkPart(ctx, upload, part[:n], contentHash)
+			if err != nil {
+				return nil, err
+			}
+			remaining -= int64(n)
+		}
+		if readErr != nil {
+			logger.Warn("Upload chunk interrupted", zap.Error(readErr), zap.String("upload_id", upload.ID), zap.Int64("offset", upload.Offset))
+			return nil, ErrUploadChunkIncomplete.Wrap(readErr)
+		}
+	}
+
+	return upload, nil
+}
+
+// storeChunkPart stores a part of a chunk at the current offset of the upload and records it along
+// with the checksum state covering it
+func (s *uploadService) storeChunkPart(ctx context.Context, upload *entity.FileUpload, part []byte, contentHash hash.Hash) (*entity.FileUpload, error) {
+	hashState, err := contentHash.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		logger.Error("Failed to save upload checksum state", zap.Error(err), zap.String("upload_id", upload.ID))
+		return nil, err
+	}
+
+	// Concurrent requests for the same offset must not overwrite each other's chunk
+	key := fmt.Sprintf("chunks/%s/%020d-%s", upload.ID, upload.Offset, uuid.New().String())
+	if err := s.fileStorage.Put(ctx, key, bytes.NewReader(part), int64(len(part)), "application/octet-stream"); err != nil {
+		logger.Error("Failed to store upload chunk", zap.Error(err), zap.String("upload_id", upload.ID))
+		return nil, ErrFileStorage.Wrap(err)
+	}
+
+	appended, err := s.uploadRepo.AppendChunk(ctx, &entity.FileUploadChunk{
+		UploadID:   upload.ID,
+		Offset:     upload.Offset,
+		Size:       int64(len(part)),
+		StorageKey: key,
+	}, hashState, time.Now().Add(s.config.Upload.TusExpiration))
+	if err != nil {
+		s.fileStorage.Delete(ctx, key)
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Warn("Upload offset moved while writing chunk", zap.String("upload_id", upload.ID))
+			return nil, ErrUploadOffsetMismatch
+		}
+		logger.Error("Failed to record upload chunk", zap.Error(err), zap.String("upload_id", upload.ID))
+		return nil, err
+	}
+	return appended, nil
+}
+
+func (s *uploadService) TerminateUpload(ctx context.Context, userID int, id string) error {
+	logger.Info("Terminating upload", zap.String("upload_id", id), zap.Int("user_id", userID))
+
+	upload, err := s.GetUpload(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.discardUpload(ctx, upload); err != nil {
+		logger.Error("Failed to terminate upload", zap.Error(err), zap.String("upload_id", id))
+		return err
+	}
+
+	logger.Info("Upload terminated successfully", zap.String("upload_id", id))
+
+	return nil
+}
+
+func (s *uploadService) MaxUploadSize() int64 {
+	return uploadSizeLimit(s.config.Upload.TusMaxSize, s.scanService)
+}
+
+// PurgeExpiredUploads discards uploads that made no progress within the expiration period
+func (s *uploadService) PurgeExpiredUploads(ctx context.Context) error {
+	uploads, err := s.uploadRepo.GetExpired(ctx, time.Now())
+	if err != nil {
+		logger.Error("Failed to get expired uploads", zap.Error(err))
+		return err
+	}
+
+	for _, upload := range uploads {
+		if err := s.discardUpload(ctx, &upload); err != nil {
+			logger.Error("Failed to discard expired upload", zap.Error(err), zap.String("upload_id", upload.ID))
+		}
+	}
+
+	return nil
+}
+
+// StartCleanupWorker periodically purges expired uploads
+func (s *uploadService) StartCleanupWorker(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			s.PurgeExpiredUploads(context.Background())
+		}
+	}()
+}
+
+// completeUpload assembles the chunks into the final object and creates the file record. An upload
+// that no longer fits in the storage quota is kept, so it can be completed once space is freed.
+func (s *uploadService) completeUpload(ctx context.Context, upload *entity.FileUpload) (*entity.FileUpload, error) {
+	// Other files may have been stored since the upload was created
+	quota, err := s.quotaService.CheckQuota(ctx, upload.UserID, upload.Length, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	chunks, err := s.uploadRepo.GetChunks(ctx, upload.ID)
+	if err != nil {
+		logger.Error("Failed to get upload chunks", zap.Error(err), zap.String("upload_id", upload.ID))
+		return nil, err
+	}
+
+	chunkContent := &chunkReader{ctx: ctx, storage: s.fileStorage, chunks: chunks}
+	defer chunkContent.Close()
+
+	// The declared filetype is only trusted once the content confirms it
+	mimeType, content, err := filetype.Detect(chunkContent)
+	if err != nil {
+		logger.Error("Failed to read upload chunks", zap.Error(err), zap.String("upload_id", upload.ID))
+		return nil, ErrFileStorage.Wrap(err)
+	}
+	if err := validateFileType(s.config, mimeType, upload.MimeType, upload.OriginalName); err != nil {
+		logger.Warn("Invalid file type", zap.String("mime_type", mimeType), zap.String("declared_type", upload.MimeType), zap.String("upload_id", upload.ID))
+		// The content cannot become valid by resuming, so the upload is dropped
+		if discardErr := s.discardUpload(ctx, upload); discardErr != nil {
+			logger.Error("Failed to discard rejected upload", zap.Error(discardErr), zap.String("upload_id", upload.ID))
+		}
+		return nil, err
+	}
+
+	// Identifying metadata is removed first; the checksum then covers the stripped content instead of
+	// the one computed as chunks arrived
+	stripped, err := stripContentMetadata(s.config, content, mimeType)
+	if err != nil {
+		logger.Warn("Failed to strip file metadata", zap.Error(err), zap.String("upload_id", upload.ID))
+		if errors.Is(err, ErrFileImageUnprocessable) {
+			if discardErr := s.discardUpload(ctx, upload); discardErr != nil {
+				logger.Error("Failed to discard rejected upload", zap.Error(discardErr), zap.String("upload_id", upload.ID))
+			}
+		}
+		return nil, err
+	}
+
+	size := upload.Length
+	var checksum string
+	if stripped != nil {
+		defer stripped.Close()
+		content, size, checksum = stripped, stripped.size, stripped.checksum
+	} else {
+		contentHash, err := restoreHash(upload.HashState)
+		if err != nil {
+			logger.Error("Failed to restore upload checksum state", zap.Error(err), zap.String("upload_id", upload.ID))
+			return nil, err
+		}
+		checksum = hex.EncodeToString(contentHash.Sum(nil))
+	}
+
+	// Content already stored by another upload is not assembled again
+	key, err := s.blobs.put(ctx, checksum, content, size, mimeType)
+	if err != nil {
+		logger.Error("Failed to assemble upload", zap.Error(err), zap.String("upload_id", upload.ID))
+		return nil, ErrFileStorage.Wrap(err)
+	}
+	metadata := extractContentMetadata(ctx, s.fileStorage, s.config, key, mimeType)
+
+	// The file is created and the upload completed together, so a concurrent request completing the
+	// same upload cannot create a second file
+	fileName := storage.NewKey(upload.OriginalName)
+	file, err := s.fileRepo.CreateFromUpload(ctx, upload.ID, fileName, upload.OriginalName, key, size, mimeType, upload.Description, upload.Category, upload.UserID, checksum, s.scanService.InitialStatus(), metadata, upload.FolderID, quota)
+	if err != nil {
+		s.blobs.release(ctx, checksum)
+		if errors.Is(err, repository.ErrQuotaExceeded) {
+			logger.Warn("Storage quota exceeded while completing upload", zap.String("upload_id", upload.ID), zap.Int("user_id", upload.UserID))
+			return nil, quotaExceededError(ctx, s.quotaService, upload.UserID)
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Info("Upload completed by another request", zap.String("upload_id", upload.ID))
+			return s.GetUpload(ctx, upload.UserID, upload.ID)
+		}
+		logger.Error("Failed to save file to database", zap.Error(err), zap.String("upload_id", upload.ID))
+		return nil, err
+	}
+
+	completed := *upload
+	completed.FileID = &file.ID
+
+	// The chunks are redundant once assembled
+	s.deleteChunks(ctx, upload.ID, chunks)
+
+	logger.Info("Resumable upload completed", zap.String("upload_id", upload.ID), zap.Int("file_id", file.ID))
+
+	s.scanService.Enqueue(file)
+
+	return &completed, nil
+}
+
+// discardUpload removes an upload and its stored chunks; a file created from it is kept
+func (s *uploadService) discardUpload(ctx context.Context, upload *entity.FileUpload) error {
+	chunks, err := s.uploadRepo.GetChunks(ctx, upload.ID)
+	if err != nil {
+		return err
+	}
+
+	// Chunk rows are removed by ON DELETE CASCADE
+	if err := s.uploadRepo.Delete(ctx, upload.ID); err != nil {
+		return err
+	}
+
+	for _, chunk := range chunks {
+		if err := s.fileStorage.Delete(ctx, chunk.StorageKey); err != nil {
+			logger.Warn("Failed to delete upload chunk", zap.Error(err), zap.String("key", chunk.StorageKey))
+		}
+	}
+
+	return nil
+}
+
+func (s *uploadService) deleteChunks(ctx context.Context, uploadID string, chunks []entity.FileUploadChunk) {
+	if err := s.uploadRepo.DeleteChunks(ctx, uploadID); err != nil {
+		logger.Warn("Failed to delete upload chunk records", zap.Error(err), zap.String("upload_id", uploadID))
+		return
+	}
+
+	for _, chunk := range chunks {
+		if err := s.fileStorage.Delete(ctx, chunk.StorageKey); err != nil {
+			logger.Warn("Failed to delete upload chunk", zap.Error(err), zap.String("key", chunk.StorageKey))
+		}
+	}
+}
+
+// chunkReader streams the chunks of an upload one after another, opening each only when it is reached
+type chunkReader struct {
+	ctx     context.Context
+	storage storage.FileStorage
+	chunks  []entity.FileUploadChunk
+	current io.ReadCloser
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for {
+		if r.current == nil {
+			if len(r.chunks) == 0 {
+				return 0, io.EOF
+			}
+			content, _, err := r.storage.Open(r.ctx, r.chunks[0].StorageKey)
+			if err != nil {
+				return 0, err
+			}
+			r.current = content
+			r.chunks = r.chunks[1:]
+		}
+
+		n, err := r.current.Read(p)
+		if err == io.EOF {
+			r.current.Close()
+			r.current = nil
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+		return n, err
+	}
+}
+
+func (r *chunkReader) Close() error {
+	if r.current != nil {
+		return r.current.Close()
+	}
+	return nil
+}
+
+// restoreHash resumes the SHA-256 of an upload from the state saved with its last chunk
+func restoreHash(state []byte) (hash.Hash, error) {
+	contentHash := sha256.New()
+	if state == nil {
+		return contentHash, nil
+	}
+	if err := contentHash.(encoding.BinaryUnmarshaler).UnmarshalBinary(state); err != nil {
+		return nil, err
+	}
+	return contentHash, nil
+}
@@ -0,0 +1,367 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"errors"
+	"io"
+	"math/rand"
+	"testing"
+	"time"
+
+	"go-template/internal/config"
+	"go-template/internal/dto"
+	"go-template/internal/entity"
+	"go-template/internal/repository"
+	"go-template/pkg/storage"
+)
+
+// fakeFileUploadRepository keeps uploads and their chunks in memory
+type fakeFileUploadRepository struct {
+	repository.FileUploadRepository
+	uploads map[string]*entity.FileUpload
+	chunks  map[string][]entity.FileUploadChunk
+}
+
+func (r *fakeFileUploadRepository) Create(ctx context.Context, upload *entity.FileUpload) (*entity.FileUpload, error) {
+	created := *upload
+	r.uploads[upload.ID] = &created
+	return upload, nil
+}
+
+func (r *fakeFileUploadRepository) GetByID(ctx context.Context, id string) (*entity.FileUpload, error) {
+	upload, ok := r.uploads[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	found := *upload
+	return &found, nil
+}
+
+func (r *fakeFileUploadRepository) AppendChunk(ctx context.Context, chunk *entity.FileUploadChunk, hashState []byte, expiresAt time.Time) (*entity.FileUpload, error) {
+	upload, ok := r.uploads[chunk.UploadID]
+	if !ok || upload.Offset != chunk.Offset {
+		return nil, sql.ErrNoRows
+	}
+	upload.Offset += chunk.Size
+	upload.HashState = hashState
+	upload.ExpiresAt = expiresAt
+	r.chunks[chunk.UploadID] = append(r.chunks[chunk.UploadID], *chunk)
+	appended := *upload
+	return &appended, nil
+}
+
+func (r *fakeFileUploadRepository) GetChunks(ctx context.Context, uploadID string) ([]entity.FileUploadChunk, error) {
+	return r.chunks[uploadID], nil
+}
+
+func (r *fakeFileUploadRepository) DeleteChunks(ctx context.Context, uploadID string) error {
+	delete(r.chunks, uploadID)
+	return nil
+}
+
+// interruptedReader returns its content and then fails, like the body of a request whose client went away
+type interruptedReader struct {
+	content io.Reader
+}
+
+var errConnectionReset = errors.New("connection reset by peer")
+
+func (r *interruptedReader) Read(p []byte) (int, error) {
+	n, err := r.content.Read(p)
+	if err == io.EOF {
+		return n, errConnectionReset
+	}
+	return n, err
+}
+
+// fakeUploadFileRepository records the files created from uploads. completedElsewhere simulates a
+// concurrent request completing the upload first.
+type fakeUploadFileRepository struct {
+	repository.FileRepository
+	uploads            *fakeFileUploadRepository
+	files              []entity.File
+	completedElsewhere bool
+}
+
+func (r *fakeUploadFileRepository) CreateFromUpload(ctx context.Context, uploadID string, fileName, originalName, filePath string, fileSize int64, mimeType, description, category string, uploadedBy int, checksum, scanStatus string, metadata *entity.FileMetadata, folderID *int, quota entity.StorageQuota) (*entity.File, error) {
+	upload := r.uploads.uploads[uploadID]
+	if r.completedElsewhere {
+		fileID := 99
+		upload.FileID = &fileID
+	}
+	if upload.FileID != nil {
+		return nil, sql.ErrNoRows
+	}
+	file := entity.File{ID: len(r.files) + 1, FileName: fileName, OriginalName: originalName, FilePath: filePath, FileSize: fileSize, UploadedBy: uploadedBy, Checksum: checksum, FolderID: folderID}
+	r.files = append(r.files, file)
+	upload.FileID = &file.ID
+	return &file, nil
+}
+
+// fakeQuotaService allows every upload
+type fakeQuotaService struct {
+	StorageQuotaService
+}
+
+func (s fakeQuotaService) CheckQuota(ctx context.Context, userID int, bytes int64, files int) (entity.StorageQuota, error) {
+	return entity.StorageQuota{}, nil
+}
+
+// fakeScanService records the files handed to it
+type fakeScanService struct {
+	FileScanService
+	enqueued []*entity.File
+}
+
+func (s *fakeScanService) InitialStatus() string {
+	return "clean"
+}
+
+func (s *fakeScanService) MaxSize() int64 {
+	return 0
+}
+
+func (s *fakeScanService) Enqueue(file *entity.File) {
+	s.enqueued = append(s.enqueued, file)
+}
+
+// testUploads holds an upload service and the fakes behind it, with a single upload of the given length.
+// User 1 has folder 1 and user 2 folder 2.
+type testUploads struct {
+	UploadService
+	uploadRepo  *fakeFileUploadRepository
+	fileRepo    *fakeUploadFileRepository
+	blobRepo    *fakeFileBlobRepository
+	scan        *fakeScanService
+	fileStorage storage.FileStorage
+}
+
+func newTestUploadService(t *testing.T, length int64) *testUploads {
+	t.Helper()
+
+	uploadRepo := &fakeFileUploadRepository{
+		uploads: map[string]*entity.FileUpload{
+			"upload": {ID: "upload", UserID: 1, Length: length, OriginalName: "notes.txt", MimeType: "text/plain"},
+		},
+		chunks: map[string][]entity.FileUploadChunk{},
+	}
+	u := &testUploads{
+		uploadRepo:  uploadRepo,
+		fileRepo:    &fakeUploadFileRepository{uploads: uploadRepo},
+		blobRepo:    &fakeFileBlobRepository{blobs: map[string]*entity.FileBlob{}},
+		scan:        &fakeScanService{},
+		fileStorage: storage.NewLocalStorage(t.TempDir(), storage.URLConfig{BaseURL: "http://localhost:8080", Secret: "secret"}),
+	}
+	cfg := &config.Config{Upload: config.UploadConfig{TusExpiration: time.Hour, TusMaxSize: 1 << 30, AllowedTypes: []string{"text/plain"}}}
+	folders := &fakeFolderRepository{files: newFakeFileRepository(), folders: map[int]*entity.Folder{
+		1: {ID: 1, UserID: 1, Name: "notes", Path: "/"},
+		2: {ID: 2, UserID: 2, Name: "notes", Path: "/"},
+	}}
+	u.UploadService = NewUploadService(uploadRepo, u.fileRepo, folders, u.blobRepo, u.scan, fakeQuotaService{}, u.fileStorage, cfg)
+	return u
+}
+
+// storedChunks concatenates the stored chunks of the upload, checking they follow each other
+func (u *testUploads) storedChunks(t *testing.T) []byte {
+	t.Helper()
+
+	var content []byte
+	for _, chunk := range u.uploadRepo.chunks["upload"] {
+		if chunk.Offset != int64(len(content)) {
+			t.Fatalf("chunk at offset %d follows %d bytes", chunk.Offset, len(content))
+		}
+		if chunk.Size > uploadPartSize {
+			t.Errorf("chunk of %d bytes exceeds the part size", chunk.Size)
+		}
+		content = append(content, readObject(t, u.fileStorage, chunk.StorageKey)...)
+	}
+	return content
+}
+
+func TestWriteChunkStoresLargeChunksInParts(t *testing.T) {
+	content := make([]byte, 2*uploadPartSize+1234)
+	rand.New(rand.NewSource(1)).Read(content)
+	uploads := newTestUploadService(t, int64(len(content))+1)
+
+	upload, err := uploads.WriteChunk(context.Background(), 1, "upload", 0, bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		t.Fatalf("WriteChunk: %v", err)
+	}
+	if upload.Offset != int64(len(content)) {
+		t.Errorf("offset %d, want %d", upload.Offset, len(content))
+	}
+	if got := len(uploads.uploadRepo.chunks["upload"]); got != 3 {
+		t.Errorf("stored %d chunks, want 3", got)
+	}
+	if got := uploads.storedChunks(t); !bytes.Equal(got, content) {
+		t.Error("stored chunks differ from the content sent")
+	}
+}
+
+func TestWriteChunkKeepsPartsReceivedBeforeInterruption(t *testing.T) {
+	content := make([]byte, 3*uploadPartSize)
+	rand.New(rand.NewSource(2)).Read(content)
+	uploads := newTestUploadService(t, int64(len(content))+1)
+	ctx := context.Background()
+
+	// The client declares the whole content but goes away after sending a part and a half
+	received := uploadPartSize + uploadPartSize/2
+	_, err := uploads.WriteChunk(ctx, 1, "upload", 0, &interruptedReader{bytes.NewReader(content[:received])}, int64(len(content)))
+	if !errors.Is(err, ErrUploadChunkIncomplete) {
+		t.Fatalf("WriteChunk error = %v, want ErrUploadChunkIncomplete", err)
+	}
+
+	upload, err := uploads.GetUpload(ctx, 1, "upload")
+	if err != nil {
+		t.Fatalf("GetUpload: %v", err)
+	}
+	if upload.Offset != int64(received) {
+		t.Fatalf("offset %d after the interruption, want the %d bytes received", upload.Offset, received)
+	}
+	if got := uploads.storedChunks(t); !bytes.Equal(got, content[:received]) {
+		t.Fatal("stored chunks differ from the content received")
+	}
+
+	// The client resumes from the offset it gets back
+	upload, err = uploads.WriteChunk(ctx, 1, "upload", upload.Offset, bytes.NewReader(content[received:]), int64(len(content)-received))
+	if err != nil {
+		t.Fatalf("WriteChunk after resuming: %v", err)
+	}
+	if got := uploads.storedChunks(t); !bytes.Equal(got, content) {
+		t.Error("stored chunks differ from the content sent")
+	}
+
+	// The checksum state covers every byte exactly once
+	contentHash, err := restoreHash(upload.HashState)
+	if err != nil {
+		t.Fatalf("restoreHash: %v", err)
+	}
+	if want := sha256.Sum256(content); !bytes.Equal(contentHash.Sum(nil), want[:]) {
+		t.Error("checksum state does not match the content")
+	}
+}
+
+func TestWriteChunkRejectsWrongOffset(t *testing.T) {
+	uploads := newTestUploadService(t, 100)
+
+	if _, err := uploads.WriteChunk(context.Background(), 1, "upload", 10, bytes.NewReader(make([]byte, 10)), 10); !errors.Is(err, ErrUploadOffsetMismatch) {
+		t.Errorf("WriteChunk error = %v, want ErrUploadOffsetMismatch", err)
+	}
+	if len(uploads.uploadRepo.chunks["upload"]) != 0 {
+		t.Error("chunk stored at a wrong offset")
+	}
+}
+
+func TestWriteChunkCompletesUploadOnce(t *testing.T) {
+	content := []byte("the last chunk completes the upload")
+	uploads := newTestUploadService(t, int64(len(content)))
+
+	upload, err := uploads.WriteChunk(context.Background(), 1, "upload", 0, bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		t.Fatalf("WriteChunk: %v", err)
+	}
+	if len(uploads.fileRepo.files) != 1 {
+		t.Fatalf("created %d files, want 1", len(uploads.fileRepo.files))
+	}
+	file := uploads.fileRepo.files[0]
+	if upload.FileID == nil || *upload.FileID != file.ID {
+		t.Errorf("upload file ID = %v, want %d", upload.FileID, file.ID)
+	}
+	if got := readObject(t, uploads.fileStorage, file.FilePath); !bytes.Equal(got, content) {
+		t.Errorf("assembled %q, want %q", got, content)
+	}
+	if len(uploads.uploadRepo.chunks["upload"]) != 0 {
+		t.Error("chunks kept after completing the upload")
+	}
+	if len(uploads.scan.enqueued) != 1 {
+		t.Errorf("enqueued %d files for scanning, want 1", len(uploads.scan.enqueued))
+	}
+
+	// A retry of the completed upload does not assemble it again
+	if _, err := uploads.WriteChunk(context.Background(), 1, "upload", int64(len(content)), bytes.NewReader(nil), 0); err != nil {
+		t.Fatalf("WriteChunk retry: %v", err)
+	}
+	if len(uploads.fileRepo.files) != 1 {
+		t.Errorf("created %d files after a retry, want 1", len(uploads.fileRepo.files))
+	}
+}
+
+func TestWriteChunkUploadCompletedConcurrently(t *testing.T) {
+	content := []byte("completed by two requests at once")
+	uploads := newTestUploadService(t, int64(len(content)))
+	uploads.fileRepo.completedElsewhere = true
+
+	upload, err := uploads.WriteChunk(context.Background(), 1, "upload", 0, bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		t.Fatalf("WriteChunk: %v", err)
+	}
+	if upload.FileID == nil || *upload.FileID != 99 {
+		t.Errorf("upload file ID = %v, want the file of the other request", upload.FileID)
+	}
+	if len(uploads.fileRepo.files) != 0 {
+		t.Errorf("created %d files, want none", len(uploads.fileRepo.files))
+	}
+	if len(uploads.scan.enqueued) != 0 {
+		t.Errorf("enqueued %d files for scanning, want none", len(uploads.scan.enqueued))
+	}
+	// The reference taken on the content belongs to the file that was not created
+	if blob := uploads.blobRepo.blobs[sha256Hex(content)]; blob.RefCount != 0 {
+		t.Errorf("content has %d references, want 0", blob.RefCount)
+	}
+}
+
+func TestCreateUploadFolder(t *testing.T) {
+	tests := []struct {
+		name     string
+		folderID *int
+		wantErr  error
+	}{
+		{"top level", nil, nil},
+		{"own folder", intPtr(1), nil},
+		{"folder of another user", intPtr(2), ErrFolderNotFound},
+		{"missing folder", intPtr(99), ErrFolderNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uploads := newTestUploadService(t, 0)
+
+			upload, err := uploads.CreateUpload(context.Background(), 1, dto.CreateUploadRequest{Length: 10, FileName: "notes.txt", FileType: "text/plain", FolderID: tt.folderID})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CreateUpload error %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				// Only the upload of the fixture exists
+				if len(uploads.uploadRepo.uploads) != 1 {
+					t.Errorf("%d uploads, want the rejected one not created", len(uploads.uploadRepo.uploads))
+				}
+				return
+			}
+			if stored := uploads.uploadRepo.uploads[upload.ID]; !equalIntPtr(stored.FolderID, tt.folderID) {
+				t.Errorf("upload folder = %v, want %v", stored.FolderID, tt.folderID)
+			}
+		})
+	}
+}
+
+func TestWriteChunkCreatesFileInFolder(t *testing.T) {
+	content := []byte("notes for the folder")
+	uploads := newTestUploadService(t, int64(len(content)))
+	uploads.uploadRepo.uploads["upload"].FolderID = intPtr(1)
+
+	if _, err := uploads.WriteChunk(context.Background(), 1, "upload", 0, bytes.NewReader(content), int64(len(content))); err != nil {
+		t.Fatalf("WriteChunk: %v", err)
+	}
+	if len(uploads.fileRepo.files) != 1 {
+		t.Fatalf("created %d files, want 1", len(uploads.fileRepo.files))
+	}
+	if folderID := uploads.fileRepo.files[0].FolderID; folderID == nil || *folderID != 1 {
+		t.Errorf("file folder = %v, want 1", folderID)
+	}
+}
+
+func equalIntPtr(a, b *int) bool {
+	return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+}
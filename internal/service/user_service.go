@@ -1,34 +1,85 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"go-template/internal/config"
 	"go-template/internal/dto"
 	"go-template/internal/entity"
 	"go-template/internal/logger"
 	"go-template/internal/repository"
+	"go-template/pkg/apperror"
+	"go-template/pkg/i18n"
+	"go-template/pkg/imageutil"
 	"go-template/pkg/pagination"
+	"go-template/pkg/storage"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
 	"go.uber.org/zap"
 )
 
+// maxMetadataSize limits the encoded size of the free-form user metadata
+const maxMetadataSize = 16 * 1024
+
+// avatarSizes are the square variants generated for every uploaded avatar
+var avatarSizes = map[string]int{
+	"small":  64,
+	"medium": 128,
+	"large":  256,
+}
+
+// avatarCategory is the category avatar originals are uploaded with
+const avatarCategory = "avatar"
+
+var avatarMimeTypes = []string{"image/jpeg", "image/png", "image/gif"}
+
+var (
+	ErrInvalidMetadata   = apperror.New(apperror.KindInvalid, apperror.CodeInvalidRequest, "metadata must be a JSON object within the size limit").WithDetails(map[string]int{"max_bytes": maxMetadataSize})
+	ErrInvalidAvatarType = apperror.New(apperror.KindUnsupportedMediaType, apperror.CodeAvatarTypeNotAllowed, "avatar must be a JPEG, PNG or GIF image")
+	ErrInvalidAvatar     = apperror.New(apperror.KindInvalid, apperror.CodeAvatarInvalid, "avatar is not a valid image")
+	ErrAvatarTooLarge    = apperror.New(apperror.KindTooLarge, apperror.CodeAvatarTooLarge, "avatar image dimensions are too large")
+)
+
 type UserService interface {
 	CreateUser(ctx context.Context, req dto.CreateUserRequest) (*dto.UserResponse, error)
 	GetUserByID(ctx context.Context, id int) (*dto.UserResponse, error)
+	GetUserSummaries(ctx context.Context, ids []int) (map[int]dto.UserSummaryResponse, error)
 	UpdateUser(ctx context.Context, id int, req dto.UpdateUserRequest) (*dto.UserResponse, error)
+	UpdateAvatar(ctx context.Context, id int, file *multipart.FileHeader) (*dto.UserResponse, error)
 	DeleteUser(ctx context.Context, id int) error
 	GetAllUsers(ctx context.Context) ([]dto.UserResponse, error)
 	GetAllUsersWithPagination(ctx context.Context, paginationParams pagination.PaginationParams, filterParams pagination.FilterParams) ([]dto.UserResponse, pagination.PaginationMeta, error)
+	GetLoginHistory(ctx context.Context, id int, paginationParams pagination.PaginationParams) ([]dto.LoginEventResponse, pagination.PaginationMeta, error)
+	GetInactiveUsers(ctx context.Context, days int, paginationParams pagination.PaginationParams) ([]dto.InactiveUserResponse, pagination.PaginationMeta, error)
 }
 
 type userService struct {
-	userRepo repository.UserRepository
+	userRepo       repository.UserRepository
+	loginEventRepo repository.LoginEventRepository
+	fileService    FileService
+	accountService AccountService
+	fileStorage    storage.FileStorage
+	config         *config.Config
 }
 
-func NewUserService(userRepo repository.UserRepository) UserService {
+func NewUserService(userRepo repository.UserRepository, loginEventRepo repository.LoginEventRepository, fileService FileService, accountService AccountService, fileStorage storage.FileStorage, config *config.Config) UserService {
 	return &userService{
-		userRepo: userRepo,
+		userRepo:       userRepo,
+		loginEventRepo: loginEventRepo,
+		fileService:    fileService,
+		accountService: accountService,
+		fileStorage:    fileStorage,
+		config:         config,
 	}
 }
 
@@ -39,7 +90,7 @@ func (s *userService) CreateUser(ctx context.Context, req dto.CreateUserRequest)
 	existingUser, err := s.userRepo.GetByEmail(ctx, req.Email)
 	if err == nil && existingUser != nil {
 		logger.Warn("User already exists", zap.String("email", req.Email))
-		return nil, errors.New("user with this email already exists")
+		return nil, ErrUserAlreadyExists
 	}
 	
 	// Create new user
@@ -51,7 +102,7 @@ func (s *userService) CreateUser(ctx context.Context, req dto.CreateUserRequest)
 	
 	logger.Info("User created successfully", zap.Int("user_id", user.ID))
 	
-	return s.mapUserToResponse(user), nil
+	return s.mapUserToResponse(ctx, user), nil
 }
 
 func (s *userService) GetUserByID(ctx context.Context, id int) (*dto.UserResponse, error) {
@@ -61,31 +112,79 @@ func (s *userService) GetUserByID(ctx context.Context, id int) (*dto.UserRespons
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			logger.Warn("User not found", zap.Int("user_id", id))
-			return nil, errors.New("user not found")
+			return nil, ErrUserNotFound
 		}
 		logger.Error("Failed to get user", zap.Error(err))
 		return nil, err
 	}
 	
-	return s.mapUserToResponse(user), nil
+	return s.mapUserToResponse(ctx, user), nil
+}
+
+// GetUserSummaries loads the public view of several users at once, keyed by user ID
+func (s *userService) GetUserSummaries(ctx context.Context, ids []int) (map[int]dto.UserSummaryResponse, error) {
+	logger.Debug("Getting user summaries", zap.Int("count", len(ids)))
+
+	users, err := s.userRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		logger.Error("Failed to get users by IDs", zap.Error(err))
+		return nil, err
+	}
+
+	summaries := make(map[int]dto.UserSummaryResponse, len(users))
+	for _, user := range users {
+		userResponse := s.mapUserToResponse(ctx, &user)
+		summaries[user.ID] = dto.UserSummaryResponse{
+			ID:          userResponse.ID,
+			Name:        userResponse.Name,
+			DisplayName: userResponse.DisplayName,
+			AvatarURL:   userResponse.AvatarURL,
+		}
+	}
+
+	return summaries, nil
 }
 
 func (s *userService) UpdateUser(ctx context.Context, id int, req dto.UpdateUserRequest) (*dto.UserResponse, error) {
 	logger.Info("Updating user", zap.Int("user_id", id))
 	
 	// Check if user exists
-	_, err := s.userRepo.GetByID(ctx, id)
+	existingUser, err := s.userRepo.GetByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			logger.Warn("User not found for update", zap.Int("user_id", id))
-			return nil, errors.New("user not found")
+			return nil, ErrUserNotFound
 		}
 		logger.Error("Failed to get user for update", zap.Error(err))
 		return nil, err
 	}
 	
+	if req.Metadata != nil {
+		encoded, err := json.Marshal(req.Metadata)
+		if err != nil || len(encoded) > maxMetadataSize {
+			logger.Warn("Metadata rejected", zap.Int("user_id", id))
+			return nil, ErrInvalidMetadata
+		}
+	}
+	
+	// Only fields present in the request are changed; an empty string clears a profile field
+	name := existingUser.Name
+	if req.Name != "" {
+		name = req.Name
+	}
+	metadata := existingUser.Metadata
+	if req.Metadata != nil {
+		metadata = req.Metadata
+	}
+	
 	// Update user
-	user, err := s.userRepo.Update(ctx, id, req.Name)
+	user, err := s.userRepo.UpdateProfile(ctx, id, name,
+		mergeProfileField(existingUser.DisplayName, req.DisplayName),
+		mergeProfileField(existingUser.Bio, req.Bio),
+		mergeProfileField(existingUser.Locale, req.Locale),
+		mergeProfileField(existingUser.Timezone, req.Timezone),
+		mergeProfileField(existingUser.Phone, req.Phone),
+		metadata)
 	if err != nil {
 		logger.Error("Failed to update user", zap.Error(err))
 		return nil, err
@@ -93,25 +192,87 @@ func (s *userService) UpdateUser(ctx context.Context, id int, req dto.UpdateUser
 	
 	logger.Info("User updated successfully", zap.Int("user_id", id))
 	
-	return s.mapUserToResponse(user), nil
+	return s.mapUserToResponse(ctx, user), nil
 }
 
-func (s *userService) DeleteUser(ctx context.Context, id int) error {
-	logger.Info("Deleting user", zap.Int("user_id", id))
+func (s *userService) UpdateAvatar(ctx context.Context, id int, file *multipart.FileHeader) (*dto.UserResponse, error) {
+	logger.Info("Updating user avatar", zap.Int("user_id", id))
 	
-	// Check if user exists
-	_, err := s.userRepo.GetByID(ctx, id)
+	existingUser, err := s.userRepo.GetByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			logger.Warn("User not found for deletion", zap.Int("user_id", id))
-			return errors.New("user not found")
+			logger.Warn("User not found for avatar update", zap.Int("user_id", id))
+			return nil, ErrUserNotFound
 		}
-		logger.Error("Failed to get user for deletion", zap.Error(err))
-		return err
+		logger.Error("Failed to get user for avatar update", zap.Error(err))
+		return nil, err
 	}
 	
-	if err := s.userRepo.Delete(ctx, id); err != nil {
-		logger.Error("Failed to delete user", zap.Error(err))
+	// Only images are accepted as avatars
+	if !slices.Contains(avatarMimeTypes, file.Header.Get("Content-Type")) {
+		logger.Warn("Invalid avatar type", zap.String("mime_type", file.Header.Get("Content-Type")))
+		return nil, ErrInvalidAvatarType
+	}
+	
+	src, err := file.Open()
+	if err != nil {
+		logger.Error("Failed to open avatar", zap.Error(err))
+		return nil, err
+	}
+	img, _, err := imageutil.Decode(src)
+	src.Close()
+	if err != nil {
+		logger.Warn("Failed to decode avatar", zap.Error(err))
+		if errors.Is(err, imageutil.ErrImageTooLarge) {
+			return nil, ErrAvatarTooLarge
+		}
+		return nil, ErrInvalidAvatar.Wrap(err)
+	}
+	
+	// Store the original through the regular upload pipeline
+	uploaded, err := s.fileService.UploadFile(ctx, file, dto.UploadFileRequest{
+		Description: "Profile picture",
+		Category:    avatarCategory,
+	}, id)
+	if err != nil {
+		return nil, err
+	}
+	
+	// Deleting the file also deletes the sizes stored so far
+	if err := s.saveAvatarVariants(ctx, img, uploaded.FileName); err != nil {
+		logger.Error("Failed to generate avatar variants", zap.Error(err))
+		s.fileService.DeleteFile(ctx, uploaded.ID)
+		return nil, err
+	}
+	
+	user, err := s.userRepo.UpdateAvatar(ctx, id, &uploaded.ID, &uploaded.FileName)
+	if err != nil {
+		logger.Error("Failed to update user avatar", zap.Error(err))
+		s.fileService.DeleteFile(ctx, uploaded.ID)
+		return nil, err
+	}
+	
+	// Remove the previous avatar once the new one is in place; avatars stored without a file only
+	// have their sizes to delete
+	if existingUser.AvatarFileID != nil {
+		if err := s.fileService.DeleteFile(ctx, *existingUser.AvatarFileID); err != nil {
+			logger.Warn("Failed to delete previous avatar", zap.Error(err), zap.Int("file_id", *existingUser.AvatarFileID))
+		}
+	} else if existingUser.AvatarFileName != nil {
+		deleteAvatarVariants(ctx, s.fileStorage, *existingUser.AvatarFileName)
+	}
+	
+	logger.Info("User avatar updated successfully", zap.Int("user_id", id), zap.Int("file_id", uploaded.ID))
+	
+	return s.mapUserToResponse(ctx, user), nil
+}
+
+func (s *userService) DeleteUser(ctx context.Context, id int) error {
+	logger.Info("Deleting user", zap.Int("user_id", id))
+	
+	// The account is purged like a scheduled deletion, so the content of its files is released and
+	// their variants, upload chunks and exports are deleted from storage
+	if err := s.accountService.PurgeAccount(ctx, id); err != nil {
 		return err
 	}
 	
@@ -131,7 +292,7 @@ func (s *userService) GetAllUsers(ctx context.Context) ([]dto.UserResponse, erro
 	
 	var userResponses []dto.UserResponse
 	for _, user := range users {
-		userResponses = append(userResponses, *s.mapUserToResponse(&user))
+		userResponses = append(userResponses, *s.mapUserToResponse(ctx, &user))
 	}
 	
 	return userResponses, nil
@@ -145,7 +306,17 @@ func (s *userService) GetAllUsersWithPagination(ctx context.Context, paginationP
 		zap.String("order", paginationParams.Order),
 		zap.String("search", paginationParams.Search))
 	
-	users, totalCount, err := s.userRepo.GetAllWithPagination(ctx, paginationParams, filterParams)
+	var users []entity.User
+	var paginationMeta pagination.PaginationMeta
+	var err error
+	// Keyset pagination skips the COUNT(*) query, so its metadata carries cursors instead of totals
+	if paginationParams.CursorMode {
+		users, paginationMeta, err = s.userRepo.GetAllWithCursor(ctx, paginationParams, filterParams)
+	} else {
+		var totalCount int
+		users, totalCount, err = s.userRepo.GetAllWithPagination(ctx, paginationParams, filterParams)
+		paginationMeta = pagination.NewPaginationMeta(paginationParams.Page, paginationParams.Limit, totalCount)
+	}
 	if err != nil {
 		logger.Error("Failed to get users with pagination", zap.Error(err))
 		return nil, pagination.PaginationMeta{}, err
@@ -153,22 +324,159 @@ func (s *userService) GetAllUsersWithPagination(ctx context.Context, paginationP
 	
 	var userResponses []dto.UserResponse
 	for _, user := range users {
-		userResponses = append(userResponses, *s.mapUserToResponse(&user))
+		userResponses = append(userResponses, *s.mapUserToResponse(ctx, &user))
 	}
 	
+	return userResponses, paginationMeta, nil
+}
+
+func (s *userService) GetLoginHistory(ctx context.Context, id int, paginationParams pagination.PaginationParams) ([]dto.LoginEventResponse, pagination.PaginationMeta, error) {
+	logger.Debug("Getting login history", zap.Int("user_id", id), zap.Int("page", paginationParams.Page))
+
+	if _, err := s.userRepo.GetByID(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pagination.PaginationMeta{}, ErrUserNotFound
+		}
+		logger.Error("Failed to get user", zap.Error(err), zap.Int("user_id", id))
+		return nil, pagination.PaginationMeta{}, err
+	}
+
+	events, totalCount, err := s.loginEventRepo.GetByUserID(ctx, id, paginationParams)
+	if err != nil {
+		logger.Error("Failed to get login history", zap.Error(err), zap.Int("user_id", id))
+		return nil, pagination.PaginationMeta{}, err
+	}
+
+	eventResponses := make([]dto.LoginEventResponse, len(events))
+	for i, event := range events {
+		eventResponses[i] = dto.LoginEventResponse{
+			ID:            event.ID,
+			Success:       event.Success,
+			Method:        event.Method,
+			FailureReason: event.FailureReason,
+			IPAddress:     event.IPAddress,
+			UserAgent:     event.UserAgent,
+			CreatedAt:     event.CreatedAt,
+		}
+	}
+
 	paginationMeta := pagination.NewPaginationMeta(paginationParams.Page, paginationParams.Limit, totalCount)
-	
+
+	return eventResponses, paginationMeta, nil
+}
+
+// GetInactiveUsers lists accounts whose last login (or creation, if they never logged in) is older than the given number of days
+func (s *userService) GetInactiveUsers(ctx context.Context, days int, paginationParams pagination.PaginationParams) ([]dto.InactiveUserResponse, pagination.PaginationMeta, error) {
+	logger.Debug("Getting inactive users", zap.Int("days", days), zap.Int("page", paginationParams.Page))
+
+	now := time.Now()
+	inactiveSince := now.AddDate(0, 0, -days)
+
+	users, totalCount, err := s.userRepo.GetInactive(ctx, inactiveSince, paginationParams)
+	if err != nil {
+		logger.Error("Failed to get inactive users", zap.Error(err))
+		return nil, pagination.PaginationMeta{}, err
+	}
+
+	userResponses := make([]dto.InactiveUserResponse, len(users))
+	for i, user := range users {
+		lastActivityAt := user.CreatedAt
+		if user.LastLoginAt != nil {
+			lastActivityAt = *user.LastLoginAt
+		}
+		userResponses[i] = dto.InactiveUserResponse{
+			UserResponse:   *s.mapUserToResponse(ctx, &user),
+			LastActivityAt: lastActivityAt,
+			InactiveDays:   int(now.Sub(lastActivityAt).Hours() / 24),
+		}
+	}
+
+	paginationMeta := pagination.NewPaginationMeta(paginationParams.Page, paginationParams.Limit, totalCount)
+
 	return userResponses, paginationMeta, nil
 }
 
-func (s *userService) mapUserToResponse(user *entity.User) *dto.UserResponse {
-	return &dto.UserResponse{
-		ID:            user.ID,
-		Name:          user.Name,
-		Email:         user.Email,
-		Role:          user.Role,
-		EmailVerified: user.EmailVerified,
-		CreatedAt:     user.CreatedAt,
-		UpdatedAt:     user.UpdatedAt,
+func (s *userService) saveAvatarVariants(ctx context.Context, img image.Image, fileName string) error {
+	for _, size := range avatarSizes {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, imageutil.Thumbnail(img, size)); err != nil {
+			return err
+		}
+		if err := s.fileStorage.Put(ctx, avatarVariantName(fileName, size), &buf, int64(buf.Len()), "image/png"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *userService) mapUserToResponse(ctx context.Context, user *entity.User) *dto.UserResponse {
+	return newUserResponse(ctx, user, s.fileStorage, s.config)
+}
+
+// newUserResponse builds the public representation of a user, including avatar URLs when one is set
+func newUserResponse(ctx context.Context, user *entity.User, fileStorage storage.FileStorage, config *config.Config) *dto.UserResponse {
+	userResponse := &dto.UserResponse{
+		ID:                  user.ID,
+		Name:                user.Name,
+		Email:               user.Email,
+		Role:                user.Role,
+		EmailVerified:       user.EmailVerified,
+		DisplayName:         user.DisplayName,
+		Bio:                 user.Bio,
+		Locale:              user.Locale,
+		Timezone:            user.Timezone,
+		Phone:               user.Phone,
+		Metadata:            user.Metadata,
+		LastLoginAt:         user.LastLoginAt,
+		DeletionScheduledAt: user.DeletionScheduledAt,
+		CreatedAt:           user.CreatedAt,
+		UpdatedAt:           user.UpdatedAt,
+	}
+
+	// avatar_file_id is cleared by the database when the avatar file is deleted
+	if user.AvatarFileID != nil && user.AvatarFileName != nil {
+		userResponse.AvatarURLs = make(map[string]string, len(avatarSizes))
+		for name := range avatarSizes {
+			userResponse.AvatarURLs[name] = fileURL(ctx, fileStorage, config, *user.AvatarFileID, avatarCategory, name, config.FileURL.AvatarTTL)
+		}
+		userResponse.AvatarURL = userResponse.AvatarURLs["large"]
+	}
+
+	return userResponse
+}
+
+// userLocale returns the locale to write to a user in: their profile locale when it is supported,
+// otherwise the locale negotiated for the request
+func userLocale(ctx context.Context, user *entity.User) string {
+	if user.Locale != nil {
+		if locale, ok := i18n.Match(*user.Locale); ok {
+			return locale
+		}
+	}
+	return i18n.FromContext(ctx)
+}
+
+// avatarVariantName derives the file name of a resized avatar from the stored original
+func avatarVariantName(fileName string, size int) string {
+	return fmt.Sprintf("%s_%d.png", strings.TrimSuffix(fileName, filepath.Ext(fileName)), size)
+}
+
+// deleteAvatarVariants deletes the resized avatars of the stored original; missing sizes are skipped
+func deleteAvatarVariants(ctx context.Context, fileStorage storage.FileStorage, fileName string) {
+	for _, size := range avatarSizes {
+		if err := fileStorage.Delete(ctx, avatarVariantName(fileName, size)); err != nil {
+			logger.Warn("Failed to delete avatar variant", zap.Error(err), zap.String("file_name", fileName), zap.Int("size", size))
+		}
+	}
+}
+
+// mergeProfileField applies an optional update; an empty string clears the field
+func mergeProfileField(current, update *string) *string {
+	if update == nil {
+		return current
+	}
+	if *update == "" {
+		return nil
 	}
+	return update
 }
\ No newline at end of file
@@ -0,0 +1,312 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+
+	"go-template/internal/dto"
+	"go-template/internal/logger"
+	"go-template/internal/repository"
+	"go-template/pkg/apperror"
+	"go-template/pkg/email"
+	"go-template/pkg/i18n"
+
+	"go.uber.org/zap"
+)
+
+// Setting value types
+const (
+	settingTypeBool   = "bool"
+	settingTypeString = "string"
+	settingTypeInt    = "int"
+)
+
+// Setting keys referenced from code
+const (
+	SettingEmailSecurityAlerts = "notifications.email.security_alerts"
+	SettingEmailAccountUpdates = "notifications.email.account_updates"
+	SettingEmailProductUpdates = "notifications.email.product_updates"
+)
+
+// settingDefinition describes an allowed setting key, its type, default and constraints
+type settingDefinition struct {
+	valueType    string
+	defaultValue interface{}
+	options      []string
+	min, max     *int
+	locked       bool
+	description  string
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+// settingsSchema is the server-side list of keys users are allowed to store
+var settingsSchema = map[string]settingDefinition{
+	SettingEmailSecurityAlerts: {
+		valueType:    settingTypeBool,
+		defaultValue: true,
+		locked:       true,
+		description:  "Security emails such as password resets and account deletion notices. Always on.",
+	},
+	SettingEmailAccountUpdates: {
+		valueType:    settingTypeBool,
+		defaultValue: true,
+		locked:       true,
+		description:  "Emails about actions you requested, such as data exports and invitations. Always on.",
+	},
+	SettingEmailProductUpdates: {
+		valueType:    settingTypeBool,
+		defaultValue: true,
+		description:  "Product news and announcements.",
+	},
+	"ui.theme": {
+		valueType:    settingTypeString,
+		defaultValue: "system",
+		options:      []string{"light", "dark", "system"},
+		description:  "Color theme of the web interface.",
+	},
+	"ui.page_size": {
+		valueType:    settingTypeInt,
+		defaultValue: 10,
+		min:          intPtr(1),
+		max:          intPtr(100),
+		description:  "Default number of items per page in lists.",
+	},
+}
+
+// emailCategorySettings maps email categories to the setting that controls them
+var emailCategorySettings = map[email.Category]string{
+	email.CategorySecurity: SettingEmailSecurityAlerts,
+	email.CategoryAccount:  SettingEmailAccountUpdates,
+	email.CategoryProduct:  SettingEmailProductUpdates,
+}
+
+// ErrInvalidSettings carries the per-key validation failures of a settings update as details
+var ErrInvalidSettings = apperror.New(apperror.KindUnprocessable, apperror.CodeValidationFailed, "validation failed")
+
+type UserSettingService interface {
+	GetSettings(ctx context.Context, userID int) ([]dto.UserSettingResponse, error)
+	UpdateSettings(ctx context.Context, userID int, req dto.UpdateUserSettingsRequest) ([]dto.UserSettingResponse, error)
+	GetBool(ctx context.Context, userID int, key string) (bool, error)
+	AllowsEmail(ctx context.Context, toEmail string, category email.Category) (bool, error)
+}
+
+type userSettingService struct {
+	userSettingRepo repository.UserSettingRepository
+	userRepo        repository.UserRepository
+}
+
+func NewUserSettingService(userSettingRepo repository.UserSettingRepository, userRepo repository.UserRepository) UserSettingService {
+	return &userSettingService{
+		userSettingRepo: userSettingRepo,
+		userRepo:        userRepo,
+	}
+}
+
+func (s *userSettingService) GetSettings(ctx context.Context, userID int) ([]dto.UserSettingResponse, error) {
+	logger.Debug("Getting user settings", zap.Int("user_id", userID))
+
+	stored, err := s.userSettingRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		logger.Error("Failed to get user settings", zap.Error(err))
+		return nil, err
+	}
+
+	values := make(map[string]interface{}, len(stored))
+	for _, setting := range stored {
+		values[setting.Key] = setting.Value
+	}
+
+	return s.buildSettingsResponse(i18n.FromContext(ctx), values), nil
+}
+
+func (s *userSettingService) UpdateSettings(ctx context.Context, userID int, req dto.UpdateUserSettingsRequest) ([]dto.UserSettingResponse, error) {
+	logger.Info("Updating user settings", zap.Int("user_id", userID), zap.Int("keys", len(req)))
+
+	// Validate the whole request before writing anything
+	locale := i18n.FromContext(ctx)
+	normalized := make(map[string]interface{}, len(req))
+	var validationErrors []dto.ValidationError
+	for key, value := range req {
+		normalizedValue, validationError := validateSetting(locale, key, value)
+		if validationError != nil {
+			validationErrors = append(validationErrors, *validationError)
+			continue
+		}
+		normalized[key] = normalizedValue
+	}
+	if len(validationErrors) > 0 {
+		sort.Slice(validationErrors, func(i, j int) bool {
+			return validationErrors[i].Field < validationErrors[j].Field
+		})
+		logger.Warn("Settings validation failed", zap.Int("user_id", userID), zap.Any("errors", validationErrors))
+		return nil, ErrInvalidSettings.WithDetails(validationErrors)
+	}
+
+	for key, value := range normalized {
+		// Storing the default is pointless; removing the row lets future default changes apply
+		if value == nil {
+			if err := s.userSettingRepo.Delete(ctx, userID, key); err != nil {
+				logger.Error("Failed to reset user setting", zap.Error(err), zap.String("key", key))
+				return nil, err
+			}
+			continue
+		}
+		if _, err := s.userSettingRepo.Upsert(ctx, userID, key, value); err != nil {
+			logger.Error("Failed to save user setting", zap.Error(err), zap.String("key", key))
+			return nil, err
+		}
+	}
+
+	logger.Info("User settings updated successfully", zap.Int("user_id", userID))
+
+	return s.GetSettings(ctx, userID)
+}
+
+// GetBool returns a boolean setting, falling back to its default when it was never set
+func (s *userSettingService) GetBool(ctx context.Context, userID int, key string) (bool, error) {
+	definition, ok := settingsSchema[key]
+	if !ok || definition.valueType != settingTypeBool {
+		return false, fmt.Errorf("unknown boolean setting %q", key)
+	}
+	if definition.locked {
+		return definition.defaultValue.(bool), nil
+	}
+
+	setting, err := s.userSettingRepo.Get(ctx, userID, key)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return definition.defaultValue.(bool), nil
+		}
+		return false, err
+	}
+
+	value, ok := setting.Value.(bool)
+	if !ok {
+		return definition.defaultValue.(bool), nil
+	}
+	return value, nil
+}
+
+// AllowsEmail implements email.PreferenceChecker
+func (s *userSettingService) AllowsEmail(ctx context.Context, toEmail string, category email.Category) (bool, error) {
+	key, ok := emailCategorySettings[category]
+	if !ok {
+		return true, nil
+	}
+
+	user, err := s.userRepo.GetByEmail(ctx, toEmail)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// Not a registered user, so there are no preferences to honour
+			return true, nil
+		}
+		return false, err
+	}
+
+	allowed, err := s.GetBool(ctx, user.ID, key)
+	if err != nil {
+		return false, err
+	}
+	if !allowed {
+		logger.Info("Email suppressed by user preference", zap.Int("user_id", user.ID), zap.String("category", string(category)))
+	}
+
+	return allowed, nil
+}
+
+func (s *userSettingService) buildSettingsResponse(locale string, values map[string]interface{}) []dto.UserSettingResponse {
+	keys := make([]string, 0, len(settingsSchema))
+	for key := range settingsSchema {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	settings := make([]dto.UserSettingResponse, 0, len(keys))
+	for _, key := range keys {
+		definition := settingsSchema[key]
+
+		value := definition.defaultValue
+		if stored, ok := values[key]; ok && !definition.locked {
+			// Ignore stored values that no longer match the schema
+			if normalizedValue, validationError := validateSetting(locale, key, stored); validationError == nil && normalizedValue != nil {
+				value = normalizedValue
+			}
+		}
+
+		settings = append(settings, dto.UserSettingResponse{
+			Key:          key,
+			Value:        value,
+			DefaultValue: definition.defaultValue,
+			Type:         definition.valueType,
+			Options:      definition.options,
+			Min:          definition.min,
+			Max:          definition.max,
+			Locked:       definition.locked,
+			Description:  i18n.T(locale, definition.description),
+		})
+	}
+
+	return settings
+}
+
+// validateSetting checks a value against the schema and returns it in its canonical Go type; error
+// messages are rendered in locale
+func validateSetting(locale, key string, value interface{}) (interface{}, *dto.ValidationError) {
+	definition, ok := settingsSchema[key]
+	if !ok {
+		return nil, &dto.ValidationError{Field: key, Tag: "unknown", Message: i18n.T(locale, "%s is not a known setting", key)}
+	}
+	if definition.locked {
+		return nil, &dto.ValidationError{Field: key, Tag: "locked", Message: i18n.T(locale, "%s cannot be changed", key)}
+	}
+	if value == nil {
+		return nil, nil
+	}
+
+	switch definition.valueType {
+	case settingTypeBool:
+		if b, ok := value.(bool); ok {
+			return b, nil
+		}
+		return nil, &dto.ValidationError{Field: key, Tag: "type", Value: settingTypeBool, Message: i18n.T(locale, "%s must be a boolean", key)}
+	case settingTypeString:
+		str, ok := value.(string)
+		if !ok {
+			return nil, &dto.ValidationError{Field: key, Tag: "type", Value: settingTypeString, Message: i18n.T(locale, "%s must be a string", key)}
+		}
+		if len(definition.options) > 0 && !slices.Contains(definition.options, str) {
+			return nil, &dto.ValidationError{Field: key, Tag: "oneof", Message: i18n.T(locale, "%s must be one of: %v", key, definition.options)}
+		}
+		return str, nil
+	case settingTypeInt:
+		var n float64
+		switch v := value.(type) {
+		case float64:
+			n = v
+		case int:
+			n = float64(v)
+		default:
+			return nil, &dto.ValidationError{Field: key, Tag: "type", Value: settingTypeInt, Message: i18n.T(locale, "%s must be an integer", key)}
+		}
+		if n != math.Trunc(n) {
+			return nil, &dto.ValidationError{Field: key, Tag: "type", Value: settingTypeInt, Message: i18n.T(locale, "%s must be an integer", key)}
+		}
+		if definition.min != nil && int(n) < *definition.min {
+			return nil, &dto.ValidationError{Field: key, Tag: "min", Value: fmt.Sprint(*definition.min), Message: i18n.T(locale, "%s must be at least %d", key, *definition.min)}
+		}
+		if definition.max != nil && int(n) > *definition.max {
+			return nil, &dto.ValidationError{Field: key, Tag: "max", Value: fmt.Sprint(*definition.max), Message: i18n.T(locale, "%s must be at most %d", key, *definition.max)}
+		}
+		return int(n), nil
+	}
+
+	return nil, &dto.ValidationError{Field: key, Tag: "type", Message: i18n.T(locale, "%s has an unsupported type", key)}
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"go-template/internal/entity"
+	"go-template/internal/repository"
+	"go-template/pkg/email"
+)
+
+// fakeUserSettingRepository keeps settings in memory, keyed by user and setting key
+type fakeUserSettingRepository struct {
+	repository.UserSettingRepository
+	settings map[int]map[string]interface{}
+}
+
+func (r *fakeUserSettingRepository) Get(ctx context.Context, userID int, key string) (*entity.UserSetting, error) {
+	value, ok := r.settings[userID][key]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &entity.UserSetting{UserID: userID, Key: key, Value: value}, nil
+}
+
+// fakeUserRepository looks users up by email; other methods are not implemented
+type fakeUserRepository struct {
+	repository.UserRepository
+	users map[string]*entity.User
+}
+
+func (r *fakeUserRepository) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
+	user, ok := r.users[email]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return user, nil
+}
+
+func TestAllowsEmail(t *testing.T) {
+	// The opted-out user turned everything off, including rows for locked settings written before
+	// they were locked
+	settings := &fakeUserSettingRepository{settings: map[int]map[string]interface{}{
+		1: {
+			SettingEmailSecurityAlerts: false,
+			SettingEmailAccountUpdates: false,
+			SettingEmailProductUpdates: false,
+		},
+	}}
+	users := &fakeUserRepository{users: map[string]*entity.User{
+		"out@example.com": {ID: 1, Email: "out@example.com"},
+		"new@example.com": {ID: 2, Email: "new@example.com"},
+	}}
+	s := NewUserSettingService(settings, users)
+
+	tests := []struct {
+		email    string
+		category email.Category
+		want     bool
+	}{
+		{"out@example.com", email.CategorySecurity, true},
+		{"out@example.com", email.CategoryAccount, true},
+		{"out@example.com", email.CategoryProduct, false},
+		{"new@example.com", email.CategorySecurity, true},
+		{"new@example.com", email.CategoryAccount, true},
+		{"new@example.com", email.CategoryProduct, true},
+		{"unknown@example.com", email.CategoryProduct, true},
+	}
+	for _, tt := range tests {
+		got, err := s.AllowsEmail(context.Background(), tt.email, tt.category)
+		if err != nil {
+			t.Fatalf("AllowsEmail(%s, %s): %v", tt.email, tt.category, err)
+		}
+		if got != tt.want {
+			t.Errorf("AllowsEmail(%s, %s) = %v, want %v", tt.email, tt.category, got, tt.want)
+		}
+	}
+}
+
+func TestLockedEmailSettingsCannotBeTurnedOff(t *testing.T) {
+	s := NewUserSettingService(&fakeUserSettingRepository{}, &fakeUserRepository{})
+
+	for _, key := range []string{SettingEmailSecurityAlerts, SettingEmailAccountUpdates} {
+		if _, err := s.UpdateSettings(context.Background(), 1, map[string]interface{}{key: false}); err == nil {
+			t.Errorf("UpdateSettings accepted turning off %s", key)
+		}
+	}
+}
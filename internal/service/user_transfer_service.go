@@ -0,0 +1,400 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-template/internal/config"
+	"go-template/internal/dto"
+	"go-template/internal/entity"
+	"go-template/internal/logger"
+	"go-template/internal/repository"
+	"go-template/pkg/apperror"
+	"go-template/pkg/email"
+	"go-template/pkg/i18n"
+	"go-template/pkg/pagination"
+	"go-template/pkg/storage"
+	"go-template/pkg/tokens"
+	"go-template/pkg/validator"
+
+	"go.uber.org/zap"
+)
+
+const (
+	maxImportRows   = 5000
+	exportBatchSize = 500
+)
+
+var (
+	ErrInvalidImportFile     = apperror.New(apperror.KindInvalid, apperror.CodeInvalidImportFile, "invalid import file")
+	ErrTooManyImportRows     = apperror.New(apperror.KindInvalid, apperror.CodeTooManyImportRows, "import file exceeds the maximum number of rows").WithDetails(map[string]int{"max_rows": maxImportRows})
+	ErrUnsupportedUserFormat = apperror.New(apperror.KindInvalid, apperror.CodeUnsupportedFormat, "unsupported format, expected csv or ndjson")
+)
+
+var userExportColumns = []string{"id", "name", "email", "role", "email_verified", "created_at", "updated_at"}
+
+type UserTransferService interface {
+	ImportUsers(ctx context.Context, r io.Reader, opts dto.UserImportOptions) (*dto.UserImportResponse, error)
+	ExportUsers(ctx context.Context, w io.Writer, format string, paginationParams pagination.PaginationParams, filterParams pagination.FilterParams) error
+}
+
+type userTransferService struct {
+	userRepo     repository.UserRepository
+	emailService email.Service
+	fileStorage  storage.FileStorage
+	validator    *validator.Validator
+	config       *config.Config
+}
+
+// importRow is a parsed record of an import file along with its line number
+type importRow struct {
+	line     int
+	data     dto.ImportUserRow
+	parseErr *dto.ValidationError
+}
+
+func NewUserTransferService(userRepo repository.UserRepository, emailService email.Service, fileStorage storage.FileStorage, validator *validator.Validator, config *config.Config) UserTransferService {
+	return &userTransferService{
+		userRepo:     userRepo,
+		emailService: emailService,
+		fileStorage:  fileStorage,
+		validator:    validator,
+		config:       config,
+	}
+}
+
+func (s *userTransferService) ImportUsers(ctx context.Context, r io.Reader, opts dto.UserImportOptions) (*dto.UserImportResponse, error) {
+	logger.Info("Importing users",
+		zap.String("format", opts.Format),
+		zap.Bool("dry_run", opts.DryRun),
+		zap.Bool("send_invites", opts.SendInvites))
+
+	var rows []importRow
+	var err error
+	switch opts.Format {
+	case dto.UserTransferFormatCSV:
+		rows, err = parseCSVImport(r)
+	case dto.UserTransferFormatNDJSON:
+		rows, err = parseNDJSONImport(r)
+	default:
+		return nil, ErrUnsupportedUserFormat
+	}
+	if err != nil {
+		logger.Warn("Failed to parse import file", zap.Error(err))
+		return nil, err
+	}
+
+	result := &dto.UserImportResponse{
+		DryRun:    opts.DryRun,
+		TotalRows: len(rows),
+		Errors:    []dto.UserImportRowError{},
+	}
+
+	// Validate every row first so a dry run reports exactly what a real run would reject
+	seenEmails := make(map[string]int)
+	var validRows []importRow
+	for _, row := range rows {
+		if rowErrors := s.validateImportRow(ctx, row, seenEmails); len(rowErrors) > 0 {
+			result.Errors = append(result.Errors, dto.UserImportRowError{
+				Line:   row.line,
+				Email:  row.data.Email,
+				Errors: rowErrors,
+			})
+			continue
+		}
+		validRows = append(validRows, row)
+	}
+	result.ValidRows = len(validRows)
+
+	if !opts.DryRun {
+		for _, row := range validRows {
+			user, err := s.createImportedUser(ctx, row.data)
+			if err != nil {
+				logger.Error("Failed to create imported user", zap.Error(err), zap.Int("line", row.line))
+				result.Errors = append(result.Errors, dto.UserImportRowError{
+					Line:  row.line,
+					Email: row.data.Email,
+					Errors: []dto.ValidationError{{
+						Tag:     "create",
+						Message: i18n.T(i18n.FromContext(ctx), "failed to create user"),
+					}},
+				})
+				continue
+			}
+			result.Created++
+
+			if opts.SendInvites {
+				if err := s.sendInvite(ctx, user); err != nil {
+					// Don't fail the import if an invite fails - the user can still use forgot-password
+					logger.Error("Failed to send invite email", zap.Error(err), zap.Int("user_id", user.ID))
+					continue
+				}
+				result.InvitesSent++
+			}
+		}
+	}
+	result.Failed = len(result.Errors)
+
+	logger.Info("User import finished",
+		zap.Bool("dry_run", opts.DryRun),
+		zap.Int("total_rows", result.TotalRows),
+		zap.Int("created", result.Created),
+		zap.Int("failed", result.Failed))
+
+	return result, nil
+}
+
+func (s *userTransferService) ExportUsers(ctx context.Context, w io.Writer, format string, paginationParams pagination.PaginationParams, filterParams pagination.FilterParams) error {
+	logger.Info("Exporting users", zap.String("format", format))
+
+	var writeBatch func(users []entity.User) error
+	switch format {
+	case dto.UserTransferFormatCSV:
+		csvWriter := csv.NewWriter(w)
+		if err := csvWriter.Write(userExportColumns); err != nil {
+			return err
+		}
+		writeBatch = func(users []entity.User) error {
+			for _, user := range users {
+				if err := csvWriter.Write(userToCSVRecord(&user)); err != nil {
+					return err
+				}
+			}
+			csvWriter.Flush()
+			return csvWriter.Error()
+		}
+	case dto.UserTransferFormatNDJSON:
+		encoder := json.NewEncoder(w)
+		writeBatch = func(users []entity.User) error {
+			for _, user := range users {
+				if err := encoder.Encode(newUserResponse(ctx, &user, s.fileStorage, s.config)); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	default:
+		return ErrUnsupportedUserFormat
+	}
+
+	// Walk the filtered list in ID order, one keyset page at a time, so the export never holds every user in
+	// memory and users created or deleted meanwhile do not shift the later pages
+	paginationParams.Limit = exportBatchSize
+	paginationParams.Sort = "id"
+	paginationParams.Order = "ASC"
+	paginationParams.Cursor = ""
+	exported := 0
+	for {
+		users, meta, err := s.userRepo.GetAllWithCursor(ctx, paginationParams, filterParams)
+		if err != nil {
+			logger.Error("Failed to get users for export", zap.Error(err), zap.Int("exported", exported))
+			return err
+		}
+
+		if err := writeBatch(users); err != nil {
+			logger.Error("Failed to write user export", zap.Error(err))
+			return err
+		}
+		if flusher, ok := w.(http.Flusher); ok {
+			flusher.Flush()
+		}
+
+		exported += len(users)
+		if meta.NextCursor == "" {
+			break
+		}
+		paginationParams.Cursor = meta.NextCursor
+	}
+
+	logger.Info("User export finished", zap.String("format", format), zap.Int("exported", exported))
+
+	return nil
+}
+
+func (s *userTransferService) validateImportRow(ctx context.Context, row importRow, seenEmails map[string]int) []dto.ValidationError {
+	locale := i18n.FromContext(ctx)
+	if row.parseErr != nil {
+		parseErr := *row.parseErr
+		parseErr.Message = i18n.T(locale, parseErr.Message)
+		return []dto.ValidationError{parseErr}
+	}
+
+	if validationErrors := s.validator.ValidateStruct(ctx, row.data); validationErrors != nil {
+		return validationErrors
+	}
+
+	if firstLine, ok := seenEmails[row.data.Email]; ok {
+		return []dto.ValidationError{{
+			Field:   "Email",
+			Tag:     "unique",
+			Message: i18n.T(locale, "Email is a duplicate of line %d", firstLine),
+		}}
+	}
+	seenEmails[row.data.Email] = row.line
+
+	if existingUser, err := s.userRepo.GetByEmail(ctx, row.data.Email); err == nil && existingUser != nil {
+		return []dto.ValidationError{{
+			Field:   "Email",
+			Tag:     "unique",
+			Message: i18n.T(locale, "user with this email already exists"),
+		}}
+	}
+
+	return nil
+}
+
+// createImportedUser creates an account without a usable password; the user sets one through the invite link,
+// which verifies the email address too
+func (s *userTransferService) createImportedUser(ctx context.Context, row dto.ImportUserRow) (*entity.User, error) {
+	role := row.Role
+	if role == "" {
+		role = "user"
+	}
+
+	verificationToken, err := tokens.GenerateVerificationToken()
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := time.Now().Add(s.config.Account.InviteTTL)
+
+	return s.userRepo.CreateWithPasswordAndRole(ctx, row.Name, row.Email, "", role, verificationToken, &expiresAt)
+}
+
+func (s *userTransferService) sendInvite(ctx context.Context, user *entity.User) error {
+	inviteToken, err := tokens.GeneratePasswordResetToken()
+	if err != nil {
+		return err
+	}
+	expiresAt := time.Now().Add(s.config.Account.InviteTTL)
+
+	if err := s.userRepo.UpdatePasswordResetToken(ctx, user.ID, inviteToken, &expiresAt); err != nil {
+		return err
+	}
+
+	inviteURL := fmt.Sprintf("%s/api/v1/auth/reset-password?token=%s", s.config.Email.BaseURL, inviteToken)
+	return s.emailService.SendUserInviteEmail(userLocale(ctx, user), user.Email, user.Name, inviteURL, expiresAt)
+}
+
+// parseCSVImport reads a CSV file whose header names the name, email and (optional) role columns
+func parseCSVImport(r io.Reader) ([]importRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: file is empty", ErrInvalidImportFile)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImportFile, err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[name] = i
+	}
+	for _, required := range []string{"name", "email"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("%w: missing required column %q", ErrInvalidImportFile, required)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var rows []importRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidImportFile, err)
+		}
+		if len(rows) >= maxImportRows {
+			return nil, ErrTooManyImportRows
+		}
+
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, importRow{
+			line: line,
+			data: dto.ImportUserRow{
+				Name:  field(record, "name"),
+				Email: strings.ToLower(field(record, "email")),
+				Role:  strings.ToLower(field(record, "role")),
+			},
+		})
+	}
+
+	return rows, nil
+}
+
+// parseNDJSONImport reads one JSON user object per line, skipping blank lines
+func parseNDJSONImport(r io.Reader) ([]importRow, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var rows []importRow
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		if len(rows) >= maxImportRows {
+			return nil, ErrTooManyImportRows
+		}
+
+		row := importRow{line: line}
+		if err := json.Unmarshal([]byte(text), &row.data); err != nil {
+			row.parseErr = &dto.ValidationError{
+				Tag:     "json",
+				Message: "line is not a valid JSON object",
+			}
+		} else {
+			row.data.Name = strings.TrimSpace(row.data.Name)
+			row.data.Email = strings.ToLower(strings.TrimSpace(row.data.Email))
+			row.data.Role = strings.ToLower(strings.TrimSpace(row.data.Role))
+		}
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImportFile, err)
+	}
+
+	return rows, nil
+}
+
+func userToCSVRecord(user *entity.User) []string {
+	return []string{
+		strconv.Itoa(user.ID),
+		csvText(user.Name),
+		csvText(user.Email),
+		user.Role,
+		strconv.FormatBool(user.EmailVerified),
+		user.CreatedAt.UTC().Format(time.RFC3339),
+		user.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// csvText neutralizes user-controlled text that spreadsheets would run as a formula, such as
+// =HYPERLINK(...), by prefixing it with a quote
+func csvText(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
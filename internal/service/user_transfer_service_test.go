@@ -0,0 +1,117 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"strconv"
+	"testing"
+	"time"
+
+	"go-template/internal/dto"
+	"go-template/internal/entity"
+	"go-template/internal/repository"
+	"go-template/pkg/pagination"
+)
+
+func TestUserToCSVRecordNeutralizesFormulas(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"Jane Doe", "Jane Doe"},
+		{"", ""},
+		{`=HYPERLINK("http://evil.example","click")`, `'=HYPERLINK("http://evil.example","click")`},
+		{"+1 555 0100", "'+1 555 0100"},
+		{"-2+3", "'-2+3"},
+		{"@SUM(A1:A2)", "'@SUM(A1:A2)"},
+		{"\t=1+1", "'\t=1+1"},
+		{"\r=1+1", "'\r=1+1"},
+		{"Jane = Doe", "Jane = Doe"},
+	}
+	for _, tt := range tests {
+		user := &entity.User{ID: 1, Name: tt.value, Email: tt.value, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+		record := userToCSVRecord(user)
+		if record[1] != tt.want || record[2] != tt.want {
+			t.Errorf("userToCSVRecord with %q wrote name %q and email %q, want %q", tt.value, record[1], record[2], tt.want)
+		}
+	}
+}
+
+// fakeExportUserRepository pages through users by ID with plain numeric cursors, and deletes the user
+// in deleteDuring once the first page was read, like a concurrent request would
+type fakeExportUserRepository struct {
+	repository.UserRepository
+	users        []entity.User
+	deleteDuring int
+	queries      []pagination.PaginationParams
+}
+
+func (r *fakeExportUserRepository) GetAllWithCursor(ctx context.Context, paginationParams pagination.PaginationParams, filterParams pagination.FilterParams) ([]entity.User, pagination.PaginationMeta, error) {
+	r.queries = append(r.queries, paginationParams)
+
+	afterID := 0
+	if paginationParams.Cursor != "" {
+		afterID, _ = strconv.Atoi(paginationParams.Cursor)
+	}
+	var page []entity.User
+	for _, user := range r.users {
+		if user.ID > afterID && len(page) < paginationParams.Limit+1 {
+			page = append(page, user)
+		}
+	}
+
+	meta := pagination.PaginationMeta{PerPage: paginationParams.Limit}
+	if len(page) > paginationParams.Limit {
+		page = page[:paginationParams.Limit]
+		meta.NextCursor = strconv.Itoa(page[len(page)-1].ID)
+	}
+
+	if len(r.queries) == 1 {
+		for i, user := range r.users {
+			if user.ID == r.deleteDuring {
+				r.users = append(r.users[:i], r.users[i+1:]...)
+				break
+			}
+		}
+	}
+	return page, meta, nil
+}
+
+func TestExportUsersWalksIDOrder(t *testing.T) {
+	users := make([]entity.User, exportBatchSize*2+3)
+	for i := range users {
+		users[i] = entity.User{ID: i + 1, Name: "User " + strconv.Itoa(i+1), Email: strconv.Itoa(i+1) + "@example.com", Role: "user"}
+	}
+	// Deleting a user of the first page must not make the export skip one of the next page
+	repo := &fakeExportUserRepository{users: users, deleteDuring: 1}
+	s := NewUserTransferService(repo, nil, nil, nil, nil)
+
+	var out bytes.Buffer
+	params := pagination.PaginationParams{Sort: "name", Order: "DESC", Limit: 10}
+	if err := s.ExportUsers(context.Background(), &out, dto.UserTransferFormatCSV, params, pagination.FilterParams{}); err != nil {
+		t.Fatalf("ExportUsers: %v", err)
+	}
+
+	records, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatalf("reading the export: %v", err)
+	}
+	if len(records) != len(users)+1 {
+		t.Fatalf("export has %d rows, want a header and %d users", len(records), len(users))
+	}
+	for i, record := range records[1:] {
+		if record[0] != strconv.Itoa(i+1) {
+			t.Fatalf("row %d has user %s, want %d", i+1, record[0], i+1)
+		}
+	}
+
+	if len(repo.queries) != 3 {
+		t.Errorf("export ran %d queries, want 3", len(repo.queries))
+	}
+	for _, query := range repo.queries {
+		if query.Sort != "id" || query.Order != "ASC" || query.Limit != exportBatchSize {
+			t.Errorf("export queried sort %s %s with limit %d, want id ASC with limit %d", query.Sort, query.Order, query.Limit, exportBatchSize)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package apperror
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Kind classifies an error independently of the transport; the HTTP layer maps it to a status code
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindInvalid
+	KindUnauthorized
+	KindForbidden
+	KindNotFound
+	KindConflict
+	KindGone
+	KindTooLarge
+	KindUnsupportedMediaType
+	KindUnprocessable
+	KindTooManyRequests
+	KindUnavailable
+	KindPreconditionFailed
+)
+
+var kindStatus = map[Kind]int{
+	KindInternal:             http.StatusInternalServerError,
+	KindInvalid:              http.StatusBadRequest,
+	KindUnauthorized:         http.StatusUnauthorized,
+	KindForbidden:            http.StatusForbidden,
+	KindNotFound:             http.StatusNotFound,
+	KindConflict:             http.StatusConflict,
+	KindGone:                 http.StatusGone,
+	KindTooLarge:             http.StatusRequestEntityTooLarge,
+	KindUnsupportedMediaType: http.StatusUnsupportedMediaType,
+	KindUnprocessable:        http.StatusUnprocessableEntity,
+	KindTooManyRequests:      http.StatusTooManyRequests,
+	KindUnavailable:          http.StatusServiceUnavailable,
+	KindPreconditionFailed:   http.StatusPreconditionFailed,
+}
+
+// Error is a domain error carrying a stable code from the catalogue and a message that is safe to show
+// to clients. The underlying cause is kept for logging only.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Details interface{}
+	cause   error
+}
+
+// New creates a domain error, typically assigned to a package-level sentinel
+func New(kind Kind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Is matches errors by code, so copies created by Wrap and WithDetails still match their sentinel
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// Wrap returns a copy of the error that records the underlying cause
+func (e *Error) Wrap(cause error) *Error {
+	wrapped := *e
+	wrapped.cause = cause
+	return &wrapped
+}
+
+// WithDetails returns a copy of the error with structured details, e.g. validation errors
+func (e *Error) WithDetails(details interface{}) *Error {
+	detailed := *e
+	detailed.Details = details
+	return &detailed
+}
+
+// WithMessage returns a copy of the error with a more specific client-facing message
+func (e *Error) WithMessage(message string) *Error {
+	specific := *e
+	specific.Message = message
+	return &specific
+}
+
+// HTTPStatus returns the status code matching the error kind
+func (e *Error) HTTPStatus() int {
+	if status, ok := kindStatus[e.Kind]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// As finds the first domain error in err's chain
+func As(err error) (*Error, bool) {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr, true
+	}
+	return nil, false
+}
@@ -0,0 +1,97 @@
+package apperror
+
+// Catalogue of the machine-readable error codes returned by the API. Codes are part of the public
+// contract: clients branch on them, so existing codes must never change meaning or be renamed.
+const (
+	// Generic request errors
+	CodeInvalidRequest       = "request.invalid"
+	CodeInvalidID            = "request.invalid_id"
+	CodeInvalidBody          = "request.invalid_body"
+	CodeMissingFile          = "request.missing_file"
+	CodeValidationFailed     = "request.validation_failed"
+	CodeRequestTooLarge      = "request.too_large"
+	CodeUnsupportedMediaType = "request.unsupported_media_type"
+	CodeRouteNotFound        = "request.route_not_found"
+	CodeMethodNotAllowed     = "request.method_not_allowed"
+	CodeRateLimited          = "request.rate_limited"
+	CodeInvalidCursor        = "request.invalid_cursor"
+	CodeInvalidFilter        = "request.invalid_filter"
+	CodeUnknownExpansion     = "request.unknown_expansion"
+
+	// Generic resource errors, used when no more specific code applies
+	CodeNotFound = "resource.not_found"
+	CodeConflict = "resource.conflict"
+
+	// Authentication and authorization
+	CodeUnauthorized              = "auth.unauthorized"
+	CodeMissingToken              = "auth.missing_token"
+	CodeInvalidToken              = "auth.invalid_token"
+	CodeTokenExpired              = "auth.token_expired"
+	CodeInvalidCredentials        = "auth.invalid_credentials"
+	CodeInvalidRefreshToken       = "auth.invalid_refresh_token"
+	CodeForbidden                 = "auth.forbidden"
+	CodeInvalidVerificationToken  = "auth.invalid_verification_token"
+	CodeEmailAlreadyVerified      = "auth.email_already_verified"
+	CodeInvalidPasswordResetToken = "auth.invalid_password_reset_token"
+
+	// Users
+	CodeUserNotFound         = "user.not_found"
+	CodeUserAlreadyExists    = "user.already_exists"
+	CodeAvatarTypeNotAllowed = "user.avatar_type_not_allowed"
+	CodeAvatarInvalid        = "user.avatar_invalid"
+	CodeAvatarTooLarge       = "user.avatar_too_large"
+	CodeInvalidImportFile    = "user.invalid_import_file"
+	CodeTooManyImportRows    = "user.too_many_import_rows"
+	CodeUnsupportedFormat    = "user.unsupported_format"
+
+	// Account self-service
+	CodeInvalidPassword          = "account.invalid_password"
+	CodeDeletionAlreadyScheduled = "account.deletion_already_scheduled"
+	CodeDeletionNotScheduled     = "account.deletion_not_scheduled"
+	CodeDataExportInProgress     = "account.data_export_in_progress"
+	CodeInvalidDataExportToken   = "account.invalid_data_export_token"
+
+	// Files
+	CodeFileNotFound           = "file.not_found"
+	CodeFileTooLarge           = "file.too_large"
+	CodeFileTypeNotAllowed     = "file.type_not_allowed"
+	CodeFileTypeMismatch       = "file.type_mismatch"
+	CodeFileStorageFailed      = "file.storage_failed"
+	CodeFileURLInvalid         = "file.url_invalid"
+	CodeFileURLExpired         = "file.url_expired"
+	CodeFileVariantNotFound    = "file.variant_not_found"
+	CodeFileImageUnprocessable = "file.image_unprocessable"
+	CodeFileScanPending        = "file.scan_pending"
+	CodeFileInfected           = "file.infected"
+	CodeFileUnscannable        = "file.unscannable"
+	CodeFileCorrupted          = "file.corrupted"
+	CodeFileVersionNotFound    = "file.version_not_found"
+	CodeFileVersionConflict    = "file.version_conflict"
+
+	// Storage quotas
+	CodeStorageQuotaExceeded = "storage.quota_exceeded"
+
+	// Folders
+	CodeFolderNotFound      = "folder.not_found"
+	CodeFolderAlreadyExists = "folder.already_exists"
+	CodeFolderNotEmpty      = "folder.not_empty"
+	CodeFolderInvalidMove   = "folder.invalid_move"
+
+	// Sharing through links and grants
+	CodeShareLinkNotFound    = "share.link_not_found"
+	CodeShareLinkExpired     = "share.link_expired"
+	CodeShareInvalidPassword = "share.invalid_password"
+	CodeShareGrantNotFound   = "share.grant_not_found"
+
+	// Resumable (tus) uploads
+	CodeUploadNotFound           = "upload.not_found"
+	CodeUploadTooLarge           = "upload.too_large"
+	CodeUploadOffsetMismatch     = "upload.offset_mismatch"
+	CodeUploadLengthExceeded     = "upload.length_exceeded"
+	CodeUploadChunkIncomplete    = "upload.chunk_incomplete"
+	CodeUploadVersionUnsupported = "upload.unsupported_version"
+
+	// Server side failures; details are never exposed in production
+	CodeInternal    = "internal.error"
+	CodeUnavailable = "internal.unavailable"
+)
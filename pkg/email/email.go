@@ -1,11 +1,37 @@
 package email
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"html"
 	"net/smtp"
 	"strings"
+	"time"
+
+	"go-template/pkg/i18n"
 )
 
+// Category classifies an email for notification preference checks
+type Category string
+
+const (
+	// CategorySecurity covers verification, password and account security emails; always delivered
+	CategorySecurity Category = "security"
+	// CategoryAccount covers transactional emails the user asked for; always delivered
+	CategoryAccount Category = "account"
+	// CategoryProduct covers product news and announcements; users can opt out
+	CategoryProduct Category = "product"
+)
+
+// ErrRecipientOptedOut is returned when the recipient disabled emails of the given category
+var ErrRecipientOptedOut = errors.New("recipient has opted out of this email category")
+
+// PreferenceChecker reports whether a recipient accepts emails of a category
+type PreferenceChecker interface {
+	AllowsEmail(ctx context.Context, toEmail string, category Category) (bool, error)
+}
+
 // Config holds email service configuration
 type Config struct {
 	SMTPHost     string
@@ -16,15 +42,21 @@ type Config struct {
 	FromName     string
 }
 
-// Service represents email service interface
+// Service represents email service interface. Emails are rendered in the given locale; unsupported
+// locales fall back to English.
 type Service interface {
-	SendVerificationEmail(toEmail, toName, verificationToken string) error
-	SendPasswordResetEmail(toEmail, toName, resetToken string) error
+	SendVerificationEmail(locale, toEmail, toName, verificationToken string) error
+	SendPasswordResetEmail(locale, toEmail, toName, resetToken string) error
+	SendDataExportEmail(locale, toEmail, toName, downloadURL string, expiresAt time.Time) error
+	SendAccountDeletionScheduledEmail(locale, toEmail, toName string, scheduledFor time.Time) error
+	SendUserInviteEmail(locale, toEmail, toName, inviteURL string, expiresAt time.Time) error
+	SendNotificationEmail(locale, toEmail, toName string, category Category, subject, message string) error
 }
 
 // SMTPService implements email service using SMTP
 type SMTPService struct {
-	config *Config
+	config      *Config
+	preferences PreferenceChecker
 }
 
 // NewSMTPService creates a new SMTP email service
@@ -34,32 +66,82 @@ func NewSMTPService(config *Config) *SMTPService {
 	}
 }
 
+// SetPreferenceChecker makes the service consult recipient preferences before sending
+func (s *SMTPService) SetPreferenceChecker(preferences PreferenceChecker) {
+	s.preferences = preferences
+}
+
 // SendVerificationEmail sends an email verification email
-func (s *SMTPService) SendVerificationEmail(toEmail, toName, verificationToken string) error {
-	subject := "Verify Your Email Address"
+func (s *SMTPService) SendVerificationEmail(locale, toEmail, toName, verificationToken string) error {
+	subject := i18n.T(locale, "Verify Your Email Address")
 	
 	// Generate verification URL (this should come from config in real implementation)
 	verificationURL := fmt.Sprintf("http://localhost:8080/api/v1/auth/verify-email?token=%s", verificationToken)
 	
-	body := s.generateVerificationEmailBody(toName, verificationURL)
+	body := s.generateVerificationEmailBody(locale, toName, verificationURL)
 	
-	return s.sendEmail(toEmail, subject, body)
+	return s.sendEmail(CategorySecurity, toEmail, subject, body)
 }
 
 // SendPasswordResetEmail sends a password reset email
-func (s *SMTPService) SendPasswordResetEmail(toEmail, toName, resetToken string) error {
-	subject := "Reset Your Password"
+func (s *SMTPService) SendPasswordResetEmail(locale, toEmail, toName, resetToken string) error {
+	subject := i18n.T(locale, "Reset Your Password")
 	
 	// Generate password reset URL (this should come from config in real implementation)
 	resetURL := fmt.Sprintf("http://localhost:8080/api/v1/auth/reset-password?token=%s", resetToken)
 	
-	body := s.generatePasswordResetEmailBody(toName, resetURL)
+	body := s.generatePasswordResetEmailBody(locale, toName, resetURL)
 	
-	return s.sendEmail(toEmail, subject, body)
+	return s.sendEmail(CategorySecurity, toEmail, subject, body)
+}
+
+// SendDataExportEmail sends a download link for a completed personal data export
+func (s *SMTPService) SendDataExportEmail(locale, toEmail, toName, downloadURL string, expiresAt time.Time) error {
+	subject := i18n.T(locale, "Your Data Export Is Ready")
+
+	body := s.generateDataExportEmailBody(locale, toName, downloadURL, expiresAt)
+
+	return s.sendEmail(CategoryAccount, toEmail, subject, body)
+}
+
+// SendAccountDeletionScheduledEmail notifies a user that their account is scheduled for deletion
+func (s *SMTPService) SendAccountDeletionScheduledEmail(locale, toEmail, toName string, scheduledFor time.Time) error {
+	subject := i18n.T(locale, "Your Account Is Scheduled For Deletion")
+
+	body := s.generateAccountDeletionEmailBody(locale, toName, scheduledFor)
+
+	return s.sendEmail(CategorySecurity, toEmail, subject, body)
+}
+
+// SendUserInviteEmail invites an imported user to set a password for their new account
+func (s *SMTPService) SendUserInviteEmail(locale, toEmail, toName, inviteURL string, expiresAt time.Time) error {
+	subject := i18n.T(locale, "You Have Been Invited")
+
+	body := s.generateUserInviteEmailBody(locale, toName, inviteURL, expiresAt)
+
+	return s.sendEmail(CategoryAccount, toEmail, subject, body)
+}
+
+// SendNotificationEmail sends a plain notification, honouring the recipient's preferences for its category
+func (s *SMTPService) SendNotificationEmail(locale, toEmail, toName string, category Category, subject, message string) error {
+	body := s.generateNotificationEmailBody(locale, toName, subject, message)
+
+	return s.sendEmail(category, toEmail, subject, body)
 }
 
 // sendEmail sends an email using SMTP
-func (s *SMTPService) sendEmail(to, subject, body string) error {
+func (s *SMTPService) sendEmail(category Category, to, subject, body string) error {
+	// Security emails can never be turned off
+	if category != CategorySecurity && s.preferences != nil {
+		allowed, err := s.preferences.AllowsEmail(context.Background(), to, category)
+		if err != nil {
+			return fmt.Errorf("failed to check email preferences for %s: %w", to, err)
+		}
+		if !allowed {
+			return ErrRecipientOptedOut
+		}
+	}
+	
 	// Create authentication
 	auth := smtp.PlainAuth("", s.config.SMTPUsername, s.config.SMTPPassword, s.config.SMTPHost)
 	
@@ -94,13 +176,14 @@ func (s *SMTPService) buildEmailMessage(from, to, subject, body string) string {
 }
 
 // generateVerificationEmailBody generates HTML email body for verification
-func (s *SMTPService) generateVerificationEmailBody(name, verificationURL string) string {
+func (s *SMTPService) generateVerificationEmailBody(locale, name, verificationURL string) string {
+	t := func(message string, args ...interface{}) string { return i18n.T(locale, message, args...) }
 	return fmt.Sprintf(`
 <!DOCTYPE html>
-<html>
+<html lang="%s">
 <head>
     <meta charset="UTF-8">
-    <title>Verify Your Email</title>
+    <title>%s</title>
     <style>
         body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
         .container { max-width: 600px; margin: 0 auto; padding: 20px; }
@@ -113,37 +196,44 @@ func (s *SMTPService) generateVerificationEmailBody(name, verificationURL string
 <body>
     <div class="container">
         <div class="header">
-            <h1>Welcome to Go Template!</h1>
+            <h1>%s</h1>
         </div>
         <div class="content">
-            <h2>Hi %s,</h2>
-            <p>Thank you for registering with Go Template! To complete your registration, please verify your email address by clicking the button below:</p>
+            <h2>%s</h2>
+            <p>%s</p>
             
-            <a href="%s" class="button">Verify My Email</a>
+            <a href="%s" class="button">%s</a>
             
-            <p>If the button doesn't work, you can copy and paste this link into your browser:</p>
+            <p>%s</p>
             <p><a href="%s">%s</a></p>
             
-            <p>This verification link will expire in 24 hours for security reasons.</p>
+            <p>%s</p>
             
-            <p>If you didn't create an account with us, please ignore this email.</p>
+            <p>%s</p>
         </div>
         <div class="footer">
-            <p>© 2025 Go Template. All rights reserved.</p>
+            <p>%s</p>
         </div>
     </div>
 </body>
-</html>`, name, verificationURL, verificationURL, verificationURL)
+</html>`, locale, t("Verify Your Email"), t("Welcome to Go Template!"), t("Hi %s,", name),
+		t("Thank you for registering with Go Template! To complete your registration, please verify your email address by clicking the button below:"),
+		verificationURL, t("Verify My Email"),
+		t("If the button doesn't work, you can copy and paste this link into your browser:"), verificationURL, verificationURL,
+		t("This verification link will expire in 24 hours for security reasons."),
+		t("If you didn't create an account with us, please ignore this email."),
+		t("© 2025 Go Template. All rights reserved."))
 }
 
 // generatePasswordResetEmailBody generates HTML email body for password reset
-func (s *SMTPService) generatePasswordResetEmailBody(name, resetURL string) string {
+func (s *SMTPService) generatePasswordResetEmailBody(locale, name, resetURL string) string {
+	t := func(message string, args ...interface{}) string { return i18n.T(locale, message, args...) }
 	return fmt.Sprintf(`
 <!DOCTYPE html>
-<html>
+<html lang="%s">
 <head>
     <meta charset="UTF-8">
-    <title>Reset Your Password</title>
+    <title>%s</title>
     <style>
         body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
         .container { max-width: 600px; margin: 0 auto; padding: 20px; }
@@ -156,25 +246,212 @@ func (s *SMTPService) generatePasswordResetEmailBody(name, resetURL string) stri
 <body>
     <div class="container">
         <div class="header">
-            <h1>Password Reset Request</h1>
+            <h1>%s</h1>
+        </div>
+        <div class="content">
+            <h2>%s</h2>
+            <p>%s</p>
+            
+            <a href="%s" class="button">%s</a>
+            
+            <p>%s</p>
+            <p><a href="%s">%s</a></p>
+            
+            <p>%s</p>
+            
+            <p>%s</p>
+        </div>
+        <div class="footer">
+            <p>%s</p>
+        </div>
+    </div>
+</body>
+</html>`, locale, t("Reset Your Password"), t("Password Reset Request"), t("Hi %s,", name),
+		t("We received a request to reset your password. If you made this request, click the button below to reset your password:"),
+		resetURL, t("Reset My Password"),
+		t("If the button doesn't work, you can copy and paste this link into your browser:"), resetURL, resetURL,
+		t("This password reset link will expire in 24 hours for security reasons."),
+		t("If you didn't request a password reset, please ignore this email. Your password will remain unchanged."),
+		t("© 2025 Go Template. All rights reserved."))
+}
+
+// generateDataExportEmailBody generates HTML email body for a completed data export
+func (s *SMTPService) generateDataExportEmailBody(locale, name, downloadURL string, expiresAt time.Time) string {
+	t := func(message string, args ...interface{}) string { return i18n.T(locale, message, args...) }
+	return fmt.Sprintf(`
+<!DOCTYPE html>
+<html lang="%s">
+<head>
+    <meta charset="UTF-8">
+    <title>%s</title>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background-color: #2196F3; color: white; padding: 20px; text-align: center; }
+        .content { padding: 20px; background-color: #f9f9f9; }
+        .button { display: inline-block; padding: 12px 24px; background-color: #2196F3; color: white; text-decoration: none; border-radius: 4px; margin: 20px 0; }
+        .footer { padding: 20px; text-align: center; color: #666; font-size: 12px; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>%s</h1>
         </div>
         <div class="content">
-            <h2>Hi %s,</h2>
-            <p>We received a request to reset your password. If you made this request, click the button below to reset your password:</p>
+            <h2>%s</h2>
+            <p>%s</p>
             
-            <a href="%s" class="button">Reset My Password</a>
+            <a href="%s" class="button">%s</a>
             
-            <p>If the button doesn't work, you can copy and paste this link into your browser:</p>
+            <p>%s</p>
             <p><a href="%s">%s</a></p>
             
-            <p>This password reset link will expire in 24 hours for security reasons.</p>
+            <p>%s</p>
             
-            <p>If you didn't request a password reset, please ignore this email. Your password will remain unchanged.</p>
+            <p>%s</p>
         </div>
         <div class="footer">
-            <p>© 2025 Go Template. All rights reserved.</p>
+            <p>%s</p>
         </div>
     </div>
 </body>
-</html>`, name, resetURL, resetURL, resetURL)
-}
\ No newline at end of file
+</html>`, locale, t("Your Data Export Is Ready"), t("Your Data Export Is Ready"), t("Hi %s,", name),
+		t("The export of your personal data you requested has been generated. It contains your profile, the metadata of your files and the files you uploaded."),
+		downloadURL, t("Download My Data"),
+		t("If the button doesn't work, you can copy and paste this link into your browser:"), downloadURL, downloadURL,
+		t("This download link will expire on %s.", i18n.FormatDateTime(locale, expiresAt)),
+		t("If you didn't request a data export, please change your password immediately."),
+		t("© 2025 Go Template. All rights reserved."))
+}
+
+// generateAccountDeletionEmailBody generates HTML email body for a scheduled account deletion
+func (s *SMTPService) generateAccountDeletionEmailBody(locale, name string, scheduledFor time.Time) string {
+	t := func(message string, args ...interface{}) string { return i18n.T(locale, message, args...) }
+	return fmt.Sprintf(`
+<!DOCTYPE html>
+<html lang="%s">
+<head>
+    <meta charset="UTF-8">
+    <title>%s</title>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background-color: #FF6B6B; color: white; padding: 20px; text-align: center; }
+        .content { padding: 20px; background-color: #f9f9f9; }
+        .footer { padding: 20px; text-align: center; color: #666; font-size: 12px; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>%s</h1>
+        </div>
+        <div class="content">
+            <h2>%s</h2>
+            <p>%s</p>
+            
+            <p>%s</p>
+            
+            <p>%s</p>
+        </div>
+        <div class="footer">
+            <p>%s</p>
+        </div>
+    </div>
+</body>
+</html>`, locale, t("Your Account Is Scheduled For Deletion"), t("Account Deletion Scheduled"), t("Hi %s,", name),
+		t("We received a request to delete your account. Your account and all of your files will be permanently deleted on %s.", i18n.FormatDateTime(locale, scheduledFor)),
+		t("Until then you can still sign in and cancel the deletion from your account settings."),
+		t("If you didn't request this, please sign in, cancel the deletion and change your password immediately."),
+		t("© 2025 Go Template. All rights reserved."))
+}
+
+// generateUserInviteEmailBody generates HTML email body for an account invitation
+func (s *SMTPService) generateUserInviteEmailBody(locale, name, inviteURL string, expiresAt time.Time) string {
+	t := func(message string, args ...interface{}) string { return i18n.T(locale, message, args...) }
+	return fmt.Sprintf(`
+<!DOCTYPE html>
+<html lang="%s">
+<head>
+    <meta charset="UTF-8">
+    <title>%s</title>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background-color: #4CAF50; color: white; padding: 20px; text-align: center; }
+        .content { padding: 20px; background-color: #f9f9f9; }
+        .button { display: inline-block; padding: 12px 24px; background-color: #4CAF50; color: white; text-decoration: none; border-radius: 4px; margin: 20px 0; }
+        .footer { padding: 20px; text-align: center; color: #666; font-size: 12px; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>%s</h1>
+        </div>
+        <div class="content">
+            <h2>%s</h2>
+            <p>%s</p>
+            
+            <a href="%s" class="button">%s</a>
+            
+            <p>%s</p>
+            <p><a href="%s">%s</a></p>
+            
+            <p>%s</p>
+            
+            <p>%s</p>
+        </div>
+        <div class="footer">
+            <p>%s</p>
+        </div>
+    </div>
+</body>
+</html>`, locale, t("You Have Been Invited"), t("Welcome to Go Template"), t("Hi %s,", name),
+		t("An account has been created for you. To get started, please choose a password by clicking the button below:"),
+		inviteURL, t("Set My Password"),
+		t("If the button doesn't work, you can copy and paste this link into your browser:"), inviteURL, inviteURL,
+		t("This invitation will expire on %s.", i18n.FormatDateTime(locale, expiresAt)),
+		t("If you weren't expecting this invitation, you can safely ignore this email."),
+		t("© 2025 Go Template. All rights reserved."))
+}
+
+// generateNotificationEmailBody generates HTML email body for a generic notification; subject and
+// message are expected to be translated by the caller
+func (s *SMTPService) generateNotificationEmailBody(locale, name, subject, message string) string {
+	t := func(message string, args ...interface{}) string { return i18n.T(locale, message, args...) }
+	return fmt.Sprintf(`
+<!DOCTYPE html>
+<html lang="%s">
+<head>
+    <meta charset="UTF-8">
+    <title>%s</title>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background-color: #2196F3; color: white; padding: 20px; text-align: center; }
+        .content { padding: 20px; background-color: #f9f9f9; }
+        .footer { padding: 20px; text-align: center; color: #666; font-size: 12px; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>%s</h1>
+        </div>
+        <div class="content">
+            <h2>%s</h2>
+            <p>%s</p>
+            
+            <p>%s</p>
+        </div>
+        <div class="footer">
+            <p>%s</p>
+        </div>
+    </div>
+</body>
+</html>`, locale, html.EscapeString(subject), html.EscapeString(subject), t("Hi %s,", html.EscapeString(name)), html.EscapeString(message),
+		t("You can change which emails you receive in your notification settings."),
+		t("© 2025 Go Template. All rights reserved."))
+}
@@ -0,0 +1,227 @@
+package email
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSMTP accepts every message and every AUTH PLAIN login, and records the recipients
+type fakeSMTP struct {
+	ln net.Listener
+
+	mu         sync.Mutex
+	recipients []string
+}
+
+func newFakeSMTP(t *testing.T) *fakeSMTP {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	f := &fakeSMTP{ln: ln}
+	t.Cleanup(func() { ln.Close() })
+	go f.serve()
+	return f
+}
+
+func (f *fakeSMTP) serve() {
+	for {
+		conn, err := f.ln.Accept()
+		if err != nil {
+			return
+		}
+		go f.handle(conn)
+	}
+}
+
+func (f *fakeSMTP) handle(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 fake ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			reply("250-fake")
+			reply("250 AUTH PLAIN")
+		case strings.HasPrefix(command, "AUTH PLAIN"):
+			reply("235 Authentication successful")
+		case strings.HasPrefix(command, "MAIL FROM:"), strings.HasPrefix(command, "RSET"), strings.HasPrefix(command, "NOOP"):
+			reply("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			f.mu.Lock()
+			f.recipients = append(f.recipients, strings.Trim(strings.TrimSpace(line)[len("RCPT TO:"):], "<>"))
+			f.mu.Unlock()
+			reply("250 OK")
+		case command == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			for {
+				dataLine, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dataLine == ".\r\n" {
+					break
+				}
+			}
+			reply("250 OK")
+		case command == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+func (f *fakeSMTP) delivered() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.recipients...)
+}
+
+// fakePreferences answers every preference check the same way and records the categories checked
+type fakePreferences struct {
+	allowed bool
+	err     error
+	checked []Category
+}
+
+func (p *fakePreferences) AllowsEmail(ctx context.Context, toEmail string, category Category) (bool, error) {
+	p.checked = append(p.checked, category)
+	return p.allowed, p.err
+}
+
+func newTestService(t *testing.T, preferences PreferenceChecker) (*SMTPService, *fakeSMTP) {
+	t.Helper()
+
+	server := newFakeSMTP(t)
+	host, port, err := net.SplitHostPort(server.ln.Addr().String())
+	if err != nil {
+		t.Fatalf("split address: %v", err)
+	}
+	service := NewSMTPService(&Config{
+		SMTPHost:  host,
+		SMTPPort:  port,
+		FromEmail: "noreply@example.com",
+		FromName:  "Go Template",
+	})
+	if preferences != nil {
+		service.SetPreferenceChecker(preferences)
+	}
+	return service, server
+}
+
+func TestSecurityEmailsIgnoreOptOut(t *testing.T) {
+	preferences := &fakePreferences{allowed: false}
+	service, server := newTestService(t, preferences)
+	expiresAt := time.Now().Add(time.Hour)
+
+	sends := map[string]func() error{
+		"verification": func() error {
+			return service.SendVerificationEmail("en", "a@example.com", "A", "token")
+		},
+		"password reset": func() error {
+			return service.SendPasswordResetEmail("en", "b@example.com", "B", "token")
+		},
+		"deletion scheduled": func() error {
+			return service.SendAccountDeletionScheduledEmail("en", "c@example.com", "C", expiresAt)
+		},
+		"security notification": func() error {
+			return service.SendNotificationEmail("en", "d@example.com", "D", CategorySecurity, "New login", "A new device signed in.")
+		},
+	}
+	for name, send := range sends {
+		if err := send(); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+	if len(server.delivered()) != len(sends) {
+		t.Errorf("delivered to %v, want %d recipients", server.delivered(), len(sends))
+	}
+	if len(preferences.checked) != 0 {
+		t.Errorf("preferences checked for %v, want security emails sent unconditionally", preferences.checked)
+	}
+}
+
+func TestAccountEmailsFollowPreferenceChecker(t *testing.T) {
+	// Account updates are locked on, so the checker allows them; the email service still asks
+	preferences := &fakePreferences{allowed: true}
+	service, server := newTestService(t, preferences)
+	expiresAt := time.Now().Add(time.Hour)
+
+	if err := service.SendDataExportEmail("en", "a@example.com", "A", "http://localhost/export", expiresAt); err != nil {
+		t.Fatalf("SendDataExportEmail: %v", err)
+	}
+	if err := service.SendUserInviteEmail("en", "b@example.com", "B", "http://localhost/invite", expiresAt); err != nil {
+		t.Fatalf("SendUserInviteEmail: %v", err)
+	}
+	if got := server.delivered(); len(got) != 2 {
+		t.Errorf("delivered to %v, want 2 recipients", got)
+	}
+	for _, category := range preferences.checked {
+		if category != CategoryAccount {
+			t.Errorf("checked category %q, want %q", category, CategoryAccount)
+		}
+	}
+}
+
+func TestProductEmailOptOut(t *testing.T) {
+	preferences := &fakePreferences{allowed: false}
+	service, server := newTestService(t, preferences)
+
+	err := service.SendNotificationEmail("en", "a@example.com", "A", CategoryProduct, "News", "Something new.")
+	if !errors.Is(err, ErrRecipientOptedOut) {
+		t.Fatalf("SendNotificationEmail error = %v, want ErrRecipientOptedOut", err)
+	}
+	if got := server.delivered(); len(got) != 0 {
+		t.Errorf("delivered to %v after opt-out", got)
+	}
+}
+
+func TestProductEmailDelivered(t *testing.T) {
+	for name, preferences := range map[string]PreferenceChecker{
+		"opted in":       &fakePreferences{allowed: true},
+		"no preferences": nil,
+	} {
+		t.Run(name, func(t *testing.T) {
+			service, server := newTestService(t, preferences)
+
+			if err := service.SendNotificationEmail("en", "a@example.com", "A", CategoryProduct, "News", "Something new."); err != nil {
+				t.Fatalf("SendNotificationEmail: %v", err)
+			}
+			if got := server.delivered(); len(got) != 1 || got[0] != "a@example.com" {
+				t.Errorf("delivered to %v, want a@example.com", got)
+			}
+		})
+	}
+}
+
+func TestProductEmailPreferenceError(t *testing.T) {
+	preferences := &fakePreferences{err: errors.New("database unavailable")}
+	service, server := newTestService(t, preferences)
+
+	err := service.SendNotificationEmail("en", "a@example.com", "A", CategoryProduct, "News", "Something new.")
+	if err == nil || errors.Is(err, ErrRecipientOptedOut) {
+		t.Fatalf("SendNotificationEmail error = %v, want the preference error", err)
+	}
+	if got := server.delivered(); len(got) != 0 {
+		t.Errorf("delivered to %v although preferences could not be checked", got)
+	}
+}
@@ -0,0 +1,65 @@
+package filemeta
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrMalformed is returned for content whose structure does not match its type
+var ErrMalformed = errors.New("malformed file structure")
+
+// Metadata describes the content of a file. Fields that do not apply to its type are zero.
+type Metadata struct {
+	Width  int `json:"width,omitempty"`
+	Height int `json:"height,omitempty"`
+	// Pages is the page count of documents
+	Pages int `json:"pages,omitempty"`
+	// Frames and Duration, in seconds, describe animations
+	Frames   int     `json:"frames,omitempty"`
+	Duration float64 `json:"duration,omitempty"`
+}
+
+// CanStrip reports whether Strip removes metadata from content of the type
+func CanStrip(mimeType string) bool {
+	return mimeType == "image/jpeg" || mimeType == "image/png"
+}
+
+// Strip copies r to w without the metadata that can identify the author or locate where a photo was
+// taken: EXIF (including GPS), XMP, IPTC and comments in JPEG images; text, EXIF and time chunks in PNG
+// images. The EXIF orientation of JPEG images is kept, so they are still displayed upright. Data after
+// the end of the image is dropped.
+func Strip(w io.Writer, r io.Reader, mimeType string) error {
+	switch mimeType {
+	case "image/jpeg":
+		return stripJPEG(w, r)
+	case "image/png":
+		return stripPNG(w, r)
+	default:
+		_, err := io.Copy(w, r)
+		return err
+	}
+}
+
+// Extract reads the metadata of content of the given type; it returns nil for types it knows nothing about
+func Extract(r io.Reader, mimeType string) (*Metadata, error) {
+	switch mimeType {
+	case "image/jpeg":
+		return extractJPEG(r)
+	case "image/png":
+		return extractPNG(r)
+	case "image/gif":
+		return extractGIF(r)
+	case "application/pdf":
+		return extractPDF(r)
+	default:
+		return nil, nil
+	}
+}
+
+// truncated reports content that ends early as malformed
+func truncated(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return ErrMalformed
+	}
+	return err
+}
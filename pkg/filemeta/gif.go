@@ -0,0 +1,115 @@
+package filemeta
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+)
+
+// extractGIF reads the dimensions of the logical screen and, for animations, counts the frames and
+// adds up their delays. Image data is skipped, not decoded.
+func extractGIF(r io.Reader) (*Metadata, error) {
+	br := bufio.NewReader(r)
+	var header [13]byte
+	if _, err := io.ReadFull(br, header[:]); err != nil {
+		return nil, truncated(err)
+	}
+	if string(header[:6]) != "GIF87a" && string(header[:6]) != "GIF89a" {
+		return nil, ErrMalformed
+	}
+
+	metadata := &Metadata{
+		Width:  int(binary.LittleEndian.Uint16(header[6:])),
+		Height: int(binary.LittleEndian.Uint16(header[8:])),
+	}
+	if err := skipColorTable(br, header[10]); err != nil {
+		return nil, truncated(err)
+	}
+
+	delay := 0 // hundredths of a second
+	for {
+		introducer, err := br.ReadByte()
+		if err != nil {
+			return nil, truncated(err)
+		}
+
+		switch introducer {
+		case 0x21: // extension
+			label, err := br.ReadByte()
+			if err != nil {
+				return nil, truncated(err)
+			}
+			if label == 0xf9 {
+				// Graphic control extension: block size, flags, delay, transparent color index
+				var control [6]byte
+				if _, err := io.ReadFull(br, control[:]); err != nil {
+					return nil, truncated(err)
+				}
+				if control[0] != 4 {
+					return nil, ErrMalformed
+				}
+				delay += int(binary.LittleEndian.Uint16(control[2:]))
+				if control[5] != 0 {
+					// More sub-blocks than the extension defines
+					if err := skipSubBlocks(br); err != nil {
+						return nil, truncated(err)
+					}
+				}
+				continue
+			}
+			if err := skipSubBlocks(br); err != nil {
+				return nil, truncated(err)
+			}
+		case 0x2c: // image descriptor
+			var descriptor [9]byte
+			if _, err := io.ReadFull(br, descriptor[:]); err != nil {
+				return nil, truncated(err)
+			}
+			if err := skipColorTable(br, descriptor[8]); err != nil {
+				return nil, truncated(err)
+			}
+			// LZW minimum code size, then the image data
+			if _, err := br.ReadByte(); err != nil {
+				return nil, truncated(err)
+			}
+			if err := skipSubBlocks(br); err != nil {
+				return nil, truncated(err)
+			}
+			metadata.Frames++
+		case 0x3b: // trailer
+			if metadata.Frames > 1 {
+				metadata.Duration = float64(delay) / 100
+			} else {
+				metadata.Frames = 0
+			}
+			return metadata, nil
+		default:
+			return nil, ErrMalformed
+		}
+	}
+}
+
+// skipColorTable skips the color table announced by the flags of a screen or image descriptor
+func skipColorTable(r *bufio.Reader, flags byte) error {
+	if flags&0x80 == 0 {
+		return nil
+	}
+	_, err := r.Discard(3 << (flags&0x07 + 1))
+	return err
+}
+
+// skipSubBlocks skips length-prefixed data sub-blocks up to the terminating empty block
+func skipSubBlocks(r *bufio.Reader) error {
+	for {
+		size, err := r.ReadByte()
+		if err != nil {
+			return err
+		}
+		if size == 0 {
+			return nil
+		}
+		if _, err := r.Discard(int(size)); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,282 @@
+package filemeta
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+)
+
+// JPEG markers
+const (
+	markerSOF0  = 0xc0
+	markerSOF15 = 0xcf
+	markerDHT   = 0xc4
+	markerJPG   = 0xc8
+	markerDAC   = 0xcc
+	markerRST0  = 0xd0
+	markerRST7  = 0xd7
+	markerSOI   = 0xd8
+	markerEOI   = 0xd9
+	markerSOS   = 0xda
+	markerAPP0  = 0xe0
+	markerAPP1  = 0xe1
+	markerAPP2  = 0xe2
+	markerAPP14 = 0xee
+	markerAPP15 = 0xef
+	markerCOM   = 0xfe
+	markerTEM   = 0x01
+)
+
+var (
+	exifPrefix  = []byte("Exif\x00\x00")
+	iccPrefix   = []byte("ICC_PROFILE\x00")
+	adobePrefix = []byte("Adobe")
+)
+
+// exifOrientationTag is the TIFF tag of the EXIF orientation
+const exifOrientationTag = 0x0112
+
+type jpegReader struct {
+	r *bufio.Reader
+}
+
+// marker reads the next marker, skipping fill bytes
+func (j *jpegReader) marker() (byte, error) {
+	b, err := j.r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	if b != 0xff {
+		return 0, ErrMalformed
+	}
+	for b == 0xff {
+		if b, err = j.r.ReadByte(); err != nil {
+			return 0, err
+		}
+	}
+	return b, nil
+}
+
+// payload reads the length-prefixed payload of a segment
+func (j *jpegReader) payload() ([]byte, error) {
+	var length [2]byte
+	if _, err := io.ReadFull(j.r, length[:]); err != nil {
+		return nil, err
+	}
+	n := int(binary.BigEndian.Uint16(length[:]))
+	if n < 2 {
+		return nil, ErrMalformed
+	}
+	payload := make([]byte, n-2)
+	if _, err := io.ReadFull(j.r, payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// standalone reports whether a marker has no payload
+func standalone(marker byte) bool {
+	return marker == markerTEM || marker >= markerRST0 && marker <= markerEOI
+}
+
+// isSOF reports whether a marker starts a frame, whose header holds the image dimensions
+func isSOF(marker byte) bool {
+	return marker >= markerSOF0 && marker <= markerSOF15 && marker != markerDHT && marker != markerJPG && marker != markerDAC
+}
+
+func stripJPEG(w io.Writer, r io.Reader) error {
+	j := &jpegReader{r: bufio.NewReader(r)}
+	bw := bufio.NewWriter(w)
+
+	marker, err := j.marker()
+	if err != nil || marker != markerSOI {
+		return ErrMalformed
+	}
+	bw.Write([]byte{0xff, markerSOI})
+
+	for {
+		marker, err := j.marker()
+		if err != nil {
+			return truncated(err)
+		}
+		for {
+			if standalone(marker) {
+				bw.Write([]byte{0xff, marker})
+				if marker == markerEOI {
+					return bw.Flush()
+				}
+				break
+			}
+
+			payload, err := j.payload()
+			if err != nil {
+				return truncated(err)
+			}
+			if marker == markerAPP1 && bytes.HasPrefix(payload, exifPrefix) {
+				// Only the orientation survives, so the image is still displayed upright
+				if orientation := exifOrientation(payload[len(exifPrefix):]); orientation > 1 {
+					writeJPEGSegment(bw, markerAPP1, orientationEXIF(orientation))
+				}
+			} else if keepJPEGSegment(marker, payload) {
+				writeJPEGSegment(bw, marker, payload)
+			}
+			if marker != markerSOS {
+				break
+			}
+
+			// Entropy-coded data follows a scan header up to the next marker
+			if marker, err = copyEntropyData(bw, j.r); err != nil {
+				return truncated(err)
+			}
+		}
+	}
+}
+
+// keepJPEGSegment tells which segments are needed to display the image: metadata segments are dropped,
+// except for color profiles and the Adobe segment that determines the color transform
+func keepJPEGSegment(marker byte, payload []byte) bool {
+	switch {
+	case marker == markerCOM:
+		return false
+	case marker == markerAPP2:
+		return bytes.HasPrefix(payload, iccPrefix)
+	case marker == markerAPP14:
+		return bytes.HasPrefix(payload, adobePrefix)
+	case marker > markerAPP0 && marker <= markerAPP15:
+		return false
+	default:
+		return true
+	}
+}
+
+func writeJPEGSegment(w *bufio.Writer, marker byte, payload []byte) {
+	w.Write([]byte{0xff, marker})
+	binary.Write(w, binary.BigEndian, uint16(len(payload)+2))
+	w.Write(payload)
+}
+
+// copyEntropyData copies the entropy-coded data of a scan and returns the marker ending it. Within the
+// data, 0xff is followed by a stuffed zero byte or a restart marker.
+func copyEntropyData(w *bufio.Writer, r *bufio.Reader) (byte, error) {
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if b != 0xff {
+			w.WriteByte(b)
+			continue
+		}
+
+		next, err := r.ReadByte()
+		for err == nil && next == 0xff {
+			next, err = r.ReadByte()
+		}
+		if err != nil {
+			return 0, err
+		}
+		if next == 0 || next >= markerRST0 && next <= markerRST7 {
+			w.Write([]byte{0xff, next})
+			continue
+		}
+		return next, nil
+	}
+}
+
+// exifOrientation returns the orientation (1-8) recorded in a TIFF structure, or 0 if there is none
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 0
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:4]) {
+	case "II*\x00":
+		order = binary.LittleEndian
+	case "MM\x00*":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 0
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < entries; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		if order.Uint16(tiff[entry:]) == exifOrientationTag && order.Uint16(tiff[entry+2:]) == 3 {
+			orientation := int(order.Uint16(tiff[entry+8:]))
+			if orientation >= 1 && orientation <= 8 {
+				return orientation
+			}
+			return 0
+		}
+	}
+	return 0
+}
+
+// orientationEXIF builds an EXIF payload holding nothing but the orientation
+func orientationEXIF(orientation int) []byte {
+	var buf bytes.Buffer
+	buf.Write(exifPrefix)
+	buf.WriteString("MM\x00*")
+	binary.Write(&buf, binary.BigEndian, uint32(8)) // offset of IFD0
+	binary.Write(&buf, binary.BigEndian, uint16(1)) // entry count
+	binary.Write(&buf, binary.BigEndian, uint16(exifOrientationTag))
+	binary.Write(&buf, binary.BigEndian, uint16(3)) // SHORT
+	binary.Write(&buf, binary.BigEndian, uint32(1)) // value count
+	binary.Write(&buf, binary.BigEndian, uint16(orientation))
+	binary.Write(&buf, binary.BigEndian, uint16(0)) // padding of the value field
+	binary.Write(&buf, binary.BigEndian, uint32(0)) // no next IFD
+	return buf.Bytes()
+}
+
+// extractJPEG reads the dimensions from the frame header, as displayed: orientations 5-8 swap them
+func extractJPEG(r io.Reader) (*Metadata, error) {
+	j := &jpegReader{r: bufio.NewReader(r)}
+	marker, err := j.marker()
+	if err != nil || marker != markerSOI {
+		return nil, ErrMalformed
+	}
+
+	orientation := 0
+	for {
+		marker, err := j.marker()
+		if err != nil {
+			return nil, truncated(err)
+		}
+		if standalone(marker) {
+			if marker == markerEOI {
+				return nil, ErrMalformed
+			}
+			continue
+		}
+		payload, err := j.payload()
+		if err != nil {
+			return nil, truncated(err)
+		}
+
+		switch {
+		case marker == markerAPP1 && bytes.HasPrefix(payload, exifPrefix):
+			orientation = exifOrientation(payload[len(exifPrefix):])
+		case isSOF(marker):
+			if len(payload) < 5 {
+				return nil, ErrMalformed
+			}
+			height := int(binary.BigEndian.Uint16(payload[1:]))
+			width := int(binary.BigEndian.Uint16(payload[3:]))
+			if orientation >= 5 {
+				width, height = height, width
+			}
+			return &Metadata{Width: width, Height: height}, nil
+		case marker == markerSOS:
+			// A frame header always precedes the first scan
+			return nil, ErrMalformed
+		}
+	}
+}
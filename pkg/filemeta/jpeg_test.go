@@ -0,0 +1,243 @@
+package filemeta
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"math/rand"
+	"testing"
+)
+
+// jpegSegment is a marker segment preceding the first scan
+type jpegSegment struct {
+	marker  byte
+	payload []byte
+}
+
+// testJPEG encodes a noisy image, so the entropy-coded data contains stuffed 0xff bytes
+func testJPEG(t *testing.T, width, height int) []byte {
+	t.Helper()
+
+	rng := rand.New(rand.NewSource(1))
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(rng.Intn(256))
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 90}); err != nil {
+		t.Fatalf("jpeg.Encode: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// withJPEGSegments inserts segments right after the SOI marker and appends trailing data after EOI
+func withJPEGSegments(content []byte, segments []jpegSegment, trailer []byte) []byte {
+	var buf bytes.Buffer
+	buf.Write(content[:2])
+	for _, segment := range segments {
+		buf.Write([]byte{0xff, segment.marker})
+		binary.Write(&buf, binary.BigEndian, uint16(len(segment.payload)+2))
+		buf.Write(segment.payload)
+	}
+	buf.Write(content[2:])
+	buf.Write(trailer)
+	return buf.Bytes()
+}
+
+// readJPEGSegments returns the segments up to the first scan
+func readJPEGSegments(t *testing.T, content []byte) []jpegSegment {
+	t.Helper()
+
+	j := &jpegReader{r: bufio.NewReader(bytes.NewReader(content))}
+	if marker, err := j.marker(); err != nil || marker != markerSOI {
+		t.Fatalf("no SOI marker: %v", err)
+	}
+	var segments []jpegSegment
+	for {
+		marker, err := j.marker()
+		if err != nil {
+			t.Fatalf("read marker: %v", err)
+		}
+		payload, err := j.payload()
+		if err != nil {
+			t.Fatalf("read segment payload: %v", err)
+		}
+		if marker == markerSOS {
+			return segments
+		}
+		segments = append(segments, jpegSegment{marker, payload})
+	}
+}
+
+// exifWithGPS builds an EXIF payload with the orientation, the camera make and a GPS IFD holding a
+// latitude
+func exifWithGPS(order binary.ByteOrder, orientation int) []byte {
+	tiff := make([]byte, 114)
+	if order == binary.BigEndian {
+		copy(tiff, "MM\x00*")
+	} else {
+		copy(tiff, "II*\x00")
+	}
+	order.PutUint32(tiff[4:], 8)
+
+	entry := func(offset int, tag, typ uint16, count, value uint32) {
+		order.PutUint16(tiff[offset:], tag)
+		order.PutUint16(tiff[offset+2:], typ)
+		order.PutUint32(tiff[offset+4:], count)
+		order.PutUint32(tiff[offset+8:], value)
+	}
+
+	// IFD0 at 8: make, orientation and the GPS IFD pointer, followed by the make at 50
+	order.PutUint16(tiff[8:], 3)
+	entry(10, 0x010f, 2, 10, 50)
+	entry(22, exifOrientationTag, 3, 1, 0)
+	order.PutUint16(tiff[30:], uint16(orientation))
+	entry(34, 0x8825, 4, 1, 60)
+	copy(tiff[50:], "SecretCam\x00")
+
+	// GPS IFD at 60: latitude reference and latitude, whose rationals follow at 90
+	order.PutUint16(tiff[60:], 2)
+	entry(62, 0x0001, 2, 2, 0)
+	copy(tiff[70:], "N\x00")
+	entry(74, 0x0002, 5, 3, 90)
+	for i, v := range []uint32{52, 1, 31, 1, 1234, 100} {
+		order.PutUint32(tiff[90+4*i:], v)
+	}
+
+	return append(append([]byte{}, exifPrefix...), tiff...)
+}
+
+func metadataSegments(exif []byte) []jpegSegment {
+	return []jpegSegment{
+		{markerAPP0, []byte("JFIF\x00\x01\x01\x00\x00\x01\x00\x01\x00\x00")},
+		{markerAPP1, exif},
+		{markerAPP1, []byte("http://ns.adobe.com/xap/1.0/\x00<x:xmpmeta><dc:creator>Jane Secret</dc:creator></x:xmpmeta>")},
+		{markerAPP2, []byte("ICC_PROFILE\x00\x01\x01fake profile")},
+		{0xed, []byte("Photoshop 3.0\x008BIM\x04\x04IPTC byline: Jane Secret")},
+		{markerCOM, []byte("Secret comment")},
+	}
+}
+
+func assertSameJPEGPixels(t *testing.T, want, got []byte) {
+	t.Helper()
+
+	wantImg, err := jpeg.Decode(bytes.NewReader(want))
+	if err != nil {
+		t.Fatalf("decode original: %v", err)
+	}
+	gotImg, err := jpeg.Decode(bytes.NewReader(got))
+	if err != nil {
+		t.Fatalf("decode stripped: %v", err)
+	}
+	if gotImg.Bounds() != wantImg.Bounds() {
+		t.Fatalf("stripped bounds %v, want %v", gotImg.Bounds(), wantImg.Bounds())
+	}
+	bounds := wantImg.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if color.RGBAModel.Convert(gotImg.At(x, y)) != color.RGBAModel.Convert(wantImg.At(x, y)) {
+				t.Fatalf("pixel (%d, %d) differs after stripping", x, y)
+			}
+		}
+	}
+}
+
+func TestStripJPEGRemovesMetadata(t *testing.T) {
+	for _, order := range []binary.ByteOrder{binary.BigEndian, binary.LittleEndian} {
+		t.Run(order.String(), func(t *testing.T) {
+			original := testJPEG(t, 48, 32)
+			content := withJPEGSegments(original, metadataSegments(exifWithGPS(order, 6)), []byte("trailing data"))
+
+			var stripped bytes.Buffer
+			if err := Strip(&stripped, bytes.NewReader(content), "image/jpeg"); err != nil {
+				t.Fatalf("Strip: %v", err)
+			}
+
+			for _, secret := range []string{"SecretCam", "Jane Secret", "Secret comment", "xmpmeta", "trailing data"} {
+				if bytes.Contains(stripped.Bytes(), []byte(secret)) {
+					t.Errorf("stripped JPEG still contains %q", secret)
+				}
+			}
+
+			var markers []byte
+			for _, segment := range readJPEGSegments(t, stripped.Bytes()) {
+				markers = append(markers, segment.marker)
+				switch segment.marker {
+				case markerAPP1:
+					// The EXIF segment holds the orientation alone, without the GPS IFD
+					if !bytes.Equal(segment.payload, orientationEXIF(6)) {
+						t.Errorf("EXIF segment = %q, want the orientation alone", segment.payload)
+					}
+				case markerAPP2:
+					if !bytes.HasPrefix(segment.payload, iccPrefix) {
+						t.Errorf("unexpected APP2 segment %q", segment.payload)
+					}
+				}
+			}
+			for _, want := range []byte{markerAPP0, markerAPP1, markerAPP2} {
+				if bytes.Count(markers, []byte{want}) != 1 {
+					t.Errorf("stripped JPEG has segments %x, want exactly one %x", markers, want)
+				}
+			}
+			if bytes.IndexByte(markers, 0xed) >= 0 || bytes.IndexByte(markers, markerCOM) >= 0 {
+				t.Errorf("stripped JPEG has segments %x, want no IPTC or comment", markers)
+			}
+
+			// Orientation 6 rotates the image, so it is displayed 32 pixels wide
+			metadata, err := Extract(bytes.NewReader(stripped.Bytes()), "image/jpeg")
+			if err != nil {
+				t.Fatalf("Extract: %v", err)
+			}
+			if metadata.Width != 32 || metadata.Height != 48 {
+				t.Errorf("Extract = %dx%d, want 32x48", metadata.Width, metadata.Height)
+			}
+
+			assertSameJPEGPixels(t, original, stripped.Bytes())
+		})
+	}
+}
+
+func TestStripJPEGDropsDefaultOrientation(t *testing.T) {
+	original := testJPEG(t, 16, 16)
+	content := withJPEGSegments(original, []jpegSegment{{markerAPP1, exifWithGPS(binary.BigEndian, 1)}}, nil)
+
+	var stripped bytes.Buffer
+	if err := Strip(&stripped, bytes.NewReader(content), "image/jpeg"); err != nil {
+		t.Fatalf("Strip: %v", err)
+	}
+	for _, segment := range readJPEGSegments(t, stripped.Bytes()) {
+		if segment.marker == markerAPP1 {
+			t.Errorf("stripped JPEG kept an EXIF segment %q for the default orientation", segment.payload)
+		}
+	}
+	assertSameJPEGPixels(t, original, stripped.Bytes())
+}
+
+func TestStripJPEGWithoutMetadataIsUnchanged(t *testing.T) {
+	original := testJPEG(t, 40, 24)
+
+	var stripped bytes.Buffer
+	if err := Strip(&stripped, bytes.NewReader(original), "image/jpeg"); err != nil {
+		t.Fatalf("Strip: %v", err)
+	}
+	if !bytes.Equal(stripped.Bytes(), original) {
+		t.Error("stripping a JPEG without metadata changed it")
+	}
+}
+
+func TestStripJPEGMalformed(t *testing.T) {
+	original := testJPEG(t, 16, 16)
+
+	for name, content := range map[string][]byte{
+		"not a JPEG": []byte("GIF89a"),
+		"truncated":  original[:len(original)/2],
+	} {
+		if err := Strip(&bytes.Buffer{}, bytes.NewReader(content), "image/jpeg"); !errors.Is(err, ErrMalformed) {
+			t.Errorf("%s: Strip error = %v, want ErrMalformed", name, err)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package filemeta
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"regexp"
+	"strconv"
+)
+
+// maxPDFSize bounds the documents, and the decompressed object streams, searched for their page count
+const maxPDFSize = 64 << 20
+
+var (
+	pdfPagesType  = regexp.MustCompile(`/Type\s*/Pages\b`)
+	pdfPageType   = regexp.MustCompile(`/Type\s*/Page\b`)
+	pdfCount      = regexp.MustCompile(`/Count\s+(\d+)`)
+	pdfObjectStm  = regexp.MustCompile(`/Type\s*/ObjStm\b`)
+	pdfStreamData = regexp.MustCompile(`stream\r?\n`)
+)
+
+// extractPDF counts the pages of a document. The count is read from the page tree root, the pages node
+// with the highest count, without parsing the cross-reference table; objects in compressed object
+// streams are searched too. Encrypted documents and documents over maxPDFSize get no page count.
+func extractPDF(r io.Reader) (*Metadata, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxPDFSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		return nil, ErrMalformed
+	}
+	if len(data) > maxPDFSize {
+		return &Metadata{}, nil
+	}
+
+	pages := pdfPageCount(data)
+	budget := maxPDFSize
+	for _, loc := range pdfObjectStm.FindAllIndex(data, -1) {
+		if budget <= 0 {
+			break
+		}
+		objects := inflatePDFStream(data[loc[1]:], budget)
+		budget -= len(objects)
+		pages = max(pages, pdfPageCount(objects))
+	}
+	if pages == 0 {
+		// No page tree found: count the page objects instead
+		pages = len(pdfPageType.FindAllIndex(data, -1))
+	}
+
+	return &Metadata{Pages: pages}, nil
+}
+
+// pdfPageCount returns the highest /Count of the pages nodes in data
+func pdfPageCount(data []byte) int {
+	pages := 0
+	for _, loc := range pdfPagesType.FindAllIndex(data, -1) {
+		start, end := pdfDictionary(data, loc[0])
+		if match := pdfCount.FindSubmatch(data[start:end]); match != nil {
+			if count, err := strconv.Atoi(string(match[1])); err == nil {
+				pages = max(pages, count)
+			}
+		}
+	}
+	return pages
+}
+
+// pdfDictionary returns the bounds of the innermost dictionary, << ... >>, around offset
+func pdfDictionary(data []byte, offset int) (int, int) {
+	start, depth := 0, 0
+	for i := offset - 1; i > 0; i-- {
+		if data[i-1] == '>' && data[i] == '>' {
+			depth++
+			i--
+		} else if data[i-1] == '<' && data[i] == '<' {
+			if depth == 0 {
+				start = i - 1
+				break
+			}
+			depth--
+			i--
+		}
+	}
+
+	end, depth := len(data), 0
+	for i := offset; i+1 < len(data); i++ {
+		if data[i] == '<' && data[i+1] == '<' {
+			depth++
+			i++
+		} else if data[i] == '>' && data[i+1] == '>' {
+			if depth == 0 {
+				end = i + 2
+				break
+			}
+			depth--
+			i++
+		}
+	}
+	return start, end
+}
+
+// inflatePDFStream decompresses the Flate-encoded stream following a stream dictionary, returning
+// nothing for streams that are not Flate-encoded or cannot be decompressed, such as encrypted ones
+func inflatePDFStream(data []byte, limit int) []byte {
+	loc := pdfStreamData.FindIndex(data)
+	if loc == nil {
+		return nil
+	}
+	zr, err := zlib.NewReader(bytes.NewReader(data[loc[1]:]))
+	if err != nil {
+		return nil
+	}
+	defer zr.Close()
+
+	objects, _ := io.ReadAll(io.LimitReader(zr, int64(limit)))
+	return objects
+}
@@ -0,0 +1,69 @@
+package filemeta
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+)
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// pngMetadataChunks are the ancillary chunks holding text (including XMP), EXIF data and timestamps
+var pngMetadataChunks = map[string]bool{
+	"tEXt": true,
+	"zTXt": true,
+	"iTXt": true,
+	"eXIf": true,
+	"tIME": true,
+}
+
+func stripPNG(w io.Writer, r io.Reader) error {
+	signature := make([]byte, len(pngSignature))
+	if _, err := io.ReadFull(r, signature); err != nil || !bytes.Equal(signature, pngSignature) {
+		return ErrMalformed
+	}
+	if _, err := w.Write(signature); err != nil {
+		return err
+	}
+
+	for {
+		var header [8]byte
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			return truncated(err)
+		}
+		length := int64(binary.BigEndian.Uint32(header[:4]))
+		chunkType := string(header[4:])
+
+		// The chunk data is followed by its CRC
+		if pngMetadataChunks[chunkType] {
+			if _, err := io.CopyN(io.Discard, r, length+4); err != nil {
+				return truncated(err)
+			}
+			continue
+		}
+		if _, err := w.Write(header[:]); err != nil {
+			return err
+		}
+		if _, err := io.CopyN(w, r, length+4); err != nil {
+			return truncated(err)
+		}
+		if chunkType == "IEND" {
+			return nil
+		}
+	}
+}
+
+// extractPNG reads the dimensions from the IHDR chunk, which comes first
+func extractPNG(r io.Reader) (*Metadata, error) {
+	var header [24]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, truncated(err)
+	}
+	if !bytes.Equal(header[:8], pngSignature) || string(header[12:16]) != "IHDR" {
+		return nil, ErrMalformed
+	}
+	return &Metadata{
+		Width:  int(binary.BigEndian.Uint32(header[16:])),
+		Height: int(binary.BigEndian.Uint32(header[20:])),
+	}, nil
+}
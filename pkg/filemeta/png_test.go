@@ -0,0 +1,158 @@
+package filemeta
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+// pngChunk is a chunk of a PNG image, without its length and CRC
+type pngChunk struct {
+	chunkType string
+	data      []byte
+}
+
+func testPNG(t *testing.T) (*image.NRGBA, []byte) {
+	t.Helper()
+
+	img := image.NewNRGBA(image.Rect(0, 0, 20, 12))
+	for y := 0; y < 12; y++ {
+		for x := 0; x < 20; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{R: uint8(x * 12), G: uint8(y * 20), B: uint8(x * y), A: uint8(255 - x)})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("png.Encode: %v", err)
+	}
+	return img, buf.Bytes()
+}
+
+func writePNGChunk(buf *bytes.Buffer, chunk pngChunk) {
+	binary.Write(buf, binary.BigEndian, uint32(len(chunk.data)))
+	buf.WriteString(chunk.chunkType)
+	buf.Write(chunk.data)
+	crc := crc32.NewIEEE()
+	crc.Write([]byte(chunk.chunkType))
+	crc.Write(chunk.data)
+	binary.Write(buf, binary.BigEndian, crc.Sum32())
+}
+
+// withPNGChunks inserts chunks right after the IHDR chunk and appends trailing data after IEND
+func withPNGChunks(content []byte, chunks []pngChunk, trailer []byte) []byte {
+	// The signature is followed by IHDR, whose 13 bytes of data make it 25 bytes long
+	ihdrEnd := len(pngSignature) + 25
+
+	var buf bytes.Buffer
+	buf.Write(content[:ihdrEnd])
+	for _, chunk := range chunks {
+		writePNGChunk(&buf, chunk)
+	}
+	buf.Write(content[ihdrEnd:])
+	buf.Write(trailer)
+	return buf.Bytes()
+}
+
+// readPNGChunkTypes lists the chunk types of a PNG image in order
+func readPNGChunkTypes(t *testing.T, content []byte) []string {
+	t.Helper()
+
+	if !bytes.HasPrefix(content, pngSignature) {
+		t.Fatal("no PNG signature")
+	}
+	var types []string
+	for rest := content[len(pngSignature):]; len(rest) > 0; {
+		if len(rest) < 12 {
+			t.Fatalf("truncated chunk of %d bytes", len(rest))
+		}
+		length := int(binary.BigEndian.Uint32(rest))
+		types = append(types, string(rest[4:8]))
+		rest = rest[12+length:]
+	}
+	return types
+}
+
+func TestStripPNGRemovesMetadata(t *testing.T) {
+	img, original := testPNG(t)
+	content := withPNGChunks(original, []pngChunk{
+		{"pHYs", []byte("\x00\x00\x0b\x13\x00\x00\x0b\x13\x01")},
+		{"tEXt", []byte("Author\x00Jane Secret")},
+		{"zTXt", []byte("Comment\x00\x00x\x9c\x0bN\x06\x00\x00\xa1\x00\xa1")},
+		{"iTXt", []byte("XML:com.adobe.xmp\x00\x00\x00\x00\x00<x:xmpmeta><dc:creator>Jane Secret</dc:creator></x:xmpmeta>")},
+		{"eXIf", exifWithGPS(binary.BigEndian, 6)[len(exifPrefix):]},
+		{"tIME", []byte("\x07\xe8\x01\x01\x0c\x00\x00")},
+	}, []byte("trailing data"))
+
+	// The metadata chunks carry valid CRCs, so the image decodes before stripping too
+	if _, err := png.Decode(bytes.NewReader(content)); err != nil {
+		t.Fatalf("decode original: %v", err)
+	}
+
+	var stripped bytes.Buffer
+	if err := Strip(&stripped, bytes.NewReader(content), "image/png"); err != nil {
+		t.Fatalf("Strip: %v", err)
+	}
+
+	for _, secret := range []string{"Jane Secret", "xmpmeta", "SecretCam", "trailing data"} {
+		if bytes.Contains(stripped.Bytes(), []byte(secret)) {
+			t.Errorf("stripped PNG still contains %q", secret)
+		}
+	}
+
+	// Chunks needed to display the image are kept in their order
+	want := append([]string{"IHDR", "pHYs"}, readPNGChunkTypes(t, original)[1:]...)
+	got := readPNGChunkTypes(t, stripped.Bytes())
+	if len(got) != len(want) {
+		t.Fatalf("stripped PNG has chunks %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("stripped PNG has chunks %v, want %v", got, want)
+		}
+	}
+
+	decoded, err := png.Decode(bytes.NewReader(stripped.Bytes()))
+	if err != nil {
+		t.Fatalf("decode stripped: %v", err)
+	}
+	if decoded.Bounds() != img.Bounds() {
+		t.Fatalf("stripped bounds %v, want %v", decoded.Bounds(), img.Bounds())
+	}
+	for y := 0; y < 12; y++ {
+		for x := 0; x < 20; x++ {
+			if got := color.NRGBAModel.Convert(decoded.At(x, y)); got != img.NRGBAAt(x, y) {
+				t.Fatalf("pixel (%d, %d) = %v, want %v", x, y, got, img.NRGBAAt(x, y))
+			}
+		}
+	}
+}
+
+func TestStripPNGWithoutMetadataIsUnchanged(t *testing.T) {
+	_, original := testPNG(t)
+
+	var stripped bytes.Buffer
+	if err := Strip(&stripped, bytes.NewReader(original), "image/png"); err != nil {
+		t.Fatalf("Strip: %v", err)
+	}
+	if !bytes.Equal(stripped.Bytes(), original) {
+		t.Error("stripping a PNG without metadata changed it")
+	}
+}
+
+func TestStripPNGMalformed(t *testing.T) {
+	_, original := testPNG(t)
+
+	for name, content := range map[string][]byte{
+		"not a PNG": []byte("GIF89a"),
+		"truncated": original[:len(original)-8],
+	} {
+		if err := Strip(&bytes.Buffer{}, bytes.NewReader(content), "image/png"); !errors.Is(err, ErrMalformed) {
+			t.Errorf("%s: Strip error = %v, want ErrMalformed", name, err)
+		}
+	}
+}
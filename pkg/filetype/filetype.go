@@ -0,0 +1,81 @@
+// Package filetype detects the type of uploaded content from its magic bytes instead of trusting the
+// Content-Type and file name chosen by the client.
+package filetype
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+	"path/filepath"
+	"strings"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+// sniffLength is the number of leading bytes the detection looks at
+const sniffLength = 3072
+
+var (
+	// ErrNotAllowed is returned when the detected type is not in the allowed list
+	ErrNotAllowed = errors.New("file type not allowed")
+	// ErrMismatch is returned when the declared type or the file extension contradicts the detected type
+	ErrMismatch = errors.New("file content does not match its declared type or extension")
+)
+
+// Detect sniffs the MIME type of the content read from r. It returns the type without parameters,
+// e.g. "text/plain", and a reader yielding the complete content including the sniffed bytes.
+func Detect(r io.Reader) (string, io.Reader, error) {
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", nil, err
+	}
+	head = head[:n]
+
+	return baseType(mimetype.Detect(head).String()), io.MultiReader(bytes.NewReader(head), r), nil
+}
+
+// Validate checks detected content against the allowed types, the type declared by the client and
+// the extension of the file name. An empty or application/octet-stream declaration counts as none,
+// as does a name without extension.
+func Validate(detected, declared, name string, allowed []string) error {
+	detectedMIME := mimetype.Lookup(detected)
+	if detectedMIME == nil {
+		return ErrNotAllowed
+	}
+
+	isAllowed := false
+	for _, allowedType := range allowed {
+		if detectedMIME.Is(allowedType) {
+			isAllowed = true
+			break
+		}
+	}
+	if !isAllowed {
+		return ErrNotAllowed
+	}
+
+	if declared = baseType(declared); declared != "" && declared != "application/octet-stream" && !detectedMIME.Is(declared) {
+		return ErrMismatch
+	}
+
+	if ext := strings.ToLower(filepath.Ext(name)); ext != "" && ext != detectedMIME.Extension() {
+		// The canonical extension is .jpg, yet .jpeg is just as common
+		extType := mime.TypeByExtension(ext)
+		if extType == "" || !detectedMIME.Is(extType) {
+			return ErrMismatch
+		}
+	}
+
+	return nil
+}
+
+// baseType strips parameters such as charset from a MIME type
+func baseType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(contentType))
+	}
+	return mediaType
+}
@@ -0,0 +1,115 @@
+package filetype
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+var (
+	pngContent  = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01\x08\x02\x00\x00\x00\x90wS\xde")
+	jpegContent = []byte("\xff\xd8\xff\xe0\x00\x10JFIF\x00\x01\x01\x00\x00\x01\x00\x01\x00\x00")
+	pdfContent  = []byte("%PDF-1.4\n1 0 obj\n<< /Type /Catalog >>\nendobj\n")
+	htmlContent = []byte("<!DOCTYPE html><html><head><script>alert(1)</script></head><body></body></html>")
+	textContent = []byte("Quarterly numbers, as discussed.\n")
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name    string
+		content []byte
+		want    string
+	}{
+		{"png", pngContent, "image/png"},
+		{"jpeg", jpegContent, "image/jpeg"},
+		{"pdf", pdfContent, "application/pdf"},
+		{"html", htmlContent, "text/html"},
+		{"text without charset", textContent, "text/plain"},
+		{"longer than the sniffed bytes", append(bytes.Repeat([]byte("a"), sniffLength), "tail"...), "text/plain"},
+		{"empty", nil, "text/plain"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detected, r, err := Detect(bytes.NewReader(tt.content))
+			if err != nil {
+				t.Fatalf("Detect: %v", err)
+			}
+			if detected != tt.want {
+				t.Errorf("Detect = %q, want %q", detected, tt.want)
+			}
+
+			// The sniffed bytes are not lost
+			content, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("read content: %v", err)
+			}
+			if !bytes.Equal(content, tt.content) {
+				t.Errorf("content read after Detect has %d bytes, want the %d bytes of the upload", len(content), len(tt.content))
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	allowed := []string{"image/jpeg", "image/png", "application/pdf", "text/plain"}
+
+	tests := []struct {
+		name     string
+		content  []byte
+		declared string
+		fileName string
+		allowed  []string
+		wantErr  error
+	}{
+		{"matching declaration and extension", pngContent, "image/png", "photo.png", allowed, nil},
+		{"html declared as image/png", htmlContent, "image/png", "photo.png", allowed, ErrNotAllowed},
+		{"allowed html declared as image/png", htmlContent, "image/png", "page.html", append(allowed, "text/html"), ErrMismatch},
+		{"declared type of other allowed content", pngContent, "image/jpeg", "photo", allowed, ErrMismatch},
+		{"extension of other content", pngContent, "image/png", "photo.jpg", allowed, ErrMismatch},
+		{"pdf renamed to text", pdfContent, "", "report.txt", allowed, ErrMismatch},
+		{"unknown extension", pngContent, "image/png", "photo.xyz123", allowed, ErrMismatch},
+		{"canonical jpeg extension", jpegContent, "image/jpeg", "photo.jpg", allowed, nil},
+		{"jpeg extension", jpegContent, "image/jpeg", "photo.jpeg", allowed, nil},
+		{"upper case extension", jpegContent, "image/jpeg", "PHOTO.JPEG", allowed, nil},
+		{"no extension", jpegContent, "image/jpeg", "photo", allowed, nil},
+		{"empty declaration", pdfContent, "", "report.pdf", allowed, nil},
+		{"octet-stream declaration", pdfContent, "application/octet-stream", "report.pdf", allowed, nil},
+		{"declaration with parameters", textContent, "text/plain; charset=utf-8", "notes.txt", allowed, nil},
+		{"detected type not allowed", pdfContent, "application/pdf", "report.pdf", []string{"image/jpeg", "image/png"}, ErrNotAllowed},
+		{"nothing allowed", pngContent, "image/png", "photo.png", nil, ErrNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detected, _, err := Detect(bytes.NewReader(tt.content))
+			if err != nil {
+				t.Fatalf("Detect: %v", err)
+			}
+
+			if err := Validate(detected, tt.declared, tt.fileName, tt.allowed); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate(%q, %q, %q) error %v, want %v", detected, tt.declared, tt.fileName, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateUnknownDetectedType(t *testing.T) {
+	if err := Validate("application/x-made-up", "", "file", []string{"application/x-made-up"}); !errors.Is(err, ErrNotAllowed) {
+		t.Errorf("Validate of an unknown type: error %v, want %v", err, ErrNotAllowed)
+	}
+}
+
+func TestBaseType(t *testing.T) {
+	tests := map[string]string{
+		"text/plain; charset=utf-8": "text/plain",
+		"Image/PNG":                 "image/png",
+		" image/png ":               "image/png",
+		"":                          "",
+		"not a type;;":              "not a type;;",
+	}
+	for contentType, want := range tests {
+		if got := baseType(contentType); got != want {
+			t.Errorf("baseType(%q) = %q, want %q", contentType, got, want)
+		}
+	}
+}
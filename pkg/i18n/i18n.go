@@ -0,0 +1,116 @@
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-playground/locales"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/es"
+	"github.com/go-playground/locales/fr"
+	"golang.org/x/text/language"
+)
+
+// DefaultLocale is used when neither the user nor the request asks for a supported locale
+const DefaultLocale = "en"
+
+// catalogues holds the translations of every supported locale except the default one. Messages are
+// keyed by their English text, so a missing translation falls back to readable English.
+var catalogues = map[string]map[string]string{
+	"es": messagesES,
+	"fr": messagesFR,
+}
+
+// translators format dates and numbers following each locale's CLDR rules
+var translators = map[string]locales.Translator{
+	"en": en.New(),
+	"es": es.New(),
+	"fr": fr.New(),
+}
+
+// The first tag is the fallback of the matcher
+var matcher = language.NewMatcher([]language.Tag{
+	language.English,
+	language.Spanish,
+	language.French,
+})
+
+type contextKey struct{}
+
+// Locales returns the supported locales, default first
+func Locales() []string {
+	return []string{"en", "es", "fr"}
+}
+
+// Translator returns the CLDR translator of a supported locale, or of the default locale
+func Translator(locale string) locales.Translator {
+	if translator, ok := translators[locale]; ok {
+		return translator
+	}
+	return translators[DefaultLocale]
+}
+
+// Negotiate picks the supported locale that best matches an Accept-Language header
+func Negotiate(acceptLanguage string) string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return DefaultLocale
+	}
+	_, index, _ := matcher.Match(tags...)
+	return Locales()[index]
+}
+
+// Match maps a BCP 47 tag such as es-MX to a supported locale, reporting false when none matches
+func Match(tag string) (string, bool) {
+	parsed, err := language.Parse(tag)
+	if err != nil {
+		return "", false
+	}
+	_, index, confidence := matcher.Match(parsed)
+	if confidence == language.No {
+		return "", false
+	}
+	return Locales()[index], true
+}
+
+// WithLocale returns a copy of ctx carrying the locale responses and emails are rendered in
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, contextKey{}, locale)
+}
+
+// FromContext returns the locale stored in ctx, or the default locale
+func FromContext(ctx context.Context) string {
+	if locale, ok := ctx.Value(contextKey{}).(string); ok && locale != "" {
+		return locale
+	}
+	return DefaultLocale
+}
+
+// T translates an English message into locale and formats it with args
+func T(locale, message string, args ...interface{}) string {
+	if translated, ok := catalogues[locale][message]; ok {
+		message = translated
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(message, args...)
+	}
+	return message
+}
+
+// TPrefix translates an English message that may be followed by untranslated context, such as
+// "invalid filter: unknown field \"x\"", keeping the context as is
+func TPrefix(locale, message, prefix string) string {
+	if rest, ok := strings.CutPrefix(message, prefix); ok {
+		return T(locale, prefix) + rest
+	}
+	return T(locale, message)
+}
+
+// FormatDateTime formats a point in time in UTC the way locale writes long dates
+func FormatDateTime(locale string, t time.Time) string {
+	translator := Translator(locale)
+	t = t.UTC()
+	return translator.FmtDateLong(t) + " " + translator.FmtTimeShort(t) + " UTC"
+}
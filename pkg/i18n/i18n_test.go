@@ -0,0 +1,26 @@
+package i18n
+
+import "testing"
+
+// TestCatalogsTranslateTheSameMessages catches a message added to one language but not the other
+func TestCatalogsTranslateTheSameMessages(t *testing.T) {
+	catalogs := map[string]map[string]string{"es": messagesES, "fr": messagesFR}
+
+	for locale, catalog := range catalogs {
+		for other, otherCatalog := range catalogs {
+			if other == locale {
+				continue
+			}
+			for message := range catalog {
+				if _, ok := otherCatalog[message]; !ok {
+					t.Errorf("%q is translated to %s but not to %s", message, locale, other)
+				}
+			}
+		}
+		for message, translation := range catalog {
+			if translation == "" {
+				t.Errorf("%q has an empty %s translation", message, locale)
+			}
+		}
+	}
+}
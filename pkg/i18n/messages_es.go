@@ -0,0 +1,238 @@
+package i18n
+
+// messagesES is the Spanish catalogue
+var messagesES = map[string]string{
+	// HTTP status titles
+	"Bad Request":              "Solicitud incorrecta",
+	"Unauthorized":             "No autorizado",
+	"Forbidden":                "Prohibido",
+	"Not Found":                "No encontrado",
+	"Method Not Allowed":       "Método no permitido",
+	"Conflict":                 "Conflicto",
+	"Gone":                     "Ya no disponible",
+	"Request Entity Too Large": "Entidad de solicitud demasiado grande",
+	"Unsupported Media Type":   "Tipo de medio no admitido",
+	"Unprocessable Entity":     "Entidad no procesable",
+	"Too Many Requests":        "Demasiadas solicitudes",
+	"Internal Server Error":    "Error interno del servidor",
+	"Service Unavailable":      "Servicio no disponible",
+	"Precondition Failed":      "Condición previa fallida",
+
+	// Responses
+	"Avatar is required":          "El avatar es obligatorio",
+	"Avatar updated successfully": "Avatar actualizado correctamente",
+	"Bulk delete completed":       "Eliminación masiva completada",
+	"Bulk update completed":       "Actualización masiva completada",
+	"Data export queued. You will receive an email with a download link when it is ready.": "Exportación de datos en cola. Recibirás un correo con un enlace de descarga cuando esté lista.",
+	"File access granted successfully":      "Acceso al archivo concedido correctamente",
+	"File access revoked successfully":      "Acceso al archivo revocado correctamente",
+	"File deleted successfully":             "Archivo eliminado correctamente",
+	"File grants retrieved successfully":    "Permisos del archivo obtenidos correctamente",
+	"File is required":                      "El archivo es obligatorio",
+	"File moved successfully":               "Archivo movido correctamente",
+	"File not found":                        "Archivo no encontrado",
+	"File retrieved successfully":           "Archivo obtenido correctamente",
+	"File updated successfully":             "Archivo actualizado correctamente",
+	"File uploaded successfully":            "Archivo subido correctamente",
+	"File version restored successfully":    "Versión del archivo restaurada correctamente",
+	"File version uploaded successfully":    "Versión del archivo subida correctamente",
+	"File versions retrieved successfully":  "Versiones del archivo obtenidas correctamente",
+	"Filename is required":                  "El nombre de archivo es obligatorio",
+	"Files retrieved successfully":          "Archivos obtenidos correctamente",
+	"Folder created successfully":           "Carpeta creada correctamente",
+	"Folder deleted successfully":           "Carpeta eliminada correctamente",
+	"Folder moved successfully":             "Carpeta movida correctamente",
+	"Folder renamed successfully":           "Carpeta renombrada correctamente",
+	"Folder retrieved successfully":         "Carpeta obtenida correctamente",
+	"Folders retrieved successfully":        "Carpetas obtenidas correctamente",
+	"Import file validated successfully":    "Archivo de importación validado correctamente",
+	"Inactive users retrieved successfully": "Usuarios inactivos obtenidos correctamente",
+	"Insufficient permissions":              "Permisos insuficientes",
+	"Internal server error":                 "Error interno del servidor",
+	"Invalid days parameter":                "Parámetro days no válido",
+	"Invalid file ID":                       "ID de archivo no válido",
+	"Invalid file ID or version":            "ID de archivo o versión no válidos",
+	"Invalid folder ID":                     "ID de carpeta no válido",
+	"Content-Length header is required":     "La cabecera Content-Length es obligatoria",
+	"Invalid Upload-Length header":          "Cabecera Upload-Length no válida",
+	"Invalid Upload-Metadata header":        "Cabecera Upload-Metadata no válida",
+	"Invalid Upload-Offset header":          "Cabecera Upload-Offset no válida",
+	"Upload-Defer-Length is not supported":  "Upload-Defer-Length no está admitido",
+	"Invalid password reset token format":   "Formato de token de restablecimiento no válido",
+	"Invalid request body":                  "Cuerpo de la solicitud no válido",
+	"Invalid request":                       "Solicitud no válida",
+	"Invalid resource ID":                   "ID de recurso no válido",
+	"Invalid share link ID":                 "ID de enlace compartido inválido",
+	"Invalid user ID":                       "ID de usuario no válido",
+	"Login history retrieved successfully":  "Historial de inicios de sesión obtenido correctamente",
+	"Login successful":                      "Inicio de sesión correcto",
+	"No file provided":                      "No se ha proporcionado ningún archivo",
+	"Password reset token is required":      "El token de restablecimiento de contraseña es obligatorio",
+	"Password reset token is valid. Please use POST method with your new password to complete the reset.": "El token de restablecimiento es válido. Usa el método POST con tu nueva contraseña para completar el restablecimiento.",
+	"Profile retrieved successfully":               "Perfil obtenido correctamente",
+	"Rate limit exceeded. Please try again later.": "Límite de solicitudes superado. Inténtalo de nuevo más tarde.",
+	"Settings retrieved successfully":              "Preferencias obtenidas correctamente",
+	"Settings updated successfully":                "Preferencias actualizadas correctamente",
+	"Share link created successfully":              "Enlace compartido creado correctamente",
+	"Share link revoked successfully":              "Enlace compartido revocado correctamente",
+	"Share links retrieved successfully":           "Enlaces compartidos obtenidos correctamente",
+	"Storage quota updated successfully":           "Cuota de almacenamiento actualizada correctamente",
+	"Storage usage retrieved successfully":         "Uso de almacenamiento obtenido correctamente",
+	"Token refreshed successfully":                 "Token renovado correctamente",
+	"User created successfully":                    "Usuario creado correctamente",
+	"User deleted successfully":                    "Usuario eliminado correctamente",
+	"User not authenticated":                       "Usuario no autenticado",
+	"User not found":                               "Usuario no encontrado",
+	"User registered successfully":                 "Usuario registrado correctamente",
+	"User retrieved successfully":                  "Usuario obtenido correctamente",
+	"User updated successfully":                    "Usuario actualizado correctamente",
+	"Users imported successfully":                  "Usuarios importados correctamente",
+	"Users retrieved successfully":                 "Usuarios obtenidos correctamente",
+	"Validation failed":                            "La validación ha fallado",
+
+	// Service results
+	"Account deletion scheduled. You can cancel it until the scheduled date.": "Eliminación de la cuenta programada. Puedes cancelarla hasta la fecha prevista.",
+	"Account deletion cancelled":  "Eliminación de la cuenta cancelada",
+	"Email is already verified":   "El correo electrónico ya está verificado",
+	"Email verified successfully": "Correo electrónico verificado correctamente",
+	"If your email is registered, you will receive a password reset link shortly": "Si tu correo electrónico está registrado, recibirás en breve un enlace para restablecer la contraseña",
+	"Invalid or expired password reset token":                                     "Token de restablecimiento de contraseña no válido o caducado",
+	"Invalid or expired verification token":                                       "Token de verificación no válido o caducado",
+	"Password reset failed":                                                       "No se pudo restablecer la contraseña",
+	"Password reset successfully":                                                 "Contraseña restablecida correctamente",
+	"Password reset token has expired":                                            "El token de restablecimiento de contraseña ha caducado",
+	"Verification email sent successfully":                                        "Correo de verificación enviado correctamente",
+	"Verification failed":                                                         "La verificación ha fallado",
+	"Verification token has expired":                                              "El token de verificación ha caducado",
+	"Email is a duplicate of line %d":                                             "El correo electrónico está duplicado en la línea %d",
+	"failed to create user":                                                       "no se pudo crear el usuario",
+	"line is not a valid JSON object":                                             "la línea no es un objeto JSON válido",
+
+	// Errors
+	"authorization header required":                               "se requiere la cabecera Authorization",
+	"authorization header must start with 'Bearer '":              "la cabecera Authorization debe empezar por 'Bearer '",
+	"token cannot be empty":                                       "el token no puede estar vacío",
+	"invalid token":                                               "token no válido",
+	"token has expired":                                           "el token ha caducado",
+	"validation failed":                                           "la validación ha fallado",
+	"invalid password":                                            "contraseña incorrecta",
+	"account deletion is already scheduled":                       "la eliminación de la cuenta ya está programada",
+	"account deletion is not scheduled":                           "la eliminación de la cuenta no está programada",
+	"a data export is already in progress":                        "ya hay una exportación de datos en curso",
+	"invalid or expired data export link":                         "enlace de exportación de datos no válido o caducado",
+	"metadata must be a JSON object within the size limit":        "los metadatos deben ser un objeto JSON dentro del límite de tamaño",
+	"avatar must be a JPEG, PNG or GIF image":                     "el avatar debe ser una imagen JPEG, PNG o GIF",
+	"avatar is not a valid image":                                 "el avatar no es una imagen válida",
+	"avatar image dimensions are too large":                       "las dimensiones del avatar son demasiado grandes",
+	"invalid email or password":                                   "correo electrónico o contraseña incorrectos",
+	"invalid refresh token":                                       "token de renovación no válido",
+	"user with this email already exists":                         "ya existe un usuario con este correo electrónico",
+	"invalid or expired verification token":                       "token de verificación no válido o caducado",
+	"email is already verified":                                   "el correo electrónico ya está verificado",
+	"invalid or expired password reset token":                     "token de restablecimiento de contraseña no válido o caducado",
+	"user not found":                                              "usuario no encontrado",
+	"invalid import file":                                         "archivo de importación no válido",
+	"import file exceeds the maximum number of rows":              "el archivo de importación supera el número máximo de filas",
+	"unsupported format, expected csv or ndjson":                  "formato no admitido, se esperaba csv o ndjson",
+	"file not found":                                              "archivo no encontrado",
+	"file size exceeds maximum allowed size":                      "el tamaño del archivo supera el máximo permitido",
+	"file type not allowed":                                       "tipo de archivo no permitido",
+	"file content does not match its declared type or extension":  "el contenido del archivo no coincide con su tipo declarado o su extensión",
+	"failed to store file":                                        "no se pudo guardar el archivo",
+	"invalid or missing file URL signature":                       "firma de la URL del archivo no válida o ausente",
+	"file URL has expired":                                        "la URL del archivo ha caducado",
+	"file variant not found":                                      "variante de archivo no encontrada",
+	"image cannot be processed":                                   "no se puede procesar la imagen",
+	"file is still being scanned for malware":                     "el archivo todavía se está analizando en busca de malware",
+	"file is quarantined because malware was found":               "el archivo está en cuarentena porque se encontró malware",
+	"file is blocked because it could not be scanned for malware": "el archivo está bloqueado porque no se pudo analizar en busca de malware",
+	"stored file content is damaged; upload the file again":       "el contenido almacenado del archivo está dañado; vuelve a subir el archivo",
+	"file version not found":                                      "versión del archivo no encontrada",
+	"file was changed by another request":                         "el archivo fue modificado por otra solicitud",
+	"storage quota exceeded":                                      "se ha superado la cuota de almacenamiento",
+	"file belongs to another user":                                "el archivo pertenece a otro usuario",
+	"only the owner of a file or a moderator can make it public":  "solo el propietario de un archivo o un moderador puede hacerlo público",
+	"folder not found":                                            "carpeta no encontrada",
+	"a folder with this name already exists":                      "ya existe una carpeta con este nombre",
+	"folder is not empty":                                         "la carpeta no está vacía",
+	"a folder cannot be moved into itself or its subfolders":      "una carpeta no se puede mover a sí misma ni a sus subcarpetas",
+	"share link not found":                                        "enlace compartido no encontrado",
+	"share link has expired or reached its download limit":        "el enlace compartido ha caducado o alcanzó su límite de descargas",
+	"share link password is missing or incorrect":                 "falta la contraseña del enlace compartido o es incorrecta",
+	"expiry of a share link must be in the future":                "la caducidad de un enlace compartido debe estar en el futuro",
+	"file is not shared with this user":                           "el archivo no está compartido con este usuario",
+	"files cannot be shared with their owner":                     "los archivos no se pueden compartir con su propietario",
+	"upload not found":                                            "subida no encontrada",
+	"upload exceeds the maximum allowed size":                     "la subida supera el tamaño máximo permitido",
+	"upload offset does not match the current offset":             "el desplazamiento de la subida no coincide con el actual",
+	"chunk exceeds the declared upload length":                    "el fragmento supera la longitud declarada de la subida",
+	"chunk ended before its declared length":                      "el fragmento terminó antes de su longitud declarada",
+	"unsupported tus protocol version":                            "versión del protocolo tus no admitida",
+	"chunks must be sent as application/offset+octet-stream":      "los fragmentos deben enviarse como application/offset+octet-stream",
+	"invalid filter":                                              "filtro no válido",
+	"invalid or tampered pagination cursor":                       "cursor de paginación no válido o manipulado",
+	"unknown expansion":                                           "expansión desconocida",
+
+	// Validation
+	"{0} is required":                                                "{0} es obligatorio",
+	"{0} must be a valid email":                                      "{0} debe ser un correo electrónico válido",
+	"{0} must be at least {1} characters":                            "{0} debe tener al menos {1} caracteres",
+	"{0} must be at most {1} characters":                             "{0} debe tener como máximo {1} caracteres",
+	"{0} must be one of: {1}":                                        "{0} debe ser uno de: {1}",
+	"{0} must be a valid BCP 47 language tag (e.g. en-US)":           "{0} debe ser una etiqueta de idioma BCP 47 válida (p. ej. es-ES)",
+	"{0} must be a valid IANA time zone (e.g. Europe/Berlin)":        "{0} debe ser una zona horaria IANA válida (p. ej. Europe/Madrid)",
+	"{0} must be a phone number in E.164 format (e.g. +14155552671)": "{0} debe ser un número de teléfono en formato E.164 (p. ej. +34912345678)",
+	"{0} must be at least 8 characters and contain uppercase, lowercase, number, and special character": "{0} debe tener al menos 8 caracteres y contener mayúsculas, minúsculas, números y caracteres especiales",
+	"{0} is invalid": "{0} no es válido",
+
+	// Settings
+	"%s is not a known setting":  "%s no es una preferencia conocida",
+	"%s cannot be changed":       "%s no se puede cambiar",
+	"%s must be a boolean":       "%s debe ser un booleano",
+	"%s must be a string":        "%s debe ser una cadena",
+	"%s must be one of: %v":      "%s debe ser uno de: %v",
+	"%s must be an integer":      "%s debe ser un número entero",
+	"%s must be at least %d":     "%s debe ser como mínimo %d",
+	"%s must be at most %d":      "%s debe ser como máximo %d",
+	"%s has an unsupported type": "%s tiene un tipo no admitido",
+	"Security emails such as password resets and account deletion notices. Always on.":     "Correos de seguridad, como restablecimientos de contraseña y avisos de eliminación de la cuenta. Siempre activados.",
+	"Emails about actions you requested, such as data exports and invitations. Always on.": "Correos sobre acciones que has solicitado, como exportaciones de datos e invitaciones. Siempre activados.",
+	"Product news and announcements.":            "Novedades y anuncios del producto.",
+	"Color theme of the web interface.":          "Tema de color de la interfaz web.",
+	"Default number of items per page in lists.": "Número predeterminado de elementos por página en las listas.",
+
+	// Emails
+	"Hi %s,": "Hola, %s:",
+	"If the button doesn't work, you can copy and paste this link into your browser:": "Si el botón no funciona, copia y pega este enlace en tu navegador:",
+	"© 2025 Go Template. All rights reserved.":                                        "© 2025 Go Template. Todos los derechos reservados.",
+	"Verify Your Email Address":                                                       "Verifica tu dirección de correo electrónico",
+	"Verify Your Email":                                                               "Verifica tu correo electrónico",
+	"Welcome to Go Template!":                                                         "¡Te damos la bienvenida a Go Template!",
+	"Thank you for registering with Go Template! To complete your registration, please verify your email address by clicking the button below:": "¡Gracias por registrarte en Go Template! Para completar el registro, verifica tu dirección de correo electrónico pulsando el botón siguiente:",
+	"Verify My Email": "Verificar mi correo",
+	"This verification link will expire in 24 hours for security reasons.": "Por motivos de seguridad, este enlace de verificación caducará en 24 horas.",
+	"If you didn't create an account with us, please ignore this email.":   "Si no has creado una cuenta con nosotros, ignora este correo.",
+	"Reset Your Password":    "Restablece tu contraseña",
+	"Password Reset Request": "Solicitud de restablecimiento de contraseña",
+	"We received a request to reset your password. If you made this request, click the button below to reset your password:": "Hemos recibido una solicitud para restablecer tu contraseña. Si la has hecho tú, pulsa el botón siguiente para restablecerla:",
+	"Reset My Password": "Restablecer mi contraseña",
+	"This password reset link will expire in 24 hours for security reasons.":                                 "Por motivos de seguridad, este enlace de restablecimiento caducará en 24 horas.",
+	"If you didn't request a password reset, please ignore this email. Your password will remain unchanged.": "Si no has solicitado restablecer la contraseña, ignora este correo. Tu contraseña no cambiará.",
+	"Your Data Export Is Ready": "Tu exportación de datos está lista",
+	"The export of your personal data you requested has been generated. It contains your profile, the metadata of your files and the files you uploaded.": "Se ha generado la exportación de datos personales que solicitaste. Contiene tu perfil, los metadatos de tus archivos y los archivos que subiste.",
+	"Download My Data":                      "Descargar mis datos",
+	"This download link will expire on %s.": "Este enlace de descarga caducará el %s.",
+	"If you didn't request a data export, please change your password immediately.": "Si no has solicitado una exportación de datos, cambia tu contraseña inmediatamente.",
+	"Your Account Is Scheduled For Deletion":                                        "Tu cuenta está programada para su eliminación",
+	"Account Deletion Scheduled":                                                    "Eliminación de la cuenta programada",
+	"We received a request to delete your account. Your account and all of your files will be permanently deleted on %s.": "Hemos recibido una solicitud para eliminar tu cuenta. Tu cuenta y todos tus archivos se eliminarán de forma permanente el %s.",
+	"Until then you can still sign in and cancel the deletion from your account settings.":                                "Hasta entonces, puedes iniciar sesión y cancelar la eliminación desde la configuración de tu cuenta.",
+	"If you didn't request this, please sign in, cancel the deletion and change your password immediately.":               "Si no lo has solicitado, inicia sesión, cancela la eliminación y cambia tu contraseña inmediatamente.",
+	"You Have Been Invited":  "Has recibido una invitación",
+	"Welcome to Go Template": "Te damos la bienvenida a Go Template",
+	"An account has been created for you. To get started, please choose a password by clicking the button below:": "Se ha creado una cuenta para ti. Para empezar, elige una contraseña pulsando el botón siguiente:",
+	"Set My Password":                    "Establecer mi contraseña",
+	"This invitation will expire on %s.": "Esta invitación caducará el %s.",
+	"If you weren't expecting this invitation, you can safely ignore this email.": "Si no esperabas esta invitación, puedes ignorar este correo.",
+	"You can change which emails you receive in your notification settings.":      "Puedes elegir qué correos recibes en tus preferencias de notificación.",
+}
@@ -0,0 +1,238 @@
+package i18n
+
+// messagesFR is the French catalogue
+var messagesFR = map[string]string{
+	// HTTP status titles
+	"Bad Request":              "Requête incorrecte",
+	"Unauthorized":             "Non autorisé",
+	"Forbidden":                "Interdit",
+	"Not Found":                "Introuvable",
+	"Method Not Allowed":       "Méthode non autorisée",
+	"Conflict":                 "Conflit",
+	"Gone":                     "Plus disponible",
+	"Request Entity Too Large": "Entité de requête trop volumineuse",
+	"Unsupported Media Type":   "Type de média non pris en charge",
+	"Unprocessable Entity":     "Entité non traitable",
+	"Too Many Requests":        "Trop de requêtes",
+	"Internal Server Error":    "Erreur interne du serveur",
+	"Service Unavailable":      "Service indisponible",
+	"Precondition Failed":      "Échec de la précondition",
+
+	// Responses
+	"Avatar is required":          "L'avatar est obligatoire",
+	"Avatar updated successfully": "Avatar mis à jour",
+	"Bulk delete completed":       "Suppression groupée terminée",
+	"Bulk update completed":       "Mise à jour groupée terminée",
+	"Data export queued. You will receive an email with a download link when it is ready.": "Export des données en file d'attente. Vous recevrez un e-mail avec un lien de téléchargement dès qu'il sera prêt.",
+	"File access granted successfully":      "Accès au fichier accordé avec succès",
+	"File access revoked successfully":      "Accès au fichier révoqué avec succès",
+	"File deleted successfully":             "Fichier supprimé",
+	"File grants retrieved successfully":    "Autorisations du fichier récupérées avec succès",
+	"File is required":                      "Le fichier est obligatoire",
+	"File moved successfully":               "Fichier déplacé",
+	"File not found":                        "Fichier introuvable",
+	"File retrieved successfully":           "Fichier récupéré",
+	"File updated successfully":             "Fichier mis à jour",
+	"File uploaded successfully":            "Fichier téléversé",
+	"File version restored successfully":    "Version du fichier restaurée",
+	"File version uploaded successfully":    "Version du fichier téléversée",
+	"File versions retrieved successfully":  "Versions du fichier récupérées",
+	"Filename is required":                  "Le nom de fichier est obligatoire",
+	"Files retrieved successfully":          "Fichiers récupérés",
+	"Folder created successfully":           "Dossier créé",
+	"Folder deleted successfully":           "Dossier supprimé",
+	"Folder moved successfully":             "Dossier déplacé",
+	"Folder renamed successfully":           "Dossier renommé",
+	"Folder retrieved successfully":         "Dossier récupéré",
+	"Folders retrieved successfully":        "Dossiers récupérés",
+	"Import file validated successfully":    "Fichier d'import validé",
+	"Inactive users retrieved successfully": "Utilisateurs inactifs récupérés",
+	"Insufficient permissions":              "Permissions insuffisantes",
+	"Internal server error":                 "Erreur interne du serveur",
+	"Invalid days parameter":                "Paramètre days invalide",
+	"Invalid file ID":                       "ID de fichier invalide",
+	"Invalid file ID or version":            "ID de fichier ou version invalide",
+	"Invalid folder ID":                     "ID de dossier invalide",
+	"Content-Length header is required":     "L'en-tête Content-Length est obligatoire",
+	"Invalid Upload-Length header":          "En-tête Upload-Length invalide",
+	"Invalid Upload-Metadata header":        "En-tête Upload-Metadata invalide",
+	"Invalid Upload-Offset header":          "En-tête Upload-Offset invalide",
+	"Upload-Defer-Length is not supported":  "Upload-Defer-Length n'est pas pris en charge",
+	"Invalid password reset token format":   "Format du jeton de réinitialisation invalide",
+	"Invalid request body":                  "Corps de requête invalide",
+	"Invalid request":                       "Requête invalide",
+	"Invalid resource ID":                   "ID de ressource invalide",
+	"Invalid share link ID":                 "ID de lien de partage invalide",
+	"Invalid user ID":                       "ID d'utilisateur invalide",
+	"Login history retrieved successfully":  "Historique des connexions récupéré",
+	"Login successful":                      "Connexion réussie",
+	"No file provided":                      "Aucun fichier fourni",
+	"Password reset token is required":      "Le jeton de réinitialisation du mot de passe est obligatoire",
+	"Password reset token is valid. Please use POST method with your new password to complete the reset.": "Le jeton de réinitialisation est valide. Utilisez la méthode POST avec votre nouveau mot de passe pour terminer la réinitialisation.",
+	"Profile retrieved successfully":               "Profil récupéré",
+	"Rate limit exceeded. Please try again later.": "Limite de requêtes dépassée. Veuillez réessayer plus tard.",
+	"Settings retrieved successfully":              "Préférences récupérées",
+	"Settings updated successfully":                "Préférences mises à jour",
+	"Share link created successfully":              "Lien de partage créé avec succès",
+	"Share link revoked successfully":              "Lien de partage révoqué avec succès",
+	"Share links retrieved successfully":           "Liens de partage récupérés avec succès",
+	"Storage quota updated successfully":           "Quota de stockage mis à jour",
+	"Storage usage retrieved successfully":         "Utilisation du stockage récupérée",
+	"Token refreshed successfully":                 "Jeton renouvelé",
+	"User created successfully":                    "Utilisateur créé",
+	"User deleted successfully":                    "Utilisateur supprimé",
+	"User not authenticated":                       "Utilisateur non authentifié",
+	"User not found":                               "Utilisateur introuvable",
+	"User registered successfully":                 "Utilisateur inscrit",
+	"User retrieved successfully":                  "Utilisateur récupéré",
+	"User updated successfully":                    "Utilisateur mis à jour",
+	"Users imported successfully":                  "Utilisateurs importés",
+	"Users retrieved successfully":                 "Utilisateurs récupérés",
+	"Validation failed":                            "La validation a échoué",
+
+	// Service results
+	"Account deletion scheduled. You can cancel it until the scheduled date.": "Suppression du compte programmée. Vous pouvez l'annuler jusqu'à la date prévue.",
+	"Account deletion cancelled":  "Suppression du compte annulée",
+	"Email is already verified":   "L'adresse e-mail est déjà vérifiée",
+	"Email verified successfully": "Adresse e-mail vérifiée",
+	"If your email is registered, you will receive a password reset link shortly": "Si votre adresse e-mail est enregistrée, vous recevrez sous peu un lien de réinitialisation du mot de passe",
+	"Invalid or expired password reset token":                                     "Jeton de réinitialisation du mot de passe invalide ou expiré",
+	"Invalid or expired verification token":                                       "Jeton de vérification invalide ou expiré",
+	"Password reset failed":                                                       "La réinitialisation du mot de passe a échoué",
+	"Password reset successfully":                                                 "Mot de passe réinitialisé",
+	"Password reset token has expired":                                            "Le jeton de réinitialisation du mot de passe a expiré",
+	"Verification email sent successfully":                                        "E-mail de vérification envoyé",
+	"Verification failed":                                                         "La vérification a échoué",
+	"Verification token has expired":                                              "Le jeton de vérification a expiré",
+	"Email is a duplicate of line %d":                                             "L'adresse e-mail est en double avec la ligne %d",
+	"failed to create user":                                                       "impossible de créer l'utilisateur",
+	"line is not a valid JSON object":                                             "la ligne n'est pas un objet JSON valide",
+
+	// Errors
+	"authorization header required":                               "l'en-tête Authorization est requis",
+	"authorization header must start with 'Bearer '":              "l'en-tête Authorization doit commencer par 'Bearer '",
+	"token cannot be empty":                                       "le jeton ne peut pas être vide",
+	"invalid token":                                               "jeton invalide",
+	"token has expired":                                           "le jeton a expiré",
+	"validation failed":                                           "la validation a échoué",
+	"invalid password":                                            "mot de passe incorrect",
+	"account deletion is already scheduled":                       "la suppression du compte est déjà programmée",
+	"account deletion is not scheduled":                           "la suppression du compte n'est pas programmée",
+	"a data export is already in progress":                        "un export des données est déjà en cours",
+	"invalid or expired data export link":                         "lien d'export des données invalide ou expiré",
+	"metadata must be a JSON object within the size limit":        "les métadonnées doivent être un objet JSON ne dépassant pas la taille maximale",
+	"avatar must be a JPEG, PNG or GIF image":                     "l'avatar doit être une image JPEG, PNG ou GIF",
+	"avatar is not a valid image":                                 "l'avatar n'est pas une image valide",
+	"avatar image dimensions are too large":                       "les dimensions de l'avatar sont trop grandes",
+	"invalid email or password":                                   "adresse e-mail ou mot de passe incorrect",
+	"invalid refresh token":                                       "jeton de renouvellement invalide",
+	"user with this email already exists":                         "un utilisateur avec cette adresse e-mail existe déjà",
+	"invalid or expired verification token":                       "jeton de vérification invalide ou expiré",
+	"email is already verified":                                   "l'adresse e-mail est déjà vérifiée",
+	"invalid or expired password reset token":                     "jeton de réinitialisation du mot de passe invalide ou expiré",
+	"user not found":                                              "utilisateur introuvable",
+	"invalid import file":                                         "fichier d'import invalide",
+	"import file exceeds the maximum number of rows":              "le fichier d'import dépasse le nombre maximal de lignes",
+	"unsupported format, expected csv or ndjson":                  "format non pris en charge, csv ou ndjson attendu",
+	"file not found":                                              "fichier introuvable",
+	"file size exceeds maximum allowed size":                      "la taille du fichier dépasse la taille maximale autorisée",
+	"file type not allowed":                                       "type de fichier non autorisé",
+	"file content does not match its declared type or extension":  "le contenu du fichier ne correspond pas à son type déclaré ou à son extension",
+	"failed to store file":                                        "impossible d'enregistrer le fichier",
+	"invalid or missing file URL signature":                       "signature de l'URL du fichier invalide ou manquante",
+	"file URL has expired":                                        "l'URL du fichier a expiré",
+	"file variant not found":                                      "variante de fichier introuvable",
+	"image cannot be processed":                                   "impossible de traiter l'image",
+	"file is still being scanned for malware":                     "le fichier est encore en cours d'analyse antivirus",
+	"file is quarantined because malware was found":               "le fichier est en quarantaine car un logiciel malveillant a été détecté",
+	"file is blocked because it could not be scanned for malware": "le fichier est bloqué car il n'a pas pu être analysé à la recherche de logiciels malveillants",
+	"stored file content is damaged; upload the file again":       "le contenu stocké du fichier est endommagé ; téléversez à nouveau le fichier",
+	"file version not found":                                      "version du fichier introuvable",
+	"file was changed by another request":                         "le fichier a été modifié par une autre requête",
+	"storage quota exceeded":                                      "quota de stockage dépassé",
+	"file belongs to another user":                                "le fichier appartient à un autre utilisateur",
+	"only the owner of a file or a moderator can make it public":  "seul le propriétaire d'un fichier ou un modérateur peut le rendre public",
+	"folder not found":                                            "dossier introuvable",
+	"a folder with this name already exists":                      "un dossier portant ce nom existe déjà",
+	"folder is not empty":                                         "le dossier n'est pas vide",
+	"a folder cannot be moved into itself or its subfolders":      "un dossier ne peut pas être déplacé dans lui-même ou ses sous-dossiers",
+	"share link not found":                                        "lien de partage introuvable",
+	"share link has expired or reached its download limit":        "le lien de partage a expiré ou a atteint sa limite de téléchargements",
+	"share link password is missing or incorrect":                 "le mot de passe du lien de partage est manquant ou incorrect",
+	"expiry of a share link must be in the future":                "l'expiration d'un lien de partage doit être dans le futur",
+	"file is not shared with this user":                           "le fichier n'est pas partagé avec cet utilisateur",
+	"files cannot be shared with their owner":                     "les fichiers ne peuvent pas être partagés avec leur propriétaire",
+	"upload not found":                                            "téléversement introuvable",
+	"upload exceeds the maximum allowed size":                     "le téléversement dépasse la taille maximale autorisée",
+	"upload offset does not match the current offset":             "la position du téléversement ne correspond pas à la position actuelle",
+	"chunk exceeds the declared upload length":                    "le fragment dépasse la longueur déclarée du téléversement",
+	"chunk ended before its declared length":                      "le fragment s'est terminé avant sa longueur déclarée",
+	"unsupported tus protocol version":                            "version du protocole tus non prise en charge",
+	"chunks must be sent as application/offset+octet-stream":      "les fragments doivent être envoyés en application/offset+octet-stream",
+	"invalid filter":                                              "filtre invalide",
+	"invalid or tampered pagination cursor":                       "curseur de pagination invalide ou altéré",
+	"unknown expansion":                                           "expansion inconnue",
+
+	// Validation
+	"{0} is required":                                                "{0} est obligatoire",
+	"{0} must be a valid email":                                      "{0} doit être une adresse e-mail valide",
+	"{0} must be at least {1} characters":                            "{0} doit contenir au moins {1} caractères",
+	"{0} must be at most {1} characters":                             "{0} doit contenir au plus {1} caractères",
+	"{0} must be one of: {1}":                                        "{0} doit être l'une des valeurs : {1}",
+	"{0} must be a valid BCP 47 language tag (e.g. en-US)":           "{0} doit être une étiquette de langue BCP 47 valide (par ex. fr-FR)",
+	"{0} must be a valid IANA time zone (e.g. Europe/Berlin)":        "{0} doit être un fuseau horaire IANA valide (par ex. Europe/Paris)",
+	"{0} must be a phone number in E.164 format (e.g. +14155552671)": "{0} doit être un numéro de téléphone au format E.164 (par ex. +33123456789)",
+	"{0} must be at least 8 characters and contain uppercase, lowercase, number, and special character": "{0} doit contenir au moins 8 caractères dont une majuscule, une minuscule, un chiffre et un caractère spécial",
+	"{0} is invalid": "{0} est invalide",
+
+	// Settings
+	"%s is not a known setting":  "%s n'est pas une préférence connue",
+	"%s cannot be changed":       "%s ne peut pas être modifié",
+	"%s must be a boolean":       "%s doit être un booléen",
+	"%s must be a string":        "%s doit être une chaîne",
+	"%s must be one of: %v":      "%s doit être l'une des valeurs : %v",
+	"%s must be an integer":      "%s doit être un entier",
+	"%s must be at least %d":     "%s doit être au moins %d",
+	"%s must be at most %d":      "%s doit être au plus %d",
+	"%s has an unsupported type": "%s a un type non pris en charge",
+	"Security emails such as password resets and account deletion notices. Always on.":     "E-mails de sécurité, comme les réinitialisations de mot de passe et les avis de suppression du compte. Toujours activés.",
+	"Emails about actions you requested, such as data exports and invitations. Always on.": "E-mails concernant les actions que vous avez demandées, comme les exports de données et les invitations. Toujours activés.",
+	"Product news and announcements.":            "Actualités et annonces du produit.",
+	"Color theme of the web interface.":          "Thème de couleurs de l'interface web.",
+	"Default number of items per page in lists.": "Nombre d'éléments par page par défaut dans les listes.",
+
+	// Emails
+	"Hi %s,": "Bonjour %s,",
+	"If the button doesn't work, you can copy and paste this link into your browser:": "Si le bouton ne fonctionne pas, copiez et collez ce lien dans votre navigateur :",
+	"© 2025 Go Template. All rights reserved.":                                        "© 2025 Go Template. Tous droits réservés.",
+	"Verify Your Email Address":                                                       "Vérifiez votre adresse e-mail",
+	"Verify Your Email":                                                               "Vérifiez votre adresse e-mail",
+	"Welcome to Go Template!":                                                         "Bienvenue sur Go Template !",
+	"Thank you for registering with Go Template! To complete your registration, please verify your email address by clicking the button below:": "Merci de votre inscription sur Go Template ! Pour la finaliser, veuillez vérifier votre adresse e-mail en cliquant sur le bouton ci-dessous :",
+	"Verify My Email": "Vérifier mon adresse e-mail",
+	"This verification link will expire in 24 hours for security reasons.": "Pour des raisons de sécurité, ce lien de vérification expirera dans 24 heures.",
+	"If you didn't create an account with us, please ignore this email.":   "Si vous n'avez pas créé de compte chez nous, ignorez cet e-mail.",
+	"Reset Your Password":    "Réinitialisez votre mot de passe",
+	"Password Reset Request": "Demande de réinitialisation du mot de passe",
+	"We received a request to reset your password. If you made this request, click the button below to reset your password:": "Nous avons reçu une demande de réinitialisation de votre mot de passe. Si vous en êtes à l'origine, cliquez sur le bouton ci-dessous pour le réinitialiser :",
+	"Reset My Password": "Réinitialiser mon mot de passe",
+	"This password reset link will expire in 24 hours for security reasons.":                                 "Pour des raisons de sécurité, ce lien de réinitialisation expirera dans 24 heures.",
+	"If you didn't request a password reset, please ignore this email. Your password will remain unchanged.": "Si vous n'avez pas demandé de réinitialisation, ignorez cet e-mail. Votre mot de passe restera inchangé.",
+	"Your Data Export Is Ready": "Votre export de données est prêt",
+	"The export of your personal data you requested has been generated. It contains your profile, the metadata of your files and the files you uploaded.": "L'export de vos données personnelles que vous avez demandé a été généré. Il contient votre profil, les métadonnées de vos fichiers et les fichiers que vous avez téléversés.",
+	"Download My Data":                      "Télécharger mes données",
+	"This download link will expire on %s.": "Ce lien de téléchargement expirera le %s.",
+	"If you didn't request a data export, please change your password immediately.": "Si vous n'avez pas demandé d'export de données, changez immédiatement votre mot de passe.",
+	"Your Account Is Scheduled For Deletion":                                        "La suppression de votre compte est programmée",
+	"Account Deletion Scheduled":                                                    "Suppression du compte programmée",
+	"We received a request to delete your account. Your account and all of your files will be permanently deleted on %s.": "Nous avons reçu une demande de suppression de votre compte. Votre compte et tous vos fichiers seront définitivement supprimés le %s.",
+	"Until then you can still sign in and cancel the deletion from your account settings.":                                "D'ici là, vous pouvez toujours vous connecter et annuler la suppression depuis les paramètres de votre compte.",
+	"If you didn't request this, please sign in, cancel the deletion and change your password immediately.":               "Si vous n'êtes pas à l'origine de cette demande, connectez-vous, annulez la suppression et changez immédiatement votre mot de passe.",
+	"You Have Been Invited":  "Vous avez été invité",
+	"Welcome to Go Template": "Bienvenue sur Go Template",
+	"An account has been created for you. To get started, please choose a password by clicking the button below:": "Un compte a été créé pour vous. Pour commencer, choisissez un mot de passe en cliquant sur le bouton ci-dessous :",
+	"Set My Password":                    "Définir mon mot de passe",
+	"This invitation will expire on %s.": "Cette invitation expirera le %s.",
+	"If you weren't expecting this invitation, you can safely ignore this email.": "Si vous n'attendiez pas cette invitation, vous pouvez ignorer cet e-mail.",
+	"You can change which emails you receive in your notification settings.":      "Vous pouvez choisir les e-mails que vous recevez dans vos préférences de notification.",
+}
@@ -0,0 +1,84 @@
+package imageutil
+
+import (
+	"errors"
+	"image"
+	"io"
+
+	// Register decoders for the formats accepted as uploads
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	"golang.org/x/image/draw"
+)
+
+// MaxPixels bounds the decoded size of an image to guard against decompression bombs
+const MaxPixels = 40 * 1000 * 1000
+
+var ErrImageTooLarge = errors.New("image dimensions are too large")
+
+// Decode reads an image after checking its dimensions against MaxPixels
+func Decode(r io.ReadSeeker) (image.Image, string, error) {
+	config, _, err := image.DecodeConfig(r)
+	if err != nil {
+		return nil, "", err
+	}
+	if config.Width*config.Height > MaxPixels {
+		return nil, "", ErrImageTooLarge
+	}
+
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, "", err
+	}
+
+	return image.Decode(r)
+}
+
+// Thumbnail center-crops src to a square and scales it to size x size pixels
+func Thumbnail(src image.Image, size int) image.Image {
+	return Cover(src, size, size)
+}
+
+// Cover center-crops src to the aspect ratio of width x height and scales it to exactly that size
+func Cover(src image.Image, width, height int) image.Image {
+	bounds := src.Bounds()
+	cropWidth, cropHeight := bounds.Dx(), bounds.Dy()
+	if cropWidth*height > cropHeight*width {
+		cropWidth = max(cropHeight*width/height, 1)
+	} else {
+		cropHeight = max(cropWidth*height/width, 1)
+	}
+
+	x0 := bounds.Min.X + (bounds.Dx()-cropWidth)/2
+	y0 := bounds.Min.Y + (bounds.Dy()-cropHeight)/2
+	crop := image.Rect(x0, y0, x0+cropWidth, y0+cropHeight)
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, crop, draw.Over, nil)
+
+	return dst
+}
+
+// Fit scales src down to fit within maxWidth x maxHeight, keeping its aspect ratio. A zero bound is
+// unlimited; images that already fit are returned unchanged.
+func Fit(src image.Image, maxWidth, maxHeight int) image.Image {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if maxWidth > 0 && width > maxWidth {
+		height = max(height*maxWidth/width, 1)
+		width = maxWidth
+	}
+	if maxHeight > 0 && height > maxHeight {
+		width = max(width*maxHeight/height, 1)
+		height = maxHeight
+	}
+	if width == bounds.Dx() && height == bounds.Dy() {
+		return src
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Over, nil)
+
+	return dst
+}
@@ -0,0 +1,105 @@
+package imageutil
+
+import (
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Output formats of variants
+const (
+	FormatJPEG = "jpeg"
+	FormatPNG  = "png"
+	FormatWebP = "webp"
+)
+
+// jpegQuality is the quality JPEG variants are encoded with
+const jpegQuality = 85
+
+// Variant describes a derivative of an uploaded image, such as a thumbnail
+type Variant struct {
+	Name   string
+	Width  int
+	Height int // 0 scales to Width, keeping the aspect ratio
+	// Crop fills Width x Height exactly by cropping; otherwise the image is scaled to fit within it
+	Crop bool
+	// Format of the variant; empty keeps JPEG images JPEG and encodes everything else as PNG
+	Format string
+}
+
+// ParseVariant parses a variant specification of the form name:WIDTHxHEIGHT[:crop][:format], e.g.
+// "thumbnail:200x200:crop" or "webp:1600x1600:webp"
+func ParseVariant(spec string) (Variant, error) {
+	parts := strings.Split(strings.TrimSpace(spec), ":")
+	if len(parts) < 2 || parts[0] == "" {
+		return Variant{}, fmt.Errorf("invalid image variant %q: expected name:WIDTHxHEIGHT[:crop][:format]", spec)
+	}
+
+	variant := Variant{Name: parts[0]}
+	width, height, found := strings.Cut(parts[1], "x")
+	var err error
+	if variant.Width, err = strconv.Atoi(width); err != nil || variant.Width < 1 {
+		return Variant{}, fmt.Errorf("invalid width in image variant %q", spec)
+	}
+	if found {
+		if variant.Height, err = strconv.Atoi(height); err != nil || variant.Height < 0 {
+			return Variant{}, fmt.Errorf("invalid height in image variant %q", spec)
+		}
+	}
+
+	for _, option := range parts[2:] {
+		switch option {
+		case "crop":
+			variant.Crop = true
+		case FormatJPEG, FormatPNG, FormatWebP:
+			variant.Format = option
+		default:
+			return Variant{}, fmt.Errorf("unknown option %q in image variant %q", option, spec)
+		}
+	}
+	if variant.Crop && variant.Height == 0 {
+		return Variant{}, fmt.Errorf("image variant %q crops without a height", spec)
+	}
+	if variant.Width > maxWebPDimension || variant.Height > maxWebPDimension {
+		return Variant{}, fmt.Errorf("image variant %q is too large", spec)
+	}
+
+	return variant, nil
+}
+
+// Render derives the variant from src
+func (v Variant) Render(src image.Image) image.Image {
+	if v.Crop {
+		return Cover(src, v.Width, v.Height)
+	}
+	return Fit(src, v.Width, v.Height)
+}
+
+// OutputFormat returns the format the variant of an image decoded as sourceFormat is encoded in
+func (v Variant) OutputFormat(sourceFormat string) string {
+	if v.Format != "" {
+		return v.Format
+	}
+	if sourceFormat == FormatJPEG {
+		return FormatJPEG
+	}
+	return FormatPNG
+}
+
+// Encode writes img in format, returning its MIME type and file extension
+func Encode(w io.Writer, img image.Image, format string) (mimeType, ext string, err error) {
+	switch format {
+	case FormatJPEG:
+		return "image/jpeg", ".jpg", jpeg.Encode(w, img, &jpeg.Options{Quality: jpegQuality})
+	case FormatPNG:
+		return "image/png", ".png", png.Encode(w, img)
+	case FormatWebP:
+		return "image/webp", ".webp", EncodeWebP(w, img)
+	default:
+		return "", "", fmt.Errorf("unsupported image format %q", format)
+	}
+}
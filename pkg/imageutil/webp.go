@@ -0,0 +1,517 @@
+package imageutil
+
+import (
+	"container/heap"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"io"
+)
+
+// maxWebPDimension is the largest width or height a WebP image can have
+const maxWebPDimension = 1 << 14
+
+// codeLengthCodeOrder is the order in which the lengths of the code length code are written
+var codeLengthCodeOrder = [19]int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// predictorTileBits sets the size of the tiles, 32x32 pixels, that each pick their own predictor
+const predictorTileBits = 5
+
+// EncodeWebP writes img as a lossless WebP (VP8L) image. Pixels go through the subtract green and
+// predictor transforms before being entropy coded; backward references and color caches are not used,
+// so the output is larger than cwebp's yet decodes everywhere.
+func EncodeWebP(w io.Writer, img image.Image) error {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width < 1 || height < 1 || width > maxWebPDimension || height > maxWebPDimension {
+		return errors.New("webp: image dimensions out of range")
+	}
+
+	pixels := make([][4]uint8, 0, width*height) // red, green, blue, alpha
+	hasAlpha := false
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			pixels = append(pixels, [4]uint8{c.R, c.G, c.B, c.A})
+			hasAlpha = hasAlpha || c.A != 0xff
+		}
+	}
+
+	bw := &bitWriter{}
+	bw.write(0x2f, 8) // VP8L signature
+	bw.write(uint32(width-1), 14)
+	bw.write(uint32(height-1), 14)
+	if hasAlpha {
+		bw.write(1, 1)
+	} else {
+		bw.write(0, 1)
+	}
+	bw.write(0, 3) // version
+
+	// Transforms are listed in the order they are applied; decoders undo them in reverse
+	subtractGreen(pixels)
+	bw.write(1, 1)
+	bw.write(2, 2) // subtract green
+
+	modes, residuals := predict(pixels, width, height)
+	bw.write(1, 1)
+	bw.write(0, 2) // predictor
+	bw.write(predictorTileBits-2, 3)
+	writeImageData(bw, modes, false)
+
+	bw.write(0, 1) // no more transforms
+	writeImageData(bw, residuals, true)
+
+	data := bw.bytes()
+	chunkSize := len(data)
+	padding := chunkSize & 1
+	header := make([]byte, 20)
+	copy(header[0:], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:], uint32(4+8+chunkSize+padding))
+	copy(header[8:], "WEBPVP8L")
+	binary.LittleEndian.PutUint32(header[16:], uint32(chunkSize))
+
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if padding != 0 {
+		_, err := w.Write([]byte{0})
+		return err
+	}
+	return nil
+}
+
+// writeImageData entropy codes pixels with a single group of prefix codes. Only the main image,
+// unlike transform sub-images, says whether it uses several groups.
+func writeImageData(bw *bitWriter, pixels [][4]uint8, mainImage bool) {
+	bw.write(0, 1) // no color cache
+	if mainImage {
+		bw.write(0, 1) // a single group of prefix codes for the whole image
+	}
+
+	// Only literals are emitted: the green alphabet also covers backward reference lengths and the
+	// distance code stays unused. Codes come in the order green, red, blue, alpha.
+	channels := [4]int{1, 0, 2, 3}
+	alphabetSizes := [4]int{256 + 24, 256, 256, 256}
+	var codes [4]*prefixCode
+	for i, size := range alphabetSizes {
+		freqs := make([]int, size)
+		for _, p := range pixels {
+			freqs[p[channels[i]]]++
+		}
+		codes[i] = newPrefixCode(freqs, 15)
+		codes[i].writeTo(bw)
+	}
+	distance := newPrefixCode(make([]int, 40), 15)
+	distance.writeTo(bw)
+
+	for _, p := range pixels {
+		for i, code := range codes {
+			code.writeSymbol(bw, int(p[channels[i]]))
+		}
+	}
+}
+
+// subtractGreen removes the green value from red and blue, which tend to follow it
+func subtractGreen(pixels [][4]uint8) {
+	for i := range pixels {
+		pixels[i][0] -= pixels[i][1]
+		pixels[i][2] -= pixels[i][1]
+	}
+}
+
+// predict picks, for every tile, the predictor leaving the smallest residuals, and returns the
+// predictor sub-image along with the residuals of all pixels
+func predict(pixels [][4]uint8, width, height int) (modes, residuals [][4]uint8) {
+	tilesPerRow := (width + 1<<predictorTileBits - 1) >> predictorTileBits
+	tilesPerColumn := (height + 1<<predictorTileBits - 1) >> predictorTileBits
+	modes = make([][4]uint8, tilesPerRow*tilesPerColumn)
+	residuals = make([][4]uint8, len(pixels))
+
+	for ty := 0; ty < tilesPerColumn; ty++ {
+		for tx := 0; tx < tilesPerRow; tx++ {
+			x0, y0 := tx<<predictorTileBits, ty<<predictorTileBits
+			x1, y1 := min(x0+1<<predictorTileBits, width), min(y0+1<<predictorTileBits, height)
+
+			best, bestCost := 0, -1
+			for mode := 0; mode < 14; mode++ {
+				cost := 0
+				for y := y0; y < y1; y++ {
+					for x := x0; x < x1; x++ {
+						prediction := predictPixel(pixels, width, x, y, mode)
+						for c, value := range pixels[y*width+x] {
+							cost += absInt(int(int8(value - prediction[c])))
+						}
+					}
+				}
+				if bestCost < 0 || cost < bestCost {
+					best, bestCost = mode, cost
+				}
+			}
+
+			modes[ty*tilesPerRow+tx] = [4]uint8{0, uint8(best), 0, 0xff}
+			for y := y0; y < y1; y++ {
+				for x := x0; x < x1; x++ {
+					prediction := predictPixel(pixels, width, x, y, best)
+					for c, value := range pixels[y*width+x] {
+						residuals[y*width+x][c] = value - prediction[c]
+					}
+				}
+			}
+		}
+	}
+	return modes, residuals
+}
+
+// predictPixel predicts the pixel at x, y from its left (L), top (T), top-left (TL) and top-right (TR)
+// neighbours. The first row and column use fixed predictors whatever the mode.
+func predictPixel(pixels [][4]uint8, width, x, y, mode int) [4]uint8 {
+	switch {
+	case x == 0 && y == 0:
+		return [4]uint8{0, 0, 0, 0xff}
+	case y == 0:
+		return pixels[x-1]
+	case x == 0:
+		return pixels[(y-1)*width]
+	}
+
+	l, t, tl := pixels[y*width+x-1], pixels[(y-1)*width+x], pixels[(y-1)*width+x-1]
+	// The top-right of the last column is the first pixel of the current row
+	tr := pixels[(y-1)*width+x+1]
+
+	var p [4]uint8
+	switch mode {
+	case 0:
+		return [4]uint8{0, 0, 0, 0xff}
+	case 1:
+		return l
+	case 2:
+		return t
+	case 3:
+		return tr
+	case 4:
+		return tl
+	case 11:
+		pl, pt := 0, 0
+		for c := range p {
+			pl += absInt(int(tl[c]) - int(t[c]))
+			pt += absInt(int(tl[c]) - int(l[c]))
+		}
+		if pl < pt {
+			return l
+		}
+		return t
+	}
+	for c := range p {
+		switch mode {
+		case 5:
+			p[c] = avg2(avg2(l[c], tr[c]), t[c])
+		case 6:
+			p[c] = avg2(l[c], tl[c])
+		case 7:
+			p[c] = avg2(l[c], t[c])
+		case 8:
+			p[c] = avg2(tl[c], t[c])
+		case 9:
+			p[c] = avg2(t[c], tr[c])
+		case 10:
+			p[c] = avg2(avg2(l[c], tl[c]), avg2(t[c], tr[c]))
+		case 12:
+			p[c] = clampByte(int(l[c]) + int(t[c]) - int(tl[c]))
+		case 13:
+			a := int(avg2(l[c], t[c]))
+			p[c] = clampByte(a + (a-int(tl[c]))/2)
+		}
+	}
+	return p
+}
+
+func avg2(a, b uint8) uint8 {
+	return uint8((int(a) + int(b)) / 2)
+}
+
+func clampByte(v int) uint8 {
+	return uint8(min(max(v, 0), 255))
+}
+
+func absInt(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// bitWriter packs values least significant bit first, as VP8L streams are read
+type bitWriter struct {
+	buf   []byte
+	bits  uint64
+	nBits uint
+}
+
+func (w *bitWriter) write(value uint32, n uint) {
+	w.bits |= uint64(value) << w.nBits
+	w.nBits += n
+	for w.nBits >= 8 {
+		w.buf = append(w.buf, byte(w.bits))
+		w.bits >>= 8
+		w.nBits -= 8
+	}
+}
+
+func (w *bitWriter) bytes() []byte {
+	if w.nBits > 0 {
+		w.buf = append(w.buf, byte(w.bits))
+		w.bits, w.nBits = 0, 0
+	}
+	return w.buf
+}
+
+// prefixCode is a canonical Huffman code over an alphabet
+type prefixCode struct {
+	lengths []int
+	codes   []uint32
+	// symbols lists the used symbols when there are at most two, which are written as a simple code
+	symbols []int
+	// single marks a code with one used symbol, whose code takes no bits
+	single bool
+}
+
+func newPrefixCode(freqs []int, maxLength int) *prefixCode {
+	var used []int
+	for symbol, freq := range freqs {
+		if freq > 0 {
+			used = append(used, symbol)
+		}
+	}
+	if len(used) == 0 {
+		// Unused codes still need a symbol; a single symbol takes no bits
+		used = []int{0}
+	}
+
+	code := &prefixCode{lengths: make([]int, len(freqs)), codes: make([]uint32, len(freqs))}
+	if len(used) <= 2 && used[len(used)-1] < 256 {
+		code.symbols = used
+		if len(used) == 2 {
+			code.lengths[used[0]], code.lengths[used[1]] = 1, 1
+			code.codes[used[1]] = 1
+		}
+		return code
+	}
+
+	code.lengths = huffmanLengths(freqs, maxLength)
+	code.codes = canonicalCodes(code.lengths, maxLength)
+	return code
+}
+
+// newLengthCode builds the code the code lengths of another code are written with
+func newLengthCode(freqs []int) *prefixCode {
+	lengths := huffmanLengths(freqs, 7)
+	used := 0
+	for _, length := range lengths {
+		if length > 0 {
+			used++
+		}
+	}
+	return &prefixCode{lengths: lengths, codes: canonicalCodes(lengths, 7), single: used == 1}
+}
+
+// writeTo writes the code itself, as a simple code when possible and otherwise as code lengths
+func (c *prefixCode) writeTo(w *bitWriter) {
+	if c.symbols != nil {
+		w.write(1, 1)
+		w.write(uint32(len(c.symbols)-1), 1)
+		if c.symbols[0] < 2 {
+			w.write(0, 1)
+			w.write(uint32(c.symbols[0]), 1)
+		} else {
+			w.write(1, 1)
+			w.write(uint32(c.symbols[0]), 8)
+		}
+		if len(c.symbols) == 2 {
+			w.write(uint32(c.symbols[1]), 8)
+		}
+		return
+	}
+	w.write(0, 1)
+
+	// Code lengths are written as symbols of the code length code: 0-15 literally, 17 and 18 for
+	// runs of zeros
+	type token struct{ symbol, extra, extraBits int }
+	var tokens []token
+	for i := 0; i < len(c.lengths); {
+		if c.lengths[i] != 0 {
+			tokens = append(tokens, token{symbol: c.lengths[i]})
+			i++
+			continue
+		}
+		run := 1
+		for i+run < len(c.lengths) && c.lengths[i+run] == 0 && run < 138 {
+			run++
+		}
+		switch {
+		case run >= 11:
+			tokens = append(tokens, token{18, run - 11, 7})
+		case run >= 3:
+			tokens = append(tokens, token{17, run - 3, 3})
+		default:
+			run = 1
+			tokens = append(tokens, token{symbol: 0})
+		}
+		i += run
+	}
+
+	freqs := make([]int, len(codeLengthCodeOrder))
+	for _, t := range tokens {
+		freqs[t.symbol]++
+	}
+	lengthCode := newLengthCode(freqs)
+
+	nCodes := 4
+	for i, symbol := range codeLengthCodeOrder {
+		if lengthCode.lengths[symbol] != 0 && i+1 > nCodes {
+			nCodes = i + 1
+		}
+	}
+	w.write(uint32(nCodes-4), 4)
+	for _, symbol := range codeLengthCodeOrder[:nCodes] {
+		w.write(uint32(lengthCode.lengths[symbol]), 3)
+	}
+	w.write(0, 1) // code lengths follow for the whole alphabet
+
+	for _, t := range tokens {
+		lengthCode.writeSymbol(w, t.symbol)
+		if t.extraBits > 0 {
+			w.write(uint32(t.extra), uint(t.extraBits))
+		}
+	}
+}
+
+// writeSymbol writes the code of symbol. Codes are read bit by bit from their most significant bit.
+func (c *prefixCode) writeSymbol(w *bitWriter, symbol int) {
+	length := c.lengths[symbol]
+	if length == 0 || c.single {
+		// The only symbol of its code
+		return
+	}
+	code := c.codes[symbol]
+	reversed := uint32(0)
+	for i := 0; i < length; i++ {
+		reversed = reversed<<1 | code>>i&1
+	}
+	w.write(reversed, uint(length))
+}
+
+// huffmanLengths computes code lengths of at most maxLength bits. A single used symbol gets length 1,
+// which decoders treat as a code without bits. Frequencies are flattened until the limit holds.
+func huffmanLengths(freqs []int, maxLength int) []int {
+	weights := make([]int, len(freqs))
+	copy(weights, freqs)
+
+	for {
+		lengths := unlimitedHuffmanLengths(weights)
+		longest := 0
+		for _, length := range lengths {
+			longest = max(longest, length)
+		}
+		if longest <= maxLength {
+			return lengths
+		}
+		for i, weight := range weights {
+			if weight > 0 {
+				weights[i] = weight/2 + 1
+			}
+		}
+	}
+}
+
+type huffmanNode struct {
+	weight  int
+	symbol  int // -1 for inner nodes
+	left    *huffmanNode
+	right   *huffmanNode
+	ordinal int // tie breaker, so the result does not depend on heap internals
+}
+
+type huffmanHeap []*huffmanNode
+
+func (h huffmanHeap) Len() int { return len(h) }
+func (h huffmanHeap) Less(i, j int) bool {
+	if h[i].weight != h[j].weight {
+		return h[i].weight < h[j].weight
+	}
+	return h[i].ordinal < h[j].ordinal
+}
+func (h huffmanHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *huffmanHeap) Push(x interface{}) { *h = append(*h, x.(*huffmanNode)) }
+func (h *huffmanHeap) Pop() interface{} {
+	old := *h
+	node := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return node
+}
+
+func unlimitedHuffmanLengths(weights []int) []int {
+	lengths := make([]int, len(weights))
+	nodes := &huffmanHeap{}
+	for symbol, weight := range weights {
+		if weight > 0 {
+			heap.Push(nodes, &huffmanNode{weight: weight, symbol: symbol, ordinal: nodes.Len()})
+		}
+	}
+	switch nodes.Len() {
+	case 0:
+		return lengths
+	case 1:
+		lengths[(*nodes)[0].symbol] = 1
+		return lengths
+	}
+
+	ordinal := len(weights)
+	for nodes.Len() > 1 {
+		left := heap.Pop(nodes).(*huffmanNode)
+		right := heap.Pop(nodes).(*huffmanNode)
+		heap.Push(nodes, &huffmanNode{weight: left.weight + right.weight, symbol: -1, left: left, right: right, ordinal: ordinal})
+		ordinal++
+	}
+
+	var walk func(node *huffmanNode, depth int)
+	walk = func(node *huffmanNode, depth int) {
+		if node.symbol >= 0 {
+			lengths[node.symbol] = depth
+			return
+		}
+		walk(node.left, depth+1)
+		walk(node.right, depth+1)
+	}
+	walk(heap.Pop(nodes).(*huffmanNode), 0)
+	return lengths
+}
+
+// canonicalCodes assigns codes in order of length, then symbol, as decoders rebuild them
+func canonicalCodes(lengths []int, maxLength int) []uint32 {
+	counts := make([]uint32, maxLength+1)
+	for _, length := range lengths {
+		counts[length]++
+	}
+	counts[0] = 0
+
+	next := make([]uint32, maxLength+1)
+	code := uint32(0)
+	for length := 1; length <= maxLength; length++ {
+		code = (code + counts[length-1]) << 1
+		next[length] = code
+	}
+
+	codes := make([]uint32, len(lengths))
+	for symbol, length := range lengths {
+		if length > 0 {
+			codes[symbol] = next[length]
+			next[length]++
+		}
+	}
+	return codes
+}
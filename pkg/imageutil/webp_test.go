@@ -0,0 +1,116 @@
+package imageutil
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+
+	"golang.org/x/image/webp"
+)
+
+// testImage draws gradients with some noise, so the tiles prefer different predictors; with alpha,
+// the alpha channel varies too, down to fully transparent pixels
+func testImage(width, height int, alpha bool, seed int64) *image.NRGBA {
+	rng := rand.New(rand.NewSource(seed))
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := color.NRGBA{
+				R: uint8(x*7 + rng.Intn(4)),
+				G: uint8(y*5 + x),
+				B: uint8((x ^ y) + rng.Intn(16)),
+				A: 0xff,
+			}
+			if alpha {
+				c.A = uint8(x*y + rng.Intn(8))
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func roundTripWebP(t *testing.T, img image.Image) image.Image {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := EncodeWebP(&buf, img); err != nil {
+		t.Fatalf("EncodeWebP: %v", err)
+	}
+
+	config, err := webp.DecodeConfig(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("DecodeConfig: %v", err)
+	}
+	if config.Width != img.Bounds().Dx() || config.Height != img.Bounds().Dy() {
+		t.Fatalf("decoded size %dx%d, want %dx%d", config.Width, config.Height, img.Bounds().Dx(), img.Bounds().Dy())
+	}
+
+	decoded, err := webp.Decode(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	return decoded
+}
+
+// assertSamePixels compares the pixels of img with decoded, which starts at the origin
+func assertSamePixels(t *testing.T, img, decoded image.Image) {
+	t.Helper()
+
+	bounds := img.Bounds()
+	mismatches := 0
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			want := color.NRGBAModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.NRGBA)
+			got := color.NRGBAModel.Convert(decoded.At(x, y)).(color.NRGBA)
+			if got != want {
+				if mismatches < 5 {
+					t.Errorf("pixel (%d, %d) = %v, want %v", x, y, got, want)
+				}
+				mismatches++
+			}
+		}
+	}
+	if mismatches > 0 {
+		t.Fatalf("%d of %d pixels differ", mismatches, bounds.Dx()*bounds.Dy())
+	}
+}
+
+func TestEncodeWebPRoundTrip(t *testing.T) {
+	// Sizes around the 32 pixel predictor tiles, including partial tiles and single rows and columns
+	sizes := [][2]int{{1, 1}, {1, 40}, {40, 1}, {31, 33}, {32, 32}, {33, 31}, {65, 47}, {100, 70}}
+
+	for _, alpha := range []bool{false, true} {
+		for i, size := range sizes {
+			t.Run(fmt.Sprintf("%dx%d alpha=%v", size[0], size[1], alpha), func(t *testing.T) {
+				img := testImage(size[0], size[1], alpha, int64(i))
+				assertSamePixels(t, img, roundTripWebP(t, img))
+			})
+		}
+	}
+}
+
+func TestEncodeWebPUniformImage(t *testing.T) {
+	// A single color leaves a single symbol per prefix code
+	img := image.NewNRGBA(image.Rect(0, 0, 37, 29))
+	for i := 0; i < len(img.Pix); i += 4 {
+		copy(img.Pix[i:], []uint8{0x12, 0x34, 0x56, 0xff})
+	}
+	assertSamePixels(t, img, roundTripWebP(t, img))
+}
+
+func TestEncodeWebPSubImage(t *testing.T) {
+	img := testImage(90, 80, true, 42).SubImage(image.Rect(13, 21, 60, 77))
+	assertSamePixels(t, img, roundTripWebP(t, img))
+}
+
+func TestEncodeWebPRejectsInvalidDimensions(t *testing.T) {
+	for _, rect := range []image.Rectangle{image.Rect(0, 0, 0, 10), image.Rect(0, 0, maxWebPDimension+1, 1)} {
+		if err := EncodeWebP(&bytes.Buffer{}, image.NewNRGBA(rect)); err == nil {
+			t.Errorf("EncodeWebP accepted a %dx%d image", rect.Dx(), rect.Dy())
+		}
+	}
+}
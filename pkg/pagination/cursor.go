@@ -0,0 +1,110 @@
+package pagination
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"go-template/pkg/apperror"
+)
+
+var ErrInvalidCursor = apperror.New(apperror.KindInvalid, apperror.CodeInvalidCursor, "invalid or tampered pagination cursor")
+
+// cursorSecret signs cursors so clients cannot forge arbitrary keyset positions.
+// It is replaced by SetCursorSecret at startup; the random fallback only keeps
+// cursors valid for the lifetime of the process.
+var cursorSecret = func() []byte {
+	secret := make([]byte, 32)
+	rand.Read(secret)
+	return secret
+}()
+
+// Cursor marks the row a keyset page starts after (or, when Before is set, ends before)
+type Cursor struct {
+	Sort   string `json:"s"`
+	Order  string `json:"o"`
+	Value  string `json:"v,omitempty"` // Sort key of the boundary row, empty when sorting by id
+	ID     int    `json:"i"`
+	Before bool   `json:"b,omitempty"`
+}
+
+// SetCursorSecret configures the key used to sign and verify cursors
+func SetCursorSecret(secret string) {
+	cursorSecret = []byte(secret)
+}
+
+// EncodeCursor serializes and signs a cursor into an opaque URL-safe token
+func EncodeCursor(cursor Cursor) string {
+	payload, _ := json.Marshal(cursor)
+	encodedPayload := base64.RawURLEncoding.EncodeToString(payload)
+	return encodedPayload + "." + base64.RawURLEncoding.EncodeToString(signCursor(encodedPayload))
+}
+
+// DecodeCursor verifies and parses a token created by EncodeCursor. An empty token yields a nil cursor (first page).
+func DecodeCursor(token string) (*Cursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	encodedPayload, encodedSignature, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil || !hmac.Equal(signature, signCursor(encodedPayload)) {
+		return nil, ErrInvalidCursor
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor Cursor
+	if err := json.Unmarshal(payload, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &cursor, nil
+}
+
+// ReverseOrder returns the opposite sort order, used to fetch the page before a cursor
+func ReverseOrder(order string) string {
+	if SanitizeOrder(order) == "ASC" {
+		return "DESC"
+	}
+	return "ASC"
+}
+
+// NewCursorPaginationMeta creates pagination metadata for a keyset page.
+// hasMore reports whether another row exists beyond the page in the direction it was fetched,
+// first and last are the cursors of the page's first and last rows (nil for an empty page).
+func NewCursorPaginationMeta(limit int, cursor *Cursor, hasMore bool, first, last *Cursor) PaginationMeta {
+	meta := PaginationMeta{PerPage: limit}
+
+	if cursor != nil && cursor.Before {
+		meta.HasPrev = hasMore
+		meta.HasNext = true
+	} else {
+		meta.HasNext = hasMore
+		meta.HasPrev = cursor != nil
+	}
+
+	if meta.HasNext && last != nil {
+		meta.NextCursor = EncodeCursor(*last)
+	}
+	if meta.HasPrev && first != nil {
+		first.Before = true
+		meta.PrevCursor = EncodeCursor(*first)
+	}
+
+	return meta
+}
+
+func signCursor(encodedPayload string) []byte {
+	mac := hmac.New(sha256.New, cursorSecret)
+	mac.Write([]byte(encodedPayload))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,147 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	cursors := []Cursor{
+		{Sort: "id", Order: "ASC", ID: 1},
+		{Sort: "created_at", Order: "DESC", Value: "2024-05-01T10:00:00.123456Z", ID: 42},
+		{Sort: "file_name", Order: "ASC", Value: "report, \"final\".pdf", ID: 7, Before: true},
+	}
+	for _, cursor := range cursors {
+		token := EncodeCursor(cursor)
+		if strings.ContainsAny(token, "+/=") {
+			t.Errorf("EncodeCursor(%+v) = %q, want a URL-safe token", cursor, token)
+		}
+
+		decoded, err := DecodeCursor(token)
+		if err != nil {
+			t.Fatalf("DecodeCursor(EncodeCursor(%+v)): %v", cursor, err)
+		}
+		if *decoded != cursor {
+			t.Errorf("DecodeCursor(EncodeCursor(%+v)) = %+v", cursor, *decoded)
+		}
+	}
+
+	if cursor, err := DecodeCursor(""); cursor != nil || err != nil {
+		t.Errorf("DecodeCursor(\"\") = %+v, %v; want the first page", cursor, err)
+	}
+}
+
+func TestDecodeCursorRejectsTampering(t *testing.T) {
+	token := EncodeCursor(Cursor{Sort: "id", Order: "ASC", ID: 10})
+	encodedPayload, encodedSignature, _ := strings.Cut(token, ".")
+
+	// A payload moved to another position keeps the signature of the original one
+	forgedPayload := base64.RawURLEncoding.EncodeToString([]byte(`{"s":"id","o":"ASC","i":1000}`))
+
+	signature, _ := base64.RawURLEncoding.DecodeString(encodedSignature)
+	signature[0] ^= 1
+	flippedSignature := base64.RawURLEncoding.EncodeToString(signature)
+
+	// Signed with the secret, but not a cursor
+	garbage := base64.RawURLEncoding.EncodeToString([]byte("not json"))
+	unparsable := garbage + "." + base64.RawURLEncoding.EncodeToString(signCursor(garbage))
+
+	tokens := map[string]string{
+		"forged payload":     forgedPayload + "." + encodedSignature,
+		"flipped signature":  encodedPayload + "." + flippedSignature,
+		"missing signature":  encodedPayload,
+		"empty signature":    encodedPayload + ".",
+		"malformed base64":   encodedPayload + ".!!!",
+		"truncated":          token[:len(token)-2],
+		"unparsable payload": unparsable,
+	}
+	for name, tok := range tokens {
+		t.Run(name, func(t *testing.T) {
+			if cursor, err := DecodeCursor(tok); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("DecodeCursor(%q) = %+v, %v; want %v", tok, cursor, err, ErrInvalidCursor)
+			}
+		})
+	}
+}
+
+func TestDecodeCursorAfterSecretChange(t *testing.T) {
+	previous := cursorSecret
+	defer func() { cursorSecret = previous }()
+
+	SetCursorSecret("old secret")
+	token := EncodeCursor(Cursor{Sort: "id", Order: "ASC", ID: 10})
+
+	SetCursorSecret("new secret")
+	if _, err := DecodeCursor(token); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("DecodeCursor of a cursor signed with another secret: error %v, want %v", err, ErrInvalidCursor)
+	}
+}
+
+func TestNewCursorPaginationMeta(t *testing.T) {
+	first := Cursor{Sort: "id", Order: "ASC", ID: 11}
+	last := Cursor{Sort: "id", Order: "ASC", ID: 20}
+	after := &Cursor{Sort: "id", Order: "ASC", ID: 10}
+	before := &Cursor{Sort: "id", Order: "ASC", ID: 21, Before: true}
+
+	tests := []struct {
+		name     string
+		cursor   *Cursor
+		hasMore  bool
+		empty    bool
+		wantNext bool
+		wantPrev bool
+	}{
+		{"only page", nil, false, false, false, false},
+		{"first page", nil, true, false, true, false},
+		{"middle page", after, true, false, true, true},
+		{"last page", after, false, false, false, true},
+		{"page before, more before it", before, true, false, true, true},
+		{"first page reached backwards", before, false, false, true, false},
+		{"empty page after", after, false, true, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pageFirst, pageLast *Cursor
+			if !tt.empty {
+				firstCopy, lastCopy := first, last
+				pageFirst, pageLast = &firstCopy, &lastCopy
+			}
+
+			meta := NewCursorPaginationMeta(10, tt.cursor, tt.hasMore, pageFirst, pageLast)
+			if meta.PerPage != 10 || meta.HasNext != tt.wantNext || meta.HasPrev != tt.wantPrev {
+				t.Fatalf("meta = %+v, want per page 10, has next %t, has prev %t", meta, tt.wantNext, tt.wantPrev)
+			}
+
+			// Cursors are only issued for directions that have rows and never for an empty page
+			if wantCursor := tt.wantNext && !tt.empty; (meta.NextCursor != "") != wantCursor {
+				t.Errorf("NextCursor = %q, want one: %t", meta.NextCursor, wantCursor)
+			}
+			if wantCursor := tt.wantPrev && !tt.empty; (meta.PrevCursor != "") != wantCursor {
+				t.Errorf("PrevCursor = %q, want one: %t", meta.PrevCursor, wantCursor)
+			}
+
+			if meta.NextCursor != "" {
+				next, err := DecodeCursor(meta.NextCursor)
+				if err != nil {
+					t.Fatalf("DecodeCursor(NextCursor): %v", err)
+				}
+				if *next != last {
+					t.Errorf("NextCursor = %+v, want after the last row %+v", *next, last)
+				}
+			}
+			if meta.PrevCursor != "" {
+				prev, err := DecodeCursor(meta.PrevCursor)
+				if err != nil {
+					t.Fatalf("DecodeCursor(PrevCursor): %v", err)
+				}
+				want := first
+				want.Before = true
+				if *prev != want {
+					t.Errorf("PrevCursor = %+v, want before the first row %+v", *prev, want)
+				}
+			}
+		})
+	}
+}
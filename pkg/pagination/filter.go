@@ -0,0 +1,465 @@
+package pagination
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"go-template/pkg/apperror"
+)
+
+// Limits that keep filter expressions cheap to parse and to execute
+const (
+	maxFilterLength     = 1024
+	maxFilterConditions = 20
+	maxFilterDepth      = 5
+	maxFilterInValues   = 50
+)
+
+var ErrInvalidFilter = apperror.New(apperror.KindInvalid, apperror.CodeInvalidFilter, "invalid filter")
+
+// FilterOperator is a comparison operator of the filter language
+type FilterOperator string
+
+const (
+	FilterEq       FilterOperator = "eq"
+	FilterNe       FilterOperator = "ne"
+	FilterGt       FilterOperator = "gt"
+	FilterGe       FilterOperator = "ge"
+	FilterLt       FilterOperator = "lt"
+	FilterLe       FilterOperator = "le"
+	FilterIn       FilterOperator = "in"
+	FilterContains FilterOperator = "contains"
+)
+
+var filterComparisons = map[FilterOperator]string{
+	FilterEq: "=",
+	FilterNe: "<>",
+	FilterGt: ">",
+	FilterGe: ">=",
+	FilterLt: "<",
+	FilterLe: "<=",
+}
+
+// FilterExpr is a node of a parsed filter expression
+type FilterExpr interface {
+	filterExpr()
+}
+
+// FilterLogical combines expressions with "and" or "or"
+type FilterLogical struct {
+	Operator string
+	Exprs    []FilterExpr
+}
+
+// FilterNot negates an expression
+type FilterNot struct {
+	Expr FilterExpr
+}
+
+// FilterCondition compares a field with one or more literal values
+type FilterCondition struct {
+	Field    string
+	Operator FilterOperator
+	Values   []FilterValue
+}
+
+// FilterValue is a literal as written in the expression; it is typed against the field when building SQL
+type FilterValue struct {
+	Raw    string
+	Quoted bool
+}
+
+func (FilterLogical) filterExpr()   {}
+func (FilterNot) filterExpr()       {}
+func (FilterCondition) filterExpr() {}
+
+// FilterFieldType determines how literals compared with a field are parsed
+type FilterFieldType int
+
+const (
+	FilterString FilterFieldType = iota
+	FilterInt
+	FilterFloat
+	FilterBool
+	FilterTime
+)
+
+// FilterField maps a public filter field to its column
+type FilterField struct {
+	Column   string
+	Type     FilterFieldType
+	Nullable bool
+}
+
+// FilterSchema whitelists the fields a resource can be filtered by
+type FilterSchema map[string]FilterField
+
+// ParseFilter parses a filter expression such as
+//
+//	role eq 'admin' and (created_at gt 2025-01-01 or name contains 'smith')
+//
+// Conditions are "field operator value" with the operators eq, ne, gt, ge, lt, le, contains
+// and "field in (value, ...)"; they can be combined with and, or, not and parentheses.
+// Strings are single-quoted, with a doubled quote escaping a quote; numbers, booleans, dates and null are bare words.
+func ParseFilter(input string) (FilterExpr, error) {
+	if len(input) > maxFilterLength {
+		return nil, fmt.Errorf("%w: expression is longer than %d characters", ErrInvalidFilter, maxFilterLength)
+	}
+
+	tokens, err := tokenizeFilter(input)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+
+	p := &filterParser{tokens: tokens}
+	expr, err := p.parseOr(0)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("%w: unexpected %q", ErrInvalidFilter, p.tokens[p.pos].text)
+	}
+
+	return expr, nil
+}
+
+// Build renders a parsed expression as a parameterised SQL condition. Every literal is passed
+// through bind, which records the argument and returns its placeholder.
+func (s FilterSchema) Build(expr FilterExpr, bind func(value interface{}) string) (string, error) {
+	switch e := expr.(type) {
+	case FilterLogical:
+		parts := make([]string, len(e.Exprs))
+		for i, sub := range e.Exprs {
+			part, err := s.Build(sub, bind)
+			if err != nil {
+				return "", err
+			}
+			parts[i] = part
+		}
+		return "(" + strings.Join(parts, " "+strings.ToUpper(e.Operator)+" ") + ")", nil
+	case FilterNot:
+		part, err := s.Build(e.Expr, bind)
+		if err != nil {
+			return "", err
+		}
+		return "NOT " + part, nil
+	case FilterCondition:
+		return s.buildCondition(e, bind)
+	default:
+		return "", fmt.Errorf("%w: unsupported expression", ErrInvalidFilter)
+	}
+}
+
+func (s FilterSchema) buildCondition(cond FilterCondition, bind func(value interface{}) string) (string, error) {
+	field, ok := s[cond.Field]
+	if !ok {
+		return "", fmt.Errorf("%w: unknown field %q", ErrInvalidFilter, cond.Field)
+	}
+
+	// null is only meaningful as an equality check
+	if len(cond.Values) == 1 && !cond.Values[0].Quoted && strings.EqualFold(cond.Values[0].Raw, "null") {
+		if !field.Nullable {
+			return "", fmt.Errorf("%w: field %q is never null", ErrInvalidFilter, cond.Field)
+		}
+		switch cond.Operator {
+		case FilterEq:
+			return field.Column + " IS NULL", nil
+		case FilterNe:
+			return field.Column + " IS NOT NULL", nil
+		default:
+			return "", fmt.Errorf("%w: null can only be compared with eq or ne", ErrInvalidFilter)
+		}
+	}
+
+	switch cond.Operator {
+	case FilterContains:
+		if field.Type != FilterString {
+			return "", fmt.Errorf("%w: contains is only supported on text fields", ErrInvalidFilter)
+		}
+		return field.Column + " ILIKE " + bind("%"+escapeLike(cond.Values[0].Raw)+"%"), nil
+	case FilterIn:
+		placeholders := make([]string, len(cond.Values))
+		for i, value := range cond.Values {
+			typed, err := field.convert(cond.Field, value)
+			if err != nil {
+				return "", err
+			}
+			placeholders[i] = bind(typed)
+		}
+		return field.Column + " IN (" + strings.Join(placeholders, ", ") + ")", nil
+	default:
+		if field.Type == FilterBool && cond.Operator != FilterEq && cond.Operator != FilterNe {
+			return "", fmt.Errorf("%w: boolean field %q only supports eq and ne", ErrInvalidFilter, cond.Field)
+		}
+		typed, err := field.convert(cond.Field, cond.Values[0])
+		if err != nil {
+			return "", err
+		}
+		return field.Column + " " + filterComparisons[cond.Operator] + " " + bind(typed), nil
+	}
+}
+
+// convert parses a literal into the Go value matching the field's column type
+func (f FilterField) convert(name string, value FilterValue) (interface{}, error) {
+	switch f.Type {
+	case FilterInt:
+		n, err := strconv.ParseInt(value.Raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q is not a valid integer for %s", ErrInvalidFilter, value.Raw, name)
+		}
+		return n, nil
+	case FilterFloat:
+		n, err := strconv.ParseFloat(value.Raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q is not a valid number for %s", ErrInvalidFilter, value.Raw, name)
+		}
+		return n, nil
+	case FilterBool:
+		b, err := strconv.ParseBool(value.Raw)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q is not a valid boolean for %s", ErrInvalidFilter, value.Raw, name)
+		}
+		return b, nil
+	case FilterTime:
+		for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+			if t, err := time.Parse(layout, value.Raw); err == nil {
+				return t, nil
+			}
+		}
+		return nil, fmt.Errorf("%w: %q is not a valid date (YYYY-MM-DD or RFC3339) for %s", ErrInvalidFilter, value.Raw, name)
+	default:
+		return value.Raw, nil
+	}
+}
+
+// escapeLike escapes the ILIKE wildcards so contains matches the value literally
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+type filterTokenKind int
+
+const (
+	filterWord filterTokenKind = iota
+	filterString
+	filterLParen
+	filterRParen
+	filterComma
+)
+
+type filterToken struct {
+	kind filterTokenKind
+	text string
+}
+
+func tokenizeFilter(input string) ([]filterToken, error) {
+	var tokens []filterToken
+	runes := []rune(input)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, filterToken{kind: filterLParen, text: "("})
+			i++
+		case r == ')':
+			tokens = append(tokens, filterToken{kind: filterRParen, text: ")"})
+			i++
+		case r == ',':
+			tokens = append(tokens, filterToken{kind: filterComma, text: ","})
+			i++
+		case r == '\'':
+			var sb strings.Builder
+			i++
+			for {
+				if i >= len(runes) {
+					return nil, fmt.Errorf("%w: unterminated string", ErrInvalidFilter)
+				}
+				if runes[i] == '\'' {
+					// A doubled quote is an escaped quote inside the string
+					if i+1 < len(runes) && runes[i+1] == '\'' {
+						sb.WriteRune('\'')
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				sb.WriteRune(runes[i])
+				i++
+			}
+			tokens = append(tokens, filterToken{kind: filterString, text: sb.String()})
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && !strings.ContainsRune("(),'", runes[i]) {
+				i++
+			}
+			tokens = append(tokens, filterToken{kind: filterWord, text: string(runes[start:i])})
+		}
+	}
+
+	return tokens, nil
+}
+
+type filterParser struct {
+	tokens     []filterToken
+	pos        int
+	conditions int
+}
+
+func (p *filterParser) peekKeyword(keyword string) bool {
+	return p.pos < len(p.tokens) && p.tokens[p.pos].kind == filterWord && strings.EqualFold(p.tokens[p.pos].text, keyword)
+}
+
+func (p *filterParser) parseOr(depth int) (FilterExpr, error) {
+	return p.parseLogical(depth, "or", p.parseAnd)
+}
+
+func (p *filterParser) parseAnd(depth int) (FilterExpr, error) {
+	return p.parseLogical(depth, "and", p.parseUnary)
+}
+
+func (p *filterParser) parseLogical(depth int, operator string, next func(int) (FilterExpr, error)) (FilterExpr, error) {
+	expr, err := next(depth)
+	if err != nil {
+		return nil, err
+	}
+
+	exprs := []FilterExpr{expr}
+	for p.peekKeyword(operator) {
+		p.pos++
+		expr, err := next(depth)
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+	}
+
+	if len(exprs) == 1 {
+		return exprs[0], nil
+	}
+	return FilterLogical{Operator: operator, Exprs: exprs}, nil
+}
+
+func (p *filterParser) parseUnary(depth int) (FilterExpr, error) {
+	if depth > maxFilterDepth {
+		return nil, fmt.Errorf("%w: expression is nested more than %d levels deep", ErrInvalidFilter, maxFilterDepth)
+	}
+	if p.pos >= len(p.tokens) {
+		return nil, fmt.Errorf("%w: unexpected end of expression", ErrInvalidFilter)
+	}
+
+	if p.peekKeyword("not") {
+		p.pos++
+		expr, err := p.parseUnary(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return FilterNot{Expr: expr}, nil
+	}
+
+	if p.tokens[p.pos].kind == filterLParen {
+		p.pos++
+		expr, err := p.parseOr(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if p.pos >= len(p.tokens) || p.tokens[p.pos].kind != filterRParen {
+			return nil, fmt.Errorf("%w: missing closing parenthesis", ErrInvalidFilter)
+		}
+		p.pos++
+		return expr, nil
+	}
+
+	return p.parseCondition()
+}
+
+func (p *filterParser) parseCondition() (FilterExpr, error) {
+	p.conditions++
+	if p.conditions > maxFilterConditions {
+		return nil, fmt.Errorf("%w: expression has more than %d conditions", ErrInvalidFilter, maxFilterConditions)
+	}
+
+	if p.pos+1 >= len(p.tokens) || p.tokens[p.pos].kind != filterWord || p.tokens[p.pos+1].kind != filterWord {
+		return nil, fmt.Errorf("%w: expected \"field operator value\"", ErrInvalidFilter)
+	}
+	field := strings.ToLower(p.tokens[p.pos].text)
+	operator := FilterOperator(strings.ToLower(p.tokens[p.pos+1].text))
+	p.pos += 2
+
+	if operator == FilterIn {
+		values, err := p.parseValueList()
+		if err != nil {
+			return nil, err
+		}
+		return FilterCondition{Field: field, Operator: operator, Values: values}, nil
+	}
+
+	if _, ok := filterComparisons[operator]; !ok && operator != FilterContains {
+		return nil, fmt.Errorf("%w: unknown operator %q", ErrInvalidFilter, operator)
+	}
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+
+	return FilterCondition{Field: field, Operator: operator, Values: []FilterValue{value}}, nil
+}
+
+func (p *filterParser) parseValueList() ([]FilterValue, error) {
+	if p.pos >= len(p.tokens) || p.tokens[p.pos].kind != filterLParen {
+		return nil, fmt.Errorf("%w: in expects a parenthesised list of values", ErrInvalidFilter)
+	}
+	p.pos++
+
+	var values []FilterValue
+	for {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+		if len(values) > maxFilterInValues {
+			return nil, fmt.Errorf("%w: in accepts at most %d values", ErrInvalidFilter, maxFilterInValues)
+		}
+
+		if p.pos >= len(p.tokens) {
+			return nil, fmt.Errorf("%w: missing closing parenthesis", ErrInvalidFilter)
+		}
+		switch p.tokens[p.pos].kind {
+		case filterComma:
+			p.pos++
+		case filterRParen:
+			p.pos++
+			return values, nil
+		default:
+			return nil, fmt.Errorf("%w: unexpected %q in value list", ErrInvalidFilter, p.tokens[p.pos].text)
+		}
+	}
+}
+
+func (p *filterParser) parseValue() (FilterValue, error) {
+	if p.pos >= len(p.tokens) {
+		return FilterValue{}, fmt.Errorf("%w: missing value", ErrInvalidFilter)
+	}
+
+	token := p.tokens[p.pos]
+	switch token.kind {
+	case filterString:
+		p.pos++
+		return FilterValue{Raw: token.text, Quoted: true}, nil
+	case filterWord:
+		p.pos++
+		return FilterValue{Raw: token.text}, nil
+	default:
+		return FilterValue{}, fmt.Errorf("%w: expected a value but got %q", ErrInvalidFilter, token.text)
+	}
+}
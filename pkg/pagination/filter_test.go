@@ -0,0 +1,221 @@
+package pagination
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testFilterSchema = FilterSchema{
+	"id":         {Column: "id", Type: FilterInt},
+	"name":       {Column: "name", Type: FilterString},
+	"bio":        {Column: "bio", Type: FilterString, Nullable: true},
+	"score":      {Column: "score", Type: FilterFloat},
+	"active":     {Column: "active", Type: FilterBool},
+	"created_at": {Column: "created_at", Type: FilterTime},
+	"width":      {Column: "(metadata->>'width')::integer", Type: FilterInt, Nullable: true},
+}
+
+// buildFilter parses and renders input with testFilterSchema, numbering placeholders from $1
+func buildFilter(input string) (string, []interface{}, error) {
+	expr, err := ParseFilter(input)
+	if err != nil || expr == nil {
+		return "", nil, err
+	}
+
+	var args []interface{}
+	sql, err := testFilterSchema.Build(expr, func(value interface{}) string {
+		args = append(args, value)
+		return "$" + strconv.Itoa(len(args))
+	})
+	return sql, args, err
+}
+
+// repeatConditions joins n conditions on id with operator
+func repeatConditions(n int, operator string) string {
+	conditions := make([]string, n)
+	for i := range conditions {
+		conditions[i] = "id eq " + strconv.Itoa(i)
+	}
+	return strings.Join(conditions, " "+operator+" ")
+}
+
+// nest wraps the condition in depth parentheses
+func nest(depth int, condition string) string {
+	return strings.Repeat("(", depth) + condition + strings.Repeat(")", depth)
+}
+
+func TestFilterBuild(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		sql   string
+		args  []interface{}
+	}{
+		// Precedence: not binds tighter than and, and tighter than or
+		{"and before or", "id eq 1 or id eq 2 and id eq 3", "(id = $1 OR (id = $2 AND id = $3))", []interface{}{int64(1), int64(2), int64(3)}},
+		{"parentheses", "(id eq 1 or id eq 2) and id eq 3", "((id = $1 OR id = $2) AND id = $3)", []interface{}{int64(1), int64(2), int64(3)}},
+		{"not before and", "not id eq 1 and id eq 2", "(NOT id = $1 AND id = $2)", []interface{}{int64(1), int64(2)}},
+		{"not group", "not (id eq 1 or id eq 2)", "NOT (id = $1 OR id = $2)", []interface{}{int64(1), int64(2)}},
+		{"double not", "not not id eq 1", "NOT NOT id = $1", []interface{}{int64(1)}},
+		{"flattened chain", "id eq 1 and id eq 2 and id eq 3", "(id = $1 AND id = $2 AND id = $3)", []interface{}{int64(1), int64(2), int64(3)}},
+		{"redundant parentheses", "((id eq 1))", "id = $1", []interface{}{int64(1)}},
+		{"keywords in any case", "ID EQ 1 AND Name Ne 'x' OR NOT active EQ true", "((id = $1 AND name <> $2) OR NOT active = $3)", []interface{}{int64(1), "x", true}},
+		{"no spaces around parentheses", "(id eq 1)and(id eq 2)", "(id = $1 AND id = $2)", []interface{}{int64(1), int64(2)}},
+
+		// Operators and the types of their values
+		{"comparisons", "id gt 1 and id ge 2 and id lt 3 and id le 4 and id ne 5", "(id > $1 AND id >= $2 AND id < $3 AND id <= $4 AND id <> $5)", []interface{}{int64(1), int64(2), int64(3), int64(4), int64(5)}},
+		{"in", "id in (1, 2,3)", "id IN ($1, $2, $3)", []interface{}{int64(1), int64(2), int64(3)}},
+		{"in one value", "name in ('a')", "name IN ($1)", []interface{}{"a"}},
+		{"float", "score ge 1.5", "score >= $1", []interface{}{1.5}},
+		{"negative int", "id gt -3", "id > $1", []interface{}{int64(-3)}},
+		{"bool", "active ne false", "active <> $1", []interface{}{false}},
+		{"date", "created_at lt 2025-01-02", "created_at < $1", []interface{}{time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)}},
+		{"timestamp", "created_at ge 2025-01-02T03:04:05Z", "created_at >= $1", []interface{}{time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)}},
+		{"bare word string", "name eq smith", "name = $1", []interface{}{"smith"}},
+		{"column expression", "width gt 100", "(metadata->>'width')::integer > $1", []interface{}{int64(100)}},
+
+		// Quoting and escaping
+		{"quoted string", "name eq 'John Smith'", "name = $1", []interface{}{"John Smith"}},
+		{"doubled quote", "name eq 'O''Brien'", "name = $1", []interface{}{"O'Brien"}},
+		{"only a quote", "name eq ''''", "name = $1", []interface{}{"'"}},
+		{"empty string", "name eq ''", "name = $1", []interface{}{""}},
+		{"keywords and punctuation in a string", "name eq 'a and (b, c) or not d'", "name = $1", []interface{}{"a and (b, c) or not d"}},
+		{"sql in a string", "name eq 'x''; DROP TABLE users; --'", "name = $1", []interface{}{"x'; DROP TABLE users; --"}},
+		{"contains", "name contains 'smith'", "name ILIKE $1", []interface{}{"%smith%"}},
+		{"contains escapes wildcards", `name contains '50%_off\'`, "name ILIKE $1", []interface{}{`%50\%\_off\\%`}},
+		{"unicode", "name eq 'Zoë'", "name = $1", []interface{}{"Zoë"}},
+
+		// null
+		{"eq null", "bio eq null", "bio IS NULL", nil},
+		{"ne null", "bio ne NULL", "bio IS NOT NULL", nil},
+		{"null on column expression", "width eq null", "(metadata->>'width')::integer IS NULL", nil},
+		{"quoted null is a string", "bio eq 'null'", "bio = $1", []interface{}{"null"}},
+		{"not null", "not bio eq null", "NOT bio IS NULL", nil},
+
+		// Empty expressions filter nothing
+		{"empty", "", "", nil},
+		{"blank", "  \t ", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args, err := buildFilter(tt.input)
+			if err != nil {
+				t.Fatalf("buildFilter(%q): %v", tt.input, err)
+			}
+			if sql != tt.sql {
+				t.Errorf("buildFilter(%q) SQL = %q, want %q", tt.input, sql, tt.sql)
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("buildFilter(%q) args = %#v, want %#v", tt.input, args, tt.args)
+			}
+		})
+	}
+}
+
+func TestFilterErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		message string
+	}{
+		// Unknown fields and operators
+		{"unknown field", "password eq 'x'", `unknown field "password"`},
+		{"unknown field in or", "id eq 1 or secret eq 2", `unknown field "secret"`},
+		{"column name is not a field", "metadata eq 'x'", `unknown field "metadata"`},
+		{"unknown operator", "id like 1", `unknown operator "like"`},
+		{"symbol operator", "id = 1", `unknown operator "="`},
+		{"contains on number", "id contains 1", "contains is only supported on text fields"},
+		{"ordering a boolean", "active gt true", `boolean field "active" only supports eq and ne`},
+
+		// Values of the wrong type
+		{"invalid int", "id eq abc", `"abc" is not a valid integer for id`},
+		{"quoted int", "id eq '1x'", `"1x" is not a valid integer for id`},
+		{"invalid int in list", "id in (1, x)", `"x" is not a valid integer for id`},
+		{"invalid float", "score gt high", `"high" is not a valid number for score`},
+		{"invalid bool", "active eq yes", `"yes" is not a valid boolean for active`},
+		{"invalid date", "created_at gt 2025-13-01", `"2025-13-01" is not a valid date`},
+
+		// null
+		{"null on non-nullable field", "id eq null", `field "id" is never null`},
+		{"ordering null", "bio gt null", "null can only be compared with eq or ne"},
+		{"null contains", "bio contains null", "null can only be compared with eq or ne"},
+
+		// Syntax
+		{"missing value", "id eq", "missing value"},
+		{"missing operator", "id", `expected "field operator value"`},
+		{"string as field", "'id' eq 1", `expected "field operator value"`},
+		{"dangling and", "id eq 1 and", "unexpected end of expression"},
+		{"leading or", "or id eq 1", `unknown operator "id"`},
+		{"missing closing parenthesis", "(id eq 1", "missing closing parenthesis"},
+		{"extra closing parenthesis", "id eq 1)", `unexpected ")"`},
+		{"empty parentheses", "()", `expected "field operator value"`},
+		{"conditions without operator", "id eq 1 id eq 2", `unexpected "id"`},
+		{"unterminated string", "name eq 'abc", "unterminated string"},
+		{"unterminated escaped quote", "name eq 'abc''", "unterminated string"},
+		{"in without list", "id in 1", "in expects a parenthesised list of values"},
+		{"in without separator", "id in (1 2)", `unexpected "2" in value list`},
+		{"in unterminated", "id in (1,", "missing value"},
+		{"in unclosed", "id in (1", "missing closing parenthesis"},
+		{"in empty", "id in ()", `expected a value but got ")"`},
+		{"value is a parenthesis", "id eq (1)", `expected a value but got "("`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := buildFilter(tt.input)
+			if !errors.Is(err, ErrInvalidFilter) {
+				t.Fatalf("buildFilter(%q) error = %v, want ErrInvalidFilter", tt.input, err)
+			}
+			if !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("buildFilter(%q) error = %q, want it to mention %q", tt.input, err, tt.message)
+			}
+		})
+	}
+}
+
+func TestFilterLimits(t *testing.T) {
+	inValues := func(n int) string {
+		values := make([]string, n)
+		for i := range values {
+			values[i] = strconv.Itoa(i)
+		}
+		return "id in (" + strings.Join(values, ",") + ")"
+	}
+	longest := "name eq '" + strings.Repeat("a", maxFilterLength-len("name eq ''")) + "'"
+
+	tests := []struct {
+		name    string
+		input   string
+		message string // Empty when the expression is within the limits
+	}{
+		{"longest expression", longest, ""},
+		{"too long", longest + " ", "expression is longer than 1024 characters"},
+		{"deepest parentheses", nest(maxFilterDepth, "id eq 1"), ""},
+		{"parentheses too deep", nest(maxFilterDepth+1, "id eq 1"), "nested more than 5 levels deep"},
+		{"deepest not", strings.Repeat("not ", maxFilterDepth) + "id eq 1", ""},
+		{"not too deep", strings.Repeat("not ", maxFilterDepth+1) + "id eq 1", "nested more than 5 levels deep"},
+		{"not and parentheses too deep", strings.Repeat("not (", 3) + "id eq 1" + strings.Repeat(")", 3), "nested more than 5 levels deep"},
+		{"most conditions", repeatConditions(maxFilterConditions, "or"), ""},
+		{"too many conditions", repeatConditions(maxFilterConditions+1, "or"), "more than 20 conditions"},
+		{"too many conditions across groups", "(" + repeatConditions(10, "and") + ") or (" + repeatConditions(11, "and") + ")", "more than 20 conditions"},
+		{"most in values", inValues(maxFilterInValues), ""},
+		{"too many in values", inValues(maxFilterInValues + 1), "in accepts at most 50 values"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := buildFilter(tt.input)
+			if tt.message == "" {
+				if err != nil {
+					t.Fatalf("buildFilter: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidFilter) || !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("buildFilter error = %v, want ErrInvalidFilter mentioning %q", err, tt.message)
+			}
+		})
+	}
+}
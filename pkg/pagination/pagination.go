@@ -15,16 +15,21 @@ type PaginationParams struct {
 	Sort   string `json:"sort"`
 	Order  string `json:"order"`
 	Search string `json:"search"`
+	Cursor string `json:"cursor,omitempty"`
+	// CursorMode selects keyset pagination, enabled by passing a cursor parameter (empty for the first page)
+	CursorMode bool `json:"-"`
 }
 
 // PaginationMeta represents pagination metadata in response
 type PaginationMeta struct {
-	CurrentPage  int  `json:"current_page"`
-	PerPage      int  `json:"per_page"`
-	TotalRecords int  `json:"total_records"`
-	TotalPages   int  `json:"total_pages"`
-	HasNext      bool `json:"has_next"`
-	HasPrev      bool `json:"has_prev"`
+	CurrentPage  int    `json:"current_page"`
+	PerPage      int    `json:"per_page"`
+	TotalRecords int    `json:"total_records"`
+	TotalPages   int    `json:"total_pages"`
+	HasNext      bool   `json:"has_next"`
+	HasPrev      bool   `json:"has_prev"`
+	NextCursor   string `json:"next_cursor,omitempty"`
+	PrevCursor   string `json:"prev_cursor,omitempty"`
 }
 
 // FilterParams represents filtering parameters for users
@@ -35,6 +40,11 @@ type FilterParams struct {
 	CreatedAfter string `json:"created_after"`
 	CreatedBefore string `json:"created_before"`
 	EmailVerified *bool  `json:"email_verified"`
+	LastLoginAfter string `json:"last_login_after"`
+	LastLoginBefore string `json:"last_login_before"`
+	NeverLoggedIn *bool  `json:"never_logged_in"`
+	// Filter is a filter expression, see ParseFilter
+	Filter string `json:"filter"`
 }
 
 // FileFilterParams represents filtering parameters for files
@@ -45,6 +55,11 @@ type FileFilterParams struct {
 	UploadedBy    *int   `json:"uploaded_by"`
 	CreatedAfter  string `json:"created_after"`
 	CreatedBefore string `json:"created_before"`
+	FolderID      *int   `json:"folder_id"`
+	// Recursive also includes the files in subfolders of FolderID
+	Recursive bool `json:"recursive"`
+	// Filter is a filter expression, see ParseFilter
+	Filter string `json:"filter"`
 }
 
 // GetPaginationParams extracts pagination parameters from Echo context
@@ -72,11 +87,13 @@ func GetPaginationParams(c echo.Context) PaginationParams {
 	search := strings.TrimSpace(c.QueryParam("search"))
 
 	return PaginationParams{
-		Page:   page,
-		Limit:  limit,
-		Sort:   sort,
-		Order:  order,
-		Search: search,
+		Page:       page,
+		Limit:      limit,
+		Sort:       sort,
+		Order:      order,
+		Search:     search,
+		Cursor:     strings.TrimSpace(c.QueryParam("cursor")),
+		CursorMode: c.QueryParams().Has("cursor"),
 	}
 }
 
@@ -89,13 +106,24 @@ func GetFilterParams(c echo.Context) FilterParams {
 		}
 	}
 
+	var neverLoggedIn *bool
+	if neverLoggedInStr := c.QueryParam("never_logged_in"); neverLoggedInStr != "" {
+		if val, err := strconv.ParseBool(neverLoggedInStr); err == nil {
+			neverLoggedIn = &val
+		}
+	}
+
 	return FilterParams{
-		Name:          strings.TrimSpace(c.QueryParam("name")),
-		Email:         strings.TrimSpace(c.QueryParam("email")),
-		Role:          strings.TrimSpace(c.QueryParam("role")),
-		CreatedAfter:  strings.TrimSpace(c.QueryParam("created_after")),
-		CreatedBefore: strings.TrimSpace(c.QueryParam("created_before")),
-		EmailVerified: emailVerified,
+		Name:            strings.TrimSpace(c.QueryParam("name")),
+		Email:           strings.TrimSpace(c.QueryParam("email")),
+		Role:            strings.TrimSpace(c.QueryParam("role")),
+		CreatedAfter:    strings.TrimSpace(c.QueryParam("created_after")),
+		CreatedBefore:   strings.TrimSpace(c.QueryParam("created_before")),
+		EmailVerified:   emailVerified,
+		LastLoginAfter:  strings.TrimSpace(c.QueryParam("last_login_after")),
+		LastLoginBefore: strings.TrimSpace(c.QueryParam("last_login_before")),
+		NeverLoggedIn:   neverLoggedIn,
+		Filter:          strings.TrimSpace(c.QueryParam("filter")),
 	}
 }
 
@@ -108,6 +136,14 @@ func GetFileFilterParams(c echo.Context) FileFilterParams {
 		}
 	}
 
+	var folderID *int
+	if folderIDStr := c.QueryParam("folder_id"); folderIDStr != "" {
+		if val, err := strconv.Atoi(folderIDStr); err == nil {
+			folderID = &val
+		}
+	}
+	recursive, _ := strconv.ParseBool(c.QueryParam("recursive"))
+
 	return FileFilterParams{
 		FileName:      strings.TrimSpace(c.QueryParam("file_name")),
 		MimeType:      strings.TrimSpace(c.QueryParam("mime_type")),
@@ -115,6 +151,9 @@ func GetFileFilterParams(c echo.Context) FileFilterParams {
 		UploadedBy:    uploadedBy,
 		CreatedAfter:  strings.TrimSpace(c.QueryParam("created_after")),
 		CreatedBefore: strings.TrimSpace(c.QueryParam("created_before")),
+		FolderID:      folderID,
+		Recursive:     recursive,
+		Filter:        strings.TrimSpace(c.QueryParam("filter")),
 	}
 }
 
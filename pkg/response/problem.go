@@ -0,0 +1,169 @@
+package response
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"go-template/pkg/apperror"
+	"go-template/pkg/i18n"
+
+	"github.com/labstack/echo/v4"
+)
+
+// MIMEApplicationProblemJSON is the media type of RFC 9457 problem details
+const MIMEApplicationProblemJSON = "application/problem+json"
+
+// ErrorOptions controls how error responses are rendered
+type ErrorOptions struct {
+	// ProblemDetails renders every error as problem details; otherwise only clients that
+	// accept application/problem+json get them and the others get the Response envelope
+	ProblemDetails bool
+	// TypeBaseURL prefixes the error code to form the problem type URI; about:blank when empty
+	TypeBaseURL string
+	// ExposeInternalErrors includes the causes of server errors in responses, for development only
+	ExposeInternalErrors bool
+}
+
+var errorOptions ErrorOptions
+
+// ConfigureErrors sets the error rendering options at startup
+func ConfigureErrors(options ErrorOptions) {
+	errorOptions = options
+}
+
+// Problem is an RFC 9457 problem details object extended with the stable error code
+type Problem struct {
+	Type      string      `json:"type"`
+	Title     string      `json:"title"`
+	Status    int         `json:"status"`
+	Detail    string      `json:"detail,omitempty"`
+	Instance  string      `json:"instance,omitempty"`
+	Code      string      `json:"code"`
+	RequestID string      `json:"request_id,omitempty"`
+	Errors    interface{} `json:"errors,omitempty"`
+}
+
+// Error renders any error as an error response. Domain errors keep their status, code and message;
+// Echo HTTP errors keep their status; everything else becomes an opaque internal error.
+func Error(c echo.Context, err error) error {
+	status, code, message, details := describeError(err)
+	if appErr, ok := apperror.As(err); ok {
+		// Translate the domain message and keep any context it was wrapped with as is
+		return renderError(c, status, code, i18n.TPrefix(locale(c), message, appErr.Message), details)
+	}
+	return writeError(c, status, code, message, details)
+}
+
+// ErrorStatus returns the status code Error responds with for err
+func ErrorStatus(err error) int {
+	status, _, _, _ := describeError(err)
+	return status
+}
+
+func describeError(err error) (int, string, string, interface{}) {
+	if appErr, ok := apperror.As(err); ok {
+		status := appErr.HTTPStatus()
+		if status >= http.StatusInternalServerError {
+			var details interface{}
+			if cause := errors.Unwrap(appErr); cause != nil {
+				details = cause.Error()
+			}
+			return status, appErr.Code, appErr.Message, details
+		}
+		// Client errors may be wrapped with extra context, e.g. the offending field of a filter
+		return status, appErr.Code, err.Error(), appErr.Details
+	}
+
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		message := http.StatusText(httpErr.Code)
+		if m, ok := httpErr.Message.(string); ok {
+			message = m
+		}
+		code := codeForStatus(httpErr.Code)
+		if httpErr.Code == http.StatusNotFound {
+			code = apperror.CodeRouteNotFound
+		}
+		return httpErr.Code, code, message, nil
+	}
+
+	return http.StatusInternalServerError, apperror.CodeInternal, "Internal server error", err.Error()
+}
+
+// writeError translates an English message into the request locale and renders the error
+func writeError(c echo.Context, status int, code, message string, details interface{}) error {
+	return renderError(c, status, code, i18n.T(locale(c), message), details)
+}
+
+// renderError renders an error as problem details or as the Response envelope. Details of server
+// errors are dropped unless ExposeInternalErrors is set.
+func renderError(c echo.Context, status int, code, message string, details interface{}) error {
+	if status >= http.StatusInternalServerError && !errorOptions.ExposeInternalErrors {
+		details = nil
+	}
+
+	// Replace any content type set by a handler before it failed, e.g. for a streamed export
+	if !wantsProblem(c) {
+		c.Response().Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		return c.JSON(status, Response{
+			Success: false,
+			Message: message,
+			Code:    code,
+			Error:   details,
+		})
+	}
+
+	problemType := "about:blank"
+	if errorOptions.TypeBaseURL != "" {
+		problemType = strings.TrimRight(errorOptions.TypeBaseURL, "/") + "/" + code
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, MIMEApplicationProblemJSON)
+	return c.JSON(status, Problem{
+		Type:      problemType,
+		Title:     i18n.T(locale(c), http.StatusText(status)),
+		Status:    status,
+		Detail:    message,
+		Instance:  c.Request().URL.Path,
+		Code:      code,
+		RequestID: c.Response().Header().Get(echo.HeaderXRequestID),
+		Errors:    details,
+	})
+}
+
+func wantsProblem(c echo.Context) bool {
+	return errorOptions.ProblemDetails || strings.Contains(c.Request().Header.Get(echo.HeaderAccept), MIMEApplicationProblemJSON)
+}
+
+// codeForStatus picks the generic catalogue code for errors that carry only a status
+func codeForStatus(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return apperror.CodeInvalidRequest
+	case http.StatusUnauthorized:
+		return apperror.CodeUnauthorized
+	case http.StatusForbidden:
+		return apperror.CodeForbidden
+	case http.StatusNotFound:
+		return apperror.CodeNotFound
+	case http.StatusMethodNotAllowed:
+		return apperror.CodeMethodNotAllowed
+	case http.StatusConflict:
+		return apperror.CodeConflict
+	case http.StatusRequestEntityTooLarge:
+		return apperror.CodeRequestTooLarge
+	case http.StatusUnsupportedMediaType:
+		return apperror.CodeUnsupportedMediaType
+	case http.StatusUnprocessableEntity:
+		return apperror.CodeValidationFailed
+	case http.StatusTooManyRequests:
+		return apperror.CodeRateLimited
+	case http.StatusServiceUnavailable:
+		return apperror.CodeUnavailable
+	}
+	if status >= http.StatusInternalServerError {
+		return apperror.CodeInternal
+	}
+	return apperror.CodeInvalidRequest
+}
@@ -3,6 +3,8 @@ package response
 import (
 	"net/http"
 
+	"go-template/pkg/apperror"
+	"go-template/pkg/i18n"
 	"go-template/pkg/pagination"
 
 	"github.com/labstack/echo/v4"
@@ -11,6 +13,7 @@ import (
 type Response struct {
 	Success    bool                     `json:"success"`
 	Message    string                   `json:"message"`
+	Code       string                   `json:"code,omitempty"`
 	Data       interface{}              `json:"data,omitempty"`
 	Error      interface{}              `json:"error,omitempty"`
 	Pagination *pagination.PaginationMeta `json:"pagination,omitempty"`
@@ -19,7 +22,7 @@ type Response struct {
 func Success(c echo.Context, message string, data interface{}) error {
 	return c.JSON(http.StatusOK, Response{
 		Success: true,
-		Message: message,
+		Message: i18n.T(locale(c), message),
 		Data:    data,
 	})
 }
@@ -27,78 +30,63 @@ func Success(c echo.Context, message string, data interface{}) error {
 func Created(c echo.Context, message string, data interface{}) error {
 	return c.JSON(http.StatusCreated, Response{
 		Success: true,
-		Message: message,
+		Message: i18n.T(locale(c), message),
 		Data:    data,
 	})
 }
 
-func BadRequest(c echo.Context, message string, err interface{}) error {
-	return c.JSON(http.StatusBadRequest, Response{
-		Success: false,
-		Message: message,
-		Error:   err,
+func Accepted(c echo.Context, message string, data interface{}) error {
+	return c.JSON(http.StatusAccepted, Response{
+		Success: true,
+		Message: i18n.T(locale(c), message),
+		Data:    data,
 	})
 }
 
+func BadRequest(c echo.Context, message string, err interface{}) error {
+	return writeError(c, http.StatusBadRequest, apperror.CodeInvalidRequest, message, err)
+}
+
 func TooManyRequest(c echo.Context, message string, err interface{}) error {
-	return c.JSON(http.StatusTooManyRequests, Response{
-		Success: false,
-		Message: message,
-		Error:   err,
-	})
+	return writeError(c, http.StatusTooManyRequests, apperror.CodeRateLimited, message, err)
 }
 
 func Unauthorized(c echo.Context, message string) error {
-	return c.JSON(http.StatusUnauthorized, Response{
-		Success: false,
-		Message: message,
-	})
+	return writeError(c, http.StatusUnauthorized, apperror.CodeUnauthorized, message, nil)
 }
 
 func Forbidden(c echo.Context, message string) error {
-	return c.JSON(http.StatusForbidden, Response{
-		Success: false,
-		Message: message,
-	})
+	return writeError(c, http.StatusForbidden, apperror.CodeForbidden, message, nil)
 }
 
 func NotFound(c echo.Context, message string) error {
-	return c.JSON(http.StatusNotFound, Response{
-		Success: false,
-		Message: message,
-	})
+	return writeError(c, http.StatusNotFound, apperror.CodeNotFound, message, nil)
 }
 
 func Conflict(c echo.Context, message string, err interface{}) error {
-	return c.JSON(http.StatusConflict, Response{
-		Success: false,
-		Message: message,
-		Error:   err,
-	})
+	return writeError(c, http.StatusConflict, apperror.CodeConflict, message, err)
 }
 
 func InternalServerError(c echo.Context, message string, err interface{}) error {
-	return c.JSON(http.StatusInternalServerError, Response{
-		Success: false,
-		Message: message,
-		Error:   err,
-	})
+	return writeError(c, http.StatusInternalServerError, apperror.CodeInternal, message, err)
 }
 
 func ValidationError(c echo.Context, message string, validationErrors interface{}) error {
-	return c.JSON(http.StatusUnprocessableEntity, Response{
-		Success: false,
-		Message: message,
-		Error:   validationErrors,
-	})
+	return writeError(c, http.StatusUnprocessableEntity, apperror.CodeValidationFailed, message, validationErrors)
 }
 
 // SuccessWithPagination returns success response with pagination metadata
 func SuccessWithPagination(c echo.Context, message string, data interface{}, paginationMeta pagination.PaginationMeta) error {
 	return c.JSON(http.StatusOK, Response{
 		Success:    true,
-		Message:    message,
+		Message:    i18n.T(locale(c), message),
 		Data:       data,
 		Pagination: &paginationMeta,
 	})
 }
+
+// locale returns the locale negotiated for the request; messages passed to the helpers are English
+// and translated into it
+func locale(c echo.Context) string {
+	return i18n.FromContext(c.Request().Context())
+}
\ No newline at end of file
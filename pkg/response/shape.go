@@ -0,0 +1,203 @@
+package response
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"go-template/pkg/apperror"
+
+	"github.com/labstack/echo/v4"
+)
+
+var ErrUnknownExpansion = apperror.New(apperror.KindInvalid, apperror.CodeUnknownExpansion, "unknown expansion")
+
+// ShapeParams selects the fields (?fields=id,name) and the related resources (?expand=uploaded_by)
+// of a response. Fields of a related resource are selected with a dotted path, e.g. uploaded_by.name,
+// which expands the resource when it is not listed in Expand.
+type ShapeParams struct {
+	Fields []string `json:"fields"`
+	Expand []string `json:"expand"`
+}
+
+// Expander loads the related resources referenced by ids in a single batch, keyed by id
+type Expander func(ctx context.Context, ids []int) (map[int]interface{}, error)
+
+// GetShapeParams extracts the fields and expand parameters from Echo context
+func GetShapeParams(c echo.Context) ShapeParams {
+	return ShapeParams{
+		Fields: splitList(c.QueryParam("fields")),
+		Expand: splitList(c.QueryParam("expand")),
+	}
+}
+
+// Shape applies sparse fieldsets and expansions to a response object or a slice of them. Expanded
+// fields hold the id of the related resource; all ids of a response are resolved with one call to
+// the field's expander and replaced by the resource. Unknown fields are ignored, as are dotted paths
+// into fields that are not objects.
+func Shape(ctx context.Context, data interface{}, params ShapeParams, expanders map[string]Expander) (interface{}, error) {
+	if len(params.Fields) == 0 && len(params.Expand) == 0 {
+		return data, nil
+	}
+	for _, field := range params.Expand {
+		if _, ok := expanders[field]; !ok {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownExpansion, field)
+		}
+	}
+
+	// Selecting fields of a related resource implies expanding it, rather than returning its id
+	expansions := slices.Clone(params.Expand)
+	for _, field := range params.Fields {
+		parent, _, ok := strings.Cut(field, ".")
+		if _, expandable := expanders[parent]; ok && expandable && !slices.Contains(expansions, parent) {
+			expansions = append(expansions, parent)
+		}
+	}
+
+	shaped, err := toGeneric(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []map[string]interface{}
+	switch v := shaped.(type) {
+	case map[string]interface{}:
+		items = []map[string]interface{}{v}
+	case []interface{}:
+		for _, item := range v {
+			if object, ok := item.(map[string]interface{}); ok {
+				items = append(items, object)
+			}
+		}
+	default:
+		return data, nil
+	}
+
+	for _, field := range expansions {
+		if err := expand(ctx, items, field, expanders[field]); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(params.Fields) > 0 {
+		selected, nested := parseFieldPaths(params.Fields)
+		// Requesting an expansion implies returning it
+		for _, field := range expansions {
+			selected[field] = true
+		}
+		for _, item := range items {
+			project(item, selected, nested)
+		}
+	}
+
+	return shaped, nil
+}
+
+// expand replaces the ids stored in field by the related resources
+func expand(ctx context.Context, items []map[string]interface{}, field string, expander Expander) error {
+	var ids []int
+	for _, item := range items {
+		if id, ok := toID(item[field]); ok && !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	resources, err := expander(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		id, ok := toID(item[field])
+		if !ok {
+			continue
+		}
+		resource, ok := resources[id]
+		if !ok {
+			// Keep the bare id when the related resource no longer exists
+			continue
+		}
+		generic, err := toGeneric(resource)
+		if err != nil {
+			return err
+		}
+		item[field] = generic
+	}
+
+	return nil
+}
+
+// project removes the fields that were not selected, descending into objects for dotted paths
+func project(item map[string]interface{}, selected map[string]bool, nested map[string][]string) {
+	for key, value := range item {
+		if subFields, ok := nested[key]; ok {
+			if object, ok := value.(map[string]interface{}); ok {
+				subSelected, subNested := parseFieldPaths(subFields)
+				project(object, subSelected, subNested)
+				continue
+			}
+		}
+		if !selected[key] {
+			delete(item, key)
+		}
+	}
+}
+
+// parseFieldPaths splits field paths into top-level fields and the sub-paths of nested objects
+func parseFieldPaths(fields []string) (map[string]bool, map[string][]string) {
+	selected := make(map[string]bool, len(fields))
+	nested := make(map[string][]string)
+	for _, field := range fields {
+		if parent, child, ok := strings.Cut(field, "."); ok {
+			nested[parent] = append(nested[parent], child)
+		} else {
+			selected[field] = true
+		}
+	}
+	return selected, nested
+}
+
+// toGeneric converts a value into its JSON representation made of maps, slices and json.Number
+func toGeneric(data interface{}) (interface{}, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var generic interface{}
+	if err := decoder.Decode(&generic); err != nil {
+		return nil, err
+	}
+	return generic, nil
+}
+
+func toID(value interface{}) (int, bool) {
+	number, ok := value.(json.Number)
+	if !ok {
+		return 0, false
+	}
+	id, err := number.Int64()
+	if err != nil {
+		return 0, false
+	}
+	return int(id), true
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		item = strings.ToLower(strings.TrimSpace(item))
+		if item != "" && !slices.Contains(items, item) {
+			items = append(items, item)
+		}
+	}
+	return items
+}
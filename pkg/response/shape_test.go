@@ -0,0 +1,155 @@
+package response
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type testUploader struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type testFile struct {
+	ID         int               `json:"id"`
+	FileName   string            `json:"file_name"`
+	UploadedBy int               `json:"uploaded_by"`
+	Metadata   map[string]string `json:"metadata"`
+}
+
+// testExpanders expands uploaded_by from a fixed set of uploaders and counts the calls
+func testExpanders(calls *int) map[string]Expander {
+	uploaders := map[int]interface{}{
+		1: testUploader{ID: 1, Name: "Ada"},
+		2: testUploader{ID: 2, Name: "Grace"},
+	}
+	return map[string]Expander{
+		"uploaded_by": func(ctx context.Context, ids []int) (map[int]interface{}, error) {
+			*calls++
+			found := make(map[int]interface{}, len(ids))
+			for _, id := range ids {
+				if uploader, ok := uploaders[id]; ok {
+					found[id] = uploader
+				}
+			}
+			return found, nil
+		},
+	}
+}
+
+var testFiles = []testFile{
+	{ID: 10, FileName: "a.png", UploadedBy: 1, Metadata: map[string]string{"width": "640", "height": "480"}},
+	{ID: 11, FileName: "b.png", UploadedBy: 2},
+	{ID: 12, FileName: "c.png", UploadedBy: 1},
+	{ID: 13, FileName: "d.png", UploadedBy: 3},
+}
+
+// shapeJSON shapes testFiles and returns the result as JSON, for comparison with the expected output
+func shapeJSON(t *testing.T, params ShapeParams, calls *int) string {
+	t.Helper()
+
+	shaped, err := Shape(context.Background(), testFiles, params, testExpanders(calls))
+	if err != nil {
+		t.Fatalf("Shape(%+v): %v", params, err)
+	}
+	raw, err := json.Marshal(shaped)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	return string(raw)
+}
+
+func TestShape(t *testing.T) {
+	tests := []struct {
+		name   string
+		params ShapeParams
+		want   string
+		calls  int
+	}{
+		{
+			name:   "fields",
+			params: ShapeParams{Fields: []string{"id", "file_name", "unknown"}},
+			want:   `[{"file_name":"a.png","id":10},{"file_name":"b.png","id":11},{"file_name":"c.png","id":12},{"file_name":"d.png","id":13}]`,
+		},
+		{
+			name:   "expand",
+			params: ShapeParams{Fields: []string{"id"}, Expand: []string{"uploaded_by"}},
+			want:   `[{"id":10,"uploaded_by":{"id":1,"name":"Ada"}},{"id":11,"uploaded_by":{"id":2,"name":"Grace"}},{"id":12,"uploaded_by":{"id":1,"name":"Ada"}},{"id":13,"uploaded_by":3}]`,
+			calls:  1,
+		},
+		{
+			name:   "expanded fields",
+			params: ShapeParams{Fields: []string{"id", "uploaded_by.name"}, Expand: []string{"uploaded_by"}},
+			want:   `[{"id":10,"uploaded_by":{"name":"Ada"}},{"id":11,"uploaded_by":{"name":"Grace"}},{"id":12,"uploaded_by":{"name":"Ada"}},{"id":13,"uploaded_by":3}]`,
+			calls:  1,
+		},
+		{
+			// The relation is expanded rather than its id returned as if it were the selected field
+			name:   "related fields without expand",
+			params: ShapeParams{Fields: []string{"id", "uploaded_by.name"}},
+			want:   `[{"id":10,"uploaded_by":{"name":"Ada"}},{"id":11,"uploaded_by":{"name":"Grace"}},{"id":12,"uploaded_by":{"name":"Ada"}},{"id":13,"uploaded_by":3}]`,
+			calls:  1,
+		},
+		{
+			name:   "nested object fields",
+			params: ShapeParams{Fields: []string{"id", "metadata.width"}},
+			want:   `[{"id":10,"metadata":{"width":"640"}},{"id":11},{"id":12},{"id":13}]`,
+		},
+		{
+			name:   "dotted path into a scalar",
+			params: ShapeParams{Fields: []string{"id", "file_name.length"}},
+			want:   `[{"id":10},{"id":11},{"id":12},{"id":13}]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			if got := shapeJSON(t, tt.params, &calls); got != tt.want {
+				t.Errorf("shaped %s, want %s", got, tt.want)
+			}
+			if calls != tt.calls {
+				t.Errorf("expander called %d times, want %d", calls, tt.calls)
+			}
+		})
+	}
+}
+
+func TestShapeSingleObject(t *testing.T) {
+	calls := 0
+	shaped, err := Shape(context.Background(), testFiles[1], ShapeParams{Fields: []string{"file_name", "uploaded_by.name"}}, testExpanders(&calls))
+	if err != nil {
+		t.Fatalf("Shape: %v", err)
+	}
+	want := map[string]interface{}{
+		"file_name":   "b.png",
+		"uploaded_by": map[string]interface{}{"name": "Grace"},
+	}
+	if !reflect.DeepEqual(shaped, want) {
+		t.Errorf("shaped %#v, want %#v", shaped, want)
+	}
+}
+
+func TestShapeUnknownExpansion(t *testing.T) {
+	calls := 0
+	_, err := Shape(context.Background(), testFiles, ShapeParams{Expand: []string{"folder"}}, testExpanders(&calls))
+	if !errors.Is(err, ErrUnknownExpansion) {
+		t.Errorf("Shape error = %v, want ErrUnknownExpansion", err)
+	}
+}
+
+func TestShapeWithoutParams(t *testing.T) {
+	calls := 0
+	shaped, err := Shape(context.Background(), testFiles, ShapeParams{}, testExpanders(&calls))
+	if err != nil {
+		t.Fatalf("Shape: %v", err)
+	}
+	if !reflect.DeepEqual(shaped, testFiles) {
+		t.Errorf("shaped %#v, want the data unchanged", shaped)
+	}
+	if calls != 0 {
+		t.Errorf("expander called %d times, want 0", calls)
+	}
+}
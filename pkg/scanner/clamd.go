@@ -0,0 +1,162 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// clamdChunkSize is the size of the chunks content is streamed to clamd in
+const clamdChunkSize = 64 * 1024
+
+// clamdScanner scans content with a ClamAV daemon using the INSTREAM command, so clamd does not need
+// access to the storage backend
+type clamdScanner struct {
+	network string
+	address string
+	timeout time.Duration
+}
+
+// NewClamdScanner creates a scanner for the clamd listening at address: "tcp://host:3310", "host:3310",
+// or a Unix socket as "unix:///run/clamav/clamd.ctl". A positive timeout bounds each scan.
+func NewClamdScanner(address string, timeout time.Duration) (Scanner, error) {
+	network := "tcp"
+	switch {
+	case strings.HasPrefix(address, "unix://"):
+		network, address = "unix", strings.TrimPrefix(address, "unix://")
+	case strings.HasPrefix(address, "tcp://"):
+		address = strings.TrimPrefix(address, "tcp://")
+	case strings.HasPrefix(address, "/"):
+		network = "unix"
+	}
+	if address == "" {
+		return nil, errors.New("clamd address is required")
+	}
+
+	return &clamdScanner{
+		network: network,
+		address: address,
+		timeout: timeout,
+	}, nil
+}
+
+func (s *clamdScanner) Scan(ctx context.Context, r io.Reader) (*Result, error) {
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, s.network, s.address)
+	if err != nil {
+		return nil, fmt.Errorf("clamd: %w", err)
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	// Unblock reads and writes when the context is cancelled
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetDeadline(time.Now())
+	})
+	defer stop()
+
+	if err := s.stream(conn, r); err != nil {
+		var writeErr *clamdWriteError
+		if !errors.As(err, &writeErr) {
+			return nil, err
+		}
+		// clamd replies and hangs up when the stream exceeds StreamMaxLength, failing the write
+		reply, readErr := readClamdReply(conn)
+		if readErr != nil {
+			return nil, fmt.Errorf("clamd: %w", writeErr.err)
+		}
+		return parseClamdReply(reply)
+	}
+
+	reply, err := readClamdReply(conn)
+	if err != nil {
+		return nil, fmt.Errorf("clamd: %w", err)
+	}
+	return parseClamdReply(reply)
+}
+
+// clamdWriteError marks a failure to send to clamd, as opposed to reading the scanned content
+type clamdWriteError struct {
+	err error
+}
+
+func (e *clamdWriteError) Error() string {
+	return e.err.Error()
+}
+
+// stream sends the INSTREAM command followed by the content as length-prefixed chunks and the
+// zero-length chunk ending the stream
+func (s *clamdScanner) stream(conn net.Conn, r io.Reader) error {
+	w := bufio.NewWriterSize(conn, clamdChunkSize+4)
+	if _, err := w.WriteString("zINSTREAM\x00"); err != nil {
+		return &clamdWriteError{err}
+	}
+
+	buf := make([]byte, clamdChunkSize)
+	var length [4]byte
+	for {
+		n, readErr := io.ReadFull(r, buf)
+		if n > 0 {
+			binary.BigEndian.PutUint32(length[:], uint32(n))
+			if _, err := w.Write(length[:]); err != nil {
+				return &clamdWriteError{err}
+			}
+			if _, err := w.Write(buf[:n]); err != nil {
+				return &clamdWriteError{err}
+			}
+		}
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			break
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+
+	binary.BigEndian.PutUint32(length[:], 0)
+	if _, err := w.Write(length[:]); err != nil {
+		return &clamdWriteError{err}
+	}
+	if err := w.Flush(); err != nil {
+		return &clamdWriteError{err}
+	}
+	return nil
+}
+
+// readClamdReply reads the NUL-terminated reply to a command sent with the "z" prefix
+func readClamdReply(conn net.Conn) (string, error) {
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && (err != io.EOF || reply == "") {
+		return "", err
+	}
+	return strings.TrimRight(reply, "\x00\n"), nil
+}
+
+// parseClamdReply interprets "stream: OK", "stream: <signature> FOUND" and "<message> ERROR" replies
+func parseClamdReply(reply string) (*Result, error) {
+	verdict := strings.TrimPrefix(reply, "stream: ")
+	switch {
+	case verdict == "OK":
+		return &Result{}, nil
+	case strings.HasSuffix(verdict, " FOUND"):
+		return &Result{Infected: true, Signature: strings.TrimSuffix(verdict, " FOUND")}, nil
+	case strings.Contains(verdict, "size limit exceeded"):
+		return nil, ErrSizeLimitExceeded
+	default:
+		return nil, fmt.Errorf("clamd: unexpected reply %q", reply)
+	}
+}
@@ -0,0 +1,213 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeClamd accepts a single INSTREAM session, records the chunks it receives and answers with
+// reply. With a positive limit, it answers as soon as more than limit bytes arrived, like clamd does
+// with StreamMaxLength, and discards the rest of the stream.
+type fakeClamd struct {
+	listener net.Listener
+	reply    string
+	limit    int
+
+	done    chan struct{}
+	command string
+	chunks  [][]byte
+	err     error
+}
+
+func startFakeClamd(t *testing.T, reply string, limit int) *fakeClamd {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	f := &fakeClamd{listener: listener, reply: reply, limit: limit, done: make(chan struct{})}
+	go f.serve()
+	return f
+}
+
+func (f *fakeClamd) serve() {
+	defer close(f.done)
+
+	conn, err := f.listener.Accept()
+	if err != nil {
+		f.err = err
+		return
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	r := bufio.NewReader(conn)
+	f.command, err = r.ReadString(0)
+	if err != nil {
+		f.err = err
+		return
+	}
+
+	received := 0
+	for {
+		var length [4]byte
+		if _, err := io.ReadFull(r, length[:]); err != nil {
+			f.err = err
+			return
+		}
+		n := binary.BigEndian.Uint32(length[:])
+		if n == 0 {
+			break
+		}
+		chunk := make([]byte, n)
+		if _, err := io.ReadFull(r, chunk); err != nil {
+			f.err = err
+			return
+		}
+		f.chunks = append(f.chunks, chunk)
+
+		received += int(n)
+		if f.limit > 0 && received > f.limit {
+			conn.Write([]byte(f.reply + "\x00"))
+			io.Copy(io.Discard, r)
+			return
+		}
+	}
+
+	conn.Write([]byte(f.reply + "\x00"))
+}
+
+// wait returns once the session is over, failing the test when the stream was malformed
+func (f *fakeClamd) wait(t *testing.T) {
+	t.Helper()
+
+	<-f.done
+	if f.err != nil {
+		t.Fatalf("fake clamd: %v", f.err)
+	}
+}
+
+func TestClamdScannerStreamsContentInChunks(t *testing.T) {
+	fake := startFakeClamd(t, "stream: OK", 0)
+	scanner, err := NewClamdScanner("tcp://"+fake.listener.Addr().String(), 5*time.Second)
+	if err != nil {
+		t.Fatalf("NewClamdScanner: %v", err)
+	}
+
+	content := bytes.Repeat([]byte("0123456789abcdef"), (2*clamdChunkSize+1000)/16)
+	if _, err := scanner.Scan(context.Background(), bytes.NewReader(content)); err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	fake.wait(t)
+
+	if fake.command != "zINSTREAM\x00" {
+		t.Errorf("command = %q, want %q", fake.command, "zINSTREAM\x00")
+	}
+	if len(fake.chunks) != 3 {
+		t.Errorf("got %d chunks, want 3", len(fake.chunks))
+	}
+	for i, chunk := range fake.chunks {
+		if len(chunk) > clamdChunkSize {
+			t.Errorf("chunk %d has %d bytes, more than %d", i, len(chunk), clamdChunkSize)
+		}
+	}
+	if got := bytes.Join(fake.chunks, nil); !bytes.Equal(got, content) {
+		t.Errorf("clamd received %d bytes that differ from the %d bytes scanned", len(got), len(content))
+	}
+}
+
+func TestClamdScannerReplies(t *testing.T) {
+	tests := []struct {
+		name      string
+		reply     string
+		limit     int
+		want      *Result
+		wantErr   error
+		errSubstr string
+	}{
+		{
+			name:  "clean",
+			reply: "stream: OK",
+			want:  &Result{},
+		},
+		{
+			name:  "infected",
+			reply: "stream: Eicar-Test-Signature FOUND",
+			want:  &Result{Infected: true, Signature: "Eicar-Test-Signature"},
+		},
+		{
+			name:    "size limit exceeded",
+			reply:   "INSTREAM size limit exceeded. ERROR",
+			limit:   clamdChunkSize,
+			wantErr: ErrSizeLimitExceeded,
+		},
+		{
+			name:      "error",
+			reply:     "INSTREAM: Can't allocate memory ERROR",
+			errSubstr: "unexpected reply",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := startFakeClamd(t, tt.reply, tt.limit)
+			scanner, err := NewClamdScanner(fake.listener.Addr().String(), 5*time.Second)
+			if err != nil {
+				t.Fatalf("NewClamdScanner: %v", err)
+			}
+
+			content := bytes.Repeat([]byte{'x'}, 4*clamdChunkSize)
+			result, err := scanner.Scan(context.Background(), bytes.NewReader(content))
+			fake.wait(t)
+
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Scan error = %v, want %v", err, tt.wantErr)
+				}
+			case tt.errSubstr != "":
+				if err == nil || !strings.Contains(err.Error(), tt.errSubstr) {
+					t.Fatalf("Scan error = %v, want one containing %q", err, tt.errSubstr)
+				}
+				if errors.Is(err, ErrSizeLimitExceeded) {
+					t.Fatalf("Scan error = %v, must not be ErrSizeLimitExceeded", err)
+				}
+			default:
+				if err != nil {
+					t.Fatalf("Scan: %v", err)
+				}
+				if *result != *tt.want {
+					t.Errorf("Scan = %+v, want %+v", *result, *tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestClamdScannerUnreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	scanner, err := NewClamdScanner(address, time.Second)
+	if err != nil {
+		t.Fatalf("NewClamdScanner: %v", err)
+	}
+	if _, err := scanner.Scan(context.Background(), strings.NewReader("content")); err == nil {
+		t.Fatal("Scan succeeded without clamd")
+	}
+}
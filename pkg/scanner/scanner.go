@@ -0,0 +1,25 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrSizeLimitExceeded is returned when content is larger than the scanner accepts, such as clamd's
+// StreamMaxLength
+var ErrSizeLimitExceeded = errors.New("content exceeds the scanner's size limit")
+
+// Result is the verdict of a scan
+type Result struct {
+	Infected bool
+	// Signature names the malware found, empty when the content is clean
+	Signature string
+}
+
+// Scanner checks content for malware
+type Scanner interface {
+	// Scan reads r to the end and reports whether it contains malware. An error means no verdict was
+	// reached, e.g. because the scanner is unreachable.
+	Scan(ctx context.Context, r io.Reader) (*Result, error)
+}
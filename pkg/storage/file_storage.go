@@ -1,70 +1,91 @@
 package storage
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
-	"mime/multipart"
-	"os"
+	"io/fs"
 	"path/filepath"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+const (
+	// DriverLocal stores objects on the local filesystem
+	DriverLocal = "local"
+	// DriverS3 stores objects in an S3-compatible bucket (AWS S3, MinIO, ...)
+	DriverS3 = "s3"
+)
+
+var (
+	// ErrObjectNotFound is returned when no object is stored under the requested key
+	ErrObjectNotFound = errors.New("object not found")
+	// ErrInvalidKey is returned for keys that are empty, absolute or contain "." or ".." elements
+	ErrInvalidKey = errors.New("invalid storage key")
+)
+
+// FileStorage stores objects under slash-separated keys such as "20240101-120000-<uuid>.png" or
+// "exports/export-1-2.zip". Keys are what the database records; where the bytes live depends on the backend.
 type FileStorage interface {
-	SaveFile(file *multipart.FileHeader, uploadPath string) (string, string, error)
-	DeleteFile(filePath string) error
-	GetFileURL(filePath, baseURL string) string
+	// Put stores the content of r under key, replacing any existing object. size must be the exact content length.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Open returns a stream of the object stored under key along with its metadata. The caller closes the stream.
+	Open(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error)
+	// OpenAt returns a stream of the object stored under key starting at offset, which must not exceed its size
+	OpenAt(ctx context.Context, key string, offset int64) (io.ReadCloser, error)
+	// Delete removes the object stored under key; deleting a missing object is not an error
+	Delete(ctx context.Context, key string) error
+	// GetFileURL returns the URL the file with fileID, or its named variant, is served from. With a
+	// positive ttl the URL is signed and expires after ttl; otherwise it is permanent and only
+	// accepted for files in public categories.
+	GetFileURL(ctx context.Context, fileID int, variant string, ttl time.Duration) string
+	// VerifyFileURL checks the expires and signature parameters of a signed file URL requested from clientIP
+	VerifyFileURL(fileID int, variant, expires, signature, clientIP string) error
 }
 
-type fileStorage struct{}
+// ObjectInfo describes a stored object
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	ContentType  string
+	ETag         string
+	LastModified time.Time
+}
 
-func NewFileStorage() FileStorage {
-	return &fileStorage{}
+// Config selects and configures the storage backend
+type Config struct {
+	Driver string
+	// LocalPath is the root directory of the local backend
+	LocalPath string
+	S3        S3Config
+	URLs      URLConfig
 }
 
-func (fs *fileStorage) SaveFile(file *multipart.FileHeader, uploadPath string) (string, string, error) {
-	// Generate unique filename
-	ext := filepath.Ext(file.Filename)
-	uniqueID := uuid.New().String()
-	timestamp := time.Now().Format("20060102-150405")
-	fileName := fmt.Sprintf("%s-%s%s", timestamp, uniqueID, ext)
-	
-	// Create full path
-	fullPath := filepath.Join(uploadPath, fileName)
-	
-	// Create directory if it doesn't exist
-	if err := os.MkdirAll(uploadPath, 0755); err != nil {
-		return "", "", err
+// New creates the storage backend selected by config.Driver
+func New(config *Config) (FileStorage, error) {
+	switch config.Driver {
+	case "", DriverLocal:
+		return NewLocalStorage(config.LocalPath, config.URLs), nil
+	case DriverS3:
+		return NewS3Storage(config.S3, config.URLs)
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", config.Driver)
 	}
-	
-	// Open uploaded file
-	src, err := file.Open()
-	if err != nil {
-		return "", "", err
-	}
-	defer src.Close()
-	
-	// Create destination file
-	dst, err := os.Create(fullPath)
-	if err != nil {
-		return "", "", err
-	}
-	defer dst.Close()
-	
-	// Copy file content
-	_, err = io.Copy(dst, src)
-	if err != nil {
-		return "", "", err
-	}
-	
-	return fileName, fullPath, nil
 }
 
-func (fs *fileStorage) DeleteFile(filePath string) error {
-	return os.Remove(filePath)
+// NewKey generates a unique key for an uploaded file, keeping the extension of its original name
+func NewKey(originalName string) string {
+	ext := filepath.Ext(originalName)
+	uniqueID := uuid.New().String()
+	timestamp := time.Now().Format("20060102-150405")
+	return fmt.Sprintf("%s-%s%s", timestamp, uniqueID, ext)
 }
 
-func (fs *fileStorage) GetFileURL(filePath, baseURL string) string {
-	return fmt.Sprintf("%s/files/%s", baseURL, filepath.Base(filePath))
-}
\ No newline at end of file
+func validateKey(key string) error {
+	if key == "." || !fs.ValidPath(key) {
+		return ErrInvalidKey
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package storage
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// signedURLParams issues a URL and returns its query parameters
+func signedURLParams(t *testing.T, s *urlSigner, ctx context.Context, fileID int, variant string, ttl time.Duration) url.Values {
+	t.Helper()
+
+	fileURL, err := url.Parse(s.GetFileURL(ctx, fileID, variant, ttl))
+	if err != nil {
+		t.Fatalf("GetFileURL returned an invalid URL: %v", err)
+	}
+	if want := "/files/" + strconv.Itoa(fileID); fileURL.Path != want {
+		t.Fatalf("GetFileURL path = %q, want %q", fileURL.Path, want)
+	}
+	return fileURL.Query()
+}
+
+func TestGetFileURL(t *testing.T) {
+	s := &urlSigner{config: URLConfig{BaseURL: "https://api.example.com/", Secret: "secret"}}
+
+	if got, want := s.GetFileURL(context.Background(), 42, "", 0), "https://api.example.com/files/42"; got != want {
+		t.Errorf("GetFileURL without a TTL = %q, want the unsigned %q", got, want)
+	}
+
+	params := signedURLParams(t, s, context.Background(), 42, "small", time.Hour)
+	if params.Get("variant") != "small" {
+		t.Errorf("variant = %q, want small", params.Get("variant"))
+	}
+	expires, err := strconv.ParseInt(params.Get("expires"), 10, 64)
+	if err != nil {
+		t.Fatalf("expires %q is not a Unix time: %v", params.Get("expires"), err)
+	}
+	if until := time.Until(time.Unix(expires, 0)); until < 59*time.Minute || until > time.Hour+time.Second {
+		t.Errorf("URL expires in %s, want an hour", until)
+	}
+	if params.Get("signature") == "" {
+		t.Error("URL with a TTL is not signed")
+	}
+}
+
+func TestVerifyFileURL(t *testing.T) {
+	s := &urlSigner{config: URLConfig{BaseURL: "https://api.example.com", Secret: "secret"}}
+	params := signedURLParams(t, s, context.Background(), 42, "small", time.Hour)
+	expires, signature := params.Get("expires"), params.Get("signature")
+
+	// GetFileURL cannot issue URLs that already expired, so that signature is computed directly
+	past := strconv.FormatInt(time.Now().Add(-time.Minute).Unix(), 10)
+	expiredSignature := base64.RawURLEncoding.EncodeToString(s.sign(42, "small", past, ""))
+
+	otherKey := &urlSigner{config: URLConfig{Secret: "other secret"}}
+	forged := base64.RawURLEncoding.EncodeToString(otherKey.sign(42, "small", expires, ""))
+
+	tests := []struct {
+		name      string
+		fileID    int
+		variant   string
+		expires   string
+		signature string
+		want      error
+	}{
+		{"valid", 42, "small", expires, signature, nil},
+		{"other file", 43, "small", expires, signature, ErrInvalidURLSignature},
+		{"other variant", 42, "large", expires, signature, ErrInvalidURLSignature},
+		{"no variant", 42, "", expires, signature, ErrInvalidURLSignature},
+		{"extended expiry", 42, "small", strconv.FormatInt(time.Now().Add(24*time.Hour).Unix(), 10), signature, ErrInvalidURLSignature},
+		{"non-numeric expiry", 42, "small", "tomorrow", signature, ErrInvalidURLSignature},
+		{"unsigned", 42, "small", expires, "", ErrInvalidURLSignature},
+		{"malformed signature", 42, "small", expires, "not base64!", ErrInvalidURLSignature},
+		{"truncated signature", 42, "small", expires, signature[:len(signature)-4], ErrInvalidURLSignature},
+		{"signed with another secret", 42, "small", expires, forged, ErrInvalidURLSignature},
+		{"expired", 42, "small", past, expiredSignature, ErrURLExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := s.VerifyFileURL(tt.fileID, tt.variant, tt.expires, tt.signature, "203.0.113.7"); !errors.Is(err, tt.want) {
+				t.Errorf("VerifyFileURL error %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyFileURLBoundToIP(t *testing.T) {
+	s := &urlSigner{config: URLConfig{BaseURL: "https://api.example.com", Secret: "secret", BindToIP: true}}
+	ctx := WithClientIP(context.Background(), "203.0.113.7")
+	bound := signedURLParams(t, s, ctx, 42, "", time.Hour)
+	// Issued outside a request, e.g. by a background job
+	unbound := signedURLParams(t, s, context.Background(), 42, "", time.Hour)
+
+	tests := []struct {
+		name     string
+		params   url.Values
+		clientIP string
+		want     error
+	}{
+		{"bound, same IP", bound, "203.0.113.7", nil},
+		{"bound, other IP", bound, "198.51.100.1", ErrInvalidURLSignature},
+		{"bound, unknown IP", bound, "", ErrInvalidURLSignature},
+		{"unbound, any IP", unbound, "198.51.100.1", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.VerifyFileURL(42, "", tt.params.Get("expires"), tt.params.Get("signature"), tt.clientIP)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("VerifyFileURL error %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	// Without BindToIP the client IP is neither signed nor checked
+	unboundSigner := &urlSigner{config: URLConfig{BaseURL: "https://api.example.com", Secret: "secret"}}
+	params := signedURLParams(t, unboundSigner, ctx, 42, "", time.Hour)
+	if err := unboundSigner.VerifyFileURL(42, "", params.Get("expires"), params.Get("signature"), "198.51.100.1"); err != nil {
+		t.Errorf("VerifyFileURL of an unbound URL from another IP: %v", err)
+	}
+}
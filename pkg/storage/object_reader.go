@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// OpenSeekable opens the object stored under key like FileStorage.Open, but returns a stream that can
+// seek, as range requests need. Backends without seekable streams reopen the object at the new offset
+// with OpenAt on the first read after a seek.
+func OpenSeekable(ctx context.Context, fileStorage FileStorage, key string) (io.ReadSeekCloser, *ObjectInfo, error) {
+	content, info, err := fileStorage.Open(ctx, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	if seekable, ok := content.(io.ReadSeekCloser); ok {
+		return seekable, info, nil
+	}
+	return &objectReader{
+		ctx:         ctx,
+		fileStorage: fileStorage,
+		key:         key,
+		size:        info.Size,
+		body:        content,
+	}, info, nil
+}
+
+// objectReader seeks within a stored object by reopening it at the new offset
+type objectReader struct {
+	ctx         context.Context
+	fileStorage FileStorage
+	key         string
+	size        int64
+	// body is positioned at bodyOffset; it is replaced once a seek moves offset elsewhere
+	body       io.ReadCloser
+	bodyOffset int64
+	offset     int64
+}
+
+func (r *objectReader) Read(p []byte) (int, error) {
+	if r.body != nil && r.bodyOffset != r.offset {
+		r.body.Close()
+		r.body = nil
+	}
+	if r.body == nil {
+		if r.offset >= r.size {
+			return 0, io.EOF
+		}
+		body, err := r.fileStorage.OpenAt(r.ctx, r.key, r.offset)
+		if err != nil {
+			return 0, err
+		}
+		r.body = body
+		r.bodyOffset = r.offset
+	}
+
+	n, err := r.body.Read(p)
+	r.offset += int64(n)
+	r.bodyOffset += int64(n)
+	return n, err
+}
+
+func (r *objectReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		if r.size < 0 {
+			return 0, errors.New("seek relative to the end of an object of unknown size")
+		}
+		offset += r.size
+	}
+	if offset < 0 {
+		return 0, errors.New("seek to a negative offset")
+	}
+	r.offset = offset
+	return offset, nil
+}
+
+func (r *objectReader) Close() error {
+	if r.body == nil {
+		return nil
+	}
+	return r.body.Close()
+}
@@ -0,0 +1,190 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+)
+
+// fakeObjectStorage stores one object whose streams cannot seek, recording where it was opened
+type fakeObjectStorage struct {
+	FileStorage
+	content []byte
+	opens   []int64
+	closed  int
+}
+
+// fakeObjectBody is a stream without Seek
+type fakeObjectBody struct {
+	r       io.Reader
+	storage *fakeObjectStorage
+}
+
+func (b *fakeObjectBody) Read(p []byte) (int, error) {
+	return b.r.Read(p)
+}
+
+func (b *fakeObjectBody) Close() error {
+	b.storage.closed++
+	return nil
+}
+
+func (s *fakeObjectStorage) Open(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error) {
+	s.opens = append(s.opens, 0)
+	return &fakeObjectBody{r: bytes.NewReader(s.content), storage: s}, &ObjectInfo{Key: key, Size: int64(len(s.content))}, nil
+}
+
+func (s *fakeObjectStorage) OpenAt(ctx context.Context, key string, offset int64) (io.ReadCloser, error) {
+	s.opens = append(s.opens, offset)
+	return &fakeObjectBody{r: bytes.NewReader(s.content[offset:]), storage: s}, nil
+}
+
+func openTestObject(t *testing.T) (io.ReadSeekCloser, *fakeObjectStorage) {
+	t.Helper()
+
+	fileStorage := &fakeObjectStorage{content: []byte("0123456789abcdefghij")}
+	r, info, err := OpenSeekable(context.Background(), fileStorage, "object")
+	if err != nil {
+		t.Fatalf("OpenSeekable: %v", err)
+	}
+	if info.Size != 20 {
+		t.Fatalf("size = %d, want 20", info.Size)
+	}
+	if _, ok := r.(*objectReader); !ok {
+		t.Fatalf("OpenSeekable returned %T, want an objectReader for a stream without Seek", r)
+	}
+	return r, fileStorage
+}
+
+func readN(t *testing.T, r io.Reader, n int) string {
+	t.Helper()
+
+	p := make([]byte, n)
+	if _, err := io.ReadFull(r, p); err != nil {
+		t.Fatalf("read %d bytes: %v", n, err)
+	}
+	return string(p)
+}
+
+func TestObjectReaderSeek(t *testing.T) {
+	tests := []struct {
+		name       string
+		offset     int64
+		whence     int
+		wantOffset int64
+		want       string
+	}{
+		{"start", 12, io.SeekStart, 12, "cdef"},
+		{"current", 3, io.SeekCurrent, 7, "789a"},
+		{"current backwards", -2, io.SeekCurrent, 2, "2345"},
+		{"end", -4, io.SeekEnd, 16, "ghij"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, fileStorage := openTestObject(t)
+			defer r.Close()
+
+			// Seeks relative to the current offset start after these bytes
+			if got := readN(t, r, 4); got != "0123" {
+				t.Fatalf("first read = %q, want %q", got, "0123")
+			}
+
+			offset, err := r.Seek(tt.offset, tt.whence)
+			if err != nil {
+				t.Fatalf("Seek: %v", err)
+			}
+			if offset != tt.wantOffset {
+				t.Errorf("Seek = %d, want %d", offset, tt.wantOffset)
+			}
+			if got := readN(t, r, 4); got != tt.want {
+				t.Errorf("read after Seek = %q, want %q", got, tt.want)
+			}
+
+			// The object was opened once and reopened at the new offset by the first read
+			if len(fileStorage.opens) != 2 || fileStorage.opens[1] != tt.wantOffset {
+				t.Errorf("object opened at %v, want at 0 and %d", fileStorage.opens, tt.wantOffset)
+			}
+			if fileStorage.closed != 1 {
+				t.Errorf("%d streams closed, want the one opened before the seek", fileStorage.closed)
+			}
+		})
+	}
+}
+
+func TestObjectReaderReopensOnlyWhenMoved(t *testing.T) {
+	r, fileStorage := openTestObject(t)
+	defer r.Close()
+
+	// Seeks are lazy: only the offset of the next read counts
+	r.Seek(10, io.SeekStart)
+	r.Seek(5, io.SeekStart)
+	if len(fileStorage.opens) != 1 {
+		t.Errorf("object opened at %v before a read, want only the first open", fileStorage.opens)
+	}
+	if got := readN(t, r, 3); got != "567" {
+		t.Errorf("read = %q, want %q", got, "567")
+	}
+
+	// Seeking to where the stream already is keeps it
+	r.Seek(0, io.SeekCurrent)
+	r.Seek(8, io.SeekStart)
+	if got := readN(t, r, 3); got != "89a" {
+		t.Errorf("read = %q, want %q", got, "89a")
+	}
+	if len(fileStorage.opens) != 2 {
+		t.Errorf("object opened at %v, want at 0 and 5", fileStorage.opens)
+	}
+}
+
+func TestObjectReaderEOF(t *testing.T) {
+	tests := []struct {
+		name   string
+		offset int64
+	}{
+		{"at the end", 20},
+		{"past the end", 25},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, fileStorage := openTestObject(t)
+			defer r.Close()
+
+			if _, err := r.Seek(tt.offset, io.SeekStart); err != nil {
+				t.Fatalf("Seek: %v", err)
+			}
+			if n, err := r.Read(make([]byte, 4)); n != 0 || err != io.EOF {
+				t.Errorf("Read = %d, %v; want 0, EOF", n, err)
+			}
+			// OpenAt does not accept offsets past the size, so none is attempted
+			if len(fileStorage.opens) != 1 {
+				t.Errorf("object opened at %v, want only the first open", fileStorage.opens)
+			}
+		})
+	}
+
+	// Reading to the end of the stream ends with EOF too
+	r, _ := openTestObject(t)
+	defer r.Close()
+	r.Seek(-3, io.SeekEnd)
+	content, err := io.ReadAll(r)
+	if err != nil || string(content) != "hij" {
+		t.Errorf("ReadAll = %q, %v; want %q", content, err, "hij")
+	}
+}
+
+func TestObjectReaderRejectsNegativeOffsets(t *testing.T) {
+	r, _ := openTestObject(t)
+	defer r.Close()
+
+	if _, err := r.Seek(-1, io.SeekStart); err == nil {
+		t.Error("Seek to -1 succeeded")
+	}
+	if _, err := r.Seek(-21, io.SeekEnd); err == nil {
+		t.Error("Seek before the start from the end succeeded")
+	}
+	// A rejected seek keeps the offset
+	if got := readN(t, r, 2); got != "01" {
+		t.Errorf("read after rejected seeks = %q, want %q", got, "01")
+	}
+}
@@ -0,0 +1,238 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeS3 is an in-memory bucket answering the path-style object requests of s3Storage
+type fakeS3 struct {
+	t      *testing.T
+	bucket string
+
+	mu          sync.Mutex
+	objects     map[string][]byte
+	types       map[string]string
+	ignoreRange bool
+}
+
+func newFakeS3(t *testing.T) (*fakeS3, FileStorage) {
+	t.Helper()
+
+	fake := &fakeS3{t: t, bucket: "test-bucket", objects: map[string][]byte{}, types: map[string]string{}}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	s3, err := NewS3Storage(S3Config{
+		Endpoint:        server.URL,
+		Region:          "eu-west-1",
+		Bucket:          fake.bucket,
+		AccessKeyID:     "access-key",
+		SecretAccessKey: "secret-key",
+		UsePathStyle:    true,
+	}, URLConfig{BaseURL: "http://localhost:8080", Secret: "secret"})
+	if err != nil {
+		t.Fatalf("NewS3Storage: %v", err)
+	}
+	return fake, s3
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=access-key/") || r.Header.Get("X-Amz-Date") == "" {
+		f.writeError(w, http.StatusForbidden, "AccessDenied", "Missing signature")
+		return
+	}
+	key, ok := strings.CutPrefix(r.URL.Path, "/"+f.bucket+"/")
+	if !ok {
+		f.writeError(w, http.StatusNotFound, "NoSuchBucket", "The specified bucket does not exist")
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodPut:
+		content, err := io.ReadAll(r.Body)
+		if err != nil {
+			f.t.Errorf("read upload: %v", err)
+			return
+		}
+		if int64(len(content)) != r.ContentLength {
+			f.t.Errorf("Content-Length %d for %d bytes", r.ContentLength, len(content))
+		}
+		f.objects[key] = content
+		f.types[key] = r.Header.Get("Content-Type")
+		w.WriteHeader(http.StatusOK)
+	case http.MethodGet:
+		content, ok := f.objects[key]
+		if !ok {
+			f.writeError(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+			return
+		}
+		w.Header().Set("Content-Type", f.types[key])
+		w.Header().Set("ETag", fmt.Sprintf("%q", fmt.Sprintf("etag-%d", len(content))))
+		w.Header().Set("Last-Modified", "Mon, 01 Jan 2024 12:00:00 GMT")
+
+		var offset int
+		if rangeHeader := r.Header.Get("Range"); rangeHeader != "" && !f.ignoreRange {
+			if _, err := fmt.Sscanf(rangeHeader, "bytes=%d-", &offset); err != nil || offset >= len(content) {
+				f.writeError(w, http.StatusRequestedRangeNotSatisfiable, "InvalidRange", "The requested range is not satisfiable")
+				return
+			}
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, len(content)-1, len(content)))
+			w.Header().Set("Content-Length", fmt.Sprint(len(content)-offset))
+			w.WriteHeader(http.StatusPartialContent)
+		} else {
+			w.Header().Set("Content-Length", fmt.Sprint(len(content)))
+		}
+		w.Write(content[offset:])
+	case http.MethodDelete:
+		// S3 answers deletes of missing objects with 204 too
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (f *fakeS3) writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?><Error><Code>%s</Code><Message>%s</Message></Error>`, code, message)
+}
+
+func readAllAndClose(t *testing.T, r io.ReadCloser) []byte {
+	t.Helper()
+
+	defer r.Close()
+	content, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	return content
+}
+
+func TestS3StoragePutAndOpen(t *testing.T) {
+	fake, s3 := newFakeS3(t)
+	ctx := context.Background()
+	content := []byte("hello, object storage")
+
+	if err := s3.Put(ctx, "docs/hello world.txt", bytes.NewReader(content), int64(len(content)), "text/plain"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if got := fake.objects["docs/hello world.txt"]; !bytes.Equal(got, content) {
+		t.Fatalf("stored %q, want %q", got, content)
+	}
+
+	r, info, err := s3.Open(ctx, "docs/hello world.txt")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if got := readAllAndClose(t, r); !bytes.Equal(got, content) {
+		t.Errorf("Open read %q, want %q", got, content)
+	}
+	if info.Key != "docs/hello world.txt" || info.Size != int64(len(content)) || info.ContentType != "text/plain" {
+		t.Errorf("Open info = %+v", info)
+	}
+	if info.ETag == "" || info.LastModified.IsZero() {
+		t.Errorf("Open info misses ETag or Last-Modified: %+v", info)
+	}
+}
+
+func TestS3StoragePutEmpty(t *testing.T) {
+	fake, s3 := newFakeS3(t)
+
+	if err := s3.Put(context.Background(), "empty.txt", bytes.NewReader(nil), 0, ""); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if content, ok := fake.objects["empty.txt"]; !ok || len(content) != 0 {
+		t.Fatalf("stored %q, %v; want an empty object", content, ok)
+	}
+}
+
+func TestS3StorageOpenAt(t *testing.T) {
+	fake, s3 := newFakeS3(t)
+	ctx := context.Background()
+	content := []byte("0123456789")
+	fake.objects["digits.txt"] = content
+
+	for _, offset := range []int64{0, 4, 9} {
+		r, err := s3.OpenAt(ctx, "digits.txt", offset)
+		if err != nil {
+			t.Fatalf("OpenAt(%d): %v", offset, err)
+		}
+		if got := readAllAndClose(t, r); !bytes.Equal(got, content[offset:]) {
+			t.Errorf("OpenAt(%d) read %q, want %q", offset, got, content[offset:])
+		}
+	}
+}
+
+func TestS3StorageOpenAtRejectsIgnoredRange(t *testing.T) {
+	fake, s3 := newFakeS3(t)
+	fake.objects["digits.txt"] = []byte("0123456789")
+	fake.ignoreRange = true
+
+	if r, err := s3.OpenAt(context.Background(), "digits.txt", 4); err == nil {
+		r.Close()
+		t.Fatal("OpenAt succeeded although the range was answered with the whole object")
+	}
+}
+
+func TestS3StorageDelete(t *testing.T) {
+	fake, s3 := newFakeS3(t)
+	ctx := context.Background()
+	fake.objects["old.txt"] = []byte("old")
+
+	if err := s3.Delete(ctx, "old.txt"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, ok := fake.objects["old.txt"]; ok {
+		t.Fatal("object still stored after Delete")
+	}
+	if err := s3.Delete(ctx, "old.txt"); err != nil {
+		t.Fatalf("Delete of a missing object: %v", err)
+	}
+}
+
+func TestS3StorageNotFound(t *testing.T) {
+	_, s3 := newFakeS3(t)
+	ctx := context.Background()
+
+	if _, _, err := s3.Open(ctx, "missing.txt"); !errors.Is(err, ErrObjectNotFound) {
+		t.Errorf("Open error = %v, want ErrObjectNotFound", err)
+	}
+	if _, err := s3.OpenAt(ctx, "missing.txt", 3); !errors.Is(err, ErrObjectNotFound) {
+		t.Errorf("OpenAt error = %v, want ErrObjectNotFound", err)
+	}
+}
+
+func TestS3StorageErrorResponse(t *testing.T) {
+	_, s3 := newFakeS3(t)
+
+	// The fake only accepts requests signed with its access key
+	s3.(*s3Storage).config.AccessKeyID = "other-key"
+	_, _, err := s3.Open(context.Background(), "file.txt")
+	if err == nil || errors.Is(err, ErrObjectNotFound) {
+		t.Fatalf("Open error = %v, want an S3 error", err)
+	}
+	if !strings.Contains(err.Error(), "AccessDenied") {
+		t.Errorf("Open error = %v, want the S3 error code", err)
+	}
+}
+
+func TestS3StorageInvalidKey(t *testing.T) {
+	_, s3 := newFakeS3(t)
+
+	if err := s3.Put(context.Background(), "../escape.txt", strings.NewReader("x"), 1, ""); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("Put error = %v, want ErrInvalidKey", err)
+	}
+}
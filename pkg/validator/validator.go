@@ -1,14 +1,40 @@
 package validator
 
 import (
+	"context"
 	"go-template/internal/dto"
+	"go-template/pkg/i18n"
 	"regexp"
+	"strings"
 
+	"github.com/go-playground/locales"
+	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
+	enTranslations "github.com/go-playground/validator/v10/translations/en"
+	esTranslations "github.com/go-playground/validator/v10/translations/es"
+	frTranslations "github.com/go-playground/validator/v10/translations/fr"
 )
 
+// messages overrides the stock translations of the tags used by the DTOs. {0} is the field
+// and {1} the tag parameter; the texts are keys of the i18n catalogue.
+var messages = map[string]string{
+	"required":           "{0} is required",
+	"email":              "{0} must be a valid email",
+	"min":                "{0} must be at least {1} characters",
+	"max":                "{0} must be at most {1} characters",
+	"oneof":              "{0} must be one of: {1}",
+	"bcp47_language_tag": "{0} must be a valid BCP 47 language tag (e.g. en-US)",
+	"timezone":           "{0} must be a valid IANA time zone (e.g. Europe/Berlin)",
+	"e164":               "{0} must be a phone number in E.164 format (e.g. +14155552671)",
+	"password":           "{0} must be at least 8 characters and contain uppercase, lowercase, number, and special character",
+}
+
+// fallbackMessage is used for tags without any translation
+const fallbackMessage = "{0} is invalid"
+
 type Validator struct {
-	validator *validator.Validate
+	validator   *validator.Validate
+	translators *ut.UniversalTranslator
 }
 
 func New() *Validator {
@@ -17,8 +43,44 @@ func New() *Validator {
 	// Register custom password validation
 	v.RegisterValidation("password", validatePassword)
 	
+	// Validation messages are rendered in every supported locale
+	var localeTranslators []locales.Translator
+	for _, locale := range i18n.Locales() {
+		localeTranslators = append(localeTranslators, i18n.Translator(locale))
+	}
+	translators := ut.New(localeTranslators[0], localeTranslators...)
+	registerDefaults := map[string]func(*validator.Validate, ut.Translator) error{
+		"en": enTranslations.RegisterDefaultTranslations,
+		"es": esTranslations.RegisterDefaultTranslations,
+		"fr": frTranslations.RegisterDefaultTranslations,
+	}
+	for _, locale := range i18n.Locales() {
+		trans, _ := translators.GetTranslator(locale)
+		if register, ok := registerDefaults[locale]; ok {
+			register(v, trans)
+		}
+		registerMessages(v, trans, locale)
+	}
+	
 	return &Validator{
-		validator: v,
+		validator:   v,
+		translators: translators,
+	}
+}
+
+// registerMessages registers the catalogue messages of locale, replacing the stock ones
+func registerMessages(v *validator.Validate, trans ut.Translator, locale string) {
+	for tag, message := range messages {
+		text := i18n.T(locale, message)
+		v.RegisterTranslation(tag, trans, func(trans ut.Translator) error {
+			return trans.Add(tag, text, true)
+		}, func(trans ut.Translator, fe validator.FieldError) string {
+			message, err := trans.T(fe.Tag(), fe.Field(), fe.Param())
+			if err != nil {
+				return fe.Error()
+			}
+			return message
+		})
 	}
 }
 
@@ -62,14 +124,36 @@ func (v *Validator) Validate(i interface{}) error {
 	return v.validator.Struct(i)
 }
 
-func (v *Validator) ValidateStruct(i interface{}) []dto.ValidationError {
+// ValidateStruct validates a struct and returns its errors with messages in the locale of ctx
+func (v *Validator) ValidateStruct(ctx context.Context, i interface{}) []dto.ValidationError {
 	err := v.validator.Struct(i)
 	if err != nil {
 		var validationErrors validator.ValidationErrors
 		if errors, ok := err.(validator.ValidationErrors); ok {
 			validationErrors = errors
 		}
-		return dto.FormatValidationErrors(validationErrors)
+		return v.formatValidationErrors(i18n.FromContext(ctx), validationErrors)
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+func (v *Validator) formatValidationErrors(locale string, errs validator.ValidationErrors) []dto.ValidationError {
+	trans, _ := v.translators.GetTranslator(locale)
+	
+	var validationErrors []dto.ValidationError
+	for _, err := range errs {
+		message := err.Translate(trans)
+		if message == err.Error() {
+			// No translation for this tag in any catalogue
+			message = strings.ReplaceAll(i18n.T(locale, fallbackMessage), "{0}", err.Field())
+		}
+		validationErrors = append(validationErrors, dto.ValidationError{
+			Field:   err.Field(),
+			Tag:     err.Tag(),
+			Value:   err.Param(),
+			Message: message,
+		})
+	}
+	
+	return validationErrors
+}